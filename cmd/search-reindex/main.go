@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	searchInfra "github.com/unclaim/chegonado.git/internal/search/infra"
+	cfg "github.com/unclaim/chegonado.git/internal/shared/config"
+)
+
+// defaultTasksIndex — псевдоним индекса задач, если search.tasks_index не задан.
+const defaultTasksIndex = "tasks"
+
+func main() {
+	configPath := flag.String("config", "../../configs/config.yaml", "Путь к файлу конфигурации")
+	mappingPath := flag.String("mapping", "../../migrations/elasticsearch/000001_init_tasks_index.json", "Файл с настройками и маппингом индекса задач")
+	batchSize := flag.Int("batch", 500, "Сколько задач индексировать одним запросом")
+	keepOld := flag.Bool("keep-old", false, "Не удалять прежние индексы после переключения псевдонима")
+	flag.Parse()
+
+	if *batchSize <= 0 {
+		log.Fatalf("Размер пакета должен быть положительным, получено %d", *batchSize)
+	}
+
+	ctx := context.Background()
+
+	config, err := cfg.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
+	if config.Search.Backend != "elasticsearch" {
+		log.Fatalf("search.backend не elasticsearch: поиск работает на PostgreSQL, переиндексация не нужна")
+	}
+	alias := config.Search.TasksIndex
+	if alias == "" {
+		alias = defaultTasksIndex
+	}
+
+	mapping, err := loadMapping(*mappingPath)
+	if err != nil {
+		log.Fatalf("Ошибка чтения маппинга: %v", err)
+	}
+
+	dbURL := config.Database.URL
+	if dbURL == "" {
+		dbURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+			config.Database.User, config.Database.Password, config.Database.Host, config.Database.Port, config.Database.Name, config.Database.SSLMode)
+	}
+	dbpool, err := pgxpool.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+	}
+	defer dbpool.Close()
+
+	engine, err := searchInfra.NewElasticsearchEngine(config.ExternalServices.Elasticsearch, alias)
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать Elasticsearch: %v", err)
+	}
+	source := searchInfra.NewTaskSource(dbpool)
+
+	// Время берётся из базы: по нему догоняются изменения, сделанные во время переиндексации.
+	startedAt, err := dbNow(ctx, dbpool)
+	if err != nil {
+		log.Fatalf("Не удалось получить время базы данных: %v", err)
+	}
+
+	index := fmt.Sprintf("%s_%s", alias, time.Now().UTC().Format("20060102150405"))
+	if err := engine.CreateIndex(ctx, index, mapping); err != nil {
+		log.Fatalf("Не удалось создать индекс %s: %v", index, err)
+	}
+	log.Printf("Создан индекс %s", index)
+
+	total, err := source.CountTasks(ctx)
+	if err != nil {
+		log.Fatalf("Ошибка подсчёта задач: %v", err)
+	}
+	log.Printf("Индексация %d задач пакетами по %d", total, *batchSize)
+
+	indexed, err := copyTasks(ctx, source, engine, index, nil, *batchSize, func(done int) {
+		if total > 0 {
+			log.Printf("Проиндексировано %d из %d (%.1f%%)", done, total, float64(done)*100/float64(total))
+		}
+	})
+	if err != nil {
+		log.Fatalf("Индексация прервана, индекс %s не подключён: %v", index, err)
+	}
+
+	// До переключения приложение пишет изменения и удаления в прежний индекс. Перед переключением
+	// новый индекс догоняет изменения с начала переиндексации и избавляется от удалённых задач,
+	// иначе после переключения в поиске остались бы задачи, которых уже нет.
+	caughtUpAt, err := dbNow(ctx, dbpool)
+	if err != nil {
+		log.Fatalf("Не удалось получить время базы данных: %v", err)
+	}
+	if err := catchUp(ctx, source, engine, index, startedAt, *batchSize); err != nil {
+		log.Fatalf("Не удалось догнать изменения задач, индекс %s не подключён: %v", index, err)
+	}
+
+	previous, err := engine.SwapAlias(ctx, index)
+	if err != nil {
+		log.Fatalf("Не удалось переключить псевдоним %s на %s: %v", alias, index, err)
+	}
+	log.Printf("Псевдоним %s переключён на %s (%d задач)", alias, index, indexed)
+
+	// Изменения, сделанные между сверкой и переключением, ушли в прежний индекс; дальше они приходят в новый сами.
+	if err := catchUp(ctx, source, engine, index, caughtUpAt, *batchSize); err != nil {
+		log.Fatalf("Не удалось догнать изменения задач после переключения: %v", err)
+	}
+
+	if *keepOld {
+		if len(previous) > 0 {
+			log.Printf("Прежние индексы сохранены: %s", strings.Join(previous, ", "))
+		}
+		return
+	}
+	for _, name := range previous {
+		if err := engine.DeleteIndex(ctx, name); err != nil {
+			log.Printf("Не удалось удалить прежний индекс %s: %v", name, err)
+			continue
+		}
+		log.Printf("Удалён прежний индекс %s", name)
+	}
+}
+
+// catchUp переиндексирует задачи, изменённые не раньше since, и удаляет из индекса задачи, которых больше нет в базе.
+func catchUp(ctx context.Context, source *searchInfra.TaskSource, engine *searchInfra.ElasticsearchEngine, index string, since time.Time, batchSize int) error {
+	updated, err := copyTasks(ctx, source, engine, index, &since, batchSize, nil)
+	if err != nil {
+		return err
+	}
+	removed, err := removeDeletedTasks(ctx, source, engine, index, batchSize)
+	if err != nil {
+		return err
+	}
+	log.Printf("Догнаны изменения %d задач, удалено %d задач, которых больше нет в базе", updated, removed)
+	return nil
+}
+
+// removeDeletedTasks сверяет ID задач в индексе с базой пакетами по порядку ID и удаляет из индекса
+// задачи, которых в базе уже нет. Возвращает число удалённых документов.
+func removeDeletedTasks(ctx context.Context, source *searchInfra.TaskSource, engine *searchInfra.ElasticsearchEngine, index string, batchSize int) (int, error) {
+	if err := engine.Refresh(ctx, index); err != nil {
+		return 0, fmt.Errorf("ошибка обновления индекса %s: %w", index, err)
+	}
+
+	var afterID int64
+	removed := 0
+	for {
+		ids, err := engine.ListTaskIDs(ctx, index, afterID, batchSize)
+		if err != nil {
+			return removed, fmt.Errorf("ошибка чтения ID задач из индекса %s: %w", index, err)
+		}
+		if len(ids) == 0 {
+			return removed, nil
+		}
+		existing, err := source.ExistingTaskIDs(ctx, ids)
+		if err != nil {
+			return removed, err
+		}
+		deleted := missingIDs(ids, existing)
+		if err := engine.BulkDelete(ctx, index, deleted); err != nil {
+			return removed, fmt.Errorf("ошибка удаления задач из индекса %s: %w", index, err)
+		}
+		removed += len(deleted)
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// missingIDs возвращает ID из ids, которых нет в existing.
+func missingIDs(ids, existing []int64) []int64 {
+	found := make(map[int64]struct{}, len(existing))
+	for _, id := range existing {
+		found[id] = struct{}{}
+	}
+	var missing []int64
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// dbNow возвращает текущее время базы данных в той же форме, что и tasks.updated_at.
+func dbNow(ctx context.Context, db *pgxpool.Pool) (time.Time, error) {
+	var now time.Time
+	err := db.QueryRow(ctx, `SELECT NOW()::timestamp`).Scan(&now)
+	return now, err
+}
+
+// copyTasks переносит задачи из базы в индекс пакетами по порядку ID и возвращает их число.
+// Если since задан, переносятся только задачи, изменённые не раньше since.
+func copyTasks(ctx context.Context, source *searchInfra.TaskSource, engine *searchInfra.ElasticsearchEngine, index string, since *time.Time, batchSize int, progress func(done int)) (int, error) {
+	var afterID int64
+	done := 0
+	for {
+		docs, err := source.ListTaskDocuments(ctx, since, afterID, batchSize)
+		if err != nil {
+			return done, err
+		}
+		if len(docs) == 0 {
+			return done, nil
+		}
+		if err := engine.BulkIndex(ctx, index, docs); err != nil {
+			return done, fmt.Errorf("ошибка индексации задач с ID %d–%d: %w", docs[0].ID, docs[len(docs)-1].ID, err)
+		}
+		done += len(docs)
+		afterID = docs[len(docs)-1].ID
+		if progress != nil {
+			progress(done)
+		}
+	}
+}
+
+// loadMapping читает файл миграции Elasticsearch и возвращает тело запроса без первой строки
+// с методом и путём. Псевдонимы из файла отбрасываются: новый индекс подключается к псевдониму
+// только после того, как в нём окажутся все задачи.
+func loadMapping(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(bytes.NewReader(data))
+	if _, err := reader.ReadString('\n'); err != nil {
+		return nil, fmt.Errorf("в файле %s нет тела запроса", path)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&body); err != nil {
+		return nil, fmt.Errorf("некорректный JSON в %s: %w", path, err)
+	}
+	delete(body, "aliases")
+	return json.Marshal(body)
+}
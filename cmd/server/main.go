@@ -57,6 +57,18 @@ func main() {
 		deps.TaskHandler,
 		deps.FileStorageHandler,
 		deps.ChatHandler,
+		deps.NotificationsHandler,
+		deps.DisputesHandler,
+		deps.BidsHandler,
+		deps.PaymentsHandler,
+		deps.LedgerHandler,
+		deps.DocumentsHandler,
+		deps.SubscriptionsHandler,
+		deps.CurrenciesHandler,
+		deps.RatingsHandler,
+		deps.ReviewsHandler,
+		deps.SearchHandler,
+		deps.ShortenerHandler,
 		deps.SessionsManager,
 		deps.Context,
 	)
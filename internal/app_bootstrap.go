@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"log/slog"
@@ -15,15 +16,57 @@ import (
 	"github.com/unclaim/chegonado.git/internal/auth/api"
 	"github.com/unclaim/chegonado.git/internal/auth/domain"
 	"github.com/unclaim/chegonado.git/internal/auth/infra"
+	"github.com/unclaim/chegonado.git/internal/bids"
+	bidsAPI "github.com/unclaim/chegonado.git/internal/bids/api"
+	bidsDomain "github.com/unclaim/chegonado.git/internal/bids/domain"
+	bidsInfra "github.com/unclaim/chegonado.git/internal/bids/infra"
 	chatAPI "github.com/unclaim/chegonado.git/internal/chat/api"
 	chatDomain "github.com/unclaim/chegonado.git/internal/chat/domain"
 	chatInfra "github.com/unclaim/chegonado.git/internal/chat/infra"
+	currenciesAPI "github.com/unclaim/chegonado.git/internal/currencies/api"
+	currenciesDomain "github.com/unclaim/chegonado.git/internal/currencies/domain"
+	currenciesInfra "github.com/unclaim/chegonado.git/internal/currencies/infra"
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	disputesAPI "github.com/unclaim/chegonado.git/internal/disputes/api"
+	disputesDomain "github.com/unclaim/chegonado.git/internal/disputes/domain"
+	disputesInfra "github.com/unclaim/chegonado.git/internal/disputes/infra"
+	documentsAPI "github.com/unclaim/chegonado.git/internal/documents/api"
+	documentsDomain "github.com/unclaim/chegonado.git/internal/documents/domain"
+	documentsInfra "github.com/unclaim/chegonado.git/internal/documents/infra"
 	filestorageAPI "github.com/unclaim/chegonado.git/internal/filestorage/api"
 	filestorageDomain "github.com/unclaim/chegonado.git/internal/filestorage/domain"
 	filestorageInfra "github.com/unclaim/chegonado.git/internal/filestorage/infra"
 	gamificationDomain "github.com/unclaim/chegonado.git/internal/gamification/domain"
 	gamificationInfra "github.com/unclaim/chegonado.git/internal/gamification/infra"
+	ledgerAPI "github.com/unclaim/chegonado.git/internal/ledger/api"
+	ledgerDomain "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	ledgerInfra "github.com/unclaim/chegonado.git/internal/ledger/infra"
+	notificationsAPI "github.com/unclaim/chegonado.git/internal/notifications/api"
+	notificationsDomain "github.com/unclaim/chegonado.git/internal/notifications/domain"
+	notificationsInfra "github.com/unclaim/chegonado.git/internal/notifications/infra"
+	"github.com/unclaim/chegonado.git/internal/payments"
+	paymentsAPI "github.com/unclaim/chegonado.git/internal/payments/api"
+	paymentsDomain "github.com/unclaim/chegonado.git/internal/payments/domain"
+	paymentsInfra "github.com/unclaim/chegonado.git/internal/payments/infra"
+	ratingsAPI "github.com/unclaim/chegonado.git/internal/ratings/api"
+	ratingsDomain "github.com/unclaim/chegonado.git/internal/ratings/domain"
+	ratingsInfra "github.com/unclaim/chegonado.git/internal/ratings/infra"
+	"github.com/unclaim/chegonado.git/internal/reviews"
+	reviewsAPI "github.com/unclaim/chegonado.git/internal/reviews/api"
+	reviewsDomain "github.com/unclaim/chegonado.git/internal/reviews/domain"
+	reviewsInfra "github.com/unclaim/chegonado.git/internal/reviews/infra"
+	searchAPI "github.com/unclaim/chegonado.git/internal/search/api"
+	searchDomain "github.com/unclaim/chegonado.git/internal/search/domain"
+	searchInfra "github.com/unclaim/chegonado.git/internal/search/infra"
 	"github.com/unclaim/chegonado.git/internal/shared/config"
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+	shortenerAPI "github.com/unclaim/chegonado.git/internal/shortener/api"
+	shortenerDomain "github.com/unclaim/chegonado.git/internal/shortener/domain"
+	shortenerInfra "github.com/unclaim/chegonado.git/internal/shortener/infra"
+	subscriptionsAPI "github.com/unclaim/chegonado.git/internal/subscriptions/api"
+	subscriptionsDomain "github.com/unclaim/chegonado.git/internal/subscriptions/domain"
+	subscriptionsInfra "github.com/unclaim/chegonado.git/internal/subscriptions/infra"
+	"github.com/unclaim/chegonado.git/internal/tasks"
 	tasksAPI "github.com/unclaim/chegonado.git/internal/tasks/api"
 	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
 	tasksInfra "github.com/unclaim/chegonado.git/internal/tasks/infra"
@@ -32,21 +75,61 @@ import (
 	usersInfra "github.com/unclaim/chegonado.git/internal/users/infra"
 	"github.com/unclaim/chegonado.git/pkg/infrastructure/email"
 	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/geocoding"
 	"github.com/unclaim/chegonado.git/pkg/security/session"
 	"github.com/unclaim/chegonado.git/pkg/security/token"
 )
 
+// savedSearchDigestInterval — как часто проверять, не пора ли отправить сводки по сохранённым поискам.
+const savedSearchDigestInterval = 5 * time.Minute
+
+// disputeSLACheckInterval — как часто проверять сроки рассмотрения споров.
+const disputeSLACheckInterval = 15 * time.Minute
+
+// auctionCloseInterval — как часто закрывать торги, срок которых истёк.
+const auctionCloseInterval = time.Minute
+
+// defaultPayoutBatchInterval — как часто отправлять одобренные выплаты, если payouts.batch_interval не задан.
+const defaultPayoutBatchInterval = time.Hour
+
+// defaultSubscriptionRenewalInterval — как часто продлевать подписки, если subscriptions.renewal_interval не задан.
+const defaultSubscriptionRenewalInterval = time.Hour
+
+// defaultRatingRefreshInterval — как часто обновлять рейтинги исполнителей, если ratings.refresh_interval не задан.
+const defaultRatingRefreshInterval = time.Hour
+
+// defaultTasksIndex — индекс задач в Elasticsearch, если search.tasks_index не задан.
+const defaultTasksIndex = "tasks"
+
+// suggestionsRefreshInterval — как часто перестраивать индекс подсказок поиска.
+const suggestionsRefreshInterval = 10 * time.Minute
+
+// defaultReviewRevealInterval — как часто публиковать отзывы по сроку, если reviews.reveal_interval не задан.
+const defaultReviewRevealInterval = 10 * time.Minute
+
 type AppDependencies struct {
-	Config             *config.AppConfig
-	DBPool             *pgxpool.Pool
-	Tokens             *token.JwtToken
-	SessionsManager    *session.SessionsDB
-	AuthHandler        *api.AuthHandler
-	UserHandler        *usersAPI.UserHandler
-	TaskHandler        *tasksAPI.TasksHandler
-	ChatHandler        *chatAPI.ChatHandler
-	FileStorageHandler *filestorageAPI.FileStorageHandler
-	Context            context.Context
+	Config               *config.AppConfig
+	DBPool               *pgxpool.Pool
+	Tokens               *token.JwtToken
+	SessionsManager      *session.SessionsDB
+	AuthHandler          *api.AuthHandler
+	UserHandler          *usersAPI.UserHandler
+	TaskHandler          *tasksAPI.TasksHandler
+	ChatHandler          *chatAPI.ChatHandler
+	FileStorageHandler   *filestorageAPI.FileStorageHandler
+	NotificationsHandler *notificationsAPI.NotificationsHandler
+	DisputesHandler      *disputesAPI.DisputesHandler
+	BidsHandler          *bidsAPI.BidsHandler
+	PaymentsHandler      *paymentsAPI.PaymentsHandler
+	LedgerHandler        *ledgerAPI.LedgerHandler
+	DocumentsHandler     *documentsAPI.DocumentsHandler
+	SubscriptionsHandler *subscriptionsAPI.SubscriptionsHandler
+	CurrenciesHandler    *currenciesAPI.CurrenciesHandler
+	RatingsHandler       *ratingsAPI.RatingsHandler
+	ReviewsHandler       *reviewsAPI.ReviewsHandler
+	SearchHandler        *searchAPI.SearchHandler
+	ShortenerHandler     *shortenerAPI.ShortenerHandler
+	Context              context.Context
 }
 
 func InitApplication(ctx context.Context, showInfo bool) (*AppDependencies, error) {
@@ -100,8 +183,34 @@ func InitApplication(ctx context.Context, showInfo bool) (*AppDependencies, erro
 	gamificationRepo := gamificationInfra.NewGamificationRepository()
 	gamificationService := gamificationDomain.NewGamificationService(gamificationRepo)
 
+	// === Блок инициализации геокодера ===
+	var geocoder ports.Geocoder
+	switch cfg.Geocoding.Provider {
+	case "", "gazetteer":
+		if cfg.Geocoding.GazetteerPath == "" {
+			geocoder = geocoding.NewGazetteer()
+			break
+		}
+		gazetteerFile, err := os.Open(cfg.Geocoding.GazetteerPath)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("не удалось открыть справочник геокодера: %w", err)
+		}
+		geocoder, err = geocoding.NewGazetteerFromReader(gazetteerFile)
+		gazetteerFile.Close()
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("не удалось загрузить справочник геокодера: %w", err)
+		}
+		slog.Info("Используется офлайн-геокодер", "gazetteer", cfg.Geocoding.GazetteerPath)
+	default:
+		dbpool.Close()
+		return nil, fmt.Errorf("неизвестный геокодер: %s", cfg.Geocoding.Provider)
+	}
+	// ===========================================
+
 	usersRepo := usersInfra.NewUsersRepository(dbpool)
-	usersService := usersDomain.NewUsersService(usersRepo, emailSender, tokens, *cfg)
+	usersService := usersDomain.NewUsersService(usersRepo, emailSender, tokens, *cfg, geocoder)
 	userHandler := usersAPI.NewUserHandler(tokens, usersService)
 	chatRepo := chatInfra.NewChatRepository(dbpool)
 	chatService := chatDomain.NewChatService(chatRepo)
@@ -129,10 +238,220 @@ func InitApplication(ctx context.Context, showInfo bool) (*AppDependencies, erro
 	fileStorageHandlers := filestorageAPI.NewFileStorageHandler(fileStorageService)
 	// ===========================================
 
+	notificationsRepo := notificationsInfra.NewNotificationsRepository(dbpool)
+	notificationsService := notificationsDomain.NewNotificationsService(notificationsRepo, emailSender)
+	notificationsHandler := notificationsAPI.NewNotificationsHandler(notificationsService)
+
+	currenciesRepo := currenciesInfra.NewCurrenciesRepository(dbpool)
+	currenciesService := currenciesDomain.NewCurrenciesService(currenciesRepo)
+	currenciesHandler := currenciesAPI.NewCurrenciesHandler(currenciesService)
+
 	tasksRepo := tasksInfra.NewTasksRepository(dbpool)
-	tasksService := tasksDomain.NewTasksService(tasksRepo)
+	tasksService := tasksDomain.NewTasksService(tasksRepo, bus, geocoder, currenciesService)
 	tasksHandler := tasksAPI.NewTasksHandler(tasksService, tokens)
 
+	disputesRepo := disputesInfra.NewDisputesRepository(dbpool)
+	disputesService := disputesDomain.NewDisputesService(disputesRepo, bus)
+	disputesHandler := disputesAPI.NewDisputesHandler(disputesService)
+
+	bidsRepo := bidsInfra.NewBidsRepository(dbpool)
+	bidsService := bidsDomain.NewBidsService(bidsRepo, bus)
+	bidsHandler := bidsAPI.NewBidsHandler(bidsService)
+
+	ledgerRepo := ledgerInfra.NewLedgerRepository(dbpool)
+	ledgerService := ledgerDomain.NewLedgerService(ledgerRepo)
+	ledgerHandler := ledgerAPI.NewLedgerHandler(ledgerService)
+
+	// === Блок инициализации платёжного шлюза ===
+	var paymentGateway paymentsDomain.PaymentGateway
+	switch cfg.ExternalServices.PaymentGateway.Provider {
+	case "", "fake":
+		paymentGateway = paymentsInfra.NewFakeGateway(0)
+		slog.Info("Используется локальный платёжный шлюз")
+	case "http":
+		paymentGateway, err = paymentsInfra.NewHTTPGateway(cfg.ExternalServices.PaymentGateway)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("не удалось инициализировать платёжный шлюз: %w", err)
+		}
+		slog.Info("Используется внешний платёжный шлюз", "url", cfg.ExternalServices.PaymentGateway.URL)
+	default:
+		dbpool.Close()
+		return nil, fmt.Errorf("неизвестный платёжный шлюз: %s", cfg.ExternalServices.PaymentGateway.Provider)
+	}
+	// ===========================================
+
+	paymentsRepo := paymentsInfra.NewPaymentsRepository(dbpool)
+	feeSchedule := paymentsDomain.FeeSchedule{DefaultBP: cfg.Fees.DefaultRateBP, ProDiscountPercent: cfg.Fees.ProDiscountPercent}
+	for _, rule := range cfg.Fees.Rules {
+		feeSchedule.Rules = append(feeSchedule.Rules, paymentsDomain.FeeRule{CategoryID: rule.CategoryID, SubcategoryID: rule.SubcategoryID, RateBP: rule.RateBP})
+	}
+	if err := feeSchedule.Validate(); err != nil {
+		dbpool.Close()
+		return nil, fmt.Errorf("ошибка в настройках комиссии: %w", err)
+	}
+	payoutBatchInterval := defaultPayoutBatchInterval
+	if cfg.Payouts.BatchInterval != "" {
+		payoutBatchInterval, err = time.ParseDuration(cfg.Payouts.BatchInterval)
+		if err != nil || payoutBatchInterval <= 0 {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный интервал пакетов выплат %q: %v", cfg.Payouts.BatchInterval, err)
+		}
+	}
+	paymentsService := paymentsDomain.NewPaymentsService(paymentsRepo, paymentGateway, ledgerService, bus, paymentsDomain.Settings{
+		WebhookSecret: cfg.ExternalServices.PaymentGateway.Secret,
+		Fees:          feeSchedule,
+		Payouts:       paymentsDomain.PayoutPolicy{MinAmount: cfg.Payouts.MinAmount, MaxBatchSize: cfg.Payouts.MaxBatchSize},
+	})
+	paymentsHandler := paymentsAPI.NewPaymentsHandler(paymentsService)
+
+	documentsRenderer, err := documentsInfra.NewPDFRenderer(cfg.Documents.FontDir)
+	if err != nil {
+		dbpool.Close()
+		return nil, fmt.Errorf("не удалось инициализировать генератор документов: %w", err)
+	}
+	documentsRepo := documentsInfra.NewDocumentsRepository(dbpool)
+	documentsService := documentsDomain.NewDocumentsService(documentsRepo, fileStorageRepo, documentsRenderer, emailSender)
+	documentsHandler := documentsAPI.NewDocumentsHandler(documentsService)
+
+	var plans subscriptionsDomain.Catalog
+	for _, plan := range cfg.Subscriptions.Plans {
+		currency := subscriptionsDomain.DefaultCurrency
+		if plan.Currency != "" {
+			currency = strings.ToUpper(strings.TrimSpace(plan.Currency))
+		}
+		plans = append(plans, subscriptionsDomain.Plan{
+			Code:     plan.Code,
+			Title:    plan.Title,
+			Period:   subscriptionsDomain.Period(plan.Period),
+			Price:    plan.Price,
+			Currency: currency,
+			NoAds:    plan.NoAds,
+		})
+	}
+	if err := plans.Validate(); err != nil {
+		dbpool.Close()
+		return nil, fmt.Errorf("некорректные тарифы подписки: %w", err)
+	}
+	var gracePeriod time.Duration
+	if cfg.Subscriptions.GracePeriod != "" {
+		gracePeriod, err = time.ParseDuration(cfg.Subscriptions.GracePeriod)
+		if err != nil || gracePeriod < 0 {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный льготный период подписки %q: %v", cfg.Subscriptions.GracePeriod, err)
+		}
+	}
+	subscriptionRenewalInterval := defaultSubscriptionRenewalInterval
+	if cfg.Subscriptions.RenewalInterval != "" {
+		subscriptionRenewalInterval, err = time.ParseDuration(cfg.Subscriptions.RenewalInterval)
+		if err != nil || subscriptionRenewalInterval <= 0 {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный интервал продления подписок %q: %v", cfg.Subscriptions.RenewalInterval, err)
+		}
+	}
+	subscriptionsRepo := subscriptionsInfra.NewSubscriptionsRepository(dbpool)
+	subscriptionsService := subscriptionsDomain.NewSubscriptionsService(subscriptionsRepo, paymentGateway, ledgerService, bus, subscriptionsDomain.Settings{
+		Plans:       plans,
+		GracePeriod: gracePeriod,
+	})
+	subscriptionsHandler := subscriptionsAPI.NewSubscriptionsHandler(subscriptionsService)
+
+	ratingSettings := ratingsDomain.DefaultSettings
+	if cfg.Ratings.PriorMean != 0 {
+		ratingSettings.PriorMean = cfg.Ratings.PriorMean
+	}
+	if cfg.Ratings.PriorWeight != 0 {
+		ratingSettings.PriorWeight = cfg.Ratings.PriorWeight
+	}
+	if cfg.Ratings.HalfLife != "" {
+		ratingSettings.HalfLife, err = time.ParseDuration(cfg.Ratings.HalfLife)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный период полураспада рейтинга %q: %v", cfg.Ratings.HalfLife, err)
+		}
+	}
+	if err := ratingSettings.Validate(); err != nil {
+		dbpool.Close()
+		return nil, err
+	}
+	ratingRefreshInterval := defaultRatingRefreshInterval
+	if cfg.Ratings.RefreshInterval != "" {
+		ratingRefreshInterval, err = time.ParseDuration(cfg.Ratings.RefreshInterval)
+		if err != nil || ratingRefreshInterval <= 0 {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный интервал обновления рейтингов %q: %v", cfg.Ratings.RefreshInterval, err)
+		}
+	}
+	ratingsRepo := ratingsInfra.NewRatingsRepository(dbpool)
+	ratingsService := ratingsDomain.NewRatingsService(ratingsRepo, ratingSettings)
+	ratingsHandler := ratingsAPI.NewRatingsHandler(ratingsService)
+
+	reviewSettings := reviewsDomain.DefaultSettings
+	if cfg.Reviews.EditWindow != "" {
+		reviewSettings.EditWindow, err = time.ParseDuration(cfg.Reviews.EditWindow)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректное окно редактирования отзыва %q: %v", cfg.Reviews.EditWindow, err)
+		}
+	}
+	if cfg.Reviews.RevealAfter != "" {
+		reviewSettings.RevealAfter, err = time.ParseDuration(cfg.Reviews.RevealAfter)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный срок публикации отзыва %q: %v", cfg.Reviews.RevealAfter, err)
+		}
+	}
+	if err := reviewSettings.Validate(); err != nil {
+		dbpool.Close()
+		return nil, err
+	}
+	reviewRevealInterval := defaultReviewRevealInterval
+	if cfg.Reviews.RevealInterval != "" {
+		reviewRevealInterval, err = time.ParseDuration(cfg.Reviews.RevealInterval)
+		if err != nil || reviewRevealInterval <= 0 {
+			dbpool.Close()
+			return nil, fmt.Errorf("некорректный интервал публикации отзывов %q: %v", cfg.Reviews.RevealInterval, err)
+		}
+	}
+	reviewsRepo := reviewsInfra.NewReviewsRepository(dbpool)
+	reviewsService := reviewsDomain.NewReviewsService(reviewsRepo, bus, reviewSettings)
+	reviewsHandler := reviewsAPI.NewReviewsHandler(reviewsService)
+
+	// === Блок инициализации поиска задач ===
+	var searchEngine searchDomain.SearchEngine
+	switch cfg.Search.Backend {
+	case "", "postgres":
+		searchEngine = searchInfra.NewPostgresEngine(dbpool)
+		slog.Info("Поиск задач работает на PostgreSQL")
+	case "elasticsearch":
+		tasksIndex := cfg.Search.TasksIndex
+		if tasksIndex == "" {
+			tasksIndex = defaultTasksIndex
+		}
+		searchEngine, err = searchInfra.NewElasticsearchEngine(cfg.ExternalServices.Elasticsearch, tasksIndex)
+		if err != nil {
+			dbpool.Close()
+			return nil, fmt.Errorf("не удалось инициализировать поиск: %w", err)
+		}
+		slog.Info("Поиск задач работает на Elasticsearch", "url", cfg.ExternalServices.Elasticsearch.URL, "index", tasksIndex)
+	default:
+		dbpool.Close()
+		return nil, fmt.Errorf("неизвестный поисковый движок: %s", cfg.Search.Backend)
+	}
+	// ===========================================
+	suggestionsRepo := searchInfra.NewSuggestionsRepository(dbpool)
+	searchService := searchDomain.NewSearchService(searchEngine, searchInfra.NewTaskSource(dbpool), currenciesService, suggestionsRepo)
+	suggestService := searchDomain.NewSuggestService(suggestionsRepo)
+	searchHandler := searchAPI.NewSearchHandler(searchService, suggestService)
+
+	shortenerSettings := shortenerDomain.Settings{BaseURL: cfg.Shortener.BaseURL, SiteURL: cfg.Shortener.SiteURL}
+	if err := shortenerSettings.Validate(); err != nil {
+		dbpool.Close()
+		return nil, err
+	}
+	shortenerService := shortenerDomain.NewShortenerService(shortenerInfra.NewShortenerRepository(dbpool), shortenerSettings)
+	shortenerHandler := shortenerAPI.NewShortenerHandler(shortenerService)
+
 	authRepo := infra.NewAuthRepository(dbpool, fileStorageService)
 	authService := domain.NewAuthService(authRepo, sm, emailSender, config.AppConfig{}, bus)
 	authHandler := api.NewAuthHandler(authService)
@@ -146,17 +465,127 @@ func InitApplication(ctx context.Context, showInfo bool) (*AppDependencies, erro
 			gamificationService.HandleUserRegistered(e)
 		}
 	})
+
+	bus.Subscribe(tasks.TaskUpdatedEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleTaskUpdated(event)
+		searchService.HandleTaskUpdated(event)
+	})
+
+	bus.Subscribe(tasks.TaskCreatedEvent{}, func(event eventbus.Event) {
+		tasksService.HandleTaskCreated(event)
+		searchService.HandleTaskCreated(event)
+	})
+
+	bus.Subscribe(tasks.TaskStatusChangedEvent{}, func(event eventbus.Event) {
+		searchService.HandleTaskStatusChanged(event)
+	})
+
+	bus.Subscribe(tasks.TaskCancelledEvent{}, func(event eventbus.Event) {
+		searchService.HandleTaskCancelled(event)
+	})
+
+	bus.Subscribe(tasks.TaskDeletedEvent{}, func(event eventbus.Event) {
+		searchService.HandleTaskDeleted(event)
+	})
+
+	bus.Subscribe(tasks.SavedSearchAlertEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleSavedSearchAlert(event)
+	})
+
+	bus.Subscribe(tasks.ResponseOfferEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleResponseOffer(event)
+	})
+
+	bus.Subscribe(tasks.ReportRejectedEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleReportRejected(event)
+	})
+
+	bus.Subscribe(tasks.ContractCreatedEvent{}, func(event eventbus.Event) {
+		paymentsService.HandleContractCreated(event)
+	})
+
+	bus.Subscribe(tasks.MilestoneConfirmedEvent{}, func(event eventbus.Event) {
+		paymentsService.HandleMilestoneConfirmed(event)
+	})
+
+	bus.Subscribe(tasks.ContractCancelledEvent{}, func(event eventbus.Event) {
+		paymentsService.HandleContractCancelled(event)
+	})
+
+	bus.Subscribe(disputes.DisputeUpdatedEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleDisputeUpdated(event)
+	})
+
+	bus.Subscribe(disputes.DisputeResolvedEvent{}, func(event eventbus.Event) {
+		tasksService.HandleDisputeResolved(event)
+		paymentsService.HandleDisputeResolved(event)
+	})
+
+	bus.Subscribe(payments.EscrowClosedEvent{}, func(event eventbus.Event) {
+		documentsService.HandleEscrowClosed(event)
+	})
+
+	bus.Subscribe(bids.BidPlacedEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleBidPlaced(event)
+	})
+
+	bus.Subscribe(bids.AuctionClosedEvent{}, func(event eventbus.Event) {
+		tasksService.HandleAuctionClosed(event)
+		notificationsService.HandleAuctionClosed(event)
+	})
+
+	bus.Subscribe(reviews.ReviewCreatedEvent{}, func(event eventbus.Event) {
+		notificationsService.HandleReviewCreated(event)
+	})
+
+	bus.Subscribe(reviews.ReviewPublishedEvent{}, func(event eventbus.Event) {
+		ratingsService.HandleReviewPublished(event)
+		notificationsService.HandleReviewPublished(event)
+	})
+
+	bus.Subscribe(reviews.ReviewRemovedEvent{}, func(event eventbus.Event) {
+		ratingsService.HandleReviewRemoved(event)
+	})
+
+	// Часовые и суточные сводки по сохранённым поискам.
+	go tasksService.RunSavedSearchDigests(ctx, savedSearchDigestInterval)
+	// Отметки о просроченных спорах.
+	go disputesService.RunSLAWatcher(ctx, disputeSLACheckInterval)
+	// Закрытие торгов по истечении срока.
+	go bidsService.RunAuctionCloser(ctx, auctionCloseInterval)
+	// Отправка одобренных выплат пакетами.
+	go paymentsService.RunPayoutBatches(ctx, payoutBatchInterval)
+	// Продление подписок Pro и снятие Pro с неоплаченных.
+	go subscriptionsService.RunRenewals(ctx, subscriptionRenewalInterval)
+	// Учёт пропущенных отзывов и давности старых в рейтингах исполнителей.
+	go ratingsService.RunRefresh(ctx, ratingRefreshInterval)
+	// Публикация отзывов, вторая сторона которых не ответила к сроку.
+	go reviewsService.RunRevealer(ctx, reviewRevealInterval)
+	// Перестроение индекса подсказок поиска: новые категории, задачи и популярность запросов.
+	go suggestService.RunRefresh(ctx, suggestionsRefreshInterval)
 	return &AppDependencies{
-		Config:             cfg,
-		DBPool:             dbpool,
-		Tokens:             tokens,
-		SessionsManager:    sm,
-		AuthHandler:        authHandler,
-		UserHandler:        userHandler,
-		TaskHandler:        tasksHandler,
-		ChatHandler:        chatHandler,
-		FileStorageHandler: fileStorageHandlers,
-		Context:            ctx,
+		Config:               cfg,
+		DBPool:               dbpool,
+		Tokens:               tokens,
+		SessionsManager:      sm,
+		AuthHandler:          authHandler,
+		UserHandler:          userHandler,
+		TaskHandler:          tasksHandler,
+		ChatHandler:          chatHandler,
+		FileStorageHandler:   fileStorageHandlers,
+		NotificationsHandler: notificationsHandler,
+		DisputesHandler:      disputesHandler,
+		BidsHandler:          bidsHandler,
+		PaymentsHandler:      paymentsHandler,
+		LedgerHandler:        ledgerHandler,
+		DocumentsHandler:     documentsHandler,
+		SubscriptionsHandler: subscriptionsHandler,
+		CurrenciesHandler:    currenciesHandler,
+		RatingsHandler:       ratingsHandler,
+		ReviewsHandler:       reviewsHandler,
+		SearchHandler:        searchHandler,
+		ShortenerHandler:     shortenerHandler,
+		Context:              ctx,
 	}, nil
 }
 
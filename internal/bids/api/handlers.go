@@ -1 +1,147 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/bids/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// BidsHandler отвечает за обработку HTTP-запросов, связанных с торгами по задачам.
+type BidsHandler struct {
+	service domain.BidsService
+}
+
+// NewBidsHandler создаёт новый экземпляр BidsHandler.
+func NewBidsHandler(service domain.BidsService) *BidsHandler {
+	return &BidsHandler{service: service}
+}
+
+// CreateAuction запускает торги по задаче.
+func (h *BidsHandler) CreateAuction(w http.ResponseWriter, r *http.Request) {
+	sess, taskID, ok := idRequest(w, r, "недопустимый идентификатор задачи")
+	if !ok {
+		return
+	}
+
+	var req domain.CreateAuctionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	auction, err := h.service.CreateAuction(r.Context(), taskID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, bidErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, auction)
+}
+
+// GetTaskAuction возвращает торги по задаче.
+func (h *BidsHandler) GetTaskAuction(w http.ResponseWriter, r *http.Request) {
+	sess, taskID, ok := idRequest(w, r, "недопустимый идентификатор задачи")
+	if !ok {
+		return
+	}
+
+	auction, err := h.service.GetTaskAuction(r.Context(), taskID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, bidErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, auction)
+}
+
+// GetAuction возвращает торги.
+func (h *BidsHandler) GetAuction(w http.ResponseWriter, r *http.Request) {
+	sess, auctionID, ok := idRequest(w, r, "недопустимый идентификатор торгов")
+	if !ok {
+		return
+	}
+
+	auction, err := h.service.GetAuction(r.Context(), auctionID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, bidErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, auction)
+}
+
+// ListBids возвращает ставки, которые видит текущий пользователь.
+func (h *BidsHandler) ListBids(w http.ResponseWriter, r *http.Request) {
+	sess, auctionID, ok := idRequest(w, r, "недопустимый идентификатор торгов")
+	if !ok {
+		return
+	}
+
+	bids, err := h.service.ListBids(r.Context(), auctionID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, bidErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, bids)
+}
+
+// PlaceBid принимает ставку исполнителя или её понижение.
+func (h *BidsHandler) PlaceBid(w http.ResponseWriter, r *http.Request) {
+	sess, auctionID, ok := idRequest(w, r, "недопустимый идентификатор торгов")
+	if !ok {
+		return
+	}
+
+	var req domain.PlaceBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	bid, err := h.service.PlaceBid(r.Context(), auctionID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, bidErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, bid)
+}
+
+// idRequest достаёт сессию и идентификатор из пути. При ошибке ответ уже записан.
+func idRequest(w http.ResponseWriter, r *http.Request, invalidIDMsg string) (*session.Session, int64, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("%s: %w", invalidIDMsg, err), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return sess, id, true
+}
+
+func bidErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrAuctionNotFound), errors.Is(err, domain.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotTaskOwner), errors.Is(err, domain.ErrOwnAuction):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidAuction), errors.Is(err, domain.ErrInvalidBid):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrAuctionExists), errors.Is(err, domain.ErrTaskNotOpen),
+		errors.Is(err, domain.ErrAuctionClosed), errors.Is(err, domain.ErrBidNotLower):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -1 +1,203 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuctionMode — видимость ставок во время торгов.
+type AuctionMode string
+
+const (
+	ModeOpen   AuctionMode = "open"   // Ставки и текущий минимум видны всем участникам
+	ModeSealed AuctionMode = "sealed" // Исполнитель видит только свою ставку, все ставки раскрываются после закрытия
+)
+
+// AuctionStatus — стадия торгов.
+type AuctionStatus string
+
+const (
+	AuctionRunning AuctionStatus = "running" // Приём ставок до срока
+	AuctionClosed  AuctionStatus = "closed"  // Торги завершены, победитель определён или ставок ниже резерва не было
+)
+
+const (
+	// MinAuctionDuration — минимальная длительность торгов от момента создания.
+	MinAuctionDuration = time.Hour
+	// MaxAuctionDuration — максимальная длительность торгов.
+	MaxAuctionDuration = 30 * 24 * time.Hour
+
+	maxBidCommentLength = 1000
+	// taskStatusActive — код открытой задачи в модуле задач; торги запускаются только по ней.
+	taskStatusActive = 100
+)
+
+var (
+	ErrAuctionNotFound = errors.New("торги не найдены")
+	ErrTaskNotFound    = errors.New("задача не найдена")
+	ErrNotTaskOwner    = errors.New("торги по задаче может запустить только заказчик")
+	ErrTaskNotOpen     = errors.New("торги можно запустить только по открытой задаче")
+	ErrAuctionExists   = errors.New("по задаче уже проводятся торги")
+	ErrInvalidAuction  = errors.New("некорректные параметры торгов")
+	ErrAuctionClosed   = errors.New("приём ставок завершён")
+	ErrInvalidBid      = errors.New("некорректная ставка")
+	ErrOwnAuction      = errors.New("заказчик не может делать ставки на своих торгах")
+	ErrBidNotLower     = errors.New("новая ставка должна быть ниже вашей предыдущей")
+)
+
+// Auction — торги на понижение цены по задаче.
+type Auction struct {
+	ID            int64         `json:"id"`
+	TaskID        int64         `json:"task_id"`
+	CustomerID    int64         `json:"customer_id"`
+	Mode          AuctionMode   `json:"mode"`
+	Status        AuctionStatus `json:"status"`
+	ReservePrice  *int          `json:"reserve_price,omitempty"` // Максимальная приемлемая цена, видна только заказчику
+	ClosesAt      time.Time     `json:"closes_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ClosedAt      *time.Time    `json:"closed_at"`
+	BidCount      int           `json:"bid_count"`
+	LowestBid     *int          `json:"lowest_bid"` // Текущий минимум; в закрытых торгах скрыт до завершения
+	WinningBidID  *int64        `json:"winning_bid_id"`
+	WinnerID      *int64        `json:"winner_id"`
+	WinningAmount *int          `json:"winning_amount"`
+}
+
+// Bid — ставка исполнителя. У исполнителя одна ставка на торги, он может только понижать её.
+type Bid struct {
+	ID         int64     `json:"id"`
+	AuctionID  int64     `json:"auction_id"`
+	ExecutorID int64     `json:"executor_id"`
+	Amount     int       `json:"amount"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"` // Когда ставка в последний раз понижалась
+}
+
+// TaskInfo — сведения о задаче, нужные для запуска торгов.
+type TaskInfo struct {
+	ID         int64
+	CustomerID int64
+	StatusCode int
+}
+
+// CreateAuctionRequest — запрос заказчика на запуск торгов.
+type CreateAuctionRequest struct {
+	Mode         AuctionMode `json:"mode"`
+	ReservePrice *int        `json:"reserve_price"`
+	ClosesAt     time.Time   `json:"closes_at"`
+}
+
+// PlaceBidRequest — ставка или её понижение.
+type PlaceBidRequest struct {
+	Amount  int    `json:"amount"`
+	Comment string `json:"comment"`
+}
+
+// Validate проверяет параметры торгов. По умолчанию торги открытые.
+func (r *CreateAuctionRequest) Validate(now time.Time) error {
+	if r.Mode == "" {
+		r.Mode = ModeOpen
+	}
+	if r.Mode != ModeOpen && r.Mode != ModeSealed {
+		return fmt.Errorf("%w: неизвестный режим %q", ErrInvalidAuction, r.Mode)
+	}
+	if r.ReservePrice != nil && *r.ReservePrice <= 0 {
+		return fmt.Errorf("%w: резервная цена должна быть положительной", ErrInvalidAuction)
+	}
+	duration := r.ClosesAt.Sub(now)
+	if duration < MinAuctionDuration || duration > MaxAuctionDuration {
+		return fmt.Errorf("%w: торги должны длиться от %s до %d дней", ErrInvalidAuction, MinAuctionDuration, int(MaxAuctionDuration.Hours()/24))
+	}
+	return nil
+}
+
+// Validate проверяет ставку и нормализует комментарий.
+func (r *PlaceBidRequest) Validate() error {
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.Amount <= 0 {
+		return fmt.Errorf("%w: сумма должна быть положительной", ErrInvalidBid)
+	}
+	if len([]rune(r.Comment)) > maxBidCommentLength {
+		return fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidBid, maxBidCommentLength)
+	}
+	return nil
+}
+
+// CanStart проверяет, можно ли запустить торги по задаче от имени пользователя.
+func (t TaskInfo) CanStart(userID int64) error {
+	if t.CustomerID != userID {
+		return ErrNotTaskOwner
+	}
+	if t.StatusCode != taskStatusActive {
+		return ErrTaskNotOpen
+	}
+	return nil
+}
+
+// AcceptsBids сообщает, принимаются ли ещё ставки. Торги, срок которых истёк,
+// не принимают ставки и до того, как фоновая задача их закроет.
+func (a Auction) AcceptsBids(now time.Time) bool {
+	return a.Status == AuctionRunning && now.Before(a.ClosesAt)
+}
+
+// CheckBid проверяет ставку исполнителя с учётом его предыдущей ставки.
+func (a Auction) CheckBid(executorID int64, previous *Bid, amount int, now time.Time) error {
+	if executorID == a.CustomerID {
+		return ErrOwnAuction
+	}
+	if !a.AcceptsBids(now) {
+		return ErrAuctionClosed
+	}
+	if previous != nil && amount >= previous.Amount {
+		return fmt.Errorf("%w: текущая ставка %d", ErrBidNotLower, previous.Amount)
+	}
+	return nil
+}
+
+// BidsVisible сообщает, видит ли пользователь чужие ставки: в открытых торгах — всегда,
+// в закрытых — только после завершения.
+func (a Auction) BidsVisible() bool {
+	return a.Mode == ModeOpen || a.Status == AuctionClosed
+}
+
+// ViewFor возвращает торги в том виде, в каком их видит пользователь:
+// резервная цена скрыта от исполнителей, минимум закрытых торгов — до завершения.
+func (a Auction) ViewFor(userID int64) Auction {
+	if userID != a.CustomerID {
+		a.ReservePrice = nil
+	}
+	if !a.BidsVisible() {
+		a.LowestBid = nil
+	}
+	return a
+}
+
+// SelectWinner выбирает победителя: самую низкую ставку не выше резервной цены.
+// При равных суммах побеждает тот, кто предложил цену раньше. Возвращает nil, если подходящих ставок нет.
+func SelectWinner(bids []Bid, reservePrice *int) *Bid {
+	eligible := make([]Bid, 0, len(bids))
+	for _, b := range bids {
+		if reservePrice == nil || b.Amount <= *reservePrice {
+			eligible = append(eligible, b)
+		}
+	}
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		if eligible[i].Amount != eligible[j].Amount {
+			return eligible[i].Amount < eligible[j].Amount
+		}
+		if !eligible[i].UpdatedAt.Equal(eligible[j].UpdatedAt) {
+			return eligible[i].UpdatedAt.Before(eligible[j].UpdatedAt)
+		}
+		return eligible[i].ID < eligible[j].ID
+	})
+	winner := eligible[0]
+	return &winner
+}
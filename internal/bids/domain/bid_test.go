@@ -1 +1,96 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateAuctionRequestValidate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	req := CreateAuctionRequest{ClosesAt: now.Add(48 * time.Hour)}
+	if err := req.Validate(now); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if req.Mode != ModeOpen {
+		t.Errorf("режим по умолчанию: ожидался open, получен %q", req.Mode)
+	}
+
+	zero := 0
+	invalid := map[string]CreateAuctionRequest{
+		"неизвестный режим": {Mode: "dutch", ClosesAt: now.Add(48 * time.Hour)},
+		"слишком короткие":  {ClosesAt: now.Add(10 * time.Minute)},
+		"слишком длинные":   {ClosesAt: now.Add(MaxAuctionDuration + time.Hour)},
+		"нулевая резервная": {ReservePrice: &zero, ClosesAt: now.Add(48 * time.Hour)},
+		"срок уже наступил": {ClosesAt: now.Add(-time.Hour)},
+	}
+	for name, r := range invalid {
+		if err := r.Validate(now); !errors.Is(err, ErrInvalidAuction) {
+			t.Errorf("%s: ожидалась ErrInvalidAuction, получено %v", name, err)
+		}
+	}
+}
+
+func TestCheckBid(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	auction := Auction{CustomerID: 1, Mode: ModeOpen, Status: AuctionRunning, ClosesAt: now.Add(time.Hour)}
+	previous := &Bid{ExecutorID: 2, Amount: 5000}
+
+	if err := auction.CheckBid(2, nil, 6000, now); err != nil {
+		t.Errorf("первая ставка: неожиданная ошибка %v", err)
+	}
+	if err := auction.CheckBid(2, previous, 4500, now); err != nil {
+		t.Errorf("понижение: неожиданная ошибка %v", err)
+	}
+	if err := auction.CheckBid(2, previous, 5000, now); !errors.Is(err, ErrBidNotLower) {
+		t.Errorf("ставка не ниже прежней: ожидалась ErrBidNotLower, получено %v", err)
+	}
+	if err := auction.CheckBid(1, nil, 4000, now); !errors.Is(err, ErrOwnAuction) {
+		t.Errorf("ставка заказчика: ожидалась ErrOwnAuction, получено %v", err)
+	}
+	if err := auction.CheckBid(2, nil, 4000, now.Add(2*time.Hour)); !errors.Is(err, ErrAuctionClosed) {
+		t.Errorf("ставка после срока: ожидалась ErrAuctionClosed, получено %v", err)
+	}
+}
+
+func TestSelectWinner(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	bids := []Bid{
+		{ID: 1, ExecutorID: 10, Amount: 5000, UpdatedAt: start},
+		{ID: 2, ExecutorID: 11, Amount: 4000, UpdatedAt: start.Add(2 * time.Minute)},
+		{ID: 3, ExecutorID: 12, Amount: 4000, UpdatedAt: start.Add(time.Minute)},
+	}
+
+	winner := SelectWinner(bids, nil)
+	if winner == nil || winner.ExecutorID != 12 {
+		t.Fatalf("при равных суммах побеждает более ранняя ставка, получено %+v", winner)
+	}
+
+	reserve := 3000
+	if winner := SelectWinner(bids, &reserve); winner != nil {
+		t.Errorf("все ставки выше резервной цены, а победитель выбран: %+v", winner)
+	}
+	if winner := SelectWinner(nil, nil); winner != nil {
+		t.Errorf("без ставок победителя быть не может: %+v", winner)
+	}
+}
+
+func TestAuctionViewFor(t *testing.T) {
+	reserve, lowest := 8000, 6000
+	sealed := Auction{CustomerID: 1, Mode: ModeSealed, Status: AuctionRunning, ReservePrice: &reserve, LowestBid: &lowest}
+
+	executorView := sealed.ViewFor(2)
+	if executorView.ReservePrice != nil || executorView.LowestBid != nil {
+		t.Errorf("исполнитель видит скрытые данные закрытых торгов: %+v", executorView)
+	}
+	customerView := sealed.ViewFor(1)
+	if customerView.ReservePrice == nil || customerView.LowestBid != nil {
+		t.Errorf("заказчик видит резерв, но не минимум до завершения: %+v", customerView)
+	}
+
+	sealed.Status = AuctionClosed
+	if sealed.ViewFor(2).LowestBid == nil {
+		t.Error("после завершения ставки раскрываются")
+	}
+}
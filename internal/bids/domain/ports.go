@@ -1 +1,41 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// BidsService — интерфейс для бизнес-логики торгов.
+type BidsService interface {
+	CreateAuction(ctx context.Context, taskID, userID int64, req CreateAuctionRequest) (*Auction, error)
+	GetAuction(ctx context.Context, auctionID, userID int64) (*Auction, error)
+	GetTaskAuction(ctx context.Context, taskID, userID int64) (*Auction, error)
+	PlaceBid(ctx context.Context, auctionID, userID int64, req PlaceBidRequest) (*Bid, error)
+	ListBids(ctx context.Context, auctionID, userID int64) ([]Bid, error) // В закрытых торгах до завершения — только свои ставки
+
+	CloseDueAuctions(ctx context.Context, now time.Time) error // Закрывает торги, срок которых истёк
+	RunAuctionCloser(ctx context.Context, interval time.Duration)
+}
+
+// BidsRepository — интерфейс для хранения торгов и ставок.
+type BidsRepository interface {
+	GetTaskInfo(ctx context.Context, taskID int64) (*TaskInfo, error) // ErrTaskNotFound
+	InsertAuction(ctx context.Context, a Auction) (int64, error)      // ErrAuctionExists
+	GetAuction(ctx context.Context, auctionID int64) (*Auction, error)
+	GetAuctionByTask(ctx context.Context, taskID int64) (*Auction, error)
+	GetBid(ctx context.Context, auctionID, executorID int64) (*Bid, error) // nil, nil, если исполнитель ещё не делал ставку
+	// SaveBid сохраняет ставку или понижает прежнюю. Торги блокируются на время записи;
+	// ErrAuctionClosed, если приём ставок завершён, ErrBidNotLower, если ставка не ниже прежней.
+	SaveBid(ctx context.Context, bid Bid) (Bid, error)
+	ListBids(ctx context.Context, auctionID int64) ([]Bid, error) // По возрастанию суммы
+
+	ListDueAuctions(ctx context.Context, now time.Time) ([]Auction, error)
+	CloseAuction(ctx context.Context, auctionID int64, winner *Bid, closedAt time.Time) (bool, error) // false, если торги уже закрыты
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
+}
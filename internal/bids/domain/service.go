@@ -1 +1,205 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/bids"
+)
+
+type bidsService struct {
+	repo BidsRepository
+	bus  EventBus
+}
+
+// NewBidsService создаёт сервис торгов.
+func NewBidsService(repo BidsRepository, bus EventBus) BidsService {
+	return &bidsService{repo: repo, bus: bus}
+}
+
+// CreateAuction запускает торги по открытой задаче заказчика.
+func (s *bidsService) CreateAuction(ctx context.Context, taskID, userID int64, req CreateAuctionRequest) (*Auction, error) {
+	now := time.Now()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+
+	task, err := s.repo.GetTaskInfo(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.CanStart(userID); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.InsertAuction(ctx, Auction{
+		TaskID:       taskID,
+		CustomerID:   userID,
+		Mode:         req.Mode,
+		Status:       AuctionRunning,
+		ReservePrice: req.ReservePrice,
+		ClosesAt:     req.ClosesAt,
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetAuction(ctx, id, userID)
+}
+
+// GetAuction возвращает торги с учётом того, что пользователю разрешено видеть.
+func (s *bidsService) GetAuction(ctx context.Context, auctionID, userID int64) (*Auction, error) {
+	auction, err := s.repo.GetAuction(ctx, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	view := auction.ViewFor(userID)
+	return &view, nil
+}
+
+// GetTaskAuction возвращает торги по задаче.
+func (s *bidsService) GetTaskAuction(ctx context.Context, taskID, userID int64) (*Auction, error) {
+	auction, err := s.repo.GetAuctionByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	view := auction.ViewFor(userID)
+	return &view, nil
+}
+
+// PlaceBid принимает ставку исполнителя или понижение его прежней ставки.
+func (s *bidsService) PlaceBid(ctx context.Context, auctionID, userID int64, req PlaceBidRequest) (*Bid, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	auction, err := s.repo.GetAuction(ctx, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repo.GetBid(ctx, auctionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := auction.CheckBid(userID, previous, req.Amount, now); err != nil {
+		return nil, err
+	}
+
+	bid, err := s.repo.SaveBid(ctx, Bid{
+		AuctionID:  auctionID,
+		ExecutorID: userID,
+		Amount:     req.Amount,
+		Comment:    req.Comment,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event := bids.BidPlacedEvent{
+		AuctionID:  auction.ID,
+		TaskID:     auction.TaskID,
+		CustomerID: auction.CustomerID,
+		BidID:      bid.ID,
+		ExecutorID: userID,
+		Lowered:    previous != nil,
+	}
+	if auction.Mode == ModeOpen {
+		event.Amount = &bid.Amount
+	}
+	s.bus.Publish(event)
+	return &bid, nil
+}
+
+// ListBids возвращает ставки. Пока закрытые торги идут, каждый видит только свои ставки,
+// а заказчик — пустой список и число ставок в самих торгах.
+func (s *bidsService) ListBids(ctx context.Context, auctionID, userID int64) ([]Bid, error) {
+	auction, err := s.repo.GetAuction(ctx, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.repo.ListBids(ctx, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if auction.BidsVisible() {
+		return all, nil
+	}
+
+	own := []Bid{}
+	for _, b := range all {
+		if b.ExecutorID == userID {
+			own = append(own, b)
+		}
+	}
+	return own, nil
+}
+
+// CloseDueAuctions закрывает торги с истёкшим сроком и определяет победителей.
+func (s *bidsService) CloseDueAuctions(ctx context.Context, now time.Time) error {
+	due, err := s.repo.ListDueAuctions(ctx, now)
+	if err != nil {
+		return fmt.Errorf("не удалось получить торги с истёкшим сроком: %w", err)
+	}
+
+	for _, auction := range due {
+		if err := s.closeAuction(ctx, auction, now); err != nil {
+			slog.Error("[Bids] Не удалось закрыть торги", "auction_id", auction.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// RunAuctionCloser периодически закрывает торги с истёкшим сроком, пока не отменён ctx.
+func (s *bidsService) RunAuctionCloser(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.CloseDueAuctions(ctx, now); err != nil {
+				slog.Error("[Bids] Ошибка закрытия торгов", "error", err)
+			}
+		}
+	}
+}
+
+func (s *bidsService) closeAuction(ctx context.Context, auction Auction, now time.Time) error {
+	all, err := s.repo.ListBids(ctx, auction.ID)
+	if err != nil {
+		return err
+	}
+	winner := SelectWinner(all, auction.ReservePrice)
+
+	closed, err := s.repo.CloseAuction(ctx, auction.ID, winner, now)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return nil
+	}
+
+	event := bids.AuctionClosedEvent{
+		AuctionID:  auction.ID,
+		TaskID:     auction.TaskID,
+		CustomerID: auction.CustomerID,
+		BidderIDs:  make([]int64, 0, len(all)),
+	}
+	for _, b := range all {
+		event.BidderIDs = append(event.BidderIDs, b.ExecutorID)
+	}
+	if winner != nil {
+		event.WinnerID = &winner.ExecutorID
+		event.WinningBidID = &winner.ID
+		event.WinningAmount = &winner.Amount
+	}
+	s.bus.Publish(event)
+	return nil
+}
@@ -1 +1,25 @@
-package bids
\ No newline at end of file
+package bids
+
+// BidPlacedEvent — событие новой ставки или её понижения.
+// В закрытых торгах сумма не раскрывается заказчику до завершения, поэтому Amount передаётся только для открытых.
+type BidPlacedEvent struct {
+	AuctionID  int64
+	TaskID     int64
+	CustomerID int64
+	BidID      int64
+	ExecutorID int64
+	Amount     *int
+	Lowered    bool // true, если исполнитель понизил свою прежнюю ставку
+}
+
+// AuctionClosedEvent — событие завершения торгов. Если победитель определён,
+// модуль задач заключает с ним контракт на сумму выигравшей ставки.
+type AuctionClosedEvent struct {
+	AuctionID     int64
+	TaskID        int64
+	CustomerID    int64
+	WinnerID      *int64 // nil, если ставок не было или все они выше резервной цены
+	WinningBidID  *int64
+	WinningAmount *int
+	BidderIDs     []int64 // Все участники торгов
+}
@@ -1 +1,215 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/bids/domain"
+)
+
+// auctionColumns — поля торгов в порядке, который ожидает scanAuction.
+const auctionColumns = `
+        a.id, a.task_id, a.customer_id, a.mode, a.status, a.reserve_price, a.closes_at, a.created_at, a.closed_at,
+        a.winning_bid_id, a.winner_id, a.winning_amount,
+        (SELECT COUNT(*) FROM bids b WHERE b.auction_id = a.id),
+        (SELECT MIN(b.amount) FROM bids b WHERE b.auction_id = a.id)`
+
+// BidsRepository хранит торги и ставки в PostgreSQL.
+type BidsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewBidsRepository создаёт новый репозиторий торгов.
+func NewBidsRepository(db *pgxpool.Pool) *BidsRepository {
+	return &BidsRepository{db: db}
+}
+
+// GetTaskInfo получает заказчика и статус задачи.
+func (r *BidsRepository) GetTaskInfo(ctx context.Context, taskID int64) (*domain.TaskInfo, error) {
+	task := domain.TaskInfo{ID: taskID}
+	err := r.db.QueryRow(ctx, `SELECT user_id, status_code FROM tasks WHERE id = $1`, taskID).Scan(&task.CustomerID, &task.StatusCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении задачи с ID %d: %w", taskID, err)
+	}
+	return &task, nil
+}
+
+// InsertAuction сохраняет новые торги. По задаче может быть только одни торги.
+func (r *BidsRepository) InsertAuction(ctx context.Context, a domain.Auction) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO auctions (task_id, customer_id, mode, status, reserve_price, closes_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id`,
+		a.TaskID, a.CustomerID, a.Mode, a.Status, a.ReservePrice, a.ClosesAt, a.CreatedAt).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, domain.ErrAuctionExists
+		}
+		return 0, fmt.Errorf("ошибка при создании торгов: %w", err)
+	}
+	return id, nil
+}
+
+// GetAuction получает торги по идентификатору.
+func (r *BidsRepository) GetAuction(ctx context.Context, auctionID int64) (*domain.Auction, error) {
+	return r.getAuction(ctx, `SELECT `+auctionColumns+` FROM auctions a WHERE a.id = $1`, auctionID)
+}
+
+// GetAuctionByTask получает торги по задаче.
+func (r *BidsRepository) GetAuctionByTask(ctx context.Context, taskID int64) (*domain.Auction, error) {
+	return r.getAuction(ctx, `SELECT `+auctionColumns+` FROM auctions a WHERE a.task_id = $1`, taskID)
+}
+
+// GetBid получает ставку исполнителя на торгах.
+func (r *BidsRepository) GetBid(ctx context.Context, auctionID, executorID int64) (*domain.Bid, error) {
+	var b domain.Bid
+	err := r.db.QueryRow(ctx, `
+        SELECT id, auction_id, executor_id, amount, comment, created_at, updated_at
+        FROM bids
+        WHERE auction_id = $1 AND executor_id = $2`, auctionID, executorID).Scan(
+		&b.ID, &b.AuctionID, &b.ExecutorID, &b.Amount, &b.Comment, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении ставки: %w", err)
+	}
+	return &b, nil
+}
+
+// SaveBid сохраняет ставку или понижает прежнюю ставку исполнителя.
+func (r *BidsRepository) SaveBid(ctx context.Context, bid domain.Bid) (domain.Bid, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.Bid{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка торгов не даёт принять ставку параллельно с их закрытием.
+	var status domain.AuctionStatus
+	var closesAt time.Time
+	err = tx.QueryRow(ctx, `SELECT status, closes_at FROM auctions WHERE id = $1 FOR UPDATE`, bid.AuctionID).Scan(&status, &closesAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Bid{}, domain.ErrAuctionNotFound
+		}
+		return domain.Bid{}, fmt.Errorf("ошибка при блокировке торгов: %w", err)
+	}
+	if status != domain.AuctionRunning || !bid.UpdatedAt.Before(closesAt) {
+		return domain.Bid{}, domain.ErrAuctionClosed
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO bids (auction_id, executor_id, amount, comment, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (auction_id, executor_id) DO UPDATE
+        SET amount = EXCLUDED.amount, comment = EXCLUDED.comment, updated_at = EXCLUDED.updated_at
+        WHERE bids.amount > EXCLUDED.amount
+        RETURNING id, created_at`,
+		bid.AuctionID, bid.ExecutorID, bid.Amount, bid.Comment, bid.CreatedAt, bid.UpdatedAt).Scan(&bid.ID, &bid.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Bid{}, domain.ErrBidNotLower
+		}
+		return domain.Bid{}, fmt.Errorf("ошибка при сохранении ставки: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Bid{}, fmt.Errorf("не удалось сохранить ставку: %w", err)
+	}
+	return bid, nil
+}
+
+// ListBids получает ставки торгов по возрастанию суммы, при равных — по времени.
+func (r *BidsRepository) ListBids(ctx context.Context, auctionID int64) ([]domain.Bid, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, auction_id, executor_id, amount, comment, created_at, updated_at
+        FROM bids
+        WHERE auction_id = $1
+        ORDER BY amount, updated_at, id`, auctionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ставок: %w", err)
+	}
+	defer rows.Close()
+
+	bids := []domain.Bid{}
+	for rows.Next() {
+		var b domain.Bid
+		if err := rows.Scan(&b.ID, &b.AuctionID, &b.ExecutorID, &b.Amount, &b.Comment, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ставки: %w", err)
+		}
+		bids = append(bids, b)
+	}
+	return bids, rows.Err()
+}
+
+// ListDueAuctions получает идущие торги, срок которых истёк.
+func (r *BidsRepository) ListDueAuctions(ctx context.Context, now time.Time) ([]domain.Auction, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+auctionColumns+`
+        FROM auctions a
+        WHERE a.status = $1 AND a.closes_at <= $2
+        ORDER BY a.closes_at, a.id`, domain.AuctionRunning, now)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении торгов с истёкшим сроком: %w", err)
+	}
+	defer rows.Close()
+
+	auctions := []domain.Auction{}
+	for rows.Next() {
+		a, err := scanAuction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании торгов: %w", err)
+		}
+		auctions = append(auctions, a)
+	}
+	return auctions, rows.Err()
+}
+
+// CloseAuction закрывает торги и записывает победителя. Возвращает false, если торги уже закрыты.
+func (r *BidsRepository) CloseAuction(ctx context.Context, auctionID int64, winner *domain.Bid, closedAt time.Time) (bool, error) {
+	var bidID, winnerID *int64
+	var amount *int
+	if winner != nil {
+		bidID, winnerID, amount = &winner.ID, &winner.ExecutorID, &winner.Amount
+	}
+
+	tag, err := r.db.Exec(ctx, `
+        UPDATE auctions
+        SET status = $2, closed_at = $3, winning_bid_id = $4, winner_id = $5, winning_amount = $6
+        WHERE id = $1 AND status = $7`,
+		auctionID, domain.AuctionClosed, closedAt, bidID, winnerID, amount, domain.AuctionRunning)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при закрытии торгов с ID %d: %w", auctionID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *BidsRepository) getAuction(ctx context.Context, query string, id int64) (*domain.Auction, error) {
+	a, err := scanAuction(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAuctionNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении торгов: %w", err)
+	}
+	return &a, nil
+}
+
+func scanAuction(row pgx.Row) (domain.Auction, error) {
+	var a domain.Auction
+	err := row.Scan(&a.ID, &a.TaskID, &a.CustomerID, &a.Mode, &a.Status, &a.ReservePrice, &a.ClosesAt, &a.CreatedAt, &a.ClosedAt,
+		&a.WinningBidID, &a.WinnerID, &a.WinningAmount, &a.BidCount, &a.LowestBid)
+	return a, err
+}
@@ -9,10 +9,14 @@ import (
 
 	"github.com/unclaim/chegonado.git/internal/chat/domain"
 	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 	"github.com/unclaim/chegonado.git/internal/shared/utils"
 	"github.com/unclaim/chegonado.git/pkg/security/session"
 )
 
+// messagesPageLimit — размер страницы сообщений по умолчанию.
+const messagesPageLimit = 50
+
 // ChatHandler отвечает за обработку HTTP-запросов, связанных с чатом.
 type ChatHandler struct {
 	chatService *domain.ChatService
@@ -56,9 +60,19 @@ func (h *ChatHandler) GetArchivedMessages(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	messages, err := h.chatService.GetArchiveMessages(ctx, sess.UserID)
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), messagesPageLimit)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.chatService.GetArchiveMessages(ctx, sess.UserID, page)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка загрузки архивированных сообщений: %w", err), http.StatusInternalServerError)
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		} else {
+			common_errors.NewAppError(w, r, fmt.Errorf("ошибка загрузки архивированных сообщений: %w", err), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -74,9 +88,19 @@ func (h *ChatHandler) GetInboxMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := h.chatService.GetInboxMessages(ctx, sess.UserID)
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), messagesPageLimit)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка загрузки сообщений: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.chatService.GetInboxMessages(ctx, sess.UserID, page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		} else {
+			common_errors.NewAppError(w, r, fmt.Errorf("ошибка загрузки сообщений: %w", err), http.StatusInternalServerError)
+		}
 		return
 	}
 
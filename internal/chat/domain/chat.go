@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 )
 
 // Message представляет собой структуру сообщения.
@@ -37,15 +39,15 @@ type MarkAsReadRequest struct {
 // ChatServicePort — интерфейс для бизнес-логики.
 type ChatServicePort interface {
 	SendMessage(ctx context.Context, senderID int64, req MessageRequest) error
-	GetInboxMessages(ctx context.Context, userID int64) ([]Message, error)
-	GetArchiveMessages(ctx context.Context, userID int64) ([]Message, error)
+	GetInboxMessages(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error)
+	GetArchiveMessages(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error)
 	MarkMessageAsRead(ctx context.Context, messageID int64) error
 }
 
 // ChatRepositoryPort — интерфейс для работы с хранилищем данных.
 type ChatRepositoryPort interface {
 	CreateMessage(ctx context.Context, message Message) error
-	FindUnreadMessagesByUserID(ctx context.Context, userID int64) ([]Message, error)
-	FindReadMessagesByUserID(ctx context.Context, userID int64) ([]Message, error)
+	FindUnreadMessagesByUserID(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error)
+	FindReadMessagesByUserID(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error)
 	UpdateMessageAsRead(ctx context.Context, messageID int64) error
 }
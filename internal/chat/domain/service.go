@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 )
 
 var ErrMessageTooLong = errors.New("сообщение слишком длинное")
@@ -36,14 +38,14 @@ func (s *ChatService) SendMessage(ctx context.Context, senderID int64, req Messa
 	return s.repo.CreateMessage(ctx, message)
 }
 
-// GetInboxMessages получает непрочитанные сообщения пользователя.
-func (s *ChatService) GetInboxMessages(ctx context.Context, userID int64) ([]Message, error) {
-	return s.repo.FindUnreadMessagesByUserID(ctx, userID)
+// GetInboxMessages получает непрочитанные сообщения пользователя, старые первыми.
+func (s *ChatService) GetInboxMessages(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error) {
+	return s.repo.FindUnreadMessagesByUserID(ctx, userID, page)
 }
 
-// GetArchiveMessages получает прочитанные (архивные) сообщения пользователя.
-func (s *ChatService) GetArchiveMessages(ctx context.Context, userID int64) ([]Message, error) {
-	return s.repo.FindReadMessagesByUserID(ctx, userID)
+// GetArchiveMessages получает прочитанные (архивные) сообщения пользователя, новые первыми.
+func (s *ChatService) GetArchiveMessages(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Message], error) {
+	return s.repo.FindReadMessagesByUserID(ctx, userID, page)
 }
 
 // MarkMessageAsRead помечает сообщение как прочитанное.
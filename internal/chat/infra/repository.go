@@ -6,6 +6,7 @@ import (
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/unclaim/chegonado.git/internal/chat/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 )
 
 // ChatRepository реализует интерфейс ChatRepositoryPort.
@@ -48,50 +49,50 @@ func (r *ChatRepository) CreateMessage(ctx context.Context, message domain.Messa
 	return nil
 }
 
+// Порядки сортировки списков сообщений, для которых выдаются курсоры.
+const (
+	inboxSort   = "oldest"
+	archiveSort = "newest"
+)
+
 // FindUnreadMessagesByUserID находит непрочитанные сообщения для пользователя.
-func (r *ChatRepository) FindUnreadMessagesByUserID(ctx context.Context, userID int64) ([]domain.Message, error) {
-	query := `SELECT m.id, m.sender_id, m.content, m.created_at, m.is_read, u.id, u.username, u.avatar_url 
-	FROM messages m 
-	JOIN users u ON m.sender_id = u.id 
-	WHERE m.recipient_id = $1 AND m.is_read = FALSE 
-	ORDER BY m.created_at ASC`
-
-	rows, err := r.db.Query(ctx, query, userID)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при выполнении запроса к базе данных: %w", err)
+func (r *ChatRepository) FindUnreadMessagesByUserID(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[domain.Message], error) {
+	return r.findMessages(ctx, userID, false, page)
+}
+
+// FindReadMessagesByUserID находит прочитанные (архивные) сообщения для пользователя.
+func (r *ChatRepository) FindReadMessagesByUserID(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[domain.Message], error) {
+	return r.findMessages(ctx, userID, true, page)
+}
+
+// findMessages выбирает страницу входящих сообщений: непрочитанные идут от старых к новым,
+// архив — от новых к старым.
+func (r *ChatRepository) findMessages(ctx context.Context, userID int64, isRead bool, page pagination.Request) (pagination.Page[domain.Message], error) {
+	sort, cmp, direction := inboxSort, ">", "ASC"
+	if isRead {
+		sort, cmp, direction = archiveSort, "<", "DESC"
 	}
-	defer rows.Close()
 
-	var messages []domain.Message
-	for rows.Next() {
-		var msg domain.Message
-		var user domain.User
-		if err := rows.Scan(&msg.ID, &msg.SenderID, &msg.Content, &msg.CreatedAt, &msg.IsRead, &user.ID, &user.Username, &user.AvatarURL); err != nil {
-			// Логируем ошибку и продолжаем, чтобы не падать на одном "битом" сообщении
-			fmt.Printf("ошибка при разборе данных сообщения: %v", err)
-			continue
+	args := []interface{}{userID, isRead, page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil || page.After.Sort != sort {
+			return pagination.Page[domain.Message]{}, pagination.ErrInvalidCursor
 		}
-		msg.Sender = user
-		messages = append(messages, msg)
+		keyset = fmt.Sprintf("AND (m.created_at, m.id) %s ($4, $5)", cmp)
+		args = append(args, *page.After.Time, page.After.ID)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка при закрытии соединений: %w", err)
-	}
-	return messages, nil
-}
+	query := fmt.Sprintf(`SELECT m.id, m.sender_id, m.content, m.created_at, m.is_read, u.id, u.username, u.avatar_url
+	FROM messages m
+	JOIN users u ON m.sender_id = u.id
+	WHERE m.recipient_id = $1 AND m.is_read = $2 %s
+	ORDER BY m.created_at %s, m.id %s
+	LIMIT $3`, keyset, direction, direction)
 
-// FindReadMessagesByUserID находит прочитанные (архивные) сообщения для пользователя.
-func (r *ChatRepository) FindReadMessagesByUserID(ctx context.Context, userID int64) ([]domain.Message, error) {
-	query := `SELECT m.id, m.sender_id, m.content, m.created_at, m.is_read, u.id, u.username, u.avatar_url 
-	FROM messages m 
-	JOIN users u ON m.sender_id = u.id 
-	WHERE m.recipient_id = $1 AND m.is_read = TRUE 
-	ORDER BY m.created_at DESC`
-
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return pagination.Page[domain.Message]{}, fmt.Errorf("ошибка при выполнении запроса к базе данных: %w", err)
 	}
 	defer rows.Close()
 
@@ -100,17 +101,19 @@ func (r *ChatRepository) FindReadMessagesByUserID(ctx context.Context, userID in
 		var msg domain.Message
 		var user domain.User
 		if err := rows.Scan(&msg.ID, &msg.SenderID, &msg.Content, &msg.CreatedAt, &msg.IsRead, &user.ID, &user.Username, &user.AvatarURL); err != nil {
-			fmt.Printf("ошибка при разборе данных сообщения: %v", err)
-			continue
+			return pagination.Page[domain.Message]{}, fmt.Errorf("ошибка при разборе данных сообщения: %w", err)
 		}
+		msg.RecipientID = userID
 		msg.Sender = user
 		messages = append(messages, msg)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка при закрытии соединений: %w", err)
+		return pagination.Page[domain.Message]{}, fmt.Errorf("ошибка при закрытии соединений: %w", err)
 	}
-	return messages, nil
+	return pagination.NewPage(messages, page, func(m domain.Message) pagination.Cursor {
+		return pagination.TimeCursor(sort, m.CreatedAt, m.ID)
+	}), nil
 }
 
 // UpdateMessageAsRead обновляет статус сообщения.
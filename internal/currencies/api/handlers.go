@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/unclaim/chegonado.git/internal/currencies/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// CurrenciesHandler отвечает за обработку HTTP-запросов к курсам валют.
+type CurrenciesHandler struct {
+	service domain.CurrenciesService
+}
+
+// NewCurrenciesHandler создаёт новый экземпляр CurrenciesHandler.
+func NewCurrenciesHandler(service domain.CurrenciesService) *CurrenciesHandler {
+	return &CurrenciesHandler{service: service}
+}
+
+// rateRequest — тело запроса на изменение курса.
+type rateRequest struct {
+	Rate string `json:"rate"`
+}
+
+// ListRates возвращает курсы валют к базовой.
+func (h *CurrenciesHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListRates(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, err, currencyErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, rates)
+}
+
+// SetRate задаёт курс валюты. Доступно администраторам.
+func (h *CurrenciesHandler) SetRate(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req rateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	rate, err := h.service.SetRate(r.Context(), sess.UserID, r.PathValue("currency"), req.Rate)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, currencyErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, rate)
+}
+
+func currencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrAdminOnly):
+		return http.StatusForbidden
+	case errors.Is(err, money.ErrUnknownCurrency), errors.Is(err, money.ErrInvalidRate), errors.Is(err, domain.ErrBaseCurrencyRate):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// CurrenciesService — интерфейс для бизнес-логики курсов валют.
+type CurrenciesService interface {
+	ListRates(ctx context.Context) ([]money.Rate, error)
+	SetRate(ctx context.Context, userID int64, currency, rate string) (*money.Rate, error) // Только администраторам
+	// ToBase пересчитывает сумму в базовую валюту по текущим курсам.
+	ToBase(ctx context.Context, m money.Money) (int, error)
+}
+
+// CurrenciesRepository — интерфейс для хранения курсов валют.
+type CurrenciesRepository interface {
+	ListRates(ctx context.Context) ([]money.Rate, error)
+	// SaveRate записывает курс и в той же транзакции пересчитывает в базовую валюту цены задач в этой валюте,
+	// чтобы поиск и сортировка по цене сразу учитывали новый курс.
+	SaveRate(ctx context.Context, rate money.Rate) (*money.Rate, error)
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// AdminUserType — тип пользователя, которому доступно изменение курсов.
+const AdminUserType = "ADMIN"
+
+var (
+	ErrAdminOnly        = errors.New("действие доступно только администраторам")
+	ErrBaseCurrencyRate = errors.New("курс базовой валюты всегда равен единице")
+)
+
+type currenciesService struct {
+	repo CurrenciesRepository
+}
+
+// NewCurrenciesService создаёт сервис курсов валют.
+func NewCurrenciesService(repo CurrenciesRepository) CurrenciesService {
+	return &currenciesService{repo: repo}
+}
+
+// ListRates возвращает курсы всех валют к базовой.
+func (s *currenciesService) ListRates(ctx context.Context) ([]money.Rate, error) {
+	return s.repo.ListRates(ctx)
+}
+
+// SetRate задаёт курс валюты к базовой в десятичной записи, например "92.5".
+func (s *currenciesService) SetRate(ctx context.Context, userID int64, currency, rate string) (*money.Rate, error) {
+	isAdmin, err := s.repo.IsAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrAdminOnly
+	}
+
+	c, err := money.ParseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if c == money.Base {
+		return nil, ErrBaseCurrencyRate
+	}
+	micros, err := money.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.SaveRate(ctx, money.Rate{
+		Currency:  c,
+		Micros:    micros,
+		Rate:      money.FormatRate(micros),
+		UpdatedBy: &userID,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// ToBase пересчитывает сумму в базовую валюту. Сумма в базовой валюте возвращается без обращения к базе.
+func (s *currenciesService) ToBase(ctx context.Context, m money.Money) (int, error) {
+	if m.Currency == money.Base {
+		return m.Amount, nil
+	}
+	rates, err := s.repo.ListRates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить курсы валют: %w", err)
+	}
+	return money.NewRates(rates).ToBase(m)
+}
@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/currencies/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// rateColumns — поля курса в порядке, который ожидает scanRate.
+const rateColumns = `currency, rate_micros, updated_by, updated_at`
+
+// CurrenciesRepository хранит курсы валют в PostgreSQL.
+type CurrenciesRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewCurrenciesRepository создаёт новый репозиторий курсов валют.
+func NewCurrenciesRepository(db *pgxpool.Pool) *CurrenciesRepository {
+	return &CurrenciesRepository{db: db}
+}
+
+// ListRates возвращает курсы всех валют в алфавитном порядке.
+func (r *CurrenciesRepository) ListRates(ctx context.Context) ([]money.Rate, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+rateColumns+` FROM currency_rates ORDER BY currency`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении курсов валют: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []money.Rate{}
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании курса валюты: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// SaveRate записывает курс и пересчитывает цены задач в этой валюте: cost_base = cost * курс
+// с поправкой на разную длину дробной части, с округлением половины вверх, как в money.Rates.
+// Расчёт ведётся в numeric: у double precision округление половины зависит от платформы.
+func (r *CurrenciesRepository) SaveRate(ctx context.Context, rate money.Rate) (*money.Rate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved, err := scanRate(tx.QueryRow(ctx, `
+        INSERT INTO currency_rates (currency, rate_micros, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (currency) DO UPDATE SET
+            rate_micros = EXCLUDED.rate_micros,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+        RETURNING `+rateColumns, rate.Currency, rate.Micros, rate.UpdatedBy, rate.UpdatedAt))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении курса %s: %w", rate.Currency, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE tasks
+        SET cost_base = ROUND(cost::numeric * $2::numeric * POWER(10::numeric, $3::int)
+                              / ($4::numeric * POWER(10::numeric, $5::int)))
+        WHERE cost_currency = $1 AND cost IS NOT NULL`,
+		rate.Currency, rate.Micros, money.Base.Exponent(), money.RateScale, rate.Currency.Exponent())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при пересчёте цен задач в %s: %w", rate.Currency, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить курс %s: %w", rate.Currency, err)
+	}
+	return &saved, nil
+}
+
+// IsAdmin проверяет, может ли пользователь менять курсы.
+func (r *CurrenciesRepository) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND type = $2)`,
+		userID, domain.AdminUserType).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке прав пользователя %d: %w", userID, err)
+	}
+	return ok, nil
+}
+
+func scanRate(row pgx.Row) (money.Rate, error) {
+	var rate money.Rate
+	err := row.Scan(&rate.Currency, &rate.Micros, &rate.UpdatedBy, &rate.UpdatedAt)
+	rate.Rate = money.FormatRate(rate.Micros)
+	return rate, err
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/disputes/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// DisputesHandler отвечает за обработку HTTP-запросов, связанных со спорами по контрактам.
+type DisputesHandler struct {
+	service domain.DisputesService
+}
+
+// NewDisputesHandler создаёт новый экземпляр DisputesHandler.
+func NewDisputesHandler(service domain.DisputesService) *DisputesHandler {
+	return &DisputesHandler{service: service}
+}
+
+// OpenDispute открывает спор по контракту от имени заказчика или исполнителя.
+func (h *DisputesHandler) OpenDispute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	var req domain.OpenDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	dispute, err := h.service.OpenDispute(ctx, contractID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, dispute)
+}
+
+// GetDispute возвращает спор с доказательствами.
+func (h *DisputesHandler) GetDispute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.GetDispute(ctx, disputeID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, dispute)
+}
+
+// AddEvidence прикладывает к спору доказательство стороны.
+func (h *DisputesHandler) AddEvidence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.EvidenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	evidence, err := h.service.AddEvidence(ctx, disputeID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, evidence)
+}
+
+// GetFeed возвращает ленту статусов спора.
+func (h *DisputesHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	feed, err := h.service.GetFeed(ctx, disputeID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, feed)
+}
+
+// ListForArbitration возвращает очередь споров для арбитра. Фильтр — параметр status.
+func (h *DisputesHandler) ListForArbitration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	status := domain.DisputeStatus(r.URL.Query().Get("status"))
+	disputes, err := h.service.ListForArbitration(ctx, sess.UserID, status)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, disputes)
+}
+
+// GetCase возвращает арбитру материалы спора.
+func (h *DisputesHandler) GetCase(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	disputeCase, err := h.service.GetCase(ctx, disputeID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, disputeCase)
+}
+
+// AssignDispute назначает текущего арбитра на спор.
+func (h *DisputesHandler) AssignDispute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	dispute, err := h.service.AssignDispute(ctx, disputeID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, dispute)
+}
+
+// ResolveDispute выносит решение арбитра по спору.
+func (h *DisputesHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, disputeID, ok := disputeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var res domain.Resolution
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	dispute, err := h.service.ResolveDispute(ctx, disputeID, sess.UserID, res)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, disputeErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, dispute)
+}
+
+// disputeRequest достаёт сессию и идентификатор спора из запроса. При ошибке ответ уже записан.
+func disputeRequest(w http.ResponseWriter, r *http.Request) (*session.Session, int64, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	disputeID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор спора: %w", err), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return sess, disputeID, true
+}
+
+func disputeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDisputeNotFound), errors.Is(err, domain.ErrContractNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotParty), errors.Is(err, domain.ErrNotArbitrator):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidDispute), errors.Is(err, domain.ErrInvalidResolution), errors.Is(err, domain.ErrEvidenceLimit):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrDisputeExists), errors.Is(err, domain.ErrDisputeResolved),
+		errors.Is(err, domain.ErrContractClosed), errors.Is(err, domain.ErrAssignedToOther):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
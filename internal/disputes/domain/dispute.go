@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// DisputeStatus — стадия рассмотрения спора.
+type DisputeStatus string
+
+const (
+	DisputeOpen     DisputeStatus = "open"      // Спор открыт и ждёт арбитра
+	DisputeInReview DisputeStatus = "in_review" // Арбитр взял спор в работу
+	DisputeResolved DisputeStatus = "resolved"  // Арбитр вынес решение
+)
+
+// IsValid сообщает, является ли статус известной стадией спора.
+func (s DisputeStatus) IsValid() bool {
+	switch s {
+	case DisputeOpen, DisputeInReview, DisputeResolved:
+		return true
+	}
+	return false
+}
+
+// Outcome — решение арбитра по спору.
+type Outcome string
+
+const (
+	OutcomeComplete Outcome = "complete" // Работа принята полностью
+	OutcomePartial  Outcome = "partial"  // Работа принята частично, исполнителю причитается часть суммы
+	OutcomeCancel   Outcome = "cancel"   // Контракт расторгается, задача отменяется
+)
+
+// FeedKind — вид записи в ленте статусов спора.
+type FeedKind string
+
+const (
+	FeedOpened      FeedKind = "opened"
+	FeedEvidence    FeedKind = "evidence_added"
+	FeedAssigned    FeedKind = "assigned"
+	FeedSLABreached FeedKind = "sla_breached"
+	FeedResolved    FeedKind = "resolved"
+)
+
+const (
+	// ResolutionSLA — за какое время с момента открытия арбитр должен вынести решение.
+	ResolutionSLA = 72 * time.Hour
+	// ArbitratorUserType — тип пользователя, которому доступен арбитраж.
+	ArbitratorUserType = "ADMIN"
+	// MaxEvidencePerDispute — сколько доказательств можно приложить к одному спору.
+	MaxEvidencePerDispute = 50
+
+	minReasonLength   = 10
+	maxReasonLength   = 2000
+	maxEvidenceLength = 2000
+)
+
+var (
+	ErrDisputeNotFound   = errors.New("спор не найден")
+	ErrContractNotFound  = errors.New("контракт не найден")
+	ErrNotParty          = errors.New("спор доступен только сторонам контракта")
+	ErrNotArbitrator     = errors.New("действие доступно только арбитру")
+	ErrContractClosed    = errors.New("по закрытому контракту спор открыть нельзя")
+	ErrDisputeExists     = errors.New("по контракту уже есть нерассмотренный спор")
+	ErrDisputeResolved   = errors.New("спор уже рассмотрен")
+	ErrInvalidDispute    = errors.New("некорректный спор")
+	ErrInvalidResolution = errors.New("некорректное решение по спору")
+	ErrEvidenceLimit     = fmt.Errorf("к спору можно приложить не больше %d доказательств", MaxEvidencePerDispute)
+	ErrAssignedToOther   = errors.New("спор рассматривает другой арбитр")
+)
+
+// Dispute — спор по контракту между заказчиком и исполнителем.
+type Dispute struct {
+	ID                int64         `json:"id"`
+	ContractID        int64         `json:"contract_id"`
+	TaskID            int64         `json:"task_id"`
+	OpenedBy          int64         `json:"opened_by"`
+	CustomerID        int64         `json:"customer_id"`
+	ExecutorID        int64         `json:"executor_id"`
+	Reason            string        `json:"reason"`
+	Status            DisputeStatus `json:"status"`
+	ArbitratorID      *int64        `json:"arbitrator_id"`
+	Outcome           *Outcome      `json:"outcome"`
+	SettledAmount     *int          `json:"settled_amount"` // Сумма исполнителю при частичном решении
+	ResolutionComment *string       `json:"resolution_comment"`
+	DueAt             time.Time     `json:"due_at"`          // Срок решения по SLA
+	SLABreachedAt     *time.Time    `json:"sla_breached_at"` // Когда срок был нарушен
+	CreatedAt         time.Time     `json:"created_at"`
+	ResolvedAt        *time.Time    `json:"resolved_at"`
+	Evidence          []Evidence    `json:"evidence"`
+}
+
+// Evidence — доказательство стороны: описание и необязательная ссылка на файл или переписку.
+type Evidence struct {
+	ID          int64     `json:"id"`
+	DisputeID   int64     `json:"dispute_id"`
+	AuthorID    int64     `json:"author_id"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FeedEntry — запись в ленте статусов спора, которую видят обе стороны.
+type FeedEntry struct {
+	ID        int64     `json:"id"`
+	DisputeID int64     `json:"dispute_id"`
+	ActorID   *int64    `json:"actor_id"` // nil для системных записей, например о нарушении SLA
+	Kind      FeedKind  `json:"kind"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContractParties — сведения о контракте, нужные для открытия спора.
+type ContractParties struct {
+	ContractID int64          `json:"contract_id"`
+	TaskID     int64          `json:"task_id"`
+	CustomerID int64          `json:"customer_id"`
+	ExecutorID int64          `json:"executor_id"`
+	IsActive   bool           `json:"is_active"`
+	Price      *int           `json:"price"`    // В минимальных единицах Currency
+	Currency   money.Currency `json:"currency"` // Валюта цены контракта
+}
+
+// IsParty сообщает, является ли пользователь стороной контракта.
+func (c ContractParties) IsParty(userID int64) bool {
+	return userID == c.CustomerID || userID == c.ExecutorID
+}
+
+// CaseMilestone — этап контракта с отчётом, как его видит арбитр.
+type CaseMilestone struct {
+	Title                string     `json:"title"`
+	Amount               int        `json:"amount"`
+	DueDate              *time.Time `json:"due_date"`
+	ExecutorComments     *string    `json:"executor_comments"`
+	CustomerFeedback     *string    `json:"customer_feedback"`
+	CustomerConfirmation *bool      `json:"customer_confirmation"`
+	ReportedAt           *time.Time `json:"reported_at"`
+}
+
+// CaseMessage — сообщение из переписки сторон.
+type CaseMessage struct {
+	SenderID    int64     `json:"sender_id"`
+	RecipientID int64     `json:"recipient_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DisputeCase — материалы спора для арбитра: контракт, отчёты по этапам, переписка и лента.
+type DisputeCase struct {
+	Dispute    Dispute         `json:"dispute"`
+	Contract   ContractParties `json:"contract"`
+	Milestones []CaseMilestone `json:"milestones"`
+	Messages   []CaseMessage   `json:"messages"`
+	Feed       []FeedEntry     `json:"feed"`
+}
+
+// EvidenceRequest — доказательство, которое сторона прикладывает к спору.
+type EvidenceRequest struct {
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// OpenDisputeRequest — запрос на открытие спора.
+type OpenDisputeRequest struct {
+	Reason   string            `json:"reason"`
+	Evidence []EvidenceRequest `json:"evidence"`
+}
+
+// Resolution — решение арбитра.
+type Resolution struct {
+	Outcome       Outcome `json:"outcome"`
+	SettledAmount *int    `json:"settled_amount"`
+	Comment       string  `json:"comment"`
+}
+
+// Validate проверяет запрос на открытие спора и нормализует текст.
+func (r *OpenDisputeRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	length := len([]rune(r.Reason))
+	if length < minReasonLength || length > maxReasonLength {
+		return fmt.Errorf("%w: причина должна содержать от %d до %d символов", ErrInvalidDispute, minReasonLength, maxReasonLength)
+	}
+	if len(r.Evidence) > MaxEvidencePerDispute {
+		return ErrEvidenceLimit
+	}
+	for i := range r.Evidence {
+		if err := r.Evidence[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate проверяет доказательство: нужен текст, ссылка — только http(s).
+func (e *EvidenceRequest) Validate() error {
+	e.Description = strings.TrimSpace(e.Description)
+	e.URL = strings.TrimSpace(e.URL)
+	if e.Description == "" {
+		return fmt.Errorf("%w: у доказательства нет описания", ErrInvalidDispute)
+	}
+	if len([]rune(e.Description)) > maxEvidenceLength {
+		return fmt.Errorf("%w: описание доказательства длиннее %d символов", ErrInvalidDispute, maxEvidenceLength)
+	}
+	if e.URL != "" {
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: ссылка на доказательство должна быть http(s)-адресом", ErrInvalidDispute)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет решение арбитра. Для частичного решения сумма должна быть
+// положительной и меньше цены контракта, если цена известна.
+func (r *Resolution) Validate(contractPrice *int) error {
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.Comment == "" {
+		return fmt.Errorf("%w: решение должно быть мотивировано", ErrInvalidResolution)
+	}
+
+	switch r.Outcome {
+	case OutcomeComplete, OutcomeCancel:
+		if r.SettledAmount != nil {
+			return fmt.Errorf("%w: сумма указывается только при частичном решении", ErrInvalidResolution)
+		}
+	case OutcomePartial:
+		if r.SettledAmount == nil || *r.SettledAmount <= 0 {
+			return fmt.Errorf("%w: для частичного решения нужна положительная сумма", ErrInvalidResolution)
+		}
+		if contractPrice != nil && *r.SettledAmount >= *contractPrice {
+			return fmt.Errorf("%w: сумма частичного решения должна быть меньше цены контракта %d", ErrInvalidResolution, *contractPrice)
+		}
+	default:
+		return fmt.Errorf("%w: неизвестное решение %q", ErrInvalidResolution, r.Outcome)
+	}
+	return nil
+}
+
+// CanAssign проверяет, может ли арбитр взять спор в работу.
+// Повторное назначение тем же арбитром ничего не меняет и не считается ошибкой.
+func (d Dispute) CanAssign(arbitratorID int64) error {
+	switch {
+	case d.Status == DisputeResolved:
+		return ErrDisputeResolved
+	case d.ArbitratorID != nil && *d.ArbitratorID != arbitratorID:
+		return ErrAssignedToOther
+	}
+	return nil
+}
+
+// CanResolve проверяет, может ли арбитр вынести решение. Решение выносит арбитр,
+// взявший спор; если спор ещё никем не взят, он назначается решающему.
+func (d Dispute) CanResolve(arbitratorID int64) error {
+	return d.CanAssign(arbitratorID)
+}
+
+// SLABreached сообщает, что срок решения истёк, а спор всё ещё не рассмотрен.
+func (d Dispute) SLABreached(now time.Time) bool {
+	return d.Status != DisputeResolved && now.After(d.DueAt)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenDisputeRequestValidate(t *testing.T) {
+	req := OpenDisputeRequest{
+		Reason:   "  Работа не соответствует смете  ",
+		Evidence: []EvidenceRequest{{Description: " Фото ", URL: "https://example.com/photo.jpg"}},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if req.Reason != "Работа не соответствует смете" || req.Evidence[0].Description != "Фото" {
+		t.Errorf("текст не нормализован: %q, %q", req.Reason, req.Evidence[0].Description)
+	}
+
+	invalid := map[string]OpenDisputeRequest{
+		"короткая причина":          {Reason: "плохо"},
+		"доказательство без текста": {Reason: "Работа не выполнена", Evidence: []EvidenceRequest{{URL: "https://example.com"}}},
+		"ссылка не http":            {Reason: "Работа не выполнена", Evidence: []EvidenceRequest{{Description: "Файл", URL: "ftp://example.com/f"}}},
+	}
+	for name, r := range invalid {
+		if err := r.Validate(); !errors.Is(err, ErrInvalidDispute) {
+			t.Errorf("%s: ожидалась ErrInvalidDispute, получено %v", name, err)
+		}
+	}
+
+	tooMany := OpenDisputeRequest{Reason: "Работа не выполнена", Evidence: make([]EvidenceRequest, MaxEvidencePerDispute+1)}
+	if err := tooMany.Validate(); !errors.Is(err, ErrEvidenceLimit) {
+		t.Errorf("ожидалась ErrEvidenceLimit, получено %v", err)
+	}
+}
+
+func TestResolutionValidate(t *testing.T) {
+	price := 10000
+	amount := func(v int) *int { return &v }
+
+	valid := []Resolution{
+		{Outcome: OutcomeComplete, Comment: "Работа выполнена"},
+		{Outcome: OutcomeCancel, Comment: "Работы не начаты"},
+		{Outcome: OutcomePartial, SettledAmount: amount(4000), Comment: "Выполнена часть этапов"},
+	}
+	for _, r := range valid {
+		if err := r.Validate(&price); err != nil {
+			t.Errorf("%s: неожиданная ошибка %v", r.Outcome, err)
+		}
+	}
+
+	invalid := map[string]Resolution{
+		"без комментария":          {Outcome: OutcomeComplete},
+		"неизвестное решение":      {Outcome: "refund", Comment: "..."},
+		"частичное без суммы":      {Outcome: OutcomePartial, Comment: "..."},
+		"частичное на всю сумму":   {Outcome: OutcomePartial, SettledAmount: amount(price), Comment: "..."},
+		"сумма при полном решении": {Outcome: OutcomeComplete, SettledAmount: amount(100), Comment: "..."},
+	}
+	for name, r := range invalid {
+		if err := r.Validate(&price); !errors.Is(err, ErrInvalidResolution) {
+			t.Errorf("%s: ожидалась ErrInvalidResolution, получено %v", name, err)
+		}
+	}
+}
+
+func TestDisputeAssignmentAndSLA(t *testing.T) {
+	arbitrator := int64(7)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	open := Dispute{Status: DisputeOpen, DueAt: now.Add(-time.Minute)}
+	if err := open.CanAssign(arbitrator); err != nil {
+		t.Errorf("открытый спор: неожиданная ошибка %v", err)
+	}
+	if !open.SLABreached(now) {
+		t.Error("срок истёк, а нарушение не обнаружено")
+	}
+
+	taken := Dispute{Status: DisputeInReview, ArbitratorID: &arbitrator, DueAt: now.Add(time.Hour)}
+	if err := taken.CanResolve(arbitrator); err != nil {
+		t.Errorf("свой спор: неожиданная ошибка %v", err)
+	}
+	if err := taken.CanResolve(8); !errors.Is(err, ErrAssignedToOther) {
+		t.Errorf("чужой спор: ожидалась ErrAssignedToOther, получено %v", err)
+	}
+	if taken.SLABreached(now) {
+		t.Error("срок не истёк, а нарушение обнаружено")
+	}
+
+	resolved := Dispute{Status: DisputeResolved, DueAt: now.Add(-time.Hour)}
+	if err := resolved.CanAssign(arbitrator); !errors.Is(err, ErrDisputeResolved) {
+		t.Errorf("решённый спор: ожидалась ErrDisputeResolved, получено %v", err)
+	}
+	if resolved.SLABreached(now) {
+		t.Error("решённый спор не может нарушать срок")
+	}
+}
+
+func TestResolutionMessageFormatsSettledAmount(t *testing.T) {
+	amount := 150000
+	msg := resolutionMessage(Resolution{Outcome: OutcomePartial, SettledAmount: &amount, Comment: "Сделана половина"}, "USD")
+	if !strings.Contains(msg, "причитается 1 500 $.") {
+		t.Errorf("сумма выводится в валюте контракта: %q", msg)
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// DisputesService — интерфейс для бизнес-логики споров.
+type DisputesService interface {
+	OpenDispute(ctx context.Context, contractID, userID int64, req OpenDisputeRequest) (*Dispute, error)
+	GetDispute(ctx context.Context, disputeID, userID int64) (*Dispute, error) // Сторонам и арбитрам
+	AddEvidence(ctx context.Context, disputeID, userID int64, req EvidenceRequest) (*Evidence, error)
+	GetFeed(ctx context.Context, disputeID, userID int64) ([]FeedEntry, error)
+
+	ListForArbitration(ctx context.Context, arbitratorID int64, status DisputeStatus) ([]Dispute, error)
+	GetCase(ctx context.Context, disputeID, arbitratorID int64) (*DisputeCase, error)
+	AssignDispute(ctx context.Context, disputeID, arbitratorID int64) (*Dispute, error)
+	ResolveDispute(ctx context.Context, disputeID, arbitratorID int64, res Resolution) (*Dispute, error)
+
+	CheckSLA(ctx context.Context, now time.Time) error // Отмечает споры, срок решения которых истёк
+	RunSLAWatcher(ctx context.Context, interval time.Duration)
+}
+
+// DisputesRepository — интерфейс для хранения споров.
+// Методы, меняющие спор, записывают переданную запись ленты в той же транзакции.
+type DisputesRepository interface {
+	GetContractParties(ctx context.Context, contractID int64) (*ContractParties, error) // ErrContractNotFound
+	IsArbitrator(ctx context.Context, userID int64) (bool, error)
+
+	InsertDispute(ctx context.Context, d Dispute, evidence []EvidenceRequest, feed FeedEntry) (int64, error) // ErrDisputeExists
+	GetDispute(ctx context.Context, disputeID int64) (*Dispute, error)                                       // С доказательствами; ErrDisputeNotFound
+	ListByStatus(ctx context.Context, statuses []DisputeStatus) ([]Dispute, error)                           // Ближайший срок SLA первым
+	InsertEvidence(ctx context.Context, e Evidence, feed FeedEntry) (Evidence, error)                        // ErrEvidenceLimit, ErrDisputeResolved
+	ListFeed(ctx context.Context, disputeID int64) ([]FeedEntry, error)
+
+	AssignArbitrator(ctx context.Context, disputeID, arbitratorID int64, feed FeedEntry) (bool, error) // false, если спор взят другим или решён
+	Resolve(ctx context.Context, disputeID, arbitratorID int64, res Resolution, resolvedAt time.Time, feed FeedEntry) (bool, error)
+	ListSLABreaches(ctx context.Context, now time.Time) ([]Dispute, error) // Нерешённые споры с истёкшим сроком, ещё не отмеченные
+	MarkSLABreached(ctx context.Context, disputeID int64, at time.Time, feed FeedEntry) (bool, error)
+
+	ListCaseMilestones(ctx context.Context, contractID int64) ([]CaseMilestone, error)
+	ListCaseMessages(ctx context.Context, userA, userB int64, limit int) ([]CaseMessage, error) // Переписка сторон по времени
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
+}
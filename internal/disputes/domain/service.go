@@ -0,0 +1,396 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// caseMessagesLimit — сколько последних сообщений переписки сторон показывать арбитру.
+const caseMessagesLimit = 500
+
+type disputesService struct {
+	repo DisputesRepository
+	bus  EventBus
+}
+
+// NewDisputesService создаёт сервис споров.
+func NewDisputesService(repo DisputesRepository, bus EventBus) DisputesService {
+	return &disputesService{repo: repo, bus: bus}
+}
+
+// OpenDispute открывает спор по активному контракту от имени заказчика или исполнителя.
+// По контракту может быть только один нерассмотренный спор.
+func (s *disputesService) OpenDispute(ctx context.Context, contractID, userID int64, req OpenDisputeRequest) (*Dispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	contract, err := s.repo.GetContractParties(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.IsParty(userID) {
+		return nil, ErrNotParty
+	}
+	if !contract.IsActive {
+		return nil, ErrContractClosed
+	}
+
+	now := time.Now()
+	d := Dispute{
+		ContractID: contract.ContractID,
+		TaskID:     contract.TaskID,
+		OpenedBy:   userID,
+		CustomerID: contract.CustomerID,
+		ExecutorID: contract.ExecutorID,
+		Reason:     req.Reason,
+		Status:     DisputeOpen,
+		DueAt:      now.Add(ResolutionSLA),
+		CreatedAt:  now,
+	}
+	feed := s.feedEntry(&userID, FeedOpened, fmt.Sprintf("%s открыл спор. Решение ожидается до %s.", partyName(contract, userID), d.DueAt.Format("02.01.2006 15:04")), now)
+
+	id, err := s.repo.InsertDispute(ctx, d, req.Evidence, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.GetDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.publishUpdate(*created, []int64{otherParty(contract, userID)}, feed)
+	return created, nil
+}
+
+// GetDispute возвращает спор стороне контракта или арбитру.
+func (s *disputesService) GetDispute(ctx context.Context, disputeID, userID int64) (*Dispute, error) {
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkViewer(ctx, *d, userID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// AddEvidence прикладывает к нерассмотренному спору доказательство стороны.
+func (s *disputesService) AddEvidence(ctx context.Context, disputeID, userID int64, req EvidenceRequest) (*Evidence, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != d.CustomerID && userID != d.ExecutorID {
+		return nil, ErrNotParty
+	}
+	if d.Status == DisputeResolved {
+		return nil, ErrDisputeResolved
+	}
+
+	now := time.Now()
+	feed := s.feedEntry(&userID, FeedEvidence, fmt.Sprintf("%s приложил доказательство.", disputePartyName(*d, userID)), now)
+	evidence, err := s.repo.InsertEvidence(ctx, Evidence{
+		DisputeID:   disputeID,
+		AuthorID:    userID,
+		Description: req.Description,
+		URL:         req.URL,
+		CreatedAt:   now,
+	}, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []int64{d.CustomerID, d.ExecutorID}
+	if d.ArbitratorID != nil {
+		recipients = append(recipients, *d.ArbitratorID)
+	}
+	s.publishUpdate(*d, exclude(recipients, userID), feed)
+	return &evidence, nil
+}
+
+// GetFeed возвращает ленту статусов спора стороне контракта или арбитру.
+func (s *disputesService) GetFeed(ctx context.Context, disputeID, userID int64) ([]FeedEntry, error) {
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkViewer(ctx, *d, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListFeed(ctx, disputeID)
+}
+
+// ListForArbitration возвращает арбитру споры в указанном статусе; без статуса — все нерассмотренные.
+func (s *disputesService) ListForArbitration(ctx context.Context, arbitratorID int64, status DisputeStatus) ([]Dispute, error) {
+	if err := s.checkArbitrator(ctx, arbitratorID); err != nil {
+		return nil, err
+	}
+
+	statuses := []DisputeStatus{DisputeOpen, DisputeInReview}
+	if status != "" {
+		if !status.IsValid() {
+			return nil, fmt.Errorf("%w: неизвестный статус %q", ErrInvalidDispute, status)
+		}
+		statuses = []DisputeStatus{status}
+	}
+	return s.repo.ListByStatus(ctx, statuses)
+}
+
+// GetCase собирает для арбитра материалы спора: контракт, отчёты по этапам, переписку сторон и ленту.
+func (s *disputesService) GetCase(ctx context.Context, disputeID, arbitratorID int64) (*DisputeCase, error) {
+	if err := s.checkArbitrator(ctx, arbitratorID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := s.repo.GetContractParties(ctx, d.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	milestones, err := s.repo.ListCaseMilestones(ctx, d.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.ListCaseMessages(ctx, d.CustomerID, d.ExecutorID, caseMessagesLimit)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := s.repo.ListFeed(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DisputeCase{
+		Dispute:    *d,
+		Contract:   *contract,
+		Milestones: milestones,
+		Messages:   messages,
+		Feed:       feed,
+	}, nil
+}
+
+// AssignDispute назначает арбитра на спор и переводит его на рассмотрение.
+func (s *disputesService) AssignDispute(ctx context.Context, disputeID, arbitratorID int64) (*Dispute, error) {
+	if err := s.checkArbitrator(ctx, arbitratorID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.CanAssign(arbitratorID); err != nil {
+		return nil, err
+	}
+	if d.ArbitratorID != nil {
+		return d, nil
+	}
+
+	feed := s.feedEntry(&arbitratorID, FeedAssigned, "Арбитр взял спор на рассмотрение.", time.Now())
+	ok, err := s.repo.AssignArbitrator(ctx, disputeID, arbitratorID, feed)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAssignedToOther
+	}
+
+	updated, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishUpdate(*updated, []int64{d.CustomerID, d.ExecutorID}, feed)
+	return updated, nil
+}
+
+// ResolveDispute выносит решение по спору. Модуль задач по событию закрывает контракт
+// и переводит задачу в итоговый статус.
+func (s *disputesService) ResolveDispute(ctx context.Context, disputeID, arbitratorID int64, res Resolution) (*Dispute, error) {
+	if err := s.checkArbitrator(ctx, arbitratorID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.CanResolve(arbitratorID); err != nil {
+		return nil, err
+	}
+	contract, err := s.repo.GetContractParties(ctx, d.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Validate(contract.Price); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	feed := s.feedEntry(&arbitratorID, FeedResolved, resolutionMessage(res, contract.Currency), now)
+	ok, err := s.repo.Resolve(ctx, disputeID, arbitratorID, res, now, feed)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDisputeResolved
+	}
+
+	resolved, err := s.repo.GetDispute(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(disputes.DisputeResolvedEvent{
+		DisputeID:     resolved.ID,
+		ContractID:    resolved.ContractID,
+		TaskID:        resolved.TaskID,
+		ArbitratorID:  arbitratorID,
+		Outcome:       string(res.Outcome),
+		SettledAmount: res.SettledAmount,
+	})
+	s.publishUpdate(*resolved, []int64{d.CustomerID, d.ExecutorID}, feed)
+	return resolved, nil
+}
+
+// CheckSLA отмечает споры, по которым арбитр не вынес решение в срок, и сообщает об этом сторонам.
+func (s *disputesService) CheckSLA(ctx context.Context, now time.Time) error {
+	breaches, err := s.repo.ListSLABreaches(ctx, now)
+	if err != nil {
+		return fmt.Errorf("не удалось получить споры с истёкшим сроком: %w", err)
+	}
+
+	for _, d := range breaches {
+		if !d.SLABreached(now) {
+			continue
+		}
+		feed := s.feedEntry(nil, FeedSLABreached, "Срок рассмотрения спора истёк. Спор передан старшему арбитру в приоритетном порядке.", now)
+		ok, err := s.repo.MarkSLABreached(ctx, d.ID, now, feed)
+		if err != nil {
+			slog.Error("[Disputes] Не удалось отметить нарушение SLA", "dispute_id", d.ID, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		recipients := []int64{d.CustomerID, d.ExecutorID}
+		if d.ArbitratorID != nil {
+			recipients = append(recipients, *d.ArbitratorID)
+		}
+		s.publishUpdate(d, recipients, feed)
+	}
+	return nil
+}
+
+// RunSLAWatcher периодически проверяет сроки рассмотрения споров, пока не отменён ctx.
+func (s *disputesService) RunSLAWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.CheckSLA(ctx, now); err != nil {
+				slog.Error("[Disputes] Ошибка проверки сроков споров", "error", err)
+			}
+		}
+	}
+}
+
+// checkViewer пропускает стороны контракта и арбитров.
+func (s *disputesService) checkViewer(ctx context.Context, d Dispute, userID int64) error {
+	if userID == d.CustomerID || userID == d.ExecutorID {
+		return nil
+	}
+	isArbitrator, err := s.repo.IsArbitrator(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isArbitrator {
+		return ErrNotParty
+	}
+	return nil
+}
+
+func (s *disputesService) checkArbitrator(ctx context.Context, userID int64) error {
+	isArbitrator, err := s.repo.IsArbitrator(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isArbitrator {
+		return ErrNotArbitrator
+	}
+	return nil
+}
+
+func (s *disputesService) feedEntry(actorID *int64, kind FeedKind, message string, at time.Time) FeedEntry {
+	return FeedEntry{ActorID: actorID, Kind: kind, Message: message, CreatedAt: at}
+}
+
+func (s *disputesService) publishUpdate(d Dispute, recipients []int64, feed FeedEntry) {
+	if len(recipients) == 0 {
+		return
+	}
+	s.bus.Publish(disputes.DisputeUpdatedEvent{
+		DisputeID:    d.ID,
+		ContractID:   d.ContractID,
+		RecipientIDs: recipients,
+		Kind:         string(feed.Kind),
+		Message:      feed.Message,
+	})
+}
+
+func resolutionMessage(res Resolution, currency money.Currency) string {
+	switch res.Outcome {
+	case OutcomeComplete:
+		return "Арбитр признал работу выполненной. Контракт завершён. " + res.Comment
+	case OutcomePartial:
+		return fmt.Sprintf("Арбитр признал работу выполненной частично: исполнителю причитается %s. Контракт завершён. %s",
+			money.New(*res.SettledAmount, currency).String(), res.Comment)
+	}
+	return "Арбитр расторг контракт, задача отменена. " + res.Comment
+}
+
+func partyName(c *ContractParties, userID int64) string {
+	if userID == c.CustomerID {
+		return "Заказчик"
+	}
+	return "Исполнитель"
+}
+
+func disputePartyName(d Dispute, userID int64) string {
+	if userID == d.CustomerID {
+		return "Заказчик"
+	}
+	return "Исполнитель"
+}
+
+func otherParty(c *ContractParties, userID int64) int64 {
+	if userID == c.CustomerID {
+		return c.ExecutorID
+	}
+	return c.CustomerID
+}
+
+func exclude(ids []int64, id int64) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, v := range ids {
+		if v != id {
+			result = append(result, v)
+		}
+	}
+	return result
+}
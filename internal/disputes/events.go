@@ -0,0 +1,21 @@
+package disputes
+
+// DisputeUpdatedEvent — событие изменения спора, о котором нужно сообщить сторонам.
+type DisputeUpdatedEvent struct {
+	DisputeID    int64
+	ContractID   int64
+	RecipientIDs []int64
+	Kind         string // Вид записи ленты: opened, evidence_added, assigned, sla_breached, resolved
+	Message      string
+}
+
+// DisputeResolvedEvent — событие решения арбитра; по нему модуль задач закрывает контракт
+// и переводит задачу в итоговый статус.
+type DisputeResolvedEvent struct {
+	DisputeID     int64
+	ContractID    int64
+	TaskID        int64
+	ArbitratorID  int64
+	Outcome       string // complete, partial или cancel
+	SettledAmount *int   // Сумма исполнителю при частичном решении
+}
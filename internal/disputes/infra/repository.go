@@ -0,0 +1,375 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/disputes/domain"
+)
+
+// disputeColumns — поля спора в порядке, который ожидает scanDispute.
+const disputeColumns = `
+        d.id, d.contract_id, d.task_id, d.opened_by, d.customer_id, d.executor_id, d.reason, d.status,
+        d.arbitrator_id, d.outcome, d.settled_amount, d.resolution_comment, d.due_at, d.sla_breached_at,
+        d.created_at, d.resolved_at`
+
+// DisputesRepository хранит споры в PostgreSQL.
+type DisputesRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDisputesRepository создаёт новый репозиторий споров.
+func NewDisputesRepository(db *pgxpool.Pool) *DisputesRepository {
+	return &DisputesRepository{db: db}
+}
+
+// GetContractParties получает стороны и цену контракта.
+func (r *DisputesRepository) GetContractParties(ctx context.Context, contractID int64) (*domain.ContractParties, error) {
+	var c domain.ContractParties
+	err := r.db.QueryRow(ctx, `
+        SELECT id, task_id, customer_id, executor_id, is_active, price, price_currency
+        FROM contracts
+        WHERE id = $1`, contractID).Scan(&c.ContractID, &c.TaskID, &c.CustomerID, &c.ExecutorID, &c.IsActive, &c.Price, &c.Currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrContractNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении контракта с ID %d: %w", contractID, err)
+	}
+	return &c, nil
+}
+
+// IsArbitrator проверяет, может ли пользователь рассматривать споры.
+func (r *DisputesRepository) IsArbitrator(ctx context.Context, userID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND type = $2)`,
+		userID, domain.ArbitratorUserType).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке прав арбитра: %w", err)
+	}
+	return ok, nil
+}
+
+// InsertDispute сохраняет спор вместе с приложенными доказательствами и первой записью ленты.
+func (r *DisputesRepository) InsertDispute(ctx context.Context, d domain.Dispute, evidence []domain.EvidenceRequest, feed domain.FeedEntry) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+        INSERT INTO disputes (contract_id, task_id, opened_by, customer_id, executor_id, reason, status, due_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id`,
+		d.ContractID, d.TaskID, d.OpenedBy, d.CustomerID, d.ExecutorID, d.Reason, d.Status, d.DueAt, d.CreatedAt).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, domain.ErrDisputeExists
+		}
+		return 0, fmt.Errorf("ошибка при создании спора: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, e := range evidence {
+		batch.Queue(`
+            INSERT INTO dispute_evidence (dispute_id, author_id, description, url, created_at)
+            VALUES ($1, $2, $3, $4, $5)`, id, d.OpenedBy, e.Description, e.URL, d.CreatedAt)
+	}
+	queueFeed(batch, id, feed)
+	if err := execBatch(ctx, tx, batch); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("не удалось сохранить спор: %w", err)
+	}
+	return id, nil
+}
+
+// GetDispute получает спор с доказательствами.
+func (r *DisputesRepository) GetDispute(ctx context.Context, disputeID int64) (*domain.Dispute, error) {
+	d, err := scanDispute(r.db.QueryRow(ctx, `SELECT `+disputeColumns+` FROM disputes d WHERE d.id = $1`, disputeID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении спора с ID %d: %w", disputeID, err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT id, dispute_id, author_id, description, url, created_at
+        FROM dispute_evidence
+        WHERE dispute_id = $1
+        ORDER BY created_at, id`, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении доказательств по спору: %w", err)
+	}
+	defer rows.Close()
+
+	d.Evidence = []domain.Evidence{}
+	for rows.Next() {
+		var e domain.Evidence
+		if err := rows.Scan(&e.ID, &e.DisputeID, &e.AuthorID, &e.Description, &e.URL, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании доказательства: %w", err)
+		}
+		d.Evidence = append(d.Evidence, e)
+	}
+	return &d, rows.Err()
+}
+
+// ListByStatus получает споры в указанных статусах, ближайший срок решения первым.
+func (r *DisputesRepository) ListByStatus(ctx context.Context, statuses []domain.DisputeStatus) ([]domain.Dispute, error) {
+	values := make([]string, len(statuses))
+	for i, s := range statuses {
+		values[i] = string(s)
+	}
+	return r.listDisputes(ctx, `
+        SELECT `+disputeColumns+`
+        FROM disputes d
+        WHERE d.status = ANY($1)
+        ORDER BY d.due_at, d.id`, values)
+}
+
+// InsertEvidence добавляет доказательство к нерассмотренному спору.
+// Спор блокируется на время вставки, чтобы лимит доказательств не превышался параллельными запросами.
+func (r *DisputesRepository) InsertEvidence(ctx context.Context, e domain.Evidence, feed domain.FeedEntry) (domain.Evidence, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.Evidence{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.DisputeStatus
+	var count int
+	err = tx.QueryRow(ctx, `
+        SELECT d.status, (SELECT COUNT(*) FROM dispute_evidence WHERE dispute_id = d.id)
+        FROM disputes d
+        WHERE d.id = $1
+        FOR UPDATE`, e.DisputeID).Scan(&status, &count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Evidence{}, domain.ErrDisputeNotFound
+		}
+		return domain.Evidence{}, fmt.Errorf("ошибка при блокировке спора: %w", err)
+	}
+	if status == domain.DisputeResolved {
+		return domain.Evidence{}, domain.ErrDisputeResolved
+	}
+	if count >= domain.MaxEvidencePerDispute {
+		return domain.Evidence{}, domain.ErrEvidenceLimit
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO dispute_evidence (dispute_id, author_id, description, url, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`, e.DisputeID, e.AuthorID, e.Description, e.URL, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		return domain.Evidence{}, fmt.Errorf("ошибка при сохранении доказательства: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	queueFeed(batch, e.DisputeID, feed)
+	if err := execBatch(ctx, tx, batch); err != nil {
+		return domain.Evidence{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Evidence{}, fmt.Errorf("не удалось сохранить доказательство: %w", err)
+	}
+	return e, nil
+}
+
+// ListFeed получает ленту статусов спора в хронологическом порядке.
+func (r *DisputesRepository) ListFeed(ctx context.Context, disputeID int64) ([]domain.FeedEntry, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, dispute_id, actor_id, kind, message, created_at
+        FROM dispute_events
+        WHERE dispute_id = $1
+        ORDER BY created_at, id`, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ленты спора: %w", err)
+	}
+	defer rows.Close()
+
+	feed := []domain.FeedEntry{}
+	for rows.Next() {
+		var f domain.FeedEntry
+		if err := rows.Scan(&f.ID, &f.DisputeID, &f.ActorID, &f.Kind, &f.Message, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании записи ленты: %w", err)
+		}
+		feed = append(feed, f)
+	}
+	return feed, rows.Err()
+}
+
+// AssignArbitrator назначает арбитра на открытый спор. Возвращает false, если спор уже взят или решён.
+func (r *DisputesRepository) AssignArbitrator(ctx context.Context, disputeID, arbitratorID int64, feed domain.FeedEntry) (bool, error) {
+	return r.updateWithFeed(ctx, disputeID, feed, `
+        UPDATE disputes
+        SET arbitrator_id = $2, status = $3
+        WHERE id = $1 AND arbitrator_id IS NULL AND status = $4`,
+		disputeID, arbitratorID, domain.DisputeInReview, domain.DisputeOpen)
+}
+
+// Resolve сохраняет решение арбитра. Спор, ещё никем не взятый, назначается решающему.
+// Возвращает false, если спор уже решён или рассматривается другим арбитром.
+func (r *DisputesRepository) Resolve(ctx context.Context, disputeID, arbitratorID int64, res domain.Resolution, resolvedAt time.Time, feed domain.FeedEntry) (bool, error) {
+	return r.updateWithFeed(ctx, disputeID, feed, `
+        UPDATE disputes
+        SET arbitrator_id = $2, status = $3, outcome = $4, settled_amount = $5, resolution_comment = $6, resolved_at = $7
+        WHERE id = $1 AND status <> $3 AND (arbitrator_id IS NULL OR arbitrator_id = $2)`,
+		disputeID, arbitratorID, domain.DisputeResolved, res.Outcome, res.SettledAmount, res.Comment, resolvedAt)
+}
+
+// ListSLABreaches получает нерешённые споры с истёкшим сроком, о которых ещё не сообщалось.
+func (r *DisputesRepository) ListSLABreaches(ctx context.Context, now time.Time) ([]domain.Dispute, error) {
+	return r.listDisputes(ctx, `
+        SELECT `+disputeColumns+`
+        FROM disputes d
+        WHERE d.status <> $1 AND d.due_at < $2 AND d.sla_breached_at IS NULL
+        ORDER BY d.due_at, d.id`, domain.DisputeResolved, now)
+}
+
+// MarkSLABreached отмечает нарушение срока. Возвращает false, если спор уже отмечен или решён.
+func (r *DisputesRepository) MarkSLABreached(ctx context.Context, disputeID int64, at time.Time, feed domain.FeedEntry) (bool, error) {
+	return r.updateWithFeed(ctx, disputeID, feed, `
+        UPDATE disputes
+        SET sla_breached_at = $2
+        WHERE id = $1 AND sla_breached_at IS NULL AND status <> $3`,
+		disputeID, at, domain.DisputeResolved)
+}
+
+// ListCaseMilestones получает этапы контракта с последними отчётами по ним.
+func (r *DisputesRepository) ListCaseMilestones(ctx context.Context, contractID int64) ([]domain.CaseMilestone, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT m.title, m.amount, m.due_date, rp.executor_comments, rp.customer_feedback,
+               rp.customer_confirmation, rp.created_at
+        FROM contract_milestones m
+        LEFT JOIN reports rp ON rp.milestone_id = m.id
+        WHERE m.contract_id = $1
+        ORDER BY m.position`, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении этапов контракта: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []domain.CaseMilestone{}
+	for rows.Next() {
+		var m domain.CaseMilestone
+		err := rows.Scan(&m.Title, &m.Amount, &m.DueDate, &m.ExecutorComments, &m.CustomerFeedback,
+			&m.CustomerConfirmation, &m.ReportedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании этапа контракта: %w", err)
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+// ListCaseMessages получает последние сообщения между двумя пользователями в хронологическом порядке.
+func (r *DisputesRepository) ListCaseMessages(ctx context.Context, userA, userB int64, limit int) ([]domain.CaseMessage, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT sender_id, recipient_id, content, created_at
+        FROM (
+            SELECT id, sender_id, recipient_id, content, created_at
+            FROM messages
+            WHERE (sender_id = $1 AND recipient_id = $2) OR (sender_id = $2 AND recipient_id = $1)
+            ORDER BY created_at DESC, id DESC
+            LIMIT $3
+        ) m
+        ORDER BY created_at, id`, userA, userB, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении переписки сторон: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.CaseMessage{}
+	for rows.Next() {
+		var m domain.CaseMessage
+		if err := rows.Scan(&m.SenderID, &m.RecipientID, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании сообщения: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// updateWithFeed выполняет условное обновление спора и, если оно сработало, записывает запись ленты.
+func (r *DisputesRepository) updateWithFeed(ctx context.Context, disputeID int64, feed domain.FeedEntry, query string, args ...any) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении спора с ID %d: %w", disputeID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	batch := &pgx.Batch{}
+	queueFeed(batch, disputeID, feed)
+	if err := execBatch(ctx, tx, batch); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("не удалось сохранить изменения спора: %w", err)
+	}
+	return true, nil
+}
+
+func (r *DisputesRepository) listDisputes(ctx context.Context, query string, args ...any) ([]domain.Dispute, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении споров: %w", err)
+	}
+	defer rows.Close()
+
+	disputes := []domain.Dispute{}
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании спора: %w", err)
+		}
+		disputes = append(disputes, d)
+	}
+	return disputes, rows.Err()
+}
+
+func scanDispute(row pgx.Row) (domain.Dispute, error) {
+	var d domain.Dispute
+	err := row.Scan(&d.ID, &d.ContractID, &d.TaskID, &d.OpenedBy, &d.CustomerID, &d.ExecutorID, &d.Reason, &d.Status,
+		&d.ArbitratorID, &d.Outcome, &d.SettledAmount, &d.ResolutionComment, &d.DueAt, &d.SLABreachedAt,
+		&d.CreatedAt, &d.ResolvedAt)
+	return d, err
+}
+
+func queueFeed(batch *pgx.Batch, disputeID int64, feed domain.FeedEntry) {
+	batch.Queue(`
+        INSERT INTO dispute_events (dispute_id, actor_id, kind, message, created_at)
+        VALUES ($1, $2, $3, $4, $5)`, disputeID, feed.ActorID, feed.Kind, feed.Message, feed.CreatedAt)
+}
+
+func execBatch(ctx context.Context, tx pgx.Tx, batch *pgx.Batch) error {
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("ошибка при записи спора: %w", err)
+		}
+	}
+	return results.Close()
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/documents/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// DocumentsHandler отвечает за обработку HTTP-запросов к закрывающим документам по контрактам.
+type DocumentsHandler struct {
+	service domain.DocumentsService
+}
+
+// NewDocumentsHandler создаёт новый экземпляр DocumentsHandler.
+func NewDocumentsHandler(service domain.DocumentsService) *DocumentsHandler {
+	return &DocumentsHandler{service: service}
+}
+
+// GetContractDocuments возвращает счёт и квитанцию по контракту со ссылками на PDF.
+func (h *DocumentsHandler) GetContractDocuments(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	documents, err := h.service.ListContractDocuments(r.Context(), contractID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, documentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, documents)
+}
+
+func documentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContractNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrDocumentsForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
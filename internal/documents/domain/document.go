@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DocumentKind — вид закрывающего документа по контракту.
+type DocumentKind string
+
+const (
+	KindInvoice DocumentKind = "invoice" // Счёт заказчику на оплаченные работы
+	KindReceipt DocumentKind = "receipt" // Квитанция о выплате исполнителю с удержанной комиссией
+)
+
+// Kinds — документы, которые выпускаются по каждому завершённому контракту, в порядке выпуска.
+var Kinds = []DocumentKind{KindInvoice, KindReceipt}
+
+var (
+	ErrContractNotFound   = errors.New("завершённый контракт с оплатой не найден")
+	ErrDocumentsForbidden = errors.New("документы по контракту доступны только его сторонам")
+)
+
+// Document — выпущенный PDF-документ. Номер сквозной в пределах вида документа и года
+// и выдаётся без пропусков: номер занимается в той же транзакции, что и запись документа.
+type Document struct {
+	ID         int64        `json:"id"`
+	ContractID int64        `json:"contract_id"`
+	Kind       DocumentKind `json:"kind"`
+	Year       int          `json:"year"`
+	Sequence   int          `json:"sequence"`
+	Number     string       `json:"number"`
+	URL        string       `json:"url"`
+	Amount     int          `json:"amount"` // Оплачено исполнителю из эскроу
+	Fee        int          `json:"fee"`    // Удержанная комиссия площадки
+	Currency   string       `json:"currency"`
+	IssuedAt   time.Time    `json:"issued_at"`
+}
+
+// Party — сторона контракта.
+type Party struct {
+	UserID int64
+	Name   string
+	Email  string
+}
+
+// Issuer — сведения о площадке из информации о компании.
+type Issuer struct {
+	Name       string
+	WebsiteURL string
+}
+
+// DocumentData — всё, что печатается в документах по контракту.
+type DocumentData struct {
+	ContractID  int64
+	TaskID      int64
+	TaskTitle   string
+	Customer    Party
+	Executor    Party
+	Issuer      Issuer
+	Amount      int // Сумма контракта
+	Released    int // Выплачено исполнителю
+	Refunded    int // Возвращено заказчику
+	Fee         int
+	FeeRateBP   int
+	Currency    string
+	CompletedAt time.Time
+}
+
+// Totals — итоговые суммы документа.
+type Totals struct {
+	Gross int // Стоимость принятых работ
+	Fee   int // Комиссия площадки, удержанная из выплаты
+	Net   int // Получено исполнителем
+}
+
+// Totals считает итоги по данным контракта.
+func (d DocumentData) Totals() Totals {
+	return Totals{Gross: d.Released, Fee: d.Fee, Net: d.Released - d.Fee}
+}
+
+// IsParty сообщает, является ли пользователь стороной контракта.
+func (d DocumentData) IsParty(userID int64) bool {
+	return userID == d.Customer.UserID || userID == d.Executor.UserID
+}
+
+// Title возвращает название документа для печати.
+func (k DocumentKind) Title() string {
+	if k == KindReceipt {
+		return "Квитанция"
+	}
+	return "Счёт"
+}
+
+// prefix возвращает префикс номера документа.
+func (k DocumentKind) prefix() string {
+	if k == KindReceipt {
+		return "RCP"
+	}
+	return "INV"
+}
+
+// FormatNumber возвращает номер документа вида INV-2026-000042.
+func FormatNumber(kind DocumentKind, year, sequence int) string {
+	return fmt.Sprintf("%s-%d-%06d", kind.prefix(), year, sequence)
+}
+
+// StoragePath — путь PDF-файла документа в файловом хранилище.
+func StoragePath(d Document) string {
+	return fmt.Sprintf("contracts/%d/documents/%s.pdf", d.ContractID, d.Number)
+}
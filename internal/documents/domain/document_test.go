@@ -0,0 +1,19 @@
+package domain
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	if got := FormatNumber(KindInvoice, 2026, 42); got != "INV-2026-000042" {
+		t.Errorf("неверный номер счёта: %s", got)
+	}
+	if got := FormatNumber(KindReceipt, 2027, 1); got != "RCP-2027-000001" {
+		t.Errorf("неверный номер квитанции: %s", got)
+	}
+}
+
+func TestDocumentDataTotals(t *testing.T) {
+	d := DocumentData{Amount: 10000, Released: 6000, Refunded: 4000, Fee: 600}
+	if got := d.Totals(); got != (Totals{Gross: 6000, Fee: 600, Net: 5400}) {
+		t.Errorf("неверные итоги: %+v", got)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "context"
+
+// DocumentsService — интерфейс для бизнес-логики закрывающих документов по контрактам.
+type DocumentsService interface {
+	// IssueForContract выпускает недостающие документы по контракту и рассылает их сторонам.
+	IssueForContract(ctx context.Context, contractID int64) ([]Document, error)
+	ListContractDocuments(ctx context.Context, contractID, userID int64) ([]Document, error)
+
+	HandleEscrowClosed(event any)
+}
+
+// DocumentsRepository — интерфейс для хранения документов.
+type DocumentsRepository interface {
+	GetDocumentData(ctx context.Context, contractID int64) (*DocumentData, error) // ErrContractNotFound
+	// CreateDocument занимает следующий номер, вызывает store для сохранения файла и записывает документ
+	// в одной транзакции: если store или запись не удались, номер не расходуется. Если документ этого вида
+	// по контракту уже выпущен, store не вызывается и возвращается существующий документ и false.
+	CreateDocument(ctx context.Context, doc Document, store func(Document) (string, error)) (*Document, bool, error)
+	ListDocuments(ctx context.Context, contractID int64) ([]Document, error)
+}
+
+// Renderer — порт генерации PDF.
+type Renderer interface {
+	Render(doc Document, data DocumentData) ([]byte, error)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"time"
+
+	filestorage "github.com/unclaim/chegonado.git/internal/filestorage/domain"
+	"github.com/unclaim/chegonado.git/internal/payments"
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+)
+
+// emailTemplatePath — шаблон письма с документами по контракту.
+const emailTemplatePath = "../../web/templates/emails/contract_documents.html"
+
+type documentsService struct {
+	repo     DocumentsRepository
+	storage  filestorage.FileStorageRepository
+	renderer Renderer
+	email    ports.EmailSender
+}
+
+// NewDocumentsService создаёт сервис закрывающих документов.
+func NewDocumentsService(repo DocumentsRepository, storage filestorage.FileStorageRepository, renderer Renderer, email ports.EmailSender) DocumentsService {
+	return &documentsService{repo: repo, storage: storage, renderer: renderer, email: email}
+}
+
+// IssueForContract выпускает счёт и квитанцию по контракту. Уже выпущенные документы не перевыпускаются,
+// а письмо сторонам уходит, только если появился хотя бы один новый документ.
+func (s *documentsService) IssueForContract(ctx context.Context, contractID int64) ([]Document, error) {
+	data, err := s.repo.GetDocumentData(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	documents := make([]Document, 0, len(Kinds))
+	var attachments []ports.Attachment
+	for _, kind := range Kinds {
+		var rendered []byte
+		doc, created, err := s.repo.CreateDocument(ctx, Document{
+			ContractID: contractID,
+			Kind:       kind,
+			Year:       now.Year(),
+			Amount:     data.Released,
+			Fee:        data.Fee,
+			Currency:   data.Currency,
+			IssuedAt:   now,
+		}, func(doc Document) (string, error) {
+			pdf, err := s.renderer.Render(doc, *data)
+			if err != nil {
+				return "", fmt.Errorf("не удалось сформировать %s: %w", doc.Number, err)
+			}
+			rendered = pdf
+			return s.storage.SaveFile(ctx, StoragePath(doc), bytes.NewReader(pdf))
+		})
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *doc)
+		if created {
+			attachments = append(attachments, ports.Attachment{
+				Filename:    doc.Number + ".pdf",
+				ContentType: "application/pdf",
+				Data:        rendered,
+			})
+		}
+	}
+
+	if len(attachments) > 0 {
+		for _, party := range []Party{data.Customer, data.Executor} {
+			if err := s.send(party, *data, attachments); err != nil {
+				slog.Error("[Documents] Не удалось отправить документы по контракту", "contract_id", contractID, "user_id", party.UserID, "error", err)
+			}
+		}
+	}
+	return documents, nil
+}
+
+// ListContractDocuments возвращает документы по контракту его сторонам.
+func (s *documentsService) ListContractDocuments(ctx context.Context, contractID, userID int64) ([]Document, error) {
+	data, err := s.repo.GetDocumentData(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IsParty(userID) {
+		return nil, ErrDocumentsForbidden
+	}
+	return s.repo.ListDocuments(ctx, contractID)
+}
+
+// HandleEscrowClosed — обработчик закрытия эскроу: по контракту, за который исполнитель
+// что-то получил, выпускаются закрывающие документы.
+func (s *documentsService) HandleEscrowClosed(event any) {
+	e, ok := event.(payments.EscrowClosedEvent)
+	if !ok {
+		slog.Error("[Documents] Получено некорректное событие", "event", event)
+		return
+	}
+	if e.Released == 0 {
+		return
+	}
+	if _, err := s.IssueForContract(context.Background(), e.ContractID); err != nil {
+		slog.Error("[Documents] Не удалось выпустить документы по контракту", "contract_id", e.ContractID, "error", err)
+	}
+}
+
+func (s *documentsService) send(to Party, data DocumentData, attachments []ports.Attachment) error {
+	if to.Email == "" {
+		return nil
+	}
+	tmpl, err := template.ParseFiles(emailTemplatePath)
+	if err != nil {
+		return fmt.Errorf("не удалось загрузить шаблон письма: %w", err)
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, struct {
+		Name       string
+		ContractID int64
+		TaskTitle  string
+	}{Name: to.Name, ContractID: data.ContractID, TaskTitle: data.TaskTitle})
+	if err != nil {
+		return fmt.Errorf("ошибка при подготовке тела письма: %w", err)
+	}
+
+	subject := fmt.Sprintf("Документы по контракту №%d", data.ContractID)
+	return s.email.SendEmailWithAttachments(to.Email, subject, &body, attachments)
+}
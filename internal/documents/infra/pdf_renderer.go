@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/unclaim/chegonado.git/internal/documents/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+const (
+	fontFamily      = "DejaVu"
+	regularFontFile = "DejaVuSans.ttf"
+	boldFontFile    = "DejaVuSans-Bold.ttf"
+)
+
+// PDFRenderer формирует документы в PDF. Встроенные шрифты PDF не содержат кириллицы,
+// поэтому в документ встраивается TrueType-шрифт DejaVu Sans.
+type PDFRenderer struct {
+	regular []byte
+	bold    []byte
+}
+
+// NewPDFRenderer загружает шрифты из каталога fontDir.
+func NewPDFRenderer(fontDir string) (*PDFRenderer, error) {
+	regular, err := os.ReadFile(filepath.Join(fontDir, regularFontFile))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить шрифт для документов: %w", err)
+	}
+	bold, err := os.ReadFile(filepath.Join(fontDir, boldFontFile))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить шрифт для документов: %w", err)
+	}
+	return &PDFRenderer{regular: regular, bold: bold}, nil
+}
+
+// Render формирует PDF счёта или квитанции.
+func (r *PDFRenderer) Render(doc domain.Document, data domain.DocumentData) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", r.regular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", r.bold)
+	// Повторно сформированный документ должен совпадать с исходным байт в байт.
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(doc.IssuedAt)
+	pdf.SetModificationDate(doc.IssuedAt)
+	pdf.SetTitle(fmt.Sprintf("%s № %s", doc.Kind.Title(), doc.Number), true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 10, fmt.Sprintf("%s № %s от %s", doc.Kind.Title(), doc.Number, doc.IssuedAt.Format("02.01.2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont(fontFamily, "", 11)
+	issuer := data.Issuer.Name
+	if issuer == "" {
+		issuer = "Площадка"
+	}
+	if data.Issuer.WebsiteURL != "" {
+		issuer += " (" + data.Issuer.WebsiteURL + ")"
+	}
+	field(pdf, "Площадка", issuer)
+	field(pdf, "Заказчик", party(data.Customer))
+	field(pdf, "Исполнитель", party(data.Executor))
+	field(pdf, "Основание", fmt.Sprintf("контракт №%d по задаче «%s»", data.ContractID, data.TaskTitle))
+	pdf.Ln(6)
+
+	totals := data.Totals()
+	rows := [][2]string{
+		{fmt.Sprintf("Работы по задаче «%s»", data.TaskTitle), formatMoney(totals.Gross, data.Currency)},
+		{fmt.Sprintf("Комиссия площадки (%s%%)", percent(data.FeeRateBP)), formatMoney(totals.Fee, data.Currency)},
+		{"Получено исполнителем", formatMoney(totals.Net, data.Currency)},
+	}
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.CellFormat(130, 8, "Наименование", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 8, "Сумма", "1", 1, "R", false, 0, "")
+	pdf.SetFont(fontFamily, "", 11)
+	for _, row := range rows {
+		pdf.CellFormat(130, 8, row[0], "1", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 8, row[1], "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	if doc.Kind == domain.KindReceipt {
+		pdf.MultiCell(0, 6, fmt.Sprintf("Исполнителю перечислено %s за вычетом комиссии площадки %s.",
+			formatMoney(totals.Net, data.Currency), formatMoney(totals.Fee, data.Currency)), "", "L", false)
+	} else {
+		pdf.MultiCell(0, 6, fmt.Sprintf("Итого оплачено заказчиком за принятые работы: %s. Комиссия площадки включена в сумму.",
+			formatMoney(totals.Gross, data.Currency)), "", "L", false)
+	}
+	if data.Refunded > 0 {
+		pdf.MultiCell(0, 6, fmt.Sprintf("Возвращено заказчику: %s.", formatMoney(data.Refunded, data.Currency)), "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("ошибка формирования PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func field(pdf *gofpdf.Fpdf, label, value string) {
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.CellFormat(35, 7, label+":", "", 0, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.MultiCell(0, 7, value, "", "L", false)
+}
+
+func party(p domain.Party) string {
+	if p.Email == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s, %s", p.Name, p.Email)
+}
+
+// formatMoney печатает сумму в минимальных единицах с разделителями разрядов и кодом валюты: 12 345,67 RUB.
+// Символ валюты не используется: в документах валюту принято указывать кодом.
+func formatMoney(amount int, currency string) string {
+	return money.New(amount, money.Currency(currency)).Number(money.LocaleRU) + " " + currency
+}
+
+// percent печатает ставку в базисных пунктах как процент: 1050 -> 10.5.
+func percent(bp int) string {
+	return strconv.FormatFloat(float64(bp)/100, 'f', -1, 64)
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/documents/domain"
+)
+
+func TestPDFRendererRender(t *testing.T) {
+	renderer, err := NewPDFRenderer("../../../web/static/fonts")
+	if err != nil {
+		t.Fatalf("не удалось загрузить шрифты: %v", err)
+	}
+
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doc := domain.Document{ContractID: 7, Kind: domain.KindReceipt, Number: domain.FormatNumber(domain.KindReceipt, 2026, 1), IssuedAt: issued}
+	data := domain.DocumentData{
+		ContractID: 7,
+		TaskTitle:  "Ремонт ванной",
+		Customer:   domain.Party{UserID: 1, Name: "Иван Петров", Email: "ivan@example.com"},
+		Executor:   domain.Party{UserID: 2, Name: "Мастер"},
+		Released:   12000,
+		Fee:        1200,
+		FeeRateBP:  1000,
+		Currency:   "RUB",
+	}
+
+	first, err := renderer.Render(doc, data)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !bytes.HasPrefix(first, []byte("%PDF-")) {
+		t.Fatalf("результат не похож на PDF: %q", first[:min(len(first), 16)])
+	}
+	second, err := renderer.Render(doc, data)
+	if err != nil || !bytes.Equal(first, second) {
+		t.Error("один и тот же документ должен формироваться одинаково")
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	cases := map[int]string{0: "0 RUB", 999: "9,99 RUB", 100000: "1 000 RUB", 123456789: "1 234 567,89 RUB", -150000: "-1 500 RUB"}
+	for amount, want := range cases {
+		if got := formatMoney(amount, "RUB"); got != want {
+			t.Errorf("formatMoney(%d) = %q, ожидалось %q", amount, got, want)
+		}
+	}
+}
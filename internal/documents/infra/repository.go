@@ -0,0 +1,138 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/documents/domain"
+)
+
+// documentColumns — поля документа в порядке, который ожидает scanDocument.
+const documentColumns = `id, contract_id, kind, year, sequence, number, url, amount, fee, currency, issued_at`
+
+// partyName — имя стороны для печати: имя и фамилия, а если их нет — логин.
+const partyName = `COALESCE(NULLIF(TRIM(CONCAT_WS(' ', %[1]s.first_name, %[1]s.last_name)), ''), %[1]s.username, '')`
+
+// DocumentsRepository хранит закрывающие документы в PostgreSQL.
+type DocumentsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDocumentsRepository создаёт новый репозиторий документов.
+func NewDocumentsRepository(db *pgxpool.Pool) *DocumentsRepository {
+	return &DocumentsRepository{db: db}
+}
+
+// GetDocumentData собирает данные для документов: платёж по контракту, задачу, стороны и сведения о компании.
+func (r *DocumentsRepository) GetDocumentData(ctx context.Context, contractID int64) (*domain.DocumentData, error) {
+	var d domain.DocumentData
+	err := r.db.QueryRow(ctx, `
+        SELECT p.contract_id, p.task_id, t.title, p.amount, p.released_amount, p.refunded_amount,
+               p.fee_amount, p.fee_rate_bp, p.currency, p.updated_at,
+               c.id, `+fmt.Sprintf(partyName, "c")+`, COALESCE(c.email, ''),
+               e.id, `+fmt.Sprintf(partyName, "e")+`, COALESCE(e.email, '')
+        FROM payments p
+        JOIN tasks t ON t.id = p.task_id
+        JOIN users c ON c.id = p.customer_id
+        JOIN users e ON e.id = p.executor_id
+        WHERE p.contract_id = $1`, contractID).Scan(
+		&d.ContractID, &d.TaskID, &d.TaskTitle, &d.Amount, &d.Released, &d.Refunded,
+		&d.Fee, &d.FeeRateBP, &d.Currency, &d.CompletedAt,
+		&d.Customer.UserID, &d.Customer.Name, &d.Customer.Email,
+		&d.Executor.UserID, &d.Executor.Name, &d.Executor.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrContractNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении данных для документов по контракту %d: %w", contractID, err)
+	}
+
+	err = r.db.QueryRow(ctx, `SELECT name, website_url FROM company LIMIT 1`).Scan(&d.Issuer.Name, &d.Issuer.WebsiteURL)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("ошибка при получении сведений о компании: %w", err)
+	}
+	return &d, nil
+}
+
+// CreateDocument выпускает документ. Счётчик номеров блокируется до конца транзакции,
+// поэтому параллельные выпуски получают номера по очереди, а откат возвращает номер в счётчик.
+func (r *DocumentsRepository) CreateDocument(ctx context.Context, doc domain.Document, store func(domain.Document) (string, error)) (*domain.Document, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO document_counters (kind, year, last_number)
+        VALUES ($1, $2, 1)
+        ON CONFLICT (kind, year) DO UPDATE SET last_number = document_counters.last_number + 1
+        RETURNING last_number`, doc.Kind, doc.Year).Scan(&doc.Sequence)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при выдаче номера документа: %w", err)
+	}
+
+	// Проверка после блокировки счётчика видит документы, выпущенные параллельно.
+	existing, err := scanDocument(tx.QueryRow(ctx, `
+        SELECT `+documentColumns+` FROM contract_documents
+        WHERE contract_id = $1 AND kind = $2`, doc.ContractID, doc.Kind))
+	if err == nil {
+		return &existing, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("ошибка при проверке документа по контракту %d: %w", doc.ContractID, err)
+	}
+
+	doc.Number = domain.FormatNumber(doc.Kind, doc.Year, doc.Sequence)
+	doc.URL, err = store(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created, err := scanDocument(tx.QueryRow(ctx, `
+        INSERT INTO contract_documents (contract_id, kind, year, sequence, number, url, amount, fee, currency, issued_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING `+documentColumns,
+		doc.ContractID, doc.Kind, doc.Year, doc.Sequence, doc.Number, doc.URL, doc.Amount, doc.Fee, doc.Currency, doc.IssuedAt))
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при записи документа %s: %w", doc.Number, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("не удалось сохранить документ %s: %w", doc.Number, err)
+	}
+	return &created, true, nil
+}
+
+// ListDocuments возвращает документы по контракту в порядке выпуска.
+func (r *DocumentsRepository) ListDocuments(ctx context.Context, contractID int64) ([]domain.Document, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+documentColumns+`
+        FROM contract_documents
+        WHERE contract_id = $1
+        ORDER BY issued_at, id`, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении документов по контракту %d: %w", contractID, err)
+	}
+	defer rows.Close()
+
+	documents := []domain.Document{}
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании документа: %w", err)
+		}
+		documents = append(documents, d)
+	}
+	return documents, rows.Err()
+}
+
+func scanDocument(row pgx.Row) (domain.Document, error) {
+	var d domain.Document
+	err := row.Scan(&d.ID, &d.ContractID, &d.Kind, &d.Year, &d.Sequence, &d.Number, &d.URL, &d.Amount, &d.Fee, &d.Currency, &d.IssuedAt)
+	return d, err
+}
@@ -2,9 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/unclaim/chegonado.git/internal/filestorage/domain"
 	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
@@ -117,3 +119,150 @@ func (h *FileStorageHandler) DeleteAvatarHandler(w http.ResponseWriter, r *http.
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`{"message": "Аватар успешно удален"}`))
 }
+
+// multipartOverhead — запас на заголовки multipart-формы сверх размера самого вложения.
+const multipartOverhead = 1024 * 1024
+
+// ListTaskAttachments возвращает вложения задачи.
+func (h *FileStorageHandler) ListTaskAttachments(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(w, r, domain.OwnerTask)
+}
+
+// UploadTaskAttachment прикрепляет файл к задаче. Доступно только заказчику.
+func (h *FileStorageHandler) UploadTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, domain.OwnerTask)
+}
+
+// DeleteTaskAttachment удаляет вложение задачи. Доступно только заказчику.
+func (h *FileStorageHandler) DeleteTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	h.deleteAttachment(w, r, domain.OwnerTask)
+}
+
+// ListReportAttachments возвращает вложения отчёта заказчику и исполнителю.
+func (h *FileStorageHandler) ListReportAttachments(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(w, r, domain.OwnerReport)
+}
+
+// UploadReportAttachment прикрепляет файл к отчёту. Доступно только исполнителю.
+func (h *FileStorageHandler) UploadReportAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, domain.OwnerReport)
+}
+
+// DeleteReportAttachment удаляет вложение отчёта. Доступно только исполнителю.
+func (h *FileStorageHandler) DeleteReportAttachment(w http.ResponseWriter, r *http.Request) {
+	h.deleteAttachment(w, r, domain.OwnerReport)
+}
+
+func (h *FileStorageHandler) listAttachments(w http.ResponseWriter, r *http.Request, ownerType domain.OwnerType) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("отсутствует авторизация: %v", err), http.StatusUnauthorized)
+		return
+	}
+
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := h.service.ListAttachments(ctx, domain.AttachmentOwner{Type: ownerType, ID: ownerID}, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить вложения: %w", err), attachmentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, attachments)
+}
+
+func (h *FileStorageHandler) uploadAttachment(w http.ResponseWriter, r *http.Request, ownerType domain.OwnerType) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("отсутствует авторизация: %v", err), http.StatusUnauthorized)
+		return
+	}
+
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAttachmentSize+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			common_errors.NewAppError(w, r, domain.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge)
+		case errors.Is(err, http.ErrMissingFile):
+			common_errors.NewAppError(w, r, fmt.Errorf("файл не предоставлен"), http.StatusBadRequest)
+		default:
+			common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении файла: %w", err), http.StatusBadRequest)
+		}
+		return
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil {
+			slog.Error("Ошибка закрытия файла: %v", slog.Any("error", cerr))
+		}
+	}()
+
+	owner := domain.AttachmentOwner{Type: ownerType, ID: ownerID}
+	attachment, err := h.service.UploadAttachment(ctx, owner, sess.UserID, file, header.Filename, header.Size)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при загрузке вложения: %w", err), attachmentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, attachment)
+}
+
+func (h *FileStorageHandler) deleteAttachment(w http.ResponseWriter, r *http.Request, ownerType domain.OwnerType) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("отсутствует авторизация: %v", err), http.StatusUnauthorized)
+		return
+	}
+
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор: %w", err), http.StatusBadRequest)
+		return
+	}
+	attachmentID, err := strconv.ParseInt(r.PathValue("attachment_id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор вложения: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	owner := domain.AttachmentOwner{Type: ownerType, ID: ownerID}
+	if err := h.service.DeleteAttachment(ctx, owner, attachmentID, sess.UserID); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось удалить вложение: %w", err), attachmentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachmentErrorStatus сопоставляет ошибки вложений с HTTP-статусами.
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrOwnerNotFound), errors.Is(err, domain.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAttachmentForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrAttachmentType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrAttachmentLimit), errors.Is(err, domain.ErrAttachmentsFrozen):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrAttachmentNameLength):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	MaxAttachmentSize      = 20 * 1024 * 1024 // 20 Мб
+	MaxAttachmentsPerOwner = 20
+	maxAttachmentNameLen   = 255
+)
+
+// OwnerType — к чему прикреплено вложение.
+type OwnerType string
+
+const (
+	OwnerTask   OwnerType = "task"   // Фото, документы и планы заказчика
+	OwnerReport OwnerType = "report" // Подтверждения выполнения от исполнителя
+)
+
+// AttachmentOwner — объект, к которому прикреплены вложения.
+type AttachmentOwner struct {
+	Type OwnerType
+	ID   int64
+}
+
+// storageDir возвращает каталог вложений владельца в хранилище.
+func (o AttachmentOwner) storageDir() string {
+	return fmt.Sprintf("%ss/%d/attachments", o.Type, o.ID)
+}
+
+var (
+	ErrOwnerNotFound        = errors.New("объект для вложений не найден")
+	ErrAttachmentNotFound   = errors.New("вложение не найдено")
+	ErrAttachmentForbidden  = errors.New("нет доступа к вложениям")
+	ErrAttachmentTooLarge   = fmt.Errorf("размер вложения превышает %d Мб", MaxAttachmentSize/1024/1024)
+	ErrAttachmentType       = errors.New("недопустимый тип вложения")
+	ErrAttachmentLimit      = fmt.Errorf("можно прикрепить не больше %d файлов", MaxAttachmentsPerOwner)
+	ErrAttachmentsFrozen    = errors.New("вложения нельзя менять после подтверждения отчёта")
+	ErrAttachmentNameLength = fmt.Errorf("имя файла длиннее %d символов", maxAttachmentNameLen)
+)
+
+// Attachment — файл, прикреплённый к задаче или отчёту.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	OwnerType   OwnerType `json:"owner_type"`
+	OwnerID     int64     `json:"owner_id"`
+	UploaderID  int64     `json:"uploader_id"`
+	FileName    string    `json:"file_name"` // Исходное имя файла
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	StoragePath string    `json:"-"` // Ключ в хранилище, по нему файл удаляется
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// attachmentType описывает допустимый тип вложения.
+// sniffed — результат http.DetectContentType, которому должно соответствовать содержимое;
+// пустое значение означает, что по содержимому формат не распознаётся и проверяется только расширение.
+type attachmentType struct {
+	contentType string
+	sniffed     []string
+}
+
+// attachmentTypes — допустимые расширения: фотографии, PDF, офисные документы и чертежи.
+var attachmentTypes = map[string]attachmentType{
+	"jpg":  {"image/jpeg", []string{"image/jpeg"}},
+	"jpeg": {"image/jpeg", []string{"image/jpeg"}},
+	"png":  {"image/png", []string{"image/png"}},
+	"webp": {"image/webp", []string{"image/webp"}},
+	"gif":  {"image/gif", []string{"image/gif"}},
+	"heic": {"image/heic", nil},
+	"pdf":  {"application/pdf", []string{"application/pdf"}},
+	"docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"application/zip"}},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"application/zip"}},
+	"doc":  {"application/msword", []string{"application/octet-stream"}},
+	"xls":  {"application/vnd.ms-excel", []string{"application/octet-stream"}},
+	"txt":  {"text/plain", []string{"text/plain; charset=utf-8", "text/plain; charset=utf-16be", "text/plain; charset=utf-16le"}},
+	"dwg":  {"image/vnd.dwg", nil},
+	"dxf":  {"image/vnd.dxf", nil},
+}
+
+// DetectAttachmentType проверяет расширение файла и его начало (до 512 байт)
+// и возвращает MIME-тип, с которым вложение будет сохранено.
+func DetectAttachmentType(filename string, head []byte) (string, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	t, ok := attachmentTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("%w: расширение %q не поддерживается", ErrAttachmentType, ext)
+	}
+	if len(t.sniffed) == 0 {
+		return t.contentType, nil
+	}
+
+	sniffed := http.DetectContentType(head)
+	for _, s := range t.sniffed {
+		if s == sniffed {
+			return t.contentType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: содержимое (%s) не соответствует расширению .%s", ErrAttachmentType, sniffed, ext)
+}
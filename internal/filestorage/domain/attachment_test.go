@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectAttachmentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf := []byte("%PDF-1.7\n")
+
+	cases := []struct {
+		name     string
+		filename string
+		head     []byte
+		want     string
+		wantErr  bool
+	}{
+		{"фото", "План.PNG", png, "image/png", false},
+		{"pdf", "смета.pdf", pdf, "application/pdf", false},
+		{"чертёж без сигнатуры", "этаж.dwg", []byte{0x41, 0x43, 0x31, 0x30}, "image/vnd.dwg", false},
+		{"исполняемый файл", "setup.exe", []byte("MZ"), "", true},
+		{"pdf под видом фото", "photo.jpg", pdf, "", true},
+		{"без расширения", "README", pdf, "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := DetectAttachmentType(c.filename, c.head)
+			if c.wantErr {
+				if !errors.Is(err, ErrAttachmentType) {
+					t.Fatalf("ожидалась ErrAttachmentType, получено %v", err)
+				}
+				return
+			}
+			if err != nil || got != c.want {
+				t.Fatalf("ожидалось %q, получено %q, %v", c.want, got, err)
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@ type FileStorageService interface {
 	GetAvatarURL(ctx context.Context, userID int64) (string, error)
 	DeleteAvatar(ctx context.Context, userID int64) error
 	CreateDefaultAvatar(ctx context.Context, email string, userID int64) (string, error)
+
+	UploadAttachment(ctx context.Context, owner AttachmentOwner, userID int64, file io.Reader, filename string, size int64) (*Attachment, error)
+	ListAttachments(ctx context.Context, owner AttachmentOwner, userID int64) ([]Attachment, error)
+	DeleteAttachment(ctx context.Context, owner AttachmentOwner, attachmentID, userID int64) error
 }
 
 // FileStorageRepository определяет контракт для взаимодействия с файловым хранилищем.
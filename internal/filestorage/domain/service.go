@@ -1,13 +1,19 @@
 package domain
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"path/filepath"
 	"strings"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/unclaim/chegonado.git/internal/filestorage/infra"
 )
@@ -154,3 +160,190 @@ func (s *Service) CreateDefaultAvatar(ctx context.Context, email string, userID
 
 	return avatarURL, nil
 }
+
+// UploadAttachment проверяет файл и права пользователя, сохраняет вложение в хранилище и в БД.
+func (s *Service) UploadAttachment(ctx context.Context, owner AttachmentOwner, userID int64, file io.Reader, filename string, size int64) (*Attachment, error) {
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if len([]rune(filename)) > maxAttachmentNameLen {
+		return nil, ErrAttachmentNameLength
+	}
+	if size > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	if err := s.checkAttachmentAccess(ctx, owner, userID, true); err != nil {
+		return nil, err
+	}
+
+	var count int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM attachments WHERE owner_type = $1 AND owner_id = $2", string(owner.Type), owner.ID).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подсчитать вложения: %w", err)
+	}
+	if count >= MaxAttachmentsPerOwner {
+		return nil, ErrAttachmentLimit
+	}
+
+	// Тип определяется по расширению и первым байтам содержимого, заявленному клиентом типу не доверяем.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("ошибка при чтении файла: %w", err)
+	}
+	head = head[:n]
+	contentType, err := DetectAttachmentType(filename, head)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := randomFileName()
+	if err != nil {
+		return nil, err
+	}
+	storagePath := fmt.Sprintf("%s/%s%s", owner.storageDir(), name, strings.ToLower(filepath.Ext(filename)))
+
+	url, err := s.repo.SaveFile(ctx, storagePath, io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении вложения: %w", err)
+	}
+
+	attachment := Attachment{
+		OwnerType:   owner.Type,
+		OwnerID:     owner.ID,
+		UploaderID:  userID,
+		FileName:    filename,
+		ContentType: contentType,
+		Size:        size,
+		URL:         url,
+		StoragePath: storagePath,
+	}
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO attachments (owner_type, owner_id, uploader_id, file_name, content_type, size, url, storage_path)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`,
+		string(owner.Type), owner.ID, userID, filename, contentType, size, url, storagePath).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		if delErr := s.repo.DeleteFile(ctx, storagePath); delErr != nil {
+			slog.Warn("Не удалось удалить вложение из хранилища после ошибки в БД", "path", storagePath, "error", delErr)
+		}
+		return nil, fmt.Errorf("ошибка при сохранении сведений о вложении: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+// ListAttachments возвращает вложения задачи или отчёта в порядке загрузки.
+func (s *Service) ListAttachments(ctx context.Context, owner AttachmentOwner, userID int64) ([]Attachment, error) {
+	if err := s.checkAttachmentAccess(ctx, owner, userID, false); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, owner_type, owner_id, uploader_id, file_name, content_type, size, url, storage_path, created_at
+		FROM attachments
+		WHERE owner_type = $1 AND owner_id = $2
+		ORDER BY id`, string(owner.Type), owner.ID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить вложения: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		var a Attachment
+		var ownerType string
+		err := rows.Scan(&a.ID, &ownerType, &a.OwnerID, &a.UploaderID, &a.FileName, &a.ContentType, &a.Size, &a.URL, &a.StoragePath, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении вложения: %w", err)
+		}
+		a.OwnerType = OwnerType(ownerType)
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// DeleteAttachment удаляет вложение из БД и хранилища.
+func (s *Service) DeleteAttachment(ctx context.Context, owner AttachmentOwner, attachmentID, userID int64) error {
+	if err := s.checkAttachmentAccess(ctx, owner, userID, true); err != nil {
+		return err
+	}
+
+	var storagePath string
+	err := s.db.QueryRow(ctx, `
+		DELETE FROM attachments
+		WHERE id = $1 AND owner_type = $2 AND owner_id = $3
+		RETURNING storage_path`, attachmentID, string(owner.Type), owner.ID).Scan(&storagePath)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAttachmentNotFound
+		}
+		return fmt.Errorf("не удалось удалить вложение: %w", err)
+	}
+
+	// Запись уже удалена: осиротевший файл не должен превращаться в ошибку для пользователя.
+	if err := s.repo.DeleteFile(ctx, storagePath); err != nil {
+		slog.Warn("Не удалось удалить файл вложения из хранилища", "path", storagePath, "error", err)
+	}
+	return nil
+}
+
+// checkAttachmentAccess проверяет права пользователя на вложения владельца.
+// Вложения задачи видны всем, менять их может только заказчик.
+// Вложения отчёта видят заказчик и исполнитель по контракту, менять может только исполнитель
+// и только пока заказчик не подтвердил отчёт.
+func (s *Service) checkAttachmentAccess(ctx context.Context, owner AttachmentOwner, userID int64, modify bool) error {
+	switch owner.Type {
+	case OwnerTask:
+		var customerID int64
+		err := s.db.QueryRow(ctx, "SELECT user_id FROM tasks WHERE id = $1", owner.ID).Scan(&customerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrOwnerNotFound
+			}
+			return fmt.Errorf("не удалось получить задачу: %w", err)
+		}
+		if modify && customerID != userID {
+			return ErrAttachmentForbidden
+		}
+		return nil
+
+	case OwnerReport:
+		var executorID, customerID int64
+		var confirmed sql.NullBool
+		err := s.db.QueryRow(ctx, `
+			SELECT c.executor_id, c.customer_id, r.customer_confirmation
+			FROM reports r
+			JOIN contracts c ON c.id = r.contract_id
+			WHERE r.id = $1`, owner.ID).Scan(&executorID, &customerID, &confirmed)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrOwnerNotFound
+			}
+			return fmt.Errorf("не удалось получить отчёт: %w", err)
+		}
+		if !modify {
+			if userID != executorID && userID != customerID {
+				return ErrAttachmentForbidden
+			}
+			return nil
+		}
+		if userID != executorID {
+			return ErrAttachmentForbidden
+		}
+		if confirmed.Valid && confirmed.Bool {
+			return ErrAttachmentsFrozen
+		}
+		return nil
+	}
+	return fmt.Errorf("неизвестный тип владельца вложений: %s", owner.Type)
+}
+
+// randomFileName возвращает случайное имя файла в хранилище, чтобы ключи нельзя было подобрать.
+func randomFileName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать имя файла: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
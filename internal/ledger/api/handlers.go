@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// statementLimit — размер страницы выписки по умолчанию.
+const statementLimit = 50
+
+// LedgerHandler отвечает за обработку HTTP-запросов к кошелькам и главной книге.
+type LedgerHandler struct {
+	service domain.LedgerService
+}
+
+// NewLedgerHandler создаёт новый экземпляр LedgerHandler.
+func NewLedgerHandler(service domain.LedgerService) *LedgerHandler {
+	return &LedgerHandler{service: service}
+}
+
+// GetWallet возвращает баланс кошелька текущего пользователя.
+func (h *LedgerHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	currency, err := walletCurrency(r)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	wallet, err := h.service.GetWallet(r.Context(), sess.UserID, currency)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, ledgerErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, wallet)
+}
+
+// GetStatement возвращает выписку по кошельку текущего пользователя с остатком после каждой операции.
+func (h *LedgerHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), statementLimit)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	currency, err := walletCurrency(r)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.service.GetStatement(r.Context(), sess.UserID, currency, page)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, ledgerErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, statement)
+}
+
+// GetTrialBalance возвращает балансы всех счетов для сверки. Доступно администраторам.
+func (h *LedgerHandler) GetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	tb, err := h.service.GetTrialBalance(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, ledgerErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, tb)
+}
+
+// walletCurrency возвращает валюту кошелька из параметра currency; по умолчанию — рубли.
+func walletCurrency(r *http.Request) (string, error) {
+	raw := r.URL.Query().Get("currency")
+	if raw == "" {
+		return domain.DefaultCurrency, nil
+	}
+	currency, err := money.ParseCurrency(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(currency), nil
+}
+
+func ledgerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrLedgerForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
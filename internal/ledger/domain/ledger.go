@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AccountKind — вид счёта в главной книге.
+type AccountKind string
+
+const (
+	AccountWallet     AccountKind = "wallet"     // Кошелёк пользователя: сколько площадка должна пользователю
+	AccountEscrow     AccountKind = "escrow"     // Средства, заблокированные по контрактам
+	AccountCommission AccountKind = "commission" // Комиссия площадки
+	AccountExternal   AccountKind = "external"   // Деньги за пределами площадки: карты и счета в платёжном шлюзе
+	AccountPayout     AccountKind = "payout"     // Средства, зарезервированные под заявки на вывод
+)
+
+// EntryKind — вид проводки.
+type EntryKind string
+
+const (
+	EntryDeposit      EntryKind = "deposit"      // Поступление от заказчика через платёжный шлюз
+	EntryHold         EntryKind = "hold"         // Блокировка оплаты контракта в эскроу
+	EntryRelease      EntryKind = "release"      // Выплата исполнителю из эскроу
+	EntryRefund       EntryKind = "refund"       // Возврат заказчику из эскроу
+	EntryWithdrawal   EntryKind = "withdrawal"   // Вывод средств из кошелька через платёжный шлюз
+	EntryFee          EntryKind = "fee"          // Комиссия площадки с выплаты исполнителю
+	EntrySubscription EntryKind = "subscription" // Оплата подписки Pro
+
+	EntryPayoutReserve EntryKind = "payout_reserve" // Резерв средств кошелька под заявку на вывод
+	EntryPayout        EntryKind = "payout"         // Отправка выплаты через платёжный шлюз
+	EntryPayoutReturn  EntryKind = "payout_return"  // Возврат резерва на кошелёк: заявка отклонена или выплата не прошла
+)
+
+// DefaultCurrency — валюта проводок, если она не указана явно.
+const DefaultCurrency = "RUB"
+
+var (
+	ErrInvalidEntry      = errors.New("некорректная проводка")
+	ErrUnbalancedEntry   = errors.New("сумма дебета и кредита проводки не сходится")
+	ErrLedgerForbidden   = errors.New("сводка по главной книге доступна только администраторам")
+	ErrInsufficientFunds = errors.New("недостаточно средств на кошельке")
+)
+
+// AdminUserType — тип пользователя, которому доступна сводка по главной книге.
+const AdminUserType = "ADMIN"
+
+// AccountRef — ссылка на счёт. Счёт создаётся при первой проводке по нему.
+// У системных счетов (эскроу, комиссия, резерв выплат, внешний) UserID равен 0.
+type AccountRef struct {
+	Kind   AccountKind `json:"kind"`
+	UserID int64       `json:"user_id,omitempty"`
+}
+
+// Wallet — кошелёк пользователя.
+func Wallet(userID int64) AccountRef { return AccountRef{Kind: AccountWallet, UserID: userID} }
+
+// Escrow — эскроу-счёт площадки.
+func Escrow() AccountRef { return AccountRef{Kind: AccountEscrow} }
+
+// Commission — счёт комиссии площадки.
+func Commission() AccountRef { return AccountRef{Kind: AccountCommission} }
+
+// PayoutReserve — счёт средств, зарезервированных под заявки на вывод.
+func PayoutReserve() AccountRef { return AccountRef{Kind: AccountPayout} }
+
+// External — внешний счёт: его баланс с обратным знаком показывает, сколько денег находится на площадке.
+func External() AccountRef { return AccountRef{Kind: AccountExternal} }
+
+// String возвращает читаемое имя счёта.
+func (a AccountRef) String() string {
+	if a.Kind == AccountWallet {
+		return fmt.Sprintf("wallet:%d", a.UserID)
+	}
+	return string(a.Kind)
+}
+
+// Posting — строка проводки. Положительная сумма увеличивает баланс счёта, отрицательная — уменьшает.
+type Posting struct {
+	Account AccountRef `json:"account"`
+	Amount  int        `json:"amount"`
+}
+
+// Entry — проводка главной книги. После записи не меняется и не удаляется:
+// ошибочную проводку исправляют сторнирующей.
+type Entry struct {
+	ID             int64     `json:"id"`
+	Kind           EntryKind `json:"kind"`
+	IdempotencyKey string    `json:"-"` // Повторная проводка с тем же ключом не записывается
+	ContractID     *int64    `json:"contract_id,omitempty"`
+	Description    string    `json:"description"`
+	Currency       string    `json:"currency"`
+	CreatedAt      time.Time `json:"created_at"`
+	Postings       []Posting `json:"postings"`
+}
+
+// Transfer — проводка, переносящая сумму с одного счёта на другой.
+func Transfer(kind EntryKind, key string, from, to AccountRef, amount int, description string) Entry {
+	return Entry{
+		Kind:           kind,
+		IdempotencyKey: key,
+		Description:    description,
+		Currency:       DefaultCurrency,
+		Postings: []Posting{
+			{Account: from, Amount: -amount},
+			{Account: to, Amount: amount},
+		},
+	}
+}
+
+// ForContract привязывает проводку к контракту.
+func (e Entry) ForContract(contractID int64) Entry {
+	e.ContractID = &contractID
+	return e
+}
+
+// InCurrency проводит сумму в указанной валюте: у каждого счёта свой баланс в каждой валюте.
+func (e Entry) InCurrency(currency string) Entry {
+	if currency != "" {
+		e.Currency = currency
+	}
+	return e
+}
+
+// Validate проверяет проводку: не меньше двух ненулевых строк, сумма строк равна нулю.
+func (e Entry) Validate() error {
+	if e.Kind == "" || e.IdempotencyKey == "" {
+		return fmt.Errorf("%w: не указан вид или ключ идемпотентности", ErrInvalidEntry)
+	}
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: в проводке должно быть не меньше двух строк", ErrInvalidEntry)
+	}
+	sum := 0
+	for _, p := range e.Postings {
+		if p.Amount == 0 {
+			return fmt.Errorf("%w: нулевая сумма по счёту %s", ErrInvalidEntry, p.Account)
+		}
+		if p.Account.Kind == AccountWallet && p.Account.UserID <= 0 {
+			return fmt.Errorf("%w: кошелёк без владельца", ErrInvalidEntry)
+		}
+		sum += p.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w: расхождение %d", ErrUnbalancedEntry, sum)
+	}
+	return nil
+}
+
+// WalletBalance — баланс кошелька пользователя.
+type WalletBalance struct {
+	UserID   int64  `json:"user_id"`
+	Currency string `json:"currency"`
+	Balance  int    `json:"balance"`
+}
+
+// StatementLine — строка выписки по кошельку с остатком после операции.
+type StatementLine struct {
+	ID          int64     `json:"id"` // Идентификатор строки проводки
+	EntryID     int64     `json:"entry_id"`
+	Kind        EntryKind `json:"kind"`
+	ContractID  *int64    `json:"contract_id,omitempty"`
+	Description string    `json:"description"`
+	Amount      int       `json:"amount"`
+	Balance     int       `json:"balance"` // Остаток после операции
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AccountBalance — баланс счёта в сводке.
+type AccountBalance struct {
+	Account  AccountRef `json:"account"`
+	Currency string     `json:"currency"`
+	Balance  int        `json:"balance"`
+}
+
+// TrialBalance — оборотно-сальдовая сводка: в каждой валюте сумма балансов всех счетов равна нулю.
+type TrialBalance struct {
+	Accounts []AccountBalance `json:"accounts"`
+	Totals   map[string]int   `json:"totals"` // Итог по каждой валюте
+	Balanced bool             `json:"balanced"`
+}
+
+// NewTrialBalance собирает сводку по балансам счетов.
+// Суммы в разных валютах не складываются: сводка сходится, только если сходится каждая валюта.
+func NewTrialBalance(accounts []AccountBalance) TrialBalance {
+	if accounts == nil {
+		accounts = []AccountBalance{}
+	}
+	totals := map[string]int{}
+	for _, a := range accounts {
+		totals[a.Currency] += a.Balance
+	}
+	balanced := true
+	for _, total := range totals {
+		if total != 0 {
+			balanced = false
+		}
+	}
+	return TrialBalance{Accounts: accounts, Totals: totals, Balanced: balanced}
+}
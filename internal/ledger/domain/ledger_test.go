@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntryValidate(t *testing.T) {
+	entry := Transfer(EntryRelease, "contract-1-milestone-1-release", Escrow(), Wallet(7), 5000, "Выплата")
+	if err := entry.Validate(); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	unbalanced := entry
+	unbalanced.Postings = []Posting{{Account: Escrow(), Amount: -5000}, {Account: Wallet(7), Amount: 4000}}
+	if err := unbalanced.Validate(); !errors.Is(err, ErrUnbalancedEntry) {
+		t.Errorf("ожидалась ErrUnbalancedEntry, получено %v", err)
+	}
+
+	invalid := map[string]Entry{
+		"одна строка":           {Kind: EntryHold, IdempotencyKey: "k", Postings: []Posting{{Account: Escrow(), Amount: 1}}},
+		"нулевая сумма":         Transfer(EntryHold, "k", Wallet(1), Escrow(), 0, ""),
+		"без ключа":             Transfer(EntryHold, "", Wallet(1), Escrow(), 100, ""),
+		"кошелёк без владельца": Transfer(EntryHold, "k", Wallet(0), Escrow(), 100, ""),
+	}
+	for name, e := range invalid {
+		if err := e.Validate(); !errors.Is(err, ErrInvalidEntry) {
+			t.Errorf("%s: ожидалась ErrInvalidEntry, получено %v", name, err)
+		}
+	}
+}
+
+func TestNewTrialBalance(t *testing.T) {
+	tb := NewTrialBalance([]AccountBalance{
+		{Account: External(), Currency: "RUB", Balance: -10000},
+		{Account: Escrow(), Currency: "RUB", Balance: 6000},
+		{Account: Wallet(7), Currency: "RUB", Balance: 4000},
+		{Account: External(), Currency: "USD", Balance: -500},
+		{Account: Wallet(7), Currency: "USD", Balance: 500},
+	})
+	if !tb.Balanced || tb.Totals["RUB"] != 0 || tb.Totals["USD"] != 0 {
+		t.Errorf("сводка должна сходиться: %+v", tb)
+	}
+	if NewTrialBalance([]AccountBalance{{Account: Escrow(), Currency: "RUB", Balance: 1}}).Balanced {
+		t.Error("сводка с ненулевым итогом не сходится")
+	}
+	// Суммы в разных валютах не взаимозачитываются.
+	if NewTrialBalance([]AccountBalance{
+		{Account: Escrow(), Currency: "RUB", Balance: 100},
+		{Account: Escrow(), Currency: "USD", Balance: -100},
+	}).Balanced {
+		t.Error("несошедшиеся валюты не должны компенсировать друг друга")
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+// LedgerService — интерфейс для бизнес-логики главной книги.
+type LedgerService interface {
+	// Post записывает проводки в одной транзакции. Проводки, ключи которых уже записаны, пропускаются.
+	// Кошелёк не может уйти в минус: такая проводка отклоняется с ErrInsufficientFunds.
+	Post(ctx context.Context, entries ...Entry) error
+	Balance(ctx context.Context, account AccountRef) (int, error)
+
+	GetWallet(ctx context.Context, userID int64, currency string) (*WalletBalance, error)
+	GetStatement(ctx context.Context, userID int64, currency string, page pagination.Request) (pagination.Page[StatementLine], error) // Новые операции первыми
+	GetTrialBalance(ctx context.Context, userID int64) (*TrialBalance, error)                                                         // Только администраторам
+}
+
+// LedgerRepository — интерфейс для хранения главной книги. Проводки только добавляются.
+type LedgerRepository interface {
+	Post(ctx context.Context, entries []Entry) error
+	Balance(ctx context.Context, account AccountRef, currency string) (int, error)
+	ListStatement(ctx context.Context, account AccountRef, currency string, page pagination.Request) (pagination.Page[StatementLine], error)
+	ListBalances(ctx context.Context) ([]AccountBalance, error)
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
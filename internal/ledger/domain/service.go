@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+type ledgerService struct {
+	repo LedgerRepository
+}
+
+// NewLedgerService создаёт сервис главной книги.
+func NewLedgerService(repo LedgerRepository) LedgerService {
+	return &ledgerService{repo: repo}
+}
+
+// Post проверяет и записывает проводки.
+func (s *ledgerService) Post(ctx context.Context, entries ...Entry) error {
+	now := time.Now()
+	for i := range entries {
+		if entries[i].Currency == "" {
+			entries[i].Currency = DefaultCurrency
+		}
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = now
+		}
+		if err := entries[i].Validate(); err != nil {
+			return fmt.Errorf("проводка %q: %w", entries[i].IdempotencyKey, err)
+		}
+	}
+	return s.repo.Post(ctx, entries)
+}
+
+// Balance возвращает баланс счёта.
+func (s *ledgerService) Balance(ctx context.Context, account AccountRef) (int, error) {
+	return s.repo.Balance(ctx, account, DefaultCurrency)
+}
+
+// GetWallet возвращает баланс кошелька пользователя в валюте.
+func (s *ledgerService) GetWallet(ctx context.Context, userID int64, currency string) (*WalletBalance, error) {
+	balance, err := s.repo.Balance(ctx, Wallet(userID), currency)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletBalance{UserID: userID, Currency: currency, Balance: balance}, nil
+}
+
+// GetStatement возвращает выписку по кошельку пользователя в валюте с остатком после каждой операции.
+func (s *ledgerService) GetStatement(ctx context.Context, userID int64, currency string, page pagination.Request) (pagination.Page[StatementLine], error) {
+	return s.repo.ListStatement(ctx, Wallet(userID), currency, page)
+}
+
+// GetTrialBalance возвращает балансы всех счетов для сверки.
+func (s *ledgerService) GetTrialBalance(ctx context.Context, userID int64) (*TrialBalance, error) {
+	isAdmin, err := s.repo.IsAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrLedgerForbidden
+	}
+	balances, err := s.repo.ListBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tb := NewTrialBalance(balances)
+	return &tb, nil
+}
@@ -0,0 +1,204 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+// statementSort — порядок выписки, для которого выдаются курсоры.
+const statementSort = "statement"
+
+// LedgerRepository хранит главную книгу в PostgreSQL.
+type LedgerRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewLedgerRepository создаёт новый репозиторий главной книги.
+func NewLedgerRepository(db *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// Post записывает проводки в одной транзакции. Проводка, ключ которой уже записан, пропускается целиком.
+func (r *LedgerRepository) Post(ctx context.Context, entries []domain.Entry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, e := range entries {
+		var entryID int64
+		err := tx.QueryRow(ctx, `
+            INSERT INTO ledger_entries (kind, idempotency_key, contract_id, description, currency, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (idempotency_key) DO NOTHING
+            RETURNING id`,
+			e.Kind, e.IdempotencyKey, e.ContractID, e.Description, e.Currency, e.CreatedAt).Scan(&entryID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("ошибка при записи проводки %q: %w", e.IdempotencyKey, err)
+		}
+
+		var debited []int64
+		for _, p := range e.Postings {
+			accountID, err := ensureAccount(ctx, tx, p.Account, e.Currency)
+			if err != nil {
+				return err
+			}
+			if p.Account.Kind == domain.AccountWallet && p.Amount < 0 {
+				// Блокировка счёта не даёт параллельным списаниям пройти проверку остатка одновременно.
+				if _, err := tx.Exec(ctx, `SELECT id FROM ledger_accounts WHERE id = $1 FOR UPDATE`, accountID); err != nil {
+					return fmt.Errorf("ошибка при блокировке счёта %s: %w", p.Account, err)
+				}
+				debited = append(debited, accountID)
+			}
+			_, err = tx.Exec(ctx, `
+                INSERT INTO ledger_lines (entry_id, account_id, amount)
+                VALUES ($1, $2, $3)`, entryID, accountID, p.Amount)
+			if err != nil {
+				return fmt.Errorf("ошибка при записи строки проводки по счёту %s: %w", p.Account, err)
+			}
+		}
+
+		for _, accountID := range debited {
+			var balance int
+			err := tx.QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0) FROM ledger_lines WHERE account_id = $1`, accountID).Scan(&balance)
+			if err != nil {
+				return fmt.Errorf("ошибка при проверке остатка счёта: %w", err)
+			}
+			if balance < 0 {
+				return fmt.Errorf("%w: проводка %q", domain.ErrInsufficientFunds, e.IdempotencyKey)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось сохранить проводки: %w", err)
+	}
+	return nil
+}
+
+// Balance возвращает баланс счёта; у счёта без проводок он нулевой.
+func (r *LedgerRepository) Balance(ctx context.Context, account domain.AccountRef, currency string) (int, error) {
+	var balance int
+	err := r.db.QueryRow(ctx, `
+        SELECT COALESCE(SUM(l.amount), 0)
+        FROM ledger_lines l
+        JOIN ledger_accounts a ON a.id = l.account_id
+        WHERE a.kind = $1 AND a.user_id = $2 AND a.currency = $3`,
+		account.Kind, account.UserID, currency).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении баланса счёта %s: %w", account, err)
+	}
+	return balance, nil
+}
+
+// ListStatement возвращает строки по счёту, новые первыми. Остаток считается по всей истории счёта,
+// поэтому он верен на любой странице.
+func (r *LedgerRepository) ListStatement(ctx context.Context, account domain.AccountRef, currency string, page pagination.Request) (pagination.Page[domain.StatementLine], error) {
+	if err := page.CheckSort(statementSort); err != nil {
+		return pagination.Page[domain.StatementLine]{}, err
+	}
+	args := []interface{}{account.Kind, account.UserID, currency, page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil {
+			return pagination.Page[domain.StatementLine]{}, pagination.ErrInvalidCursor
+		}
+		keyset = "WHERE (created_at, id) < ($5, $6)"
+		args = append(args, *page.After.Time, page.After.ID)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        WITH statement AS (
+            SELECT l.id, e.id AS entry_id, e.kind, e.contract_id, e.description, l.amount, e.created_at,
+                   SUM(l.amount) OVER (ORDER BY e.created_at, l.id) AS balance
+            FROM ledger_lines l
+            JOIN ledger_entries e ON e.id = l.entry_id
+            JOIN ledger_accounts a ON a.id = l.account_id
+            WHERE a.kind = $1 AND a.user_id = $2 AND a.currency = $3
+        )
+        SELECT id, entry_id, kind, contract_id, description, amount, balance, created_at
+        FROM statement
+        `+keyset+`
+        ORDER BY created_at DESC, id DESC
+        LIMIT $4`, args...)
+	if err != nil {
+		return pagination.Page[domain.StatementLine]{}, fmt.Errorf("ошибка при получении выписки по счёту %s: %w", account, err)
+	}
+	defer rows.Close()
+
+	var lines []domain.StatementLine
+	for rows.Next() {
+		var line domain.StatementLine
+		if err := rows.Scan(&line.ID, &line.EntryID, &line.Kind, &line.ContractID, &line.Description, &line.Amount, &line.Balance, &line.CreatedAt); err != nil {
+			return pagination.Page[domain.StatementLine]{}, fmt.Errorf("ошибка при сканировании строки выписки: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[domain.StatementLine]{}, fmt.Errorf("ошибка при итерации по строкам выписки: %w", err)
+	}
+
+	return pagination.NewPage(lines, page, func(line domain.StatementLine) pagination.Cursor {
+		return pagination.TimeCursor(statementSort, line.CreatedAt, line.ID)
+	}), nil
+}
+
+// ListBalances возвращает балансы всех счетов: сначала системные, затем кошельки.
+func (r *LedgerRepository) ListBalances(ctx context.Context) ([]domain.AccountBalance, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT a.kind, a.user_id, a.currency, COALESCE(SUM(l.amount), 0)
+        FROM ledger_accounts a
+        LEFT JOIN ledger_lines l ON l.account_id = a.id
+        GROUP BY a.id
+        ORDER BY a.user_id <> 0, a.kind, a.user_id, a.currency`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении балансов счетов: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []domain.AccountBalance
+	for rows.Next() {
+		var b domain.AccountBalance
+		if err := rows.Scan(&b.Account.Kind, &b.Account.UserID, &b.Currency, &b.Balance); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании баланса счёта: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// IsAdmin проверяет, доступна ли пользователю сводка по главной книге.
+func (r *LedgerRepository) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND type = $2)`,
+		userID, domain.AdminUserType).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке прав пользователя %d: %w", userID, err)
+	}
+	return ok, nil
+}
+
+// ensureAccount возвращает идентификатор счёта, создавая его при первой проводке.
+func ensureAccount(ctx context.Context, tx pgx.Tx, account domain.AccountRef, currency string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `
+        INSERT INTO ledger_accounts (kind, user_id, currency)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (kind, user_id, currency) DO UPDATE SET kind = EXCLUDED.kind
+        RETURNING id`, account.Kind, account.UserID, currency).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении счёта %s: %w", account, err)
+	}
+	return id, nil
+}
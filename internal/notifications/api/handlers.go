@@ -1 +1,71 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/notifications/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// NotificationsHandler отвечает за обработку HTTP-запросов, связанных с уведомлениями.
+type NotificationsHandler struct {
+	service domain.NotificationsService
+}
+
+// NewNotificationsHandler создаёт новый экземпляр NotificationsHandler.
+func NewNotificationsHandler(service domain.NotificationsService) *NotificationsHandler {
+	return &NotificationsHandler{service: service}
+}
+
+// ListNotifications возвращает уведомления текущего пользователя.
+func (h *NotificationsHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	notifications, err := h.service.ListNotifications(ctx, sess.UserID, limit, offset)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка загрузки уведомлений: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, notifications)
+}
+
+// MarkRead помечает уведомление прочитанным.
+func (h *NotificationsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор уведомления: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.MarkRead(ctx, id, sess.UserID); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			common_errors.NewAppError(w, r, err, http.StatusNotFound)
+		} else {
+			common_errors.NewAppError(w, r, fmt.Errorf("ошибка при пометке уведомления: %w", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1 +1,34 @@
 package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Виды уведомлений.
+const (
+	KindTaskUpdated      = "task_updated"       // Заказчик изменил условия задачи, на которую пользователь откликнулся
+	KindSavedSearchAlert = "saved_search_alert" // Появились новые задачи по сохранённому поиску
+	KindResponseOffer    = "response_offer"     // Другая сторона сделала ход в переговорах по отклику
+	KindReportRejected   = "report_rejected"    // Заказчик отклонил отчёт по этапу контракта
+	KindDisputeUpdated   = "dispute_updated"    // В споре по контракту появилось новое событие
+	KindBidPlaced        = "bid_placed"         // На торгах заказчика появилась или понизилась ставка
+	KindAuctionClosed    = "auction_closed"     // Торги, в которых участвовал пользователь, завершились
+	KindReviewReceived   = "review_received"    // Вторая сторона контракта оставила скрытый отзыв
+	KindReviewPublished  = "review_published"   // Отзыв о пользователе опубликован
+)
+
+// ErrNotificationNotFound возвращается, если уведомление не найдено или принадлежит другому пользователю.
+var ErrNotificationNotFound = errors.New("уведомление не найдено")
+
+// Notification — уведомление, показываемое пользователю в приложении.
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
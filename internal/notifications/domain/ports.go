@@ -4,12 +4,26 @@ import "context"
 
 // NotificationsService — интерфейс для бизнес-логики уведомлений.
 type NotificationsService interface {
-	// ...
+	Notify(ctx context.Context, userIDs []int64, kind, title, body, link string) error
+	ListNotifications(ctx context.Context, userID int64, limit, offset int) ([]Notification, error)
+	MarkRead(ctx context.Context, notificationID, userID int64) error
+	HandleTaskUpdated(event any)
+	HandleSavedSearchAlert(event any)
+	HandleResponseOffer(event any)
+	HandleReportRejected(event any)
+	HandleDisputeUpdated(event any)
+	HandleBidPlaced(event any)
+	HandleAuctionClosed(event any)
+	HandleReviewCreated(event any)
+	HandleReviewPublished(event any)
 }
 
 // NotificationsRepository — интерфейс для хранения уведомлений.
 type NotificationsRepository interface {
-	// ...
+	InsertNotifications(ctx context.Context, notifications []Notification) error
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]Notification, error)
+	MarkRead(ctx context.Context, notificationID, userID int64) (bool, error)
+	GetUserEmail(ctx context.Context, userID int64) (string, error) // Пустая строка, если email не указан
 }
 
 // EmailAdapter — интерфейс для отправки электронной почты.
@@ -1 +1,309 @@
 package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/bids"
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	"github.com/unclaim/chegonado.git/internal/reviews"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+)
+
+// notificationsService реализует интерфейс NotificationsService.
+type notificationsService struct {
+	repo        NotificationsRepository
+	emailSender ports.EmailSender
+}
+
+// NewNotificationsService создаёт новый сервис уведомлений.
+func NewNotificationsService(repo NotificationsRepository, emailSender ports.EmailSender) NotificationsService {
+	return &notificationsService{repo: repo, emailSender: emailSender}
+}
+
+// Notify создаёт одинаковое уведомление для каждого из пользователей.
+func (s *notificationsService) Notify(ctx context.Context, userIDs []int64, kind, title, body, link string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	notifications := make([]Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, Notification{
+			UserID:    userID,
+			Kind:      kind,
+			Title:     title,
+			Body:      body,
+			Link:      link,
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.repo.InsertNotifications(ctx, notifications); err != nil {
+		return fmt.Errorf("не удалось сохранить уведомления: %w", err)
+	}
+	return nil
+}
+
+// ListNotifications возвращает уведомления пользователя, новые первыми.
+func (s *notificationsService) ListNotifications(ctx context.Context, userID int64, limit, offset int) ([]Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := s.repo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить уведомления: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkRead помечает уведомление пользователя прочитанным.
+func (s *notificationsService) MarkRead(ctx context.Context, notificationID, userID int64) error {
+	ok, err := s.repo.MarkRead(ctx, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось отметить уведомление: %w", err)
+	}
+	if !ok {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// HandleTaskUpdated — обработчик события изменения условий задачи.
+// Сообщает откликнувшимся исполнителям, что их отклик дан на устаревшую редакцию.
+func (s *notificationsService) HandleTaskUpdated(event any) {
+	e, ok := event.(tasks.TaskUpdatedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+
+	title := "Условия задачи изменились"
+	body := fmt.Sprintf("Заказчик изменил условия задачи «%s» (редакция %d). Ваш отклик был дан на предыдущую редакцию — проверьте, актуальны ли цена и сроки.", e.Title, e.Revision)
+	link := fmt.Sprintf("/tasks/%d", e.TaskID)
+
+	if err := s.Notify(context.Background(), e.ResponderIDs, KindTaskUpdated, title, body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении об изменении задачи", "task_id", e.TaskID, "error", err)
+	}
+}
+
+// HandleSavedSearchAlert — обработчик оповещения по сохранённому поиску.
+// Создаёт уведомление в приложении и дублирует его письмом.
+func (s *notificationsService) HandleSavedSearchAlert(event any) {
+	e, ok := event.(tasks.SavedSearchAlertEvent)
+	if !ok || len(e.Tasks) == 0 {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+
+	var title, link string
+	var body strings.Builder
+	if len(e.Tasks) == 1 {
+		title = fmt.Sprintf("Новая задача по поиску «%s»", e.SearchName)
+		link = fmt.Sprintf("/tasks/%d", e.Tasks[0].ID)
+		fmt.Fprintf(&body, "Опубликована задача «%s».", e.Tasks[0].Title)
+	} else {
+		title = fmt.Sprintf("Новые задачи по поиску «%s»: %d", e.SearchName, len(e.Tasks))
+		link = fmt.Sprintf("/saved-searches/%d", e.SearchID)
+		body.WriteString("Опубликованы задачи:")
+		for _, t := range e.Tasks {
+			fmt.Fprintf(&body, "\n— %s (/tasks/%d)", t.Title, t.ID)
+		}
+	}
+
+	if err := s.Notify(ctx, []int64{e.UserID}, KindSavedSearchAlert, title, body.String(), link); err != nil {
+		slog.Error("[Notifications] Ошибка при оповещении по сохранённому поиску", "search_id", e.SearchID, "error", err)
+	}
+
+	email, err := s.repo.GetUserEmail(ctx, e.UserID)
+	if err != nil {
+		slog.Error("[Notifications] Не удалось получить email для оповещения", "user_id", e.UserID, "error", err)
+		return
+	}
+	if email == "" {
+		return
+	}
+	if err := s.emailSender.SendEmail(email, title, strings.NewReader(body.String())); err != nil {
+		slog.Error("[Notifications] Не удалось отправить письмо по сохранённому поиску", "user_id", e.UserID, "error", err)
+	}
+}
+
+// HandleResponseOffer — обработчик хода в переговорах по отклику.
+// Сообщает другой стороне, что теперь её очередь ответить.
+func (s *notificationsService) HandleResponseOffer(event any) {
+	e, ok := event.(tasks.ResponseOfferEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+
+	var title, body string
+	switch e.Action {
+	case "counter":
+		title = "Встречное предложение по отклику"
+		body = fmt.Sprintf("Предложены новые условия: %s. Примите их, предложите свои или откажитесь.", money.New(e.Price, e.Currency))
+	case "accept":
+		title = "Условия отклика согласованы"
+		body = fmt.Sprintf("Другая сторона приняла условия: %s.", money.New(e.Price, e.Currency))
+	case "withdraw":
+		title = "Отклик отозван"
+		body = "Исполнитель отозвал свой отклик на задачу."
+	default:
+		return
+	}
+	link := fmt.Sprintf("/tasks/%d/responses/%d", e.TaskID, e.ResponseID)
+
+	if err := s.Notify(context.Background(), []int64{e.RecipientID}, KindResponseOffer, title, body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении о предложении по отклику", "response_id", e.ResponseID, "error", err)
+	}
+}
+
+// HandleReportRejected — обработчик события отклонения отчёта: исполнитель может сдать отчёт заново или открыть спор.
+func (s *notificationsService) HandleReportRejected(event any) {
+	e, ok := event.(tasks.ReportRejectedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+
+	title := "Отчёт по этапу отклонён"
+	body := fmt.Sprintf("Заказчик отклонил отчёт по этапу «%s». Исправьте работу и сдайте отчёт заново или откройте спор, если не согласны.", e.MilestoneTitle)
+	if e.Feedback != "" {
+		body += fmt.Sprintf(" Комментарий заказчика: %s", e.Feedback)
+	}
+	link := fmt.Sprintf("/contracts/%d/milestones", e.ContractID)
+
+	if err := s.Notify(context.Background(), []int64{e.ExecutorID}, KindReportRejected, title, body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении об отклонении отчёта", "contract_id", e.ContractID, "error", err)
+	}
+}
+
+// HandleDisputeUpdated — обработчик события по спору: сообщает сторонам и арбитру о новой записи в ленте.
+func (s *notificationsService) HandleDisputeUpdated(event any) {
+	e, ok := event.(disputes.DisputeUpdatedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+
+	title := "Новое событие в споре по контракту"
+	switch e.Kind {
+	case "opened":
+		title = "По контракту открыт спор"
+	case "resolved":
+		title = "Арбитр вынес решение по спору"
+	case "sla_breached":
+		title = "Срок рассмотрения спора истёк"
+	}
+	link := fmt.Sprintf("/disputes/%d", e.DisputeID)
+
+	if err := s.Notify(context.Background(), e.RecipientIDs, KindDisputeUpdated, title, e.Message, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении о споре", "dispute_id", e.DisputeID, "error", err)
+	}
+}
+
+// HandleBidPlaced — обработчик новой ставки: сообщает заказчику о ходе торгов.
+func (s *notificationsService) HandleBidPlaced(event any) {
+	e, ok := event.(bids.BidPlacedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+
+	title := "Новая ставка на торгах"
+	body := "Исполнитель сделал ставку. Суммы закрытых торгов раскроются после их завершения."
+	if e.Lowered {
+		title = "Ставка на торгах понижена"
+		body = "Исполнитель понизил свою ставку. Суммы закрытых торгов раскроются после их завершения."
+	}
+	if e.Amount != nil {
+		body = fmt.Sprintf("Предложенная цена: %s.", money.New(*e.Amount, money.Base))
+	}
+	link := fmt.Sprintf("/auctions/%d", e.AuctionID)
+
+	if err := s.Notify(context.Background(), []int64{e.CustomerID}, KindBidPlaced, title, body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении о ставке", "auction_id", e.AuctionID, "error", err)
+	}
+}
+
+// HandleAuctionClosed — обработчик завершения торгов: сообщает итог заказчику и участникам.
+func (s *notificationsService) HandleAuctionClosed(event any) {
+	e, ok := event.(bids.AuctionClosedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+	link := fmt.Sprintf("/auctions/%d", e.AuctionID)
+
+	if e.WinnerID == nil {
+		body := "Торги завершились без победителя: ставок не было или все они выше резервной цены."
+		if err := s.Notify(ctx, append([]int64{e.CustomerID}, e.BidderIDs...), KindAuctionClosed, "Торги завершены", body, link); err != nil {
+			slog.Error("[Notifications] Ошибка при уведомлении о завершении торгов", "auction_id", e.AuctionID, "error", err)
+		}
+		return
+	}
+
+	body := fmt.Sprintf("Победила ставка %s. С победителем заключён контракт.", money.New(*e.WinningAmount, money.Base))
+	if err := s.Notify(ctx, []int64{e.CustomerID}, KindAuctionClosed, "Торги завершены", body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении о завершении торгов", "auction_id", e.AuctionID, "error", err)
+	}
+	winnerBody := fmt.Sprintf("Ваша ставка %s победила. С вами заключён контракт.", money.New(*e.WinningAmount, money.Base))
+	if err := s.Notify(ctx, []int64{*e.WinnerID}, KindAuctionClosed, "Вы победили в торгах", winnerBody, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении победителя торгов", "auction_id", e.AuctionID, "error", err)
+	}
+
+	losers := make([]int64, 0, len(e.BidderIDs))
+	for _, id := range e.BidderIDs {
+		if id != *e.WinnerID {
+			losers = append(losers, id)
+		}
+	}
+	if len(losers) == 0 {
+		return
+	}
+	if err := s.Notify(ctx, losers, KindAuctionClosed, "Торги завершены", "Торги завершены, победила другая ставка.", link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении участников торгов", "auction_id", e.AuctionID, "error", err)
+	}
+}
+
+// HandleReviewCreated — обработчик скрытого отзыва: предлагает второй стороне оставить свой,
+// чтобы увидеть отзыв до истечения срока.
+func (s *notificationsService) HandleReviewCreated(event any) {
+	e, ok := event.(reviews.ReviewCreatedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+	link := fmt.Sprintf("/contracts/%d/reviews", e.ContractID)
+	body := fmt.Sprintf("Вторая сторона контракта оставила отзыв о вас. Оставьте свой, чтобы увидеть его сразу; иначе он будет опубликован %s.",
+		e.RevealAt.Format("02.01.2006"))
+	if err := s.Notify(context.Background(), []int64{e.UserID}, KindReviewReceived, "Вам оставили отзыв", body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении об отзыве", "review_id", e.ReviewID, "error", err)
+	}
+}
+
+// HandleReviewPublished — обработчик публикации отзыва: сообщает пользователю, о котором он оставлен.
+func (s *notificationsService) HandleReviewPublished(event any) {
+	e, ok := event.(reviews.ReviewPublishedEvent)
+	if !ok {
+		slog.Error("[Notifications] Получено некорректное событие", "event", event)
+		return
+	}
+	link := fmt.Sprintf("/contracts/%d/reviews", e.ContractID)
+	body := fmt.Sprintf("Отзыв о вас с оценкой %d опубликован. Вы можете ответить на него.", e.Rating)
+	if err := s.Notify(context.Background(), []int64{e.UserID}, KindReviewPublished, "Отзыв опубликован", body, link); err != nil {
+		slog.Error("[Notifications] Ошибка при уведомлении о публикации отзыва", "review_id", e.ReviewID, "error", err)
+	}
+}
@@ -1 +1,95 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/notifications/domain"
+)
+
+// NotificationsRepository хранит уведомления в PostgreSQL.
+type NotificationsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationsRepository создаёт новый репозиторий уведомлений.
+func NewNotificationsRepository(db *pgxpool.Pool) *NotificationsRepository {
+	return &NotificationsRepository{db: db}
+}
+
+// InsertNotifications сохраняет уведомления одним пакетом.
+func (r *NotificationsRepository) InsertNotifications(ctx context.Context, notifications []domain.Notification) error {
+	batch := &pgx.Batch{}
+	for _, n := range notifications {
+		batch.Queue(`
+            INSERT INTO notifications (user_id, kind, title, body, link, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6)`,
+			n.UserID, n.Kind, n.Title, n.Body, n.Link, n.CreatedAt)
+	}
+
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range notifications {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("ошибка при вставке уведомления: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByUser получает уведомления пользователя, новые первыми.
+func (r *NotificationsRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]domain.Notification, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, user_id, kind, title, body, link, read_at, created_at
+        FROM notifications
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении уведомлений: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		var n domain.Notification
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.Link, &readAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании уведомления: %w", err)
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkRead помечает уведомление прочитанным. Возвращает false, если уведомление не принадлежит пользователю.
+func (r *NotificationsRepository) MarkRead(ctx context.Context, notificationID, userID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE notifications
+        SET read_at = COALESCE(read_at, $3)
+        WHERE id = $1 AND user_id = $2`, notificationID, userID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении уведомления: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetUserEmail получает адрес электронной почты пользователя для рассылки уведомлений.
+func (r *NotificationsRepository) GetUserEmail(ctx context.Context, userID int64) (string, error) {
+	var email sql.NullString
+	err := r.db.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при получении email пользователя %d: %w", userID, err)
+	}
+	return email.String, nil
+}
@@ -1 +1,332 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// maxWebhookBody — предельный размер уведомления платёжного шлюза.
+const maxWebhookBody = 64 << 10
+
+// PaymentsHandler отвечает за обработку HTTP-запросов, связанных с оплатой контрактов.
+type PaymentsHandler struct {
+	service domain.PaymentsService
+}
+
+// NewPaymentsHandler создаёт новый экземпляр PaymentsHandler.
+func NewPaymentsHandler(service domain.PaymentsService) *PaymentsHandler {
+	return &PaymentsHandler{service: service}
+}
+
+// GetContractPayment возвращает состояние эскроу по контракту и историю операций.
+func (h *PaymentsHandler) GetContractPayment(w http.ResponseWriter, r *http.Request) {
+	sess, contractID, ok := contractRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.GetContractPayment(r.Context(), contractID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payment)
+}
+
+// RetryHold повторяет блокировку средств, если шлюз отказал при заключении контракта.
+func (h *PaymentsHandler) RetryHold(w http.ResponseWriter, r *http.Request) {
+	sess, contractID, ok := contractRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.RetryHold(r.Context(), contractID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payment)
+}
+
+// Webhook принимает уведомление платёжного шлюза. Сессии у шлюза нет: подлинность
+// проверяется по подписи в заголовке X-Signature.
+func (h *PaymentsHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось прочитать уведомление: %w", err), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBody {
+		common_errors.NewAppError(w, r, fmt.Errorf("%w: слишком большое тело", domain.ErrInvalidWebhook), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	result, err := h.service.HandleWebhook(r.Context(), body, r.Header.Get(domain.WebhookSignatureHeader))
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, result)
+}
+
+// ListWebhookEvents возвращает журнал уведомлений шлюза. Параметр status отбирает, например, только failed.
+func (h *PaymentsHandler) ListWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	events, err := h.service.ListWebhookEvents(r.Context(), sess.UserID, domain.WebhookStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, events)
+}
+
+// ReplayWebhookEvent повторно обрабатывает сохранённое уведомление шлюза.
+func (h *PaymentsHandler) ReplayWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор уведомления: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.ReplayWebhookEvent(r.Context(), id, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, result)
+}
+
+// AddPayoutMethod сохраняет способ вывода по токену, выданному платёжным шлюзом.
+func (h *PaymentsHandler) AddPayoutMethod(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.PayoutMethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	method, err := h.service.AddPayoutMethod(r.Context(), sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, method)
+}
+
+// ListPayoutMethods возвращает сохранённые способы вывода пользователя.
+func (h *PaymentsHandler) ListPayoutMethods(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	methods, err := h.service.ListPayoutMethods(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, methods)
+}
+
+// DeletePayoutMethod удаляет способ вывода пользователя.
+func (h *PaymentsHandler) DeletePayoutMethod(w http.ResponseWriter, r *http.Request) {
+	sess, id, ok := idRequest(w, r, "способа вывода")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePayoutMethod(r.Context(), sess.UserID, id); err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestPayout создаёт заявку на вывод средств с кошелька.
+func (h *PaymentsHandler) RequestPayout(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.PayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+	req.IdempotencyKey = r.Header.Get(domain.PayoutIdempotencyHeader)
+
+	payout, err := h.service.RequestPayout(r.Context(), sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, payout)
+}
+
+// ListPayouts возвращает заявки пользователя на вывод.
+func (h *PaymentsHandler) ListPayouts(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	payouts, err := h.service.ListPayouts(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payouts)
+}
+
+// ListPayoutQueue возвращает очередь заявок на вывод. Параметр status выбирает другой статус вместо requested.
+func (h *PaymentsHandler) ListPayoutQueue(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	payouts, err := h.service.ListPayoutQueue(r.Context(), sess.UserID, domain.PayoutStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payouts)
+}
+
+// ApprovePayout одобряет заявку на вывод.
+func (h *PaymentsHandler) ApprovePayout(w http.ResponseWriter, r *http.Request) {
+	sess, id, ok := idRequest(w, r, "заявки на вывод")
+	if !ok {
+		return
+	}
+
+	payout, err := h.service.ApprovePayout(r.Context(), id, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payout)
+}
+
+// RejectPayout отклоняет заявку на вывод с указанием причины.
+func (h *PaymentsHandler) RejectPayout(w http.ResponseWriter, r *http.Request) {
+	sess, id, ok := idRequest(w, r, "заявки на вывод")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	payout, err := h.service.RejectPayout(r.Context(), id, sess.UserID, req.Reason)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, paymentErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, payout)
+}
+
+// idRequest достаёт сессию и идентификатор из пути. При ошибке ответ уже записан.
+func idRequest(w http.ResponseWriter, r *http.Request, what string) (*session.Session, int64, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор %s: %w", what, err), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return sess, id, true
+}
+
+// contractRequest достаёт сессию и идентификатор контракта из пути. При ошибке ответ уже записан.
+func contractRequest(w http.ResponseWriter, r *http.Request) (*session.Session, int64, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return sess, contractID, true
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound), errors.Is(err, domain.ErrWebhookEventNotFound),
+		errors.Is(err, domain.ErrPayoutMethodNotFound), errors.Is(err, domain.ErrPayoutNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPaymentForbidden), errors.Is(err, domain.ErrAdminOnly):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrWebhookNotConfigured):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrInvalidWebhook),
+		errors.Is(err, domain.ErrInvalidPayoutMethod), errors.Is(err, domain.ErrPayoutBelowMinimum):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPaymentState), errors.Is(err, domain.ErrPayoutState), errors.Is(err, ledger.ErrInsufficientFunds):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrGatewayDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrGatewayUnavailable):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
@@ -1 +1,300 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/payments"
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+const testSecret = "whsec_test"
+
+// memRepo — хранилище платежей в памяти: достаточно для проверки обработки уведомлений.
+type memRepo struct {
+	domain.PaymentsRepository
+	payment  domain.Payment
+	events   []domain.StoredWebhookEvent
+	releases []domain.PendingRelease
+	released map[string]bool
+	payouts  []domain.Payout
+}
+
+func (r *memRepo) GetPayoutMethod(_ context.Context, id int64) (*domain.PayoutMethod, error) {
+	return &domain.PayoutMethod{ID: id, UserID: 2, Kind: domain.PayoutMethodCard}, nil
+}
+
+func (r *memRepo) CreatePayout(_ context.Context, p domain.Payout) (*domain.Payout, bool, error) {
+	for i := range r.payouts {
+		if p.RequestKey != "" && r.payouts[i].UserID == p.UserID && r.payouts[i].RequestKey == p.RequestKey {
+			existing := r.payouts[i]
+			return &existing, false, nil
+		}
+	}
+	p.ID = int64(len(r.payouts) + 1)
+	r.payouts = append(r.payouts, p)
+	return &p, true, nil
+}
+
+func (r *memRepo) TransitionPayout(_ context.Context, id int64, t domain.PayoutTransition) (*domain.Payout, bool, error) {
+	p := &r.payouts[id-1]
+	if p.Status != t.From {
+		return nil, false, nil
+	}
+	p.Status, p.Reason = t.To, t.Reason
+	updated := *p
+	return &updated, true, nil
+}
+
+func (r *memRepo) GetPaymentByContract(_ context.Context, contractID int64) (*domain.Payment, error) {
+	if contractID != r.payment.ContractID {
+		return nil, domain.ErrPaymentNotFound
+	}
+	p := r.payment
+	return &p, nil
+}
+
+func (r *memRepo) RecordOperation(_ context.Context, _ int64, op domain.Operation) (*domain.Payment, bool, error) {
+	if r.payment.HasOperation(op.IdempotencyKey) {
+		p := r.payment
+		return &p, false, nil
+	}
+	updated, err := r.payment.Apply(op)
+	if err != nil {
+		return nil, false, err
+	}
+	updated.Operations = append(updated.Operations, op)
+	r.payment = updated
+	return &updated, true, nil
+}
+
+func (r *memRepo) SavePendingRelease(_ context.Context, pr domain.PendingRelease) error {
+	for _, existing := range r.releases {
+		if existing.IdempotencyKey == pr.IdempotencyKey {
+			return nil
+		}
+	}
+	r.releases = append(r.releases, pr)
+	return nil
+}
+
+func (r *memRepo) ListPendingReleases(_ context.Context, contractID int64) ([]domain.PendingRelease, error) {
+	var pending []domain.PendingRelease
+	for _, pr := range r.releases {
+		if pr.ContractID == contractID && !r.released[pr.IdempotencyKey] {
+			pending = append(pending, pr)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memRepo) MarkReleaseDone(_ context.Context, key string, _ time.Time) error {
+	if r.released == nil {
+		r.released = map[string]bool{}
+	}
+	r.released[key] = true
+	return nil
+}
+
+func (r *memRepo) GetPaymentByGatewayRef(_ context.Context, ref string) (*domain.Payment, error) {
+	if ref != r.payment.GatewayRef {
+		return nil, domain.ErrPaymentNotFound
+	}
+	p := r.payment
+	return &p, nil
+}
+
+func (r *memRepo) SetHoldStatus(_ context.Context, _ int64, from, to domain.PaymentStatus, reason string, _ *domain.Operation) (*domain.Payment, bool, error) {
+	if r.payment.Status != from {
+		return nil, false, nil
+	}
+	r.payment.Status, r.payment.FailureReason = to, reason
+	p := r.payment
+	return &p, true, nil
+}
+
+func (r *memRepo) SaveWebhookEvent(_ context.Context, e domain.StoredWebhookEvent) (*domain.StoredWebhookEvent, bool, error) {
+	for i := range r.events {
+		if r.events[i].EventID == e.EventID {
+			existing := r.events[i]
+			return &existing, false, nil
+		}
+	}
+	e.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, e)
+	return &e, true, nil
+}
+
+func (r *memRepo) MarkWebhookEvent(_ context.Context, id int64, status domain.WebhookStatus, errText string, _ time.Time) error {
+	r.events[id-1].Status, r.events[id-1].Error = status, errText
+	r.events[id-1].Attempts++
+	return nil
+}
+
+// memGateway — шлюз, который принимает все выплаты и запоминает их ключи.
+type memGateway struct {
+	domain.PaymentGateway
+	captures []string
+}
+
+func (g *memGateway) Capture(_ context.Context, _ string, _ int, key string) error {
+	g.captures = append(g.captures, key)
+	return nil
+}
+
+type memLedger struct {
+	entries []ledger.Entry
+	err     error // Если задана, проводки не записываются
+}
+
+func (l *memLedger) Post(_ context.Context, entries ...ledger.Entry) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.entries = append(l.entries, entries...)
+	return nil
+}
+
+type recordingBus struct {
+	mu     sync.Mutex
+	events []eventbus.Event
+}
+
+func (b *recordingBus) Publish(event eventbus.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+}
+
+func postWebhook(h *PaymentsHandler, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewReader(body))
+	req.Header.Set(domain.WebhookSignatureHeader, signature)
+	rec := httptest.NewRecorder()
+	h.Webhook(rec, req)
+	return rec
+}
+
+func TestWebhookConfirmsPendingHold(t *testing.T) {
+	repo := &memRepo{payment: domain.Payment{
+		ID: 1, ContractID: 10, CustomerID: 1, ExecutorID: 2, Amount: 5000,
+		Status: domain.StatusPending, GatewayRef: "hold_1", HoldAttempts: 1,
+	}}
+	book := &memLedger{}
+	bus := &recordingBus{}
+	h := NewPaymentsHandler(domain.NewPaymentsService(repo, nil, book, bus, domain.Settings{WebhookSecret: testSecret}))
+
+	body := []byte(`{"id":"evt_1","type":"hold.succeeded","hold_ref":"hold_1","amount":5000}`)
+	signature := domain.SignWebhook(testSecret, time.Now(), body)
+
+	if rec := postWebhook(h, body, signature); rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получен %d: %s", rec.Code, rec.Body)
+	}
+	if repo.payment.Status != domain.StatusHeld {
+		t.Errorf("платёж должен перейти в held, статус %s", repo.payment.Status)
+	}
+	if len(book.entries) != 2 {
+		t.Errorf("блокировка должна пройти по главной книге двумя проводками, записано %d", len(book.entries))
+	}
+	var succeeded int
+	for _, e := range bus.events {
+		if _, ok := e.(payments.PaymentSucceededEvent); ok {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("ожидалось одно событие PaymentSucceeded, получено %d", succeeded)
+	}
+
+	// Повторная доставка того же уведомления ничего не меняет.
+	if rec := postWebhook(h, body, signature); rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"duplicate":true`)) {
+		t.Errorf("повтор должен быть распознан как дубликат: %d %s", rec.Code, rec.Body)
+	}
+	if len(book.entries) != 2 || len(repo.events) != 1 {
+		t.Errorf("повтор уведомления не должен проводить блокировку снова")
+	}
+}
+
+func TestWebhookRejectsBadSignature(t *testing.T) {
+	repo := &memRepo{}
+	h := NewPaymentsHandler(domain.NewPaymentsService(repo, nil, &memLedger{}, &recordingBus{}, domain.Settings{WebhookSecret: testSecret}))
+
+	body := []byte(`{"id":"evt_2","type":"hold.failed","hold_ref":"hold_1"}`)
+	rec := postWebhook(h, body, domain.SignWebhook("other-secret", time.Now(), body))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("ожидался 401, получен %d", rec.Code)
+	}
+	if len(repo.events) != 0 {
+		t.Error("уведомление с неверной подписью не должно сохраняться")
+	}
+}
+
+func TestMilestoneConfirmedBeforeHoldIsReleasedAfterWebhook(t *testing.T) {
+	repo := &memRepo{payment: domain.Payment{
+		ID: 1, ContractID: 10, CustomerID: 1, ExecutorID: 2, Amount: 5000,
+		Status: domain.StatusPending, GatewayRef: "hold_1", HoldAttempts: 1,
+	}}
+	gateway := &memGateway{}
+	svc := domain.NewPaymentsService(repo, gateway, &memLedger{}, &recordingBus{}, domain.Settings{WebhookSecret: testSecret})
+	h := NewPaymentsHandler(svc)
+
+	// Этап подтверждён, пока шлюз ещё не подтвердил блокировку: выплата ждёт её.
+	svc.HandleMilestoneConfirmed(tasks.MilestoneConfirmedEvent{ContractID: 10, TaskID: 20, MilestoneID: 100, Amount: 2000})
+	if len(gateway.captures) != 0 || repo.payment.ReleasedAmount != 0 {
+		t.Fatalf("до блокировки средств выплата не проводится: выплат %d", len(gateway.captures))
+	}
+
+	body := []byte(`{"id":"evt_3","type":"hold.succeeded","hold_ref":"hold_1","amount":5000}`)
+	if rec := postWebhook(h, body, domain.SignWebhook(testSecret, time.Now(), body)); rec.Code != http.StatusOK {
+		t.Fatalf("ожидался 200, получен %d: %s", rec.Code, rec.Body)
+	}
+	if repo.payment.ReleasedAmount != 2000 || len(gateway.captures) != 1 {
+		t.Fatalf("после блокировки отложенная выплата проводится: выплачено %d, выплат %d", repo.payment.ReleasedAmount, len(gateway.captures))
+	}
+
+	// Повторное событие о том же этапе не выплачивает его второй раз.
+	svc.HandleMilestoneConfirmed(tasks.MilestoneConfirmedEvent{ContractID: 10, TaskID: 20, MilestoneID: 100, Amount: 2000})
+	if repo.payment.ReleasedAmount != 2000 || len(gateway.captures) != 1 {
+		t.Errorf("выплата этапа проводится один раз: выплачено %d, выплат %d", repo.payment.ReleasedAmount, len(gateway.captures))
+	}
+}
+
+func TestRequestPayoutFailsWithoutReserveAndDeduplicatesByKey(t *testing.T) {
+	repo := &memRepo{}
+	book := &memLedger{err: errors.New("главная книга недоступна")}
+	svc := domain.NewPaymentsService(repo, nil, book, &recordingBus{}, domain.Settings{})
+	ctx := context.Background()
+	req := domain.PayoutRequest{MethodID: 1, Amount: 1000, IdempotencyKey: "payout-1"}
+
+	// Резерв не записан: заявка не остаётся в очереди.
+	if _, err := svc.RequestPayout(ctx, 2, req); err == nil {
+		t.Fatal("ожидалась ошибка резерва")
+	}
+	if len(repo.payouts) != 1 || repo.payouts[0].Status != domain.PayoutFailed {
+		t.Fatalf("заявка без резерва помечается failed: %+v", repo.payouts)
+	}
+
+	// Повтор с тем же ключом возвращает ту же заявку, а не создаёт вторую.
+	book.err = nil
+	payout, err := svc.RequestPayout(ctx, 2, req)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(repo.payouts) != 1 || payout.ID != repo.payouts[0].ID || len(book.entries) != 0 {
+		t.Errorf("повтор с тем же ключом не создаёт заявку и не резервирует сумму: заявок %d, проводок %d", len(repo.payouts), len(book.entries))
+	}
+
+	// Новый ключ — новая заявка с резервом.
+	req.IdempotencyKey = "payout-2"
+	if payout, err = svc.RequestPayout(ctx, 2, req); err != nil || payout.Status != domain.PayoutRequested || len(book.entries) != 1 {
+		t.Errorf("новая заявка резервирует сумму: %+v, проводок %d, ошибка %v", payout, len(book.entries), err)
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxRateBP — ставка 100% в базисных пунктах.
+const maxRateBP = 10000
+
+var ErrInvalidFeeSchedule = errors.New("некорректный тариф комиссии")
+
+// FeeRule — ставка комиссии для категории задач или, если указана подкатегория, только для неё.
+type FeeRule struct {
+	CategoryID    int
+	SubcategoryID int // 0 — правило для всей категории
+	RateBP        int
+}
+
+// FeeSchedule — тариф комиссии площадки. Ставки задаются в базисных пунктах: 1000 = 10%.
+type FeeSchedule struct {
+	DefaultBP          int
+	ProDiscountPercent int // На сколько процентов снижается ставка для исполнителей с Pro
+	Rules              []FeeRule
+}
+
+// FeeBasis — то, от чего зависит ставка комиссии по контракту.
+type FeeBasis struct {
+	CategoryID    *int
+	SubcategoryID *int
+	ExecutorPro   bool
+}
+
+// Validate проверяет, что ставки и скидка лежат в допустимых пределах.
+func (s FeeSchedule) Validate() error {
+	if s.DefaultBP < 0 || s.DefaultBP > maxRateBP {
+		return fmt.Errorf("%w: ставка по умолчанию %d", ErrInvalidFeeSchedule, s.DefaultBP)
+	}
+	if s.ProDiscountPercent < 0 || s.ProDiscountPercent > 100 {
+		return fmt.Errorf("%w: скидка Pro %d%%", ErrInvalidFeeSchedule, s.ProDiscountPercent)
+	}
+	for _, r := range s.Rules {
+		if r.CategoryID <= 0 || r.SubcategoryID < 0 || r.RateBP < 0 || r.RateBP > maxRateBP {
+			return fmt.Errorf("%w: правило %+v", ErrInvalidFeeSchedule, r)
+		}
+	}
+	return nil
+}
+
+// RateFor возвращает ставку для контракта: правило подкатегории важнее правила категории,
+// а оно — ставки по умолчанию. Исполнителям с Pro ставка снижается на ProDiscountPercent.
+func (s FeeSchedule) RateFor(basis FeeBasis) int {
+	rate := s.DefaultBP
+	if basis.CategoryID != nil {
+		for _, r := range s.Rules {
+			if r.CategoryID == *basis.CategoryID && r.SubcategoryID == 0 {
+				rate = r.RateBP
+			}
+		}
+		if basis.SubcategoryID != nil {
+			for _, r := range s.Rules {
+				if r.CategoryID == *basis.CategoryID && r.SubcategoryID == *basis.SubcategoryID {
+					rate = r.RateBP
+				}
+			}
+		}
+	}
+	if basis.ExecutorPro {
+		rate = rate * (100 - s.ProDiscountPercent) / 100
+	}
+	return rate
+}
+
+// FeeFor возвращает комиссию с суммы по ставке rateBP, округлённую до целого в ближайшую сторону.
+func FeeFor(amount, rateBP int) int {
+	if amount <= 0 || rateBP <= 0 {
+		return 0
+	}
+	return (amount*rateBP + maxRateBP/2) / maxRateBP
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFeeScheduleRateFor(t *testing.T) {
+	category, subcategory, other := 3, 31, 4
+	schedule := FeeSchedule{
+		DefaultBP:          1000,
+		ProDiscountPercent: 30,
+		Rules: []FeeRule{
+			{CategoryID: 3, SubcategoryID: 31, RateBP: 500},
+			{CategoryID: 3, RateBP: 800},
+		},
+	}
+
+	cases := map[string]struct {
+		basis FeeBasis
+		want  int
+	}{
+		"без категории":          {FeeBasis{}, 1000},
+		"категория без правила":  {FeeBasis{CategoryID: &other}, 1000},
+		"правило категории":      {FeeBasis{CategoryID: &category}, 800},
+		"правило подкатегории":   {FeeBasis{CategoryID: &category, SubcategoryID: &subcategory}, 500},
+		"скидка Pro":             {FeeBasis{ExecutorPro: true}, 700},
+		"скидка Pro к категории": {FeeBasis{CategoryID: &category, ExecutorPro: true}, 560},
+	}
+	for name, c := range cases {
+		if got := schedule.RateFor(c.basis); got != c.want {
+			t.Errorf("%s: ожидалась ставка %d, получено %d", name, c.want, got)
+		}
+	}
+}
+
+func TestFeeFor(t *testing.T) {
+	if got := FeeFor(10000, 1000); got != 1000 {
+		t.Errorf("10%% от 10000: получено %d", got)
+	}
+	if got := FeeFor(15, 1000); got != 2 {
+		t.Errorf("округление 1.5 вверх: получено %d", got)
+	}
+	if got := FeeFor(5000, 0); got != 0 {
+		t.Errorf("нулевая ставка: получено %d", got)
+	}
+}
+
+func TestPayoutPolicyCheckAmount(t *testing.T) {
+	policy := PayoutPolicy{MinAmount: 1000}
+	if err := policy.CheckAmount(1000); err != nil {
+		t.Errorf("минимальная сумма допустима: %v", err)
+	}
+	if err := policy.CheckAmount(999); !errors.Is(err, ErrPayoutBelowMinimum) {
+		t.Errorf("ожидалась ErrPayoutBelowMinimum, получено %v", err)
+	}
+	if err := policy.CheckAmount(0); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("ожидалась ErrInvalidAmount, получено %v", err)
+	}
+}
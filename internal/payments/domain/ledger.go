@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+)
+
+// HoldEntries — проводки блокировки: поступление от заказчика через шлюз на его кошелёк
+// и перенос с кошелька в эскроу. В выписке заказчика видны оба движения, а баланс не меняется.
+// Проводки по платежу ведутся в его валюте.
+func HoldEntries(p Payment, key string) []ledger.Entry {
+	return []ledger.Entry{
+		ledger.Transfer(ledger.EntryDeposit, key+":deposit", ledger.External(), ledger.Wallet(p.CustomerID), p.Amount,
+			fmt.Sprintf("Поступление оплаты по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency),
+		ledger.Transfer(ledger.EntryHold, key, ledger.Wallet(p.CustomerID), ledger.Escrow(), p.Amount,
+			fmt.Sprintf("Блокировка оплаты по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency),
+	}
+}
+
+// MoveEntries — проводки выплаты исполнителю или возврата заказчику. Выплата остаётся на кошельке
+// исполнителя за вычетом комиссии площадки, а возврат сразу уходит обратно на карту заказчика.
+func MoveEntries(p Payment, kind OperationKind, amount, fee int, key string) []ledger.Entry {
+	if kind == OperationRelease {
+		entries := []ledger.Entry{
+			ledger.Transfer(ledger.EntryRelease, key, ledger.Escrow(), ledger.Wallet(p.ExecutorID), amount,
+				fmt.Sprintf("Выплата по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency),
+		}
+		if fee > 0 {
+			entries = append(entries, ledger.Transfer(ledger.EntryFee, key+":fee", ledger.Wallet(p.ExecutorID), ledger.Commission(), fee,
+				fmt.Sprintf("Комиссия площадки по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency))
+		}
+		return entries
+	}
+	return []ledger.Entry{
+		ledger.Transfer(ledger.EntryRefund, key, ledger.Escrow(), ledger.Wallet(p.CustomerID), amount,
+			fmt.Sprintf("Возврат по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency),
+		ledger.Transfer(ledger.EntryWithdrawal, key+":withdrawal", ledger.Wallet(p.CustomerID), ledger.External(), amount,
+			fmt.Sprintf("Возврат на карту по контракту №%d", p.ContractID)).ForContract(p.ContractID).InCurrency(p.Currency),
+	}
+}
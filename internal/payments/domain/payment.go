@@ -1 +1,225 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PaymentStatus — состояние эскроу-платежа по контракту.
+type PaymentStatus string
+
+const (
+	StatusPending  PaymentStatus = "pending"  // Запрос на блокировку средств отправлен, ответа шлюза ещё нет
+	StatusHeld     PaymentStatus = "held"     // Средства заказчика заблокированы, часть может быть уже выплачена
+	StatusReleased PaymentStatus = "released" // Вся сумма выплачена исполнителю
+	StatusRefunded PaymentStatus = "refunded" // Вся сумма возвращена заказчику
+	StatusSettled  PaymentStatus = "settled"  // Сумма разделена: часть выплачена, остаток возвращён
+	StatusFailed   PaymentStatus = "failed"   // Шлюз отказал в блокировке средств
+)
+
+// OperationKind — вид операции по эскроу.
+type OperationKind string
+
+const (
+	OperationHold    OperationKind = "hold"
+	OperationRelease OperationKind = "release"
+	OperationRefund  OperationKind = "refund"
+)
+
+// DefaultCurrency — валюта платежей, если у контракта она не указана, и выплат, если она не указана в заявке.
+const DefaultCurrency = "RUB"
+
+// AdminUserType — тип пользователя, которому доступны журнал уведомлений шлюза и их повторная обработка.
+const AdminUserType = "ADMIN"
+
+var (
+	ErrPaymentNotFound    = errors.New("платёж не найден")
+	ErrPaymentForbidden   = errors.New("платёж по контракту доступен только его сторонам")
+	ErrAdminOnly          = errors.New("действие доступно только администраторам")
+	ErrPaymentState       = errors.New("операция недоступна в текущем состоянии платежа")
+	ErrInvalidAmount      = errors.New("некорректная сумма операции")
+	ErrGatewayDeclined    = errors.New("платёжный шлюз отклонил операцию")
+	ErrGatewayUnavailable = errors.New("платёжный шлюз недоступен")
+)
+
+// Payment — эскроу по контракту: средства заказчика блокируются при заключении контракта,
+// выплачиваются исполнителю по мере подтверждения этапов и возвращаются при отмене.
+type Payment struct {
+	ID             int64         `json:"id"`
+	ContractID     int64         `json:"contract_id"`
+	TaskID         int64         `json:"task_id"`
+	CustomerID     int64         `json:"customer_id"`
+	ExecutorID     int64         `json:"executor_id"`
+	Amount         int           `json:"amount"`          // Заблокированная сумма
+	ReleasedAmount int           `json:"released_amount"` // Выплачено исполнителю
+	RefundedAmount int           `json:"refunded_amount"` // Возвращено заказчику
+	FeeRateBP      int           `json:"fee_rate_bp"`     // Ставка комиссии, зафиксированная при заключении контракта
+	FeeAmount      int           `json:"fee_amount"`      // Удержанная комиссия с выплат исполнителю
+	Currency       string        `json:"currency"`
+	Status         PaymentStatus `json:"status"`
+	GatewayRef     string        `json:"-"` // Идентификатор блокировки в платёжном шлюзе
+	FailureReason  string        `json:"failure_reason,omitempty"`
+	HoldAttempts   int           `json:"hold_attempts"` // Сколько раз запрашивалась блокировка
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Operations     []Operation   `json:"operations,omitempty"`
+}
+
+// Operation — операция по эскроу. Ключ идемпотентности не даёт провести одну и ту же выплату дважды,
+// даже если событие о подтверждении этапа пришло повторно.
+type Operation struct {
+	ID             int64         `json:"id"`
+	PaymentID      int64         `json:"payment_id"`
+	Kind           OperationKind `json:"kind"`
+	Amount         int           `json:"amount"`
+	Fee            int           `json:"fee,omitempty"` // Комиссия площадки с выплаты
+	IdempotencyKey string        `json:"-"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// PendingRelease — выплата за подтверждённый этап, сохранённая до её проведения.
+// Она проводится, как только средства по контракту заблокированы.
+type PendingRelease struct {
+	ContractID     int64
+	Amount         int
+	IdempotencyKey string
+	CreatedAt      time.Time
+}
+
+// ContractInfo — сведения о контракте, под который блокируются средства.
+type ContractInfo struct {
+	ContractID int64
+	TaskID     int64
+	CustomerID int64
+	ExecutorID int64
+	Amount     int    // В минимальных единицах Currency
+	Currency   string // Валюта контракта
+}
+
+// PayoutAmount возвращает сумму выплаты, которая остаётся исполнителю после комиссии.
+func (o Operation) PayoutAmount() int {
+	return o.Amount - o.Fee
+}
+
+// HoldRequest — запрос к шлюзу на блокировку средств заказчика.
+type HoldRequest struct {
+	IdempotencyKey string
+	CustomerID     int64
+	Amount         int
+	Currency       string
+	Description    string
+}
+
+// ChargeRequest — запрос к шлюзу на разовое списание, например оплату подписки.
+type ChargeRequest struct {
+	IdempotencyKey string
+	CustomerID     int64
+	Amount         int
+	Currency       string
+	Description    string
+}
+
+// HoldResult — ответ шлюза на запрос блокировки. Если Pending, шлюз подтвердит
+// или отклонит блокировку позже, уведомлением на вебхук.
+type HoldResult struct {
+	Ref     string
+	Pending bool
+}
+
+// Remaining возвращает сумму, которая ещё заблокирована и не распределена.
+func (p Payment) Remaining() int {
+	return p.Amount - p.ReleasedAmount - p.RefundedAmount
+}
+
+// CanHold сообщает, можно ли (повторно) заблокировать средства: впервые или после отказа шлюза.
+// Блокировка, которую шлюз принял, но ещё не подтвердил, повторно не запрашивается.
+func (p Payment) CanHold() bool {
+	return (p.Status == StatusPending && p.GatewayRef == "") || p.Status == StatusFailed
+}
+
+// CheckRelease проверяет выплату исполнителю.
+func (p Payment) CheckRelease(amount int) error {
+	if p.Status != StatusHeld {
+		return fmt.Errorf("%w: выплата из платежа в статусе %s", ErrPaymentState, p.Status)
+	}
+	if amount <= 0 || amount > p.Remaining() {
+		return fmt.Errorf("%w: %d при остатке %d", ErrInvalidAmount, amount, p.Remaining())
+	}
+	return nil
+}
+
+// Apply учитывает проведённую операцию и возвращает новое состояние платежа.
+func (p Payment) Apply(op Operation) (Payment, error) {
+	switch op.Kind {
+	case OperationRelease, OperationRefund:
+		if p.Status != StatusHeld {
+			return p, fmt.Errorf("%w: %s из платежа в статусе %s", ErrPaymentState, op.Kind, p.Status)
+		}
+		if op.Amount <= 0 || op.Amount > p.Remaining() {
+			return p, fmt.Errorf("%w: %d при остатке %d", ErrInvalidAmount, op.Amount, p.Remaining())
+		}
+		if op.Fee < 0 || op.Fee > op.Amount {
+			return p, fmt.Errorf("%w: комиссия %d с суммы %d", ErrInvalidAmount, op.Fee, op.Amount)
+		}
+	default:
+		return p, fmt.Errorf("%w: неизвестная операция %q", ErrPaymentState, op.Kind)
+	}
+
+	if op.Kind == OperationRelease {
+		p.ReleasedAmount += op.Amount
+		p.FeeAmount += op.Fee
+	} else {
+		p.RefundedAmount += op.Amount
+	}
+	p.UpdatedAt = op.CreatedAt
+
+	if p.Remaining() == 0 {
+		switch {
+		case p.RefundedAmount == 0:
+			p.Status = StatusReleased
+		case p.ReleasedAmount == 0:
+			p.Status = StatusRefunded
+		default:
+			p.Status = StatusSettled
+		}
+	}
+	return p, nil
+}
+
+// HasOperation сообщает, проведена ли уже операция с этим ключом идемпотентности.
+func (p Payment) HasOperation(idempotencyKey string) bool {
+	for _, op := range p.Operations {
+		if op.IdempotencyKey == idempotencyKey {
+			return true
+		}
+	}
+	return false
+}
+
+// IsParty сообщает, является ли пользователь стороной контракта.
+func (p Payment) IsParty(userID int64) bool {
+	return userID == p.CustomerID || userID == p.ExecutorID
+}
+
+// HoldKey — ключ идемпотентности блокировки средств по контракту.
+// У каждой попытки свой ключ: иначе шлюз вернул бы на повторный запрос прежний отказ.
+func HoldKey(contractID int64, attempt int) string {
+	return fmt.Sprintf("contract-%d-hold-%d", contractID, attempt)
+}
+
+// ReleaseKey — ключ выплаты за подтверждённый этап.
+func ReleaseKey(contractID, milestoneID int64) string {
+	return fmt.Sprintf("contract-%d-milestone-%d-release", contractID, milestoneID)
+}
+
+// SettlementKey — ключ выплаты по решению арбитра.
+func SettlementKey(contractID int64) string {
+	return fmt.Sprintf("contract-%d-settlement", contractID)
+}
+
+// RefundKey — ключ возврата остатка заказчику. Он один на контракт,
+// поэтому отмена задачи и решение арбитра не вернут средства дважды.
+func RefundKey(contractID int64) string {
+	return fmt.Sprintf("contract-%d-refund", contractID)
+}
@@ -1 +1,100 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+)
+
+func TestPaymentApply(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	held := Payment{Amount: 10000, Status: StatusHeld}
+
+	p, err := held.Apply(Operation{Kind: OperationRelease, Amount: 4000, CreatedAt: now})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if p.Status != StatusHeld || p.Remaining() != 6000 {
+		t.Errorf("после частичной выплаты: статус %s, остаток %d", p.Status, p.Remaining())
+	}
+
+	released, err := p.Apply(Operation{Kind: OperationRelease, Amount: 6000, CreatedAt: now})
+	if err != nil || released.Status != StatusReleased {
+		t.Errorf("выплата остатка: ожидался released, получено %s, %v", released.Status, err)
+	}
+	settled, err := p.Apply(Operation{Kind: OperationRefund, Amount: 6000, CreatedAt: now})
+	if err != nil || settled.Status != StatusSettled {
+		t.Errorf("возврат остатка после выплаты: ожидался settled, получено %s, %v", settled.Status, err)
+	}
+	refunded, err := held.Apply(Operation{Kind: OperationRefund, Amount: 10000, CreatedAt: now})
+	if err != nil || refunded.Status != StatusRefunded {
+		t.Errorf("полный возврат: ожидался refunded, получено %s, %v", refunded.Status, err)
+	}
+
+	if _, err := p.Apply(Operation{Kind: OperationRelease, Amount: 7000}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("выплата сверх остатка: ожидалась ErrInvalidAmount, получено %v", err)
+	}
+	if _, err := released.Apply(Operation{Kind: OperationRefund, Amount: 1}); !errors.Is(err, ErrPaymentState) {
+		t.Errorf("возврат из закрытого платежа: ожидалась ErrPaymentState, получено %v", err)
+	}
+}
+
+func TestPaymentCheckRelease(t *testing.T) {
+	p := Payment{Amount: 5000, ReleasedAmount: 2000, Status: StatusHeld}
+	if err := p.CheckRelease(3000); err != nil {
+		t.Errorf("неожиданная ошибка: %v", err)
+	}
+	if err := p.CheckRelease(3001); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("ожидалась ErrInvalidAmount, получено %v", err)
+	}
+	p.Status = StatusFailed
+	if err := p.CheckRelease(100); !errors.Is(err, ErrPaymentState) {
+		t.Errorf("выплата без блокировки: ожидалась ErrPaymentState, получено %v", err)
+	}
+}
+
+func TestLedgerEntriesBalance(t *testing.T) {
+	p := Payment{ContractID: 3, CustomerID: 1, ExecutorID: 2, Amount: 9000, Currency: "USD"}
+
+	entries := HoldEntries(p, HoldKey(3, 1))
+	entries = append(entries, MoveEntries(p, OperationRelease, 4000, 400, ReleaseKey(3, 1))...)
+	entries = append(entries, MoveEntries(p, OperationRefund, 5000, 0, RefundKey(3))...)
+
+	balances := map[string]int{}
+	for _, e := range entries {
+		if err := e.Validate(); err != nil {
+			t.Fatalf("проводка %q: %v", e.IdempotencyKey, err)
+		}
+		if e.Currency != p.Currency {
+			t.Errorf("проводка %q в валюте %q, ожидалась валюта платежа %q", e.IdempotencyKey, e.Currency, p.Currency)
+		}
+		for _, posting := range e.Postings {
+			balances[posting.Account.String()] += posting.Amount
+		}
+	}
+	if balances["escrow"] != 0 || balances["wallet:1"] != 0 || balances["wallet:2"] != 3600 || balances["commission"] != 400 || balances["external"] != -4000 {
+		t.Errorf("неожиданные балансы после закрытия эскроу: %v", balances)
+	}
+}
+
+func TestPayoutCurrency(t *testing.T) {
+	cases := map[string]string{"": DefaultCurrency, " usd ": "USD", "EUR": "EUR"}
+	for raw, want := range cases {
+		got, err := PayoutRequest{MethodID: 1, Amount: 5000, Currency: raw}.PayoutCurrency()
+		if err != nil || got != want {
+			t.Errorf("%q: ожидалась валюта %s, получено %q, %v", raw, want, got, err)
+		}
+	}
+	if _, err := (PayoutRequest{Currency: "XYZ"}).PayoutCurrency(); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("неизвестная валюта: ожидалась ErrInvalidAmount, получено %v", err)
+	}
+
+	p := Payout{ID: 4, UserID: 2, Amount: 5000, Currency: "USD"}
+	for _, e := range []ledger.Entry{PayoutReserveEntry(p), PayoutSentEntry(p), PayoutReturnEntry(p)} {
+		if e.Currency != p.Currency {
+			t.Errorf("проводка %q в валюте %q, ожидалась валюта заявки %q", e.IdempotencyKey, e.Currency, p.Currency)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// PayoutMethodKind — вид способа вывода средств.
+type PayoutMethodKind string
+
+const (
+	PayoutMethodCard        PayoutMethodKind = "card"
+	PayoutMethodBankAccount PayoutMethodKind = "bank_account"
+)
+
+// PayoutStatus — состояние заявки на вывод.
+type PayoutStatus string
+
+const (
+	PayoutRequested  PayoutStatus = "requested"  // Ждёт решения администратора
+	PayoutApproved   PayoutStatus = "approved"   // Одобрена и ждёт отправки в очередном пакете
+	PayoutRejected   PayoutStatus = "rejected"   // Отклонена, средства вернулись на кошелёк
+	PayoutProcessing PayoutStatus = "processing" // Отправлена в шлюз в составе пакета
+	PayoutPaid       PayoutStatus = "paid"       // Шлюз перевёл деньги
+	PayoutFailed     PayoutStatus = "failed"     // Шлюз отказал, средства вернулись на кошелёк
+)
+
+var (
+	ErrPayoutMethodNotFound = errors.New("способ вывода не найден")
+	ErrInvalidPayoutMethod  = errors.New("некорректный способ вывода")
+	ErrPayoutNotFound       = errors.New("заявка на вывод не найдена")
+	ErrPayoutBelowMinimum   = errors.New("сумма вывода меньше минимальной")
+	ErrPayoutState          = errors.New("действие недоступно в текущем состоянии заявки на вывод")
+)
+
+// PayoutMethod — сохранённый способ вывода. Реквизиты хранит платёжный шлюз,
+// у нас — только его токен и последние цифры номера для показа пользователю.
+type PayoutMethod struct {
+	ID        int64            `json:"id"`
+	UserID    int64            `json:"user_id"`
+	Kind      PayoutMethodKind `json:"kind"`
+	Token     string           `json:"-"`
+	Last4     string           `json:"last4"`
+	CreatedAt time.Time        `json:"created_at"`
+	DeletedAt *time.Time       `json:"-"` // Удалённый способ остаётся для уже созданных заявок
+}
+
+// PayoutMethodRequest — запрос на сохранение способа вывода. Токен выдаёт шлюз после ввода реквизитов.
+type PayoutMethodRequest struct {
+	Kind  PayoutMethodKind `json:"kind"`
+	Token string           `json:"token"`
+	Last4 string           `json:"last4"`
+}
+
+// PayoutIdempotencyHeader — заголовок с ключом идемпотентности заявки на вывод.
+const PayoutIdempotencyHeader = "Idempotency-Key"
+
+// PayoutRequest — заявка исполнителя на вывод средств с кошелька.
+// Currency — валюта баланса кошелька, из которой выводятся средства; по умолчанию DefaultCurrency.
+// IdempotencyKey берётся из заголовка PayoutIdempotencyHeader: повтор запроса с тем же ключом
+// возвращает уже созданную заявку.
+type PayoutRequest struct {
+	MethodID       int64  `json:"method_id"`
+	Amount         int    `json:"amount"`
+	Currency       string `json:"currency"`
+	IdempotencyKey string `json:"-"`
+}
+
+// PayoutCurrency возвращает код валюты заявки в верхнем регистре.
+func (r PayoutRequest) PayoutCurrency() (string, error) {
+	if strings.TrimSpace(r.Currency) == "" {
+		return DefaultCurrency, nil
+	}
+	currency, err := money.ParseCurrency(r.Currency)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	return string(currency), nil
+}
+
+// Payout — заявка на вывод средств. Сумма резервируется на кошельке при создании заявки
+// и возвращается, если заявку отклонили или шлюз не смог перевести деньги.
+type Payout struct {
+	ID          int64        `json:"id"`
+	UserID      int64        `json:"user_id"`
+	MethodID    int64        `json:"method_id"`
+	Amount      int          `json:"amount"`
+	Currency    string       `json:"currency"`
+	Status      PayoutStatus `json:"status"`
+	BatchID     *int64       `json:"batch_id,omitempty"`
+	GatewayRef  string       `json:"-"`
+	Reason      string       `json:"reason,omitempty"` // Причина отказа администратора или шлюза
+	ReviewedBy  *int64       `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time   `json:"reviewed_at,omitempty"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	RequestKey  string       `json:"-"` // Ключ идемпотентности запроса, создавшего заявку
+}
+
+// PayoutTransition — смена статуса заявки на вывод вместе с сопутствующими полями.
+type PayoutTransition struct {
+	From       PayoutStatus
+	To         PayoutStatus
+	Reason     string
+	ReviewedBy *int64 // Заполняется, если статус меняет администратор
+	GatewayRef string
+	At         time.Time
+}
+
+// PayoutPolicy — ограничения на вывод средств.
+type PayoutPolicy struct {
+	MinAmount    int
+	MaxBatchSize int
+}
+
+// PayoutTransfer — запрос к шлюзу на перевод средств по сохранённому способу вывода.
+type PayoutTransfer struct {
+	IdempotencyKey string
+	Destination    string // Токен способа вывода
+	Amount         int
+	Currency       string
+	Description    string
+}
+
+// Validate проверяет запрос на сохранение способа вывода.
+func (r PayoutMethodRequest) Validate() error {
+	if r.Kind != PayoutMethodCard && r.Kind != PayoutMethodBankAccount {
+		return fmt.Errorf("%w: неизвестный вид %q", ErrInvalidPayoutMethod, r.Kind)
+	}
+	if r.Token == "" {
+		return fmt.Errorf("%w: не указан токен шлюза", ErrInvalidPayoutMethod)
+	}
+	if len(r.Last4) != 4 {
+		return fmt.Errorf("%w: нужны последние четыре цифры номера", ErrInvalidPayoutMethod)
+	}
+	for _, c := range r.Last4 {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%w: нужны последние четыре цифры номера", ErrInvalidPayoutMethod)
+		}
+	}
+	return nil
+}
+
+// CheckAmount проверяет сумму заявки на вывод.
+func (p PayoutPolicy) CheckAmount(amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+	}
+	if amount < p.MinAmount {
+		return fmt.Errorf("%w: %d при минимуме %d", ErrPayoutBelowMinimum, amount, p.MinAmount)
+	}
+	return nil
+}
+
+// PayoutReserveKey — ключ проводки, резервирующей сумму заявки на кошельке.
+func PayoutReserveKey(payoutID int64) string {
+	return fmt.Sprintf("payout-%d-reserve", payoutID)
+}
+
+// PayoutKey — ключ перевода в шлюзе и проводки отправленной выплаты.
+func PayoutKey(payoutID int64) string {
+	return fmt.Sprintf("payout-%d", payoutID)
+}
+
+// PayoutReturnKey — ключ проводки, возвращающей резерв на кошелёк. Он один на заявку,
+// поэтому отказ администратора и отказ шлюза не вернут средства дважды.
+func PayoutReturnKey(payoutID int64) string {
+	return fmt.Sprintf("payout-%d-return", payoutID)
+}
+
+// PayoutReserveEntry — проводка резерва суммы заявки на кошельке исполнителя.
+// Проводки заявки идут в её валюте: резерв списывается с баланса кошелька в этой валюте.
+func PayoutReserveEntry(p Payout) ledger.Entry {
+	return ledger.Transfer(ledger.EntryPayoutReserve, PayoutReserveKey(p.ID), ledger.Wallet(p.UserID), ledger.PayoutReserve(), p.Amount,
+		fmt.Sprintf("Заявка на вывод №%d", p.ID)).InCurrency(p.Currency)
+}
+
+// PayoutSentEntry — проводка выплаты: деньги уходят из резерва за пределы площадки.
+func PayoutSentEntry(p Payout) ledger.Entry {
+	return ledger.Transfer(ledger.EntryPayout, PayoutKey(p.ID), ledger.PayoutReserve(), ledger.External(), p.Amount,
+		fmt.Sprintf("Вывод средств по заявке №%d", p.ID)).InCurrency(p.Currency)
+}
+
+// PayoutReturnEntry — проводка возврата резерва на кошелёк.
+func PayoutReturnEntry(p Payout) ledger.Entry {
+	return ledger.Transfer(ledger.EntryPayoutReturn, PayoutReturnKey(p.ID), ledger.PayoutReserve(), ledger.Wallet(p.UserID), p.Amount,
+		fmt.Sprintf("Возврат по заявке на вывод №%d", p.ID)).InCurrency(p.Currency)
+}
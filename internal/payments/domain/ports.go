@@ -1 +1,112 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// PaymentsService — интерфейс для бизнес-логики эскроу-платежей.
+type PaymentsService interface {
+	HoldForContract(ctx context.Context, contract ContractInfo) (*Payment, error)
+	RetryHold(ctx context.Context, contractID, userID int64) (*Payment, error) // Повторная блокировка после отказа шлюза
+	Release(ctx context.Context, contractID int64, amount int, idempotencyKey string) (*Payment, error)
+	ReleaseRemaining(ctx context.Context, contractID int64, idempotencyKey string) (*Payment, error)
+	RefundRemaining(ctx context.Context, contractID int64) (*Payment, error)
+	GetContractPayment(ctx context.Context, contractID, userID int64) (*Payment, error)
+
+	// HandleWebhook проверяет подпись уведомления шлюза, сохраняет его и применяет к платежу.
+	HandleWebhook(ctx context.Context, body []byte, signature string) (*WebhookResult, error)
+	ListWebhookEvents(ctx context.Context, userID int64, status WebhookStatus) ([]StoredWebhookEvent, error) // Только администраторам
+	ReplayWebhookEvent(ctx context.Context, id, userID int64) (*WebhookResult, error)                        // Только администраторам
+
+	AddPayoutMethod(ctx context.Context, userID int64, req PayoutMethodRequest) (*PayoutMethod, error)
+	ListPayoutMethods(ctx context.Context, userID int64) ([]PayoutMethod, error)
+	DeletePayoutMethod(ctx context.Context, userID, methodID int64) error
+	// RequestPayout создаёт заявку на вывод и резервирует её сумму на кошельке.
+	RequestPayout(ctx context.Context, userID int64, req PayoutRequest) (*Payout, error)
+	ListPayouts(ctx context.Context, userID int64) ([]Payout, error)
+	ListPayoutQueue(ctx context.Context, userID int64, status PayoutStatus) ([]Payout, error) // Только администраторам
+	ApprovePayout(ctx context.Context, payoutID, userID int64) (*Payout, error)               // Только администраторам
+	RejectPayout(ctx context.Context, payoutID, userID int64, reason string) (*Payout, error) // Только администраторам
+	// ProcessPayoutBatch отправляет в шлюз пакет одобренных выплат и возвращает их число.
+	ProcessPayoutBatch(ctx context.Context) (int, error)
+	RunPayoutBatches(ctx context.Context, interval time.Duration)
+
+	HandleContractCreated(event any)
+	HandleMilestoneConfirmed(event any)
+	HandleContractCancelled(event any)
+	HandleDisputeResolved(event any)
+}
+
+// PaymentGateway — порт платёжного шлюза. Все операции идемпотентны по ключу:
+// повтор с тем же ключом возвращает результат первого вызова и не двигает деньги второй раз.
+type PaymentGateway interface {
+	// Hold блокирует средства заказчика. Если шлюз подтверждает блокировку асинхронно,
+	// результат придёт уведомлением на вебхук.
+	Hold(ctx context.Context, req HoldRequest) (HoldResult, error)
+	// Capture переводит часть заблокированной суммы исполнителю.
+	Capture(ctx context.Context, holdRef string, amount int, idempotencyKey string) error
+	// Refund снимает блокировку с части суммы и возвращает её заказчику.
+	Refund(ctx context.Context, holdRef string, amount int, idempotencyKey string) error
+	// Charge списывает разовый платёж с карты пользователя и возвращает идентификатор списания.
+	Charge(ctx context.Context, req ChargeRequest) (string, error)
+	// Payout переводит средства с площадки по сохранённому способу вывода и возвращает идентификатор перевода.
+	Payout(ctx context.Context, req PayoutTransfer) (string, error)
+}
+
+// PaymentsRepository — интерфейс для хранения платежей.
+type PaymentsRepository interface {
+	// CreatePayment создаёт платёж по контракту; если он уже есть, возвращает существующий и false.
+	CreatePayment(ctx context.Context, p Payment) (*Payment, bool, error)
+	GetPaymentByContract(ctx context.Context, contractID int64) (*Payment, error) // С операциями; ErrPaymentNotFound
+	// UpdateHold записывает результат блокировки: ссылку шлюза или причину отказа.
+	UpdateHold(ctx context.Context, paymentID int64, status PaymentStatus, gatewayRef, failureReason string, op *Operation) (*Payment, error)
+	// RecordOperation учитывает выплату или возврат под блокировкой платежа.
+	// Повтор операции с тем же ключом ничего не меняет и возвращает false.
+	RecordOperation(ctx context.Context, paymentID int64, op Operation) (*Payment, bool, error)
+	GetPaymentByGatewayRef(ctx context.Context, gatewayRef string) (*Payment, error) // ErrPaymentNotFound
+	// SetHoldStatus переводит платёж из статуса from в to по уведомлению шлюза.
+	// Возвращает false, если платёж уже не в статусе from.
+	SetHoldStatus(ctx context.Context, paymentID int64, from, to PaymentStatus, failureReason string, op *Operation) (*Payment, bool, error)
+	// SavePendingRelease сохраняет выплату за этап; повтор с тем же ключом ничего не меняет.
+	SavePendingRelease(ctx context.Context, r PendingRelease) error
+	ListPendingReleases(ctx context.Context, contractID int64) ([]PendingRelease, error) // Ещё не проведённые, старые первыми
+	MarkReleaseDone(ctx context.Context, idempotencyKey string, at time.Time) error
+
+	// SaveWebhookEvent сохраняет уведомление; если уведомление с тем же ID уже есть, возвращает его и false.
+	SaveWebhookEvent(ctx context.Context, e StoredWebhookEvent) (*StoredWebhookEvent, bool, error)
+	GetWebhookEvent(ctx context.Context, id int64) (*StoredWebhookEvent, error) // ErrWebhookEventNotFound
+	ListWebhookEvents(ctx context.Context, status WebhookStatus, limit int) ([]StoredWebhookEvent, error)
+	MarkWebhookEvent(ctx context.Context, id int64, status WebhookStatus, errText string, at time.Time) error
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+	// GetFeeBasis возвращает категорию задачи и статус Pro исполнителя для расчёта комиссии.
+	GetFeeBasis(ctx context.Context, taskID, executorID int64) (FeeBasis, error)
+
+	CreatePayoutMethod(ctx context.Context, m PayoutMethod) (*PayoutMethod, error)
+	GetPayoutMethod(ctx context.Context, id int64) (*PayoutMethod, error) // В том числе удалённый; ErrPayoutMethodNotFound
+	ListPayoutMethods(ctx context.Context, userID int64) ([]PayoutMethod, error)
+	DeletePayoutMethod(ctx context.Context, userID, id int64, at time.Time) error // ErrPayoutMethodNotFound
+	// CreatePayout создаёт заявку; если заявка пользователя с тем же RequestKey уже есть, возвращает её и false.
+	CreatePayout(ctx context.Context, p Payout) (*Payout, bool, error)
+	GetPayout(ctx context.Context, id int64) (*Payout, error) // ErrPayoutNotFound
+	ListPayoutsByUser(ctx context.Context, userID int64, limit int) ([]Payout, error)
+	ListPayoutsByStatus(ctx context.Context, status PayoutStatus, limit int) ([]Payout, error) // Старые первыми
+	// TransitionPayout переводит заявку из статуса t.From в t.To. Возвращает false, если заявка уже не в статусе t.From.
+	TransitionPayout(ctx context.Context, id int64, t PayoutTransition) (*Payout, bool, error)
+	// ClaimPayoutBatch переводит до limit одобренных заявок в processing под новым пакетом.
+	ClaimPayoutBatch(ctx context.Context, limit int, at time.Time) ([]Payout, error)
+}
+
+// Ledger — главная книга, через которую проводится каждое движение денег по эскроу.
+// Проводки идемпотентны по ключу, поэтому их можно безопасно повторять вместе с операцией шлюза.
+type Ledger interface {
+	Post(ctx context.Context, entries ...ledger.Entry) error
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
+}
@@ -1 +1,807 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/payments"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+)
+
+// webhookEventsLimit — сколько уведомлений шлюза показывать в журнале.
+const webhookEventsLimit = 100
+
+// payoutsLimit — сколько заявок на вывод показывать в списках.
+const payoutsLimit = 100
+
+// defaultPayoutBatchSize — размер пакета выплат, если он не задан в настройках.
+const defaultPayoutBatchSize = 100
+
+// Settings — настройки сервиса платежей.
+type Settings struct {
+	WebhookSecret string // Общий со шлюзом секрет; пока он не задан, вебхук отклоняет все уведомления
+	Fees          FeeSchedule
+	Payouts       PayoutPolicy
+}
+
+type paymentsService struct {
+	repo     PaymentsRepository
+	gateway  PaymentGateway
+	ledger   Ledger
+	bus      EventBus
+	settings Settings
+}
+
+// NewPaymentsService создаёт сервис эскроу-платежей.
+func NewPaymentsService(repo PaymentsRepository, gateway PaymentGateway, ledger Ledger, bus EventBus, settings Settings) PaymentsService {
+	return &paymentsService{repo: repo, gateway: gateway, ledger: ledger, bus: bus, settings: settings}
+}
+
+// HoldForContract блокирует у заказчика сумму контракта. Повторный вызов для контракта,
+// средства по которому уже заблокированы, ничего не делает.
+func (s *paymentsService) HoldForContract(ctx context.Context, contract ContractInfo) (*Payment, error) {
+	if contract.Amount <= 0 {
+		return nil, fmt.Errorf("%w: сумма контракта %d", ErrInvalidAmount, contract.Amount)
+	}
+
+	// Ставка фиксируется при заключении контракта: смена тарифа или статуса Pro
+	// не меняет условий уже начатой работы.
+	basis, err := s.repo.GetFeeBasis(ctx, contract.TaskID, contract.ExecutorID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := contract.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	now := time.Now()
+	payment, _, err := s.repo.CreatePayment(ctx, Payment{
+		ContractID: contract.ContractID,
+		TaskID:     contract.TaskID,
+		CustomerID: contract.CustomerID,
+		ExecutorID: contract.ExecutorID,
+		Amount:     contract.Amount,
+		FeeRateBP:  s.settings.Fees.RateFor(basis),
+		Currency:   currency,
+		Status:     StatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !payment.CanHold() {
+		return payment, nil
+	}
+	return s.hold(ctx, payment)
+}
+
+// RetryHold повторяет блокировку средств после отказа шлюза. Доступно заказчику.
+func (s *paymentsService) RetryHold(ctx context.Context, contractID, userID int64) (*Payment, error) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.CustomerID != userID {
+		return nil, ErrPaymentForbidden
+	}
+	if !payment.CanHold() {
+		return nil, fmt.Errorf("%w: средства уже заблокированы", ErrPaymentState)
+	}
+	return s.hold(ctx, payment)
+}
+
+// Release выплачивает исполнителю часть заблокированной суммы.
+func (s *paymentsService) Release(ctx context.Context, contractID int64, amount int, idempotencyKey string) (*Payment, error) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.HasOperation(idempotencyKey) {
+		return payment, nil
+	}
+	if err := payment.CheckRelease(amount); err != nil {
+		return nil, err
+	}
+	return s.move(ctx, payment, OperationRelease, amount, idempotencyKey)
+}
+
+// ReleaseRemaining выплачивает исполнителю весь нераспределённый остаток.
+func (s *paymentsService) ReleaseRemaining(ctx context.Context, contractID int64, idempotencyKey string) (*Payment, error) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != StatusHeld || payment.Remaining() == 0 {
+		return payment, nil
+	}
+	return s.move(ctx, payment, OperationRelease, payment.Remaining(), idempotencyKey)
+}
+
+// RefundRemaining возвращает заказчику весь нераспределённый остаток.
+func (s *paymentsService) RefundRemaining(ctx context.Context, contractID int64) (*Payment, error) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != StatusHeld || payment.Remaining() == 0 {
+		return payment, nil
+	}
+	return s.move(ctx, payment, OperationRefund, payment.Remaining(), RefundKey(contractID))
+}
+
+// GetContractPayment возвращает платёж по контракту с историей операций его сторонам.
+func (s *paymentsService) GetContractPayment(ctx context.Context, contractID, userID int64) (*Payment, error) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if !payment.IsParty(userID) {
+		return nil, ErrPaymentForbidden
+	}
+	return payment, nil
+}
+
+// HandleContractCreated — обработчик заключения контракта: блокирует сумму контракта у заказчика.
+func (s *paymentsService) HandleContractCreated(event any) {
+	e, ok := event.(tasks.ContractCreatedEvent)
+	if !ok {
+		slog.Error("[Payments] Получено некорректное событие", "event", event)
+		return
+	}
+	_, err := s.HoldForContract(context.Background(), ContractInfo{
+		ContractID: e.ContractID,
+		TaskID:     e.TaskID,
+		CustomerID: e.CustomerID,
+		ExecutorID: e.ExecutorID,
+		Amount:     e.Price,
+		Currency:   string(e.Currency),
+	})
+	if err != nil {
+		slog.Error("[Payments] Не удалось заблокировать средства по контракту", "contract_id", e.ContractID, "error", err)
+	}
+}
+
+// HandleMilestoneConfirmed — обработчик подтверждения этапа: выплачивает исполнителю сумму этапа.
+// События обрабатываются в произвольном порядке, поэтому выплата сначала сохраняется: если средства
+// ещё не заблокированы, она будет проведена, когда блокировка состоится.
+func (s *paymentsService) HandleMilestoneConfirmed(event any) {
+	e, ok := event.(tasks.MilestoneConfirmedEvent)
+	if !ok {
+		slog.Error("[Payments] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+	err := s.repo.SavePendingRelease(ctx, PendingRelease{
+		ContractID:     e.ContractID,
+		Amount:         e.Amount,
+		IdempotencyKey: ReleaseKey(e.ContractID, e.MilestoneID),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		s.logHandlerError("сохранить выплату суммы этапа", e.ContractID, err)
+		return
+	}
+	s.releasePending(ctx, e.ContractID)
+}
+
+// HandleContractCancelled — обработчик отмены контракта: возвращает заказчику остаток.
+func (s *paymentsService) HandleContractCancelled(event any) {
+	e, ok := event.(tasks.ContractCancelledEvent)
+	if !ok {
+		slog.Error("[Payments] Получено некорректное событие", "event", event)
+		return
+	}
+	_, err := s.RefundRemaining(context.Background(), e.ContractID)
+	s.logHandlerError("вернуть средства заказчику", e.ContractID, err)
+}
+
+// HandleDisputeResolved — обработчик решения арбитра: остаток выплачивается исполнителю,
+// возвращается заказчику или делится так, чтобы исполнитель получил присуждённую сумму.
+func (s *paymentsService) HandleDisputeResolved(event any) {
+	e, ok := event.(disputes.DisputeResolvedEvent)
+	if !ok {
+		slog.Error("[Payments] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+
+	switch e.Outcome {
+	case "complete":
+		_, err := s.ReleaseRemaining(ctx, e.ContractID, SettlementKey(e.ContractID))
+		s.logHandlerError("выплатить остаток по решению арбитра", e.ContractID, err)
+	case "partial":
+		payment, err := s.repo.GetPaymentByContract(ctx, e.ContractID)
+		if err != nil {
+			s.logHandlerError("получить платёж по решению арбитра", e.ContractID, err)
+			return
+		}
+		// Присуждённая сумма учитывает уже выплаченные этапы.
+		if e.SettledAmount != nil && *e.SettledAmount > payment.ReleasedAmount {
+			amount := min(*e.SettledAmount-payment.ReleasedAmount, payment.Remaining())
+			if _, err := s.Release(ctx, e.ContractID, amount, SettlementKey(e.ContractID)); err != nil {
+				s.logHandlerError("выплатить присуждённую сумму", e.ContractID, err)
+				return
+			}
+		}
+		_, err = s.RefundRemaining(ctx, e.ContractID)
+		s.logHandlerError("вернуть остаток по решению арбитра", e.ContractID, err)
+	case "cancel":
+		_, err := s.RefundRemaining(ctx, e.ContractID)
+		s.logHandlerError("вернуть средства по решению арбитра", e.ContractID, err)
+	}
+}
+
+// releasePending проводит сохранённые выплаты за этапы, если средства по контракту уже заблокированы.
+// Пока платежа нет или шлюз не подтвердил блокировку, выплаты ждут её.
+func (s *paymentsService) releasePending(ctx context.Context, contractID int64) {
+	payment, err := s.repo.GetPaymentByContract(ctx, contractID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return
+	}
+	if err != nil {
+		s.logHandlerError("получить платёж для отложенных выплат", contractID, err)
+		return
+	}
+	if payment.Status == StatusPending || payment.Status == StatusFailed {
+		return
+	}
+
+	pending, err := s.repo.ListPendingReleases(ctx, contractID)
+	if err != nil {
+		s.logHandlerError("получить отложенные выплаты", contractID, err)
+		return
+	}
+	for _, r := range pending {
+		if _, err := s.Release(ctx, contractID, r.Amount, r.IdempotencyKey); err != nil {
+			s.logHandlerError("выплатить сумму этапа", contractID, err)
+			continue
+		}
+		err := s.repo.MarkReleaseDone(ctx, r.IdempotencyKey, time.Now())
+		s.logHandlerError("отметить выплату суммы этапа", contractID, err)
+	}
+}
+
+func (s *paymentsService) hold(ctx context.Context, payment *Payment) (*Payment, error) {
+	attempt := payment.HoldAttempts + 1
+	res, err := s.gateway.Hold(ctx, HoldRequest{
+		IdempotencyKey: HoldKey(payment.ContractID, attempt),
+		CustomerID:     payment.CustomerID,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		Description:    fmt.Sprintf("Оплата по контракту №%d", payment.ContractID),
+	})
+	if err != nil {
+		if !errors.Is(err, ErrGatewayDeclined) {
+			return nil, err
+		}
+		failed, updateErr := s.repo.UpdateHold(ctx, payment.ID, StatusFailed, "", err.Error(), nil)
+		if updateErr != nil {
+			return nil, updateErr
+		}
+		s.publishHoldFailed(failed)
+		return failed, nil
+	}
+	if res.Pending {
+		// Шлюз подтвердит блокировку уведомлением на вебхук.
+		return s.repo.UpdateHold(ctx, payment.ID, StatusPending, res.Ref, "", nil)
+	}
+
+	// Проводка записывается до платежа: если запись платежа не удастся, повтор попытки
+	// с тем же ключом получит ту же блокировку в шлюзе, а повторная проводка будет пропущена.
+	if err := s.ledger.Post(ctx, HoldEntries(*payment, HoldKey(payment.ContractID, attempt))...); err != nil {
+		return nil, fmt.Errorf("не удалось провести блокировку по главной книге: %w", err)
+	}
+
+	held, err := s.repo.UpdateHold(ctx, payment.ID, StatusHeld, res.Ref, "", &Operation{
+		PaymentID:      payment.ID,
+		Kind:           OperationHold,
+		Amount:         payment.Amount,
+		IdempotencyKey: HoldKey(payment.ContractID, attempt),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishHeld(held)
+	s.releasePending(ctx, held.ContractID)
+	return held, nil
+}
+
+// move проводит выплату или возврат в шлюзе, главной книге и платеже — именно в таком порядке.
+// Каждый шаг идемпотентен по ключу, поэтому прерванную операцию безопасно повторить целиком.
+// С выплаты исполнителю удерживается комиссия по ставке платежа.
+func (s *paymentsService) move(ctx context.Context, payment *Payment, kind OperationKind, amount int, key string) (*Payment, error) {
+	fee := 0
+	if kind == OperationRelease {
+		fee = FeeFor(amount, payment.FeeRateBP)
+	}
+
+	var err error
+	if kind == OperationRelease {
+		err = s.gateway.Capture(ctx, payment.GatewayRef, amount, key)
+	} else {
+		err = s.gateway.Refund(ctx, payment.GatewayRef, amount, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("операция %s по контракту %d: %w", kind, payment.ContractID, err)
+	}
+	if err := s.ledger.Post(ctx, MoveEntries(*payment, kind, amount, fee, key)...); err != nil {
+		return nil, fmt.Errorf("не удалось провести операцию %s по главной книге: %w", kind, err)
+	}
+
+	updated, recorded, err := s.repo.RecordOperation(ctx, payment.ID, Operation{
+		PaymentID:      payment.ID,
+		Kind:           kind,
+		Amount:         amount,
+		Fee:            fee,
+		IdempotencyKey: key,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		return updated, nil
+	}
+
+	if kind == OperationRelease {
+		s.bus.Publish(payments.EscrowReleasedEvent{PaymentID: updated.ID, ContractID: updated.ContractID, ExecutorID: updated.ExecutorID, Amount: amount, Fee: fee})
+	} else {
+		s.bus.Publish(payments.EscrowRefundedEvent{PaymentID: updated.ID, ContractID: updated.ContractID, CustomerID: updated.CustomerID, Amount: amount})
+	}
+	if updated.Status != StatusHeld {
+		s.bus.Publish(payments.EscrowClosedEvent{
+			PaymentID:  updated.ID,
+			ContractID: updated.ContractID,
+			CustomerID: updated.CustomerID,
+			ExecutorID: updated.ExecutorID,
+			Status:     string(updated.Status),
+			Released:   updated.ReleasedAmount,
+			Refunded:   updated.RefundedAmount,
+			Fee:        updated.FeeAmount,
+		})
+	}
+	return updated, nil
+}
+
+// HandleWebhook принимает уведомление шлюза. Уведомление сохраняется до обработки, поэтому
+// при сбое его можно обработать повторно; уже обработанное уведомление с тем же ID пропускается.
+func (s *paymentsService) HandleWebhook(ctx context.Context, body []byte, signature string) (*WebhookResult, error) {
+	if err := VerifyWebhookSignature(s.settings.WebhookSecret, signature, body, time.Now()); err != nil {
+		return nil, err
+	}
+	event, err := ParseWebhookEvent(body)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, created, err := s.repo.SaveWebhookEvent(ctx, StoredWebhookEvent{
+		EventID:    event.ID,
+		Type:       event.Type,
+		Payload:    body,
+		Status:     WebhookReceived,
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !created && stored.Done() {
+		return &WebhookResult{EventID: event.ID, Status: stored.Status, Duplicate: true}, nil
+	}
+	return s.processWebhook(ctx, stored.ID, event)
+}
+
+// ListWebhookEvents возвращает последние уведомления шлюза, при необходимости — только с заданным статусом.
+func (s *paymentsService) ListWebhookEvents(ctx context.Context, userID int64, status WebhookStatus) ([]StoredWebhookEvent, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWebhookEvents(ctx, status, webhookEventsLimit)
+}
+
+// ReplayWebhookEvent повторно обрабатывает сохранённое уведомление. Переходы статусов идемпотентны,
+// поэтому повтор уже обработанного уведомления ничего не меняет.
+func (s *paymentsService) ReplayWebhookEvent(ctx context.Context, id, userID int64) (*WebhookResult, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	stored, err := s.repo.GetWebhookEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	event, err := ParseWebhookEvent(stored.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.processWebhook(ctx, stored.ID, event)
+}
+
+// processWebhook применяет уведомление и записывает результат обработки.
+func (s *paymentsService) processWebhook(ctx context.Context, storedID int64, event WebhookEvent) (*WebhookResult, error) {
+	status, applyErr := s.applyWebhook(ctx, event)
+	errText := ""
+	if applyErr != nil {
+		status, errText = WebhookFailed, applyErr.Error()
+	}
+	if err := s.repo.MarkWebhookEvent(ctx, storedID, status, errText, time.Now()); err != nil {
+		return nil, err
+	}
+	if applyErr != nil {
+		return nil, applyErr
+	}
+	return &WebhookResult{EventID: event.ID, Status: status}, nil
+}
+
+// applyWebhook переводит платёж, ожидающий подтверждения блокировки, в held или failed.
+func (s *paymentsService) applyWebhook(ctx context.Context, event WebhookEvent) (WebhookStatus, error) {
+	if event.Type != WebhookHoldSucceeded && event.Type != WebhookHoldFailed {
+		return WebhookIgnored, nil
+	}
+	payment, err := s.repo.GetPaymentByGatewayRef(ctx, event.HoldRef)
+	if err != nil {
+		return "", err
+	}
+	if payment.Status != StatusPending {
+		return WebhookIgnored, nil
+	}
+
+	if event.Type == WebhookHoldFailed {
+		reason := event.FailureReason
+		if reason == "" {
+			reason = ErrGatewayDeclined.Error()
+		}
+		failed, ok, err := s.repo.SetHoldStatus(ctx, payment.ID, StatusPending, StatusFailed, reason, nil)
+		if err != nil || !ok {
+			return WebhookIgnored, err
+		}
+		s.publishHoldFailed(failed)
+		s.bus.Publish(payments.PaymentFailedEvent{
+			PaymentID:  failed.ID,
+			ContractID: failed.ContractID,
+			CustomerID: failed.CustomerID,
+			Amount:     failed.Amount,
+			Reason:     reason,
+			EventID:    event.ID,
+		})
+		return WebhookProcessed, nil
+	}
+
+	if event.Amount != 0 && event.Amount != payment.Amount {
+		return "", fmt.Errorf("%w: шлюз подтвердил %d вместо %d", ErrInvalidAmount, event.Amount, payment.Amount)
+	}
+	key := HoldKey(payment.ContractID, payment.HoldAttempts)
+	if err := s.ledger.Post(ctx, HoldEntries(*payment, key)...); err != nil {
+		return "", fmt.Errorf("не удалось провести блокировку по главной книге: %w", err)
+	}
+	held, ok, err := s.repo.SetHoldStatus(ctx, payment.ID, StatusPending, StatusHeld, "", &Operation{
+		PaymentID:      payment.ID,
+		Kind:           OperationHold,
+		Amount:         payment.Amount,
+		IdempotencyKey: key,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil || !ok {
+		return WebhookIgnored, err
+	}
+	s.publishHeld(held)
+	s.bus.Publish(payments.PaymentSucceededEvent{
+		PaymentID:  held.ID,
+		ContractID: held.ContractID,
+		CustomerID: held.CustomerID,
+		ExecutorID: held.ExecutorID,
+		Amount:     held.Amount,
+		EventID:    event.ID,
+	})
+	s.releasePending(ctx, held.ContractID)
+	return WebhookProcessed, nil
+}
+
+// AddPayoutMethod сохраняет способ вывода пользователя.
+func (s *paymentsService) AddPayoutMethod(ctx context.Context, userID int64, req PayoutMethodRequest) (*PayoutMethod, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.CreatePayoutMethod(ctx, PayoutMethod{
+		UserID:    userID,
+		Kind:      req.Kind,
+		Token:     req.Token,
+		Last4:     req.Last4,
+		CreatedAt: time.Now(),
+	})
+}
+
+// ListPayoutMethods возвращает сохранённые способы вывода пользователя.
+func (s *paymentsService) ListPayoutMethods(ctx context.Context, userID int64) ([]PayoutMethod, error) {
+	return s.repo.ListPayoutMethods(ctx, userID)
+}
+
+// DeletePayoutMethod удаляет способ вывода. Уже созданные заявки на него будут выплачены.
+func (s *paymentsService) DeletePayoutMethod(ctx context.Context, userID, methodID int64) error {
+	return s.repo.DeletePayoutMethod(ctx, userID, methodID, time.Now())
+}
+
+// RequestPayout создаёт заявку на вывод и резервирует её сумму на кошельке. Если резерв не удался,
+// заявка сразу помечается failed: в очереди остаются только заявки с резервом. Повтор запроса
+// с тем же ключом идемпотентности возвращает уже созданную заявку, а не создаёт вторую.
+func (s *paymentsService) RequestPayout(ctx context.Context, userID int64, req PayoutRequest) (*Payout, error) {
+	if err := s.settings.Payouts.CheckAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	currency, err := req.PayoutCurrency()
+	if err != nil {
+		return nil, err
+	}
+	method, err := s.repo.GetPayoutMethod(ctx, req.MethodID)
+	if err != nil {
+		return nil, err
+	}
+	if method.UserID != userID || method.DeletedAt != nil {
+		return nil, ErrPayoutMethodNotFound
+	}
+
+	payout, created, err := s.repo.CreatePayout(ctx, Payout{
+		UserID:     userID,
+		MethodID:   method.ID,
+		Amount:     req.Amount,
+		Currency:   currency,
+		Status:     PayoutRequested,
+		CreatedAt:  time.Now(),
+		RequestKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Повтор запроса дозаписывает резерв, если первый запрос прервался до него: проводка идемпотентна.
+	if !created && payout.Status != PayoutRequested {
+		return payout, nil
+	}
+
+	if err := s.ledger.Post(ctx, PayoutReserveEntry(*payout)); err != nil {
+		_, _, tErr := s.repo.TransitionPayout(ctx, payout.ID, PayoutTransition{
+			From: PayoutRequested, To: PayoutFailed, Reason: err.Error(), At: time.Now(),
+		})
+		if tErr != nil {
+			slog.Error("[Payments] Не удалось закрыть заявку на вывод без резерва", "payout_id", payout.ID, "error", tErr)
+		}
+		return nil, fmt.Errorf("не удалось зарезервировать сумму вывода: %w", err)
+	}
+	return payout, nil
+}
+
+// ListPayouts возвращает заявки пользователя на вывод, новые первыми.
+func (s *paymentsService) ListPayouts(ctx context.Context, userID int64) ([]Payout, error) {
+	return s.repo.ListPayoutsByUser(ctx, userID, payoutsLimit)
+}
+
+// ListPayoutQueue возвращает заявки с заданным статусом, по умолчанию — ждущие решения.
+func (s *paymentsService) ListPayoutQueue(ctx context.Context, userID int64, status PayoutStatus) ([]Payout, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = PayoutRequested
+	}
+	return s.repo.ListPayoutsByStatus(ctx, status, payoutsLimit)
+}
+
+// ApprovePayout одобряет заявку; она уйдёт в шлюз с ближайшим пакетом выплат.
+func (s *paymentsService) ApprovePayout(ctx context.Context, payoutID, userID int64) (*Payout, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	payout, err := s.repo.GetPayout(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status != PayoutRequested {
+		return nil, fmt.Errorf("%w: одобрение заявки в статусе %s", ErrPayoutState, payout.Status)
+	}
+	// Резерв идемпотентен: если он уже записан при создании заявки, проводка будет пропущена.
+	if err := s.ledger.Post(ctx, PayoutReserveEntry(*payout)); err != nil {
+		return nil, fmt.Errorf("не удалось зарезервировать сумму вывода: %w", err)
+	}
+
+	approved, ok, err := s.repo.TransitionPayout(ctx, payoutID, PayoutTransition{
+		From: PayoutRequested, To: PayoutApproved, ReviewedBy: &userID, At: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: заявка уже рассмотрена", ErrPayoutState)
+	}
+	s.publishPayout(approved)
+	return approved, nil
+}
+
+// RejectPayout отклоняет заявку, ждущую решения или ещё не отправленную в шлюз, и возвращает резерв на кошелёк.
+func (s *paymentsService) RejectPayout(ctx context.Context, payoutID, userID int64, reason string) (*Payout, error) {
+	if err := s.checkAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	payout, err := s.repo.GetPayout(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status != PayoutRequested && payout.Status != PayoutApproved {
+		return nil, fmt.Errorf("%w: отклонение заявки в статусе %s", ErrPayoutState, payout.Status)
+	}
+
+	// Резерв мог не записаться при создании заявки: тогда возвращать нечего.
+	reserved := true
+	if err := s.ledger.Post(ctx, PayoutReserveEntry(*payout)); err != nil {
+		if !errors.Is(err, ledger.ErrInsufficientFunds) {
+			return nil, fmt.Errorf("не удалось проверить резерв заявки: %w", err)
+		}
+		reserved = false
+	}
+
+	rejected, ok, err := s.repo.TransitionPayout(ctx, payoutID, PayoutTransition{
+		From: payout.Status, To: PayoutRejected, Reason: reason, ReviewedBy: &userID, At: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: заявка уже рассмотрена или отправлена", ErrPayoutState)
+	}
+	if reserved {
+		if err := s.ledger.Post(ctx, PayoutReturnEntry(*rejected)); err != nil {
+			slog.Error("[Payments] Не удалось вернуть резерв отклонённой заявки", "payout_id", payoutID, "error", err)
+			return nil, fmt.Errorf("не удалось вернуть резерв на кошелёк: %w", err)
+		}
+	}
+	s.publishPayout(rejected)
+	return rejected, nil
+}
+
+// ProcessPayoutBatch забирает пакет одобренных заявок и отправляет их в шлюз.
+func (s *paymentsService) ProcessPayoutBatch(ctx context.Context) (int, error) {
+	limit := s.settings.Payouts.MaxBatchSize
+	if limit <= 0 {
+		limit = defaultPayoutBatchSize
+	}
+	batch, err := s.repo.ClaimPayoutBatch(ctx, limit, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, payout := range batch {
+		if err := s.sendPayout(ctx, payout); err != nil {
+			slog.Error("[Payments] Не удалось отправить выплату", "payout_id", payout.ID, "error", err)
+		}
+	}
+	return len(batch), nil
+}
+
+// RunPayoutBatches периодически отправляет одобренные выплаты, пока не отменён ctx.
+func (s *paymentsService) RunPayoutBatches(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ProcessPayoutBatch(ctx); err != nil {
+				slog.Error("[Payments] Ошибка отправки пакета выплат", "error", err)
+			}
+		}
+	}
+}
+
+// sendPayout переводит деньги по заявке. При отказе шлюза резерв возвращается на кошелёк,
+// при любом другом сбое заявка возвращается в очередь: перевод и проводка идемпотентны по ключу заявки.
+func (s *paymentsService) sendPayout(ctx context.Context, payout Payout) error {
+	method, err := s.repo.GetPayoutMethod(ctx, payout.MethodID)
+	if err != nil {
+		return s.requeuePayout(ctx, payout, err)
+	}
+	ref, err := s.gateway.Payout(ctx, PayoutTransfer{
+		IdempotencyKey: PayoutKey(payout.ID),
+		Destination:    method.Token,
+		Amount:         payout.Amount,
+		Currency:       payout.Currency,
+		Description:    fmt.Sprintf("Вывод средств по заявке №%d", payout.ID),
+	})
+	if err != nil {
+		if errors.Is(err, ErrGatewayDeclined) {
+			return s.failPayout(ctx, payout, err.Error())
+		}
+		return s.requeuePayout(ctx, payout, err)
+	}
+	if err := s.ledger.Post(ctx, PayoutSentEntry(payout)); err != nil {
+		return s.requeuePayout(ctx, payout, err)
+	}
+
+	paid, ok, err := s.repo.TransitionPayout(ctx, payout.ID, PayoutTransition{
+		From: PayoutProcessing, To: PayoutPaid, GatewayRef: ref, At: time.Now(),
+	})
+	if err != nil || !ok {
+		return err
+	}
+	s.publishPayout(paid)
+	return nil
+}
+
+func (s *paymentsService) failPayout(ctx context.Context, payout Payout, reason string) error {
+	failed, ok, err := s.repo.TransitionPayout(ctx, payout.ID, PayoutTransition{
+		From: PayoutProcessing, To: PayoutFailed, Reason: reason, At: time.Now(),
+	})
+	if err != nil || !ok {
+		return err
+	}
+	if err := s.ledger.Post(ctx, PayoutReturnEntry(*failed)); err != nil {
+		return fmt.Errorf("не удалось вернуть резерв на кошелёк: %w", err)
+	}
+	s.publishPayout(failed)
+	return nil
+}
+
+func (s *paymentsService) requeuePayout(ctx context.Context, payout Payout, cause error) error {
+	_, _, err := s.repo.TransitionPayout(ctx, payout.ID, PayoutTransition{
+		From: PayoutProcessing, To: PayoutApproved, At: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("%v; заявка не возвращена в очередь: %w", cause, err)
+	}
+	return cause
+}
+
+func (s *paymentsService) publishPayout(p *Payout) {
+	s.bus.Publish(payments.PayoutStatusChangedEvent{
+		PayoutID: p.ID,
+		UserID:   p.UserID,
+		Amount:   p.Amount,
+		Status:   string(p.Status),
+		Reason:   p.Reason,
+	})
+}
+
+func (s *paymentsService) checkAdmin(ctx context.Context, userID int64) error {
+	isAdmin, err := s.repo.IsAdmin(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrAdminOnly
+	}
+	return nil
+}
+
+func (s *paymentsService) publishHeld(p *Payment) {
+	s.bus.Publish(payments.EscrowHeldEvent{
+		PaymentID:  p.ID,
+		ContractID: p.ContractID,
+		CustomerID: p.CustomerID,
+		ExecutorID: p.ExecutorID,
+		Amount:     p.Amount,
+	})
+}
+
+func (s *paymentsService) publishHoldFailed(p *Payment) {
+	s.bus.Publish(payments.EscrowHoldFailedEvent{
+		PaymentID:  p.ID,
+		ContractID: p.ContractID,
+		CustomerID: p.CustomerID,
+		Amount:     p.Amount,
+		Reason:     p.FailureReason,
+	})
+}
+
+// logHandlerError пишет в лог ошибку обработчика события. Контракты без эскроу
+// (заключённые до появления платежей) пропускаются молча.
+func (s *paymentsService) logHandlerError(action string, contractID int64, err error) {
+	if err == nil || errors.Is(err, ErrPaymentNotFound) {
+		return
+	}
+	slog.Error("[Payments] Не удалось "+action, "contract_id", contractID, "error", err)
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader — заголовок с подписью уведомления: "t=<unix-время>,v1=<hex HMAC-SHA256>".
+	WebhookSignatureHeader = "X-Signature"
+	// WebhookTolerance — насколько время подписи может расходиться с нашим: старые уведомления
+	// не принимаются, чтобы перехваченный запрос нельзя было воспроизвести.
+	WebhookTolerance = 5 * time.Minute
+)
+
+// WebhookEventType — тип уведомления платёжного шлюза.
+type WebhookEventType string
+
+const (
+	WebhookHoldSucceeded WebhookEventType = "hold.succeeded" // Шлюз подтвердил блокировку средств
+	WebhookHoldFailed    WebhookEventType = "hold.failed"    // Шлюз отклонил блокировку средств
+)
+
+// WebhookStatus — результат обработки сохранённого уведомления.
+type WebhookStatus string
+
+const (
+	WebhookReceived  WebhookStatus = "received"  // Сохранено, ещё не обработано
+	WebhookProcessed WebhookStatus = "processed" // Изменило состояние платежа
+	WebhookIgnored   WebhookStatus = "ignored"   // Не требует действий: неизвестный тип или платёж уже в нужном состоянии
+	WebhookFailed    WebhookStatus = "failed"    // Обработка не удалась; можно повторить
+)
+
+var (
+	ErrInvalidSignature     = errors.New("неверная подпись уведомления платёжного шлюза")
+	ErrInvalidWebhook       = errors.New("некорректное уведомление платёжного шлюза")
+	ErrWebhookNotConfigured = errors.New("секрет для уведомлений платёжного шлюза не задан")
+	ErrWebhookEventNotFound = errors.New("уведомление платёжного шлюза не найдено")
+)
+
+// WebhookEvent — уведомление платёжного шлюза о блокировке.
+type WebhookEvent struct {
+	ID            string           `json:"id"`
+	Type          WebhookEventType `json:"type"`
+	HoldRef       string           `json:"hold_ref"`
+	Amount        int              `json:"amount"`
+	FailureReason string           `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// StoredWebhookEvent — сохранённое уведомление. Исходное тело хранится как есть,
+// чтобы уведомление можно было обработать повторно.
+type StoredWebhookEvent struct {
+	ID          int64            `json:"id"`
+	EventID     string           `json:"event_id"`
+	Type        WebhookEventType `json:"type"`
+	Payload     json.RawMessage  `json:"payload"`
+	Status      WebhookStatus    `json:"status"`
+	Error       string           `json:"error,omitempty"`
+	Attempts    int              `json:"attempts"`
+	ReceivedAt  time.Time        `json:"received_at"`
+	ProcessedAt *time.Time       `json:"processed_at,omitempty"`
+}
+
+// WebhookResult — ответ шлюзу на уведомление.
+type WebhookResult struct {
+	EventID   string        `json:"event_id"`
+	Status    WebhookStatus `json:"status"`
+	Duplicate bool          `json:"duplicate"` // Уведомление уже было обработано
+}
+
+// Done сообщает, обработано ли уведомление окончательно. Такие повторно не обрабатываются.
+func (e StoredWebhookEvent) Done() bool {
+	return e.Status == WebhookProcessed || e.Status == WebhookIgnored
+}
+
+// ParseWebhookEvent разбирает тело уведомления.
+func ParseWebhookEvent(body []byte) (WebhookEvent, error) {
+	var e WebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return e, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if e.ID == "" || e.Type == "" {
+		return e, fmt.Errorf("%w: не указан id или type", ErrInvalidWebhook)
+	}
+	return e, nil
+}
+
+// SignWebhook подписывает тело уведомления так же, как это делает платёжный шлюз,
+// и возвращает значение заголовка WebhookSignatureHeader. Нужен для тестов и локальной отладки.
+func SignWebhook(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+// VerifyWebhookSignature проверяет подпись уведомления и её свежесть.
+func VerifyWebhookSignature(secret, header string, body []byte, now time.Time) error {
+	if secret == "" {
+		return ErrWebhookNotConfigured
+	}
+
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: в заголовке нет времени или подписи", ErrInvalidSignature)
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: некорректное время подписи", ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > WebhookTolerance || age < -WebhookTolerance {
+		return fmt.Errorf("%w: подпись устарела", ErrInvalidSignature)
+	}
+
+	expected := webhookMAC(secret, ts, body)
+	// Шлюз может присылать несколько подписей на время смены секрета.
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func webhookMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
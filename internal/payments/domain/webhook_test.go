@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_1","type":"hold.succeeded","hold_ref":"hold_1","amount":5000}`)
+	header := SignWebhook("secret", now, body)
+
+	if err := VerifyWebhookSignature("secret", header, body, now.Add(time.Minute)); err != nil {
+		t.Fatalf("подпись должна проходить проверку: %v", err)
+	}
+
+	cases := map[string]struct {
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		"изменённое тело":   {"secret", header, []byte(`{"id":"evt_1","type":"hold.succeeded","amount":1}`), now},
+		"другой секрет":     {"other", header, body, now},
+		"устаревшая":        {"secret", header, body, now.Add(WebhookTolerance + time.Second)},
+		"без подписи":       {"secret", "t=1700000000", body, now},
+		"мусор в заголовке": {"secret", "garbage", body, now},
+	}
+	for name, c := range cases {
+		if err := VerifyWebhookSignature(c.secret, c.header, c.body, c.now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: ожидалась ErrInvalidSignature, получено %v", name, err)
+		}
+	}
+
+	if err := VerifyWebhookSignature("", header, body, now); !errors.Is(err, ErrWebhookNotConfigured) {
+		t.Errorf("без секрета: ожидалась ErrWebhookNotConfigured, получено %v", err)
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	e, err := ParseWebhookEvent([]byte(`{"id":"evt_2","type":"hold.failed","hold_ref":"hold_2","failure_reason":"card declined"}`))
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if e.Type != WebhookHoldFailed || e.HoldRef != "hold_2" || e.FailureReason != "card declined" {
+		t.Errorf("неверно разобрано уведомление: %+v", e)
+	}
+
+	for _, body := range []string{`not json`, `{"type":"hold.failed"}`, `{"id":"evt_3"}`} {
+		if _, err := ParseWebhookEvent([]byte(body)); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("%s: ожидалась ErrInvalidWebhook, получено %v", body, err)
+		}
+	}
+}
+
+func TestCanHoldPendingConfirmation(t *testing.T) {
+	if !(Payment{Status: StatusPending}).CanHold() {
+		t.Error("новый платёж можно заблокировать")
+	}
+	if (Payment{Status: StatusPending, GatewayRef: "hold_1"}).CanHold() {
+		t.Error("блокировка, ожидающая подтверждения шлюза, не должна запрашиваться повторно")
+	}
+}
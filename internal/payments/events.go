@@ -1 +1,81 @@
-package payments
\ No newline at end of file
+package payments
+
+// EscrowHeldEvent — событие успешной блокировки средств заказчика по контракту.
+type EscrowHeldEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	ExecutorID int64
+	Amount     int
+}
+
+// EscrowHoldFailedEvent — событие отказа шлюза в блокировке средств.
+// Заказчик может повторить блокировку, пополнив счёт.
+type EscrowHoldFailedEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	Amount     int
+	Reason     string
+}
+
+// EscrowReleasedEvent — событие выплаты исполнителю из эскроу.
+// Fee — удержанная с выплаты комиссия площадки, на кошелёк исполнителя поступило Amount - Fee.
+type EscrowReleasedEvent struct {
+	PaymentID  int64
+	ContractID int64
+	ExecutorID int64
+	Amount     int
+	Fee        int
+}
+
+// EscrowRefundedEvent — событие возврата остатка эскроу заказчику.
+type EscrowRefundedEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	Amount     int
+}
+
+// EscrowClosedEvent — событие закрытия эскроу: вся сумма распределена между исполнителем и заказчиком.
+// Status — итоговый статус платежа: released, refunded или settled.
+type EscrowClosedEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	ExecutorID int64
+	Status     string
+	Released   int
+	Refunded   int
+	Fee        int
+}
+
+// PaymentSucceededEvent — событие подтверждения платежа шлюзом по уведомлению на вебхук.
+// EventID — идентификатор уведомления шлюза.
+type PaymentSucceededEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	ExecutorID int64
+	Amount     int
+	EventID    string
+}
+
+// PaymentFailedEvent — событие отказа в платеже, о котором шлюз сообщил уведомлением на вебхук.
+type PaymentFailedEvent struct {
+	PaymentID  int64
+	ContractID int64
+	CustomerID int64
+	Amount     int
+	Reason     string
+	EventID    string
+}
+
+// PayoutStatusChangedEvent — событие смены статуса заявки на вывод: одобрена, отклонена, выплачена или не прошла.
+type PayoutStatusChangedEvent struct {
+	PayoutID int64
+	UserID   int64
+	Amount   int
+	Status   string
+	Reason   string
+}
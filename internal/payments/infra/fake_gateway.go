@@ -0,0 +1,141 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+)
+
+// FakeGateway — локальный платёжный шлюз для разработки и тестов. Ведёт блокировки в памяти
+// и повторяет поведение настоящего шлюза: идемпотентность по ключу и контроль остатка блокировки.
+type FakeGateway struct {
+	mu           sync.Mutex
+	declineAbove int // Блокировки сверх этой суммы отклоняются; 0 — без ограничения
+	holds        map[string]*fakeHold
+	byKey        map[string]string // Ключ идемпотентности блокировки -> идентификатор блокировки
+	applied      map[string]bool   // Ключи проведённых списаний и возвратов
+	payouts      map[string]string // Ключ идемпотентности выплаты -> идентификатор выплаты
+	charges      map[string]string // Ключ идемпотентности списания -> идентификатор списания
+	seq          int
+}
+
+type fakeHold struct {
+	amount int
+	moved  int
+}
+
+// NewFakeGateway создаёт локальный шлюз. declineAbove > 0 включает отказ в блокировке больших сумм.
+func NewFakeGateway(declineAbove int) *FakeGateway {
+	return &FakeGateway{
+		declineAbove: declineAbove,
+		holds:        make(map[string]*fakeHold),
+		byKey:        make(map[string]string),
+		applied:      make(map[string]bool),
+		payouts:      make(map[string]string),
+		charges:      make(map[string]string),
+	}
+}
+
+// Hold блокирует сумму. Повторный запрос с тем же ключом возвращает прежнюю блокировку.
+// Локальный шлюз подтверждает блокировку сразу, без уведомления на вебхук.
+func (g *FakeGateway) Hold(_ context.Context, req domain.HoldRequest) (domain.HoldResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ref, ok := g.byKey[req.IdempotencyKey]; ok {
+		return domain.HoldResult{Ref: ref}, nil
+	}
+	if req.Amount <= 0 {
+		return domain.HoldResult{}, fmt.Errorf("%w: некорректная сумма %d", domain.ErrGatewayDeclined, req.Amount)
+	}
+	if g.declineAbove > 0 && req.Amount > g.declineAbove {
+		return domain.HoldResult{}, fmt.Errorf("%w: недостаточно средств", domain.ErrGatewayDeclined)
+	}
+
+	g.seq++
+	ref := fmt.Sprintf("fake-hold-%d", g.seq)
+	g.holds[ref] = &fakeHold{amount: req.Amount}
+	g.byKey[req.IdempotencyKey] = ref
+	return domain.HoldResult{Ref: ref}, nil
+}
+
+// Capture списывает часть блокировки.
+func (g *FakeGateway) Capture(_ context.Context, holdRef string, amount int, idempotencyKey string) error {
+	return g.move(holdRef, amount, idempotencyKey)
+}
+
+// Refund возвращает часть блокировки.
+func (g *FakeGateway) Refund(_ context.Context, holdRef string, amount int, idempotencyKey string) error {
+	return g.move(holdRef, amount, idempotencyKey)
+}
+
+// Charge списывает разовый платёж. Суммы сверх declineAbove отклоняются, как и блокировки.
+func (g *FakeGateway) Charge(_ context.Context, req domain.ChargeRequest) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ref, ok := g.charges[req.IdempotencyKey]; ok {
+		return ref, nil
+	}
+	if req.Amount <= 0 {
+		return "", fmt.Errorf("%w: некорректная сумма %d", domain.ErrGatewayDeclined, req.Amount)
+	}
+	if g.declineAbove > 0 && req.Amount > g.declineAbove {
+		return "", fmt.Errorf("%w: недостаточно средств", domain.ErrGatewayDeclined)
+	}
+
+	g.seq++
+	ref := fmt.Sprintf("fake-charge-%d", g.seq)
+	g.charges[req.IdempotencyKey] = ref
+	return ref, nil
+}
+
+// Payout переводит выплату. Повторный запрос с тем же ключом возвращает прежний перевод.
+func (g *FakeGateway) Payout(_ context.Context, req domain.PayoutTransfer) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ref, ok := g.payouts[req.IdempotencyKey]; ok {
+		return ref, nil
+	}
+	if req.Amount <= 0 || req.Destination == "" {
+		return "", fmt.Errorf("%w: некорректная выплата", domain.ErrGatewayDeclined)
+	}
+
+	g.seq++
+	ref := fmt.Sprintf("fake-payout-%d", g.seq)
+	g.payouts[req.IdempotencyKey] = ref
+	return ref, nil
+}
+
+// Remaining возвращает ещё не списанный и не возвращённый остаток блокировки.
+func (g *FakeGateway) Remaining(holdRef string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if hold, ok := g.holds[holdRef]; ok {
+		return hold.amount - hold.moved
+	}
+	return 0
+}
+
+func (g *FakeGateway) move(holdRef string, amount int, idempotencyKey string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.applied[idempotencyKey] {
+		return nil
+	}
+	hold, ok := g.holds[holdRef]
+	if !ok {
+		return fmt.Errorf("%w: блокировка %q не найдена", domain.ErrGatewayDeclined, holdRef)
+	}
+	if amount <= 0 || amount > hold.amount-hold.moved {
+		return fmt.Errorf("%w: сумма %d превышает остаток блокировки %d", domain.ErrGatewayDeclined, amount, hold.amount-hold.moved)
+	}
+	hold.moved += amount
+	g.applied[idempotencyKey] = true
+	return nil
+}
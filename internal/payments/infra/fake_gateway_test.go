@@ -0,0 +1,45 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+)
+
+func TestFakeGatewayIdempotency(t *testing.T) {
+	ctx := context.Background()
+	gw := NewFakeGateway(0)
+
+	res, err := gw.Hold(ctx, domain.HoldRequest{IdempotencyKey: "hold-1", Amount: 1000})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка блокировки: %v", err)
+	}
+	ref := res.Ref
+	again, err := gw.Hold(ctx, domain.HoldRequest{IdempotencyKey: "hold-1", Amount: 1000})
+	if err != nil || again.Ref != ref {
+		t.Fatalf("повтор блокировки должен вернуть %q, получено %q, %v", ref, again.Ref, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := gw.Capture(ctx, ref, 400, "release-1"); err != nil {
+			t.Fatalf("неожиданная ошибка выплаты: %v", err)
+		}
+	}
+	if got := gw.Remaining(ref); got != 600 {
+		t.Errorf("повтор выплаты с тем же ключом списал сумму дважды: остаток %d", got)
+	}
+
+	if err := gw.Refund(ctx, ref, 700, "refund-1"); !errors.Is(err, domain.ErrGatewayDeclined) {
+		t.Errorf("возврат сверх остатка: ожидалась ErrGatewayDeclined, получено %v", err)
+	}
+}
+
+func TestFakeGatewayDecline(t *testing.T) {
+	gw := NewFakeGateway(500)
+	_, err := gw.Hold(context.Background(), domain.HoldRequest{IdempotencyKey: "hold-1", Amount: 1000})
+	if !errors.Is(err, domain.ErrGatewayDeclined) {
+		t.Errorf("ожидалась ErrGatewayDeclined, получено %v", err)
+	}
+}
@@ -1 +1,184 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/config"
+)
+
+// defaultGatewayTimeout используется, если таймаут в конфигурации не задан.
+const defaultGatewayTimeout = 5 * time.Second
+
+// HTTPGateway — адаптер внешнего платёжного шлюза с REST API блокировок (holds), списаний (charges) и выплат (payouts).
+type HTTPGateway struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPGateway создаёт адаптер платёжного шлюза по настройкам external_services.payment_gateway.
+func NewHTTPGateway(cfg config.ExternalService) (*HTTPGateway, error) {
+	if cfg.URL == "" || cfg.APIKey == "" {
+		return nil, fmt.Errorf("для платёжного шлюза нужны url и api_key")
+	}
+	timeout := defaultGatewayTimeout
+	if cfg.Timeout != "" {
+		parsed, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный таймаут платёжного шлюза %q: %w", cfg.Timeout, err)
+		}
+		timeout = parsed
+	}
+	return &HTTPGateway{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		apiKey:  cfg.APIKey,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type holdRequest struct {
+	CustomerID  int64  `json:"customer_id"`
+	Amount      int    `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+}
+
+type payoutRequest struct {
+	Destination string `json:"destination"`
+	Amount      int    `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+}
+
+// payoutResponse — ответ шлюза на выплату или разовое списание.
+type payoutResponse struct {
+	ID string `json:"id"`
+}
+
+type amountRequest struct {
+	Amount int `json:"amount"`
+}
+
+type holdResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // succeeded или pending — тогда результат придёт на вебхук
+}
+
+type gatewayError struct {
+	Message string `json:"message"`
+}
+
+// Hold блокирует средства заказчика. Ответ со статусом pending означает, что шлюз
+// подтвердит или отклонит блокировку уведомлением на вебхук.
+func (g *HTTPGateway) Hold(ctx context.Context, req domain.HoldRequest) (domain.HoldResult, error) {
+	var resp holdResponse
+	err := g.do(ctx, "/holds", req.IdempotencyKey, holdRequest{
+		CustomerID:  req.CustomerID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+	}, &resp)
+	if err != nil {
+		return domain.HoldResult{}, err
+	}
+	if resp.ID == "" {
+		return domain.HoldResult{}, fmt.Errorf("%w: шлюз не вернул идентификатор блокировки", domain.ErrGatewayUnavailable)
+	}
+	return domain.HoldResult{Ref: resp.ID, Pending: resp.Status == "pending"}, nil
+}
+
+// Capture списывает часть заблокированной суммы в пользу исполнителя.
+func (g *HTTPGateway) Capture(ctx context.Context, holdRef string, amount int, idempotencyKey string) error {
+	return g.do(ctx, "/holds/"+url.PathEscape(holdRef)+"/capture", idempotencyKey, amountRequest{Amount: amount}, nil)
+}
+
+// Refund снимает блокировку с части суммы и возвращает её заказчику.
+func (g *HTTPGateway) Refund(ctx context.Context, holdRef string, amount int, idempotencyKey string) error {
+	return g.do(ctx, "/holds/"+url.PathEscape(holdRef)+"/refund", idempotencyKey, amountRequest{Amount: amount}, nil)
+}
+
+// Charge списывает разовый платёж. Запрос устроен как блокировка, но шлюз сразу её исполняет.
+func (g *HTTPGateway) Charge(ctx context.Context, req domain.ChargeRequest) (string, error) {
+	var resp payoutResponse
+	err := g.do(ctx, "/charges", req.IdempotencyKey, holdRequest{
+		CustomerID:  req.CustomerID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("%w: шлюз не вернул идентификатор списания", domain.ErrGatewayUnavailable)
+	}
+	return resp.ID, nil
+}
+
+// Payout переводит средства по токену сохранённого способа вывода.
+func (g *HTTPGateway) Payout(ctx context.Context, req domain.PayoutTransfer) (string, error) {
+	var resp payoutResponse
+	err := g.do(ctx, "/payouts", req.IdempotencyKey, payoutRequest{
+		Destination: req.Destination,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("%w: шлюз не вернул идентификатор выплаты", domain.ErrGatewayUnavailable)
+	}
+	return resp.ID, nil
+}
+
+// do отправляет POST-запрос в шлюз. Отказ шлюза (4xx) превращается в ErrGatewayDeclined,
+// сетевые ошибки и 5xx — в ErrGatewayUnavailable: такие запросы можно безопасно повторить с тем же ключом.
+func (g *HTTPGateway) do(ctx context.Context, path, idempotencyKey string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать запрос к платёжному шлюзу: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("не удалось создать запрос к платёжному шлюзу: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%w: статус %d", domain.ErrGatewayUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		var gwErr gatewayError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&gwErr)
+		if gwErr.Message == "" {
+			gwErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("%w: %s", domain.ErrGatewayDeclined, gwErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: некорректный ответ: %v", domain.ErrGatewayUnavailable, err)
+	}
+	return nil
+}
@@ -1 +1,600 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/payments/domain"
+)
+
+// paymentColumns — поля платежа в порядке, который ожидает scanPayment.
+const paymentColumns = `
+        id, contract_id, task_id, customer_id, executor_id, amount, released_amount, refunded_amount,
+        fee_rate_bp, fee_amount, currency, status, gateway_ref, failure_reason, hold_attempts, created_at, updated_at`
+
+// webhookEventColumns — поля уведомления в порядке, который ожидает scanWebhookEvent.
+const webhookEventColumns = `id, event_id, type, payload, status, error, attempts, received_at, processed_at`
+
+// payoutMethodColumns — поля способа вывода в порядке, который ожидает scanPayoutMethod.
+const payoutMethodColumns = `id, user_id, kind, token, last4, created_at, deleted_at`
+
+// payoutColumns — поля заявки на вывод в порядке, который ожидает scanPayout.
+const payoutColumns = `
+        id, user_id, method_id, amount, currency, status, batch_id, gateway_ref, reason,
+        reviewed_by, reviewed_at, completed_at, created_at`
+
+// PaymentsRepository хранит эскроу-платежи в PostgreSQL.
+type PaymentsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPaymentsRepository создаёт новый репозиторий платежей.
+func NewPaymentsRepository(db *pgxpool.Pool) *PaymentsRepository {
+	return &PaymentsRepository{db: db}
+}
+
+// CreatePayment создаёт платёж по контракту. На контракт приходится один платёж.
+func (r *PaymentsRepository) CreatePayment(ctx context.Context, p domain.Payment) (*domain.Payment, bool, error) {
+	created, err := scanPayment(r.db.QueryRow(ctx, `
+        INSERT INTO payments (contract_id, task_id, customer_id, executor_id, amount, fee_rate_bp, currency, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (contract_id) DO NOTHING
+        RETURNING `+paymentColumns,
+		p.ContractID, p.TaskID, p.CustomerID, p.ExecutorID, p.Amount, p.FeeRateBP, p.Currency, p.Status, p.CreatedAt, p.UpdatedAt))
+	if err == nil {
+		return &created, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("ошибка при создании платежа: %w", err)
+	}
+
+	existing, err := r.GetPaymentByContract(ctx, p.ContractID)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// GetPaymentByContract получает платёж по контракту вместе с историей операций.
+func (r *PaymentsRepository) GetPaymentByContract(ctx context.Context, contractID int64) (*domain.Payment, error) {
+	p, err := scanPayment(r.db.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE contract_id = $1`, contractID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении платежа по контракту %d: %w", contractID, err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT id, payment_id, kind, amount, fee, idempotency_key, created_at
+        FROM payment_operations
+        WHERE payment_id = $1
+        ORDER BY created_at, id`, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении операций по платежу: %w", err)
+	}
+	defer rows.Close()
+
+	p.Operations = []domain.Operation{}
+	for rows.Next() {
+		var op domain.Operation
+		if err := rows.Scan(&op.ID, &op.PaymentID, &op.Kind, &op.Amount, &op.Fee, &op.IdempotencyKey, &op.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании операции: %w", err)
+		}
+		p.Operations = append(p.Operations, op)
+	}
+	return &p, rows.Err()
+}
+
+// UpdateHold записывает результат блокировки средств и, при успехе, операцию блокировки.
+func (r *PaymentsRepository) UpdateHold(ctx context.Context, paymentID int64, status domain.PaymentStatus, gatewayRef, failureReason string, op *domain.Operation) (*domain.Payment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanPayment(tx.QueryRow(ctx, `
+        UPDATE payments
+        SET status = $2, gateway_ref = $3, failure_reason = $4, hold_attempts = hold_attempts + 1, updated_at = NOW()
+        WHERE id = $1
+        RETURNING `+paymentColumns, paymentID, status, gatewayRef, failureReason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка при обновлении платежа с ID %d: %w", paymentID, err)
+	}
+
+	if op != nil {
+		if _, err := insertOperation(ctx, tx, *op); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить результат блокировки: %w", err)
+	}
+	return &p, nil
+}
+
+// RecordOperation учитывает выплату или возврат. Платёж блокируется на время записи,
+// чтобы параллельные операции не распределили одну и ту же сумму дважды.
+func (r *PaymentsRepository) RecordOperation(ctx context.Context, paymentID int64, op domain.Operation) (*domain.Payment, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanPayment(tx.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, paymentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, domain.ErrPaymentNotFound
+		}
+		return nil, false, fmt.Errorf("ошибка при блокировке платежа с ID %d: %w", paymentID, err)
+	}
+
+	inserted, err := insertOperation(ctx, tx, op)
+	if err != nil {
+		return nil, false, err
+	}
+	if !inserted {
+		return &p, false, nil
+	}
+
+	updated, err := p.Apply(op)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE payments
+        SET released_amount = $2, refunded_amount = $3, fee_amount = $4, status = $5, updated_at = $6
+        WHERE id = $1`, paymentID, updated.ReleasedAmount, updated.RefundedAmount, updated.FeeAmount, updated.Status, updated.UpdatedAt)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при обновлении платежа с ID %d: %w", paymentID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("не удалось сохранить операцию по платежу: %w", err)
+	}
+	return &updated, true, nil
+}
+
+// GetPaymentByGatewayRef получает платёж по идентификатору блокировки в шлюзе.
+func (r *PaymentsRepository) GetPaymentByGatewayRef(ctx context.Context, gatewayRef string) (*domain.Payment, error) {
+	if gatewayRef == "" {
+		return nil, domain.ErrPaymentNotFound
+	}
+	p, err := scanPayment(r.db.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE gateway_ref = $1`, gatewayRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении платежа по блокировке %q: %w", gatewayRef, err)
+	}
+	return &p, nil
+}
+
+// SetHoldStatus переводит платёж из статуса from в to и, если передана, записывает операцию блокировки.
+func (r *PaymentsRepository) SetHoldStatus(ctx context.Context, paymentID int64, from, to domain.PaymentStatus, failureReason string, op *domain.Operation) (*domain.Payment, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanPayment(tx.QueryRow(ctx, `
+        UPDATE payments
+        SET status = $3, failure_reason = $4, updated_at = NOW()
+        WHERE id = $1 AND status = $2
+        RETURNING `+paymentColumns, paymentID, from, to, failureReason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("ошибка при смене статуса платежа с ID %d: %w", paymentID, err)
+	}
+
+	if op != nil {
+		if _, err := insertOperation(ctx, tx, *op); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("не удалось сохранить статус платежа: %w", err)
+	}
+	return &p, true, nil
+}
+
+// SavePendingRelease сохраняет выплату за этап до её проведения. Повторное событие о том же этапе не дублирует её.
+func (r *PaymentsRepository) SavePendingRelease(ctx context.Context, pr domain.PendingRelease) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO payment_pending_releases (contract_id, amount, idempotency_key, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (idempotency_key) DO NOTHING`, pr.ContractID, pr.Amount, pr.IdempotencyKey, pr.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении выплаты %q: %w", pr.IdempotencyKey, err)
+	}
+	return nil
+}
+
+// ListPendingReleases возвращает ещё не проведённые выплаты по контракту.
+func (r *PaymentsRepository) ListPendingReleases(ctx context.Context, contractID int64) ([]domain.PendingRelease, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT contract_id, amount, idempotency_key, created_at
+        FROM payment_pending_releases
+        WHERE contract_id = $1 AND released_at IS NULL
+        ORDER BY created_at, id`, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении отложенных выплат по контракту %d: %w", contractID, err)
+	}
+	defer rows.Close()
+
+	releases := []domain.PendingRelease{}
+	for rows.Next() {
+		var pr domain.PendingRelease
+		if err := rows.Scan(&pr.ContractID, &pr.Amount, &pr.IdempotencyKey, &pr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании отложенной выплаты: %w", err)
+		}
+		releases = append(releases, pr)
+	}
+	return releases, rows.Err()
+}
+
+// MarkReleaseDone отмечает выплату проведённой.
+func (r *PaymentsRepository) MarkReleaseDone(ctx context.Context, idempotencyKey string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE payment_pending_releases SET released_at = $2
+        WHERE idempotency_key = $1 AND released_at IS NULL`, idempotencyKey, at)
+	if err != nil {
+		return fmt.Errorf("ошибка при отметке выплаты %q: %w", idempotencyKey, err)
+	}
+	return nil
+}
+
+// SaveWebhookEvent сохраняет уведомление шлюза. Повторное уведомление с тем же ID не дублируется.
+func (r *PaymentsRepository) SaveWebhookEvent(ctx context.Context, e domain.StoredWebhookEvent) (*domain.StoredWebhookEvent, bool, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO payment_webhook_events (event_id, type, payload, status, received_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (event_id) DO NOTHING
+        RETURNING id`, e.EventID, e.Type, string(e.Payload), e.Status, e.ReceivedAt).Scan(&id)
+	if err == nil {
+		e.ID = id
+		return &e, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("ошибка при сохранении уведомления %q: %w", e.EventID, err)
+	}
+
+	existing, err := scanWebhookEvent(r.db.QueryRow(ctx, `SELECT `+webhookEventColumns+` FROM payment_webhook_events WHERE event_id = $1`, e.EventID))
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при получении уведомления %q: %w", e.EventID, err)
+	}
+	return &existing, false, nil
+}
+
+// GetWebhookEvent получает сохранённое уведомление.
+func (r *PaymentsRepository) GetWebhookEvent(ctx context.Context, id int64) (*domain.StoredWebhookEvent, error) {
+	e, err := scanWebhookEvent(r.db.QueryRow(ctx, `SELECT `+webhookEventColumns+` FROM payment_webhook_events WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookEventNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении уведомления с ID %d: %w", id, err)
+	}
+	return &e, nil
+}
+
+// ListWebhookEvents возвращает последние уведомления; пустой status — все.
+func (r *PaymentsRepository) ListWebhookEvents(ctx context.Context, status domain.WebhookStatus, limit int) ([]domain.StoredWebhookEvent, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+webhookEventColumns+`
+        FROM payment_webhook_events
+        WHERE $1 = '' OR status = $1
+        ORDER BY received_at DESC, id DESC
+        LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении уведомлений шлюза: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.StoredWebhookEvent{}
+	for rows.Next() {
+		e, err := scanWebhookEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании уведомления: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// MarkWebhookEvent записывает результат очередной попытки обработки уведомления.
+func (r *PaymentsRepository) MarkWebhookEvent(ctx context.Context, id int64, status domain.WebhookStatus, errText string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE payment_webhook_events
+        SET status = $2, error = $3, attempts = attempts + 1, processed_at = $4
+        WHERE id = $1`, id, status, errText, at)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении уведомления с ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// IsAdmin проверяет, доступен ли пользователю журнал уведомлений шлюза.
+func (r *PaymentsRepository) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND type = $2)`,
+		userID, domain.AdminUserType).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке прав пользователя %d: %w", userID, err)
+	}
+	return ok, nil
+}
+
+// GetFeeBasis возвращает категорию и подкатегорию задачи и статус Pro исполнителя.
+func (r *PaymentsRepository) GetFeeBasis(ctx context.Context, taskID, executorID int64) (domain.FeeBasis, error) {
+	var basis domain.FeeBasis
+	err := r.db.QueryRow(ctx, `
+        SELECT t.category_id, t.subcategory_id, COALESCE(u.pro, FALSE)
+        FROM tasks t
+        LEFT JOIN users u ON u.id = $2
+        WHERE t.id = $1`, taskID, executorID).Scan(&basis.CategoryID, &basis.SubcategoryID, &basis.ExecutorPro)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return domain.FeeBasis{}, fmt.Errorf("ошибка при получении условий комиссии по задаче %d: %w", taskID, err)
+	}
+	return basis, nil
+}
+
+// CreatePayoutMethod сохраняет способ вывода.
+func (r *PaymentsRepository) CreatePayoutMethod(ctx context.Context, m domain.PayoutMethod) (*domain.PayoutMethod, error) {
+	created, err := scanPayoutMethod(r.db.QueryRow(ctx, `
+        INSERT INTO payout_methods (user_id, kind, token, last4, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING `+payoutMethodColumns, m.UserID, m.Kind, m.Token, m.Last4, m.CreatedAt))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении способа вывода: %w", err)
+	}
+	return &created, nil
+}
+
+// GetPayoutMethod получает способ вывода, в том числе удалённый.
+func (r *PaymentsRepository) GetPayoutMethod(ctx context.Context, id int64) (*domain.PayoutMethod, error) {
+	m, err := scanPayoutMethod(r.db.QueryRow(ctx, `SELECT `+payoutMethodColumns+` FROM payout_methods WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPayoutMethodNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении способа вывода с ID %d: %w", id, err)
+	}
+	return &m, nil
+}
+
+// ListPayoutMethods возвращает действующие способы вывода пользователя.
+func (r *PaymentsRepository) ListPayoutMethods(ctx context.Context, userID int64) ([]domain.PayoutMethod, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+payoutMethodColumns+`
+        FROM payout_methods
+        WHERE user_id = $1 AND deleted_at IS NULL
+        ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении способов вывода: %w", err)
+	}
+	defer rows.Close()
+
+	methods := []domain.PayoutMethod{}
+	for rows.Next() {
+		m, err := scanPayoutMethod(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании способа вывода: %w", err)
+		}
+		methods = append(methods, m)
+	}
+	return methods, rows.Err()
+}
+
+// DeletePayoutMethod помечает способ вывода удалённым.
+func (r *PaymentsRepository) DeletePayoutMethod(ctx context.Context, userID, id int64, at time.Time) error {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE payout_methods SET deleted_at = $3
+        WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении способа вывода с ID %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPayoutMethodNotFound
+	}
+	return nil
+}
+
+// CreatePayout создаёт заявку на вывод. Повтор запроса с тем же ключом не создаёт вторую заявку.
+func (r *PaymentsRepository) CreatePayout(ctx context.Context, p domain.Payout) (*domain.Payout, bool, error) {
+	created, err := scanPayout(r.db.QueryRow(ctx, `
+        INSERT INTO payouts (user_id, method_id, amount, currency, status, created_at, request_key)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+        ON CONFLICT (user_id, request_key) DO NOTHING
+        RETURNING `+payoutColumns, p.UserID, p.MethodID, p.Amount, p.Currency, p.Status, p.CreatedAt, p.RequestKey))
+	if err == nil {
+		return &created, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("ошибка при создании заявки на вывод: %w", err)
+	}
+
+	existing, err := scanPayout(r.db.QueryRow(ctx, `
+        SELECT `+payoutColumns+` FROM payouts WHERE user_id = $1 AND request_key = $2`, p.UserID, p.RequestKey))
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при получении заявки на вывод по ключу %q: %w", p.RequestKey, err)
+	}
+	return &existing, false, nil
+}
+
+// GetPayout получает заявку на вывод по ID.
+func (r *PaymentsRepository) GetPayout(ctx context.Context, id int64) (*domain.Payout, error) {
+	p, err := scanPayout(r.db.QueryRow(ctx, `SELECT `+payoutColumns+` FROM payouts WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPayoutNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении заявки на вывод с ID %d: %w", id, err)
+	}
+	return &p, nil
+}
+
+// ListPayoutsByUser возвращает заявки пользователя, новые первыми.
+func (r *PaymentsRepository) ListPayoutsByUser(ctx context.Context, userID int64, limit int) ([]domain.Payout, error) {
+	return r.listPayouts(ctx, `
+        SELECT `+payoutColumns+`
+        FROM payouts
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2`, userID, limit)
+}
+
+// ListPayoutsByStatus возвращает заявки с заданным статусом в порядке очереди.
+func (r *PaymentsRepository) ListPayoutsByStatus(ctx context.Context, status domain.PayoutStatus, limit int) ([]domain.Payout, error) {
+	return r.listPayouts(ctx, `
+        SELECT `+payoutColumns+`
+        FROM payouts
+        WHERE status = $1
+        ORDER BY created_at, id
+        LIMIT $2`, status, limit)
+}
+
+// TransitionPayout меняет статус заявки, только если она всё ещё в статусе t.From.
+// Возврат в очередь снимает заявку с пакета, завершающие статусы проставляют время завершения.
+func (r *PaymentsRepository) TransitionPayout(ctx context.Context, id int64, t domain.PayoutTransition) (*domain.Payout, bool, error) {
+	p, err := scanPayout(r.db.QueryRow(ctx, `
+        UPDATE payouts
+        SET status = $3,
+            reason = CASE WHEN $4 <> '' THEN $4 ELSE reason END,
+            gateway_ref = CASE WHEN $5 <> '' THEN $5 ELSE gateway_ref END,
+            reviewed_by = COALESCE($6, reviewed_by),
+            reviewed_at = CASE WHEN $6::BIGINT IS NOT NULL THEN $7 ELSE reviewed_at END,
+            batch_id = CASE WHEN $3 = 'approved' THEN NULL ELSE batch_id END,
+            completed_at = CASE WHEN $3 IN ('paid', 'failed', 'rejected') THEN $7 ELSE completed_at END
+        WHERE id = $1 AND status = $2
+        RETURNING `+payoutColumns, id, t.From, t.To, t.Reason, t.GatewayRef, t.ReviewedBy, t.At))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("ошибка при смене статуса заявки на вывод с ID %d: %w", id, err)
+	}
+	return &p, true, nil
+}
+
+// ClaimPayoutBatch создаёт пакет и переводит в него до limit одобренных заявок, давно одобренные первыми.
+// Заявки, которые уже забрал параллельный обработчик, пропускаются.
+func (r *PaymentsRepository) ClaimPayoutBatch(ctx context.Context, limit int, at time.Time) ([]domain.Payout, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var batchID int64
+	if err := tx.QueryRow(ctx, `INSERT INTO payout_batches (created_at) VALUES ($1) RETURNING id`, at).Scan(&batchID); err != nil {
+		return nil, fmt.Errorf("ошибка при создании пакета выплат: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+        UPDATE payouts
+        SET status = 'processing', batch_id = $1
+        WHERE id IN (
+            SELECT id FROM payouts
+            WHERE status = 'approved'
+            ORDER BY reviewed_at, id
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING `+payoutColumns, batchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании пакета выплат: %w", err)
+	}
+	batch, err := collectPayouts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(batch) == 0 {
+		// Пустой пакет не сохраняется.
+		return nil, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить пакет выплат: %w", err)
+	}
+	return batch, nil
+}
+
+func (r *PaymentsRepository) listPayouts(ctx context.Context, query string, args ...interface{}) ([]domain.Payout, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заявок на вывод: %w", err)
+	}
+	return collectPayouts(rows)
+}
+
+func collectPayouts(rows pgx.Rows) ([]domain.Payout, error) {
+	defer rows.Close()
+
+	payouts := []domain.Payout{}
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании заявки на вывод: %w", err)
+		}
+		payouts = append(payouts, p)
+	}
+	return payouts, rows.Err()
+}
+
+// insertOperation добавляет операцию; false, если операция с таким ключом уже записана.
+func insertOperation(ctx context.Context, tx pgx.Tx, op domain.Operation) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO payment_operations (payment_id, kind, amount, fee, idempotency_key, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (idempotency_key) DO NOTHING`,
+		op.PaymentID, op.Kind, op.Amount, op.Fee, op.IdempotencyKey, op.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при записи операции по платежу: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanPayment(row pgx.Row) (domain.Payment, error) {
+	var p domain.Payment
+	err := row.Scan(&p.ID, &p.ContractID, &p.TaskID, &p.CustomerID, &p.ExecutorID, &p.Amount, &p.ReleasedAmount, &p.RefundedAmount,
+		&p.FeeRateBP, &p.FeeAmount, &p.Currency, &p.Status, &p.GatewayRef, &p.FailureReason, &p.HoldAttempts, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func scanWebhookEvent(row pgx.Row) (domain.StoredWebhookEvent, error) {
+	var e domain.StoredWebhookEvent
+	var payload string
+	err := row.Scan(&e.ID, &e.EventID, &e.Type, &payload, &e.Status, &e.Error, &e.Attempts, &e.ReceivedAt, &e.ProcessedAt)
+	e.Payload = []byte(payload)
+	return e, err
+}
+
+func scanPayoutMethod(row pgx.Row) (domain.PayoutMethod, error) {
+	var m domain.PayoutMethod
+	err := row.Scan(&m.ID, &m.UserID, &m.Kind, &m.Token, &m.Last4, &m.CreatedAt, &m.DeletedAt)
+	return m, err
+}
+
+func scanPayout(row pgx.Row) (domain.Payout, error) {
+	var p domain.Payout
+	err := row.Scan(&p.ID, &p.UserID, &p.MethodID, &p.Amount, &p.Currency, &p.Status, &p.BatchID, &p.GatewayRef, &p.Reason,
+		&p.ReviewedBy, &p.ReviewedAt, &p.CompletedAt, &p.CreatedAt)
+	return p, err
+}
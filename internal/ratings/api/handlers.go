@@ -1 +1,38 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/ratings/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+)
+
+// RatingsHandler отвечает за обработку HTTP-запросов к рейтингам исполнителей.
+type RatingsHandler struct {
+	service domain.RatingsService
+}
+
+// NewRatingsHandler создаёт новый экземпляр RatingsHandler.
+func NewRatingsHandler(service domain.RatingsService) *RatingsHandler {
+	return &RatingsHandler{service: service}
+}
+
+// GetExecutorRating возвращает общий рейтинг исполнителя и рейтинги по категориям и подкатегориям.
+func (h *RatingsHandler) GetExecutorRating(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор пользователя: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	rating, err := h.service.GetExecutorRating(r.Context(), userID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, rating)
+}
@@ -1 +1,39 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// RatingsService — интерфейс для бизнес-логики рейтингов исполнителей.
+type RatingsService interface {
+	// GetExecutorRating возвращает общий рейтинг исполнителя и рейтинги по категориям.
+	GetExecutorRating(ctx context.Context, userID int64) (ExecutorRating, error)
+	// HandleReviewPublished учитывает опубликованный отзыв в рейтинге исполнителя.
+	HandleReviewPublished(event eventbus.Event)
+	// HandleReviewRemoved исключает снятый модератором отзыв из рейтинга исполнителя.
+	HandleReviewRemoved(event eventbus.Event)
+
+	// Refresh учитывает пропущенные отзывы и приводит устаревшие срезы к моменту now.
+	// Возвращает число обновлённых отзывов и срезов.
+	Refresh(ctx context.Context, now time.Time) (int, error)
+	RunRefresh(ctx context.Context, interval time.Duration)
+}
+
+// RatingsRepository — интерфейс для хранения рейтингов.
+type RatingsRepository interface {
+	GetReview(ctx context.Context, reviewID int64) (*Review, error) // ErrReviewNotFound
+	// ApplyReview в одной транзакции запоминает review.Rating как учтённую оценку отзыва, блокирует
+	// срезы review.Scopes() и обновляет каждый функцией update, передавая ранее учтённую оценку (0 — не учтён).
+	// Отсутствующие срезы создаются пустыми. Возвращает false, если учтённая оценка не изменилась.
+	ApplyReview(ctx context.Context, review Review, update func(a *Aggregate, prev int)) (bool, error)
+	// ListUnapplied возвращает отзывы об исполнителях, учтённая оценка которых отличается от текущей, старые первыми.
+	ListUnapplied(ctx context.Context, limit int) ([]Review, error)
+	// ListStale возвращает срезы, приведённые к моменту раньше before.
+	ListStale(ctx context.Context, before time.Time, limit int) ([]Aggregate, error)
+	// UpdateAggregate блокирует срез и обновляет его функцией update.
+	UpdateAggregate(ctx context.Context, userID int64, scope Scope, update func(*Aggregate)) error
+	ListAggregates(ctx context.Context, userID int64) ([]Aggregate, error)
+}
@@ -1 +1,211 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrReviewNotFound возвращается, если отзыв не найден или оставлен не исполнителю.
+	ErrReviewNotFound = errors.New("отзыв об исполнителе не найден")
+	// ErrInvalidSettings возвращается для настроек, с которыми рейтинг нельзя посчитать.
+	ErrInvalidSettings = errors.New("некорректные настройки рейтинга")
+)
+
+// Scope — срез, по которому считается рейтинг: все отзывы (нули), категория или подкатегория задач.
+type Scope struct {
+	CategoryID    int64 `json:"category_id,omitempty"`
+	SubcategoryID int64 `json:"subcategory_id,omitempty"`
+}
+
+// Overall — срез по всем отзывам исполнителя.
+var Overall = Scope{}
+
+// IsOverall сообщает, что срез охватывает все отзывы.
+func (s Scope) IsOverall() bool {
+	return s == Overall
+}
+
+// ScopesFor возвращает срезы, в которые попадает отзыв по задаче: общий, категория и подкатегория.
+// Неизвестная категория или подкатегория (ноль) пропускается.
+func ScopesFor(categoryID, subcategoryID int64) []Scope {
+	scopes := []Scope{Overall}
+	if categoryID > 0 {
+		scopes = append(scopes, Scope{CategoryID: categoryID})
+		if subcategoryID > 0 {
+			scopes = append(scopes, Scope{CategoryID: categoryID, SubcategoryID: subcategoryID})
+		}
+	}
+	return scopes
+}
+
+// Review — отзыв заказчика об исполнителе с категорией задачи, по которой был контракт.
+type Review struct {
+	ID         int64
+	ExecutorID int64
+	// Rating — оценка, которая должна быть учтена в рейтинге: 0, пока отзыв скрыт или после снятия модератором.
+	Rating        int
+	CategoryID    int64
+	SubcategoryID int64
+	CreatedAt     time.Time
+}
+
+// Scopes возвращает срезы, в которые попадает отзыв.
+func (r Review) Scopes() []Scope {
+	return ScopesFor(r.CategoryID, r.SubcategoryID)
+}
+
+// Settings — параметры байесовского рейтинга.
+//
+// Пока отзывов мало, рейтинг тянется к PriorMean так, будто у исполнителя уже есть PriorWeight
+// отзывов с этой оценкой. Вес отзыва убывает вдвое каждые HalfLife, поэтому свежие отзывы
+// влияют на рейтинг сильнее старых.
+type Settings struct {
+	PriorMean   float64
+	PriorWeight float64
+	HalfLife    time.Duration
+}
+
+// DefaultSettings — параметры рейтинга по умолчанию: априорная оценка 4 с весом пяти отзывов,
+// вес отзыва убывает вдвое за полгода.
+var DefaultSettings = Settings{
+	PriorMean:   4,
+	PriorWeight: 5,
+	HalfLife:    180 * 24 * time.Hour,
+}
+
+// Validate проверяет настройки.
+func (s Settings) Validate() error {
+	if s.PriorMean < 1 || s.PriorMean > 5 {
+		return fmt.Errorf("%w: априорная оценка должна быть от 1 до 5, получено %v", ErrInvalidSettings, s.PriorMean)
+	}
+	if s.PriorWeight <= 0 {
+		return fmt.Errorf("%w: вес априорной оценки должен быть положительным, получено %v", ErrInvalidSettings, s.PriorWeight)
+	}
+	if s.HalfLife <= 0 {
+		return fmt.Errorf("%w: период полураспада должен быть положительным, получено %s", ErrInvalidSettings, s.HalfLife)
+	}
+	return nil
+}
+
+// decay возвращает, во сколько раз уменьшается вес отзыва за время от from до to.
+func (s Settings) decay(from, to time.Time) float64 {
+	if !to.After(from) {
+		return 1
+	}
+	return math.Exp2(-float64(to.Sub(from)) / float64(s.HalfLife))
+}
+
+// score считает байесовскую оценку по взвешенной сумме оценок и сумме весов.
+func (s Settings) score(weightedSum, weightTotal float64) float64 {
+	return (s.PriorWeight*s.PriorMean + weightedSum) / (s.PriorWeight + weightTotal)
+}
+
+// Aggregate — накопленные по исполнителю суммы в одном срезе.
+// Взвешенные суммы приведены к моменту AsOf: перед добавлением отзыва они «стареют» до его даты,
+// поэтому пересчёт при новом отзыве не требует чтения всех прошлых отзывов.
+type Aggregate struct {
+	UserID int64
+	Scope
+	Count       int
+	RatingSum   int
+	WeightedSum float64
+	WeightTotal float64
+	Score       float64
+	AsOf        time.Time
+}
+
+// NewAggregate создаёт пустой срез рейтинга исполнителя с априорной оценкой.
+func NewAggregate(userID int64, scope Scope, s Settings) Aggregate {
+	return Aggregate{UserID: userID, Scope: scope, Score: s.score(0, 0)}
+}
+
+// Average возвращает простое среднее оценок без весов и априорной оценки.
+func (a Aggregate) Average() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return float64(a.RatingSum) / float64(a.Count)
+}
+
+// DecayTo приводит взвешенные суммы к моменту at и пересчитывает оценку.
+// Момент раньше AsOf ничего не меняет.
+func (a *Aggregate) DecayTo(at time.Time, s Settings) {
+	if !at.After(a.AsOf) {
+		return
+	}
+	if !a.AsOf.IsZero() {
+		k := s.decay(a.AsOf, at)
+		a.WeightedSum *= k
+		a.WeightTotal *= k
+	}
+	a.AsOf = at
+	a.Score = s.score(a.WeightedSum, a.WeightTotal)
+}
+
+// Add учитывает оценку, поставленную в момент at. Отзыв, учтённый с опозданием
+// (at раньше AsOf), сразу получает вес, соответствующий его возрасту.
+func (a *Aggregate) Add(rating int, at time.Time, s Settings) {
+	a.DecayTo(at, s)
+	weight := s.decay(at, a.AsOf)
+	a.Count++
+	a.RatingSum += rating
+	a.WeightedSum += weight * float64(rating)
+	a.WeightTotal += weight
+	a.Score = s.score(a.WeightedSum, a.WeightTotal)
+}
+
+// Remove вычитает оценку, поставленную в момент at и учтённую ранее через Add.
+// Вес вычитается тот же, что набрал отзыв к моменту AsOf; накопленная погрешность не уводит суммы ниже нуля.
+func (a *Aggregate) Remove(rating int, at time.Time, s Settings) {
+	a.DecayTo(at, s)
+	weight := s.decay(at, a.AsOf)
+	a.Count--
+	a.RatingSum -= rating
+	a.WeightedSum -= weight * float64(rating)
+	a.WeightTotal -= weight
+	if a.Count <= 0 {
+		a.Count, a.RatingSum = 0, 0
+	}
+	if a.Count == 0 || a.WeightTotal <= 0 || a.WeightedSum <= 0 {
+		a.WeightedSum, a.WeightTotal = 0, 0
+	}
+	a.Score = s.score(a.WeightedSum, a.WeightTotal)
+}
+
+// Rating — рейтинг исполнителя в одном срезе, как его видят клиенты.
+type Rating struct {
+	Scope
+	Score       float64 `json:"score"`   // Байесовская оценка с учётом давности отзывов
+	Average     float64 `json:"average"` // Простое среднее оценок
+	ReviewCount int     `json:"review_count"`
+}
+
+// ExecutorRating — общий рейтинг исполнителя и рейтинги по категориям и подкатегориям.
+type ExecutorRating struct {
+	UserID     int64    `json:"user_id"`
+	Overall    Rating   `json:"overall"`
+	Categories []Rating `json:"categories"`
+}
+
+// NewExecutorRating собирает рейтинг исполнителя из срезов, приведённых к моменту now.
+// Исполнитель без отзывов получает априорную оценку.
+func NewExecutorRating(userID int64, aggregates []Aggregate, now time.Time, s Settings) ExecutorRating {
+	result := ExecutorRating{
+		UserID:     userID,
+		Overall:    Rating{Score: s.score(0, 0)},
+		Categories: []Rating{},
+	}
+	for _, a := range aggregates {
+		a.DecayTo(now, s)
+		rating := Rating{Scope: a.Scope, Score: a.Score, Average: a.Average(), ReviewCount: a.Count}
+		if a.IsOverall() {
+			result.Overall = rating
+		} else {
+			result.Categories = append(result.Categories, rating)
+		}
+	}
+	return result
+}
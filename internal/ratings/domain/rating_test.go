@@ -1 +1,136 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAggregateBayesian(t *testing.T) {
+	s := Settings{PriorMean: 4, PriorWeight: 5, HalfLife: 24 * time.Hour}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a := NewAggregate(1, Overall, s)
+	if a.Score != 4 {
+		t.Fatalf("без отзывов ожидалась априорная оценка 4, получено %v", a.Score)
+	}
+
+	a.Add(5, at, s)
+	// (5*4 + 5) / (5 + 1)
+	if !almostEqual(a.Score, 25.0/6) {
+		t.Errorf("один отзыв: ожидалось %v, получено %v", 25.0/6, a.Score)
+	}
+	for range 99 {
+		a.Add(5, at, s)
+	}
+	if a.Score < 4.95 || a.Count != 100 || a.Average() != 5 {
+		t.Errorf("сто пятёрок: получено score=%v count=%d average=%v", a.Score, a.Count, a.Average())
+	}
+}
+
+func TestAggregateRemove(t *testing.T) {
+	s := Settings{PriorMean: 3, PriorWeight: 1, HalfLife: 24 * time.Hour}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Снятие отзыва возвращает срез к состоянию, как если бы отзыва не было.
+	a := NewAggregate(1, Overall, s)
+	a.Add(1, start, s)
+	a.Add(5, start.Add(24*time.Hour), s)
+	a.Remove(1, start, s)
+
+	b := NewAggregate(1, Overall, s)
+	b.Add(5, start.Add(24*time.Hour), s)
+	if a.Count != 1 || a.RatingSum != 5 || !almostEqual(a.Score, b.Score) || !almostEqual(a.WeightTotal, b.WeightTotal) {
+		t.Errorf("после снятия ожидалось %+v, получено %+v", b, a)
+	}
+
+	a.Remove(5, start.Add(24*time.Hour), s)
+	if a.Count != 0 || a.WeightTotal != 0 || a.WeightedSum != 0 || a.Score != 3 {
+		t.Errorf("без отзывов ожидалась априорная оценка, получено %+v", a)
+	}
+}
+
+func TestAggregateRecency(t *testing.T) {
+	s := Settings{PriorMean: 3, PriorWeight: 1, HalfLife: 24 * time.Hour}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Старая единица и свежая пятёрка: пятёрка весит вдвое больше.
+	a := NewAggregate(1, Overall, s)
+	a.Add(1, start, s)
+	a.Add(5, start.Add(24*time.Hour), s)
+	if !almostEqual(a.WeightTotal, 1.5) || !almostEqual(a.Score, (3+0.5+5)/2.5) {
+		t.Errorf("ожидались вес 1.5 и оценка %v, получено %v и %v", (3+0.5+5)/2.5, a.WeightTotal, a.Score)
+	}
+
+	// Порядок учёта не важен: опоздавший старый отзыв получает вес по своему возрасту.
+	b := NewAggregate(1, Overall, s)
+	b.Add(5, start.Add(24*time.Hour), s)
+	b.Add(1, start, s)
+	if !almostEqual(a.Score, b.Score) || !almostEqual(a.WeightTotal, b.WeightTotal) || !b.AsOf.Equal(a.AsOf) {
+		t.Errorf("результат зависит от порядка отзывов: %+v и %+v", a, b)
+	}
+
+	// Со временем оценка без новых отзывов возвращается к априорной.
+	a.DecayTo(start.Add(1000*24*time.Hour), s)
+	if !almostEqual(a.Score, 3) || a.Count != 2 {
+		t.Errorf("старые отзывы: ожидалась оценка 3, получено %v (count=%d)", a.Score, a.Count)
+	}
+}
+
+func TestScopesFor(t *testing.T) {
+	cases := []struct {
+		category, subcategory int64
+		want                  int
+	}{
+		{0, 0, 1},
+		{7, 0, 2},
+		{7, 12, 3},
+		{0, 12, 1},
+	}
+	for _, c := range cases {
+		scopes := ScopesFor(c.category, c.subcategory)
+		if len(scopes) != c.want || !scopes[0].IsOverall() {
+			t.Errorf("ScopesFor(%d, %d) = %+v", c.category, c.subcategory, scopes)
+		}
+	}
+}
+
+func TestNewExecutorRating(t *testing.T) {
+	s := DefaultSettings
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	empty := NewExecutorRating(1, nil, now, s)
+	if empty.Overall.Score != s.PriorMean || empty.Overall.ReviewCount != 0 || len(empty.Categories) != 0 {
+		t.Errorf("без отзывов: %+v", empty)
+	}
+
+	overall := NewAggregate(1, Overall, s)
+	overall.Add(5, now, s)
+	category := NewAggregate(1, Scope{CategoryID: 7}, s)
+	category.Add(5, now, s)
+	r := NewExecutorRating(1, []Aggregate{category, overall}, now, s)
+	if r.Overall.ReviewCount != 1 || len(r.Categories) != 1 || r.Categories[0].CategoryID != 7 {
+		t.Errorf("с отзывом: %+v", r)
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	if err := DefaultSettings.Validate(); err != nil {
+		t.Errorf("настройки по умолчанию: %v", err)
+	}
+	bad := []Settings{
+		{PriorMean: 0, PriorWeight: 5, HalfLife: time.Hour},
+		{PriorMean: 4, PriorWeight: 0, HalfLife: time.Hour},
+		{PriorMean: 4, PriorWeight: 5},
+	}
+	for _, s := range bad {
+		if err := s.Validate(); !errors.Is(err, ErrInvalidSettings) {
+			t.Errorf("%+v: ожидалась ErrInvalidSettings, получено %v", s, err)
+		}
+	}
+}
@@ -1 +1,147 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/reviews"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// refreshBatchSize — сколько отзывов или срезов обрабатывать за один запрос к базе.
+const refreshBatchSize = 200
+
+// staleAfter — через сколько срез рейтинга считается устаревшим и приводится к текущему моменту.
+// Без этого оценка исполнителя, у которого давно не было отзывов, не отражала бы их давность.
+const staleAfter = 24 * time.Hour
+
+type ratingsService struct {
+	repo     RatingsRepository
+	settings Settings
+}
+
+// NewRatingsService создаёт сервис рейтингов исполнителей.
+func NewRatingsService(repo RatingsRepository, settings Settings) RatingsService {
+	return &ratingsService{repo: repo, settings: settings}
+}
+
+// GetExecutorRating возвращает рейтинг исполнителя, приведённый к текущему моменту.
+func (s *ratingsService) GetExecutorRating(ctx context.Context, userID int64) (ExecutorRating, error) {
+	aggregates, err := s.repo.ListAggregates(ctx, userID)
+	if err != nil {
+		return ExecutorRating{}, err
+	}
+	return NewExecutorRating(userID, aggregates, time.Now(), s.settings), nil
+}
+
+// HandleReviewPublished — обработчик публикации отзыва: добавляет оценку в срезы исполнителя
+// без чтения прошлых отзывов. Отзывы о заказчиках в рейтинг исполнителей не попадают.
+func (s *ratingsService) HandleReviewPublished(event eventbus.Event) {
+	e, ok := event.(reviews.ReviewPublishedEvent)
+	if !ok {
+		slog.Error("[Ratings] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.ReviewID)
+}
+
+// HandleReviewRemoved — обработчик снятия отзыва модератором: вычитает его оценку из рейтинга.
+func (s *ratingsService) HandleReviewRemoved(event eventbus.Event) {
+	e, ok := event.(reviews.ReviewRemovedEvent)
+	if !ok {
+		slog.Error("[Ratings] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.ReviewID)
+}
+
+// Refresh учитывает публикации и снятия отзывов, событие о которых было потеряно или пришло до запуска сервиса,
+// и приводит к моменту now срезы, которые давно не обновлялись.
+func (s *ratingsService) Refresh(ctx context.Context, now time.Time) (int, error) {
+	refreshed := 0
+	for {
+		pending, err := s.repo.ListUnapplied(ctx, refreshBatchSize)
+		if err != nil {
+			return refreshed, err
+		}
+		for _, review := range pending {
+			applied, err := s.apply(ctx, review)
+			if err != nil {
+				return refreshed, err
+			}
+			if applied {
+				refreshed++
+			}
+		}
+		if len(pending) < refreshBatchSize {
+			break
+		}
+	}
+
+	for {
+		stale, err := s.repo.ListStale(ctx, now.Add(-staleAfter), refreshBatchSize)
+		if err != nil {
+			return refreshed, err
+		}
+		for _, a := range stale {
+			err := s.repo.UpdateAggregate(ctx, a.UserID, a.Scope, func(a *Aggregate) {
+				a.DecayTo(now, s.settings)
+			})
+			if err != nil {
+				return refreshed, err
+			}
+			refreshed++
+		}
+		if len(stale) < refreshBatchSize {
+			break
+		}
+	}
+	return refreshed, nil
+}
+
+// RunRefresh периодически вызывает Refresh, пока не отменён ctx. Первый проход выполняется сразу,
+// чтобы после запуска учесть отзывы, оставленные до появления рейтингов.
+func (s *ratingsService) RunRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	now := time.Now()
+	for {
+		if _, err := s.Refresh(ctx, now); err != nil {
+			slog.Error("[Ratings] Ошибка обновления рейтингов", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+	}
+}
+
+// sync приводит вклад отзыва в рейтинг к его текущему состоянию.
+func (s *ratingsService) sync(ctx context.Context, reviewID int64) {
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		if !errors.Is(err, ErrReviewNotFound) {
+			slog.Error("[Ratings] Не удалось получить отзыв", "review_id", reviewID, "error", err)
+		}
+		return
+	}
+	if _, err := s.apply(ctx, *review); err != nil {
+		slog.Error("[Ratings] Не удалось учесть отзыв в рейтинге", "review_id", reviewID, "user_id", review.ExecutorID, "error", err)
+	}
+}
+
+// apply заменяет во всех срезах отзыва ранее учтённую оценку текущей.
+func (s *ratingsService) apply(ctx context.Context, review Review) (bool, error) {
+	return s.repo.ApplyReview(ctx, review, func(a *Aggregate, prev int) {
+		if prev > 0 {
+			a.Remove(prev, review.CreatedAt, s.settings)
+		}
+		if review.Rating > 0 {
+			a.Add(review.Rating, review.CreatedAt, s.settings)
+		}
+	})
+}
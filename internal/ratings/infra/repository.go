@@ -1 +1,203 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/ratings/domain"
+)
+
+// aggregateColumns — поля среза рейтинга в порядке, который ожидает scanAggregate.
+const aggregateColumns = `user_id, category_id, subcategory_id, review_count, rating_sum, weighted_sum, weight_total, score, as_of`
+
+// effectiveRating — оценка отзыва, которую должен учитывать рейтинг: скрытые и снятые отзывы не учитываются.
+const effectiveRating = `CASE WHEN rv.published_at IS NOT NULL AND rv.removed_at IS NULL THEN rv.rating ELSE 0 END`
+
+// executorReviews — отзывы об исполнителях с категорией задачи, по которой был контракт.
+// Поля идут в порядке, который ожидает scanReview.
+const executorReviews = `
+        SELECT rv.id, rv.user_id, ` + effectiveRating + `, COALESCE(t.category_id, 0), COALESCE(t.subcategory_id, 0), rv.created_at
+        FROM reviews rv
+        JOIN contracts c ON c.id = rv.contract_id AND c.executor_id = rv.user_id
+        JOIN tasks t ON t.id = c.task_id`
+
+// RatingsRepository хранит рейтинги исполнителей в PostgreSQL.
+type RatingsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRatingsRepository создаёт новый репозиторий рейтингов.
+func NewRatingsRepository(db *pgxpool.Pool) *RatingsRepository {
+	return &RatingsRepository{db: db}
+}
+
+// GetReview возвращает отзыв об исполнителе с категорией задачи.
+func (r *RatingsRepository) GetReview(ctx context.Context, reviewID int64) (*domain.Review, error) {
+	review, err := scanReview(r.db.QueryRow(ctx, executorReviews+` WHERE rv.id = $1`, reviewID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении отзыва %d: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+// ApplyReview заменяет учтённую оценку отзыва и обновляет его срезы в одной транзакции.
+// Строка отзыва в rating_applied_reviews блокируется первой, срезы — в порядке review.Scopes(),
+// поэтому параллельные события об одном отзыве или исполнителе не приводят к взаимоблокировке.
+func (r *RatingsRepository) ApplyReview(ctx context.Context, review domain.Review, update func(a *domain.Aggregate, prev int)) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `INSERT INTO rating_applied_reviews (review_id, rating) VALUES ($1, 0) ON CONFLICT DO NOTHING`, review.ID); err != nil {
+		return false, fmt.Errorf("ошибка при отметке отзыва %d: %w", review.ID, err)
+	}
+	var prev int
+	if err := tx.QueryRow(ctx, `SELECT rating FROM rating_applied_reviews WHERE review_id = $1 FOR UPDATE`, review.ID).Scan(&prev); err != nil {
+		return false, fmt.Errorf("ошибка при блокировке отзыва %d: %w", review.ID, err)
+	}
+	if prev == review.Rating {
+		return false, nil
+	}
+
+	for _, scope := range review.Scopes() {
+		err := updateAggregate(ctx, tx, review.ExecutorID, scope, func(a *domain.Aggregate) {
+			update(a, prev)
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE rating_applied_reviews SET rating = $2, applied_at = NOW() WHERE review_id = $1`, review.ID, review.Rating); err != nil {
+		return false, fmt.Errorf("ошибка при отметке отзыва %d: %w", review.ID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("не удалось зафиксировать рейтинг по отзыву %d: %w", review.ID, err)
+	}
+	return true, nil
+}
+
+// ListUnapplied возвращает отзывы об исполнителях, учтённая в rating_applied_reviews оценка которых
+// отличается от текущей: опубликованные, но не учтённые, и снятые, но ещё учтённые.
+func (r *RatingsRepository) ListUnapplied(ctx context.Context, limit int) ([]domain.Review, error) {
+	rows, err := r.db.Query(ctx, executorReviews+`
+        LEFT JOIN rating_applied_reviews a ON a.review_id = rv.id
+        WHERE `+effectiveRating+` <> COALESCE(a.rating, 0)
+        ORDER BY rv.created_at, rv.id
+        LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении неучтённых отзывов: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []domain.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании отзыва: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// ListStale возвращает срезы, которые не обновлялись с момента before.
+func (r *RatingsRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]domain.Aggregate, error) {
+	return r.listAggregates(ctx, `SELECT `+aggregateColumns+` FROM executor_ratings WHERE as_of < $1 ORDER BY as_of LIMIT $2`, before, limit)
+}
+
+// UpdateAggregate блокирует срез и сохраняет результат update.
+func (r *RatingsRepository) UpdateAggregate(ctx context.Context, userID int64, scope domain.Scope, update func(*domain.Aggregate)) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateAggregate(ctx, tx, userID, scope, update); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать рейтинг пользователя %d: %w", userID, err)
+	}
+	return nil
+}
+
+// ListAggregates возвращает все срезы рейтинга исполнителя: общий первым, затем по категориям.
+func (r *RatingsRepository) ListAggregates(ctx context.Context, userID int64) ([]domain.Aggregate, error) {
+	return r.listAggregates(ctx, `SELECT `+aggregateColumns+` FROM executor_ratings WHERE user_id = $1 ORDER BY category_id, subcategory_id`, userID)
+}
+
+func (r *RatingsRepository) listAggregates(ctx context.Context, query string, args ...interface{}) ([]domain.Aggregate, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении рейтингов: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []domain.Aggregate
+	for rows.Next() {
+		a, err := scanAggregate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании рейтинга: %w", err)
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, rows.Err()
+}
+
+// updateAggregate блокирует срез в транзакции, применяет update и записывает результат.
+// Отсутствующий срез создаётся пустым.
+func updateAggregate(ctx context.Context, tx pgx.Tx, userID int64, scope domain.Scope, update func(*domain.Aggregate)) error {
+	a, err := scanAggregate(tx.QueryRow(ctx, `
+        SELECT `+aggregateColumns+` FROM executor_ratings
+        WHERE user_id = $1 AND category_id = $2 AND subcategory_id = $3
+        FOR UPDATE`, userID, scope.CategoryID, scope.SubcategoryID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("ошибка при блокировке рейтинга пользователя %d: %w", userID, err)
+		}
+		a = domain.Aggregate{UserID: userID, Scope: scope}
+	}
+
+	update(&a)
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO executor_ratings (user_id, category_id, subcategory_id, review_count, rating_sum, weighted_sum, weight_total, score, as_of, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+        ON CONFLICT (user_id, category_id, subcategory_id) DO UPDATE SET
+            review_count = EXCLUDED.review_count,
+            rating_sum = EXCLUDED.rating_sum,
+            weighted_sum = EXCLUDED.weighted_sum,
+            weight_total = EXCLUDED.weight_total,
+            score = EXCLUDED.score,
+            as_of = EXCLUDED.as_of,
+            updated_at = EXCLUDED.updated_at`,
+		a.UserID, a.CategoryID, a.SubcategoryID, a.Count, a.RatingSum, a.WeightedSum, a.WeightTotal, a.Score, a.AsOf)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении рейтинга пользователя %d: %w", userID, err)
+	}
+	return nil
+}
+
+func scanReview(row pgx.Row) (domain.Review, error) {
+	var review domain.Review
+	err := row.Scan(&review.ID, &review.ExecutorID, &review.Rating, &review.CategoryID, &review.SubcategoryID, &review.CreatedAt)
+	return review, err
+}
+
+func scanAggregate(row pgx.Row) (domain.Aggregate, error) {
+	var a domain.Aggregate
+	err := row.Scan(&a.UserID, &a.CategoryID, &a.SubcategoryID, &a.Count, &a.RatingSum, &a.WeightedSum, &a.WeightTotal, &a.Score, &a.AsOf)
+	return a, err
+}
@@ -1 +1,257 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/reviews/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// defaultListLimit — размер страницы списка отзывов, если limit не передан.
+const defaultListLimit = 20
+
+// ReviewsHandler отвечает за обработку HTTP-запросов к отзывам.
+type ReviewsHandler struct {
+	service domain.ReviewsService
+}
+
+// NewReviewsHandler создаёт новый экземпляр ReviewsHandler.
+func NewReviewsHandler(service domain.ReviewsService) *ReviewsHandler {
+	return &ReviewsHandler{service: service}
+}
+
+// CreateReview сохраняет отзыв о второй стороне завершённого контракта.
+func (h *ReviewsHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.CreateReview(r.Context(), sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, review)
+}
+
+// UpdateReview меняет оценку и текст отзыва, пока он не опубликован.
+func (h *ReviewsHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	sess, reviewID, ok := reviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.UpdateReview(r.Context(), sess.UserID, reviewID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, review)
+}
+
+// Reply сохраняет ответ пользователя на опубликованный отзыв о нём.
+func (h *ReviewsHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	sess, reviewID, ok := reviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ReplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.Reply(r.Context(), sess.UserID, reviewID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, review)
+}
+
+// ReportReview принимает жалобу на опубликованный отзыв.
+func (h *ReviewsHandler) ReportReview(w http.ResponseWriter, r *http.Request) {
+	sess, reviewID, ok := reviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.ReportReview(r.Context(), sess.UserID, reviewID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, report)
+}
+
+// GetReviewsByUser возвращает опубликованные отзывы о пользователе с курсорной пагинацией.
+func (h *ReviewsHandler) GetReviewsByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор пользователя: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), defaultListLimit)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := h.service.GetReviewsByUser(r.Context(), userID, page)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить отзывы: %w", err), reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, reviews)
+}
+
+// GetUserReviewsStats возвращает число и среднюю оценку опубликованных отзывов о пользователе.
+func (h *ReviewsHandler) GetUserReviewsStats(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор пользователя: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.service.GetUserStats(r.Context(), userID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить статистику отзывов: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Ответ без обёртки utils.NewResponse: формат статистики остаётся прежним для существующих клиентов.
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при кодировании ответа в JSON: %w", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetContractReviews возвращает отзывы по контракту, видимые текущему пользователю.
+func (h *ReviewsHandler) GetContractReviews(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := h.service.GetContractReviews(r.Context(), sess.UserID, contractID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, reviews)
+}
+
+// ListModerationQueue возвращает модератору отзывы с открытыми жалобами.
+func (h *ReviewsHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	items, err := h.service.ListModerationQueue(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, items)
+}
+
+// Moderate принимает решение модератора по жалобам на отзыв.
+func (h *ReviewsHandler) Moderate(w http.ResponseWriter, r *http.Request) {
+	sess, reviewID, ok := reviewRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.service.Moderate(r.Context(), sess.UserID, reviewID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, reviewErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, review)
+}
+
+// reviewRequest извлекает сессию и ID отзыва из пути. При ошибке ответ уже записан.
+func reviewRequest(w http.ResponseWriter, r *http.Request) (*session.Session, int64, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	reviewID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор отзыва: %w", err), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return sess, reviewID, true
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrReviewNotFound), errors.Is(err, domain.ErrContractNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotParty), errors.Is(err, domain.ErrNotAuthor),
+		errors.Is(err, domain.ErrNotReviewed), errors.Is(err, domain.ErrOwnReview), errors.Is(err, domain.ErrNotModerator):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidReview), errors.Is(err, domain.ErrInvalidReport),
+		errors.Is(err, domain.ErrInvalidModeration), errors.Is(err, pagination.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrContractActive), errors.Is(err, domain.ErrAlreadyReviewed),
+		errors.Is(err, domain.ErrEditWindowClosed), errors.Is(err, domain.ErrReviewNotPublished),
+		errors.Is(err, domain.ErrReplyExists), errors.Is(err, domain.ErrAlreadyReported):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
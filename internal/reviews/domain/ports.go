@@ -1 +1,68 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// ReviewsService — интерфейс для бизнес-логики отзывов.
+type ReviewsService interface {
+	// CreateReview сохраняет отзыв автора о второй стороне контракта. Отзыв публикуется,
+	// когда его оставят обе стороны или истечёт срок ожидания второго.
+	CreateReview(ctx context.Context, authorID int64, req ReviewRequest) (*Review, error)
+	UpdateReview(ctx context.Context, authorID, reviewID int64, req ReviewRequest) (*Review, error)
+	Reply(ctx context.Context, userID, reviewID int64, req ReplyRequest) (*Review, error)
+
+	GetReviewsByUser(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Review], error) // Только опубликованные
+	GetUserStats(ctx context.Context, userID int64) (Stats, error)
+	// GetContractReviews возвращает опубликованные отзывы по контракту и неопубликованный отзыв зрителя.
+	GetContractReviews(ctx context.Context, viewerID, contractID int64) ([]Review, error)
+
+	ReportReview(ctx context.Context, reporterID, reviewID int64, req ReportRequest) (*Report, error)
+	ListModerationQueue(ctx context.Context, moderatorID int64) ([]ModerationItem, error)
+	Moderate(ctx context.Context, moderatorID, reviewID int64, req ModerationRequest) (*Review, error)
+
+	// PublishDue публикует отзывы, вторая сторона которых не ответила к сроку.
+	// Возвращает число опубликованных отзывов.
+	PublishDue(ctx context.Context, now time.Time) (int, error)
+	RunRevealer(ctx context.Context, interval time.Duration)
+}
+
+// ReviewsRepository — интерфейс для хранения отзывов и жалоб.
+type ReviewsRepository interface {
+	GetContract(ctx context.Context, contractID int64) (*Contract, error) // ErrContractNotFound
+	IsModerator(ctx context.Context, userID int64) (bool, error)
+
+	// InsertReview сохраняет неопубликованный отзыв. Если автор уже оставлял отзыв
+	// по контракту, в том числе снятый модератором, возвращает ErrAlreadyReviewed.
+	InsertReview(ctx context.Context, r Review) (*Review, error)
+	GetReview(ctx context.Context, reviewID int64) (*Review, error) // ErrReviewNotFound
+	// UpdateReview меняет оценку и текст, если отзыв не опубликован и создан не раньше editableSince.
+	// Иначе возвращает ErrEditWindowClosed.
+	UpdateReview(ctx context.Context, reviewID int64, rating int, comment string, editableSince, at time.Time) (*Review, error)
+	SetReply(ctx context.Context, reviewID int64, reply Reply) (*Review, error) // ErrReplyExists
+	// PublishContract публикует отзывы по контракту, если их оставили обе стороны.
+	// Возвращает только что опубликованные отзывы.
+	PublishContract(ctx context.Context, contractID int64, at time.Time) ([]Review, error)
+	// PublishCreatedBefore публикует отзывы, оставленные раньше createdBefore и всё ещё скрытые.
+	PublishCreatedBefore(ctx context.Context, createdBefore, at time.Time, limit int) ([]Review, error)
+
+	ListPublishedByUser(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Review], error)
+	GetStats(ctx context.Context, userID int64) (Stats, error)
+	ListByContract(ctx context.Context, contractID int64) ([]Review, error)
+
+	InsertReport(ctx context.Context, report Report) (*Report, error) // ErrAlreadyReported
+	// ListModerationQueue возвращает отзывы с открытыми жалобами: больше жалоб — раньше,
+	// при равенстве — по времени первой жалобы.
+	ListModerationQueue(ctx context.Context, limit int) ([]ModerationItem, error)
+	// Moderate закрывает открытые жалобы на отзыв и, если remove, снимает отзыв с публикации.
+	Moderate(ctx context.Context, reviewID, moderatorID int64, remove bool, reason string, at time.Time) (*Review, error)
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
+}
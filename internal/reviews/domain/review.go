@@ -1 +1,281 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Side — сторона контракта, которая оставила отзыв.
+type Side string
+
+const (
+	SideCustomer Side = "customer" // Заказчик об исполнителе
+	SideExecutor Side = "executor" // Исполнитель о заказчике
+)
+
+// ReportReason — причина жалобы на отзыв.
+type ReportReason string
+
+const (
+	ReasonSpam         ReportReason = "spam"          // Реклама или бессмысленный текст
+	ReasonAbuse        ReportReason = "abuse"         // Оскорбления, угрозы
+	ReasonPersonalData ReportReason = "personal_data" // Телефоны, адреса и другие личные данные
+	ReasonOffTopic     ReportReason = "off_topic"     // Отзыв не о работе по контракту
+	ReasonOther        ReportReason = "other"
+)
+
+// IsValid сообщает, является ли причина известной.
+func (r ReportReason) IsValid() bool {
+	switch r {
+	case ReasonSpam, ReasonAbuse, ReasonPersonalData, ReasonOffTopic, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// ReportStatus — стадия рассмотрения жалобы.
+type ReportStatus string
+
+const (
+	ReportOpen     ReportStatus = "open"     // Ждёт модератора
+	ReportUpheld   ReportStatus = "upheld"   // Отзыв снят с публикации
+	ReportRejected ReportStatus = "rejected" // Отзыв оставлен
+)
+
+// ModerationAction — решение модератора по отзыву.
+type ModerationAction string
+
+const (
+	ActionRemove ModerationAction = "remove" // Снять отзыв с публикации
+	ActionKeep   ModerationAction = "keep"   // Оставить отзыв и отклонить жалобы
+)
+
+const (
+	// ModeratorUserType — тип пользователя, которому доступна модерация отзывов.
+	ModeratorUserType = "ADMIN"
+
+	maxCommentLength = 2000
+	maxReplyLength   = 2000
+	maxReportLength  = 1000
+)
+
+var (
+	ErrReviewNotFound     = errors.New("отзыв не найден")
+	ErrContractNotFound   = errors.New("контракт не найден")
+	ErrNotParty           = errors.New("отзыв может оставить только сторона контракта")
+	ErrContractActive     = errors.New("отзыв можно оставить только по завершённому контракту")
+	ErrAlreadyReviewed    = errors.New("вы уже оставили отзыв по этому контракту")
+	ErrInvalidReview      = errors.New("некорректный отзыв")
+	ErrNotAuthor          = errors.New("изменить отзыв может только его автор")
+	ErrEditWindowClosed   = errors.New("отзыв больше нельзя изменить")
+	ErrNotReviewed        = errors.New("ответить на отзыв может только пользователь, о котором он оставлен")
+	ErrReviewNotPublished = errors.New("отзыв ещё не опубликован")
+	ErrReplyExists        = errors.New("на отзыв уже дан ответ")
+	ErrInvalidReport      = errors.New("некорректная жалоба")
+	ErrAlreadyReported    = errors.New("вы уже пожаловались на этот отзыв")
+	ErrOwnReview          = errors.New("нельзя пожаловаться на собственный отзыв")
+	ErrNotModerator       = errors.New("действие доступно только модератору")
+	ErrInvalidModeration  = errors.New("некорректное решение модератора")
+	ErrInvalidSettings    = errors.New("некорректные настройки отзывов")
+)
+
+// Settings — сроки жизни отзыва.
+type Settings struct {
+	// EditWindow — сколько автор может править отзыв после отправки. Окно закрывается раньше,
+	// если отзыв опубликован: после того как стороны увидели отзывы друг друга, менять их нельзя.
+	EditWindow time.Duration
+	// RevealAfter — сколько ждать отзыва второй стороны. Пока обе стороны не оставили отзывы
+	// или срок не истёк, отзыв видит только автор.
+	RevealAfter time.Duration
+}
+
+// DefaultSettings — сроки по умолчанию: сутки на правку и две недели на ответный отзыв.
+var DefaultSettings = Settings{
+	EditWindow:  24 * time.Hour,
+	RevealAfter: 14 * 24 * time.Hour,
+}
+
+// Validate проверяет настройки.
+func (s Settings) Validate() error {
+	if s.EditWindow <= 0 {
+		return fmt.Errorf("%w: окно редактирования должно быть положительным, получено %s", ErrInvalidSettings, s.EditWindow)
+	}
+	if s.RevealAfter <= 0 {
+		return fmt.Errorf("%w: срок публикации должен быть положительным, получено %s", ErrInvalidSettings, s.RevealAfter)
+	}
+	return nil
+}
+
+// Review — отзыв одной стороны контракта о другой. UserID — пользователь, о котором оставлен отзыв.
+type Review struct {
+	ID            int64      `json:"id"`
+	ContractID    int64      `json:"contract_id"`
+	AuthorID      int64      `json:"author_id"`
+	UserID        int64      `json:"user_id"`
+	Side          Side       `json:"side"`
+	Rating        int        `json:"rating"`
+	Comment       string     `json:"comment"`
+	Reply         *Reply     `json:"reply"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	PublishedAt   *time.Time `json:"published_at"` // nil, пока отзыв виден только автору
+	RevealAt      *time.Time `json:"reveal_at,omitempty"`
+	EditableUntil *time.Time `json:"editable_until,omitempty"`
+	RemovedAt     *time.Time `json:"removed_at,omitempty"` // Снят модератором
+	RemovalReason *string    `json:"removal_reason,omitempty"`
+}
+
+// Reply — публичный ответ пользователя, о котором оставлен отзыв.
+type Reply struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsPublished сообщает, что отзыв виден всем.
+func (r Review) IsPublished() bool {
+	return r.PublishedAt != nil && r.RemovedAt == nil
+}
+
+// Editable сообщает, может ли автор ещё править отзыв в момент now.
+func (r Review) Editable(now time.Time, s Settings) bool {
+	return r.PublishedAt == nil && r.RemovedAt == nil && now.Before(r.CreatedAt.Add(s.EditWindow))
+}
+
+// WithDeadlines заполняет сроки, которые видит автор неопубликованного отзыва.
+func (r Review) WithDeadlines(now time.Time, s Settings) Review {
+	r.RevealAt, r.EditableUntil = nil, nil
+	if r.PublishedAt != nil || r.RemovedAt != nil {
+		return r
+	}
+	revealAt := r.CreatedAt.Add(s.RevealAfter)
+	r.RevealAt = &revealAt
+	if r.Editable(now, s) {
+		editableUntil := r.CreatedAt.Add(s.EditWindow)
+		r.EditableUntil = &editableUntil
+	}
+	return r
+}
+
+// Contract — сведения о контракте, нужные для отзыва.
+type Contract struct {
+	ID         int64
+	CustomerID int64
+	ExecutorID int64
+	IsActive   bool
+}
+
+// Counterpart возвращает сторону автора и пользователя, о котором он оставляет отзыв.
+func (c Contract) Counterpart(authorID int64) (Side, int64, error) {
+	switch authorID {
+	case c.CustomerID:
+		return SideCustomer, c.ExecutorID, nil
+	case c.ExecutorID:
+		return SideExecutor, c.CustomerID, nil
+	}
+	return "", 0, ErrNotParty
+}
+
+// ReviewRequest — оценка и текст отзыва при создании и правке.
+type ReviewRequest struct {
+	ContractID int64  `json:"contract_id"`
+	Rating     int    `json:"rating"`
+	Comment    string `json:"comment"`
+}
+
+// Validate проверяет оценку и нормализует текст.
+func (r *ReviewRequest) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return fmt.Errorf("%w: рейтинг должен быть от 1 до 5", ErrInvalidReview)
+	}
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.Comment == "" {
+		return fmt.Errorf("%w: комментарий не может быть пустым", ErrInvalidReview)
+	}
+	if len([]rune(r.Comment)) > maxCommentLength {
+		return fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidReview, maxCommentLength)
+	}
+	return nil
+}
+
+// ReplyRequest — ответ на отзыв.
+type ReplyRequest struct {
+	Text string `json:"text"`
+}
+
+// Validate проверяет и нормализует текст ответа.
+func (r *ReplyRequest) Validate() error {
+	r.Text = strings.TrimSpace(r.Text)
+	if r.Text == "" || len([]rune(r.Text)) > maxReplyLength {
+		return fmt.Errorf("%w: ответ должен содержать от 1 до %d символов", ErrInvalidReview, maxReplyLength)
+	}
+	return nil
+}
+
+// Report — жалоба пользователя на отзыв.
+type Report struct {
+	ID         int64        `json:"id"`
+	ReviewID   int64        `json:"review_id"`
+	ReporterID int64        `json:"reporter_id"`
+	Reason     ReportReason `json:"reason"`
+	Comment    string       `json:"comment"`
+	Status     ReportStatus `json:"status"`
+	ResolvedBy *int64       `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// ReportRequest — запрос на жалобу.
+type ReportRequest struct {
+	Reason  ReportReason `json:"reason"`
+	Comment string       `json:"comment"`
+}
+
+// Validate проверяет причину жалобы. Для причины other нужен комментарий.
+func (r *ReportRequest) Validate() error {
+	if !r.Reason.IsValid() {
+		return fmt.Errorf("%w: неизвестная причина %q", ErrInvalidReport, r.Reason)
+	}
+	r.Comment = strings.TrimSpace(r.Comment)
+	if r.Reason == ReasonOther && r.Comment == "" {
+		return fmt.Errorf("%w: опишите причину жалобы", ErrInvalidReport)
+	}
+	if len([]rune(r.Comment)) > maxReportLength {
+		return fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidReport, maxReportLength)
+	}
+	return nil
+}
+
+// ModerationItem — отзыв в очереди модерации с открытыми жалобами на него.
+type ModerationItem struct {
+	Review  Review   `json:"review"`
+	Reports []Report `json:"reports"`
+}
+
+// ModerationRequest — решение модератора. Для снятия отзыва нужна причина, которую увидит автор.
+type ModerationRequest struct {
+	Action ModerationAction `json:"action"`
+	Reason string           `json:"reason"`
+}
+
+// Validate проверяет решение модератора.
+func (r *ModerationRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	switch r.Action {
+	case ActionKeep:
+		return nil
+	case ActionRemove:
+		if r.Reason == "" {
+			return fmt.Errorf("%w: укажите причину снятия отзыва", ErrInvalidModeration)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: неизвестное действие %q", ErrInvalidModeration, r.Action)
+}
+
+// Stats — число опубликованных отзывов о пользователе и их средняя оценка.
+type Stats struct {
+	TotalReviews  int     `json:"total_reviews"`
+	AverageRating float64 `json:"average_rating"`
+}
@@ -1 +1,91 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContractCounterpart(t *testing.T) {
+	c := Contract{ID: 1, CustomerID: 10, ExecutorID: 20}
+
+	side, userID, err := c.Counterpart(10)
+	if err != nil || side != SideCustomer || userID != 20 {
+		t.Errorf("заказчик: получено %q, %d, %v", side, userID, err)
+	}
+	side, userID, err = c.Counterpart(20)
+	if err != nil || side != SideExecutor || userID != 10 {
+		t.Errorf("исполнитель: получено %q, %d, %v", side, userID, err)
+	}
+	if _, _, err := c.Counterpart(30); !errors.Is(err, ErrNotParty) {
+		t.Errorf("посторонний: ожидалась ErrNotParty, получено %v", err)
+	}
+}
+
+func TestReviewDeadlines(t *testing.T) {
+	s := Settings{EditWindow: time.Hour, RevealAfter: 24 * time.Hour}
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := Review{CreatedAt: created}
+
+	got := r.WithDeadlines(created.Add(30*time.Minute), s)
+	if !r.Editable(created.Add(30*time.Minute), s) || got.EditableUntil == nil || !got.EditableUntil.Equal(created.Add(time.Hour)) {
+		t.Errorf("в окне правки ожидался срок %v, получено %v", created.Add(time.Hour), got.EditableUntil)
+	}
+	if got.RevealAt == nil || !got.RevealAt.Equal(created.Add(24*time.Hour)) {
+		t.Errorf("ожидался срок публикации %v, получено %v", created.Add(24*time.Hour), got.RevealAt)
+	}
+
+	got = r.WithDeadlines(created.Add(2*time.Hour), s)
+	if r.Editable(created.Add(2*time.Hour), s) || got.EditableUntil != nil || got.RevealAt == nil {
+		t.Errorf("после окна правки: получено %+v", got)
+	}
+
+	// Опубликованный отзыв нельзя править даже в окне, сроков у него нет.
+	published := created.Add(10 * time.Minute)
+	r.PublishedAt = &published
+	got = r.WithDeadlines(created.Add(20*time.Minute), s)
+	if r.Editable(created.Add(20*time.Minute), s) || got.EditableUntil != nil || got.RevealAt != nil || !r.IsPublished() {
+		t.Errorf("опубликованный отзыв: получено %+v", got)
+	}
+
+	removed := created.Add(time.Hour)
+	r.RemovedAt = &removed
+	if r.IsPublished() {
+		t.Error("снятый отзыв не должен считаться опубликованным")
+	}
+}
+
+func TestRequestsValidate(t *testing.T) {
+	review := ReviewRequest{Rating: 5, Comment: "  Отлично  "}
+	if err := review.Validate(); err != nil || review.Comment != "Отлично" {
+		t.Errorf("корректный отзыв: получено %q, %v", review.Comment, err)
+	}
+	for _, bad := range []ReviewRequest{
+		{Rating: 0, Comment: "x"},
+		{Rating: 6, Comment: "x"},
+		{Rating: 3, Comment: "   "},
+		{Rating: 3, Comment: strings.Repeat("я", maxCommentLength+1)},
+	} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalidReview) {
+			t.Errorf("%+v: ожидалась ErrInvalidReview, получено %v", bad, err)
+		}
+	}
+
+	if err := (&ReportRequest{Reason: ReasonSpam}).Validate(); err != nil {
+		t.Errorf("жалоба на спам без комментария: %v", err)
+	}
+	if err := (&ReportRequest{Reason: ReasonOther}).Validate(); !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("причина other без комментария: ожидалась ErrInvalidReport, получено %v", err)
+	}
+	if err := (&ReportRequest{Reason: "fake"}).Validate(); !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("неизвестная причина: ожидалась ErrInvalidReport, получено %v", err)
+	}
+
+	if err := (&ModerationRequest{Action: ActionKeep}).Validate(); err != nil {
+		t.Errorf("keep без причины: %v", err)
+	}
+	if err := (&ModerationRequest{Action: ActionRemove, Reason: " "}).Validate(); !errors.Is(err, ErrInvalidModeration) {
+		t.Errorf("remove без причины: ожидалась ErrInvalidModeration, получено %v", err)
+	}
+}
@@ -1 +1,283 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/reviews"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+// moderationQueueLimit — сколько отзывов показывать в очереди модерации.
+const moderationQueueLimit = 50
+
+// publishBatchSize — сколько просроченных отзывов публиковать за один запрос к базе.
+const publishBatchSize = 100
+
+type reviewsService struct {
+	repo     ReviewsRepository
+	bus      EventBus
+	settings Settings
+}
+
+// NewReviewsService создаёт сервис отзывов.
+func NewReviewsService(repo ReviewsRepository, bus EventBus, settings Settings) ReviewsService {
+	return &reviewsService{repo: repo, bus: bus, settings: settings}
+}
+
+// CreateReview сохраняет скрытый отзыв и публикует оба отзыва по контракту, если второй уже оставлен.
+func (s *reviewsService) CreateReview(ctx context.Context, authorID int64, req ReviewRequest) (*Review, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	contract, err := s.repo.GetContract(ctx, req.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	side, userID, err := contract.Counterpart(authorID)
+	if err != nil {
+		return nil, err
+	}
+	if contract.IsActive {
+		return nil, ErrContractActive
+	}
+
+	now := time.Now()
+	review, err := s.repo.InsertReview(ctx, Review{
+		ContractID: contract.ID,
+		AuthorID:   authorID,
+		UserID:     userID,
+		Side:       side,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.repo.PublishContract(ctx, contract.ID, now)
+	if err != nil {
+		// Отзыв сохранён; если публикация не удалась, его опубликует планировщик по сроку.
+		slog.Error("[Reviews] Не удалось опубликовать отзывы по контракту", "contract_id", contract.ID, "error", err)
+	}
+	s.publishEvents(published)
+	for _, p := range published {
+		if p.ID == review.ID {
+			review = &p
+		}
+	}
+	if review.PublishedAt == nil {
+		s.bus.Publish(reviews.ReviewCreatedEvent{
+			ReviewID:   review.ID,
+			ContractID: review.ContractID,
+			AuthorID:   review.AuthorID,
+			UserID:     review.UserID,
+			RevealAt:   review.CreatedAt.Add(s.settings.RevealAfter),
+		})
+	}
+
+	result := review.WithDeadlines(now, s.settings)
+	return &result, nil
+}
+
+// UpdateReview меняет оценку и текст отзыва, пока он не опубликован и не истекло окно редактирования.
+func (s *reviewsService) UpdateReview(ctx context.Context, authorID, reviewID int64, req ReviewRequest) (*Review, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.AuthorID != authorID {
+		return nil, ErrNotAuthor
+	}
+	now := time.Now()
+	if !review.Editable(now, s.settings) {
+		return nil, ErrEditWindowClosed
+	}
+
+	updated, err := s.repo.UpdateReview(ctx, reviewID, req.Rating, req.Comment, now.Add(-s.settings.EditWindow), now)
+	if err != nil {
+		return nil, err
+	}
+	result := updated.WithDeadlines(now, s.settings)
+	return &result, nil
+}
+
+// Reply сохраняет публичный ответ пользователя, о котором оставлен опубликованный отзыв.
+func (s *reviewsService) Reply(ctx context.Context, userID, reviewID int64, req ReplyRequest) (*Review, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, ErrNotReviewed
+	}
+	if !review.IsPublished() {
+		return nil, ErrReviewNotPublished
+	}
+	if review.Reply != nil {
+		return nil, ErrReplyExists
+	}
+	return s.repo.SetReply(ctx, reviewID, Reply{Text: req.Text, CreatedAt: time.Now()})
+}
+
+// GetReviewsByUser возвращает опубликованные отзывы о пользователе, новые первыми.
+func (s *reviewsService) GetReviewsByUser(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[Review], error) {
+	return s.repo.ListPublishedByUser(ctx, userID, page)
+}
+
+// GetUserStats возвращает число и среднюю оценку опубликованных отзывов о пользователе.
+func (s *reviewsService) GetUserStats(ctx context.Context, userID int64) (Stats, error) {
+	return s.repo.GetStats(ctx, userID)
+}
+
+// GetContractReviews возвращает отзывы по контракту, которые может видеть зритель:
+// опубликованные и его собственный, ещё скрытый от второй стороны.
+func (s *reviewsService) GetContractReviews(ctx context.Context, viewerID, contractID int64) ([]Review, error) {
+	all, err := s.repo.ListByContract(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	visible := []Review{}
+	for _, r := range all {
+		if r.IsPublished() || r.AuthorID == viewerID {
+			visible = append(visible, r.WithDeadlines(now, s.settings))
+		}
+	}
+	return visible, nil
+}
+
+// ReportReview принимает жалобу на опубликованный отзыв. Жалоба попадает в очередь модерации.
+func (s *reviewsService) ReportReview(ctx context.Context, reporterID, reviewID int64, req ReportRequest) (*Report, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.IsPublished() {
+		return nil, ErrReviewNotPublished
+	}
+	if review.AuthorID == reporterID {
+		return nil, ErrOwnReview
+	}
+	return s.repo.InsertReport(ctx, Report{
+		ReviewID:   reviewID,
+		ReporterID: reporterID,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+		Status:     ReportOpen,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// ListModerationQueue возвращает отзывы с открытыми жалобами.
+func (s *reviewsService) ListModerationQueue(ctx context.Context, moderatorID int64) ([]ModerationItem, error) {
+	if err := s.requireModerator(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListModerationQueue(ctx, moderationQueueLimit)
+}
+
+// Moderate закрывает жалобы на отзыв решением модератора. Снятый отзыв перестаёт показываться
+// и учитываться в рейтинге.
+func (s *reviewsService) Moderate(ctx context.Context, moderatorID, reviewID int64, req ModerationRequest) (*Review, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.requireModerator(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	remove := req.Action == ActionRemove
+	if remove && review.RemovedAt != nil {
+		return review, nil
+	}
+
+	moderated, err := s.repo.Moderate(ctx, reviewID, moderatorID, remove, req.Reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if remove {
+		s.bus.Publish(reviews.ReviewRemovedEvent{
+			ReviewID:   moderated.ID,
+			ContractID: moderated.ContractID,
+			AuthorID:   moderated.AuthorID,
+			UserID:     moderated.UserID,
+			Reason:     req.Reason,
+		})
+	}
+	return moderated, nil
+}
+
+// PublishDue публикует скрытые отзывы, вторая сторона которых так и не оставила свой к сроку.
+func (s *reviewsService) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	total := 0
+	for {
+		published, err := s.repo.PublishCreatedBefore(ctx, now.Add(-s.settings.RevealAfter), now, publishBatchSize)
+		if err != nil {
+			return total, err
+		}
+		s.publishEvents(published)
+		total += len(published)
+		if len(published) < publishBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunRevealer периодически публикует отзывы с истёкшим сроком ожидания, пока не отменён ctx.
+func (s *reviewsService) RunRevealer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.PublishDue(ctx, now); err != nil {
+				slog.Error("[Reviews] Ошибка публикации отзывов по сроку", "error", err)
+			}
+		}
+	}
+}
+
+// publishEvents сообщает о публикации отзывов.
+func (s *reviewsService) publishEvents(published []Review) {
+	for _, r := range published {
+		s.bus.Publish(reviews.ReviewPublishedEvent{
+			ReviewID:   r.ID,
+			ContractID: r.ContractID,
+			AuthorID:   r.AuthorID,
+			UserID:     r.UserID,
+			Side:       string(r.Side),
+			Rating:     r.Rating,
+		})
+	}
+}
+
+func (s *reviewsService) requireModerator(ctx context.Context, userID int64) error {
+	ok, err := s.repo.IsModerator(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке прав модератора: %w", err)
+	}
+	if !ok {
+		return ErrNotModerator
+	}
+	return nil
+}
@@ -1 +1,33 @@
-package reviews
\ No newline at end of file
+package reviews
+
+import "time"
+
+// ReviewCreatedEvent — событие отправки отзыва. Отзыв пока скрыт: UserID, о котором он оставлен,
+// увидит его, когда оставит свой или наступит RevealAt.
+type ReviewCreatedEvent struct {
+	ReviewID   int64
+	ContractID int64
+	AuthorID   int64
+	UserID     int64
+	RevealAt   time.Time
+}
+
+// ReviewPublishedEvent — событие публикации отзыва: обе стороны оставили отзывы или истёк срок ожидания.
+// Side — сторона автора: customer или executor.
+type ReviewPublishedEvent struct {
+	ReviewID   int64
+	ContractID int64
+	AuthorID   int64
+	UserID     int64
+	Side       string
+	Rating     int
+}
+
+// ReviewRemovedEvent — событие снятия отзыва модератором.
+type ReviewRemovedEvent struct {
+	ReviewID   int64
+	ContractID int64
+	AuthorID   int64
+	UserID     int64
+	Reason     string
+}
@@ -1 +1,334 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/reviews/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+)
+
+// reviewsListSort — порядок списка отзывов о пользователе, для которого выдаются курсоры.
+const reviewsListSort = "newest"
+
+// reviewColumns — поля отзыва в порядке, который ожидает scanReview. Таблица reviews везде идёт под псевдонимом rv.
+const reviewColumns = `rv.id, rv.contract_id, COALESCE(rv.author_id, 0), rv.user_id, COALESCE(rv.side, ''), rv.rating, rv.comment,
+        rv.reply, rv.replied_at, rv.created_at, rv.updated_at, rv.published_at, rv.removed_at, rv.removal_reason`
+
+// reportColumns — поля жалобы в порядке, который ожидает scanReport.
+const reportColumns = `id, review_id, reporter_id, reason, comment, status, resolved_by, resolved_at, created_at`
+
+// publishedReview — условие, при котором отзыв виден всем.
+const publishedReview = `rv.published_at IS NOT NULL AND rv.removed_at IS NULL`
+
+// ReviewsRepository хранит отзывы и жалобы на них в PostgreSQL.
+type ReviewsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewReviewsRepository создаёт новый репозиторий отзывов.
+func NewReviewsRepository(db *pgxpool.Pool) *ReviewsRepository {
+	return &ReviewsRepository{db: db}
+}
+
+// GetContract возвращает стороны контракта и то, закрыт ли он.
+func (r *ReviewsRepository) GetContract(ctx context.Context, contractID int64) (*domain.Contract, error) {
+	var c domain.Contract
+	err := r.db.QueryRow(ctx, `SELECT id, customer_id, executor_id, is_active FROM contracts WHERE id = $1`, contractID).
+		Scan(&c.ID, &c.CustomerID, &c.ExecutorID, &c.IsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrContractNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении контракта с ID %d: %w", contractID, err)
+	}
+	return &c, nil
+}
+
+// IsModerator проверяет, может ли пользователь модерировать отзывы.
+func (r *ReviewsRepository) IsModerator(ctx context.Context, userID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND type = $2)`,
+		userID, domain.ModeratorUserType).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке прав пользователя %d: %w", userID, err)
+	}
+	return ok, nil
+}
+
+// InsertReview сохраняет отзыв, если автор ещё не оставлял отзыв по контракту.
+func (r *ReviewsRepository) InsertReview(ctx context.Context, review domain.Review) (*domain.Review, error) {
+	saved, err := scanReview(r.db.QueryRow(ctx, `
+        INSERT INTO reviews AS rv (contract_id, author_id, user_id, side, rating, comment, created_at, updated_at)
+        SELECT $1, $2, $3, $4, $5, $6, $7, $8
+        WHERE NOT EXISTS (SELECT 1 FROM reviews WHERE contract_id = $1 AND author_id = $2)
+        RETURNING `+reviewColumns,
+		review.ContractID, review.AuthorID, review.UserID, review.Side, review.Rating, review.Comment, review.CreatedAt, review.UpdatedAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || utils.IsUniqueViolation(err) {
+			return nil, domain.ErrAlreadyReviewed
+		}
+		return nil, fmt.Errorf("не удалось создать отзыв для контракта с ID %d и пользователя с ID %d: %w", review.ContractID, review.UserID, err)
+	}
+	return &saved, nil
+}
+
+// GetReview возвращает отзыв по ID.
+func (r *ReviewsRepository) GetReview(ctx context.Context, reviewID int64) (*domain.Review, error) {
+	review, err := scanReview(r.db.QueryRow(ctx, `SELECT `+reviewColumns+` FROM reviews rv WHERE rv.id = $1`, reviewID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении отзыва %d: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+// UpdateReview меняет оценку и текст скрытого отзыва, созданного не раньше editableSince.
+// Условие проверяется в том же запросе, поэтому отзыв, опубликованный параллельно, не изменится.
+func (r *ReviewsRepository) UpdateReview(ctx context.Context, reviewID int64, rating int, comment string, editableSince, at time.Time) (*domain.Review, error) {
+	review, err := scanReview(r.db.QueryRow(ctx, `
+        UPDATE reviews AS rv SET rating = $2, comment = $3, updated_at = $5
+        WHERE rv.id = $1 AND rv.published_at IS NULL AND rv.removed_at IS NULL AND rv.created_at >= $4
+        RETURNING `+reviewColumns, reviewID, rating, comment, editableSince, at))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEditWindowClosed
+		}
+		return nil, fmt.Errorf("ошибка при изменении отзыва %d: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+// SetReply сохраняет ответ на отзыв, если его ещё нет.
+func (r *ReviewsRepository) SetReply(ctx context.Context, reviewID int64, reply domain.Reply) (*domain.Review, error) {
+	review, err := scanReview(r.db.QueryRow(ctx, `
+        UPDATE reviews AS rv SET reply = $2, replied_at = $3
+        WHERE rv.id = $1 AND rv.reply IS NULL
+        RETURNING `+reviewColumns, reviewID, reply.Text, reply.CreatedAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReplyExists
+		}
+		return nil, fmt.Errorf("ошибка при сохранении ответа на отзыв %d: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+// PublishContract публикует скрытые отзывы по контракту, если отзывы оставили обе стороны.
+// Снятый модератором отзыв тоже считается оставленным.
+func (r *ReviewsRepository) PublishContract(ctx context.Context, contractID int64, at time.Time) ([]domain.Review, error) {
+	return r.listReviews(ctx, `
+        UPDATE reviews AS rv SET published_at = $2
+        WHERE rv.contract_id = $1 AND rv.published_at IS NULL AND rv.removed_at IS NULL
+          AND (SELECT COUNT(DISTINCT author_id) FROM reviews WHERE contract_id = $1) >= 2
+        RETURNING `+reviewColumns, contractID, at)
+}
+
+// PublishCreatedBefore публикует скрытые отзывы, оставленные раньше createdBefore, старые первыми.
+func (r *ReviewsRepository) PublishCreatedBefore(ctx context.Context, createdBefore, at time.Time, limit int) ([]domain.Review, error) {
+	return r.listReviews(ctx, `
+        UPDATE reviews AS rv SET published_at = $2
+        WHERE rv.id IN (
+            SELECT id FROM reviews
+            WHERE published_at IS NULL AND removed_at IS NULL AND created_at < $1
+            ORDER BY created_at
+            LIMIT $3
+            FOR UPDATE SKIP LOCKED
+        ) AND rv.published_at IS NULL
+        RETURNING `+reviewColumns, createdBefore, at, limit)
+}
+
+// ListPublishedByUser возвращает опубликованные отзывы о пользователе, новые первыми, с курсорной пагинацией.
+func (r *ReviewsRepository) ListPublishedByUser(ctx context.Context, userID int64, page pagination.Request) (pagination.Page[domain.Review], error) {
+	args := []interface{}{userID, page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil || page.After.Sort != reviewsListSort {
+			return pagination.Page[domain.Review]{}, pagination.ErrInvalidCursor
+		}
+		keyset = "AND (rv.created_at, rv.id) < ($3, $4)"
+		args = append(args, *page.After.Time, page.After.ID)
+	}
+
+	reviews, err := r.listReviews(ctx, `SELECT `+reviewColumns+` FROM reviews rv
+        WHERE rv.user_id = $1 AND `+publishedReview+` `+keyset+`
+        ORDER BY rv.created_at DESC, rv.id DESC
+        LIMIT $2`, args...)
+	if err != nil {
+		return pagination.Page[domain.Review]{}, err
+	}
+	return pagination.NewPage(reviews, page, func(rv domain.Review) pagination.Cursor {
+		return pagination.TimeCursor(reviewsListSort, rv.CreatedAt, rv.ID)
+	}), nil
+}
+
+// GetStats считает опубликованные отзывы о пользователе и их среднюю оценку.
+func (r *ReviewsRepository) GetStats(ctx context.Context, userID int64) (domain.Stats, error) {
+	var stats domain.Stats
+	err := r.db.QueryRow(ctx, `
+        SELECT COUNT(*), COALESCE(AVG(rv.rating), 0)::float8
+        FROM reviews rv
+        WHERE rv.user_id = $1 AND `+publishedReview, userID).Scan(&stats.TotalReviews, &stats.AverageRating)
+	if err != nil {
+		return domain.Stats{}, fmt.Errorf("ошибка при получении статистики отзывов пользователя %d: %w", userID, err)
+	}
+	return stats, nil
+}
+
+// ListByContract возвращает все отзывы по контракту, включая скрытые и снятые.
+func (r *ReviewsRepository) ListByContract(ctx context.Context, contractID int64) ([]domain.Review, error) {
+	return r.listReviews(ctx, `SELECT `+reviewColumns+` FROM reviews rv WHERE rv.contract_id = $1 ORDER BY rv.created_at, rv.id`, contractID)
+}
+
+// InsertReport сохраняет жалобу. Повторная жалоба того же пользователя не записывается.
+func (r *ReviewsRepository) InsertReport(ctx context.Context, report domain.Report) (*domain.Report, error) {
+	saved, err := scanReport(r.db.QueryRow(ctx, `
+        INSERT INTO review_reports (review_id, reporter_id, reason, comment, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (review_id, reporter_id) DO NOTHING
+        RETURNING `+reportColumns,
+		report.ReviewID, report.ReporterID, report.Reason, report.Comment, report.Status, report.CreatedAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAlreadyReported
+		}
+		return nil, fmt.Errorf("ошибка при сохранении жалобы на отзыв %d: %w", report.ReviewID, err)
+	}
+	return &saved, nil
+}
+
+// ListModerationQueue возвращает отзывы с открытыми жалобами и сами жалобы.
+func (r *ReviewsRepository) ListModerationQueue(ctx context.Context, limit int) ([]domain.ModerationItem, error) {
+	reviews, err := r.listReviews(ctx, `
+        SELECT `+reviewColumns+`
+        FROM reviews rv
+        JOIN (
+            SELECT review_id, COUNT(*) AS open_reports, MIN(created_at) AS first_reported_at
+            FROM review_reports
+            WHERE status = 'open'
+            GROUP BY review_id
+        ) q ON q.review_id = rv.id
+        ORDER BY q.open_reports DESC, q.first_reported_at, rv.id
+        LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return []domain.ModerationItem{}, nil
+	}
+
+	ids := make([]int64, len(reviews))
+	items := make([]domain.ModerationItem, len(reviews))
+	index := make(map[int64]int, len(reviews))
+	for i, review := range reviews {
+		ids[i] = review.ID
+		items[i] = domain.ModerationItem{Review: review, Reports: []domain.Report{}}
+		index[review.ID] = i
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT `+reportColumns+` FROM review_reports
+        WHERE status = 'open' AND review_id = ANY($1)
+        ORDER BY created_at, id`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении жалоб на отзывы: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании жалобы: %w", err)
+		}
+		i := index[report.ReviewID]
+		items[i].Reports = append(items[i].Reports, report)
+	}
+	return items, rows.Err()
+}
+
+// Moderate снимает отзыв с публикации, если remove, и закрывает открытые жалобы на него в одной транзакции.
+func (r *ReviewsRepository) Moderate(ctx context.Context, reviewID, moderatorID int64, remove bool, reason string, at time.Time) (*domain.Review, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status := domain.ReportRejected
+	if remove {
+		status = domain.ReportUpheld
+		if _, err := tx.Exec(ctx, `UPDATE reviews SET removed_at = $2, removal_reason = $3 WHERE id = $1 AND removed_at IS NULL`,
+			reviewID, at, reason); err != nil {
+			return nil, fmt.Errorf("ошибка при снятии отзыва %d: %w", reviewID, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+        UPDATE review_reports SET status = $2, resolved_by = $3, resolved_at = $4
+        WHERE review_id = $1 AND status = 'open'`, reviewID, status, moderatorID, at); err != nil {
+		return nil, fmt.Errorf("ошибка при закрытии жалоб на отзыв %d: %w", reviewID, err)
+	}
+
+	review, err := scanReview(tx.QueryRow(ctx, `SELECT `+reviewColumns+` FROM reviews rv WHERE rv.id = $1`, reviewID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении отзыва %d: %w", reviewID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось зафиксировать решение по отзыву %d: %w", reviewID, err)
+	}
+	return &review, nil
+}
+
+func (r *ReviewsRepository) listReviews(ctx context.Context, query string, args ...interface{}) ([]domain.Review, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении отзывов: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []domain.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании отзыва: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк результата: %w", err)
+	}
+	return reviews, nil
+}
+
+func scanReview(row pgx.Row) (domain.Review, error) {
+	var review domain.Review
+	var reply *string
+	var repliedAt *time.Time
+	err := row.Scan(&review.ID, &review.ContractID, &review.AuthorID, &review.UserID, &review.Side, &review.Rating, &review.Comment,
+		&reply, &repliedAt, &review.CreatedAt, &review.UpdatedAt, &review.PublishedAt, &review.RemovedAt, &review.RemovalReason)
+	if err == nil && reply != nil {
+		review.Reply = &domain.Reply{Text: *reply}
+		if repliedAt != nil {
+			review.Reply.CreatedAt = *repliedAt
+		}
+	}
+	return review, err
+}
+
+func scanReport(row pgx.Row) (domain.Report, error) {
+	var report domain.Report
+	err := row.Scan(&report.ID, &report.ReviewID, &report.ReporterID, &report.Reason, &report.Comment, &report.Status,
+		&report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt)
+	return report, err
+}
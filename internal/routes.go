@@ -10,8 +10,20 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 	_ "github.com/unclaim/chegonado.git/docs" // Импортируем документацию Swagger
 	"github.com/unclaim/chegonado.git/internal/auth/api"
+	bidsAPI "github.com/unclaim/chegonado.git/internal/bids/api"
 	chatAPI "github.com/unclaim/chegonado.git/internal/chat/api"
+	currenciesAPI "github.com/unclaim/chegonado.git/internal/currencies/api"
+	disputesAPI "github.com/unclaim/chegonado.git/internal/disputes/api"
+	documentsAPI "github.com/unclaim/chegonado.git/internal/documents/api"
 	filestorageAPI "github.com/unclaim/chegonado.git/internal/filestorage/api"
+	ledgerAPI "github.com/unclaim/chegonado.git/internal/ledger/api"
+	notificationsAPI "github.com/unclaim/chegonado.git/internal/notifications/api"
+	paymentsAPI "github.com/unclaim/chegonado.git/internal/payments/api"
+	ratingsAPI "github.com/unclaim/chegonado.git/internal/ratings/api"
+	reviewsAPI "github.com/unclaim/chegonado.git/internal/reviews/api"
+	searchAPI "github.com/unclaim/chegonado.git/internal/search/api"
+	shortenerAPI "github.com/unclaim/chegonado.git/internal/shortener/api"
+	subscriptionsAPI "github.com/unclaim/chegonado.git/internal/subscriptions/api"
 	tasksAPI "github.com/unclaim/chegonado.git/internal/tasks/api"
 	usersAPI "github.com/unclaim/chegonado.git/internal/users/api"
 	"github.com/unclaim/chegonado.git/pkg/index"
@@ -27,7 +39,7 @@ func init() {
 }
 
 // SetupRoutes настраивает все HTTP-маршруты приложения
-func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.TasksHandler, fs *filestorageAPI.FileStorageHandler, ch *chatAPI.ChatHandler, sessionsManager *session.SessionsDB, ctx context.Context) http.Handler {
+func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.TasksHandler, fs *filestorageAPI.FileStorageHandler, ch *chatAPI.ChatHandler, nh *notificationsAPI.NotificationsHandler, dh *disputesAPI.DisputesHandler, bh *bidsAPI.BidsHandler, ph *paymentsAPI.PaymentsHandler, lh *ledgerAPI.LedgerHandler, doch *documentsAPI.DocumentsHandler, sh *subscriptionsAPI.SubscriptionsHandler, cuh *currenciesAPI.CurrenciesHandler, rh *ratingsAPI.RatingsHandler, rvh *reviewsAPI.ReviewsHandler, seh *searchAPI.SearchHandler, shh *shortenerAPI.ShortenerHandler, sessionsManager *session.SessionsDB, ctx context.Context) http.Handler {
 	mux := http.NewServeMux()
 
 	// Обновление email адреса пользователя
@@ -202,7 +214,11 @@ func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.Tas
 	apiMux.HandleFunc("GET /tasks", th.GetTasks)
 
 	// Поиск заданий по фильтру
-	apiMux.HandleFunc("GET /tasks/search", th.SearchTasks)
+	apiMux.HandleFunc("GET /tasks/search", seh.SearchTasksList)
+	apiMux.HandleFunc("GET /search/tasks", seh.SearchTasks)
+
+	// Подсказки строки поиска по мере ввода
+	apiMux.HandleFunc("GET /search/suggest", seh.Suggest)
 
 	// Детали конкретного задания
 	apiMux.HandleFunc("GET /tasks/{id}", th.GetTaskHandler)
@@ -210,9 +226,29 @@ func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.Tas
 	// Удаление задания
 	apiMux.HandleFunc("DELETE /tasks/{id}", th.DeleteTaskByID)
 
+	// Изменение условий задания
+	apiMux.HandleFunc("PUT /tasks/{id}", th.UpdateTask)
+
+	// Предыдущие редакции условий задания
+	apiMux.HandleFunc("GET /tasks/{id}/revisions", th.GetTaskRevisions)
+
+	// История смены статусов задания
+	apiMux.HandleFunc("GET /tasks/{id}/status-history", th.GetTaskStatusHistory)
+
+	// Вложения задания: фото, документы, планы
+	apiMux.HandleFunc("GET /tasks/{id}/attachments", fs.ListTaskAttachments)
+	apiMux.HandleFunc("POST /tasks/{id}/attachments", fs.UploadTaskAttachment)
+	apiMux.HandleFunc("DELETE /tasks/{id}/attachments/{attachment_id}", fs.DeleteTaskAttachment)
+
 	// Получение списка категорий заданий
 	apiMux.HandleFunc("GET /tasks/categories", th.Categories)
 
+	// Сохранённые поиски заданий с оповещениями о новых заданиях
+	apiMux.HandleFunc("GET /saved-searches", th.ListSavedSearches)
+	apiMux.HandleFunc("POST /saved-searches", th.CreateSavedSearch)
+	apiMux.HandleFunc("PUT /saved-searches/{id}", th.UpdateSavedSearch)
+	apiMux.HandleFunc("DELETE /saved-searches/{id}", th.DeleteSavedSearch)
+
 	// Реакция на задание
 	apiMux.HandleFunc("GET /task/response", th.ResponseHandler)
 
@@ -237,6 +273,10 @@ func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.Tas
 	// Создание отклика на задание
 	apiMux.HandleFunc("POST /tasks/{id}/response", th.CreateResponse)
 
+	// Переговоры по отклику: история предложений и ход стороны
+	apiMux.HandleFunc("GET /responses/{id}/offers", th.GetResponseOffers)
+	apiMux.HandleFunc("POST /responses/{id}/offers", th.MakeOffer)
+
 	// Создание нового задания
 	apiMux.HandleFunc("POST /tasks/new", th.CreateTask)
 
@@ -249,15 +289,87 @@ func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.Tas
 	// Возвращает количество просмотров задания
 	apiMux.HandleFunc("GET /tasks/{task_id}/views/count", th.GetTaskViewsCountHandler)
 
+	// Торги на понижение цены по задаче
+	apiMux.HandleFunc("POST /tasks/{id}/auction", bh.CreateAuction)
+	apiMux.HandleFunc("GET /tasks/{id}/auction", bh.GetTaskAuction)
+	apiMux.HandleFunc("GET /auctions/{id}", bh.GetAuction)
+	apiMux.HandleFunc("GET /auctions/{id}/bids", bh.ListBids)
+	apiMux.HandleFunc("POST /auctions/{id}/bids", bh.PlaceBid)
+
 	// Создание контракта
 	apiMux.HandleFunc("POST /contract", th.CreateContract)
 
+	// Этапы контракта: у каждого своя сумма, срок и отчёт
+	apiMux.HandleFunc("GET /contracts/{id}/milestones", th.GetContractMilestones)
+	apiMux.HandleFunc("PUT /contracts/{id}/milestones", th.ReplaceMilestones)
+
+	// Эскроу по контракту: состояние платежа и повторная блокировка средств после отказа шлюза
+	apiMux.HandleFunc("GET /contracts/{id}/payment", ph.GetContractPayment)
+	apiMux.HandleFunc("POST /contracts/{id}/payment/hold", ph.RetryHold)
+
+	// Закрывающие документы по контракту: счёт и квитанция в PDF
+	apiMux.HandleFunc("GET /contracts/{id}/documents", doch.GetContractDocuments)
+
+	// Уведомления платёжного шлюза и их журнал для повторной обработки
+	apiMux.HandleFunc("POST /payments/webhook", ph.Webhook)
+	apiMux.HandleFunc("GET /admin/payments/webhooks", ph.ListWebhookEvents)
+	apiMux.HandleFunc("POST /admin/payments/webhooks/{id}/replay", ph.ReplayWebhookEvent)
+
+	// Кошелёк пользователя: баланс и выписка с остатком после каждой операции
+	apiMux.HandleFunc("GET /wallet", lh.GetWallet)
+	apiMux.HandleFunc("GET /wallet/statement", lh.GetStatement)
+
+	// Вывод средств: способы вывода и заявки исполнителя
+	apiMux.HandleFunc("GET /wallet/payout-methods", ph.ListPayoutMethods)
+	apiMux.HandleFunc("POST /wallet/payout-methods", ph.AddPayoutMethod)
+	apiMux.HandleFunc("DELETE /wallet/payout-methods/{id}", ph.DeletePayoutMethod)
+	apiMux.HandleFunc("GET /wallet/payouts", ph.ListPayouts)
+	apiMux.HandleFunc("POST /wallet/payouts", ph.RequestPayout)
+
+	// Подписка Pro: тарифы, оформление, смена тарифа, отмена в конце периода и история списаний
+	apiMux.HandleFunc("GET /subscriptions/plans", sh.ListPlans)
+	apiMux.HandleFunc("GET /subscription", sh.GetSubscription)
+	apiMux.HandleFunc("POST /subscription", sh.Subscribe)
+	apiMux.HandleFunc("PUT /subscription/plan", sh.ChangePlan)
+	apiMux.HandleFunc("POST /subscription/cancel", sh.Cancel)
+	apiMux.HandleFunc("POST /subscription/resume", sh.Resume)
+	apiMux.HandleFunc("GET /subscription/charges", sh.ListCharges)
+
+	// Курсы валют к базовой: просмотр и изменение администратором
+	apiMux.HandleFunc("GET /currencies/rates", cuh.ListRates)
+	apiMux.HandleFunc("PUT /admin/currencies/rates/{currency}", cuh.SetRate)
+
+	// Сверка главной книги: балансы всех счетов
+	apiMux.HandleFunc("GET /admin/ledger/balances", lh.GetTrialBalance)
+
+	// Очередь заявок на вывод: одобрение и отклонение администратором
+	apiMux.HandleFunc("GET /admin/payouts", ph.ListPayoutQueue)
+	apiMux.HandleFunc("POST /admin/payouts/{id}/approve", ph.ApprovePayout)
+	apiMux.HandleFunc("POST /admin/payouts/{id}/reject", ph.RejectPayout)
+
+	// Споры по контракту: открытие стороной, доказательства и лента статусов
+	apiMux.HandleFunc("POST /contracts/{id}/disputes", dh.OpenDispute)
+	apiMux.HandleFunc("GET /disputes/{id}", dh.GetDispute)
+	apiMux.HandleFunc("POST /disputes/{id}/evidence", dh.AddEvidence)
+	apiMux.HandleFunc("GET /disputes/{id}/feed", dh.GetFeed)
+
+	// Арбитраж споров: очередь, материалы дела, назначение и решение
+	apiMux.HandleFunc("GET /admin/disputes", dh.ListForArbitration)
+	apiMux.HandleFunc("GET /admin/disputes/{id}/case", dh.GetCase)
+	apiMux.HandleFunc("POST /admin/disputes/{id}/assign", dh.AssignDispute)
+	apiMux.HandleFunc("POST /admin/disputes/{id}/resolve", dh.ResolveDispute)
+
 	// Обновляет отчет по заданию
 	apiMux.HandleFunc("PUT /update_report", th.UpdateReport)
 
 	// Создание отчета по заданию
 	apiMux.HandleFunc("POST /reports/create", th.CreateReport)
 
+	// Вложения отчета: подтверждения выполнения от исполнителя
+	apiMux.HandleFunc("GET /reports/{id}/attachments", fs.ListReportAttachments)
+	apiMux.HandleFunc("POST /reports/{id}/attachments", fs.UploadReportAttachment)
+	apiMux.HandleFunc("DELETE /reports/{id}/attachments/{attachment_id}", fs.DeleteReportAttachment)
+
 	// Проверяет наличие отчета по контракту
 	apiMux.HandleFunc("GET /reports/check/{contract_id}", th.GetContractReportExists)
 
@@ -279,18 +391,51 @@ func SetupRoutes(ah *api.AuthHandler, uh *usersAPI.UserHandler, th *tasksAPI.Tas
 	// Удаляет отклик на задание
 	apiMux.HandleFunc("DELETE /response", th.DeleteResponseHandler)
 
-	// Создание отзыва
-	apiMux.HandleFunc("POST /reviews", th.CreateReview)
+	// Создание отзыва по завершённому контракту. Отзыв скрыт, пока вторая сторона не оставит свой или не истечёт срок
+	apiMux.HandleFunc("POST /reviews", rvh.CreateReview)
+
+	// Правка отзыва автором, пока отзыв не опубликован
+	apiMux.HandleFunc("PUT /reviews/{id}", rvh.UpdateReview)
+
+	// Ответ пользователя на опубликованный отзыв о нём
+	apiMux.HandleFunc("POST /reviews/{id}/reply", rvh.Reply)
+
+	// Жалоба на отзыв
+	apiMux.HandleFunc("POST /reviews/{id}/reports", rvh.ReportReview)
+
+	// Отзывы по контракту, видимые текущему пользователю
+	apiMux.HandleFunc("GET /contracts/{id}/reviews", rvh.GetContractReviews)
 
 	// Получает статистику отзывов пользователя
-	apiMux.HandleFunc("GET /users/{user_id}/reviews", th.GetUserReviewsStats)
+	apiMux.HandleFunc("GET /users/{user_id}/reviews", rvh.GetUserReviewsStats)
 
 	// Получает полный список отзывов пользователя
-	apiMux.HandleFunc("GET /users/{user_id}/reviews/list", th.GetReviewsByUser)
+	apiMux.HandleFunc("GET /users/{user_id}/reviews/list", rvh.GetReviewsByUser)
+
+	// Модерация отзывов: очередь жалоб и решение по отзыву
+	apiMux.HandleFunc("GET /admin/reviews/reports", rvh.ListModerationQueue)
+	apiMux.HandleFunc("POST /admin/reviews/{id}/moderation", rvh.Moderate)
+
+	// Рейтинг исполнителя: общий и по категориям с учётом давности отзывов
+	apiMux.HandleFunc("GET /users/{user_id}/rating", rh.GetExecutorRating)
+
+	// Уведомления текущего пользователя
+	apiMux.HandleFunc("GET /notifications", nh.ListNotifications)
+
+	// Отметка уведомления прочитанным
+	apiMux.HandleFunc("POST /notifications/{id}/read", nh.MarkRead)
+
+	// Короткие ссылки на задачи, профили и приглашения и статистика переходов для владельца
+	apiMux.HandleFunc("POST /links", shh.CreateLink)
+	apiMux.HandleFunc("GET /links", shh.ListLinks)
+	apiMux.HandleFunc("GET /links/{code}/stats", shh.GetLinkStats)
 
 	// Передача запросов в API-контроллеры
 	mux.Handle("/api/", http.StripPrefix("/api", apiMux)) // Используем apiMux
 
+	// Переход по короткой ссылке
+	mux.HandleFunc("GET /s/{code}", shh.Redirect)
+
 	// Публичная страница сайта
 	mux.HandleFunc("GET /public/", public.Public)
 
@@ -1 +1,99 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+// SearchHandler отвечает за обработку HTTP-запросов к поиску.
+type SearchHandler struct {
+	service domain.SearchService
+	suggest domain.SuggestService
+}
+
+// NewSearchHandler создаёт новый экземпляр SearchHandler.
+func NewSearchHandler(service domain.SearchService, suggest domain.SuggestService) *SearchHandler {
+	return &SearchHandler{service: service, suggest: suggest}
+}
+
+// SearchTasks ищет задачи по тем же параметрам, что и GET /tasks/search, и дополнительно
+// возвращает общее число найденных задач и фасеты.
+func (h *SearchHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	query, err := tasksDomain.ParseTaskSearchQuery(r.URL.Query())
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.SearchTasks(r.Context(), query)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при поиске задач: %w", err), searchErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, result)
+}
+
+// SearchTasksList — прежний ответ GET /tasks/search: только страница задач, без общего числа и фасетов.
+func (h *SearchHandler) SearchTasksList(w http.ResponseWriter, r *http.Request) {
+	query, err := tasksDomain.ParseTaskSearchQuery(r.URL.Query())
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.SearchTasks(r.Context(), query)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при поиске задач: %w", err), searchErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, &tasksDomain.SearchTasksRes{Tasks: result.Items, NextCursor: result.NextCursor})
+}
+
+// Suggest возвращает подсказки для строки поиска по мере ввода: категории, подкатегории, навыки,
+// частые запросы и названия открытых задач.
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			common_errors.NewAppError(w, r, fmt.Errorf("%w: limit должен быть числом", domain.ErrInvalidSuggestQuery), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	result, err := h.suggest.Suggest(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidSuggestQuery) {
+			status = http.StatusBadRequest
+		}
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении подсказок: %w", err), status)
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, result)
+}
+
+// searchErrorStatus сопоставляет ошибки поиска HTTP-статусам.
+func searchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tasksDomain.ErrInvalidSearchQuery), errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, money.ErrRateNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrEngineUnavailable):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
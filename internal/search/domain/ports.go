@@ -1 +1,59 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+// SearchService — интерфейс для поиска задач и синхронизации поискового индекса.
+type SearchService interface {
+	// SearchTasks ищет задачи по запросу и возвращает страницу результатов с фасетами.
+	SearchTasks(ctx context.Context, query tasksDomain.TaskSearchQuery) (TaskResult, error)
+
+	// Обработчики событий задач: документ задачи перечитывается из базы и записывается в индекс
+	// или удаляется из него, если задачи больше нет.
+	HandleTaskCreated(event any)
+	HandleTaskUpdated(event any)
+	HandleTaskStatusChanged(event any)
+	HandleTaskCancelled(event any)
+	HandleTaskDeleted(event any)
+}
+
+// SearchEngine — порт поискового движка, в котором хранится индекс задач.
+type SearchEngine interface {
+	// IndexTask добавляет документ в индекс или заменяет его.
+	IndexTask(ctx context.Context, doc TaskDocument) error
+	// DeleteTask удаляет документ из индекса. Отсутствие документа не считается ошибкой.
+	DeleteTask(ctx context.Context, taskID int64) error
+	// SearchTasks выполняет проверенный запрос; цены в запросе уже в базовой валюте.
+	SearchTasks(ctx context.Context, query tasksDomain.TaskSearchQuery) (TaskResult, error)
+}
+
+// TaskSource — интерфейс для чтения задач из основной базы при индексации.
+type TaskSource interface {
+	GetTaskDocument(ctx context.Context, taskID int64) (*TaskDocument, error) // ErrTaskNotFound
+}
+
+// SuggestService — подсказки поиска по мере ввода.
+type SuggestService interface {
+	// Suggest возвращает подсказки для введённого текста. Индекс строится при первом запросе,
+	// если фоновое обновление ещё не успело.
+	Suggest(ctx context.Context, text string, limit int) (SuggestResult, error)
+	// Refresh перестраивает индекс подсказок из базы.
+	Refresh(ctx context.Context) error
+	// RunRefresh перестраивает индекс с периодом interval до отмены ctx.
+	RunRefresh(ctx context.Context, interval time.Duration)
+}
+
+// SuggestionSource — интерфейс для чтения подсказок из основной базы.
+type SuggestionSource interface {
+	ListSuggestions(ctx context.Context) ([]Suggestion, error)
+}
+
+// QueryLog — журнал поисковых запросов: по нему считается популярность запросов в подсказках.
+type QueryLog interface {
+	// LogQuery учитывает ещё один запуск нормализованного запроса.
+	LogQuery(ctx context.Context, query string) error
+}
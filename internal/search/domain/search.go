@@ -1 +1,160 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+var (
+	// ErrTaskNotFound возвращается источником, если задачи уже нет в базе.
+	ErrTaskNotFound = errors.New("задача не найдена")
+	// ErrEngineUnavailable возвращается, если поисковый движок не ответил или ответил ошибкой.
+	ErrEngineUnavailable = errors.New("поисковый движок недоступен")
+)
+
+// TaskDocument — задача в том виде, в котором она хранится в поисковом индексе.
+// Цены хранятся в минимальных единицах; CostBase — в базовой валюте, по ней фильтруют и сортируют.
+type TaskDocument struct {
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	UserID          int64          `json:"user_id"`
+	CategoryID      *int           `json:"category_id,omitempty"`
+	SubcategoryID   *int           `json:"subcategory_id,omitempty"`
+	Cost            *int           `json:"cost,omitempty"`
+	CostCurrency    money.Currency `json:"cost_currency,omitempty"`
+	CostBase        *int           `json:"cost_base,omitempty"`
+	Addresses       []string       `json:"addresses"`
+	ServiceLocation string         `json:"service_location,omitempty"`
+	PeriodType      string         `json:"period_type"`
+	StatusCode      int            `json:"status_code"`
+	Revision        int            `json:"revision"`
+	CreatedAt       time.Time      `json:"created_at"`
+	StartDate       *time.Time     `json:"start_date,omitempty"`
+	EndDate         *time.Time     `json:"end_date,omitempty"`
+	Locations       []geo.Point    `json:"locations,omitempty"` // Только адреса с известными координатами
+}
+
+// NewTaskDocument собирает документ индекса из задачи.
+func NewTaskDocument(t tasksDomain.Task) TaskDocument {
+	doc := TaskDocument{
+		ID:              t.ID,
+		Title:           t.Title,
+		Description:     t.Description,
+		UserID:          t.UserID,
+		CategoryID:      t.CategoryID,
+		SubcategoryID:   t.SubcategoryID,
+		CostBase:        t.CostBase,
+		Addresses:       t.Addresses,
+		ServiceLocation: t.ServiceLocation,
+		PeriodType:      t.PeriodType,
+		StatusCode:      int(t.StatusCode),
+		Revision:        t.Revision,
+		CreatedAt:       t.CreatedAt,
+		StartDate:       t.StartDate,
+		EndDate:         t.EndDate,
+	}
+	if doc.Addresses == nil {
+		doc.Addresses = []string{}
+	}
+	if t.Cost != nil {
+		amount := t.Cost.Amount
+		doc.Cost, doc.CostCurrency = &amount, t.Cost.Currency
+	}
+	for _, loc := range t.Locations {
+		if loc.Point != nil {
+			doc.Locations = append(doc.Locations, *loc.Point)
+		}
+	}
+	return doc
+}
+
+// Task восстанавливает задачу из документа. Если задана точка поиска near, заполняется расстояние
+// до ближайшего из адресов задачи.
+func (d TaskDocument) Task(near *geo.Point) tasksDomain.Task {
+	t := tasksDomain.Task{
+		ID:              d.ID,
+		Title:           d.Title,
+		Description:     d.Description,
+		CreatedAt:       d.CreatedAt,
+		UserID:          d.UserID,
+		CategoryID:      d.CategoryID,
+		SubcategoryID:   d.SubcategoryID,
+		CostBase:        d.CostBase,
+		Addresses:       d.Addresses,
+		ServiceLocation: d.ServiceLocation,
+		PeriodType:      d.PeriodType,
+		StartDate:       d.StartDate,
+		EndDate:         d.EndDate,
+		StatusCode:      tasksDomain.TaskStatusCode(d.StatusCode),
+		Revision:        d.Revision,
+	}
+	if d.Cost != nil {
+		cost := money.New(*d.Cost, d.CostCurrency)
+		t.Cost = &cost
+	}
+	if near != nil {
+		for _, p := range d.Locations {
+			km := geo.DistanceKm(*near, p)
+			if t.DistanceKm == nil || km < *t.DistanceKm {
+				t.DistanceKm = &km
+			}
+		}
+	}
+	return t
+}
+
+// FacetBucket — значение фасета и число задач с ним.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CostRange — диапазон цены в минимальных единицах базовой валюты: From включительно, To не включительно.
+type CostRange struct {
+	From *int `json:"from,omitempty"`
+	To   *int `json:"to,omitempty"`
+}
+
+// CostBucket — диапазон цены и число задач в нём.
+type CostBucket struct {
+	CostRange
+	Count int64 `json:"count"`
+}
+
+// CostRanges — диапазоны фасета по цене: до 1 000 ₽, 1 000–5 000 ₽, 5 000–20 000 ₽, 20 000–100 000 ₽ и от 100 000 ₽.
+var CostRanges = []CostRange{
+	{To: rubles(1_000)},
+	{From: rubles(1_000), To: rubles(5_000)},
+	{From: rubles(5_000), To: rubles(20_000)},
+	{From: rubles(20_000), To: rubles(100_000)},
+	{From: rubles(100_000)},
+}
+
+// rubles переводит рубли в копейки — минимальные единицы базовой валюты.
+func rubles(units int) *int {
+	v := units * 100
+	return &v
+}
+
+// TaskFacets — распределение найденных задач по категориям, подкатегориям, месту оказания услуги и цене.
+// Фасет по категориям не учитывает фильтр по категориям, а фасет по подкатегориям — фильтр по подкатегориям:
+// клиент видит, сколько задач добавит выбор ещё одного значения.
+type TaskFacets struct {
+	Categories       []FacetBucket `json:"categories"`
+	Subcategories    []FacetBucket `json:"subcategories"`
+	ServiceLocations []FacetBucket `json:"service_locations"`
+	Cost             []CostBucket  `json:"cost"`
+}
+
+// TaskResult — страница найденных задач, их общее число и фасеты.
+type TaskResult struct {
+	Items      []tasksDomain.Task `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	Total      int64              `json:"total"`
+	Facets     TaskFacets         `json:"facets"`
+}
@@ -1 +1,39 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+func TestTaskDocumentRoundTrip(t *testing.T) {
+	cost := money.New(1500, money.Currency("USD"))
+	base := 135000
+	near := geo.Point{Lat: 55.75, Lon: 37.61}
+	far := geo.Point{Lat: 59.93, Lon: 30.31}
+	task := tasksDomain.Task{
+		ID:        5,
+		Title:     "Перевезти пианино",
+		Cost:      &cost,
+		CostBase:  &base,
+		Locations: []tasksDomain.TaskLocation{{Point: &far}, {}, {Point: &near}},
+	}
+
+	doc := NewTaskDocument(task)
+	if len(doc.Locations) != 2 {
+		t.Fatalf("в документ должны попасть только адреса с координатами, получено %d", len(doc.Locations))
+	}
+
+	got := doc.Task(&near)
+	if got.Cost == nil || *got.Cost != cost || got.CostBase == nil || *got.CostBase != base {
+		t.Errorf("стоимость не сохранилась: %+v, %v", got.Cost, got.CostBase)
+	}
+	if got.DistanceKm == nil || *got.DistanceKm > 0.001 {
+		t.Errorf("расстояние должно считаться до ближайшего адреса, получено %v", got.DistanceKm)
+	}
+	if doc.Task(nil).DistanceKm != nil {
+		t.Error("без точки поиска расстояние не заполняется")
+	}
+}
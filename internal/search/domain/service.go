@@ -1 +1,123 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+type searchService struct {
+	engine     SearchEngine
+	source     TaskSource
+	currencies ports.CurrencyConverter
+	queries    QueryLog
+}
+
+// NewSearchService создаёт сервис поиска задач поверх поискового движка.
+// Запросы, по которым что-то нашлось, записываются в queries для подсказок.
+func NewSearchService(engine SearchEngine, source TaskSource, currencies ports.CurrencyConverter, queries QueryLog) SearchService {
+	return &searchService{engine: engine, source: source, currencies: currencies, queries: queries}
+}
+
+// SearchTasks проверяет запрос, пересчитывает фильтр по цене в базовую валюту и передаёт запрос движку.
+// Текст запроса с первой страницы учитывается в популярности подсказок, если по нему что-то нашлось.
+func (s *searchService) SearchTasks(ctx context.Context, query tasksDomain.TaskSearchQuery) (TaskResult, error) {
+	if err := query.Validate(); err != nil {
+		return TaskResult{}, err
+	}
+	query, err := query.InBaseCurrency(ctx, s.currencies)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	result, err := s.engine.SearchTasks(ctx, query)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	if query.Page.After == nil && result.Total > 0 {
+		s.logQuery(ctx, query.Text)
+	}
+	return result, nil
+}
+
+// logQuery записывает запрос в журнал. Ошибка журнала не должна мешать поиску, поэтому только логируется.
+func (s *searchService) logQuery(ctx context.Context, text string) {
+	normalized := NormalizeQuery(text)
+	if normalized == "" || utf8.RuneCountInString(normalized) > MaxSuggestQueryLength {
+		return
+	}
+	if err := s.queries.LogQuery(ctx, normalized); err != nil {
+		slog.Error("[Search] Не удалось записать поисковый запрос", "error", err)
+	}
+}
+
+// HandleTaskCreated — обработчик создания задачи: добавляет её в индекс.
+func (s *searchService) HandleTaskCreated(event any) {
+	e, ok := event.(tasks.TaskCreatedEvent)
+	if !ok {
+		slog.Error("[Search] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.TaskID)
+}
+
+// HandleTaskUpdated — обработчик изменения условий задачи: обновляет её документ в индексе.
+func (s *searchService) HandleTaskUpdated(event any) {
+	e, ok := event.(tasks.TaskUpdatedEvent)
+	if !ok {
+		slog.Error("[Search] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.TaskID)
+}
+
+// HandleTaskStatusChanged — обработчик смены статуса задачи: по умолчанию ищутся только открытые задачи,
+// поэтому статус в индексе должен совпадать со статусом в базе.
+func (s *searchService) HandleTaskStatusChanged(event any) {
+	e, ok := event.(tasks.TaskStatusChangedEvent)
+	if !ok {
+		slog.Error("[Search] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.TaskID)
+}
+
+// HandleTaskCancelled — обработчик отмены задачи.
+func (s *searchService) HandleTaskCancelled(event any) {
+	e, ok := event.(tasks.TaskCancelledEvent)
+	if !ok {
+		slog.Error("[Search] Получено некорректное событие", "event", event)
+		return
+	}
+	s.sync(context.Background(), e.TaskID)
+}
+
+// HandleTaskDeleted — обработчик удаления задачи: убирает её из индекса.
+func (s *searchService) HandleTaskDeleted(event any) {
+	e, ok := event.(tasks.TaskDeletedEvent)
+	if !ok {
+		slog.Error("[Search] Получено некорректное событие", "event", event)
+		return
+	}
+	if err := s.engine.DeleteTask(context.Background(), e.TaskID); err != nil {
+		slog.Error("[Search] Не удалось удалить задачу из индекса", "task_id", e.TaskID, "error", err)
+	}
+}
+
+// sync приводит документ задачи в индексе к её текущему состоянию в базе.
+// События могут прийти не по порядку, поэтому документ всегда перечитывается, а не собирается из события.
+func (s *searchService) sync(ctx context.Context, taskID int64) {
+	doc, err := s.source.GetTaskDocument(ctx, taskID)
+	if errors.Is(err, ErrTaskNotFound) {
+		err = s.engine.DeleteTask(ctx, taskID)
+	} else if err == nil {
+		err = s.engine.IndexTask(ctx, *doc)
+	}
+	if err != nil {
+		slog.Error("[Search] Не удалось обновить задачу в индексе", "task_id", taskID, "error", err)
+	}
+}
@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidSuggestQuery возвращается при пустом или слишком длинном префиксе подсказок и некорректном лимите.
+var ErrInvalidSuggestQuery = errors.New("некорректный запрос подсказок")
+
+// SuggestionKind — источник подсказки.
+type SuggestionKind string
+
+const (
+	SuggestCategory    SuggestionKind = "category"    // Категория; популярность — число задач в ней
+	SuggestSubcategory SuggestionKind = "subcategory" // Подкатегория; популярность — число задач в ней
+	SuggestSkill       SuggestionKind = "skill"       // Навык; популярность — число исполнителей с ним
+	SuggestQuery       SuggestionKind = "query"       // Частый поисковый запрос; популярность — число запусков
+	SuggestTask        SuggestionKind = "task"        // Название открытой задачи; популярность — число откликов
+)
+
+// Suggestion — подсказка поиска.
+type Suggestion struct {
+	Kind       SuggestionKind `json:"kind"`
+	Text       string         `json:"text"`
+	ID         int64          `json:"id,omitempty"` // Категории, подкатегории, навыка или задачи; у запросов нет
+	Popularity int64          `json:"popularity"`
+}
+
+// SuggestResult — подсказки в порядке убывания уместности.
+type SuggestResult struct {
+	Items []Suggestion `json:"items"`
+}
+
+const (
+	// MaxSuggestQueryLength — максимальная длина префикса подсказок и сохраняемого запроса в символах.
+	MaxSuggestQueryLength = 100
+	// DefaultSuggestLimit — число подсказок, если клиент не указал limit.
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit — максимальное число подсказок в ответе.
+	MaxSuggestLimit = 20
+)
+
+// NormalizeQuery приводит текст к виду, в котором он хранится в индексе и журнале запросов:
+// нижний регистр, «ё» как «е», слова через один пробел, без знаков препинания по краям слов.
+func NormalizeQuery(text string) string {
+	return strings.Join(tokenize(text), " ")
+}
+
+// tokenize разбивает текст на нормализованные слова из букв и цифр.
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// allowedTypos — сколько опечаток допускается в слове запроса: в коротких словах опечатка
+// слишком меняет смысл, поэтому до 4 символов — ни одной, до 8 — одна, дальше — две.
+func allowedTypos(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// SuggestIndex — префиксный индекс подсказок в памяти. Каждое слово подсказки — ключ в
+// отсортированном списке, поэтому подсказки с точным префиксом находятся двоичным поиском,
+// а с опечатками — перебором ключей. Индекс неизменяем: при обновлении строится новый.
+type SuggestIndex struct {
+	entries []indexEntry
+	keys    []indexKey // Отсортированы по token
+}
+
+type indexEntry struct {
+	suggestion Suggestion
+	tokens     []string
+}
+
+type indexKey struct {
+	token string
+	entry int
+}
+
+// NewSuggestIndex строит индекс. Подсказки одного вида с одинаковым текстом объединяются,
+// популярность берётся наибольшая; подсказки без слов пропускаются.
+func NewSuggestIndex(items []Suggestion) *SuggestIndex {
+	idx := &SuggestIndex{}
+	seen := make(map[string]int)
+	for _, s := range items {
+		tokens := tokenize(s.Text)
+		if len(tokens) == 0 {
+			continue
+		}
+		key := string(s.Kind) + "\x00" + strings.Join(tokens, " ")
+		if i, ok := seen[key]; ok {
+			if s.Popularity > idx.entries[i].suggestion.Popularity {
+				idx.entries[i].suggestion = s
+			}
+			continue
+		}
+		seen[key] = len(idx.entries)
+		idx.entries = append(idx.entries, indexEntry{suggestion: s, tokens: tokens})
+	}
+	for i, e := range idx.entries {
+		for _, t := range e.tokens {
+			idx.keys = append(idx.keys, indexKey{token: t, entry: i})
+		}
+	}
+	sort.Slice(idx.keys, func(a, b int) bool { return idx.keys[a].token < idx.keys[b].token })
+	return idx
+}
+
+// Len возвращает число подсказок в индексе.
+func (idx *SuggestIndex) Len() int {
+	return len(idx.entries)
+}
+
+// match — найденная подсказка и качество совпадения.
+type match struct {
+	entry   int
+	typos   int  // Суммарное число опечаток по словам запроса
+	leading bool // Первое слово запроса совпало с первым словом подсказки
+}
+
+// Search возвращает до limit подсказок для введённого текста. Каждое слово запроса должно быть
+// началом какого-нибудь слова подсказки с учётом допустимых опечаток. Выше идут подсказки без
+// опечаток, затем начинающиеся с запроса, затем более популярные, затем более короткие.
+func (idx *SuggestIndex) Search(text string, limit int) []Suggestion {
+	words := tokenize(text)
+	if len(words) == 0 || limit <= 0 {
+		return []Suggestion{}
+	}
+	// Кандидатов ищем по самому длинному слову: у него меньше всего совпадений.
+	anchor := words[0]
+	for _, w := range words[1:] {
+		if utf8.RuneCountInString(w) > utf8.RuneCountInString(anchor) {
+			anchor = w
+		}
+	}
+
+	candidates := make(map[int]bool)
+	start := sort.Search(len(idx.keys), func(i int) bool { return idx.keys[i].token >= anchor })
+	for i := start; i < len(idx.keys) && strings.HasPrefix(idx.keys[i].token, anchor); i++ {
+		candidates[idx.keys[i].entry] = true
+	}
+	// Опечатки ищем, только если точных совпадений не хватает на ответ.
+	if maxTypos := allowedTypos(anchor); len(candidates) < limit && maxTypos > 0 {
+		for _, k := range idx.keys {
+			if !candidates[k.entry] && prefixDistance(anchor, k.token, maxTypos) <= maxTypos {
+				candidates[k.entry] = true
+			}
+		}
+	}
+
+	matches := make([]match, 0, len(candidates))
+	for i := range candidates {
+		if m, ok := idx.matchEntry(i, words); ok {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		ma, mb := matches[a], matches[b]
+		if ma.typos != mb.typos {
+			return ma.typos < mb.typos
+		}
+		if ma.leading != mb.leading {
+			return ma.leading
+		}
+		sa, sb := idx.entries[ma.entry].suggestion, idx.entries[mb.entry].suggestion
+		if sa.Popularity != sb.Popularity {
+			return sa.Popularity > sb.Popularity
+		}
+		if len(sa.Text) != len(sb.Text) {
+			return len(sa.Text) < len(sb.Text)
+		}
+		if sa.Text != sb.Text {
+			return sa.Text < sb.Text
+		}
+		return ma.entry < mb.entry
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]Suggestion, len(matches))
+	for i, m := range matches {
+		result[i] = idx.entries[m.entry].suggestion
+	}
+	return result
+}
+
+// matchEntry проверяет, что каждое слово запроса — начало какого-нибудь слова подсказки,
+// и считает опечатки: для каждого слова берётся лучшее совпадение.
+func (idx *SuggestIndex) matchEntry(i int, words []string) (match, bool) {
+	e := idx.entries[i]
+	m := match{entry: i}
+	for wi, w := range words {
+		maxTypos := allowedTypos(w)
+		best := maxTypos + 1
+		bestPos := -1
+		for ti, t := range e.tokens {
+			d := prefixDistance(w, t, maxTypos)
+			if d < best {
+				best, bestPos = d, ti
+			}
+		}
+		if best > maxTypos {
+			return match{}, false
+		}
+		m.typos += best
+		if wi == 0 {
+			m.leading = bestPos == 0
+		}
+	}
+	return m, true
+}
+
+// prefixDistance — наименьшее расстояние Дамерау — Левенштейна (с перестановкой соседних символов)
+// между word и началом token. Если оно больше maxTypos, возвращается maxTypos+1.
+func prefixDistance(word, token string, maxTypos int) int {
+	if strings.HasPrefix(token, word) {
+		return 0
+	}
+	if maxTypos == 0 {
+		return 1
+	}
+	w, t := []rune(word), []rune(token)
+	if len(t) < len(w)-maxTypos {
+		return maxTypos + 1
+	}
+	// Строки — префиксы word, столбцы — префиксы token; ответ — минимум последней строки,
+	// потому что сравнивается word с любым началом token.
+	cols := min(len(t), len(w)+maxTypos) + 1
+	prevPrev := make([]int, cols)
+	prev := make([]int, cols)
+	cur := make([]int, cols)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(w); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if w[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && w[i-1] == t[j-2] && w[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > maxTypos {
+			return maxTypos + 1
+		}
+		prevPrev, prev, cur = prev, cur, prevPrev
+	}
+	best := maxTypos + 1
+	for _, d := range prev {
+		best = min(best, d)
+	}
+	return best
+}
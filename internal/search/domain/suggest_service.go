@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type suggestService struct {
+	source SuggestionSource
+
+	mu    sync.RWMutex
+	index *SuggestIndex // nil, пока индекс не построен
+
+	refreshMu sync.Mutex // Не даёт строить индекс из базы параллельно
+}
+
+// NewSuggestService создаёт сервис подсказок поверх источника из основной базы.
+func NewSuggestService(source SuggestionSource) SuggestService {
+	return &suggestService{source: source}
+}
+
+// Suggest проверяет запрос и ищет подсказки в текущем индексе.
+func (s *suggestService) Suggest(ctx context.Context, text string, limit int) (SuggestResult, error) {
+	if NormalizeQuery(text) == "" {
+		return SuggestResult{}, fmt.Errorf("%w: пустой запрос", ErrInvalidSuggestQuery)
+	}
+	if utf8.RuneCountInString(text) > MaxSuggestQueryLength {
+		return SuggestResult{}, fmt.Errorf("%w: запрос длиннее %d символов", ErrInvalidSuggestQuery, MaxSuggestQueryLength)
+	}
+	if limit == 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit < 0 || limit > MaxSuggestLimit {
+		return SuggestResult{}, fmt.Errorf("%w: limit должен быть от 1 до %d", ErrInvalidSuggestQuery, MaxSuggestLimit)
+	}
+
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+	if index == nil {
+		if err := s.Refresh(ctx); err != nil {
+			return SuggestResult{}, err
+		}
+		s.mu.RLock()
+		index = s.index
+		s.mu.RUnlock()
+	}
+	return SuggestResult{Items: index.Search(text, limit)}, nil
+}
+
+// Refresh строит новый индекс и подменяет им текущий; запросы во время построения идут в старый.
+func (s *suggestService) Refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	items, err := s.source.ListSuggestions(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка при загрузке подсказок: %w", err)
+	}
+	index := NewSuggestIndex(items)
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	slog.Debug("[Search] Индекс подсказок обновлён", "suggestions", index.Len())
+	return nil
+}
+
+// RunRefresh строит индекс сразу и затем перестраивает его каждые interval до отмены ctx.
+func (s *suggestService) RunRefresh(ctx context.Context, interval time.Duration) {
+	if err := s.Refresh(ctx); err != nil {
+		slog.Error("[Search] Не удалось построить индекс подсказок", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				slog.Error("[Search] Не удалось обновить индекс подсказок", "error", err)
+			}
+		}
+	}
+}
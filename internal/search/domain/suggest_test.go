@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+func testIndex() *SuggestIndex {
+	return NewSuggestIndex([]Suggestion{
+		{Kind: SuggestCategory, ID: 1, Text: "Ремонт и строительство", Popularity: 120},
+		{Kind: SuggestSubcategory, ID: 10, Text: "Сантехнические работы", Popularity: 40},
+		{Kind: SuggestSkill, ID: 3, Text: "Электрика", Popularity: 15},
+		{Kind: SuggestQuery, Text: "сантехник на час", Popularity: 25},
+		{Kind: SuggestQuery, Text: "срочный ремонт ванной", Popularity: 9},
+		{Kind: SuggestTask, ID: 77, Text: "Поменять смеситель, ремонт крана", Popularity: 2},
+		{Kind: SuggestQuery, Text: "Сантехник  на  час!", Popularity: 30},
+		{Kind: SuggestTask, ID: 78, Text: "Ёлка на праздник", Popularity: 1},
+	})
+}
+
+func texts(items []Suggestion) []string {
+	result := make([]string, len(items))
+	for i, s := range items {
+		result[i] = s.Text
+	}
+	return result
+}
+
+func TestSuggestIndexPrefix(t *testing.T) {
+	idx := testIndex()
+	if idx.Len() != 7 {
+		t.Fatalf("одинаковые запросы должны объединяться, в индексе %d подсказок", idx.Len())
+	}
+
+	got := idx.Search("Рем", 10)
+	// Подсказки, начинающиеся с запроса, идут выше подсказок, где совпало слово в середине.
+	if want := []string{"Ремонт и строительство", "срочный ремонт ванной", "Поменять смеситель, ремонт крана"}; !equalTexts(got, want) {
+		t.Errorf("поиск по префиксу: получено %q, ожидалось %q", texts(got), want)
+	}
+
+	got = idx.Search("сантех", 10)
+	if len(got) != 2 || got[0].Text != "Сантехнические работы" || got[1].Popularity != 30 {
+		t.Errorf("среди совпадений без опечаток выше более популярные: %q", texts(got))
+	}
+
+	if got := idx.Search("ремонт ван", 10); len(got) != 1 || got[0].Text != "срочный ремонт ванной" {
+		t.Errorf("каждое слово запроса должно совпасть с началом слова подсказки: %q", texts(got))
+	}
+	if got := idx.Search("елка", 10); len(got) != 1 || got[0].ID != 78 {
+		t.Errorf("ё и е должны совпадать: %q", texts(got))
+	}
+	if got := idx.Search("рем", 1); len(got) != 1 {
+		t.Errorf("лимит не соблюдён: %q", texts(got))
+	}
+	if got := idx.Search(" ,. ", 10); len(got) != 0 {
+		t.Errorf("запрос без слов ничего не находит: %q", texts(got))
+	}
+}
+
+func TestSuggestIndexTypos(t *testing.T) {
+	idx := testIndex()
+
+	cases := map[string]string{
+		"элекрика":   "Электрика",              // Пропущена буква
+		"сатнехник":  "Сантехник  на  час!",    // Переставлены соседние буквы
+		"строитльст": "Ремонт и строительство", // Незаконченное слово с опечаткой
+	}
+	for query, want := range cases {
+		got := idx.Search(query, 10)
+		if len(got) == 0 || got[0].Text != want {
+			t.Errorf("%q: ожидалась подсказка %q, получено %q", query, want, texts(got))
+		}
+	}
+
+	// В коротких словах опечатки не допускаются.
+	if got := idx.Search("рух", 10); len(got) != 0 {
+		t.Errorf("короткий запрос с опечаткой ничего не должен находить: %q", texts(got))
+	}
+	// Совпадения без опечаток выше совпадений с опечатками, даже менее популярные.
+	got := NewSuggestIndex([]Suggestion{
+		{Kind: SuggestQuery, Text: "плитка", Popularity: 1},
+		{Kind: SuggestQuery, Text: "плотник", Popularity: 100},
+	}).Search("плит", 10)
+	if len(got) != 2 || got[0].Text != "плитка" {
+		t.Errorf("точное совпадение должно быть первым: %q", texts(got))
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	if got := NormalizeQuery("  Ёлка,  на ПРАЗДНИК! "); got != "елка на праздник" {
+		t.Errorf("нормализация: получено %q", got)
+	}
+}
+
+type fakeSuggestionSource struct {
+	items []Suggestion
+	calls int
+}
+
+func (f *fakeSuggestionSource) ListSuggestions(ctx context.Context) ([]Suggestion, error) {
+	f.calls++
+	return f.items, nil
+}
+
+func TestSuggestServiceBuildsIndexLazily(t *testing.T) {
+	source := &fakeSuggestionSource{items: []Suggestion{{Kind: SuggestCategory, ID: 1, Text: "Ремонт"}}}
+	svc := NewSuggestService(source)
+
+	result, err := svc.Suggest(context.Background(), "рем", 0)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(result.Items) != 1 || source.calls != 1 {
+		t.Fatalf("индекс должен построиться при первом запросе: %+v, загрузок %d", result.Items, source.calls)
+	}
+	if _, err := svc.Suggest(context.Background(), "ремонт", 0); err != nil || source.calls != 1 {
+		t.Errorf("построенный индекс должен переиспользоваться: %v, загрузок %d", err, source.calls)
+	}
+
+	for _, tc := range []struct {
+		text  string
+		limit int
+	}{{"", 0}, {"рем", MaxSuggestLimit + 1}, {"рем", -1}} {
+		if _, err := svc.Suggest(context.Background(), tc.text, tc.limit); !errors.Is(err, ErrInvalidSuggestQuery) {
+			t.Errorf("%q, limit %d: ожидалась ErrInvalidSuggestQuery, получено %v", tc.text, tc.limit, err)
+		}
+	}
+}
+
+type fakeEngine struct {
+	total int64
+	last  tasksDomain.TaskSearchQuery
+}
+
+func (f *fakeEngine) IndexTask(ctx context.Context, doc TaskDocument) error { return nil }
+func (f *fakeEngine) DeleteTask(ctx context.Context, taskID int64) error    { return nil }
+func (f *fakeEngine) SearchTasks(ctx context.Context, q tasksDomain.TaskSearchQuery) (TaskResult, error) {
+	f.last = q
+	return TaskResult{Total: f.total}, nil
+}
+
+type fakeQueryLog struct {
+	queries []string
+}
+
+func (f *fakeQueryLog) LogQuery(ctx context.Context, query string) error {
+	f.queries = append(f.queries, query)
+	return nil
+}
+
+func TestSearchTasksLogsQueries(t *testing.T) {
+	engine := &fakeEngine{total: 3}
+	log := &fakeQueryLog{}
+	svc := NewSearchService(engine, nil, nil, log)
+
+	if _, err := svc.SearchTasks(context.Background(), tasksDomain.TaskSearchQuery{Text: "  Ремонт   Крана "}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	next := tasksDomain.TaskSearchQuery{Text: "ремонт крана", Sort: tasksDomain.SortRelevance,
+		Page: pagination.Request{After: &pagination.Cursor{Sort: string(tasksDomain.SortRelevance), ID: 1}}}
+	if _, err := svc.SearchTasks(context.Background(), next); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	engine.total = 0
+	if _, err := svc.SearchTasks(context.Background(), tasksDomain.TaskSearchQuery{Text: "абракадабра"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if len(log.queries) != 1 || log.queries[0] != "ремонт крана" {
+		t.Errorf("записываются только первые страницы запросов, по которым что-то нашлось: %q", log.queries)
+	}
+}
+
+func equalTexts(items []Suggestion, want []string) bool {
+	if len(items) != len(want) {
+		return false
+	}
+	for i, s := range items {
+		if s.Text != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1 +1,577 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/config"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+// defaultElasticsearchTimeout используется, если таймаут в конфигурации не задан.
+const defaultElasticsearchTimeout = 5 * time.Second
+
+// Размеры фасетов по значениям: сколько самых частых значений возвращать.
+const (
+	categoryFacetSize        = 50
+	subcategoryFacetSize     = 100
+	serviceLocationFacetSize = 20
+)
+
+// ElasticsearchEngine — адаптер поиска задач в Elasticsearch через REST API.
+// index — имя индекса или псевдонима, через который идут чтение и запись.
+type ElasticsearchEngine struct {
+	baseURL string
+	index   string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewElasticsearchEngine создаёт адаптер по настройкам external_services.elasticsearch.
+func NewElasticsearchEngine(cfg config.ExternalService, index string) (*ElasticsearchEngine, error) {
+	if cfg.URL == "" || index == "" {
+		return nil, fmt.Errorf("для Elasticsearch нужны url и имя индекса")
+	}
+	timeout := defaultElasticsearchTimeout
+	if cfg.Timeout != "" {
+		parsed, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный таймаут Elasticsearch %q: %w", cfg.Timeout, err)
+		}
+		timeout = parsed
+	}
+	return &ElasticsearchEngine{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		index:   index,
+		apiKey:  cfg.APIKey,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// IndexTask записывает документ задачи под её ID.
+func (e *ElasticsearchEngine) IndexTask(ctx context.Context, doc domain.TaskDocument) error {
+	_, err := e.do(ctx, http.MethodPut, e.docPath(doc.ID), doc, nil)
+	return err
+}
+
+// DeleteTask удаляет документ задачи. Ответ 404 означает, что документа уже нет.
+func (e *ElasticsearchEngine) DeleteTask(ctx context.Context, taskID int64) error {
+	status, err := e.do(ctx, http.MethodDelete, e.docPath(taskID), nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// SearchTasks ищет задачи с фильтрами, сортировкой и фасетами. Пагинация — через search_after
+// по ключу сортировки и id, как в курсорах остальных списков.
+func (e *ElasticsearchEngine) SearchTasks(ctx context.Context, q tasksDomain.TaskSearchQuery) (domain.TaskResult, error) {
+	body, err := buildTaskSearch(q)
+	if err != nil {
+		return domain.TaskResult{}, err
+	}
+
+	var resp searchResponse
+	if _, err := e.do(ctx, http.MethodPost, "/"+e.index+"/_search", body, &resp); err != nil {
+		return domain.TaskResult{}, err
+	}
+
+	items := make([]tasksDomain.Task, 0, len(resp.Hits.Hits))
+	cursors := make(map[int64]pagination.Cursor, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		task := hit.Source.Task(q.Near)
+		cursor, err := hitCursor(q.Sort, hit.Sort, task.ID)
+		if err != nil {
+			return domain.TaskResult{}, err
+		}
+		cursors[task.ID] = cursor
+		items = append(items, task)
+	}
+	page := pagination.NewPage(items, q.Page, func(t tasksDomain.Task) pagination.Cursor { return cursors[t.ID] })
+
+	return domain.TaskResult{
+		Items:      page.Items,
+		NextCursor: page.NextCursor,
+		Total:      resp.Hits.Total.Value,
+		Facets:     resp.facets(),
+	}, nil
+}
+
+func (e *ElasticsearchEngine) docPath(taskID int64) string {
+	return "/" + e.index + "/_doc/" + strconv.FormatInt(taskID, 10)
+}
+
+// CreateIndex создаёт индекс name с настройками и маппингом body.
+func (e *ElasticsearchEngine) CreateIndex(ctx context.Context, name string, body json.RawMessage) error {
+	_, err := e.do(ctx, http.MethodPut, "/"+name, body, nil)
+	return err
+}
+
+// DeleteIndex удаляет индекс name.
+func (e *ElasticsearchEngine) DeleteIndex(ctx context.Context, name string) error {
+	_, err := e.do(ctx, http.MethodDelete, "/"+name, nil, nil)
+	return err
+}
+
+// BulkIndex записывает документы в индекс index одним запросом _bulk.
+func (e *ElasticsearchEngine) BulkIndex(ctx context.Context, index string, docs []domain.TaskDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		action := map[string]any{"index": map[string]any{"_index": index, "_id": strconv.FormatInt(doc.ID, 10)}}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("не удалось сериализовать запрос к Elasticsearch: %w", err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("не удалось сериализовать документ задачи %d: %w", doc.ID, err)
+		}
+	}
+	return e.bulk(ctx, &buf, "не проиндексирована")
+}
+
+// BulkDelete удаляет документы задач из индекса index одним запросом _bulk.
+// Документы, которых в индексе уже нет, пропускаются.
+func (e *ElasticsearchEngine) BulkDelete(ctx context.Context, index string, taskIDs []int64) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, id := range taskIDs {
+		action := map[string]any{"delete": map[string]any{"_index": index, "_id": strconv.FormatInt(id, 10)}}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("не удалось сериализовать запрос к Elasticsearch: %w", err)
+		}
+	}
+	return e.bulk(ctx, &buf, "не удалена")
+}
+
+// bulk отправляет запрос _bulk и возвращает ошибку первого необработанного документа.
+func (e *ElasticsearchEngine) bulk(ctx context.Context, payload io.Reader, failure string) error {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string `json:"_id"`
+			Error *struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if _, err := e.doRaw(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", payload, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	// _bulk отвечает 200, даже если часть документов не обработана.
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Error != nil {
+				return fmt.Errorf("%w: задача %s %s: %s", domain.ErrEngineUnavailable, result.ID, failure, result.Error.Reason)
+			}
+		}
+	}
+	return fmt.Errorf("%w: часть задач %s", domain.ErrEngineUnavailable, failure)
+}
+
+// Refresh делает записанные в индекс index документы видимыми для поиска.
+func (e *ElasticsearchEngine) Refresh(ctx context.Context, index string) error {
+	_, err := e.do(ctx, http.MethodPost, "/"+index+"/_refresh", nil, nil)
+	return err
+}
+
+// ListTaskIDs возвращает до limit ID задач из индекса index, больших afterID, в порядке возрастания.
+func (e *ElasticsearchEngine) ListTaskIDs(ctx context.Context, index string, afterID int64, limit int) ([]int64, error) {
+	body := map[string]any{
+		"_source":          false,
+		"size":             limit,
+		"track_total_hits": false,
+		"sort":             []any{map[string]any{"id": "asc"}},
+		"search_after":     []any{afterID},
+	}
+	var resp struct {
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if _, err := e.do(ctx, http.MethodPost, "/"+index+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: некорректный ID документа %q", domain.ErrEngineUnavailable, hit.ID)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SwapAlias переключает псевдоним адаптера на индекс index одним атомарным запросом _aliases
+// и возвращает индексы, на которые псевдоним указывал раньше. Поиск не видит момента переключения.
+// Старые индексы не удаляются.
+func (e *ElasticsearchEngine) SwapAlias(ctx context.Context, index string) ([]string, error) {
+	var current map[string]json.RawMessage
+	status, err := e.do(ctx, http.MethodGet, "/_alias/"+e.index, nil, &current)
+	if err != nil && status != http.StatusNotFound {
+		return nil, err
+	}
+
+	var previous []string
+	actions := []any{map[string]any{"add": map[string]any{"index": index, "alias": e.index}}}
+	if status == http.StatusNotFound {
+		// Раньше индекс создавался прямо под именем псевдонима. Такой индекс удаляется в том же запросе,
+		// иначе псевдоним с его именем не создать.
+		if status, _ := e.do(ctx, http.MethodHead, "/"+e.index, nil, nil); status == http.StatusOK {
+			actions = append(actions, map[string]any{"remove_index": map[string]any{"index": e.index}})
+		}
+	}
+	for name := range current {
+		if name == index {
+			continue
+		}
+		previous = append(previous, name)
+		actions = append(actions, map[string]any{"remove": map[string]any{"index": name, "alias": e.index}})
+	}
+	if _, err := e.do(ctx, http.MethodPost, "/_aliases", map[string]any{"actions": actions}, nil); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// do отправляет JSON-запрос в Elasticsearch и возвращает статус ответа.
+func (e *ElasticsearchEngine) do(ctx context.Context, method, path string, body, out any) (int, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось сериализовать запрос к Elasticsearch: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+	return e.doRaw(ctx, method, path, "application/json", payload, out)
+}
+
+// doRaw отправляет запрос с готовым телом. Любой ответ, кроме 2xx, и сетевые ошибки
+// превращаются в ErrEngineUnavailable с причиной из тела ответа.
+func (e *ElasticsearchEngine) doRaw(ctx context.Context, method, path, contentType string, payload io.Reader, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, payload)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать запрос к Elasticsearch: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrEngineUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var esErr struct {
+			Error struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&esErr)
+		reason := esErr.Error.Reason
+		if reason == "" {
+			reason = http.StatusText(resp.StatusCode)
+		}
+		return resp.StatusCode, fmt.Errorf("%w: %s %s: статус %d: %s", domain.ErrEngineUnavailable, method, path, resp.StatusCode, reason)
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("%w: некорректный ответ: %v", domain.ErrEngineUnavailable, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// buildTaskSearch собирает тело запроса _search.
+//
+// Фильтры по категориям и подкатегориям вынесены в post_filter: они отсекают выдачу, но не фасеты,
+// а каждый фасет применяет их сам, кроме фильтра по собственному полю.
+func buildTaskSearch(q tasksDomain.TaskSearchQuery) (map[string]any, error) {
+	var filters []any
+	if q.CostMin != nil || q.CostMax != nil {
+		filters = append(filters, rangeFilter("cost_base", q.CostMin, q.CostMax))
+	}
+	if q.CreatedFrom != nil || q.CreatedTo != nil {
+		filters = append(filters, dateFilter("created_at", q.CreatedFrom, q.CreatedTo))
+	}
+	if q.StartFrom != nil || q.StartTo != nil {
+		filters = append(filters, dateFilter("start_date", q.StartFrom, q.StartTo))
+	}
+	if len(q.Statuses) > 0 {
+		statuses := make([]int, len(q.Statuses))
+		for i, s := range q.Statuses {
+			statuses[i] = int(s)
+		}
+		filters = append(filters, map[string]any{"terms": map[string]any{"status_code": statuses}})
+	}
+	if q.ServiceLocation != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"service_location": q.ServiceLocation}})
+	}
+	if q.Near != nil && q.RadiusKm != nil {
+		filters = append(filters, map[string]any{"geo_distance": map[string]any{
+			"distance":  strconv.FormatFloat(*q.RadiusKm, 'f', -1, 64) + "km",
+			"locations": map[string]any{"lat": q.Near.Lat, "lon": q.Near.Lon},
+		}})
+	}
+	if q.Sort == tasksDomain.SortDistance {
+		// Сортировка по расстоянию в Elasticsearch не умеет ставить документы без координат в конец,
+		// поэтому такие задачи в эту выдачу не попадают.
+		filters = append(filters, map[string]any{"exists": map[string]any{"field": "locations"}})
+	}
+
+	var categoryFilter, subcategoryFilter any
+	if len(q.CategoryIDs) > 0 {
+		categoryFilter = map[string]any{"terms": map[string]any{"category_id": q.CategoryIDs}}
+	}
+	if len(q.SubcategoryIDs) > 0 {
+		subcategoryFilter = map[string]any{"terms": map[string]any{"subcategory_id": q.SubcategoryIDs}}
+	}
+
+	must := []any{map[string]any{"match_all": map[string]any{}}}
+	if q.Text != "" {
+		must = []any{map[string]any{"multi_match": map[string]any{
+			"query":    q.Text,
+			"fields":   []string{"title^2", "description"},
+			"operator": "and",
+		}}}
+	}
+	if filters == nil {
+		filters = []any{}
+	}
+
+	sortSpec, err := taskSort(q)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{
+		"size":             q.Page.FetchLimit(),
+		"track_total_hits": true,
+		"query":            map[string]any{"bool": map[string]any{"must": must, "filter": filters}},
+		"sort":             sortSpec,
+		"aggs": map[string]any{
+			"categories":        facetAgg(termsAgg("category_id", categoryFacetSize), subcategoryFilter),
+			"subcategories":     facetAgg(termsAgg("subcategory_id", subcategoryFacetSize), categoryFilter),
+			"service_locations": facetAgg(termsAgg("service_location", serviceLocationFacetSize), categoryFilter, subcategoryFilter),
+			"cost":              facetAgg(costAgg(), categoryFilter, subcategoryFilter),
+		},
+	}
+	if post := boolFilter(categoryFilter, subcategoryFilter); post != nil {
+		body["post_filter"] = post
+	}
+
+	if after := q.Page.After; after != nil {
+		searchAfter, err := searchAfter(q.Sort, after)
+		if err != nil {
+			return nil, err
+		}
+		body["search_after"] = searchAfter
+	}
+	return body, nil
+}
+
+// taskSort возвращает порядок сортировки; последним ключом всегда идёт id.
+// Задачи без цены при сортировке по цене идут в конце.
+func taskSort(q tasksDomain.TaskSearchQuery) ([]any, error) {
+	switch q.Sort {
+	case tasksDomain.SortRelevance:
+		return []any{map[string]any{"_score": "desc"}, map[string]any{"id": "desc"}}, nil
+	case tasksDomain.SortNewest:
+		return []any{map[string]any{"created_at": "desc"}, map[string]any{"id": "desc"}}, nil
+	case tasksDomain.SortPriceAsc:
+		return []any{map[string]any{"cost_base": map[string]any{"order": "asc", "missing": "_last"}}, map[string]any{"id": "asc"}}, nil
+	case tasksDomain.SortPriceDesc:
+		return []any{map[string]any{"cost_base": map[string]any{"order": "desc", "missing": "_last"}}, map[string]any{"id": "desc"}}, nil
+	case tasksDomain.SortDistance:
+		return []any{map[string]any{"_geo_distance": map[string]any{
+			"locations": map[string]any{"lat": q.Near.Lat, "lon": q.Near.Lon},
+			"order":     "asc",
+			"unit":      "km",
+			"mode":      "min",
+		}}, map[string]any{"id": "asc"}}, nil
+	}
+	return nil, fmt.Errorf("%w: неизвестная сортировка %q", tasksDomain.ErrInvalidSearchQuery, q.Sort)
+}
+
+// searchAfter переводит курсор в значения search_after. Время передаётся в миллисекундах,
+// как Elasticsearch возвращает его в sort.
+func searchAfter(sort tasksDomain.TaskSortOrder, after *pagination.Cursor) ([]any, error) {
+	switch {
+	case (sort == tasksDomain.SortRelevance || sort == tasksDomain.SortDistance) && after.Float != nil:
+		return []any{*after.Float, after.ID}, nil
+	case (sort == tasksDomain.SortPriceAsc || sort == tasksDomain.SortPriceDesc) && after.Int != nil:
+		return []any{*after.Int, after.ID}, nil
+	case sort == tasksDomain.SortNewest && after.Time != nil:
+		return []any{after.Time.UnixMilli(), after.ID}, nil
+	}
+	return nil, pagination.ErrInvalidCursor
+}
+
+// hitCursor строит курсор по значениям sort найденного документа.
+func hitCursor(sort tasksDomain.TaskSortOrder, values []json.Number, id int64) (pagination.Cursor, error) {
+	if len(values) == 0 {
+		return pagination.Cursor{}, fmt.Errorf("%w: в ответе нет значений сортировки", domain.ErrEngineUnavailable)
+	}
+	key := values[0]
+	switch sort {
+	case tasksDomain.SortRelevance, tasksDomain.SortDistance:
+		v, err := key.Float64()
+		if err != nil {
+			return pagination.Cursor{}, fmt.Errorf("%w: некорректный ключ сортировки %q", domain.ErrEngineUnavailable, key)
+		}
+		return pagination.FloatCursor(string(sort), v, id), nil
+	case tasksDomain.SortPriceAsc, tasksDomain.SortPriceDesc:
+		v, err := key.Int64()
+		if err != nil {
+			return pagination.Cursor{}, fmt.Errorf("%w: некорректный ключ сортировки %q", domain.ErrEngineUnavailable, key)
+		}
+		return pagination.IntCursor(string(sort), v, id), nil
+	}
+	ms, err := key.Int64()
+	if err != nil {
+		return pagination.Cursor{}, fmt.Errorf("%w: некорректный ключ сортировки %q", domain.ErrEngineUnavailable, key)
+	}
+	return pagination.TimeCursor(string(sort), time.UnixMilli(ms).UTC(), id), nil
+}
+
+func rangeFilter(field string, from, to *int) map[string]any {
+	bounds := map[string]any{}
+	if from != nil {
+		bounds["gte"] = *from
+	}
+	if to != nil {
+		bounds["lte"] = *to
+	}
+	return map[string]any{"range": map[string]any{field: bounds}}
+}
+
+func dateFilter(field string, from, to *time.Time) map[string]any {
+	bounds := map[string]any{}
+	if from != nil {
+		bounds["gte"] = from.Format(time.RFC3339Nano)
+	}
+	if to != nil {
+		bounds["lte"] = to.Format(time.RFC3339Nano)
+	}
+	return map[string]any{"range": map[string]any{field: bounds}}
+}
+
+// boolFilter объединяет непустые фильтры через AND. Без фильтров возвращает nil.
+func boolFilter(filters ...any) map[string]any {
+	var set []any
+	for _, f := range filters {
+		if f != nil {
+			set = append(set, f)
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return map[string]any{"bool": map[string]any{"filter": set}}
+}
+
+// facetAgg оборачивает агрегацию values в filter-агрегацию с фильтрами фасета.
+func facetAgg(values map[string]any, filters ...any) map[string]any {
+	filter := boolFilter(filters...)
+	if filter == nil {
+		filter = map[string]any{"match_all": map[string]any{}}
+	}
+	return map[string]any{"filter": filter, "aggs": map[string]any{"values": values}}
+}
+
+func termsAgg(field string, size int) map[string]any {
+	return map[string]any{"terms": map[string]any{"field": field, "size": size}}
+}
+
+func costAgg() map[string]any {
+	ranges := make([]map[string]any, len(domain.CostRanges))
+	for i, r := range domain.CostRanges {
+		bucket := map[string]any{}
+		if r.From != nil {
+			bucket["from"] = *r.From
+		}
+		if r.To != nil {
+			bucket["to"] = *r.To
+		}
+		ranges[i] = bucket
+	}
+	return map[string]any{"range": map[string]any{"field": "cost_base", "ranges": ranges}}
+}
+
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source domain.TaskDocument `json:"_source"`
+			Sort   []json.Number       `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Values struct {
+			Buckets []struct {
+				Key      json.RawMessage `json:"key"`
+				DocCount int64           `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"values"`
+	} `json:"aggregations"`
+}
+
+// facets переводит агрегации в фасеты. Корзины диапазонов цены приходят в порядке domain.CostRanges.
+func (r searchResponse) facets() domain.TaskFacets {
+	terms := func(name string) []domain.FacetBucket {
+		buckets := r.Aggregations[name].Values.Buckets
+		result := make([]domain.FacetBucket, 0, len(buckets))
+		for _, b := range buckets {
+			value := string(b.Key)
+			var s string
+			if json.Unmarshal(b.Key, &s) == nil {
+				value = s
+			}
+			result = append(result, domain.FacetBucket{Value: value, Count: b.DocCount})
+		}
+		return result
+	}
+
+	facets := domain.TaskFacets{
+		Categories:       terms("categories"),
+		Subcategories:    terms("subcategories"),
+		ServiceLocations: terms("service_locations"),
+		Cost:             make([]domain.CostBucket, 0, len(domain.CostRanges)),
+	}
+	for i, b := range r.Aggregations["cost"].Values.Buckets {
+		if i >= len(domain.CostRanges) {
+			break
+		}
+		facets.Cost = append(facets.Cost, domain.CostBucket{CostRange: domain.CostRanges[i], Count: b.DocCount})
+	}
+	return facets
+}
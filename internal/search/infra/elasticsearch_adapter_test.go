@@ -0,0 +1,254 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/config"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+// fakeElasticsearch — локальный HTTP-сервер, который запоминает запросы и отвечает заготовленным телом.
+// В routes можно задать отдельный ответ для "МЕТОД путь".
+type fakeElasticsearch struct {
+	status   int
+	response string
+	routes   map[string]string
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	method string
+	path   string
+	auth   string
+	raw    string
+	body   map[string]any
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	req := recordedRequest{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), raw: string(raw)}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &req.body)
+	}
+	f.requests = append(f.requests, req)
+	w.Header().Set("Content-Type", "application/json")
+	if response, ok := f.routes[r.Method+" "+r.URL.Path]; ok {
+		_, _ = io.WriteString(w, response)
+		return
+	}
+	w.WriteHeader(f.status)
+	_, _ = io.WriteString(w, f.response)
+}
+
+func newTestEngine(t *testing.T, fake *fakeElasticsearch) *ElasticsearchEngine {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	engine, err := NewElasticsearchEngine(config.ExternalService{URL: srv.URL + "/", APIKey: "secret"}, "tasks")
+	if err != nil {
+		t.Fatalf("не удалось создать адаптер: %v", err)
+	}
+	return engine
+}
+
+func TestElasticsearchIndexAndDelete(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeElasticsearch{status: http.StatusOK, response: `{}`}
+	engine := newTestEngine(t, fake)
+
+	if err := engine.IndexTask(ctx, domain.TaskDocument{ID: 7, Title: "Покрасить забор"}); err != nil {
+		t.Fatalf("неожиданная ошибка индексации: %v", err)
+	}
+	got := fake.requests[0]
+	if got.method != http.MethodPut || got.path != "/tasks/_doc/7" || got.auth != "ApiKey secret" {
+		t.Errorf("неожиданный запрос индексации: %s %s %q", got.method, got.path, got.auth)
+	}
+	if got.body["title"] != "Покрасить забор" {
+		t.Errorf("в документе нет названия: %v", got.body)
+	}
+
+	fake.status = http.StatusNotFound
+	if err := engine.DeleteTask(ctx, 7); err != nil {
+		t.Errorf("удаление отсутствующего документа не должно быть ошибкой: %v", err)
+	}
+	if got := fake.requests[1]; got.method != http.MethodDelete || got.path != "/tasks/_doc/7" {
+		t.Errorf("неожиданный запрос удаления: %s %s", got.method, got.path)
+	}
+
+	fake.status = http.StatusServiceUnavailable
+	if err := engine.IndexTask(ctx, domain.TaskDocument{ID: 7}); !errors.Is(err, domain.ErrEngineUnavailable) {
+		t.Errorf("ожидалась ErrEngineUnavailable, получено %v", err)
+	}
+}
+
+func TestElasticsearchSearchTasks(t *testing.T) {
+	fake := &fakeElasticsearch{status: http.StatusOK, response: `{
+		"hits": {
+			"total": {"value": 3},
+			"hits": [
+				{"_source": {"id": 3, "title": "Собрать шкаф", "cost": 500000, "cost_currency": "RUB", "cost_base": 500000,
+					"created_at": "2026-10-01T10:00:00Z", "status_code": 1}, "sort": [500000, 3]},
+				{"_source": {"id": 2, "title": "Собрать кровать", "cost": 100, "cost_currency": "USD", "cost_base": 900000,
+					"created_at": "2026-10-02T10:00:00Z", "status_code": 1}, "sort": [900000, 2]}
+			]
+		},
+		"aggregations": {
+			"categories": {"doc_count": 3, "values": {"buckets": [{"key": 4, "doc_count": 3}]}},
+			"subcategories": {"doc_count": 2, "values": {"buckets": [{"key": 12, "doc_count": 2}]}},
+			"service_locations": {"doc_count": 2, "values": {"buckets": [{"key": "client", "doc_count": 2}]}},
+			"cost": {"doc_count": 2, "values": {"buckets": [
+				{"key": "*-100000.0", "doc_count": 0},
+				{"key": "100000.0-500000.0", "doc_count": 0},
+				{"key": "500000.0-2000000.0", "doc_count": 2},
+				{"key": "2000000.0-10000000.0", "doc_count": 0},
+				{"key": "10000000.0-*", "doc_count": 0}
+			]}}
+		}
+	}`}
+	engine := newTestEngine(t, fake)
+
+	after := pagination.IntCursor(string(tasksDomain.SortPriceAsc), 100000, 9)
+	query := tasksDomain.TaskSearchQuery{
+		Text:           "собрать",
+		CategoryIDs:    []int{4},
+		SubcategoryIDs: []int{12},
+		Statuses:       []tasksDomain.TaskStatusCode{tasksDomain.StatusActive},
+		Sort:           tasksDomain.SortPriceAsc,
+		Page:           pagination.Request{After: &after, Limit: 1},
+	}
+	result, err := engine.SearchTasks(context.Background(), query)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка поиска: %v", err)
+	}
+
+	req := fake.requests[0]
+	if req.method != http.MethodPost || req.path != "/tasks/_search" {
+		t.Errorf("неожиданный запрос поиска: %s %s", req.method, req.path)
+	}
+	if req.body["size"] != float64(2) {
+		t.Errorf("size: ожидалось 2, получено %v", req.body["size"])
+	}
+	if _, ok := req.body["post_filter"]; !ok {
+		t.Error("фильтры по категориям должны идти в post_filter, чтобы не сужать фасеты")
+	}
+	if after, _ := json.Marshal(req.body["search_after"]); string(after) != "[100000,9]" {
+		t.Errorf("search_after: ожидалось [100000,9], получено %s", after)
+	}
+
+	if result.Total != 3 || len(result.Items) != 1 || result.Items[0].ID != 3 {
+		t.Fatalf("неожиданная выдача: total %d, items %+v", result.Total, result.Items)
+	}
+	if result.Items[0].Cost == nil || result.Items[0].Cost.Amount != 500000 {
+		t.Errorf("стоимость не восстановлена из документа: %+v", result.Items[0].Cost)
+	}
+	next, err := pagination.Decode(result.NextCursor)
+	if err != nil || next.Int == nil || *next.Int != 500000 || next.ID != 3 {
+		t.Errorf("неожиданный курсор следующей страницы: %+v, %v", next, err)
+	}
+
+	facets := result.Facets
+	if len(facets.Categories) != 1 || facets.Categories[0] != (domain.FacetBucket{Value: "4", Count: 3}) {
+		t.Errorf("неожиданный фасет категорий: %+v", facets.Categories)
+	}
+	if len(facets.ServiceLocations) != 1 || facets.ServiceLocations[0].Value != "client" {
+		t.Errorf("неожиданный фасет места оказания услуги: %+v", facets.ServiceLocations)
+	}
+	if len(facets.Cost) != len(domain.CostRanges) || facets.Cost[2].Count != 2 || *facets.Cost[2].From != 500000 {
+		t.Errorf("неожиданный фасет цены: %+v", facets.Cost)
+	}
+}
+
+func TestElasticsearchSearchRejectsForeignCursor(t *testing.T) {
+	fake := &fakeElasticsearch{status: http.StatusOK, response: `{}`}
+	engine := newTestEngine(t, fake)
+
+	after := pagination.IntCursor(string(tasksDomain.SortNewest), 1, 1)
+	query := tasksDomain.TaskSearchQuery{Sort: tasksDomain.SortNewest, Page: pagination.Request{After: &after, Limit: 10}}
+	if _, err := engine.SearchTasks(context.Background(), query); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("ожидалась ErrInvalidCursor, получено %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Error("запрос с некорректным курсором не должен уходить в Elasticsearch")
+	}
+}
+
+func TestElasticsearchBulkIndexAndSwapAlias(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeElasticsearch{
+		status:   http.StatusOK,
+		response: `{"errors": false}`,
+		routes:   map[string]string{"GET /_alias/tasks": `{"tasks_1": {"aliases": {"tasks": {}}}}`},
+	}
+	engine := newTestEngine(t, fake)
+
+	docs := []domain.TaskDocument{{ID: 1, Title: "Первая"}, {ID: 2, Title: "Вторая"}}
+	if err := engine.BulkIndex(ctx, "tasks_2", docs); err != nil {
+		t.Fatalf("неожиданная ошибка пакетной индексации: %v", err)
+	}
+	bulk := fake.requests[0]
+	if bulk.path != "/_bulk" || strings.Count(bulk.raw, "\n") != 4 || !strings.Contains(bulk.raw, `"_index":"tasks_2"`) {
+		t.Errorf("неожиданный запрос _bulk: %s %q", bulk.path, bulk.raw)
+	}
+
+	previous, err := engine.SwapAlias(ctx, "tasks_2")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка переключения псевдонима: %v", err)
+	}
+	if len(previous) != 1 || previous[0] != "tasks_1" {
+		t.Errorf("ожидался прежний индекс tasks_1, получено %v", previous)
+	}
+	swap := fake.requests[len(fake.requests)-1]
+	actions, _ := json.Marshal(swap.body["actions"])
+	want := `[{"add":{"alias":"tasks","index":"tasks_2"}},{"remove":{"alias":"tasks","index":"tasks_1"}}]`
+	if swap.path != "/_aliases" || string(actions) != want {
+		t.Errorf("переключение должно быть одним запросом add+remove, получено %s %s", swap.path, actions)
+	}
+
+	fake.response = `{"errors": true, "items": [{"index": {"_id": "2", "error": {"reason": "mapper_parsing_exception"}}}]}`
+	if err := engine.BulkIndex(ctx, "tasks_2", docs); !errors.Is(err, domain.ErrEngineUnavailable) {
+		t.Errorf("ошибка отдельного документа должна вернуться как ErrEngineUnavailable, получено %v", err)
+	}
+}
+
+func TestElasticsearchListTaskIDsAndBulkDelete(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeElasticsearch{
+		status:   http.StatusOK,
+		response: `{"errors": false}`,
+		routes:   map[string]string{"POST /tasks_2/_search": `{"hits": {"hits": [{"_id": "3"}, {"_id": "7"}]}}`},
+	}
+	engine := newTestEngine(t, fake)
+
+	ids, err := engine.ListTaskIDs(ctx, "tasks_2", 2, 100)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка чтения ID: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 7 {
+		t.Errorf("ожидались ID [3 7], получено %v", ids)
+	}
+	search := fake.requests[0]
+	after, _ := json.Marshal(search.body["search_after"])
+	if search.body["_source"] != false || string(after) != "[2]" {
+		t.Errorf("ID должны читаться без документов начиная после afterID: %s", search.raw)
+	}
+
+	if err := engine.BulkDelete(ctx, "tasks_2", []int64{7}); err != nil {
+		t.Fatalf("неожиданная ошибка удаления: %v", err)
+	}
+	bulk := fake.requests[1]
+	if bulk.path != "/_bulk" || strings.TrimSpace(bulk.raw) != `{"delete":{"_id":"7","_index":"tasks_2"}}` {
+		t.Errorf("неожиданный запрос _bulk: %s %q", bulk.path, bulk.raw)
+	}
+	if err := engine.BulkDelete(ctx, "tasks_2", nil); err != nil || len(fake.requests) != 2 {
+		t.Errorf("пустой список не должен отправлять запрос: %v, запросов %d", err, len(fake.requests))
+	}
+}
@@ -0,0 +1,313 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/lib/pq"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	tasksDomain "github.com/unclaim/chegonado.git/internal/tasks/domain"
+)
+
+// unknownDistanceKm — ключ сортировки по расстоянию для задач без координат.
+// Больше любого расстояния на Земле, поэтому такие задачи идут в конце выдачи.
+const unknownDistanceKm = 1e9
+
+// tasksDocument — текст задачи для полнотекстового поиска с русской морфологией.
+const tasksDocument = `to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, ''))`
+
+// PostgresEngine ищет задачи прямо в таблице tasks полнотекстовым поиском PostgreSQL.
+// Отдельного индекса нет, поэтому IndexTask и DeleteTask ничего не делают.
+type PostgresEngine struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresEngine создаёт поиск задач по PostgreSQL.
+func NewPostgresEngine(db *pgxpool.Pool) *PostgresEngine {
+	return &PostgresEngine{db: db}
+}
+
+// IndexTask ничего не делает: задача уже в таблице, по которой идёт поиск.
+func (e *PostgresEngine) IndexTask(ctx context.Context, doc domain.TaskDocument) error {
+	return nil
+}
+
+// DeleteTask ничего не делает: удалённой задачи уже нет в таблице.
+func (e *PostgresEngine) DeleteTask(ctx context.Context, taskID int64) error {
+	return nil
+}
+
+// taskQuery — условия поиска задач в SQL. Имена колонок фиксированы в коде, из запроса пользователя
+// приходят только значения. Номера аргументов у каждого SQL-запроса свои, поэтому условия
+// собираются заново для каждого из них.
+type taskQuery struct {
+	args        []interface{}
+	where       []string // Все фильтры, кроме категорий и подкатегорий
+	category    string
+	subcategory string
+	textArg     string
+	distance    string // Расстояние до ближайшего адреса задачи; пусто без точки поиска
+}
+
+func newTaskQuery(q tasksDomain.TaskSearchQuery) *taskQuery {
+	t := &taskQuery{}
+	if q.Text != "" {
+		t.textArg = t.arg(q.Text)
+		t.where = append(t.where, fmt.Sprintf("%s @@ plainto_tsquery('russian', %s)", tasksDocument, t.textArg))
+	}
+	if len(q.CategoryIDs) > 0 {
+		t.category = "category_id = ANY(" + t.arg(pq.Array(q.CategoryIDs)) + ")"
+	}
+	if len(q.SubcategoryIDs) > 0 {
+		t.subcategory = "subcategory_id = ANY(" + t.arg(pq.Array(q.SubcategoryIDs)) + ")"
+	}
+	if q.CostMin != nil {
+		t.where = append(t.where, "cost_base >= "+t.arg(*q.CostMin))
+	}
+	if q.CostMax != nil {
+		t.where = append(t.where, "cost_base <= "+t.arg(*q.CostMax))
+	}
+	if q.CreatedFrom != nil {
+		t.where = append(t.where, "created_at >= "+t.arg(*q.CreatedFrom))
+	}
+	if q.CreatedTo != nil {
+		t.where = append(t.where, "created_at <= "+t.arg(*q.CreatedTo))
+	}
+	if q.StartFrom != nil {
+		t.where = append(t.where, "start_date >= "+t.arg(*q.StartFrom))
+	}
+	if q.StartTo != nil {
+		t.where = append(t.where, "start_date <= "+t.arg(*q.StartTo))
+	}
+	if len(q.Statuses) > 0 {
+		statuses := make([]int, len(q.Statuses))
+		for i, st := range q.Statuses {
+			statuses[i] = int(st)
+		}
+		t.where = append(t.where, "status_code = ANY("+t.arg(pq.Array(statuses))+")")
+	}
+	if q.ServiceLocation != "" {
+		t.where = append(t.where, "service_location = "+t.arg(q.ServiceLocation))
+	}
+	if q.Near != nil {
+		latArg, lonArg := t.arg(q.Near.Lat)+"::float8", t.arg(q.Near.Lon)+"::float8"
+		t.distance = "(SELECT MIN(" + geo.DistanceSQL("ta.latitude", "ta.longitude", latArg, lonArg) +
+			") FROM task_addresses ta WHERE ta.task_id = tasks.id AND ta.latitude IS NOT NULL)"
+		if q.RadiusKm != nil {
+			t.where = append(t.where, t.distance+" <= "+t.arg(*q.RadiusKm))
+		}
+	}
+	return t
+}
+
+func (t *taskQuery) arg(v interface{}) string {
+	t.args = append(t.args, v)
+	return fmt.Sprintf("$%d", len(t.args))
+}
+
+// whereSQL собирает WHERE из общих фильтров и дополнительных условий; пустые условия пропускаются.
+func (t *taskQuery) whereSQL(extra ...string) string {
+	conditions := append([]string{}, t.where...)
+	for _, c := range extra {
+		if c != "" {
+			conditions = append(conditions, c)
+		}
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// SearchTasks ищет задачи по строке запроса и фильтрам и считает фасеты.
+// Пагинация курсорная: ключ курсора зависит от сортировки, при равенстве ключей порядок задаёт id.
+func (e *PostgresEngine) SearchTasks(ctx context.Context, q tasksDomain.TaskSearchQuery) (domain.TaskResult, error) {
+	page, err := e.searchPage(ctx, q)
+	if err != nil {
+		return domain.TaskResult{}, err
+	}
+	total, cost, err := e.countCost(ctx, q)
+	if err != nil {
+		return domain.TaskResult{}, err
+	}
+
+	result := domain.TaskResult{Items: page.Items, NextCursor: page.NextCursor, Total: total}
+	result.Facets.Cost = cost
+	// Фасет категорий не учитывает фильтр по категориям, фасет подкатегорий — фильтр по подкатегориям.
+	if result.Facets.Categories, err = e.termsFacet(ctx, q, "category_id", categoryFacetSize, false, true); err != nil {
+		return domain.TaskResult{}, err
+	}
+	if result.Facets.Subcategories, err = e.termsFacet(ctx, q, "subcategory_id", subcategoryFacetSize, true, false); err != nil {
+		return domain.TaskResult{}, err
+	}
+	if result.Facets.ServiceLocations, err = e.termsFacet(ctx, q, "service_location", serviceLocationFacetSize, true, true); err != nil {
+		return domain.TaskResult{}, err
+	}
+	return result, nil
+}
+
+func (e *PostgresEngine) searchPage(ctx context.Context, q tasksDomain.TaskSearchQuery) (pagination.Page[tasksDomain.Task], error) {
+	t := newTaskQuery(q)
+
+	// Ключ сортировки и направление. Цены сравниваются в базовой валюте;
+	// задачи без цены при сортировке по цене идут в конце.
+	var sortKey, direction, cmp string
+	switch q.Sort {
+	case tasksDomain.SortRelevance:
+		sortKey, direction, cmp = fmt.Sprintf("ts_rank(%s, plainto_tsquery('russian', %s))::float8", tasksDocument, t.textArg), "DESC", "<"
+	case tasksDomain.SortPriceAsc:
+		sortKey, direction, cmp = "COALESCE(cost_base, 9223372036854775807)::bigint", "ASC", ">"
+	case tasksDomain.SortPriceDesc:
+		sortKey, direction, cmp = "COALESCE(cost_base, -1)::bigint", "DESC", "<"
+	case tasksDomain.SortDistance:
+		// Задачи без координат идут в конце.
+		sortKey, direction, cmp = fmt.Sprintf("COALESCE(%s, %v)::float8", t.distance, unknownDistanceKm), "ASC", ">"
+	default:
+		sortKey, direction, cmp = "created_at", "DESC", "<"
+	}
+
+	var after string
+	if cursor := q.Page.After; cursor != nil {
+		var keyArg string
+		switch {
+		case (q.Sort == tasksDomain.SortRelevance || q.Sort == tasksDomain.SortDistance) && cursor.Float != nil:
+			keyArg = t.arg(*cursor.Float)
+		case (q.Sort == tasksDomain.SortPriceAsc || q.Sort == tasksDomain.SortPriceDesc) && cursor.Int != nil:
+			keyArg = t.arg(*cursor.Int)
+		case q.Sort == tasksDomain.SortNewest && cursor.Time != nil:
+			keyArg = t.arg(*cursor.Time)
+		default:
+			return pagination.Page[tasksDomain.Task]{}, pagination.ErrInvalidCursor
+		}
+		after = fmt.Sprintf("(%s, id) %s (%s, %s)", sortKey, cmp, keyArg, t.arg(cursor.ID))
+	}
+
+	distanceColumn := "NULL::float8"
+	if t.distance != "" {
+		distanceColumn = t.distance
+	}
+
+	sqlQuery := `SELECT ` + documentColumns + `, ` + distanceColumn + `, ` + sortKey + ` FROM tasks` +
+		t.whereSQL(t.category, t.subcategory, after) +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", sortKey, direction, direction, t.arg(q.Page.FetchLimit()))
+
+	rows, err := e.db.Query(ctx, sqlQuery, t.args...)
+	if err != nil {
+		return pagination.Page[tasksDomain.Task]{}, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer rows.Close()
+
+	var items []tasksDomain.Task
+	cursors := make(map[int64]pagination.Cursor)
+	sort := string(q.Sort)
+	for rows.Next() {
+		var doc domain.TaskDocument
+		var distanceKm sql.NullFloat64
+		var timeKey time.Time
+		var intKey int64
+		var floatKey float64
+
+		var key interface{}
+		switch q.Sort {
+		case tasksDomain.SortRelevance, tasksDomain.SortDistance:
+			key = &floatKey
+		case tasksDomain.SortPriceAsc, tasksDomain.SortPriceDesc:
+			key = &intKey
+		default:
+			key = &timeKey
+		}
+		if err := scanDocument(rows, &doc, &distanceKm, key); err != nil {
+			return pagination.Page[tasksDomain.Task]{}, err
+		}
+
+		task := doc.Task(nil)
+		if distanceKm.Valid {
+			task.DistanceKm = &distanceKm.Float64
+		}
+		switch q.Sort {
+		case tasksDomain.SortRelevance, tasksDomain.SortDistance:
+			cursors[task.ID] = pagination.FloatCursor(sort, floatKey, task.ID)
+		case tasksDomain.SortPriceAsc, tasksDomain.SortPriceDesc:
+			cursors[task.ID] = pagination.IntCursor(sort, intKey, task.ID)
+		default:
+			cursors[task.ID] = pagination.TimeCursor(sort, timeKey, task.ID)
+		}
+		items = append(items, task)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[tasksDomain.Task]{}, fmt.Errorf("ошибка обработки результата: %w", err)
+	}
+
+	return pagination.NewPage(items, q.Page, func(t tasksDomain.Task) pagination.Cursor { return cursors[t.ID] }), nil
+}
+
+// countCost считает все найденные задачи и их распределение по диапазонам цены одним запросом.
+func (e *PostgresEngine) countCost(ctx context.Context, q tasksDomain.TaskSearchQuery) (int64, []domain.CostBucket, error) {
+	t := newTaskQuery(q)
+	columns := []string{"COUNT(*)"}
+	for _, r := range domain.CostRanges {
+		var bounds []string
+		if r.From != nil {
+			bounds = append(bounds, "cost_base >= "+t.arg(*r.From))
+		}
+		if r.To != nil {
+			bounds = append(bounds, "cost_base < "+t.arg(*r.To))
+		}
+		columns = append(columns, "COUNT(*) FILTER (WHERE "+strings.Join(bounds, " AND ")+")")
+	}
+
+	counts := make([]int64, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	sqlQuery := `SELECT ` + strings.Join(columns, ", ") + ` FROM tasks` + t.whereSQL(t.category, t.subcategory)
+	if err := e.db.QueryRow(ctx, sqlQuery, t.args...).Scan(dest...); err != nil {
+		return 0, nil, fmt.Errorf("ошибка при подсчёте найденных задач: %w", err)
+	}
+
+	buckets := make([]domain.CostBucket, len(domain.CostRanges))
+	for i, r := range domain.CostRanges {
+		buckets[i] = domain.CostBucket{CostRange: r, Count: counts[i+1]}
+	}
+	return counts[0], buckets, nil
+}
+
+// termsFacet считает самые частые значения колонки column среди найденных задач.
+// withCategory и withSubcategory определяют, учитываются ли фильтры по категориям и подкатегориям.
+func (e *PostgresEngine) termsFacet(ctx context.Context, q tasksDomain.TaskSearchQuery, column string, size int, withCategory, withSubcategory bool) ([]domain.FacetBucket, error) {
+	t := newTaskQuery(q)
+	var category, subcategory string
+	if withCategory {
+		category = t.category
+	}
+	if withSubcategory {
+		subcategory = t.subcategory
+	}
+	notEmpty := column + " IS NOT NULL AND " + column + "::text <> ''"
+
+	sqlQuery := `SELECT ` + column + `::text, COUNT(*) FROM tasks` + t.whereSQL(category, subcategory, notEmpty) +
+		` GROUP BY ` + column + ` ORDER BY COUNT(*) DESC, ` + column + ` LIMIT ` + t.arg(size)
+	rows, err := e.db.Query(ctx, sqlQuery, t.args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте фасета %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.FacetBucket{}
+	for rows.Next() {
+		var b domain.FacetBucket
+		if err := rows.Scan(&b.Value, &b.Count); err != nil {
+			return nil, fmt.Errorf("ошибка чтения фасета %s: %w", column, err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
@@ -0,0 +1,141 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+)
+
+// documentColumns — поля задачи в порядке, который ожидает listDocuments.
+const documentColumns = `id, title, COALESCE(description, ''), user_id, category_id, subcategory_id, cost, COALESCE(cost_currency, ''), cost_base,
+        COALESCE(addresses, '{}'), COALESCE(service_location, ''), COALESCE(period_type, ''), COALESCE(status_code, 0), COALESCE(revision, 0),
+        created_at, start_date, end_date`
+
+// TaskSource читает задачи из PostgreSQL для поискового индекса.
+type TaskSource struct {
+	db *pgxpool.Pool
+}
+
+// NewTaskSource создаёт источник задач для индексации.
+func NewTaskSource(db *pgxpool.Pool) *TaskSource {
+	return &TaskSource{db: db}
+}
+
+// GetTaskDocument возвращает документ индекса для задачи.
+func (r *TaskSource) GetTaskDocument(ctx context.Context, taskID int64) (*domain.TaskDocument, error) {
+	docs, err := r.listDocuments(ctx, `SELECT `+documentColumns+` FROM tasks WHERE id = $1`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, domain.ErrTaskNotFound
+	}
+	return &docs[0], nil
+}
+
+// CountTasks возвращает число задач в базе: по нему переиндексация показывает прогресс.
+func (r *TaskSource) CountTasks(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM tasks`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчёте задач: %w", err)
+	}
+	return count, nil
+}
+
+// ListTaskDocuments возвращает до limit документов задач с ID больше afterID в порядке ID.
+// Если since задан, возвращаются только задачи, изменённые не раньше since.
+func (r *TaskSource) ListTaskDocuments(ctx context.Context, since *time.Time, afterID int64, limit int) ([]domain.TaskDocument, error) {
+	if since != nil {
+		return r.listDocuments(ctx, `SELECT `+documentColumns+` FROM tasks WHERE updated_at >= $1 AND id > $2 ORDER BY id LIMIT $3`, *since, afterID, limit)
+	}
+	return r.listDocuments(ctx, `SELECT `+documentColumns+` FROM tasks WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+}
+
+// ExistingTaskIDs возвращает те из ids, задачи с которыми ещё есть в базе.
+func (r *TaskSource) ExistingTaskIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `SELECT id FROM tasks WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при проверке существования задач: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ID задачи: %w", err)
+		}
+		existing = append(existing, id)
+	}
+	return existing, rows.Err()
+}
+
+// scanDocument читает строку с колонками documentColumns и дополнительными колонками extra.
+func scanDocument(row pgx.Row, d *domain.TaskDocument, extra ...interface{}) error {
+	dest := []interface{}{&d.ID, &d.Title, &d.Description, &d.UserID, &d.CategoryID, &d.SubcategoryID, &d.Cost, &d.CostCurrency, &d.CostBase,
+		&d.Addresses, &d.ServiceLocation, &d.PeriodType, &d.StatusCode, &d.Revision, &d.CreatedAt, &d.StartDate, &d.EndDate}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return fmt.Errorf("ошибка при сканировании задачи: %w", err)
+	}
+	if d.Cost == nil {
+		d.CostCurrency = ""
+	}
+	return nil
+}
+
+// listDocuments читает задачи запросом query и дополняет их координатами адресов.
+func (r *TaskSource) listDocuments(ctx context.Context, query string, args ...interface{}) ([]domain.TaskDocument, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении задач для индексации: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []domain.TaskDocument
+	index := make(map[int64]int)
+	for rows.Next() {
+		var d domain.TaskDocument
+		if err := scanDocument(rows, &d); err != nil {
+			return nil, err
+		}
+		index[d.ID] = len(docs)
+		docs = append(docs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк результата: %w", err)
+	}
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	ids := make([]int64, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	locRows, err := r.db.Query(ctx, `
+        SELECT task_id, latitude, longitude
+        FROM task_addresses
+        WHERE task_id = ANY($1) AND latitude IS NOT NULL AND longitude IS NOT NULL
+        ORDER BY task_id, position`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении адресов задач: %w", err)
+	}
+	defer locRows.Close()
+
+	for locRows.Next() {
+		var taskID int64
+		var p geo.Point
+		if err := locRows.Scan(&taskID, &p.Lat, &p.Lon); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании адреса задачи: %w", err)
+		}
+		i := index[taskID]
+		docs[i].Locations = append(docs[i].Locations, p)
+	}
+	return docs, locRows.Err()
+}
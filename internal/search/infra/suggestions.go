@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/search/domain"
+)
+
+const (
+	// popularQueryMinRuns — сколько раз запрос должен найти что-то, чтобы попасть в подсказки.
+	// Разовые запросы могут содержать личные данные, поэтому в подсказки не попадают.
+	popularQueryMinRuns = 3
+	// popularQueryWindow — за какой срок учитываются запросы.
+	popularQueryWindow = "90 days"
+	// popularQueryLimit — сколько самых частых запросов попадает в подсказки.
+	popularQueryLimit = 1000
+	// taskTitleLimit — сколько последних открытых задач попадает в подсказки.
+	taskTitleLimit = 5000
+	// openTaskStatus — код статуса открытой задачи (tasks/domain.StatusActive).
+	openTaskStatus = 100
+)
+
+// suggestionQueries — запросы, из которых строится индекс подсказок: вид подсказки и SQL,
+// возвращающий id, текст и популярность.
+var suggestionQueries = []struct {
+	kind  domain.SuggestionKind
+	query string
+}{
+	{domain.SuggestCategory, `
+        SELECT c.id, c.name, COUNT(t.id)
+        FROM categories c
+        LEFT JOIN tasks t ON t.category_id = c.id
+        GROUP BY c.id, c.name`},
+	{domain.SuggestSubcategory, `
+        SELECT s.id, s.name, COUNT(t.id)
+        FROM subcategories s
+        LEFT JOIN tasks t ON t.subcategory_id = s.id
+        GROUP BY s.id, s.name`},
+	{domain.SuggestSkill, `
+        SELECT c.id, c.name, COUNT(DISTINCT sk.user_id)
+        FROM user_skills sk
+        JOIN categories c ON c.id = sk.category_id
+        GROUP BY c.id, c.name`},
+	{domain.SuggestQuery, fmt.Sprintf(`
+        SELECT 0, query, runs
+        FROM search_queries
+        WHERE runs >= %d AND last_run_at >= NOW() - INTERVAL '%s'
+        ORDER BY runs DESC
+        LIMIT %d`, popularQueryMinRuns, popularQueryWindow, popularQueryLimit)},
+	{domain.SuggestTask, fmt.Sprintf(`
+        SELECT t.id, t.title, (SELECT COUNT(*) FROM responses r WHERE r.task_id = t.id)
+        FROM tasks t
+        WHERE t.status_code = %d
+        ORDER BY t.created_at DESC
+        LIMIT %d`, openTaskStatus, taskTitleLimit)},
+}
+
+// SuggestionsRepository читает подсказки из PostgreSQL и ведёт журнал поисковых запросов.
+type SuggestionsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSuggestionsRepository создаёт репозиторий подсказок.
+func NewSuggestionsRepository(db *pgxpool.Pool) *SuggestionsRepository {
+	return &SuggestionsRepository{db: db}
+}
+
+// ListSuggestions возвращает все подсказки для индекса: категории, подкатегории, навыки,
+// частые запросы и названия открытых задач.
+func (r *SuggestionsRepository) ListSuggestions(ctx context.Context) ([]domain.Suggestion, error) {
+	var result []domain.Suggestion
+	for _, q := range suggestionQueries {
+		rows, err := r.db.Query(ctx, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении подсказок %s: %w", q.kind, err)
+		}
+		for rows.Next() {
+			s := domain.Suggestion{Kind: q.kind}
+			if err := rows.Scan(&s.ID, &s.Text, &s.Popularity); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("ошибка при сканировании подсказки %s: %w", q.kind, err)
+			}
+			result = append(result, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("ошибка при обработке подсказок %s: %w", q.kind, err)
+		}
+	}
+	return result, nil
+}
+
+// LogQuery учитывает ещё один запуск запроса.
+func (r *SuggestionsRepository) LogQuery(ctx context.Context, query string) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO search_queries (query) VALUES ($1)
+        ON CONFLICT (query) DO UPDATE SET runs = search_queries.runs + 1, last_run_at = NOW()`, query)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи поискового запроса: %w", err)
+	}
+	return nil
+}
@@ -22,6 +22,15 @@ type AppConfig struct {
 	Security         Security         `yaml:"security"`
 	Deployment       Deployment       `yaml:"deployment"`
 	FileStorage      FileStorage      `yaml:"file_storage"`
+	Geocoding        Geocoding        `yaml:"geocoding"`
+	Fees             Fees             `yaml:"fees"`
+	Payouts          Payouts          `yaml:"payouts"`
+	Documents        Documents        `yaml:"documents"`
+	Subscriptions    Subscriptions    `yaml:"subscriptions"`
+	Ratings          Ratings          `yaml:"ratings"`
+	Reviews          Reviews          `yaml:"reviews"`
+	Search           Search           `yaml:"search"`
+	Shortener        Shortener        `yaml:"shortener"`
 	SMTPConfig       *SMTPConfig      `yaml:"smtp_config"`
 }
 
@@ -159,15 +168,17 @@ type CORS struct {
 type ExternalServices struct {
 	PaymentGateway      ExternalService `yaml:"payment_gateway"`
 	NotificationService ExternalService `yaml:"notification_service"`
+	Elasticsearch       ExternalService `yaml:"elasticsearch"`
 }
 
 // ExternalService содержит общие параметры для сторонних сервисов.
 type ExternalService struct {
-	URL     string `yaml:"url"`
-	APIKey  string `yaml:"api_key"`
-	Secret  string `yaml:"secret"`
-	Timeout string `yaml:"timeout"`
-	Retries int    `yaml:"retries"`
+	Provider string `yaml:"provider"` // Реализация адаптера, например "fake" или "http"
+	URL      string `yaml:"url"`
+	APIKey   string `yaml:"api_key"`
+	Secret   string `yaml:"secret"`
+	Timeout  string `yaml:"timeout"`
+	Retries  int    `yaml:"retries"`
 }
 
 // Security содержит параметры безопасности.
@@ -244,6 +255,82 @@ type S3 struct {
 	Bucket          string `yaml:"bucket"`
 }
 
+// Geocoding содержит параметры геокодера адресов.
+type Geocoding struct {
+	Provider      string `yaml:"provider"`       // gazetteer — офлайн-справочник, работает без сети
+	GazetteerPath string `yaml:"gazetteer_path"` // Свой справочник вместо встроенного, необязательно
+}
+
+// Fees содержит тарифы комиссии площадки. Ставки задаются в базисных пунктах: 1000 = 10%.
+type Fees struct {
+	DefaultRateBP      int       `yaml:"default_rate_bp"`
+	ProDiscountPercent int       `yaml:"pro_discount_percent"` // Скидка на комиссию для исполнителей с Pro
+	Rules              []FeeRule `yaml:"rules"`
+}
+
+// FeeRule — ставка комиссии для категории или подкатегории задач.
+type FeeRule struct {
+	CategoryID    int `yaml:"category_id"`
+	SubcategoryID int `yaml:"subcategory_id"` // 0 — правило для всей категории
+	RateBP        int `yaml:"rate_bp"`
+}
+
+// Payouts содержит параметры вывода средств исполнителями.
+type Payouts struct {
+	MinAmount     int    `yaml:"min_amount"`     // Минимальная сумма заявки на вывод в копейках
+	MaxBatchSize  int    `yaml:"max_batch_size"` // Сколько одобренных выплат отправлять в шлюз за раз
+	BatchInterval string `yaml:"batch_interval"` // Как часто отправлять одобренные выплаты
+}
+
+// Documents содержит параметры закрывающих документов по контрактам.
+type Documents struct {
+	FontDir string `yaml:"font_dir"` // Каталог со шрифтами DejaVu Sans для PDF
+}
+
+// Subscriptions содержит тарифы подписки Pro.
+type Subscriptions struct {
+	GracePeriod     string             `yaml:"grace_period"`     // Сколько Pro действует после неудачного продления
+	RenewalInterval string             `yaml:"renewal_interval"` // Как часто проверять подписки к продлению
+	Plans           []SubscriptionPlan `yaml:"plans"`
+}
+
+// SubscriptionPlan — тариф подписки Pro.
+type SubscriptionPlan struct {
+	Code     string `yaml:"code"`
+	Title    string `yaml:"title"`
+	Period   string `yaml:"period"`   // monthly или yearly
+	Price    int    `yaml:"price"`    // В минимальных единицах валюты тарифа
+	Currency string `yaml:"currency"` // Код валюты цены, по умолчанию RUB; у всех тарифов одна валюта
+	NoAds    bool   `yaml:"no_ads"`
+}
+
+// Ratings содержит параметры байесовского рейтинга исполнителей. Пустые значения заменяются значениями по умолчанию.
+type Ratings struct {
+	PriorMean       float64 `yaml:"prior_mean"`       // Оценка исполнителя без отзывов
+	PriorWeight     float64 `yaml:"prior_weight"`     // Сколько отзывов нужно, чтобы собственные оценки перевесили априорную
+	HalfLife        string  `yaml:"half_life"`        // За сколько вес отзыва убывает вдвое
+	RefreshInterval string  `yaml:"refresh_interval"` // Как часто учитывать пропущенные отзывы и давность старых
+}
+
+// Reviews содержит сроки правки и публикации отзывов. Пустые значения заменяются значениями по умолчанию.
+type Reviews struct {
+	EditWindow     string `yaml:"edit_window"`     // Сколько автор может править отзыв после отправки
+	RevealAfter    string `yaml:"reveal_after"`    // Сколько ждать отзыва второй стороны перед публикацией
+	RevealInterval string `yaml:"reveal_interval"` // Как часто публиковать отзывы с истёкшим сроком ожидания
+}
+
+// Search содержит настройки поиска задач.
+type Search struct {
+	Backend    string `yaml:"backend"`     // "postgres" (по умолчанию) или "elasticsearch"
+	TasksIndex string `yaml:"tasks_index"` // Псевдоним индекса задач в Elasticsearch; по умолчанию "tasks"
+}
+
+// Shortener содержит адреса коротких ссылок. Пустые адреса — ссылки от корня этого же сервера.
+type Shortener struct {
+	BaseURL string `yaml:"base_url"` // Адрес, к которому добавляется /s/{code}
+	SiteURL string `yaml:"site_url"` // Адрес сайта, на страницы которого ведут ссылки
+}
+
 // LoadConfig загружает конфигурацию из файла и переменных окружения.
 // Переменные окружения имеют приоритет.
 func LoadConfig(filename string) (*AppConfig, error) {
@@ -349,12 +436,27 @@ func LoadConfig(filename string) (*AppConfig, error) {
 	if smtpFromName := os.Getenv("SMTP_FROM_NAME"); smtpFromName != "" {
 		config.Monitoring.Alerts.Email.FromName = smtpFromName
 	}
+	if paymentProvider := os.Getenv("PAYMENT_GATEWAY_PROVIDER"); paymentProvider != "" {
+		config.ExternalServices.PaymentGateway.Provider = paymentProvider
+	}
 	if paymentKey := os.Getenv("PAYMENT_API_KEY"); paymentKey != "" {
 		config.ExternalServices.PaymentGateway.APIKey = paymentKey
 	}
+	if paymentSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET"); paymentSecret != "" {
+		config.ExternalServices.PaymentGateway.Secret = paymentSecret
+	}
 	if notificationSecret := os.Getenv("NOTIFICATION_SECRET"); notificationSecret != "" {
 		config.ExternalServices.NotificationService.Secret = notificationSecret
 	}
+	if searchBackend := os.Getenv("SEARCH_BACKEND"); searchBackend != "" {
+		config.Search.Backend = searchBackend
+	}
+	if esURL := os.Getenv("ELASTICSEARCH_URL"); esURL != "" {
+		config.ExternalServices.Elasticsearch.URL = esURL
+	}
+	if esKey := os.Getenv("ELASTICSEARCH_API_KEY"); esKey != "" {
+		config.ExternalServices.Elasticsearch.APIKey = esKey
+	}
 
 	// Настройки файлового хранилища
 	if fileStorageType := os.Getenv("FILE_STORAGE_TYPE"); fileStorageType != "" {
@@ -373,5 +475,13 @@ func LoadConfig(filename string) (*AppConfig, error) {
 		config.FileStorage.S3.Bucket = s3Bucket
 	}
 
+	// Настройки геокодера
+	if geocoderProvider := os.Getenv("GEOCODER_PROVIDER"); geocoderProvider != "" {
+		config.Geocoding.Provider = geocoderProvider
+	}
+	if gazetteerPath := os.Getenv("GEOCODER_GAZETTEER_PATH"); gazetteerPath != "" {
+		config.Geocoding.GazetteerPath = gazetteerPath
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm — средний радиус Земли в километрах.
+const EarthRadiusKm = 6371.0
+
+// MaxRadiusKm — максимальный радиус поиска, который принимает API.
+const MaxRadiusKm = 1000.0
+
+var (
+	// ErrInvalidPoint возвращается для координат вне допустимого диапазона.
+	ErrInvalidPoint = errors.New("некорректные координаты")
+	// ErrInvalidRadius возвращается для радиуса поиска вне допустимого диапазона.
+	ErrInvalidRadius = errors.New("некорректный радиус поиска")
+	// ErrAddressNotFound возвращается геокодером, если адрес не удалось сопоставить с координатами.
+	ErrAddressNotFound = errors.New("адрес не найден")
+)
+
+// Point — точка на поверхности Земли в градусах WGS 84.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Validate проверяет, что широта и долгота лежат в допустимых пределах.
+func (p Point) Validate() error {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lon) || p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("%w: %.6f, %.6f", ErrInvalidPoint, p.Lat, p.Lon)
+	}
+	return nil
+}
+
+// ParsePoint разбирает координаты из строковых параметров запроса.
+// Если обе строки пусты, возвращает nil: точка не задана.
+func ParsePoint(rawLat, rawLon string) (*Point, error) {
+	rawLat, rawLon = strings.TrimSpace(rawLat), strings.TrimSpace(rawLon)
+	if rawLat == "" && rawLon == "" {
+		return nil, nil
+	}
+	if rawLat == "" || rawLon == "" {
+		return nil, fmt.Errorf("%w: широта и долгота задаются вместе", ErrInvalidPoint)
+	}
+	lat, err := strconv.ParseFloat(rawLat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: широта должна быть числом, получено %q", ErrInvalidPoint, rawLat)
+	}
+	lon, err := strconv.ParseFloat(rawLon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: долгота должна быть числом, получено %q", ErrInvalidPoint, rawLon)
+	}
+	p := Point{Lat: lat, Lon: lon}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ParseRadiusKm разбирает радиус поиска в километрах из диапазона (0, MaxRadiusKm].
+// Пустая строка означает, что радиус не задан.
+func ParseRadiusKm(raw string) (*float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	r, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(r) || r <= 0 || r > MaxRadiusKm {
+		return nil, fmt.Errorf("%w: радиус должен быть числом от 0 до %v км, получено %q", ErrInvalidRadius, MaxRadiusKm, raw)
+	}
+	return &r, nil
+}
+
+// DistanceKm возвращает расстояние по дуге большого круга между точками (формула гаверсинусов).
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// DistanceSQL возвращает SQL-выражение расстояния в километрах между колонками latCol/lonCol
+// и точкой, переданной параметрами latArg/lonArg. Выражение совпадает с DistanceKm
+// и не требует расширений PostgreSQL.
+func DistanceSQL(latCol, lonCol, latArg, lonArg string) string {
+	return fmt.Sprintf(
+		"(2 * %v * asin(least(1, sqrt(power(sin(radians(%s - %s) / 2), 2) + cos(radians(%s)) * cos(radians(%s)) * power(sin(radians(%s - %s) / 2), 2)))))",
+		EarthRadiusKm, latCol, latArg, latArg, latCol, lonCol, lonArg)
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
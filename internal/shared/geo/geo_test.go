@@ -0,0 +1,57 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	moscow := Point{Lat: 55.7558, Lon: 37.6173}
+	spb := Point{Lat: 59.9343, Lon: 30.3351}
+
+	// Расстояние Москва — Санкт-Петербург по прямой около 634 км.
+	if d := DistanceKm(moscow, spb); math.Abs(d-634) > 5 {
+		t.Errorf("ожидалось около 634 км, получено %.1f", d)
+	}
+	if d := DistanceKm(moscow, moscow); d != 0 {
+		t.Errorf("расстояние до самой точки должно быть 0, получено %f", d)
+	}
+}
+
+func TestPointValidate(t *testing.T) {
+	if err := (Point{Lat: 91, Lon: 0}).Validate(); err == nil {
+		t.Error("широта больше 90 должна отклоняться")
+	}
+	if err := (Point{Lat: 0, Lon: -181}).Validate(); err == nil {
+		t.Error("долгота меньше -180 должна отклоняться")
+	}
+	if err := (Point{Lat: 55.75, Lon: 37.61}).Validate(); err != nil {
+		t.Errorf("корректная точка отклонена: %v", err)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	if p, err := ParsePoint("", ""); p != nil || err != nil {
+		t.Errorf("пустые координаты означают отсутствие точки, получено %v, %v", p, err)
+	}
+	if p, err := ParsePoint("55.75", "37.61"); err != nil || p.Lat != 55.75 || p.Lon != 37.61 {
+		t.Errorf("координаты разобраны неверно: %v, %v", p, err)
+	}
+	for _, c := range [][2]string{{"55.75", ""}, {"abc", "37.61"}, {"95", "37.61"}} {
+		if _, err := ParsePoint(c[0], c[1]); !errors.Is(err, ErrInvalidPoint) {
+			t.Errorf("%v: ожидалась ErrInvalidPoint, получено %v", c, err)
+		}
+	}
+}
+
+func TestParseRadiusKm(t *testing.T) {
+	if r, err := ParseRadiusKm("25"); err != nil || *r != 25 {
+		t.Errorf("радиус разобран неверно: %v, %v", r, err)
+	}
+	for _, raw := range []string{"0", "-5", "abc", "5000"} {
+		if _, err := ParseRadiusKm(raw); !errors.Is(err, ErrInvalidRadius) {
+			t.Errorf("%q: ожидалась ErrInvalidRadius, получено %v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency — код валюты по ISO 4217.
+type Currency string
+
+// Base — базовая валюта площадки: в ней сравниваются и сортируются цены в разных валютах.
+const Base Currency = "RUB"
+
+// Locale — язык, по правилам которого печатаются суммы.
+type Locale string
+
+const (
+	LocaleRU Locale = "ru" // 1 234,56 ₽
+	LocaleEN Locale = "en" // ₽1,234.56
+)
+
+var (
+	// ErrUnknownCurrency возвращается для валюты, которую площадка не поддерживает.
+	ErrUnknownCurrency = errors.New("неизвестная валюта")
+	// ErrInvalidMoney возвращается для отрицательной суммы или суммы без валюты.
+	ErrInvalidMoney = errors.New("некорректная сумма")
+)
+
+// currencyInfo — число знаков дробной части и символ валюты.
+type currencyInfo struct {
+	exponent int
+	symbol   string
+}
+
+// currencies — валюты, в которых можно указывать цены.
+var currencies = map[Currency]currencyInfo{
+	"RUB": {2, "₽"},
+	"USD": {2, "$"},
+	"EUR": {2, "€"},
+	"KZT": {2, "₸"},
+	"BYN": {2, "Br"},
+	"UZS": {2, "сўм"},
+	"CNY": {2, "¥"},
+	"JPY": {0, "¥"},
+}
+
+// ParseCurrency разбирает код валюты без учёта регистра.
+func ParseCurrency(raw string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(raw)))
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	return c, nil
+}
+
+// Validate проверяет, что валюта поддерживается.
+func (c Currency) Validate() error {
+	if _, ok := currencies[c]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, string(c))
+	}
+	return nil
+}
+
+// Exponent возвращает число знаков дробной части: 2 для рубля (копейки), 0 для иены.
+func (c Currency) Exponent() int {
+	return currencies[c].exponent
+}
+
+// Symbol возвращает символ валюты или её код, если символа нет.
+func (c Currency) Symbol() string {
+	if info, ok := currencies[c]; ok {
+		return info.symbol
+	}
+	return string(c)
+}
+
+// Money — сумма в минимальных единицах валюты: 150000 RUB — это 1 500 рублей.
+type Money struct {
+	Amount   int      `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New создаёт сумму в минимальных единицах валюты.
+func New(amount int, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Validate проверяет валюту и то, что сумма не отрицательна.
+func (m Money) Validate() error {
+	if m.Currency == "" {
+		return fmt.Errorf("%w: не указана валюта", ErrInvalidMoney)
+	}
+	if err := m.Currency.Validate(); err != nil {
+		return err
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMoney, m.Amount)
+	}
+	return nil
+}
+
+// IsZero сообщает, что сумма равна нулю.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// String печатает сумму по русским правилам.
+func (m Money) String() string {
+	return m.Format(LocaleRU)
+}
+
+// Format печатает сумму по правилам локали. Дробная часть печатается, только если она не нулевая:
+// 1 500 ₽, но 1 500,50 ₽. Неизвестная локаль печатается по-русски.
+func (m Money) Format(locale Locale) string {
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	number := m.abs().Number(locale)
+	if locale == LocaleEN {
+		return sign + m.Currency.Symbol() + number
+	}
+	return sign + number + " " + m.Currency.Symbol()
+}
+
+// Number печатает сумму по правилам локали без символа валюты: 1 500,50 или 1,500.50.
+// Пригодится там, где валюту нужно указать кодом, например в документах.
+func (m Money) Number(locale Locale) string {
+	groupSep, decimalSep := " ", ","
+	if locale == LocaleEN {
+		groupSep, decimalSep = ",", "."
+	}
+
+	amount, sign := m.Amount, ""
+	if amount < 0 {
+		amount, sign = -amount, "-"
+	}
+	unit := pow10(m.Currency.Exponent())
+	number := group(strconv.Itoa(amount/unit), groupSep)
+	if fraction := amount % unit; fraction != 0 {
+		number += decimalSep + fmt.Sprintf("%0*d", m.Currency.Exponent(), fraction)
+	}
+	return sign + number
+}
+
+func (m Money) abs() Money {
+	if m.Amount < 0 {
+		m.Amount = -m.Amount
+	}
+	return m
+}
+
+// group разделяет разряды целой части: 1234567 -> 1 234 567.
+func group(digits, sep string) string {
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func pow10(n int) int {
+	p := 1
+	for range n {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,92 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		m      Money
+		locale Locale
+		want   string
+	}{
+		{New(150000, "RUB"), LocaleRU, "1 500 ₽"},
+		{New(123456789, "RUB"), LocaleRU, "1 234 567,89 ₽"},
+		{New(5, "RUB"), LocaleRU, "0,05 ₽"},
+		{New(123456, "USD"), LocaleEN, "$1,234.56"},
+		{New(-2500, "EUR"), LocaleEN, "-€25"},
+		{New(1500, "JPY"), LocaleRU, "1 500 ¥"},
+	}
+	for _, c := range cases {
+		if got := c.m.Format(c.locale); got != c.want {
+			t.Errorf("%+v %s: ожидалось %q, получено %q", c.m, c.locale, c.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := New(100, "RUB").Validate(); err != nil {
+		t.Errorf("корректная сумма: %v", err)
+	}
+	if err := New(-1, "RUB").Validate(); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("отрицательная сумма: ожидалась ErrInvalidMoney, получено %v", err)
+	}
+	if err := New(100, "").Validate(); !errors.Is(err, ErrInvalidMoney) {
+		t.Errorf("без валюты: ожидалась ErrInvalidMoney, получено %v", err)
+	}
+	if err := New(100, "XXX").Validate(); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("неизвестная валюта: ожидалась ErrUnknownCurrency, получено %v", err)
+	}
+	if c, err := ParseCurrency(" usd "); err != nil || c != "USD" {
+		t.Errorf("ParseCurrency: получено %q, %v", c, err)
+	}
+}
+
+func TestRates(t *testing.T) {
+	usd, err := ParseRate("92.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jpy, err := ParseRate("0.61")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := NewRates([]Rate{{Currency: "USD", Micros: usd}, {Currency: "JPY", Micros: jpy}})
+
+	cases := []struct {
+		m    Money
+		to   Currency
+		want int
+	}{
+		{New(1000, "USD"), Base, 92500},  // $10 = 925 ₽
+		{New(92500, "RUB"), "USD", 1000}, // и обратно
+		{New(1000, "JPY"), Base, 61000},  // 1000 ¥ = 610 ₽: у иены нет дробной части
+		{New(1, "USD"), "JPY", 2},        // 0,925 ₽ / 0,61 = 1,516 ¥ -> 2
+		{New(777, "RUB"), Base, 777},
+	}
+	for _, c := range cases {
+		got, err := rates.Convert(c.m, c.to)
+		if err != nil || got.Amount != c.want || got.Currency != c.to {
+			t.Errorf("%+v -> %s: ожидалось %d, получено %+v, %v", c.m, c.to, c.want, got, err)
+		}
+	}
+
+	if _, err := rates.ToBase(New(100, "EUR")); !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("курс не задан: ожидалась ErrRateNotFound, получено %v", err)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	if got, err := ParseRate("0.000001"); err != nil || got != 1 {
+		t.Errorf("минимальный курс: получено %d, %v", got, err)
+	}
+	for _, raw := range []string{"0", "-1", "abc", "0.0000001"} {
+		if _, err := ParseRate(raw); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("%q: ожидалась ErrInvalidRate, получено %v", raw, err)
+		}
+	}
+	if got := FormatRate(92500000); got != "92.5" {
+		t.Errorf("FormatRate: получено %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateScale — во сколько раз курс увеличен для хранения в целых числах: курс 92.5 хранится как 92500000.
+const RateScale = 1_000_000
+
+var (
+	// ErrRateNotFound возвращается, если для валюты не задан курс.
+	ErrRateNotFound = errors.New("курс валюты не задан")
+	// ErrInvalidRate возвращается для нулевого, отрицательного или слишком точного курса.
+	ErrInvalidRate = errors.New("некорректный курс валюты")
+)
+
+// Rate — курс валюты к базовой: сколько единиц Base стоит одна единица Currency, умноженное на RateScale.
+type Rate struct {
+	Currency  Currency  `json:"currency"`
+	Micros    int64     `json:"-"`
+	Rate      string    `json:"rate"` // Курс в десятичной записи, например "92.5"
+	UpdatedBy *int64    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ParseRate разбирает курс в десятичной записи с точностью до RateScale.
+func ParseRate(raw string) (int64, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(raw))
+	if !ok || r.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, raw)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(RateScale))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%w: %q точнее шести знаков или слишком велик", ErrInvalidRate, raw)
+	}
+	return r.Num().Int64(), nil
+}
+
+// FormatRate печатает курс в десятичной записи без лишних нулей: 92500000 -> "92.5".
+func FormatRate(micros int64) string {
+	s := strconv.FormatInt(micros/RateScale, 10)
+	if fraction := micros % RateScale; fraction != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%06d", fraction), "0")
+	}
+	return s
+}
+
+// Rates — таблица курсов на момент расчёта. Курс базовой валюты всегда равен единице.
+type Rates struct {
+	micros map[Currency]int64
+}
+
+// NewRates собирает таблицу курсов.
+func NewRates(rates []Rate) Rates {
+	t := Rates{micros: map[Currency]int64{Base: RateScale}}
+	for _, r := range rates {
+		t.micros[r.Currency] = r.Micros
+	}
+	return t
+}
+
+// Convert пересчитывает сумму в другую валюту по курсам к базовой с учётом разной длины
+// дробной части и округляет половину вверх.
+func (t Rates) Convert(m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	from, ok := t.micros[m.Currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrRateNotFound, m.Currency)
+	}
+	target, ok := t.micros[to]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrRateNotFound, to)
+	}
+
+	// amount * from * 10^exp(to) / (target * 10^exp(from))
+	num := new(big.Int).Mul(big.NewInt(int64(m.Amount)), big.NewInt(from))
+	num.Mul(num, big.NewInt(int64(pow10(to.Exponent()))))
+	den := new(big.Int).Mul(big.NewInt(target), big.NewInt(int64(pow10(m.Currency.Exponent()))))
+	return New(int(roundHalfUp(num, den)), to), nil
+}
+
+// ToBase пересчитывает сумму в базовую валюту.
+func (t Rates) ToBase(m Money) (int, error) {
+	converted, err := t.Convert(m, Base)
+	if err != nil {
+		return 0, err
+	}
+	return converted.Amount, nil
+}
+
+// roundHalfUp делит num на den с округлением половины от нуля.
+func roundHalfUp(num, den *big.Int) int64 {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q.Int64()
+}
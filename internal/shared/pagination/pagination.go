@@ -0,0 +1,123 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MaxLimit — максимальный размер страницы, который может запросить клиент.
+const MaxLimit = 100
+
+var (
+	// ErrInvalidCursor возвращается, если курсор повреждён или выдан для другого порядка сортировки.
+	ErrInvalidCursor = errors.New("некорректный курсор пагинации")
+	// ErrInvalidLimit возвращается при некорректном размере страницы.
+	ErrInvalidLimit = errors.New("некорректный размер страницы")
+)
+
+// Cursor — позиция в упорядоченном списке: ключ сортировки и ID последней выданной строки.
+// Заполняется ровно одно из полей Time, Int, Float — в зависимости от ключа сортировки.
+type Cursor struct {
+	Sort  string     `json:"s,omitempty"` // Порядок сортировки, для которого выдан курсор
+	Time  *time.Time `json:"t,omitempty"`
+	Int   *int64     `json:"i,omitempty"`
+	Float *float64   `json:"f,omitempty"`
+	ID    int64      `json:"id"`
+}
+
+// TimeCursor создаёт курсор для списка, упорядоченного по времени.
+func TimeCursor(sort string, t time.Time, id int64) Cursor {
+	return Cursor{Sort: sort, Time: &t, ID: id}
+}
+
+// IntCursor создаёт курсор для списка, упорядоченного по целочисленному ключу.
+func IntCursor(sort string, v int64, id int64) Cursor {
+	return Cursor{Sort: sort, Int: &v, ID: id}
+}
+
+// FloatCursor создаёт курсор для списка, упорядоченного по вещественному ключу (например, релевантности).
+func FloatCursor(sort string, v float64, id int64) Cursor {
+	return Cursor{Sort: sort, Float: &v, ID: id}
+}
+
+// Encode кодирует курсор в непрозрачную строку для клиента.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode разбирает строку курсора. Пустая строка означает начало списка.
+func Decode(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Request — параметры запроса страницы.
+type Request struct {
+	After *Cursor // nil — первая страница
+	Limit int
+}
+
+// ParseRequest разбирает параметры cursor и limit. Пустой limit заменяется на defaultLimit.
+func ParseRequest(cursor, limit string, defaultLimit int) (Request, error) {
+	after, err := Decode(cursor)
+	if err != nil {
+		return Request{}, err
+	}
+
+	req := Request{After: after, Limit: defaultLimit}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > MaxLimit {
+			return Request{}, fmt.Errorf("%w: ожидается число от 1 до %d, получено %q", ErrInvalidLimit, MaxLimit, limit)
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+// CheckSort проверяет, что курсор выдан для того же порядка сортировки.
+func (r Request) CheckSort(sort string) error {
+	if r.After != nil && r.After.Sort != sort {
+		return fmt.Errorf("%w: курсор выдан для сортировки %q", ErrInvalidCursor, r.After.Sort)
+	}
+	return nil
+}
+
+// FetchLimit — сколько строк запрашивать из хранилища: на одну больше, чтобы узнать, есть ли следующая страница.
+func (r Request) FetchLimit() int {
+	return r.Limit + 1
+}
+
+// Page — страница списка с курсором на следующую.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // Пусто, если это последняя страница
+}
+
+// NewPage собирает страницу из строк, полученных с лимитом r.FetchLimit().
+// cursorOf строит курсор по последнему элементу страницы.
+func NewPage[T any](items []T, r Request, cursorOf func(T) Cursor) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	if len(items) <= r.Limit {
+		return Page[T]{Items: items}
+	}
+	items = items[:r.Limit]
+	return Page[T]{Items: items, NextCursor: cursorOf(items[len(items)-1]).Encode()}
+}
@@ -0,0 +1,59 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	token := TimeCursor("newest", created, 42).Encode()
+
+	req, err := ParseRequest(token, "", 10)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if req.After == nil || req.After.ID != 42 || !req.After.Time.Equal(created) {
+		t.Fatalf("курсор восстановлен неверно: %+v", req.After)
+	}
+	if err := req.CheckSort("newest"); err != nil {
+		t.Fatalf("курсор должен подходить к своей сортировке: %v", err)
+	}
+	if err := req.CheckSort("price_asc"); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("курсор другой сортировки должен отклоняться, получено: %v", err)
+	}
+}
+
+func TestParseRequestRejects(t *testing.T) {
+	if _, err := ParseRequest("не-курсор", "", 10); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("ожидалась ErrInvalidCursor, получено: %v", err)
+	}
+	for _, limit := range []string{"0", "-1", "abc", "101"} {
+		if _, err := ParseRequest("", limit, 10); !errors.Is(err, ErrInvalidLimit) {
+			t.Errorf("limit=%q: ожидалась ErrInvalidLimit, получено: %v", limit, err)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	req := Request{Limit: 2}
+	cursorOf := func(id int64) Cursor { return IntCursor("id", id, id) }
+
+	page := NewPage([]int64{1, 2, 3}, req, cursorOf)
+	if len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("ожидалась неполная выдача с курсором, получено %+v", page)
+	}
+	next, _ := Decode(page.NextCursor)
+	if next.ID != 2 {
+		t.Errorf("курсор должен указывать на последний выданный элемент, получено %d", next.ID)
+	}
+
+	last := NewPage([]int64{4}, req, cursorOf)
+	if last.NextCursor != "" {
+		t.Errorf("у последней страницы не должно быть курсора")
+	}
+	if empty := NewPage[int64](nil, req, cursorOf); empty.Items == nil {
+		t.Errorf("пустая страница должна сериализоваться как [], а не null")
+	}
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// CurrencyConverter определяет интерфейс для пересчёта сумм в базовую валюту площадки.
+type CurrencyConverter interface {
+	// ToBase возвращает сумму в минимальных единицах money.Base или money.ErrRateNotFound.
+	ToBase(ctx context.Context, m money.Money) (int, error)
+}
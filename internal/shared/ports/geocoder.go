@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+)
+
+// Geocoder определяет интерфейс для преобразования адреса в координаты.
+type Geocoder interface {
+	// Geocode возвращает координаты адреса или geo.ErrAddressNotFound.
+	Geocode(ctx context.Context, address string) (geo.Point, error)
+}
@@ -1 +1,129 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/internal/shortener/domain"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// defaultListLimit — размер страницы списка ссылок, если limit не передан.
+const defaultListLimit = 20
+
+// ShortenerHandler отвечает за обработку HTTP-запросов к коротким ссылкам.
+type ShortenerHandler struct {
+	service domain.ShortenerService
+}
+
+// NewShortenerHandler создаёт новый экземпляр ShortenerHandler.
+func NewShortenerHandler(service domain.ShortenerService) *ShortenerHandler {
+	return &ShortenerHandler{service: service}
+}
+
+// CreateLink создаёт короткую ссылку текущего пользователя на задачу, профиль или приглашение.
+func (h *ShortenerHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.CreateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.service.CreateLink(r.Context(), sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, linkErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, link)
+}
+
+// ListLinks возвращает ссылки текущего пользователя с курсорной пагинацией.
+func (h *ShortenerHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), defaultListLimit)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	links, err := h.service.ListLinks(r.Context(), sess.UserID, page)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить ссылки: %w", err), linkErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, links)
+}
+
+// GetLinkStats возвращает владельцу статистику переходов по ссылке за последние days суток.
+func (h *ShortenerHandler) GetLinkStats(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var q domain.StatsQuery
+	if days := r.URL.Query().Get("days"); days != "" {
+		q.Days, err = strconv.Atoi(days)
+		if err != nil {
+			common_errors.NewAppError(w, r, fmt.Errorf("%w: days должен быть числом", domain.ErrInvalidStats), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := h.service.GetStats(r.Context(), sess.UserID, r.PathValue("code"), q)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, linkErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, stats)
+}
+
+// Redirect перенаправляет по короткой ссылке и учитывает переход. Ответ не кэшируется,
+// чтобы каждый переход доходил до сервера.
+func (h *ShortenerHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	target, err := h.service.Resolve(r.Context(), r.PathValue("code"), r.Referer(), r.UserAgent())
+	if err != nil {
+		common_errors.NewAppError(w, r, err, linkErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+func linkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrLinkNotFound), errors.Is(err, domain.ErrTargetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrLinkExpired):
+		return http.StatusGone
+	case errors.Is(err, domain.ErrNotOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidLink), errors.Is(err, domain.ErrInvalidStats), errors.Is(err, pagination.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrAliasTaken):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
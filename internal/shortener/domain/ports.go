@@ -1 +1,32 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+// ShortenerService — интерфейс для бизнес-логики коротких ссылок.
+type ShortenerService interface {
+	CreateLink(ctx context.Context, ownerID int64, req CreateLinkRequest) (*Link, error)
+	ListLinks(ctx context.Context, ownerID int64, page pagination.Request) (pagination.Page[Link], error)
+	// Resolve находит ссылку по коду, учитывает переход и возвращает адрес для перенаправления.
+	Resolve(ctx context.Context, code, referrer, userAgent string) (string, error)
+	GetStats(ctx context.Context, ownerID int64, code string, q StatsQuery) (*LinkStats, error)
+}
+
+// ShortenerRepository — интерфейс для хранения ссылок и переходов по ним.
+type ShortenerRepository interface {
+	// TargetExists проверяет, что задача или пользователь, на которых ведёт ссылка, существуют.
+	TargetExists(ctx context.Context, target TargetType, id int64) (bool, error)
+	// InsertLink сохраняет ссылку. Если код уже занят, возвращает ErrAliasTaken.
+	InsertLink(ctx context.Context, link Link) (*Link, error)
+	GetLinkByCode(ctx context.Context, code string) (*Link, error) // ErrLinkNotFound
+	ListByOwner(ctx context.Context, ownerID int64, page pagination.Request) (pagination.Page[Link], error)
+
+	InsertClick(ctx context.Context, click Click) error
+	// GetStats возвращает переходы по ссылке: всего, по дням начиная с since и самые частые источники и браузеры.
+	// Дни без переходов не возвращаются.
+	GetStats(ctx context.Context, linkID int64, since time.Time, topLimit int) (*LinkStats, error)
+}
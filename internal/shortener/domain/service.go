@@ -1 +1,144 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+// codeAttempts — сколько раз генерировать новый случайный код, если сгенерированный уже занят.
+const codeAttempts = 5
+
+type shortenerService struct {
+	repo     ShortenerRepository
+	settings Settings
+}
+
+// NewShortenerService создаёт сервис коротких ссылок.
+func NewShortenerService(repo ShortenerRepository, settings Settings) ShortenerService {
+	return &shortenerService{repo: repo, settings: settings}
+}
+
+// CreateLink создаёт ссылку владельца на задачу, профиль или приглашение. Без собственного
+// кода генерируется случайный.
+func (s *shortenerService) CreateLink(ctx context.Context, ownerID int64, req CreateLinkRequest) (*Link, error) {
+	now := time.Now()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+	if req.TargetType == TargetReferral {
+		req.TargetID = ownerID
+	} else {
+		ok, err := s.repo.TargetExists(ctx, req.TargetType, req.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrTargetNotFound
+		}
+	}
+
+	link := Link{
+		OwnerID:    ownerID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  now,
+	}
+	if req.Alias != "" {
+		link.Code, link.Custom = req.Alias, true
+		saved, err := s.repo.InsertLink(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+		return s.withURLs(saved), nil
+	}
+
+	for attempt := 0; attempt < codeAttempts; attempt++ {
+		code, err := GenerateCode()
+		if err != nil {
+			return nil, err
+		}
+		link.Code = code
+		saved, err := s.repo.InsertLink(ctx, link)
+		if errors.Is(err, ErrAliasTaken) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.withURLs(saved), nil
+	}
+	return nil, fmt.Errorf("не удалось подобрать свободный код ссылки за %d попыток", codeAttempts)
+}
+
+// ListLinks возвращает ссылки владельца, новые первыми, с числом переходов.
+func (s *shortenerService) ListLinks(ctx context.Context, ownerID int64, page pagination.Request) (pagination.Page[Link], error) {
+	links, err := s.repo.ListByOwner(ctx, ownerID, page)
+	if err != nil {
+		return pagination.Page[Link]{}, err
+	}
+	for i := range links.Items {
+		s.withURLs(&links.Items[i])
+	}
+	return links, nil
+}
+
+// Resolve возвращает адрес перехода по коду и записывает переход. Ошибка записи перехода
+// не мешает перенаправлению.
+func (s *shortenerService) Resolve(ctx context.Context, code, referrer, userAgent string) (string, error) {
+	if !IsValidCode(code) {
+		return "", ErrLinkNotFound
+	}
+	link, err := s.repo.GetLinkByCode(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if link.IsExpired(now) {
+		return "", ErrLinkExpired
+	}
+
+	if err := s.repo.InsertClick(ctx, NewClick(link.ID, now, referrer, userAgent)); err != nil {
+		slog.Error("[Shortener] Не удалось записать переход по ссылке", "code", code, "error", err)
+	}
+	return s.withURLs(link).TargetURL, nil
+}
+
+// GetStats возвращает владельцу статистику переходов по ссылке за последние q.Days суток.
+func (s *shortenerService) GetStats(ctx context.Context, ownerID int64, code string, q StatsQuery) (*LinkStats, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	if !IsValidCode(code) {
+		return nil, ErrLinkNotFound
+	}
+	link, err := s.repo.GetLinkByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if link.OwnerID != ownerID {
+		return nil, ErrNotOwner
+	}
+
+	now := time.Now()
+	since := q.Since(now)
+	stats, err := s.repo.GetStats(ctx, link.ID, since, statsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	stats.Link = *s.withURLs(link)
+	stats.Days = FillDays(since, now, stats.Days)
+	return stats, nil
+}
+
+// withURLs заполняет короткий адрес ссылки и адрес перехода.
+func (s *shortenerService) withURLs(link *Link) *Link {
+	link.ShortURL = s.settings.BaseURL + "/s/" + link.Code
+	link.TargetURL = s.settings.SiteURL + link.TargetPath()
+	return link
+}
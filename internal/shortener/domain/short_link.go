@@ -1 +1,338 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TargetType — на что ведёт короткая ссылка. Адрес перехода строится сервером по типу и ID,
+// поэтому через сокращатель нельзя увести пользователя на сторонний сайт.
+type TargetType string
+
+const (
+	TargetTask     TargetType = "task"     // Страница задачи
+	TargetProfile  TargetType = "profile"  // Профиль пользователя
+	TargetReferral TargetType = "referral" // Регистрация по приглашению владельца ссылки
+)
+
+// IsValid сообщает, является ли тип цели известным.
+func (t TargetType) IsValid() bool {
+	switch t {
+	case TargetTask, TargetProfile, TargetReferral:
+		return true
+	}
+	return false
+}
+
+const (
+	// CodeLength — длина случайного кода ссылки.
+	CodeLength = 7
+	// codeAlphabet — символы случайного кода: цифры и латиница в обоих регистрах.
+	codeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	minAliasLength = 4
+	maxAliasLength = 32
+
+	// MaxLinkTTL — наибольший срок жизни ссылки с ограниченным сроком.
+	MaxLinkTTL = 365 * 24 * time.Hour
+
+	// DefaultStatsDays и MaxStatsDays — за сколько последних дней показывать переходы по дням.
+	DefaultStatsDays = 30
+	MaxStatsDays     = 365
+
+	// statsTopLimit — сколько источников переходов показывать в статистике.
+	statsTopLimit = 20
+)
+
+var (
+	ErrLinkNotFound    = errors.New("ссылка не найдена")
+	ErrLinkExpired     = errors.New("срок действия ссылки истёк")
+	ErrInvalidLink     = errors.New("некорректная ссылка")
+	ErrAliasTaken      = errors.New("такой адрес ссылки уже занят")
+	ErrTargetNotFound  = errors.New("объект, на который ведёт ссылка, не найден")
+	ErrNotOwner        = errors.New("статистика доступна только владельцу ссылки")
+	ErrInvalidStats    = errors.New("некорректный запрос статистики")
+	ErrInvalidSettings = errors.New("некорректные настройки коротких ссылок")
+)
+
+// Settings — адреса, из которых собираются короткие ссылки и адреса перехода.
+// Пустой адрес — ссылки относительные, от корня этого же сервера.
+type Settings struct {
+	BaseURL string // Адрес, к которому добавляется /s/{code}, например https://chgn.do
+	SiteURL string // Адрес сайта, на страницы которого ведут ссылки
+}
+
+// Validate проверяет, что заданные адреса абсолютные, и убирает из них завершающий «/».
+func (s *Settings) Validate() error {
+	for _, addr := range []*string{&s.BaseURL, &s.SiteURL} {
+		*addr = strings.TrimRight(*addr, "/")
+		if *addr == "" {
+			continue
+		}
+		u, err := url.Parse(*addr)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: ожидается адрес вида https://example.com, получено %q", ErrInvalidSettings, *addr)
+		}
+	}
+	return nil
+}
+
+// aliasPattern — допустимый собственный адрес ссылки: строчная латиница, цифры и дефис,
+// не в начале и не в конце.
+var aliasPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+
+// Link — короткая ссылка.
+type Link struct {
+	ID         int64      `json:"id"`
+	Code       string     `json:"code"`
+	OwnerID    int64      `json:"owner_id"`
+	TargetType TargetType `json:"target_type"`
+	TargetID   int64      `json:"target_id"`  // Для приглашений — ID владельца
+	Custom     bool       `json:"custom"`     // Код задан владельцем, а не сгенерирован
+	ExpiresAt  *time.Time `json:"expires_at"` // nil — бессрочная
+	CreatedAt  time.Time  `json:"created_at"`
+	Clicks     int64      `json:"clicks"`     // Переходы без учёта ботов
+	ShortURL   string     `json:"short_url"`  // Заполняет сервис
+	TargetURL  string     `json:"target_url"` // Заполняет сервис
+}
+
+// IsExpired сообщает, истёк ли срок действия ссылки к моменту at.
+func (l *Link) IsExpired(at time.Time) bool {
+	return l.ExpiresAt != nil && !at.Before(*l.ExpiresAt)
+}
+
+// TargetPath возвращает путь страницы сайта, на которую ведёт ссылка.
+func (l *Link) TargetPath() string {
+	switch l.TargetType {
+	case TargetTask:
+		return "/tasks/" + strconv.FormatInt(l.TargetID, 10)
+	case TargetProfile:
+		return "/profile/" + strconv.FormatInt(l.TargetID, 10)
+	case TargetReferral:
+		return "/signup?ref=" + url.QueryEscape(l.Code)
+	}
+	return "/"
+}
+
+// CreateLinkRequest — запрос на создание короткой ссылки.
+type CreateLinkRequest struct {
+	TargetType TargetType `json:"target_type"`
+	TargetID   int64      `json:"target_id"`  // Не нужен для приглашений
+	Alias      string     `json:"alias"`      // Необязательный собственный код
+	ExpiresAt  *time.Time `json:"expires_at"` // Необязательный срок действия
+}
+
+// Validate проверяет запрос и приводит собственный код к нижнему регистру.
+func (r *CreateLinkRequest) Validate(now time.Time) error {
+	if !r.TargetType.IsValid() {
+		return fmt.Errorf("%w: неизвестный тип цели %q", ErrInvalidLink, r.TargetType)
+	}
+	if r.TargetType != TargetReferral && r.TargetID <= 0 {
+		return fmt.Errorf("%w: не указан target_id", ErrInvalidLink)
+	}
+	if r.Alias != "" {
+		r.Alias = strings.ToLower(strings.TrimSpace(r.Alias))
+		if err := ValidateAlias(r.Alias); err != nil {
+			return err
+		}
+	}
+	if r.ExpiresAt != nil {
+		if !r.ExpiresAt.After(now) {
+			return fmt.Errorf("%w: срок действия уже истёк", ErrInvalidLink)
+		}
+		if r.ExpiresAt.Sub(now) > MaxLinkTTL {
+			return fmt.Errorf("%w: срок действия больше года", ErrInvalidLink)
+		}
+	}
+	return nil
+}
+
+// ValidateAlias проверяет собственный код ссылки.
+func ValidateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: адрес должен быть от %d до %d символов", ErrInvalidLink, minAliasLength, maxAliasLength)
+	}
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("%w: адрес может содержать только строчные латинские буквы, цифры и дефис", ErrInvalidLink)
+	}
+	return nil
+}
+
+// IsValidCode сообщает, может ли строка быть кодом ссылки. Позволяет не ходить в базу
+// за заведомо несуществующими кодами.
+func IsValidCode(code string) bool {
+	if len(code) == 0 || len(code) > maxAliasLength {
+		return false
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(codeAlphabet, c) && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// GenerateCode возвращает криптографически случайный код длины CodeLength.
+func GenerateCode() (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	code := make([]byte, CodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("ошибка при генерации кода ссылки: %w", err)
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// UAFamily — семейство браузера, с которого перешли по ссылке.
+type UAFamily string
+
+const (
+	UAChrome  UAFamily = "chrome"
+	UASafari  UAFamily = "safari"
+	UAFirefox UAFamily = "firefox"
+	UAEdge    UAFamily = "edge"
+	UAOpera   UAFamily = "opera"
+	UAYandex  UAFamily = "yandex"
+	UAInApp   UAFamily = "in_app" // Встроенный браузер приложения соцсети или мессенджера
+	UABot     UAFamily = "bot"    // Роботы, в том числе сборщики превью ссылок в соцсетях
+	UAOther   UAFamily = "other"
+)
+
+// uaRules — признаки семейств в User-Agent в порядке проверки: боты и встроенные браузеры
+// притворяются обычными, а Edge, Opera и Яндекс.Браузер — Chrome, поэтому идут раньше.
+var uaRules = []struct {
+	family  UAFamily
+	markers []string
+}{
+	{UABot, []string{"bot", "crawler", "spider", "facebookexternalhit", "vkshare", "whatsapp", "preview", "curl", "python-requests", "go-http-client"}},
+	{UAInApp, []string{"fban", "fbav", "instagram", "vkclient", "okapp", "telegram", "line/"}},
+	{UAYandex, []string{"yabrowser", "yasearchbrowser"}},
+	{UAEdge, []string{"edg/", "edge/", "edga/", "edgios/"}},
+	{UAOpera, []string{"opr/", "opera"}},
+	{UAFirefox, []string{"firefox/", "fxios/"}},
+	{UAChrome, []string{"chrome/", "crios/", "chromium/"}},
+	{UASafari, []string{"safari/"}},
+}
+
+// ClassifyUserAgent определяет семейство браузера по заголовку User-Agent.
+func ClassifyUserAgent(ua string) UAFamily {
+	ua = strings.ToLower(ua)
+	if ua == "" {
+		return UAOther
+	}
+	for _, rule := range uaRules {
+		for _, m := range rule.markers {
+			if strings.Contains(ua, m) {
+				return rule.family
+			}
+		}
+	}
+	return UAOther
+}
+
+// maxReferrerLength — длина, до которой обрезается сохраняемый источник перехода.
+const maxReferrerLength = 255
+
+// ReferrerHost возвращает домен источника перехода без «www.» и «m.»; полный адрес не
+// хранится, потому что может содержать личные данные. Пустая строка — прямой переход.
+func ReferrerHost(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if len(host) > maxReferrerLength {
+		host = host[:maxReferrerLength]
+	}
+	return host
+}
+
+// Click — переход по ссылке.
+type Click struct {
+	LinkID       int64
+	At           time.Time
+	ReferrerHost string
+	UAFamily     UAFamily
+}
+
+// NewClick описывает переход по заголовкам запроса.
+func NewClick(linkID int64, at time.Time, referrer, userAgent string) Click {
+	return Click{
+		LinkID:       linkID,
+		At:           at,
+		ReferrerHost: ReferrerHost(referrer),
+		UAFamily:     ClassifyUserAgent(userAgent),
+	}
+}
+
+// DayClicks — число переходов за сутки (UTC).
+type DayClicks struct {
+	Day    time.Time `json:"day"`
+	Clicks int64     `json:"clicks"`
+}
+
+// Bucket — число переходов с одним значением признака.
+type Bucket struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// LinkStats — статистика переходов по ссылке за период. Переходы ботов, в том числе сборщиков
+// превью соцсетей, считаются отдельно и не входят в остальные показатели.
+type LinkStats struct {
+	Link      Link        `json:"link"` // Link.Clicks — переходы за всё время
+	Clicks    int64       `json:"clicks"`
+	BotClicks int64       `json:"bot_clicks"`
+	Days      []DayClicks `json:"days"`      // Каждый день периода, включая дни без переходов
+	Referrers []Bucket    `json:"referrers"` // Пустое значение — прямые переходы
+	Browsers  []Bucket    `json:"browsers"`
+}
+
+// StatsQuery — запрос статистики за последние Days суток, включая текущие.
+type StatsQuery struct {
+	Days int
+}
+
+// Validate подставляет период по умолчанию и проверяет его.
+func (q *StatsQuery) Validate() error {
+	if q.Days == 0 {
+		q.Days = DefaultStatsDays
+	}
+	if q.Days < 1 || q.Days > MaxStatsDays {
+		return fmt.Errorf("%w: days должен быть от 1 до %d", ErrInvalidStats, MaxStatsDays)
+	}
+	return nil
+}
+
+// Since возвращает начало первых суток периода по UTC.
+func (q StatsQuery) Since(now time.Time) time.Time {
+	today := now.UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -(q.Days - 1))
+}
+
+// FillDays возвращает переходы за каждые сутки с since по now, подставляя нули за дни без переходов.
+func FillDays(since, now time.Time, counted []DayClicks) []DayClicks {
+	byDay := make(map[time.Time]int64, len(counted))
+	for _, d := range counted {
+		byDay[d.Day.UTC().Truncate(24*time.Hour)] += d.Clicks
+	}
+	var days []DayClicks
+	last := now.UTC().Truncate(24 * time.Hour)
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, DayClicks{Day: day, Clicks: byDay[day]})
+	}
+	return days
+}
@@ -1 +1,227 @@
-package domain
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+func TestCreateLinkRequestValidate(t *testing.T) {
+	now := time.Now()
+	past, tooFar := now.Add(-time.Minute), now.Add(MaxLinkTTL+time.Hour)
+
+	cases := map[string]CreateLinkRequest{
+		"неизвестный тип":           {TargetType: "url", TargetID: 1},
+		"задача без id":             {TargetType: TargetTask},
+		"короткий адрес":            {TargetType: TargetProfile, TargetID: 1, Alias: "abc"},
+		"адрес с недопустимыми":     {TargetType: TargetProfile, TargetID: 1, Alias: "мой-профиль"},
+		"адрес с дефисом на краю":   {TargetType: TargetProfile, TargetID: 1, Alias: "-ivan"},
+		"истёкший срок":             {TargetType: TargetTask, TargetID: 1, ExpiresAt: &past},
+		"срок дольше максимального": {TargetType: TargetTask, TargetID: 1, ExpiresAt: &tooFar},
+	}
+	for name, req := range cases {
+		if err := req.Validate(now); !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("%s: ожидалась ErrInvalidLink, получено %v", name, err)
+		}
+	}
+
+	req := CreateLinkRequest{TargetType: TargetReferral, Alias: " Ivan-Plumber "}
+	if err := req.Validate(now); err != nil {
+		t.Fatalf("приглашение без target_id допустимо: %v", err)
+	}
+	if req.Alias != "ivan-plumber" {
+		t.Errorf("адрес должен приводиться к нижнему регистру, получено %q", req.Alias)
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := GenerateCode()
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(code) != CodeLength || !IsValidCode(code) {
+			t.Fatalf("некорректный код %q", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 99 {
+		t.Errorf("коды должны быть случайными, уникальных %d из 100", len(seen))
+	}
+	if IsValidCode("../admin") || IsValidCode("") {
+		t.Error("коды с посторонними символами и пустые недопустимы")
+	}
+}
+
+func TestClassifyUserAgent(t *testing.T) {
+	cases := map[string]UAFamily{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36":               UAChrome,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0":     UAEdge,
+		"Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 YaBrowser/23.11 Safari/537.36":           UAYandex,
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/604.1": UASafari,
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0":                                                    UAFirefox,
+		"Mozilla/5.0 (Linux; Android 13) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36 Instagram 312.0":                      UAInApp,
+		"TelegramBot (like TwitterBot)": UABot,
+		"facebookexternalhit/1.1":       UABot,
+		"":                              UAOther,
+		"SomeUnknownClient/1.0":         UAOther,
+	}
+	for ua, want := range cases {
+		if got := ClassifyUserAgent(ua); got != want {
+			t.Errorf("%q: ожидалось %q, получено %q", ua, want, got)
+		}
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	cases := map[string]string{
+		"https://www.vk.com/wall-1_2?utm_source=x": "vk.com",
+		"https://m.facebook.com/story.php?id=1":    "facebook.com",
+		"http://T.ME/channel":                      "t.me",
+		"":                                         "",
+		"android-app://org.telegram.messenger":     "",
+		"not a url":                                "",
+	}
+	for referrer, want := range cases {
+		if got := ReferrerHost(referrer); got != want {
+			t.Errorf("%q: ожидалось %q, получено %q", referrer, want, got)
+		}
+	}
+}
+
+func TestFillDays(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	q := StatsQuery{Days: 3}
+	since := q.Since(now)
+	if want := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC); !since.Equal(want) {
+		t.Fatalf("начало периода: ожидалось %v, получено %v", want, since)
+	}
+
+	days := FillDays(since, now, []DayClicks{{Day: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Clicks: 4}})
+	if len(days) != 3 || days[0].Clicks != 0 || days[1].Clicks != 4 || days[2].Clicks != 0 {
+		t.Errorf("дни без переходов должны заполняться нулями: %+v", days)
+	}
+}
+
+type fakeRepo struct {
+	links   map[string]*Link
+	clicks  []Click
+	taken   int // Сколько следующих вставок вернут ErrAliasTaken
+	targets map[int64]bool
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{links: make(map[string]*Link), targets: map[int64]bool{7: true}}
+}
+
+func (f *fakeRepo) TargetExists(ctx context.Context, target TargetType, id int64) (bool, error) {
+	return f.targets[id], nil
+}
+
+func (f *fakeRepo) InsertLink(ctx context.Context, link Link) (*Link, error) {
+	if f.taken > 0 {
+		f.taken--
+		return nil, ErrAliasTaken
+	}
+	if _, ok := f.links[link.Code]; ok {
+		return nil, ErrAliasTaken
+	}
+	link.ID = int64(len(f.links) + 1)
+	f.links[link.Code] = &link
+	saved := link
+	return &saved, nil
+}
+
+func (f *fakeRepo) GetLinkByCode(ctx context.Context, code string) (*Link, error) {
+	link, ok := f.links[code]
+	if !ok {
+		return nil, ErrLinkNotFound
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListByOwner(ctx context.Context, ownerID int64, page pagination.Request) (pagination.Page[Link], error) {
+	return pagination.Page[Link]{}, nil
+}
+
+func (f *fakeRepo) InsertClick(ctx context.Context, click Click) error {
+	f.clicks = append(f.clicks, click)
+	return nil
+}
+
+func (f *fakeRepo) GetStats(ctx context.Context, linkID int64, since time.Time, topLimit int) (*LinkStats, error) {
+	return &LinkStats{}, nil
+}
+
+func TestShortenerServiceCreateLink(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewShortenerService(repo, Settings{BaseURL: "https://chgn.do", SiteURL: "https://chegonado.ru"})
+	ctx := context.Background()
+
+	repo.taken = 2
+	link, err := svc.CreateLink(ctx, 1, CreateLinkRequest{TargetType: TargetTask, TargetID: 7})
+	if err != nil {
+		t.Fatalf("занятый случайный код должен генерироваться заново: %v", err)
+	}
+	if link.Custom || link.ShortURL != "https://chgn.do/s/"+link.Code || link.TargetURL != "https://chegonado.ru/tasks/7" {
+		t.Errorf("неверные адреса ссылки: %+v", link)
+	}
+
+	if _, err := svc.CreateLink(ctx, 1, CreateLinkRequest{TargetType: TargetTask, TargetID: 8}); !errors.Is(err, ErrTargetNotFound) {
+		t.Errorf("ссылка на несуществующую задачу: ожидалась ErrTargetNotFound, получено %v", err)
+	}
+
+	ref, err := svc.CreateLink(ctx, 5, CreateLinkRequest{TargetType: TargetReferral, TargetID: 7, Alias: "Ivan"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if ref.TargetID != 5 || !ref.Custom || ref.TargetURL != "https://chegonado.ru/signup?ref=ivan" {
+		t.Errorf("приглашение должно вести на регистрацию от имени владельца: %+v", ref)
+	}
+	if _, err := svc.CreateLink(ctx, 6, CreateLinkRequest{TargetType: TargetProfile, TargetID: 7, Alias: "ivan"}); !errors.Is(err, ErrAliasTaken) {
+		t.Errorf("занятый собственный адрес: ожидалась ErrAliasTaken, получено %v", err)
+	}
+}
+
+func TestShortenerServiceResolve(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewShortenerService(repo, Settings{})
+	ctx := context.Background()
+
+	link, err := svc.CreateLink(ctx, 1, CreateLinkRequest{TargetType: TargetProfile, TargetID: 7, Alias: "master-7"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	target, err := svc.Resolve(ctx, link.Code, "https://www.vk.com/feed", "Mozilla/5.0 Firefox/121.0")
+	if err != nil || target != "/profile/7" {
+		t.Fatalf("переход по ссылке: %q, %v", target, err)
+	}
+	if len(repo.clicks) != 1 || repo.clicks[0].ReferrerHost != "vk.com" || repo.clicks[0].UAFamily != UAFirefox {
+		t.Errorf("переход записан неверно: %+v", repo.clicks)
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	repo.links[link.Code].ExpiresAt = &expired
+	if _, err := svc.Resolve(ctx, link.Code, "", ""); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("истёкшая ссылка: ожидалась ErrLinkExpired, получено %v", err)
+	}
+	if _, err := svc.Resolve(ctx, "nope", "", ""); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("несуществующий код: ожидалась ErrLinkNotFound, получено %v", err)
+	}
+	if len(repo.clicks) != 1 {
+		t.Errorf("переходы по недействующим ссылкам не учитываются: %d", len(repo.clicks))
+	}
+
+	if _, err := svc.GetStats(ctx, 2, link.Code, StatsQuery{}); !errors.Is(err, ErrNotOwner) {
+		t.Errorf("чужая статистика: ожидалась ErrNotOwner, получено %v", err)
+	}
+	stats, err := svc.GetStats(ctx, 1, link.Code, StatsQuery{})
+	if err != nil || len(stats.Days) != DefaultStatsDays {
+		t.Errorf("статистика владельца за период по умолчанию: %v, дней %d", err, len(stats.Days))
+	}
+}
@@ -1 +1,218 @@
-package infra
\ No newline at end of file
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/internal/shortener/domain"
+)
+
+// linksListSort — порядок списка ссылок владельца, для которого выдаются курсоры.
+const linksListSort = "newest"
+
+// linkColumns — поля ссылки в порядке, который ожидает scanLink.
+const linkColumns = `id, code, owner_id, target_type, target_id, custom, expires_at, created_at, clicks`
+
+// humanClick — условие, отсекающее переходы ботов.
+const humanClick = `ua_family <> 'bot'`
+
+// ShortenerRepository хранит короткие ссылки и переходы по ним в PostgreSQL.
+type ShortenerRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewShortenerRepository создаёт новый репозиторий коротких ссылок.
+func NewShortenerRepository(db *pgxpool.Pool) *ShortenerRepository {
+	return &ShortenerRepository{db: db}
+}
+
+// TargetExists проверяет, что задача или пользователь существуют.
+func (r *ShortenerRepository) TargetExists(ctx context.Context, target domain.TargetType, id int64) (bool, error) {
+	var query string
+	switch target {
+	case domain.TargetTask:
+		query = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`
+	case domain.TargetProfile, domain.TargetReferral:
+		query = `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
+	default:
+		return false, fmt.Errorf("неизвестный тип цели ссылки %q", target)
+	}
+
+	var ok bool
+	if err := r.db.QueryRow(ctx, query, id).Scan(&ok); err != nil {
+		return false, fmt.Errorf("ошибка при проверке цели ссылки %s %d: %w", target, id, err)
+	}
+	return ok, nil
+}
+
+// InsertLink сохраняет ссылку; занятый код возвращает ErrAliasTaken.
+func (r *ShortenerRepository) InsertLink(ctx context.Context, link domain.Link) (*domain.Link, error) {
+	saved, err := scanLink(r.db.QueryRow(ctx, `
+        INSERT INTO short_links (code, owner_id, target_type, target_id, custom, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING `+linkColumns,
+		link.Code, link.OwnerID, link.TargetType, link.TargetID, link.Custom, utcPtr(link.ExpiresAt), link.CreatedAt.UTC()))
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return nil, domain.ErrAliasTaken
+		}
+		return nil, fmt.Errorf("не удалось создать ссылку для пользователя %d: %w", link.OwnerID, err)
+	}
+	return &saved, nil
+}
+
+// GetLinkByCode возвращает ссылку по коду.
+func (r *ShortenerRepository) GetLinkByCode(ctx context.Context, code string) (*domain.Link, error) {
+	link, err := scanLink(r.db.QueryRow(ctx, `SELECT `+linkColumns+` FROM short_links WHERE code = $1`, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении ссылки %q: %w", code, err)
+	}
+	return &link, nil
+}
+
+// ListByOwner возвращает ссылки владельца, новые первыми, с курсорной пагинацией.
+func (r *ShortenerRepository) ListByOwner(ctx context.Context, ownerID int64, page pagination.Request) (pagination.Page[domain.Link], error) {
+	args := []interface{}{ownerID, page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil || page.After.Sort != linksListSort {
+			return pagination.Page[domain.Link]{}, pagination.ErrInvalidCursor
+		}
+		keyset = "AND (created_at, id) < ($3, $4)"
+		args = append(args, *page.After.Time, page.After.ID)
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+linkColumns+` FROM short_links
+        WHERE owner_id = $1 `+keyset+`
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2`, args...)
+	if err != nil {
+		return pagination.Page[domain.Link]{}, fmt.Errorf("ошибка при получении ссылок пользователя %d: %w", ownerID, err)
+	}
+	defer rows.Close()
+
+	var links []domain.Link
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return pagination.Page[domain.Link]{}, fmt.Errorf("ошибка при сканировании ссылки: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[domain.Link]{}, fmt.Errorf("ошибка при обработке строк результата: %w", err)
+	}
+	return pagination.NewPage(links, page, func(l domain.Link) pagination.Cursor {
+		return pagination.TimeCursor(linksListSort, l.CreatedAt, l.ID)
+	}), nil
+}
+
+// InsertClick записывает переход и, если он не от бота, увеличивает счётчик ссылки в том же запросе.
+func (r *ShortenerRepository) InsertClick(ctx context.Context, click domain.Click) error {
+	_, err := r.db.Exec(ctx, `
+        WITH click AS (
+            INSERT INTO short_link_clicks (link_id, clicked_at, referrer_host, ua_family)
+            VALUES ($1, $2, $3, $4)
+        )
+        UPDATE short_links SET clicks = clicks + 1
+        WHERE id = $1 AND $4::text <> 'bot'`,
+		click.LinkID, click.At.UTC(), click.ReferrerHost, string(click.UAFamily))
+	if err != nil {
+		return fmt.Errorf("ошибка при записи перехода по ссылке %d: %w", click.LinkID, err)
+	}
+	return nil
+}
+
+// GetStats считает переходы по ссылке начиная с since: всего, от ботов, по суткам (UTC)
+// и до topLimit самых частых источников и браузеров.
+func (r *ShortenerRepository) GetStats(ctx context.Context, linkID int64, since time.Time, topLimit int) (*domain.LinkStats, error) {
+	since = since.UTC()
+	stats := &domain.LinkStats{}
+	err := r.db.QueryRow(ctx, `
+        SELECT COUNT(*) FILTER (WHERE `+humanClick+`), COUNT(*) FILTER (WHERE NOT `+humanClick+`)
+        FROM short_link_clicks
+        WHERE link_id = $1 AND clicked_at >= $2`, linkID, since).Scan(&stats.Clicks, &stats.BotClicks)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте переходов по ссылке %d: %w", linkID, err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT date_trunc('day', clicked_at), COUNT(*)
+        FROM short_link_clicks
+        WHERE link_id = $1 AND clicked_at >= $2 AND `+humanClick+`
+        GROUP BY 1
+        ORDER BY 1`, linkID, since)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении переходов по дням для ссылки %d: %w", linkID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d domain.DayClicks
+		if err := rows.Scan(&d.Day, &d.Clicks); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании переходов за день: %w", err)
+		}
+		stats.Days = append(stats.Days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк результата: %w", err)
+	}
+
+	if stats.Referrers, err = r.topBuckets(ctx, "referrer_host", linkID, since, topLimit); err != nil {
+		return nil, err
+	}
+	if stats.Browsers, err = r.topBuckets(ctx, "ua_family", linkID, since, topLimit); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// topBuckets группирует переходы людей по колонке column и возвращает самые частые значения.
+func (r *ShortenerRepository) topBuckets(ctx context.Context, column string, linkID int64, since time.Time, limit int) ([]domain.Bucket, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+column+`, COUNT(*) AS n
+        FROM short_link_clicks
+        WHERE link_id = $1 AND clicked_at >= $2 AND `+humanClick+`
+        GROUP BY 1
+        ORDER BY n DESC, 1
+        LIMIT $3`, linkID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при группировке переходов по ссылке %d по %s: %w", linkID, column, err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.Bucket{}
+	for rows.Next() {
+		var b domain.Bucket
+		if err := rows.Scan(&b.Value, &b.Clicks); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании переходов по %s: %w", column, err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func scanLink(row pgx.Row) (domain.Link, error) {
+	var link domain.Link
+	err := row.Scan(&link.ID, &link.Code, &link.OwnerID, &link.TargetType, &link.TargetID, &link.Custom,
+		&link.ExpiresAt, &link.CreatedAt, &link.Clicks)
+	return link, err
+}
+
+// utcPtr приводит время к UTC: колонки без часового пояса хранят время в UTC.
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	payments "github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/utils"
+	"github.com/unclaim/chegonado.git/internal/subscriptions/domain"
+	"github.com/unclaim/chegonado.git/pkg/security/session"
+)
+
+// SubscriptionsHandler отвечает за обработку HTTP-запросов, связанных с подпиской Pro.
+type SubscriptionsHandler struct {
+	service domain.SubscriptionsService
+}
+
+// NewSubscriptionsHandler создаёт новый экземпляр SubscriptionsHandler.
+func NewSubscriptionsHandler(service domain.SubscriptionsService) *SubscriptionsHandler {
+	return &SubscriptionsHandler{service: service}
+}
+
+// planRequest — тело запроса на оформление подписки или смену тарифа.
+type planRequest struct {
+	Plan string `json:"plan"`
+}
+
+// ListPlans возвращает доступные тарифы Pro.
+func (h *SubscriptionsHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	utils.NewResponse(w, http.StatusOK, h.service.ListPlans())
+}
+
+// GetSubscription возвращает подписку текущего пользователя.
+func (h *SubscriptionsHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	subscription, err := h.service.GetSubscription(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, subscription)
+}
+
+// Subscribe оформляет подписку на тариф и списывает его цену.
+func (h *SubscriptionsHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	sess, plan, ok := planChangeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.Subscribe(r.Context(), sess.UserID, plan)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, subscription)
+}
+
+// ChangePlan переводит подписку на другой тариф с перерасчётом.
+func (h *SubscriptionsHandler) ChangePlan(w http.ResponseWriter, r *http.Request) {
+	sess, plan, ok := planChangeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.ChangePlan(r.Context(), sess.UserID, plan)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, subscription)
+}
+
+// Cancel отменяет подписку в конце оплаченного периода.
+func (h *SubscriptionsHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	subscription, err := h.service.Cancel(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, subscription)
+}
+
+// Resume отменяет запланированную отмену подписки.
+func (h *SubscriptionsHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	subscription, err := h.service.Resume(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, subscription)
+}
+
+// ListCharges возвращает историю списаний по подписке.
+func (h *SubscriptionsHandler) ListCharges(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	charges, err := h.service.ListCharges(r.Context(), sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, err, subscriptionErrorStatus(err))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, charges)
+}
+
+// planChangeRequest извлекает сессию и код тарифа из тела запроса.
+func planChangeRequest(w http.ResponseWriter, r *http.Request) (*session.Session, string, bool) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return nil, "", false
+	}
+
+	var req planRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("неверный формат запроса: %w", err), http.StatusBadRequest)
+		return nil, "", false
+	}
+	return sess, req.Plan, true
+}
+
+func subscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPlanNotFound), errors.Is(err, domain.ErrSubscriptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadySubscribed), errors.Is(err, domain.ErrSubscriptionState),
+		errors.Is(err, domain.ErrSubscriptionConflict):
+		return http.StatusConflict
+	case errors.Is(err, payments.ErrGatewayDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, payments.ErrGatewayUnavailable):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	payments "github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+// SubscriptionsService — интерфейс для бизнес-логики подписок Pro.
+type SubscriptionsService interface {
+	ListPlans() []Plan
+	GetSubscription(ctx context.Context, userID int64) (*Subscription, error)
+	Subscribe(ctx context.Context, userID int64, planCode string) (*Subscription, error)
+	// ChangePlan переводит подписку на другой тариф с зачётом неиспользованной части текущего периода.
+	ChangePlan(ctx context.Context, userID int64, planCode string) (*Subscription, error)
+	Cancel(ctx context.Context, userID int64) (*Subscription, error) // Подписка действует до конца оплаченного периода
+	Resume(ctx context.Context, userID int64) (*Subscription, error) // Отменяет запланированную отмену
+	ListCharges(ctx context.Context, userID int64) ([]Charge, error)
+
+	// ProcessDue продлевает подписки, у которых закончился период, и снимает Pro с неоплаченных.
+	// Возвращает число обработанных подписок.
+	ProcessDue(ctx context.Context, now time.Time) (int, error)
+	RunRenewals(ctx context.Context, interval time.Duration)
+}
+
+// SubscriptionsRepository — интерфейс для хранения подписок.
+type SubscriptionsRepository interface {
+	GetSubscription(ctx context.Context, userID int64) (*Subscription, error) // ErrSubscriptionNotFound
+	// SaveSubscription сохраняет подписку, флаги пользователя и списание в одной транзакции.
+	// Если версия подписки в базе отличается от s.Version, возвращает ErrSubscriptionConflict.
+	SaveSubscription(ctx context.Context, s Subscription, flags Entitlements, charge *Charge) (*Subscription, error)
+	// ListDue возвращает подписки, требующие продления или окончания к моменту now.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	ListCharges(ctx context.Context, userID int64, limit int) ([]Charge, error) // Новые первыми
+}
+
+// Gateway — платёжный шлюз, через который списывается оплата подписки.
+type Gateway interface {
+	Charge(ctx context.Context, req payments.ChargeRequest) (string, error)
+}
+
+// Ledger — главная книга, в которой учитывается оплата подписок.
+type Ledger interface {
+	Post(ctx context.Context, entries ...ledger.Entry) error
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
+}
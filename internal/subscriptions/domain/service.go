@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	payments "github.com/unclaim/chegonado.git/internal/payments/domain"
+	"github.com/unclaim/chegonado.git/internal/subscriptions"
+)
+
+// chargesLimit — сколько списаний показывать в истории.
+const chargesLimit = 100
+
+// dueBatchSize — сколько подписок обрабатывать за один проход планировщика.
+const dueBatchSize = 100
+
+// renewalRetryInterval — как часто повторять списание за продление в льготный период.
+const renewalRetryInterval = 24 * time.Hour
+
+// Settings — настройки сервиса подписок.
+type Settings struct {
+	Plans       Catalog
+	GracePeriod time.Duration // Сколько Pro действует после неудачного продления
+}
+
+type subscriptionsService struct {
+	repo     SubscriptionsRepository
+	gateway  Gateway
+	ledger   Ledger
+	bus      EventBus
+	settings Settings
+}
+
+// NewSubscriptionsService создаёт сервис подписок Pro.
+func NewSubscriptionsService(repo SubscriptionsRepository, gateway Gateway, ledger Ledger, bus EventBus, settings Settings) SubscriptionsService {
+	return &subscriptionsService{repo: repo, gateway: gateway, ledger: ledger, bus: bus, settings: settings}
+}
+
+// ListPlans возвращает доступные тарифы.
+func (s *subscriptionsService) ListPlans() []Plan {
+	return s.settings.Plans
+}
+
+// GetSubscription возвращает подписку пользователя.
+func (s *subscriptionsService) GetSubscription(ctx context.Context, userID int64) (*Subscription, error) {
+	return s.repo.GetSubscription(ctx, userID)
+}
+
+// Subscribe оформляет подписку: списывает цену тарифа за вычетом остатка и включает Pro.
+// Закончившаяся подписка оформляется заново с новым периодом.
+func (s *subscriptionsService) Subscribe(ctx context.Context, userID int64, planCode string) (*Subscription, error) {
+	plan, err := s.settings.Plans.Find(planCode)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.repo.GetSubscription(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, ErrSubscriptionNotFound) {
+			return nil, err
+		}
+		sub = &Subscription{UserID: userID}
+	}
+	if sub.Entitled() {
+		return nil, ErrAlreadySubscribed
+	}
+
+	now := time.Now()
+	due, credit := ApplyCredit(plan.Price, sub.Credit)
+	charge, err := s.charge(ctx, *sub, plan, ChargeStart, due, now)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *sub
+	next.PlanCode = plan.Code
+	next.Status = StatusActive
+	next.CurrentPeriodStart = now
+	next.CurrentPeriodEnd = plan.PeriodEnd(now)
+	next.CancelAtPeriodEnd = false
+	next.GraceUntil = nil
+	next.Credit = credit
+	next.RenewalAttempts = 0
+	next.EndedAt = nil
+	return s.save(ctx, next, plan, charge, ActionStarted, now)
+}
+
+// ChangePlan переводит подписку на другой тариф. Неиспользованная часть текущего периода
+// засчитывается в цену нового тарифа, и новый период начинается сразу.
+func (s *subscriptionsService) ChangePlan(ctx context.Context, userID int64, planCode string) (*Subscription, error) {
+	to, err := s.settings.Plans.Find(planCode)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.repo.GetSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status != StatusActive {
+		return nil, fmt.Errorf("%w: сменить тариф можно только у оплаченной подписки", ErrSubscriptionState)
+	}
+	if sub.PlanCode == to.Code {
+		return nil, fmt.Errorf("%w: подписка уже на тарифе %s", ErrSubscriptionState, to.Code)
+	}
+	from, err := s.settings.Plans.Find(sub.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	due, credit := Prorate(*sub, from, to, now)
+	charge, err := s.charge(ctx, *sub, to, ChargeChange, due, now)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *sub
+	next.PlanCode = to.Code
+	next.CurrentPeriodStart = now
+	next.CurrentPeriodEnd = to.PeriodEnd(now)
+	next.CancelAtPeriodEnd = false
+	next.Credit = credit
+	return s.save(ctx, next, to, charge, ActionPlanChanged, now)
+}
+
+// Cancel отменяет подписку в конце оплаченного периода. Подписка в льготном периоде
+// ничего не оплатила вперёд, поэтому заканчивается сразу.
+func (s *subscriptionsService) Cancel(ctx context.Context, userID int64) (*Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := s.planOf(*sub)
+	now := time.Now()
+
+	switch {
+	case sub.Status == StatusPastDue:
+		return s.end(ctx, *sub, plan, StatusCancelled, ActionCancelled, now)
+	case sub.Status == StatusActive && !sub.CancelAtPeriodEnd:
+		next := *sub
+		next.CancelAtPeriodEnd = true
+		return s.save(ctx, next, plan, nil, ActionCancelScheduled, now)
+	}
+	return nil, fmt.Errorf("%w: подписка не действует или уже отменена", ErrSubscriptionState)
+}
+
+// Resume отменяет запланированную отмену, пока оплаченный период не закончился.
+func (s *subscriptionsService) Resume(ctx context.Context, userID int64) (*Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status != StatusActive || !sub.CancelAtPeriodEnd {
+		return nil, fmt.Errorf("%w: отмена не запланирована", ErrSubscriptionState)
+	}
+	next := *sub
+	next.CancelAtPeriodEnd = false
+	return s.save(ctx, next, s.planOf(*sub), nil, ActionResumed, time.Now())
+}
+
+// ListCharges возвращает историю списаний пользователя.
+func (s *subscriptionsService) ListCharges(ctx context.Context, userID int64) ([]Charge, error) {
+	return s.repo.ListCharges(ctx, userID, chargesLimit)
+}
+
+// ProcessDue обрабатывает подписки, у которых закончился период или льготный срок.
+// Ошибка одной подписки не останавливает обработку остальных: она попадёт в следующий проход.
+func (s *subscriptionsService) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.repo.ListDue(ctx, now, dueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for _, sub := range due {
+		if err := s.process(ctx, sub, now); err != nil {
+			slog.Error("[Subscriptions] Не удалось обработать подписку", "subscription_id", sub.ID, "user_id", sub.UserID, "error", err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// RunRenewals периодически продлевает подписки и снимает Pro с неоплаченных, пока не отменён ctx.
+func (s *subscriptionsService) RunRenewals(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.ProcessDue(ctx, now); err != nil {
+				slog.Error("[Subscriptions] Ошибка продления подписок", "error", err)
+			}
+		}
+	}
+}
+
+// process продлевает подписку или заканчивает её. Тариф, убранный из настроек, не продлевается.
+func (s *subscriptionsService) process(ctx context.Context, sub Subscription, now time.Time) error {
+	plan, planErr := s.settings.Plans.Find(sub.PlanCode)
+	switch {
+	case sub.Status == StatusActive && (sub.CancelAtPeriodEnd || planErr != nil):
+		_, err := s.end(ctx, sub, plan, StatusCancelled, ActionCancelled, now)
+		return err
+	case sub.Status == StatusPastDue && (planErr != nil || sub.GraceUntil == nil || !now.Before(*sub.GraceUntil)):
+		_, err := s.end(ctx, sub, plan, StatusExpired, ActionExpired, now)
+		return err
+	case sub.Status == StatusPastDue && now.Before(sub.UpdatedAt.Add(renewalRetryInterval)):
+		return nil
+	}
+	return s.renew(ctx, sub, plan, now)
+}
+
+// renew списывает оплату следующего периода. Отказ шлюза переводит подписку в льготный период,
+// любой другой сбой оставляет её как есть до следующего прохода.
+func (s *subscriptionsService) renew(ctx context.Context, sub Subscription, plan Plan, now time.Time) error {
+	due, credit := ApplyCredit(plan.Price, sub.Credit)
+	charge, err := s.charge(ctx, sub, plan, ChargeRenewal, due, now)
+	if err != nil {
+		if !errors.Is(err, payments.ErrGatewayDeclined) {
+			return err
+		}
+		next := sub
+		next.RenewalAttempts++
+		if sub.Status == StatusPastDue {
+			// Повторный отказ в льготный период не меняет состояния, событие не нужно.
+			next.UpdatedAt = now
+			_, err := s.repo.SaveSubscription(ctx, next, EntitlementsFor(next, plan), nil)
+			return err
+		}
+		grace := sub.CurrentPeriodEnd.Add(s.settings.GracePeriod)
+		next.Status = StatusPastDue
+		next.GraceUntil = &grace
+		_, err = s.save(ctx, next, plan, nil, ActionPastDue, now)
+		return err
+	}
+
+	// Новый период продолжает оплаченный, но если планировщик долго не работал,
+	// период отсчитывается от момента продления, а не списывается задним числом.
+	start := sub.CurrentPeriodEnd
+	if !plan.PeriodEnd(start).After(now) {
+		start = now
+	}
+	next := sub
+	next.Status = StatusActive
+	next.CurrentPeriodStart = start
+	next.CurrentPeriodEnd = plan.PeriodEnd(start)
+	next.GraceUntil = nil
+	next.RenewalAttempts = 0
+	next.Credit = credit
+	_, err = s.save(ctx, next, plan, charge, ActionRenewed, now)
+	return err
+}
+
+// end заканчивает подписку и снимает Pro.
+func (s *subscriptionsService) end(ctx context.Context, sub Subscription, plan Plan, status Status, action string, now time.Time) (*Subscription, error) {
+	next := sub
+	next.Status = status
+	next.CancelAtPeriodEnd = false
+	next.GraceUntil = nil
+	next.EndedAt = &now
+	return s.save(ctx, next, plan, nil, action, now)
+}
+
+// charge списывает amount через шлюз и проводит оплату по главной книге.
+// Нулевая сумма, полностью покрытая остатком, не списывается.
+func (s *subscriptionsService) charge(ctx context.Context, sub Subscription, plan Plan, kind ChargeKind, amount int, now time.Time) (*Charge, error) {
+	if amount == 0 {
+		return nil, nil
+	}
+	c := Charge{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		Kind:           kind,
+		PlanCode:       plan.Code,
+		Amount:         amount,
+		Currency:       plan.Currency,
+		IdempotencyKey: ChargeKey(sub, kind),
+		CreatedAt:      now,
+	}
+	ref, err := s.gateway.Charge(ctx, payments.ChargeRequest{
+		IdempotencyKey: c.IdempotencyKey,
+		CustomerID:     c.UserID,
+		Amount:         c.Amount,
+		Currency:       c.Currency,
+		Description:    fmt.Sprintf("Подписка «%s»", plan.Title),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.GatewayRef = ref
+	if err := s.ledger.Post(ctx, ChargeEntries(c)...); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *subscriptionsService) save(ctx context.Context, sub Subscription, plan Plan, charge *Charge, action string, now time.Time) (*Subscription, error) {
+	sub.UpdatedAt = now
+	saved, err := s.repo.SaveSubscription(ctx, sub, EntitlementsFor(sub, plan), charge)
+	if err != nil {
+		return nil, err
+	}
+	amount := 0
+	if charge != nil {
+		amount = charge.Amount
+	}
+	s.bus.Publish(subscriptions.SubscriptionChangedEvent{
+		SubscriptionID: saved.ID,
+		UserID:         saved.UserID,
+		Plan:           saved.PlanCode,
+		Status:         string(saved.Status),
+		Action:         action,
+		Amount:         amount,
+		PeriodEnd:      saved.CurrentPeriodEnd,
+		Pro:            saved.Entitled(),
+	})
+	return saved, nil
+}
+
+// planOf возвращает тариф подписки; тариф, убранный из настроек, считается тарифом без отключения рекламы.
+func (s *subscriptionsService) planOf(sub Subscription) Plan {
+	plan, err := s.settings.Plans.Find(sub.PlanCode)
+	if err != nil {
+		return Plan{Code: sub.PlanCode}
+	}
+	return plan
+}
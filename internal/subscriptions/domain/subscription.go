@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// DefaultCurrency — валюта тарифов, если она не задана в настройках.
+const DefaultCurrency = "RUB"
+
+// Period — срок, на который оплачивается тариф.
+type Period string
+
+const (
+	PeriodMonthly Period = "monthly"
+	PeriodYearly  Period = "yearly"
+)
+
+// Status — состояние подписки.
+type Status string
+
+const (
+	StatusActive    Status = "active"    // Оплачена, Pro включён
+	StatusPastDue   Status = "past_due"  // Продление не прошло, Pro действует до конца льготного периода
+	StatusCancelled Status = "cancelled" // Отменена пользователем и закончилась
+	StatusExpired   Status = "expired"   // Не продлена до конца льготного периода
+)
+
+// ChargeKind — за что списаны деньги.
+type ChargeKind string
+
+const (
+	ChargeStart   ChargeKind = "start"   // Оформление подписки
+	ChargeRenewal ChargeKind = "renewal" // Продление на следующий период
+	ChargeChange  ChargeKind = "change"  // Доплата при смене тарифа
+)
+
+// Действия над подпиской, о которых сообщает SubscriptionChangedEvent.
+const (
+	ActionStarted         = "started"
+	ActionRenewed         = "renewed"
+	ActionPlanChanged     = "plan_changed"
+	ActionCancelScheduled = "cancel_scheduled"
+	ActionResumed         = "resumed"
+	ActionPastDue         = "past_due"
+	ActionCancelled       = "cancelled"
+	ActionExpired         = "expired"
+)
+
+var (
+	ErrPlanNotFound         = errors.New("тариф не найден")
+	ErrInvalidPlan          = errors.New("некорректный тариф")
+	ErrSubscriptionNotFound = errors.New("подписка не найдена")
+	ErrAlreadySubscribed    = errors.New("подписка уже оформлена")
+	ErrSubscriptionState    = errors.New("действие недоступно в текущем состоянии подписки")
+	ErrSubscriptionConflict = errors.New("подписка изменена параллельно, повторите запрос")
+)
+
+// Plan — тариф подписки Pro.
+type Plan struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Period   Period `json:"period"`
+	Price    int    `json:"price"`
+	Currency string `json:"currency"`
+	NoAds    bool   `json:"no_ads"` // Отключает рекламу на время подписки
+}
+
+// Validate проверяет код, срок, цену и валюту тарифа.
+func (p Plan) Validate() error {
+	if p.Code == "" {
+		return fmt.Errorf("%w: не указан код", ErrInvalidPlan)
+	}
+	if p.Period != PeriodMonthly && p.Period != PeriodYearly {
+		return fmt.Errorf("%w: неизвестный срок %q у тарифа %s", ErrInvalidPlan, p.Period, p.Code)
+	}
+	if p.Price <= 0 {
+		return fmt.Errorf("%w: цена %d у тарифа %s", ErrInvalidPlan, p.Price, p.Code)
+	}
+	if err := money.Currency(p.Currency).Validate(); err != nil {
+		return fmt.Errorf("%w: валюта тарифа %s: %w", ErrInvalidPlan, p.Code, err)
+	}
+	return nil
+}
+
+// PeriodEnd возвращает конец периода, начатого в start. Месяц отсчитывается по календарю:
+// подписка от 15 марта продлевается 15 апреля.
+func (p Plan) PeriodEnd(start time.Time) time.Time {
+	if p.Period == PeriodYearly {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// Catalog — доступные тарифы.
+type Catalog []Plan
+
+// Validate проверяет тарифы, уникальность кодов и общую валюту: при смене тарифа остаток
+// текущего периода засчитывается в цену нового, поэтому цены должны быть в одной валюте.
+func (c Catalog) Validate() error {
+	seen := make(map[string]bool, len(c))
+	for _, p := range c {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		if seen[p.Code] {
+			return fmt.Errorf("%w: код %s повторяется", ErrInvalidPlan, p.Code)
+		}
+		seen[p.Code] = true
+		if p.Currency != c[0].Currency {
+			return fmt.Errorf("%w: тарифы %s и %s в разных валютах", ErrInvalidPlan, c[0].Code, p.Code)
+		}
+	}
+	return nil
+}
+
+// Find возвращает тариф по коду.
+func (c Catalog) Find(code string) (Plan, error) {
+	for _, p := range c {
+		if p.Code == code {
+			return p, nil
+		}
+	}
+	return Plan{}, fmt.Errorf("%w: %s", ErrPlanNotFound, code)
+}
+
+// Subscription — подписка пользователя. У пользователя одна запись: после окончания
+// подписки повторное оформление переиспользует её.
+type Subscription struct {
+	ID                 int64      `json:"id"`
+	UserID             int64      `json:"user_id"`
+	PlanCode           string     `json:"plan"`
+	Status             Status     `json:"status"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time  `json:"current_period_end"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end"`
+	GraceUntil         *time.Time `json:"grace_until,omitempty"`
+	Credit             int        `json:"credit"` // Неиспользованный остаток после смены тарифа, уменьшает следующие списания
+	RenewalAttempts    int        `json:"-"`
+	Version            int        `json:"-"` // Растёт с каждым сохранением; защищает от параллельных изменений
+	EndedAt            *time.Time `json:"ended_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// Entitled сообщает, действует ли Pro: подписка оплачена или идёт льготный период.
+func (s Subscription) Entitled() bool {
+	return s.Status == StatusActive || s.Status == StatusPastDue
+}
+
+// Entitlements — флаги пользователя, которые включает подписка.
+type Entitlements struct {
+	Pro   bool
+	NoAds bool
+}
+
+// EntitlementsFor возвращает флаги пользователя для подписки на тариф plan.
+func EntitlementsFor(s Subscription, plan Plan) Entitlements {
+	if !s.Entitled() {
+		return Entitlements{}
+	}
+	return Entitlements{Pro: true, NoAds: plan.NoAds}
+}
+
+// ApplyCredit вычитает из цены накопленный остаток. Возвращает сумму к списанию и остаток после списания.
+func ApplyCredit(price, credit int) (due, left int) {
+	if credit >= price {
+		return 0, credit - price
+	}
+	return price - credit, 0
+}
+
+// Prorate считает смену тарифа с момента now: неиспользованная часть текущего периода
+// засчитывается в цену нового тарифа, новый период начинается сразу. Если зачёт больше цены,
+// разница остаётся на подписке и уменьшает следующие продления.
+func Prorate(s Subscription, from, to Plan, now time.Time) (due, credit int) {
+	total := int64(s.CurrentPeriodEnd.Sub(s.CurrentPeriodStart) / time.Second)
+	left := int64(s.CurrentPeriodEnd.Sub(now) / time.Second)
+	unused := 0
+	if total > 0 && left > 0 {
+		left = min(left, total)
+		unused = int((int64(from.Price)*left + total/2) / total)
+	}
+	return ApplyCredit(to.Price, unused+s.Credit)
+}
+
+// ChargeKey — ключ идемпотентности списания. Версия подписки меняется с каждым сохранением,
+// поэтому повтор запроса до сохранения попадает в то же списание, а следующее действие — в новое.
+func ChargeKey(s Subscription, kind ChargeKind) string {
+	return fmt.Sprintf("subscription-%d-v%d-%s", s.UserID, s.Version, kind)
+}
+
+// Charge — списание по подписке.
+type Charge struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	UserID         int64      `json:"user_id"`
+	Kind           ChargeKind `json:"kind"`
+	PlanCode       string     `json:"plan"`
+	Amount         int        `json:"amount"`
+	Currency       string     `json:"currency"`
+	IdempotencyKey string     `json:"-"`
+	GatewayRef     string     `json:"gateway_ref"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ChargeEntries — проводки оплаты подписки: поступление с карты на кошелёк пользователя
+// и перенос с кошелька в доход площадки. В выписке видны оба движения, а баланс не меняется.
+// Проводки идут в валюте тарифа.
+func ChargeEntries(c Charge) []ledger.Entry {
+	return []ledger.Entry{
+		ledger.Transfer(ledger.EntryDeposit, c.IdempotencyKey+":deposit", ledger.External(), ledger.Wallet(c.UserID), c.Amount,
+			fmt.Sprintf("Поступление оплаты подписки %s", c.PlanCode)).InCurrency(c.Currency),
+		ledger.Transfer(ledger.EntrySubscription, c.IdempotencyKey, ledger.Wallet(c.UserID), ledger.Commission(), c.Amount,
+			fmt.Sprintf("Оплата подписки %s", c.PlanCode)).InCurrency(c.Currency),
+	}
+}
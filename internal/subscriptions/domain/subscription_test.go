@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ledger "github.com/unclaim/chegonado.git/internal/ledger/domain"
+)
+
+func TestPlanPeriodEnd(t *testing.T) {
+	start := time.Date(2026, time.March, 15, 10, 0, 0, 0, time.UTC)
+	monthly := Plan{Code: "pro_monthly", Period: PeriodMonthly, Price: 499}
+	yearly := Plan{Code: "pro_yearly", Period: PeriodYearly, Price: 4990}
+
+	if got := monthly.PeriodEnd(start); !got.Equal(time.Date(2026, time.April, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("месячный тариф: получено %s", got)
+	}
+	if got := yearly.PeriodEnd(start); !got.Equal(time.Date(2027, time.March, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("годовой тариф: получено %s", got)
+	}
+}
+
+func TestProrate(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	monthly := Plan{Code: "pro_monthly", Period: PeriodMonthly, Price: 600}
+	yearly := Plan{Code: "pro_yearly", Period: PeriodYearly, Price: 6000}
+	sub := Subscription{CurrentPeriodStart: start, CurrentPeriodEnd: start.Add(30 * 24 * time.Hour)}
+
+	cases := map[string]struct {
+		sub         Subscription
+		from, to    Plan
+		now         time.Time
+		due, credit int
+	}{
+		"в начале периода засчитывается всё": {sub, monthly, yearly, start, 5400, 0},
+		"в середине периода — половина":      {sub, monthly, yearly, start.Add(15 * 24 * time.Hour), 5700, 0},
+		"после конца периода зачёта нет":     {sub, monthly, yearly, start.Add(40 * 24 * time.Hour), 6000, 0},
+		"остаток подписки тоже засчитывается": {
+			Subscription{CurrentPeriodStart: start, CurrentPeriodEnd: sub.CurrentPeriodEnd, Credit: 100}, monthly, yearly, start, 5300, 0,
+		},
+		"зачёт больше цены остаётся на подписке": {
+			Subscription{CurrentPeriodStart: start, CurrentPeriodEnd: start.AddDate(1, 0, 0)}, yearly, monthly, start, 0, 5400,
+		},
+	}
+	for name, c := range cases {
+		due, credit := Prorate(c.sub, c.from, c.to, c.now)
+		if due != c.due || credit != c.credit {
+			t.Errorf("%s: ожидалось %d к списанию и %d остатка, получено %d и %d", name, c.due, c.credit, due, credit)
+		}
+	}
+}
+
+func TestEntitlementsFor(t *testing.T) {
+	plan := Plan{Code: "pro_monthly", NoAds: true}
+	for status, want := range map[Status]bool{
+		StatusActive:    true,
+		StatusPastDue:   true,
+		StatusCancelled: false,
+		StatusExpired:   false,
+	} {
+		got := EntitlementsFor(Subscription{Status: status}, plan)
+		if got.Pro != want || got.NoAds != want {
+			t.Errorf("%s: ожидалось Pro=%v, получено %+v", status, want, got)
+		}
+	}
+}
+
+func TestChargeEntriesInPlanCurrency(t *testing.T) {
+	for currency, want := range map[string]string{"USD": "USD", "": ledger.DefaultCurrency} {
+		charge := Charge{UserID: 7, PlanCode: "pro_monthly", Amount: 999, Currency: currency, IdempotencyKey: "subscription-7-v1-new"}
+		for _, entry := range ChargeEntries(charge) {
+			if err := entry.Validate(); err != nil {
+				t.Fatalf("%q: некорректная проводка %s: %v", currency, entry.Kind, err)
+			}
+			if entry.Currency != want {
+				t.Errorf("%q: проводка %s должна идти в валюте %s, получено %s", currency, entry.Kind, want, entry.Currency)
+			}
+		}
+	}
+}
+
+func TestCatalogValidateRejectsMixedCurrencies(t *testing.T) {
+	monthly := Plan{Code: "pro_monthly", Period: PeriodMonthly, Price: 49900, Currency: "RUB"}
+	yearly := Plan{Code: "pro_yearly", Period: PeriodYearly, Price: 499000, Currency: "RUB"}
+	if err := (Catalog{monthly, yearly}).Validate(); err != nil {
+		t.Fatalf("тарифы в одной валюте: неожиданная ошибка %v", err)
+	}
+
+	yearly.Currency = "USD"
+	if err := (Catalog{monthly, yearly}).Validate(); !errors.Is(err, ErrInvalidPlan) {
+		t.Errorf("тарифы в разных валютах: ожидалась ErrInvalidPlan, получено %v", err)
+	}
+	yearly.Currency = "XXX"
+	if err := (Catalog{yearly}).Validate(); !errors.Is(err, ErrInvalidPlan) {
+		t.Errorf("неизвестная валюта: ожидалась ErrInvalidPlan, получено %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package subscriptions
+
+import "time"
+
+// SubscriptionChangedEvent — событие изменения подписки Pro: оформление, продление, смена тарифа,
+// запланированная или отменённая отмена, неудачное продление и окончание.
+// Action — одно из значений domain.Action*, Amount — списанная сумма, если действие было платным.
+type SubscriptionChangedEvent struct {
+	SubscriptionID int64
+	UserID         int64
+	Plan           string
+	Status         string
+	Action         string
+	Amount         int
+	PeriodEnd      time.Time
+	Pro            bool
+}
@@ -0,0 +1,161 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/unclaim/chegonado.git/internal/subscriptions/domain"
+)
+
+// subscriptionColumns — поля подписки в порядке, который ожидает scanSubscription.
+const subscriptionColumns = `id, user_id, plan_code, status, current_period_start, current_period_end, cancel_at_period_end,
+        grace_until, credit, renewal_attempts, version, ended_at, created_at, updated_at`
+
+// chargeColumns — поля списания в порядке, который ожидает scanCharge.
+const chargeColumns = `id, subscription_id, user_id, kind, plan_code, amount, currency, idempotency_key, gateway_ref, created_at`
+
+// SubscriptionsRepository хранит подписки Pro в PostgreSQL.
+type SubscriptionsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSubscriptionsRepository создаёт новый репозиторий подписок.
+func NewSubscriptionsRepository(db *pgxpool.Pool) *SubscriptionsRepository {
+	return &SubscriptionsRepository{db: db}
+}
+
+// GetSubscription возвращает подписку пользователя.
+func (r *SubscriptionsRepository) GetSubscription(ctx context.Context, userID int64) (*domain.Subscription, error) {
+	s, err := scanSubscription(r.db.QueryRow(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE user_id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении подписки пользователя %d: %w", userID, err)
+	}
+	return &s, nil
+}
+
+// SaveSubscription записывает подписку с проверкой версии, выставляет флаги пользователя
+// и сохраняет списание. Повтор списания с тем же ключом не записывается.
+func (r *SubscriptionsRepository) SaveSubscription(ctx context.Context, s domain.Subscription, flags domain.Entitlements, charge *domain.Charge) (*domain.Subscription, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved, err := scanSubscription(tx.QueryRow(ctx, `
+        INSERT INTO subscriptions (user_id, plan_code, status, current_period_start, current_period_end, cancel_at_period_end,
+                                   grace_until, credit, renewal_attempts, version, ended_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1, $10, $11, $11)
+        ON CONFLICT (user_id) DO UPDATE SET
+            plan_code = EXCLUDED.plan_code,
+            status = EXCLUDED.status,
+            current_period_start = EXCLUDED.current_period_start,
+            current_period_end = EXCLUDED.current_period_end,
+            cancel_at_period_end = EXCLUDED.cancel_at_period_end,
+            grace_until = EXCLUDED.grace_until,
+            credit = EXCLUDED.credit,
+            renewal_attempts = EXCLUDED.renewal_attempts,
+            version = subscriptions.version + 1,
+            ended_at = EXCLUDED.ended_at,
+            updated_at = EXCLUDED.updated_at
+        WHERE subscriptions.version = $12
+        RETURNING `+subscriptionColumns,
+		s.UserID, s.PlanCode, s.Status, s.CurrentPeriodStart, s.CurrentPeriodEnd, s.CancelAtPeriodEnd,
+		s.GraceUntil, s.Credit, s.RenewalAttempts, s.EndedAt, s.UpdatedAt, s.Version))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSubscriptionConflict
+		}
+		return nil, fmt.Errorf("ошибка при сохранении подписки пользователя %d: %w", s.UserID, err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET pro = $2, no_ads = $3 WHERE id = $1`, s.UserID, flags.Pro, flags.NoAds); err != nil {
+		return nil, fmt.Errorf("ошибка при обновлении флагов пользователя %d: %w", s.UserID, err)
+	}
+
+	if charge != nil {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO subscription_charges (subscription_id, user_id, kind, plan_code, amount, currency, idempotency_key, gateway_ref, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            ON CONFLICT (idempotency_key) DO NOTHING`,
+			saved.ID, charge.UserID, charge.Kind, charge.PlanCode, charge.Amount, charge.Currency,
+			charge.IdempotencyKey, charge.GatewayRef, charge.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при записи списания %s: %w", charge.IdempotencyKey, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить подписку пользователя %d: %w", s.UserID, err)
+	}
+	return &saved, nil
+}
+
+// ListDue возвращает оплаченные подписки с закончившимся периодом и подписки в льготном периоде.
+func (r *SubscriptionsRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.Subscription, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+subscriptionColumns+`
+        FROM subscriptions
+        WHERE (status = $1 AND current_period_end <= $3) OR status = $2
+        ORDER BY current_period_end, id
+        LIMIT $4`, domain.StatusActive, domain.StatusPastDue, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении подписок к продлению: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.Subscription{}
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании подписки: %w", err)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, rows.Err()
+}
+
+// ListCharges возвращает списания пользователя, новые первыми.
+func (r *SubscriptionsRepository) ListCharges(ctx context.Context, userID int64, limit int) ([]domain.Charge, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+chargeColumns+`
+        FROM subscription_charges
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списаний пользователя %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	charges := []domain.Charge{}
+	for rows.Next() {
+		c, err := scanCharge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании списания: %w", err)
+		}
+		charges = append(charges, c)
+	}
+	return charges, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (domain.Subscription, error) {
+	var s domain.Subscription
+	err := row.Scan(&s.ID, &s.UserID, &s.PlanCode, &s.Status, &s.CurrentPeriodStart, &s.CurrentPeriodEnd, &s.CancelAtPeriodEnd,
+		&s.GraceUntil, &s.Credit, &s.RenewalAttempts, &s.Version, &s.EndedAt, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func scanCharge(row pgx.Row) (domain.Charge, error) {
+	var c domain.Charge
+	err := row.Scan(&c.ID, &c.SubscriptionID, &c.UserID, &c.Kind, &c.PlanCode, &c.Amount, &c.Currency, &c.IdempotencyKey, &c.GatewayRef, &c.CreatedAt)
+	return c, err
+}
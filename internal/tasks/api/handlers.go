@@ -3,19 +3,26 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 	"github.com/unclaim/chegonado.git/internal/shared/utils"
 	"github.com/unclaim/chegonado.git/internal/tasks/domain" // Импортируем доменные модели и интерфейсы
 	"github.com/unclaim/chegonado.git/pkg/security/session"
 	"github.com/unclaim/chegonado.git/pkg/security/token"
 )
 
+// Размеры страниц по умолчанию для списков с курсорной пагинацией.
+const (
+	tasksFeedLimit   = 5
+	defaultListLimit = 20
+)
+
 // TasksHandler отвечает за обработку HTTP-запросов, связанных с задачами.
 type TasksHandler struct {
 	TasksService domain.TasksService // Зависимость от интерфейса сервиса
@@ -65,56 +72,27 @@ func (h *TasksHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TasksHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	page, err := parsePage(pageStr) // parsePage теперь вспомогательная функция внутри api или service
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), tasksFeedLimit)
 	if err != nil {
 		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
 		return
 	}
 
-	tasks, message, err := h.TasksService.GetTasks(page)
+	tasks, err := h.TasksService.GetTasks(r.Context(), page)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении задач: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении задач: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
 	taskResp := &domain.TasksResponse{
-		Tasks:   tasks,
-		Message: message,
+		Tasks:      tasks.Items,
+		NextCursor: tasks.NextCursor,
 	}
-
-	utils.NewResponse(w, http.StatusOK, taskResp)
-}
-
-func (h *TasksHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	params := r.URL.Query()
-
-	searchStr := params.Get("search")
-	if searchStr == "" {
-		common_errors.NewAppError(w, r, fmt.Errorf("параметр 'search' обязателен"), http.StatusBadRequest)
-		return
-	}
-
-	page, err := parsePage(params.Get("page"))
-	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("некорректный параметр 'page': %w", err), http.StatusBadRequest)
-		return
-	}
-
-	const pageSize = 10
-	offset := page * pageSize
-
-	searchFilters := map[string]interface{}{}
-	// Здесь можно добавить парсинг других фильтров из запроса и передать их в searchFilters
-
-	tasks, err := h.TasksService.SearchTasks(ctx, searchStr, searchFilters, pageSize, offset)
-	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при поиске задач: %w", err), http.StatusInternalServerError)
-		return
+	if tasks.NextCursor == "" {
+		taskResp.Message = "Конец списка"
 	}
 
-	utils.NewResponse(w, http.StatusOK, &domain.SearchTasksRes{Tasks: tasks})
+	utils.NewResponse(w, http.StatusOK, taskResp)
 }
 
 func (h *TasksHandler) CreateContract(w http.ResponseWriter, r *http.Request) {
@@ -135,7 +113,7 @@ func (h *TasksHandler) CreateContract(w http.ResponseWriter, r *http.Request) {
 
 	contractID, err := h.TasksService.CreateContract(ctx, req, creatorID)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при создании контракта: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при создании контракта: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -174,6 +152,12 @@ func (h *TasksHandler) GetTasksResponses(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *TasksHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.SessionFromContext(r.Context())
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
 	var report domain.Report
 
 	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
@@ -181,8 +165,8 @@ func (h *TasksHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.TasksService.CreateReport(r.Context(), report); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при создании отчета: %w", err), http.StatusInternalServerError)
+	if err := h.TasksService.CreateReport(r.Context(), sess.UserID, report); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при создании отчета: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -233,34 +217,6 @@ func (h *TasksHandler) CheckContract(w http.ResponseWriter, r *http.Request) {
 	utils.NewResponse(w, http.StatusOK, contract)
 }
 
-func (h *TasksHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		common_errors.NewAppError(w, r, fmt.Errorf("метод не разрешен"), http.StatusMethodNotAllowed)
-		return
-	}
-
-	var review domain.Review
-	log.Println(review)
-	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании тела запроса: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	ctx := r.Context()
-
-	reviewID, err := h.TasksService.CreateReview(ctx, review)
-	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при попытке добавить отзыв: %w", err), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(map[string]int{"id": reviewID}); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при формировании JSON-ответа: %w", err), http.StatusInternalServerError)
-		return
-	}
-}
-
 func (h *TasksHandler) GetContractReportExists(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	prefix := "/reports/check/"
@@ -296,25 +252,15 @@ func (h *TasksHandler) GetContractReportExists(w http.ResponseWriter, r *http.Re
 	})
 }
 
-func (h *TasksHandler) GetReviewsByUser(w http.ResponseWriter, r *http.Request) {
-	userID := r.PathValue("user_id") // Предполагаем, что router парсит PathValue
-	if userID == "" {
-		common_errors.NewAppError(w, r, fmt.Errorf("идентификатор пользователя не предоставлен"), http.StatusBadRequest) // Изменил на 400 Bad Request
-		return
-	}
+func (h *TasksHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	reviews, err := h.TasksService.GetReviewsByUser(r.Context(), userID)
+	sess, err := session.SessionFromContext(ctx)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить отзывы: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
 		return
 	}
 
-	utils.NewResponse(w, http.StatusOK, reviews)
-}
-
-func (h *TasksHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	contractIDStr := r.URL.Query().Get("id")
 	if contractIDStr == "" {
 		common_errors.NewAppError(w, r, fmt.Errorf("отсутствует параметр id в запросе"), http.StatusBadRequest)
@@ -327,6 +273,17 @@ func (h *TasksHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Этап можно не указывать, если контракт не разбит на этапы.
+	var milestoneID *int64
+	if raw := r.URL.Query().Get("milestone_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			common_errors.NewAppError(w, r, fmt.Errorf("некорректный формат milestone_id: %w", err), http.StatusBadRequest)
+			return
+		}
+		milestoneID = &id
+	}
+
 	var updateData struct {
 		CustomerFeedback     *string `json:"customer_feedback,omitempty"`
 		CustomerConfirmation *bool   `json:"customer_confirmation,omitempty"`
@@ -337,9 +294,9 @@ func (h *TasksHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.TasksService.UpdateReport(ctx, contractID, updateData.CustomerFeedback, updateData.CustomerConfirmation)
+	err = h.TasksService.UpdateReport(ctx, contractID, sess.UserID, milestoneID, updateData.CustomerFeedback, updateData.CustomerConfirmation)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при обновлении отчета: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при обновлении отчета: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -366,7 +323,7 @@ func (h *TasksHandler) CancelTaskHandler(w http.ResponseWriter, r *http.Request)
 	userID := sess.UserID
 
 	if err := h.TasksService.CancelTask(r.Context(), task.ID, userID); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка отмены задания: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка отмены задания: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -428,7 +385,7 @@ func (h *TasksHandler) CreateResponse(w http.ResponseWriter, r *http.Request) {
 
 	createdResponse, err := h.TasksService.CreateResponse(r.Context(), newResponse)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("не удалось сохранить ответ: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось сохранить ответ: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -488,13 +445,94 @@ func (h *TasksHandler) DeleteTaskByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.TasksService.DeleteTaskByID(ctx, userID, taskID); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("не удалось удалить задачу: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось удалить задачу: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// UpdateTask изменяет условия задачи.
+func (h *TasksHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор задачи: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	var req domain.UpdateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.TasksService.UpdateTask(ctx, taskID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при изменении задачи: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, task)
+}
+
+// GetTaskRevisions возвращает предыдущие редакции условий задачи заказчику и откликнувшимся исполнителям.
+func (h *TasksHandler) GetTaskRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор задачи: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.TasksService.GetTaskRevisions(ctx, taskID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить редакции задачи: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, revisions)
+}
+
+// GetTaskStatusHistory возвращает историю смены статусов задачи.
+func (h *TasksHandler) GetTaskStatusHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор задачи: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.TasksService.GetTaskStatusHistory(ctx, taskID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить историю статусов: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, history)
+}
+
 func (h *TasksHandler) TotalResponses(w http.ResponseWriter, r *http.Request) {
 	taskIDStr := r.URL.Query().Get("task_id")
 
@@ -550,9 +588,15 @@ func (h *TasksHandler) GetResponsesHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	responses, err := h.TasksService.GetResponsesHandler(ctx, taskID, userID)
+	page, err := pagination.ParseRequest(r.URL.Query().Get("cursor"), r.URL.Query().Get("limit"), defaultListLimit)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить ответы: %w", err), http.StatusInternalServerError)
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	responses, err := h.TasksService.GetResponsesHandler(ctx, taskID, userID, page)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить ответы: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -692,27 +736,6 @@ func (h *TasksHandler) GetTasksCount(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *TasksHandler) GetUserReviewsStats(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID := r.PathValue("user_id")
-	if userID == "" {
-		common_errors.NewAppError(w, r, fmt.Errorf("идентификатор пользователя обязателен"), http.StatusBadRequest)
-		return
-	}
-
-	stats, err := h.TasksService.GetUserReviewsStats(ctx, userID)
-	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить статистику отзывов: %w", err), http.StatusInternalServerError)
-		return
-	}
-
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при кодировании ответа в JSON: %w", err), http.StatusInternalServerError)
-		return
-	}
-}
-
 func (h *TasksHandler) RecordTaskViewHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -809,14 +832,222 @@ func (h *TasksHandler) ResponseViews(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// parsePage вспомогательная функция для API слоя
-func parsePage(pageStr string) (int, error) {
-	if pageStr == "" {
-		return 0, nil // по умолчанию первая страница
+// serviceErrorStatus возвращает HTTP-код из domain.ServiceError, а для прочих ошибок — fallback.
+func serviceErrorStatus(err error, fallback int) int {
+	var svcErr *domain.ServiceError
+	if errors.As(err, &svcErr) && svcErr.Code != 0 {
+		return svcErr.Code
 	}
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 0 {
-		return 0, fmt.Errorf("неправильный номер страницы '%s'", pageStr)
+	return fallback
+}
+
+// CreateSavedSearch сохраняет фильтры поиска задач текущего пользователя.
+func (h *TasksHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.TasksService.CreateSavedSearch(ctx, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при сохранении поиска: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, search)
+}
+
+// ListSavedSearches возвращает сохранённые поиски текущего пользователя.
+func (h *TasksHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	searches, err := h.TasksService.ListSavedSearches(ctx, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить сохранённые поиски: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, searches)
+}
+
+// UpdateSavedSearch изменяет сохранённый поиск текущего пользователя.
+func (h *TasksHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	searchID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор поиска: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	var req domain.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.TasksService.UpdateSavedSearch(ctx, searchID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при изменении поиска: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, search)
+}
+
+// DeleteSavedSearch удаляет сохранённый поиск текущего пользователя.
+func (h *TasksHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	searchID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор поиска: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.TasksService.DeleteSavedSearch(ctx, searchID, sess.UserID); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при удалении поиска: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetResponseOffers возвращает историю переговоров по отклику.
+func (h *TasksHandler) GetResponseOffers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
 	}
-	return page, nil
+
+	responseID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор отклика: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	offers, err := h.TasksService.GetResponseOffers(ctx, responseID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить историю переговоров: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, offers)
+}
+
+// MakeOffer принимает ход в переговорах по отклику: встречное предложение, согласие или отзыв.
+func (h *TasksHandler) MakeOffer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	responseID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор отклика: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	var req domain.OfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	offer, err := h.TasksService.MakeOffer(ctx, responseID, sess.UserID, req)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось сделать предложение: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusCreated, offer)
+}
+
+// GetContractMilestones возвращает этапы контракта с отчётами по ним.
+func (h *TasksHandler) GetContractMilestones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	milestones, err := h.TasksService.GetContractMilestones(ctx, contractID, sess.UserID)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось получить этапы контракта: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, milestones)
+}
+
+// ReplaceMilestones заменяет план этапов контракта.
+func (h *TasksHandler) ReplaceMilestones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sess, err := session.SessionFromContext(ctx)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при получении сессии: %w", err), http.StatusUnauthorized)
+		return
+	}
+
+	contractID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("недопустимый идентификатор контракта: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	var plan []domain.MilestoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при декодировании запроса: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	milestones, err := h.TasksService.ReplaceMilestones(ctx, contractID, sess.UserID, plan)
+	if err != nil {
+		common_errors.NewAppError(w, r, fmt.Errorf("не удалось изменить этапы контракта: %w", err), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	utils.NewResponse(w, http.StatusOK, milestones)
 }
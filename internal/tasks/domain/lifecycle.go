@@ -0,0 +1,130 @@
+// internal/tasks/domain/lifecycle.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TaskStatusCode — код статуса задачи в жизненном цикле.
+type TaskStatusCode int
+
+const (
+	StatusActive     TaskStatusCode = 100 // Задача открыта и принимает отклики
+	StatusInProgress TaskStatusCode = 101 // По задаче заключён активный контракт
+	StatusCompleted  TaskStatusCode = 102 // Работа выполнена и подтверждена заказчиком
+	StatusCancelled  TaskStatusCode = 103 // Задача отменена
+)
+
+// String возвращает человекочитаемое название статуса.
+func (c TaskStatusCode) String() string {
+	switch c {
+	case StatusActive:
+		return "Active"
+	case StatusInProgress:
+		return "In Progress"
+	case StatusCompleted:
+		return "Completed"
+	case StatusCancelled:
+		return "Cancelled"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(c))
+	}
+}
+
+// IsValid сообщает, является ли код известным статусом задачи.
+func (c TaskStatusCode) IsValid() bool {
+	switch c {
+	case StatusActive, StatusInProgress, StatusCompleted, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsTerminal сообщает, что из статуса больше нет переходов.
+func (c TaskStatusCode) IsTerminal() bool {
+	return c == StatusCompleted || c == StatusCancelled
+}
+
+var (
+	// ErrInvalidTransition возвращается, если переход между статусами не предусмотрен жизненным циклом.
+	ErrInvalidTransition = errors.New("недопустимый переход статуса задачи")
+	// ErrTransitionGuard возвращается, если переход предусмотрен, но не выполнены его условия.
+	ErrTransitionGuard = errors.New("условия перехода статуса задачи не выполнены")
+	// ErrStatusConflict возвращается репозиторием, если статус задачи успел измениться параллельно.
+	ErrStatusConflict = errors.New("статус задачи был изменён другим запросом")
+	// ErrTaskHasDocuments возвращается при удалении задачи, по контрактам которой выпущены счета или чеки:
+	// финансовые документы хранятся вместе с контрактом и не удаляются.
+	ErrTaskHasDocuments = errors.New("по контрактам задачи выпущены финансовые документы")
+)
+
+// TransitionGuards — факты о контракте и отчёте, от которых зависят переходы.
+type TransitionGuards struct {
+	HasActiveContract bool // По задаче есть активный контракт
+	HasReport         bool // Исполнитель сдал отчёт хотя бы по одному этапу активного контракта
+	ReportConfirmed   bool // Заказчик подтвердил отчёты по всем этапам активного контракта
+
+	ArbitrationDecision bool // Переход выполняется по решению арбитра, условия по отчётам не проверяются
+}
+
+// allowedTransitions перечисляет разрешённые переходы жизненного цикла задачи.
+var allowedTransitions = map[TaskStatusCode][]TaskStatusCode{
+	StatusActive:     {StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusCompleted, StatusCancelled, StatusActive},
+}
+
+// CanTransition проверяет, предусмотрен ли переход from -> to жизненным циклом.
+func CanTransition(from, to TaskStatusCode) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition проверяет переход с учётом состояния контракта и отчёта.
+func ValidateTransition(from, to TaskStatusCode, guards TransitionGuards) error {
+	if !from.IsValid() || !to.IsValid() {
+		return fmt.Errorf("%w: неизвестный статус %d -> %d", ErrInvalidTransition, from, to)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	switch {
+	case from == StatusActive && to == StatusInProgress:
+		if !guards.HasActiveContract {
+			return fmt.Errorf("%w: для начала работ нужен активный контракт", ErrTransitionGuard)
+		}
+	case from == StatusActive && to == StatusCancelled:
+		if guards.HasActiveContract {
+			return fmt.Errorf("%w: по задаче уже заключён активный контракт", ErrTransitionGuard)
+		}
+	case from == StatusInProgress && to == StatusCompleted:
+		if !guards.ArbitrationDecision && (!guards.HasReport || !guards.ReportConfirmed) {
+			return fmt.Errorf("%w: завершение возможно только после подтверждения заказчиком всех этапов", ErrTransitionGuard)
+		}
+	case from == StatusInProgress && to == StatusCancelled:
+		if guards.HasReport && !guards.ArbitrationDecision {
+			return fmt.Errorf("%w: исполнитель уже сдал отчёт, спор решается через арбитраж", ErrTransitionGuard)
+		}
+	case from == StatusInProgress && to == StatusActive:
+		if guards.HasActiveContract {
+			return fmt.Errorf("%w: вернуть задачу в поиск можно только после закрытия контракта", ErrTransitionGuard)
+		}
+	}
+	return nil
+}
+
+// TaskStatusChange — запись истории смены статуса задачи.
+type TaskStatusChange struct {
+	ID         int64          `json:"id"`
+	TaskID     int64          `json:"task_id"`
+	FromStatus TaskStatusCode `json:"from_status"`
+	ToStatus   TaskStatusCode `json:"to_status"`
+	ChangedBy  int64          `json:"changed_by"`
+	Reason     string         `json:"reason"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
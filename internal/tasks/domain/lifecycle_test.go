@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    TaskStatusCode
+		to      TaskStatusCode
+		guards  TransitionGuards
+		wantErr error
+	}{
+		{"старт работ с контрактом", StatusActive, StatusInProgress, TransitionGuards{HasActiveContract: true}, nil},
+		{"старт работ без контракта", StatusActive, StatusInProgress, TransitionGuards{}, ErrTransitionGuard},
+		{"отмена открытой задачи", StatusActive, StatusCancelled, TransitionGuards{}, nil},
+		{"отмена при активном контракте", StatusActive, StatusCancelled, TransitionGuards{HasActiveContract: true}, ErrTransitionGuard},
+		{"завершение после подтверждения", StatusInProgress, StatusCompleted, TransitionGuards{HasActiveContract: true, HasReport: true, ReportConfirmed: true}, nil},
+		{"завершение без подтверждения", StatusInProgress, StatusCompleted, TransitionGuards{HasActiveContract: true, HasReport: true}, ErrTransitionGuard},
+		{"отмена после сдачи отчёта", StatusInProgress, StatusCancelled, TransitionGuards{HasActiveContract: true, HasReport: true}, ErrTransitionGuard},
+		{"завершение по решению арбитра", StatusInProgress, StatusCompleted, TransitionGuards{HasActiveContract: true, HasReport: true, ArbitrationDecision: true}, nil},
+		{"отмена по решению арбитра", StatusInProgress, StatusCancelled, TransitionGuards{HasActiveContract: true, HasReport: true, ArbitrationDecision: true}, nil},
+		{"арбитраж не обходит жизненный цикл", StatusCompleted, StatusCancelled, TransitionGuards{ArbitrationDecision: true}, ErrInvalidTransition},
+		{"возврат в поиск после закрытия контракта", StatusInProgress, StatusActive, TransitionGuards{}, nil},
+		{"завершение открытой задачи", StatusActive, StatusCompleted, TransitionGuards{}, ErrInvalidTransition},
+		{"выход из терминального статуса", StatusCancelled, StatusActive, TransitionGuards{}, ErrInvalidTransition},
+		{"неизвестный статус", TaskStatusCode(108), StatusCancelled, TransitionGuards{}, ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransition(tt.from, tt.to, tt.guards)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ожидался успешный переход, получено: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ожидалась ошибка %v, получено: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// internal/tasks/domain/milestones.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MilestoneStatus — состояние этапа контракта, выводится из отчёта по нему.
+type MilestoneStatus string
+
+const (
+	MilestonePending   MilestoneStatus = "pending"   // Отчёт ещё не сдан
+	MilestoneSubmitted MilestoneStatus = "submitted" // Отчёт сдан и ждёт решения заказчика
+	MilestoneRejected  MilestoneStatus = "rejected"  // Заказчик отклонил отчёт, исполнитель может сдать его заново
+	MilestoneConfirmed MilestoneStatus = "confirmed" // Заказчик подтвердил отчёт
+)
+
+const (
+	// MaxMilestones — сколько этапов может быть в одном контракте.
+	MaxMilestones = 50
+	// maxMilestoneTitleLength — предел длины названия этапа в символах.
+	maxMilestoneTitleLength = 255
+	// defaultMilestoneTitle — название единственного этапа, если заказчик не разбил работу на этапы.
+	defaultMilestoneTitle = "Весь объём работ"
+)
+
+var (
+	// ErrInvalidMilestones возвращается при некорректном плане этапов.
+	ErrInvalidMilestones = errors.New("некорректный план этапов")
+	// ErrMilestoneNotFound возвращается, если этап не найден в контракте.
+	ErrMilestoneNotFound = errors.New("этап контракта не найден")
+	// ErrMilestonesLocked возвращается при попытке изменить план после сдачи отчёта.
+	ErrMilestonesLocked = errors.New("план этапов нельзя менять после сдачи отчёта")
+	// ErrMilestoneRequired возвращается, если в контракте несколько этапов, а этап не указан.
+	ErrMilestoneRequired = errors.New("в контракте несколько этапов, укажите milestone_id")
+	// ErrReportAlreadySubmitted возвращается, если отчёт по этапу уже сдан и не отклонён.
+	ErrReportAlreadySubmitted = errors.New("отчёт по этапу уже сдан")
+	// ErrReportNotSubmitted возвращается при решении по отчёту, который не ждёт решения заказчика.
+	ErrReportNotSubmitted = errors.New("отчёт по этапу не ждёт решения заказчика")
+)
+
+// Milestone — этап контракта со своей суммой, сроком и циклом отчёт/подтверждение.
+type Milestone struct {
+	ID         int64           `json:"id"`
+	ContractID int64           `json:"contract_id"`
+	Position   int             `json:"position"` // Порядковый номер этапа, с 1
+	Title      string          `json:"title"`
+	Amount     int             `json:"amount"`
+	DueDate    *time.Time      `json:"due_date"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Status     MilestoneStatus `json:"status"`
+	Report     *Report         `json:"report"` // nil, пока исполнитель не сдал отчёт
+}
+
+// MilestoneRequest — этап в плане, который задаёт заказчик.
+type MilestoneRequest struct {
+	Title   string     `json:"title"`
+	Amount  int        `json:"amount"`
+	DueDate *time.Time `json:"due_date"`
+}
+
+// StatusFromReport возвращает состояние этапа по отчёту.
+func StatusFromReport(report *Report) MilestoneStatus {
+	switch {
+	case report == nil:
+		return MilestonePending
+	case report.CustomerConfirmation == nil:
+		return MilestoneSubmitted
+	case *report.CustomerConfirmation:
+		return MilestoneConfirmed
+	}
+	return MilestoneRejected
+}
+
+// DefaultMilestones возвращает план из одного этапа на всю сумму и срок контракта.
+func DefaultMilestones(terms OfferTerms) []MilestoneRequest {
+	return []MilestoneRequest{{Title: defaultMilestoneTitle, Amount: terms.Price, DueDate: terms.EndDate}}
+}
+
+// ValidateMilestones проверяет план этапов и нормализует названия.
+// Сумма этапов должна совпадать с ценой контракта; total <= 0 означает, что цена не известна
+// (контракты до переговоров), и тогда сумма не проверяется.
+func ValidateMilestones(plan []MilestoneRequest, total int) error {
+	if len(plan) == 0 {
+		return fmt.Errorf("%w: нужен хотя бы один этап", ErrInvalidMilestones)
+	}
+	if len(plan) > MaxMilestones {
+		return fmt.Errorf("%w: не больше %d этапов", ErrInvalidMilestones, MaxMilestones)
+	}
+
+	sum := 0
+	for i := range plan {
+		plan[i].Title = strings.TrimSpace(plan[i].Title)
+		if plan[i].Title == "" {
+			return fmt.Errorf("%w: у этапа %d нет названия", ErrInvalidMilestones, i+1)
+		}
+		if len([]rune(plan[i].Title)) > maxMilestoneTitleLength {
+			return fmt.Errorf("%w: название этапа %d длиннее %d символов", ErrInvalidMilestones, i+1, maxMilestoneTitleLength)
+		}
+		if plan[i].Amount <= 0 {
+			return fmt.Errorf("%w: сумма этапа %d должна быть положительной", ErrInvalidMilestones, i+1)
+		}
+		sum += plan[i].Amount
+	}
+	if total > 0 && sum != total {
+		return fmt.Errorf("%w: сумма этапов %d не совпадает с ценой контракта %d", ErrInvalidMilestones, sum, total)
+	}
+	return nil
+}
+
+// FindMilestone выбирает этап для отчёта. Если этап не указан, а в контракте он один, выбирается он:
+// так продолжают работать клиенты, которые знают только об одном отчёте на контракт.
+func FindMilestone(milestones []Milestone, milestoneID *int64) (Milestone, error) {
+	if milestoneID == nil {
+		if len(milestones) == 1 {
+			return milestones[0], nil
+		}
+		return Milestone{}, ErrMilestoneRequired
+	}
+	for _, m := range milestones {
+		if m.ID == *milestoneID {
+			return m, nil
+		}
+	}
+	return Milestone{}, ErrMilestoneNotFound
+}
+
+// AllMilestonesConfirmed сообщает, что заказчик подтвердил каждый этап — контракт выполнен.
+func AllMilestonesConfirmed(milestones []Milestone) bool {
+	if len(milestones) == 0 {
+		return false
+	}
+	for _, m := range milestones {
+		if m.Status != MilestoneConfirmed {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfirmationCompletesContract сообщает, что подтверждение этапа milestoneID завершит контракт:
+// все остальные этапы уже подтверждены.
+func ConfirmationCompletesContract(milestones []Milestone, milestoneID int64) bool {
+	for _, m := range milestones {
+		if m.ID != milestoneID && m.Status != MilestoneConfirmed {
+			return false
+		}
+	}
+	return len(milestones) > 0
+}
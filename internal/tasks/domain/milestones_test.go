@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateMilestones(t *testing.T) {
+	plan := []MilestoneRequest{{Title: " Демонтаж ", Amount: 3000}, {Title: "Отделка", Amount: 7000}}
+	if err := ValidateMilestones(plan, 10000); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if plan[0].Title != "Демонтаж" {
+		t.Errorf("название этапа не нормализовано: %q", plan[0].Title)
+	}
+
+	invalid := map[string][]MilestoneRequest{
+		"пустой план":           nil,
+		"сумма не сходится":     {{Title: "Демонтаж", Amount: 3000}, {Title: "Отделка", Amount: 6000}},
+		"этап без названия":     {{Title: " ", Amount: 10000}},
+		"неположительная сумма": {{Title: "Демонтаж", Amount: 10000}, {Title: "Отделка", Amount: 0}},
+	}
+	for name, p := range invalid {
+		if err := ValidateMilestones(p, 10000); !errors.Is(err, ErrInvalidMilestones) {
+			t.Errorf("%s: ожидалась ErrInvalidMilestones, получено %v", name, err)
+		}
+	}
+
+	// Цена старых контрактов неизвестна — сумма этапов не сверяется.
+	if err := ValidateMilestones([]MilestoneRequest{{Title: "Работы", Amount: 500}}, 0); err != nil {
+		t.Errorf("без цены контракта: неожиданная ошибка %v", err)
+	}
+}
+
+func TestMilestoneStatusAndCompletion(t *testing.T) {
+	yes, no := true, false
+	confirmed := Milestone{ID: 1, Status: StatusFromReport(&Report{CustomerConfirmation: &yes})}
+	rejected := Milestone{ID: 2, Status: StatusFromReport(&Report{CustomerConfirmation: &no})}
+	submitted := Milestone{ID: 3, Status: StatusFromReport(&Report{})}
+	pending := Milestone{ID: 4, Status: StatusFromReport(nil)}
+
+	if confirmed.Status != MilestoneConfirmed || rejected.Status != MilestoneRejected ||
+		submitted.Status != MilestoneSubmitted || pending.Status != MilestonePending {
+		t.Fatalf("неверные статусы: %s %s %s %s", confirmed.Status, rejected.Status, submitted.Status, pending.Status)
+	}
+
+	if !AllMilestonesConfirmed([]Milestone{confirmed, confirmed}) {
+		t.Error("все этапы подтверждены — контракт выполнен")
+	}
+	if AllMilestonesConfirmed([]Milestone{confirmed, submitted}) || AllMilestonesConfirmed(nil) {
+		t.Error("контракт не выполнен, пока есть неподтверждённые этапы")
+	}
+}
+
+func TestFindMilestone(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	single := []Milestone{{ID: 5}}
+	multi := []Milestone{{ID: 5}, {ID: 6}}
+
+	if m, err := FindMilestone(single, nil); err != nil || m.ID != 5 {
+		t.Errorf("единственный этап выбирается без milestone_id: %v %v", m, err)
+	}
+	if _, err := FindMilestone(multi, nil); !errors.Is(err, ErrMilestoneRequired) {
+		t.Errorf("ожидалась ErrMilestoneRequired, получено %v", err)
+	}
+	if m, err := FindMilestone(multi, id(6)); err != nil || m.ID != 6 {
+		t.Errorf("ожидался этап 6: %v %v", m, err)
+	}
+	if _, err := FindMilestone(multi, id(7)); !errors.Is(err, ErrMilestoneNotFound) {
+		t.Errorf("ожидалась ErrMilestoneNotFound, получено %v", err)
+	}
+}
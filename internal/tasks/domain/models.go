@@ -2,7 +2,11 @@
 package domain
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
 )
 
 // TaskStatus представляет статус задачи.
@@ -15,20 +19,59 @@ type TaskStatus struct {
 
 // Task представляет собой структуру задачи.
 type Task struct {
-	ID              int64      `json:"id"`
-	Title           string     `json:"title"`
-	Description     string     `json:"description"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UserID          int64      `json:"user_id"`
-	CategoryID      *int       `json:"category_id"`
-	SubcategoryID   *int       `json:"subcategory_id"`
-	Cost            *int       `json:"cost"`
-	Addresses       []string   `json:"addresses"`
-	ServiceLocation string     `json:"service_location"`
-	PeriodType      string     `json:"period_type"`
-	StartDate       *time.Time `json:"start_date"`
-	EndDate         *time.Time `json:"end_date"`
-	StatusCode      int        `json:"status_code"` // 100 - Active, 101 - In Progress, 102 - Completed, 103 - Cancelled
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UserID          int64          `json:"user_id"`
+	CategoryID      *int           `json:"category_id"`
+	SubcategoryID   *int           `json:"subcategory_id"`
+	Cost            *money.Money   `json:"cost"`
+	CostBase        *int           `json:"cost_base,omitempty"` // Цена в базовой валюте по текущему курсу; по ней ищут и сортируют
+	Addresses       []string       `json:"addresses"`
+	ServiceLocation string         `json:"service_location"`
+	PeriodType      string         `json:"period_type"`
+	StartDate       *time.Time     `json:"start_date"`
+	EndDate         *time.Time     `json:"end_date"`
+	StatusCode      TaskStatusCode `json:"status_code"`           // См. lifecycle.go
+	Revision        int            `json:"revision"`              // Номер текущей редакции условий задачи
+	Locations       []TaskLocation `json:"locations,omitempty"`   // Адреса с координатами, в порядке Addresses
+	DistanceKm      *float64       `json:"distance_km,omitempty"` // Расстояние до точки поиска, если она задана
+}
+
+// TaskLocation — адрес задачи и его координаты.
+// Point пуст, если геокодер не смог определить координаты адреса.
+type TaskLocation struct {
+	Address string     `json:"address"`
+	Point   *geo.Point `json:"point,omitempty"`
+}
+
+// UpdateTaskRequest представляет запрос на изменение условий задачи.
+type UpdateTaskRequest struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Cost        *money.Money `json:"cost"`
+	Addresses   []string     `json:"addresses"`
+	StartDate   *time.Time   `json:"start_date"`
+	EndDate     *time.Time   `json:"end_date"`
+
+	Locations []TaskLocation `json:"-"` // Заполняется сервисом по Addresses
+	CostBase  *int           `json:"-"` // Заполняется сервисом по Cost
+}
+
+// TaskRevision — сохранённая предыдущая редакция условий задачи.
+type TaskRevision struct {
+	ID          int64        `json:"id"`
+	TaskID      int64        `json:"task_id"`
+	Revision    int          `json:"revision"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Cost        *money.Money `json:"cost"`
+	Addresses   []string     `json:"addresses"`
+	StartDate   *time.Time   `json:"start_date"`
+	EndDate     *time.Time   `json:"end_date"`
+	ChangedBy   int64        `json:"changed_by"` // Кто заменил эту редакцию новой
+	ReplacedAt  time.Time    `json:"replaced_at"`
 }
 
 // Category представляет собой структуру категории.
@@ -47,12 +90,15 @@ type Subcategory struct {
 
 // ProposedResponse представляет отклик на задачу.
 type ProposedResponse struct {
-	ID            int64     `json:"id"`
-	TaskID        int64     `json:"task_id"`
-	UserID        int64     `json:"user_id"`
-	ProposedPrice int       `json:"proposed_price"`
-	ResponseText  string    `json:"response_text"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int64       `json:"id"`
+	TaskID        int64       `json:"task_id"`
+	UserID        int64       `json:"user_id"`
+	ProposedPrice money.Money `json:"proposed_price"`
+	ResponseText  string      `json:"response_text"`
+	CreatedAt     time.Time   `json:"created_at"`
+	TaskRevision  int         `json:"task_revision"` // Редакция задачи, на которую дан отклик
+
+	NegotiationStatus NegotiationStatus `json:"negotiation_status"`
 }
 
 // UserInfo представляет информацию о пользователе.
@@ -65,34 +111,63 @@ type UserInfo struct {
 
 // ResponseWithUser представляет отклик с информацией о пользователе.
 type ResponseWithUser struct {
-	ID            int64     `json:"id"`
-	TaskID        int64     `json:"task_id"`
-	UserID        int64     `json:"user_id"`
-	ProposedPrice int       `json:"proposed_price"`
-	ResponseText  string    `json:"response_text"`
-	CreatedAt     time.Time `json:"created_at"`
-	UserInfo      UserInfo  `json:"user_info"`
-	HasContract   bool      `json:"has_contract"`
+	ID            int64       `json:"id"`
+	TaskID        int64       `json:"task_id"`
+	UserID        int64       `json:"user_id"`
+	ProposedPrice money.Money `json:"proposed_price"`
+	ResponseText  string      `json:"response_text"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UserInfo      UserInfo    `json:"user_info"`
+	HasContract   bool        `json:"has_contract"`
+	TaskRevision  int         `json:"task_revision"` // Редакция задачи, на которую дан отклик
+	TermsChanged  bool        `json:"terms_changed"` // Условия задачи менялись после отклика
+
+	NegotiationStatus NegotiationStatus `json:"negotiation_status"`
+	CurrentTerms      OfferTerms        `json:"current_terms"` // Условия последнего предложения
+	LastOfferBy       NegotiationRole   `json:"last_offer_by"` // Чей ход был последним
 }
 
 // Contract представляет собой структуру контракта.
 type Contract struct {
-	ID         int64      `json:"id"`
-	TaskID     int64      `json:"task_id"`
-	ExecutorID int64      `json:"executor_id"`
-	CustomerID int64      `json:"customer_id"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	IsActive   bool       `json:"is_active"`
-	StatusID   int64      `json:"status_id"`
-	StartDate  *time.Time `json:"start_date"`
-	EndDate    *time.Time `json:"end_date"`
+	ID         int64        `json:"id"`
+	TaskID     int64        `json:"task_id"`
+	ExecutorID int64        `json:"executor_id"`
+	CustomerID int64        `json:"customer_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	IsActive   bool         `json:"is_active"`
+	StatusID   int64        `json:"status_id"`
+	StartDate  *time.Time   `json:"start_date"`
+	EndDate    *time.Time   `json:"end_date"`
+	Price      *money.Money `json:"price"` // Согласованная с исполнителем цена
 }
 
 // CreateContractRequest представляет запрос на создание контракта.
+// Если Milestones не заданы, контракт состоит из одного этапа на всю согласованную сумму.
 type CreateContractRequest struct {
-	TaskID     int64 `json:"task_id"`
-	ExecutorID int64 `json:"executor_id"`
+	TaskID     int64              `json:"task_id"`
+	ExecutorID int64              `json:"executor_id"`
+	Milestones []MilestoneRequest `json:"milestones"`
+}
+
+// NewContract — контракт, который заключается по задаче, вместе с планом этапов.
+type NewContract struct {
+	TaskID     int64
+	ExecutorID int64
+	CustomerID int64
+	CreatedAt  time.Time
+	StatusID   int64
+	Terms      OfferTerms
+	Milestones []MilestoneRequest
+	AuctionID  *int64 // Торги, по итогам которых заключён контракт
+}
+
+// StartReason — причина перевода задачи в работу для истории статусов.
+func (c NewContract) StartReason(contractID int64) string {
+	if c.AuctionID != nil {
+		return fmt.Sprintf("заключён контракт %d по итогам торгов %d", contractID, *c.AuctionID)
+	}
+	return fmt.Sprintf("заключён контракт %d", contractID)
 }
 
 // Report представляет собой структуру отчета.
@@ -100,6 +175,7 @@ type Report struct {
 	ID                   int64     `json:"id"`
 	ContractID           int64     `json:"contract_id"`
 	TaskID               int64     `json:"task_id"`
+	MilestoneID          *int64    `json:"milestone_id"` // Этап контракта, по которому сдан отчёт
 	ExecutorComments     *string   `json:"executor_comments"`
 	CustomerFeedback     *string   `json:"customer_feedback"`
 	ExecutionStatus      bool      `json:"execution_status"`
@@ -108,31 +184,17 @@ type Report struct {
 	UpdatedAt            time.Time `json:"updated_at"`
 }
 
-// Review представляет собой структуру отзыва.
-type Review struct {
-	ID         int64     `json:"id"`
-	ContractID int64     `json:"contract_id"`
-	UserID     int64     `json:"user_id"`
-	Rating     int       `json:"rating"`
-	Comment    string    `json:"comment"`
-	CreatedAt  time.Time `json:"created_at"`
-}
-
-// ReviewStats представляет статистику отзывов.
-type ReviewStats struct {
-	TotalReviews  int     `json:"total_reviews"`
-	AverageRating float64 `json:"average_rating"`
-}
-
 // TasksResponse - это структура для ответа GetTasks.
 type TasksResponse struct {
-	Tasks   []Task `json:"tasks"`
-	Message string `json:"message"`
+	Tasks      []Task `json:"tasks"`
+	Message    string `json:"message"`
+	NextCursor string `json:"next_cursor,omitempty"` // Курсор следующей страницы; пусто в конце списка
 }
 
 // SearchTasksRes - это структура для ответа SearchTasks.
 type SearchTasksRes struct {
-	Tasks []Task `json:"tasks"`
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ReportResponse - это структура для ответа GetContractReportExists.
@@ -0,0 +1,217 @@
+// internal/tasks/domain/negotiation.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+// NegotiationStatus — состояние переговоров по отклику.
+type NegotiationStatus string
+
+const (
+	NegotiationOpen      NegotiationStatus = "open"      // Стороны обмениваются предложениями
+	NegotiationAgreed    NegotiationStatus = "agreed"    // Одна сторона приняла последнее предложение другой
+	NegotiationWithdrawn NegotiationStatus = "withdrawn" // Исполнитель отозвал отклик
+)
+
+// NegotiationRole — сторона переговоров.
+type NegotiationRole string
+
+const (
+	RoleCustomer NegotiationRole = "customer"
+	RoleExecutor NegotiationRole = "executor"
+)
+
+// OfferAction — действие стороны в переговорах.
+type OfferAction string
+
+const (
+	OfferPropose  OfferAction = "propose"  // Первоначальные условия отклика
+	OfferCounter  OfferAction = "counter"  // Встречное предложение цены или сроков
+	OfferAccept   OfferAction = "accept"   // Согласие с последним предложением другой стороны
+	OfferWithdraw OfferAction = "withdraw" // Исполнитель отзывает отклик
+)
+
+// maxOfferCommentLength — предел длины комментария к предложению в символах.
+const maxOfferCommentLength = 1000
+
+var (
+	// ErrResponseNotFound возвращается, если отклик не найден.
+	ErrResponseNotFound = errors.New("отклик не найден")
+	// ErrInvalidOffer возвращается при неизвестном действии или некорректных условиях предложения.
+	ErrInvalidOffer = errors.New("некорректное предложение")
+	// ErrNegotiationClosed возвращается, если переговоры уже завершены согласием или отзывом отклика.
+	ErrNegotiationClosed = errors.New("переговоры по отклику завершены")
+	// ErrNotYourTurn возвращается, если сторона отвечает на собственное предложение.
+	ErrNotYourTurn = errors.New("сейчас ход другой стороны")
+	// ErrOfferConflict возвращается, если другая сторона успела сделать ход раньше.
+	ErrOfferConflict = errors.New("предложение устарело: другая сторона уже ответила")
+	// ErrNoAgreedTerms возвращается, если контракт нельзя заключить: условия с исполнителем не согласованы.
+	ErrNoAgreedTerms = errors.New("условия с исполнителем не согласованы")
+)
+
+// OfferTerms — условия, о которых договариваются стороны.
+// Цена указывается в минимальных единицах валюты отклика и в ходе переговоров не меняет валюту.
+type OfferTerms struct {
+	Price     int            `json:"price"`
+	Currency  money.Currency `json:"currency"`
+	StartDate *time.Time     `json:"start_date"`
+	EndDate   *time.Time     `json:"end_date"`
+}
+
+// ResponseOffer — одно предложение в истории переговоров по отклику.
+// Каждое предложение хранит полные условия, а не только изменённые поля.
+type ResponseOffer struct {
+	ID         int64           `json:"id"`
+	ResponseID int64           `json:"response_id"`
+	AuthorID   int64           `json:"author_id"`
+	AuthorRole NegotiationRole `json:"author_role"`
+	Action     OfferAction     `json:"action"`
+	OfferTerms
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OfferRequest — ход стороны в переговорах.
+// Для встречного предложения незаполненные поля берутся из последнего предложения.
+type OfferRequest struct {
+	Action    OfferAction `json:"action"`
+	Price     *int        `json:"price"`
+	StartDate *time.Time  `json:"start_date"`
+	EndDate   *time.Time  `json:"end_date"`
+	Comment   string      `json:"comment"`
+}
+
+// Negotiation — текущее состояние переговоров по отклику.
+type Negotiation struct {
+	Response   ProposedResponse
+	CustomerID int64
+	Status     NegotiationStatus
+	LastOffer  ResponseOffer
+}
+
+// RoleOf возвращает сторону переговоров, которую представляет пользователь.
+func (n Negotiation) RoleOf(userID int64) (NegotiationRole, bool) {
+	switch userID {
+	case n.Response.UserID:
+		return RoleExecutor, true
+	case n.CustomerID:
+		return RoleCustomer, true
+	}
+	return "", false
+}
+
+// NextOffer проверяет ход стороны и возвращает новое предложение вместе со статусом переговоров после него.
+// Заказчик может сделать встречное предложение или принять условия исполнителя;
+// исполнитель — принять встречное предложение, предложить свои условия или отозвать отклик.
+func (n Negotiation) NextOffer(role NegotiationRole, authorID int64, req OfferRequest) (ResponseOffer, NegotiationStatus, error) {
+	comment := strings.TrimSpace(req.Comment)
+	if len([]rune(comment)) > maxOfferCommentLength {
+		return ResponseOffer{}, "", fmt.Errorf("%w: комментарий длиннее %d символов", ErrInvalidOffer, maxOfferCommentLength)
+	}
+
+	offer := ResponseOffer{
+		ResponseID: n.Response.ID,
+		AuthorID:   authorID,
+		AuthorRole: role,
+		Action:     req.Action,
+		OfferTerms: n.LastOffer.OfferTerms,
+		Comment:    comment,
+	}
+
+	switch req.Action {
+	case OfferWithdraw:
+		if role != RoleExecutor {
+			return ResponseOffer{}, "", fmt.Errorf("%w: отозвать отклик может только исполнитель", ErrInvalidOffer)
+		}
+		if n.Status == NegotiationWithdrawn {
+			return ResponseOffer{}, "", ErrNegotiationClosed
+		}
+		return offer, NegotiationWithdrawn, nil
+
+	case OfferCounter:
+		if n.Status != NegotiationOpen {
+			return ResponseOffer{}, "", ErrNegotiationClosed
+		}
+		if n.LastOffer.AuthorRole == role {
+			return ResponseOffer{}, "", ErrNotYourTurn
+		}
+		if req.Price == nil && req.StartDate == nil && req.EndDate == nil {
+			return ResponseOffer{}, "", fmt.Errorf("%w: укажите цену или сроки", ErrInvalidOffer)
+		}
+		if req.Price != nil {
+			offer.Price = *req.Price
+		}
+		if req.StartDate != nil {
+			offer.StartDate = req.StartDate
+		}
+		if req.EndDate != nil {
+			offer.EndDate = req.EndDate
+		}
+		if offer.OfferTerms.Equal(n.LastOffer.OfferTerms) {
+			return ResponseOffer{}, "", fmt.Errorf("%w: условия не отличаются от текущего предложения", ErrInvalidOffer)
+		}
+		if err := offer.OfferTerms.Validate(); err != nil {
+			return ResponseOffer{}, "", err
+		}
+		return offer, NegotiationOpen, nil
+
+	case OfferAccept:
+		if n.Status != NegotiationOpen {
+			return ResponseOffer{}, "", ErrNegotiationClosed
+		}
+		if n.LastOffer.AuthorRole == role {
+			return ResponseOffer{}, "", ErrNotYourTurn
+		}
+		if req.Price != nil || req.StartDate != nil || req.EndDate != nil {
+			return ResponseOffer{}, "", fmt.Errorf("%w: принять можно только условия другой стороны без изменений", ErrInvalidOffer)
+		}
+		return offer, NegotiationAgreed, nil
+	}
+
+	return ResponseOffer{}, "", fmt.Errorf("%w: неизвестное действие %q", ErrInvalidOffer, req.Action)
+}
+
+// ContractTerms возвращает условия, на которых заказчик может заключить контракт.
+// Если последнее слово за исполнителем, создание контракта означает согласие с его условиями —
+// в этом случае вторым значением возвращается true, и принятие нужно записать в историю.
+func (n Negotiation) ContractTerms() (OfferTerms, bool, error) {
+	switch n.Status {
+	case NegotiationAgreed:
+		return n.LastOffer.OfferTerms, false, nil
+	case NegotiationOpen:
+		if n.LastOffer.AuthorRole == RoleExecutor {
+			return n.LastOffer.OfferTerms, true, nil
+		}
+		return OfferTerms{}, false, fmt.Errorf("%w: исполнитель ещё не ответил на встречное предложение", ErrNoAgreedTerms)
+	}
+	return OfferTerms{}, false, fmt.Errorf("%w: исполнитель отозвал отклик", ErrNoAgreedTerms)
+}
+
+// Validate проверяет цену и сроки предложения.
+func (t OfferTerms) Validate() error {
+	if t.Price <= 0 {
+		return fmt.Errorf("%w: цена должна быть положительной", ErrInvalidOffer)
+	}
+	if t.StartDate != nil && t.EndDate != nil && t.EndDate.Before(*t.StartDate) {
+		return fmt.Errorf("%w: дата окончания раньше даты начала", ErrInvalidOffer)
+	}
+	return nil
+}
+
+// Equal сообщает, совпадают ли условия.
+func (t OfferTerms) Equal(other OfferTerms) bool {
+	return t.Price == other.Price && sameTime(t.StartDate, other.StartDate) && sameTime(t.EndDate, other.EndDate)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
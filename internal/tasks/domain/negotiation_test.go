@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+func newNegotiation(status NegotiationStatus, lastBy NegotiationRole, price int) Negotiation {
+	return Negotiation{
+		Response:   ProposedResponse{ID: 1, TaskID: 10, UserID: 2, ProposedPrice: money.New(5000, money.Base)},
+		CustomerID: 1,
+		Status:     status,
+		LastOffer:  ResponseOffer{ID: 7, AuthorRole: lastBy, OfferTerms: OfferTerms{Price: price, Currency: money.Base}},
+	}
+}
+
+func TestNegotiationNextOffer(t *testing.T) {
+	price := func(p int) *int { return &p }
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, -1)
+
+	cases := []struct {
+		name       string
+		n          Negotiation
+		role       NegotiationRole
+		req        OfferRequest
+		wantStatus NegotiationStatus
+		wantPrice  int
+		wantErr    error
+	}{
+		{"заказчик предлагает свою цену", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferCounter, Price: price(4000)}, NegotiationOpen, 4000, nil},
+		{"заказчик меняет только сроки", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferCounter, StartDate: &start}, NegotiationOpen, 5000, nil},
+		{"исполнитель принимает встречное", newNegotiation(NegotiationOpen, RoleCustomer, 4000), RoleExecutor,
+			OfferRequest{Action: OfferAccept}, NegotiationAgreed, 4000, nil},
+		{"исполнитель отзывает после согласия", newNegotiation(NegotiationAgreed, RoleExecutor, 4000), RoleExecutor,
+			OfferRequest{Action: OfferWithdraw}, NegotiationWithdrawn, 4000, nil},
+		{"ответ на собственное предложение", newNegotiation(NegotiationOpen, RoleCustomer, 4000), RoleCustomer,
+			OfferRequest{Action: OfferCounter, Price: price(3000)}, "", 0, ErrNotYourTurn},
+		{"встречное после согласия", newNegotiation(NegotiationAgreed, RoleCustomer, 4000), RoleExecutor,
+			OfferRequest{Action: OfferCounter, Price: price(4500)}, "", 0, ErrNegotiationClosed},
+		{"заказчик не может отозвать", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferWithdraw}, "", 0, ErrInvalidOffer},
+		{"те же условия", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferCounter, Price: price(5000)}, "", 0, ErrInvalidOffer},
+		{"окончание раньше начала", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferCounter, StartDate: &start, EndDate: &end}, "", 0, ErrInvalidOffer},
+		{"принятие с изменениями", newNegotiation(NegotiationOpen, RoleCustomer, 4000), RoleExecutor,
+			OfferRequest{Action: OfferAccept, Price: price(4200)}, "", 0, ErrInvalidOffer},
+		{"неизвестное действие", newNegotiation(NegotiationOpen, RoleExecutor, 5000), RoleCustomer,
+			OfferRequest{Action: OfferPropose}, "", 0, ErrInvalidOffer},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			offer, status, err := c.n.NextOffer(c.role, 99, c.req)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("ожидалась ошибка %v, получено %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if status != c.wantStatus || offer.Price != c.wantPrice || offer.AuthorRole != c.role || offer.ResponseID != 1 {
+				t.Errorf("получено %+v со статусом %q", offer, status)
+			}
+		})
+	}
+}
+
+func TestNegotiationContractTerms(t *testing.T) {
+	terms, implicit, err := newNegotiation(NegotiationAgreed, RoleCustomer, 4000).ContractTerms()
+	if err != nil || implicit || terms.Price != 4000 {
+		t.Errorf("согласованные условия: %+v %v %v", terms, implicit, err)
+	}
+
+	terms, implicit, err = newNegotiation(NegotiationOpen, RoleExecutor, 5000).ContractTerms()
+	if err != nil || !implicit || terms.Price != 5000 {
+		t.Errorf("условия исполнителя принимаются контрактом: %+v %v %v", terms, implicit, err)
+	}
+
+	if _, _, err := newNegotiation(NegotiationOpen, RoleCustomer, 4000).ContractTerms(); !errors.Is(err, ErrNoAgreedTerms) {
+		t.Errorf("встречное без ответа: ожидалась ErrNoAgreedTerms, получено %v", err)
+	}
+	if _, _, err := newNegotiation(NegotiationWithdrawn, RoleExecutor, 5000).ContractTerms(); !errors.Is(err, ErrNoAgreedTerms) {
+		t.Errorf("отозванный отклик: ожидалась ErrNoAgreedTerms, получено %v", err)
+	}
+}
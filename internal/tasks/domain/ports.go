@@ -4,21 +4,21 @@ package domain
 import (
 	"context"
 	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
 )
 
 // TasksService определяет интерфейс для бизнес-логики задач.
 type TasksService interface {
 	CreateTask(ctx context.Context, task Task, userID int64) (int, error)
-	GetTasks(page int) ([]Task, string, error)
-	SearchTasks(ctx context.Context, searchStr string, filterParams map[string]interface{}, pageSize, offset int) ([]Task, error)
+	GetTasks(ctx context.Context, page pagination.Request) (pagination.Page[Task], error)
 	CreateContract(ctx context.Context, req CreateContractRequest, creatorID int64) (int64, error)
 	GetTasksResponses(ctx context.Context, userID int64) ([]Task, error)
-	CreateReport(ctx context.Context, report Report) error
+	CreateReport(ctx context.Context, userID int64, report Report) error // Отчёт по этапу сдаёт исполнитель; повторно — только после отклонения
 	CheckContract(ctx context.Context, taskID, customerID, executorID int64) (*Contract, error)
-	CreateReview(ctx context.Context, review Review) (int, error)
 	GetContractReportExists(ctx context.Context, contractID int) (bool, *Report, error)
-	GetReviewsByUser(ctx context.Context, userID string) ([]Review, error)
-	UpdateReport(ctx context.Context, contractID int64, feedback *string, confirmation *bool) error
+	UpdateReport(ctx context.Context, contractID, userID int64, milestoneID *int64, feedback *string, confirmation *bool) error // Решение по отчёту принимает заказчик
 	CancelTask(ctx context.Context, taskID, userID int64) error
 	GetAllCategories(ctx context.Context) ([]Category, error)
 	GetCategoryByID(ctx context.Context, id int) (string, error)
@@ -26,57 +26,123 @@ type TasksService interface {
 	DeleteResponse(ctx context.Context, responseID, userID int64) error
 	DeleteTaskByID(ctx context.Context, userID, taskID int64) error
 	TotalResponses(taskID int64) (int, error)
-	GetResponsesHandler(ctx context.Context, taskID, userID int64) ([]ResponseWithUser, error)
+	GetResponsesHandler(ctx context.Context, taskID, userID int64, page pagination.Request) (pagination.Page[ResponseWithUser], error)
 	GetSubcategories(ctx context.Context, categoryID string) ([]Subcategory, error)
 	GetTaskViewsCount(ctx context.Context, taskID int64) (int64, error)
 	GetTaskHandler(ctx context.Context, taskID int64) (*Task, error)
 	GetTasksByUserID(ctx context.Context, userID int64) ([]*Task, error)              // Возвращает задачи, созданные пользователем
 	GetTasksCountResponses(ctx context.Context, userID int64) ([]TaskResponse, error) // Задачи, на которые пользователь откликнулся, с количеством откликов
 	GetTasksCount(ctx context.Context, userID int64) (int, []TaskResponse, error)     // Общее количество созданных задач и откликов
-	GetUserReviewsStats(ctx context.Context, userID string) (ReviewStats, error)
 	RecordTaskView(ctx context.Context, taskID, userID int64) error
 	GetResponseByTaskAndUser(ctx context.Context, taskID, userID int64) (ProposedResponse, error)
 	CheckResponseView(ctx context.Context, responseID, userID int64) (bool, error)
+	GetTaskStatusHistory(ctx context.Context, taskID, userID int64) ([]TaskStatusChange, error)
+	UpdateTask(ctx context.Context, taskID, userID int64, req UpdateTaskRequest) (*Task, error)
+	GetTaskRevisions(ctx context.Context, taskID, userID int64) ([]TaskRevision, error) // Заказчику и откликнувшимся исполнителям
+
+	CreateSavedSearch(ctx context.Context, userID int64, req SavedSearchRequest) (*SavedSearch, error)
+	ListSavedSearches(ctx context.Context, userID int64) ([]SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, searchID, userID int64, req SavedSearchRequest) (*SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, searchID, userID int64) error
+	HandleTaskCreated(event any)                                     // Сопоставляет новую задачу с сохранёнными поисками
+	SendSavedSearchDigests(ctx context.Context, now time.Time) error // Отправляет часовые и суточные сводки, срок которых наступил
+
+	GetResponseOffers(ctx context.Context, responseID, userID int64) ([]ResponseOffer, error) // История переговоров, видна только сторонам
+	MakeOffer(ctx context.Context, responseID, userID int64, req OfferRequest) (*ResponseOffer, error)
+
+	GetContractMilestones(ctx context.Context, contractID, userID int64) ([]Milestone, error)
+	ReplaceMilestones(ctx context.Context, contractID, userID int64, plan []MilestoneRequest) ([]Milestone, error)
+
+	HandleDisputeResolved(event any) // Закрывает контракт и завершает или отменяет задачу по решению арбитра
+	HandleAuctionClosed(event any)   // Заключает контракт с победителем торгов
 }
 
 // TasksRepository определяет интерфейс для доступа к данным задач.
 type TasksRepository interface {
-	InsertTaskIntoDB(ctx context.Context, task Task, userID int64) (int, error)
-	FetchTasksFromDB(page int) ([]Task, error) // Убрали message, так как это логика сервиса
-	SearchTasks(ctx context.Context, queryStr string, filterParams map[string]interface{}, limit, offset int) ([]Task, error)
+	InsertTaskIntoDB(ctx context.Context, task Task, userID int64) (int, error) // Сохраняет и task.Locations
+	FetchTasksFromDB(ctx context.Context, page pagination.Request) (pagination.Page[Task], error)
 	GetTaskOwner(ctx context.Context, taskID int64) (int64, error)
 	GetActiveStatusID(ctx context.Context) (int64, error)
-	CreateContractInDB(ctx context.Context, taskID, executorID, customerID int64, createdAt time.Time, statusID int64) (int64, error)
+	// StartContract сохраняет контракт с планом этапов и переводит открытую задачу в работу в одной транзакции.
+	// Если задача уже не открыта, ничего не сохраняет и возвращает ErrStatusConflict.
+	StartContract(ctx context.Context, contract NewContract) (int64, error)
 	GetTasksByUserID(ctx context.Context, userID int64) ([]Task, error) // Задачи, на которые пользователь откликнулся
 	GetTasksUserID(ctx context.Context, userID int64) ([]Task, error)   // Задачи, созданные пользователем
-	CreateReport(ctx context.Context, contractID, taskID int64, executorComments string, executionStatus bool) error
+	CreateReport(ctx context.Context, contractID, taskID, milestoneID int64, executorComments string, executionStatus bool) error
 	GetContractByDetails(ctx context.Context, taskID, executorID, customerID int64) (*Contract, error)
-	InsertReviewInDB(ctx context.Context, review Review) (int, error)
 	CheckResponseView(ctx context.Context, responseID, userID int64) (bool, error)
 	GetReportByContractID(ctx context.Context, contractID int64) (*Report, error)
-	FetchReviewsByUserFromDB(userID string) ([]Review, error)
+	// UpdateReport сохраняет отзыв заказчика и, если customerConfirmation задан, его решение по отчёту.
+	// Решение принимается только по отчёту, который ждёт решения, иначе возвращается ErrReportNotSubmitted.
 	UpdateReport(ctx context.Context, reportID int64, customerFeedback string, customerConfirmation *bool) error
 	CheckTaskOwnership(ctx context.Context, taskID, userID int64) (bool, error)
-	CancelTask(ctx context.Context, taskID int64) error
 	GetAllCategories(ctx context.Context) ([]Category, error)
 	FetchCategoriesFromDB() ([]Category, error) // Возможно, этот метод будет объединен с GetAllCategories
 	GetCategoryByID(ctx context.Context, id int) (string, error)
 	InsertResponseIntoDB(newResponse ProposedResponse) (ProposedResponse, error)
 	DeleteResponse(ctx context.Context, responseID, userID int64) error
-	DeleteTask(ctx context.Context, userID, taskID int64) error
+	DeleteTask(ctx context.Context, userID, taskID int64) error // ErrTaskHasDocuments, если по контрактам выпущены документы
+	HasContractDocuments(ctx context.Context, taskID int64) (bool, error)
 	GetContractExists(ctx context.Context, taskID int64, executorID int64, customerID int64) (bool, error)
 	TotalResponses(taskID int64) (int, error)
 	GetTasks(ctx context.Context, userID int64) ([]*Task, error)
 
-	GetResponsesByTaskID(taskID, userID int64) ([]ResponseWithUser, error)
+	GetResponsesByTaskID(taskID, userID int64, page pagination.Request) (pagination.Page[ResponseWithUser], error)
 	FetchSubcategoriesByCategoryIDFromDB(categoryID string) ([]Subcategory, error)
 	CountTaskViews(ctx context.Context, taskID int64) (int64, error)
 	GetTaskByID(ctx context.Context, id int64) (*Task, error)
 	GetUserCreatedTasksCount(ctx context.Context, userID int64) (int, error)
 	GetTaskResponsesCountByUserID(ctx context.Context, userID int64) (map[int64]int, error) // Для задач, созданных пользователем
 	GetTaskResponsesCountUserID(ctx context.Context, userID int64) (map[int64]int, error)   // Для задач, на которые пользователь откликнулся
-	FetchUserReviewsStatsFromDB(ctx context.Context, userID string) (ReviewStats, error)
 	RecordTaskView(ctx context.Context, taskID, userID int64) error
 	GetResponseByTaskAndUser(ctx context.Context, taskID, userID int64) (ProposedResponse, error)
 	ResponseExists(ctx context.Context, taskID, userID int64) (bool, error) // Добавлен, чтобы сервис мог использовать
+
+	GetTaskStatus(ctx context.Context, taskID int64) (TaskStatusCode, error)
+	GetTransitionGuards(ctx context.Context, taskID int64) (TransitionGuards, error)
+	UpdateTaskStatus(ctx context.Context, change TaskStatusChange) error // Меняет статус, только если текущий совпадает с change.FromStatus
+	GetTaskStatusHistory(ctx context.Context, taskID int64) ([]TaskStatusChange, error)
+	IsTaskParticipant(ctx context.Context, taskID, userID int64) (bool, error) // Заказчик или исполнитель по контракту
+
+	// UpdateTask сохраняет новую редакцию условий и возвращает её номер.
+	// Если задача уже не открыта, ничего не меняет и возвращает ErrStatusConflict.
+	UpdateTask(ctx context.Context, taskID, userID int64, req UpdateTaskRequest, changedAt time.Time) (int, error)
+	GetTaskRevisions(ctx context.Context, taskID int64) ([]TaskRevision, error)
+	GetResponderIDs(ctx context.Context, taskID int64) ([]int64, error)
+
+	CountSavedSearches(ctx context.Context, userID int64) (int, error)
+	InsertSavedSearch(ctx context.Context, search SavedSearch) (int64, error)
+	ListSavedSearchesByUser(ctx context.Context, userID int64) ([]SavedSearch, error)
+	GetSavedSearch(ctx context.Context, searchID, userID int64) (*SavedSearch, error) // ErrSavedSearchNotFound, если поиск чужой
+	UpdateSavedSearch(ctx context.Context, search SavedSearch) (bool, error)
+	DeleteSavedSearch(ctx context.Context, searchID, userID int64) (bool, error)
+	ListSavedSearchesExceptUser(ctx context.Context, userID int64) ([]SavedSearch, error) // Поиски всех пользователей, кроме автора задачи
+	TaskMatchesText(ctx context.Context, taskID int64, text string) (bool, error)
+	InsertSavedSearchMatch(ctx context.Context, searchID, taskID int64, matchedAt time.Time, notified bool) error
+	ListPendingDigests(ctx context.Context) ([]SavedSearchDigest, error) // Неотправленные совпадения поисков со сводками
+	MarkDigestSent(ctx context.Context, searchID int64, taskIDs []int64, sentAt time.Time) error
+
+	GetNegotiation(ctx context.Context, responseID int64) (*Negotiation, error)                      // ErrResponseNotFound, если отклика нет
+	GetNegotiationByTaskAndUser(ctx context.Context, taskID, executorID int64) (*Negotiation, error) // ErrResponseNotFound, если исполнитель не откликался
+	AppendOffer(ctx context.Context, offer ResponseOffer, status NegotiationStatus, prevOfferID int64) (ResponseOffer, error)
+	ListResponseOffers(ctx context.Context, responseID int64) ([]ResponseOffer, error)
+
+	GetContractByID(ctx context.Context, contractID int64) (*Contract, error)               // nil, nil, если контракта нет
+	GetActiveContractByTask(ctx context.Context, taskID int64) (*Contract, error)           // nil, nil, если действующего контракта нет
+	ListMilestones(ctx context.Context, contractID int64) ([]Milestone, error)              // С отчётами, в порядке этапов
+	ReplaceMilestones(ctx context.Context, contractID int64, plan []MilestoneRequest) error // ErrMilestonesLocked, если отчёты уже сданы
+	// ResubmitReport заменяет отклонённый отчёт; ErrReportAlreadySubmitted, если отчёт уже подтверждён.
+	ResubmitReport(ctx context.Context, reportID int64, executorComments string, executionStatus bool) error
+	// FinishContract закрывает контракт и меняет статус его задачи в одной транзакции.
+	// Если статус задачи уже не равен change.FromStatus, ничего не меняет и возвращает ErrStatusConflict.
+	FinishContract(ctx context.Context, contractID int64, change TaskStatusChange) error
+	// ConfirmLastMilestone подтверждает отчёт по последнему этапу, закрывает контракт и меняет статус
+	// задачи в одной транзакции. ErrReportNotSubmitted, если отчёт уже не ждёт решения;
+	// ErrStatusConflict, если статус задачи уже не равен change.FromStatus.
+	ConfirmLastMilestone(ctx context.Context, reportID int64, customerFeedback string, contractID int64, change TaskStatusChange) error
+}
+
+// EventBus — интерфейс для публикации событий.
+type EventBus interface {
+	Publish(event eventbus.Event)
 }
@@ -0,0 +1,177 @@
+// internal/tasks/domain/saved_search.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+)
+
+// AlertFrequency — как часто пользователь получает оповещения о новых задачах по сохранённому поиску.
+type AlertFrequency string
+
+const (
+	AlertInstant AlertFrequency = "instant" // Сразу после публикации подходящей задачи
+	AlertHourly  AlertFrequency = "hourly"  // Сводка раз в час
+	AlertDaily   AlertFrequency = "daily"   // Сводка раз в сутки
+)
+
+// IsValid сообщает, поддерживается ли частота оповещений.
+func (f AlertFrequency) IsValid() bool {
+	switch f {
+	case AlertInstant, AlertHourly, AlertDaily:
+		return true
+	}
+	return false
+}
+
+// Period возвращает интервал между сводками; для мгновенных оповещений — 0.
+func (f AlertFrequency) Period() time.Duration {
+	switch f {
+	case AlertHourly:
+		return time.Hour
+	case AlertDaily:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// MaxSavedSearches — сколько сохранённых поисков может быть у одного пользователя.
+const MaxSavedSearches = 20
+
+// maxSavedSearchNameLength — предел длины названия сохранённого поиска в символах.
+const maxSavedSearchNameLength = 100
+
+var (
+	// ErrInvalidSavedSearch возвращается при некорректном названии, фильтрах или частоте оповещений.
+	ErrInvalidSavedSearch = errors.New("некорректный сохранённый поиск")
+	// ErrSavedSearchNotFound возвращается, если поиск не найден или принадлежит другому пользователю.
+	ErrSavedSearchNotFound = errors.New("сохранённый поиск не найден")
+)
+
+// SavedSearch — набор фильтров поиска задач, сохранённый пользователем.
+// Filters хранятся в том же виде, что и параметры GET /tasks/search.
+type SavedSearch struct {
+	ID             int64          `json:"id"`
+	UserID         int64          `json:"user_id"`
+	Name           string         `json:"name"`
+	Filters        url.Values     `json:"filters"`
+	Frequency      AlertFrequency `json:"frequency"`
+	CreatedAt      time.Time      `json:"created_at"`
+	LastNotifiedAt *time.Time     `json:"last_notified_at"` // Когда отправлена последняя сводка
+}
+
+// SavedSearchRequest — запрос на создание или изменение сохранённого поиска.
+type SavedSearchRequest struct {
+	Name      string         `json:"name"`
+	Filters   url.Values     `json:"filters"`
+	Frequency AlertFrequency `json:"frequency"`
+}
+
+// Параметры поиска, которые не относятся к фильтрам и не сохраняются.
+var savedSearchIgnoredParams = []string{searchParamCursor, searchParamLimit, searchParamSort}
+
+// Normalize проверяет запрос и убирает из фильтров параметры страницы и сортировки.
+func (r *SavedSearchRequest) Normalize() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: название не может быть пустым", ErrInvalidSavedSearch)
+	}
+	if len([]rune(r.Name)) > maxSavedSearchNameLength {
+		return fmt.Errorf("%w: название длиннее %d символов", ErrInvalidSavedSearch, maxSavedSearchNameLength)
+	}
+	if r.Frequency == "" {
+		r.Frequency = AlertInstant
+	}
+	if !r.Frequency.IsValid() {
+		return fmt.Errorf("%w: неизвестная частота оповещений %q", ErrInvalidSavedSearch, r.Frequency)
+	}
+
+	filters := url.Values{}
+	for key, values := range r.Filters {
+		if !slices.Contains(savedSearchIgnoredParams, key) {
+			filters[key] = values
+		}
+	}
+	if _, err := ParseTaskSearchQuery(filters); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSavedSearch, err)
+	}
+	r.Filters = filters
+	return nil
+}
+
+// DigestDue сообщает, пора ли отправить сводку: с прошлой сводки (или с создания поиска) прошёл период частоты.
+// Для мгновенных оповещений сводки не отправляются.
+func (s SavedSearch) DigestDue(now time.Time) bool {
+	period := s.Frequency.Period()
+	if period == 0 {
+		return false
+	}
+	since := s.CreatedAt
+	if s.LastNotifiedAt != nil {
+		since = *s.LastNotifiedAt
+	}
+	return !now.Before(since.Add(period))
+}
+
+// Query возвращает фильтры сохранённого поиска в виде запроса поиска задач.
+func (s SavedSearch) Query() (TaskSearchQuery, error) {
+	return ParseTaskSearchQuery(s.Filters)
+}
+
+// MatchesTask проверяет задачу на соответствие всем фильтрам запроса, кроме полнотекстового:
+// его проверяет репозиторий, чтобы морфология совпадала с поиском.
+// Стоимость сравнивается в базовой валюте: фильтр по стоимости должен быть уже пересчитан в неё.
+func (q TaskSearchQuery) MatchesTask(t Task) bool {
+	if len(q.CategoryIDs) > 0 && (t.CategoryID == nil || !slices.Contains(q.CategoryIDs, *t.CategoryID)) {
+		return false
+	}
+	if len(q.SubcategoryIDs) > 0 && (t.SubcategoryID == nil || !slices.Contains(q.SubcategoryIDs, *t.SubcategoryID)) {
+		return false
+	}
+	if q.CostMin != nil && (t.CostBase == nil || *t.CostBase < *q.CostMin) {
+		return false
+	}
+	if q.CostMax != nil && (t.CostBase == nil || *t.CostBase > *q.CostMax) {
+		return false
+	}
+	if q.CreatedFrom != nil && t.CreatedAt.Before(*q.CreatedFrom) {
+		return false
+	}
+	if q.CreatedTo != nil && t.CreatedAt.After(*q.CreatedTo) {
+		return false
+	}
+	if q.StartFrom != nil && (t.StartDate == nil || t.StartDate.Before(*q.StartFrom)) {
+		return false
+	}
+	if q.StartTo != nil && (t.StartDate == nil || t.StartDate.After(*q.StartTo)) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, t.StatusCode) {
+		return false
+	}
+	if q.ServiceLocation != "" && q.ServiceLocation != t.ServiceLocation {
+		return false
+	}
+	if q.Near != nil && q.RadiusKm != nil {
+		for _, loc := range t.Locations {
+			if loc.Point != nil && geo.DistanceKm(*q.Near, *loc.Point) <= *q.RadiusKm {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// SavedSearchDigest — накопленные совпадения сохранённого поиска, которые пора отправить сводкой.
+type SavedSearchDigest struct {
+	Search SavedSearch
+	Tasks  []tasks.MatchedTask
+}
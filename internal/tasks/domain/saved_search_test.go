@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+)
+
+func TestSavedSearchRequestNormalize(t *testing.T) {
+	req := SavedSearchRequest{
+		Name:    "  Ремонт в Москве ",
+		Filters: url.Values{"search": {"ремонт"}, "cursor": {"abc"}, "limit": {"5"}, "sort": {"newest"}},
+	}
+	if err := req.Normalize(); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if req.Name != "Ремонт в Москве" || req.Frequency != AlertInstant {
+		t.Errorf("название или частота по умолчанию неверны: %q %q", req.Name, req.Frequency)
+	}
+	if len(req.Filters) != 1 || req.Filters.Get("search") != "ремонт" {
+		t.Errorf("параметры страницы и сортировки должны отбрасываться: %v", req.Filters)
+	}
+
+	invalid := []SavedSearchRequest{
+		{Name: "", Filters: url.Values{}},
+		{Name: "x", Frequency: "weekly"},
+		{Name: "x", Filters: url.Values{"title": {"x"}}},
+	}
+	for _, r := range invalid {
+		if err := r.Normalize(); !errors.Is(err, ErrInvalidSavedSearch) {
+			t.Errorf("%+v: ожидалась ErrInvalidSavedSearch, получено %v", r, err)
+		}
+	}
+}
+
+func TestTaskSearchQueryMatchesTask(t *testing.T) {
+	category, cost := 3, 500000
+	task := Task{
+		CategoryID: &category,
+		Cost:       &money.Money{Amount: cost, Currency: money.Base},
+		CostBase:   &cost,
+		CreatedAt:  time.Now(),
+		StatusCode: StatusActive,
+		Locations:  []TaskLocation{{Address: "Москва", Point: &geo.Point{Lat: 55.7558, Lon: 37.6173}}},
+	}
+
+	cases := []struct {
+		filters url.Values
+		want    bool
+	}{
+		{url.Values{}, true},
+		{url.Values{"category": {"1,3"}, "cost_min": {"100000"}}, true},
+		{url.Values{"category": {"1"}}, false},
+		{url.Values{"cost_max": {"400000"}}, false},
+		{url.Values{"status": {"101"}}, false},
+		{url.Values{"lat": {"55.75"}, "lon": {"37.6"}, "radius_km": {"10"}}, true},
+		{url.Values{"lat": {"59.93"}, "lon": {"30.33"}, "radius_km": {"50"}}, false},
+	}
+	for _, c := range cases {
+		q, err := ParseTaskSearchQuery(c.filters)
+		if err != nil {
+			t.Fatalf("%v: неожиданная ошибка: %v", c.filters, err)
+		}
+		if got := q.MatchesTask(task); got != c.want {
+			t.Errorf("%v: ожидалось %v, получено %v", c.filters, c.want, got)
+		}
+	}
+}
+
+func TestSavedSearchDigestDue(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sent := created.Add(3 * time.Hour)
+
+	cases := []struct {
+		search SavedSearch
+		now    time.Time
+		want   bool
+	}{
+		{SavedSearch{Frequency: AlertInstant, CreatedAt: created}, created.Add(48 * time.Hour), false},
+		{SavedSearch{Frequency: AlertHourly, CreatedAt: created}, created.Add(59 * time.Minute), false},
+		{SavedSearch{Frequency: AlertHourly, CreatedAt: created}, created.Add(time.Hour), true},
+		{SavedSearch{Frequency: AlertDaily, CreatedAt: created, LastNotifiedAt: &sent}, sent.Add(23 * time.Hour), false},
+		{SavedSearch{Frequency: AlertDaily, CreatedAt: created, LastNotifiedAt: &sent}, sent.Add(24 * time.Hour), true},
+	}
+	for i, c := range cases {
+		if got := c.search.DigestDue(c.now); got != c.want {
+			t.Errorf("случай %d: ожидалось %v, получено %v", i, c.want, got)
+		}
+	}
+}
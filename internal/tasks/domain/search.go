@@ -0,0 +1,307 @@
+// internal/tasks/domain/search.go
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+)
+
+// TaskSortOrder — порядок сортировки результатов поиска задач.
+type TaskSortOrder string
+
+const (
+	SortRelevance TaskSortOrder = "relevance"  // По релевантности полнотекстового запроса
+	SortNewest    TaskSortOrder = "newest"     // Сначала новые
+	SortPriceAsc  TaskSortOrder = "price_asc"  // Сначала дешёвые
+	SortPriceDesc TaskSortOrder = "price_desc" // Сначала дорогие
+	SortDistance  TaskSortOrder = "distance"   // Сначала ближайшие к точке поиска
+)
+
+// IsValid сообщает, поддерживается ли порядок сортировки.
+func (o TaskSortOrder) IsValid() bool {
+	switch o {
+	case SortRelevance, SortNewest, SortPriceAsc, SortPriceDesc, SortDistance:
+		return true
+	}
+	return false
+}
+
+// ErrInvalidSearchQuery возвращается при неизвестном фильтре или некорректном значении фильтра.
+var ErrInvalidSearchQuery = errors.New("некорректный поисковый запрос")
+
+// TaskSearchQuery — типизированный запрос поиска задач.
+// Пустые поля означают отсутствие соответствующего фильтра.
+type TaskSearchQuery struct {
+	Text            string           // Полнотекстовый запрос по названию и описанию
+	CategoryIDs     []int            // Любая из категорий
+	SubcategoryIDs  []int            // Любая из подкатегорий
+	CostMin         *int             // Стоимость не меньше, в минимальных единицах CostCurrency
+	CostMax         *int             // Стоимость не больше, в минимальных единицах CostCurrency
+	CostCurrency    money.Currency   // Валюта фильтра по стоимости; по умолчанию базовая
+	CreatedFrom     *time.Time       // Создана не раньше
+	CreatedTo       *time.Time       // Создана не позже
+	StartFrom       *time.Time       // Начало работ не раньше
+	StartTo         *time.Time       // Начало работ не позже
+	Statuses        []TaskStatusCode // Любой из статусов; по умолчанию только открытые задачи
+	ServiceLocation string           // Место оказания услуги
+	Near            *geo.Point       // Точка поиска для фильтра по радиусу и сортировки по расстоянию
+	RadiusKm        *float64         // Хотя бы один адрес задачи не дальше RadiusKm от Near
+	Sort            TaskSortOrder
+	Page            pagination.Request
+}
+
+// defaultSearchLimit — размер страницы поиска, если клиент не указал limit.
+const defaultSearchLimit = 10
+
+// Параметры запроса, которые понимает поиск задач. Всё остальное отклоняется.
+const (
+	searchParamText            = "search"
+	searchParamCursor          = "cursor"
+	searchParamLimit           = "limit"
+	searchParamCategory        = "category"
+	searchParamSubcategory     = "subcategory"
+	searchParamCostMin         = "cost_min"
+	searchParamCostMax         = "cost_max"
+	searchParamCostCurrency    = "cost_currency"
+	searchParamCreatedFrom     = "created_from"
+	searchParamCreatedTo       = "created_to"
+	searchParamStartFrom       = "start_from"
+	searchParamStartTo         = "start_to"
+	searchParamStatus          = "status"
+	searchParamServiceLocation = "service_location"
+	searchParamSort            = "sort"
+	searchParamLat             = "lat"
+	searchParamLon             = "lon"
+	searchParamRadius          = "radius_km"
+)
+
+var knownSearchParams = map[string]bool{
+	searchParamText:            true,
+	searchParamCursor:          true,
+	searchParamLimit:           true,
+	searchParamCategory:        true,
+	searchParamSubcategory:     true,
+	searchParamCostMin:         true,
+	searchParamCostMax:         true,
+	searchParamCostCurrency:    true,
+	searchParamCreatedFrom:     true,
+	searchParamCreatedTo:       true,
+	searchParamStartFrom:       true,
+	searchParamStartTo:         true,
+	searchParamStatus:          true,
+	searchParamServiceLocation: true,
+	searchParamSort:            true,
+	searchParamLat:             true,
+	searchParamLon:             true,
+	searchParamRadius:          true,
+}
+
+// ParseTaskSearchQuery разбирает параметры URL в TaskSearchQuery.
+// Списки можно передавать повтором параметра или через запятую (?category=1,2).
+func ParseTaskSearchQuery(values url.Values) (TaskSearchQuery, error) {
+	var unknown []string
+	for key := range values {
+		if !knownSearchParams[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return TaskSearchQuery{}, fmt.Errorf("%w: неизвестные фильтры: %s", ErrInvalidSearchQuery, strings.Join(unknown, ", "))
+	}
+
+	var q TaskSearchQuery
+	var err error
+
+	q.Text = strings.TrimSpace(values.Get(searchParamText))
+	q.ServiceLocation = strings.TrimSpace(values.Get(searchParamServiceLocation))
+
+	if q.CategoryIDs, err = parseIntList(values, searchParamCategory); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.SubcategoryIDs, err = parseIntList(values, searchParamSubcategory); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.CostMin, err = parseOptionalInt(values, searchParamCostMin); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.CostMax, err = parseOptionalInt(values, searchParamCostMax); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if raw := values.Get(searchParamCostCurrency); raw != "" {
+		if q.CostCurrency, err = money.ParseCurrency(raw); err != nil {
+			return TaskSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+		}
+	}
+	if q.CreatedFrom, err = parseOptionalDate(values, searchParamCreatedFrom, false); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.CreatedTo, err = parseOptionalDate(values, searchParamCreatedTo, true); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.StartFrom, err = parseOptionalDate(values, searchParamStartFrom, false); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	if q.StartTo, err = parseOptionalDate(values, searchParamStartTo, true); err != nil {
+		return TaskSearchQuery{}, err
+	}
+
+	statuses, err := parseIntList(values, searchParamStatus)
+	if err != nil {
+		return TaskSearchQuery{}, err
+	}
+	for _, s := range statuses {
+		q.Statuses = append(q.Statuses, TaskStatusCode(s))
+	}
+
+	if q.Near, err = geo.ParsePoint(values.Get(searchParamLat), values.Get(searchParamLon)); err != nil {
+		return TaskSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+	if q.RadiusKm, err = geo.ParseRadiusKm(values.Get(searchParamRadius)); err != nil {
+		return TaskSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+
+	q.Sort = TaskSortOrder(values.Get(searchParamSort))
+
+	if q.Page, err = pagination.ParseRequest(values.Get(searchParamCursor), values.Get(searchParamLimit), defaultSearchLimit); err != nil {
+		return TaskSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+
+	if err := q.Validate(); err != nil {
+		return TaskSearchQuery{}, err
+	}
+	return q, nil
+}
+
+// Validate проверяет согласованность фильтров и подставляет значения по умолчанию.
+func (q *TaskSearchQuery) Validate() error {
+	if q.CostMin != nil && *q.CostMin < 0 {
+		return fmt.Errorf("%w: %s не может быть отрицательным", ErrInvalidSearchQuery, searchParamCostMin)
+	}
+	if q.CostMin != nil && q.CostMax != nil && *q.CostMin > *q.CostMax {
+		return fmt.Errorf("%w: %s больше %s", ErrInvalidSearchQuery, searchParamCostMin, searchParamCostMax)
+	}
+	if q.CostCurrency == "" {
+		q.CostCurrency = money.Base
+	}
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return fmt.Errorf("%w: %s позже %s", ErrInvalidSearchQuery, searchParamCreatedFrom, searchParamCreatedTo)
+	}
+	if q.StartFrom != nil && q.StartTo != nil && q.StartFrom.After(*q.StartTo) {
+		return fmt.Errorf("%w: %s позже %s", ErrInvalidSearchQuery, searchParamStartFrom, searchParamStartTo)
+	}
+	for _, s := range q.Statuses {
+		if !s.IsValid() {
+			return fmt.Errorf("%w: неизвестный статус %d", ErrInvalidSearchQuery, s)
+		}
+	}
+
+	if q.RadiusKm != nil && q.Near == nil {
+		return fmt.Errorf("%w: для %s нужны %s и %s", ErrInvalidSearchQuery, searchParamRadius, searchParamLat, searchParamLon)
+	}
+
+	if q.Sort == "" {
+		q.Sort = SortRelevance
+	}
+	if !q.Sort.IsValid() {
+		return fmt.Errorf("%w: неизвестная сортировка %q", ErrInvalidSearchQuery, q.Sort)
+	}
+	if q.Sort == SortDistance && q.Near == nil {
+		return fmt.Errorf("%w: сортировка по расстоянию требует %s и %s", ErrInvalidSearchQuery, searchParamLat, searchParamLon)
+	}
+	// Без текста релевантность не определена.
+	if q.Sort == SortRelevance && q.Text == "" {
+		q.Sort = SortNewest
+	}
+	if len(q.Statuses) == 0 {
+		q.Statuses = []TaskStatusCode{StatusActive}
+	}
+	if q.Page.Limit <= 0 {
+		q.Page.Limit = defaultSearchLimit
+	}
+	// Курсор привязан к сортировке: ключ страницы по цене бессмыслен для сортировки по дате.
+	if err := q.Page.CheckSort(string(q.Sort)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+	return nil
+}
+
+func parseIntList(values url.Values, key string) ([]int, error) {
+	var result []int
+	for _, raw := range values[key] {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s должен содержать целые числа, получено %q", ErrInvalidSearchQuery, key, part)
+			}
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func parseOptionalInt(values url.Values, key string) (*int, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s должен быть целым числом, получено %q", ErrInvalidSearchQuery, key, raw)
+	}
+	return &n, nil
+}
+
+// parseOptionalDate принимает дату в формате 2006-01-02 или RFC 3339.
+// Для верхней границы диапазона дата без времени включает весь день.
+func parseOptionalDate(values url.Values, key string, endOfDay bool) (*time.Time, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return &t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%w: %s должен быть датой в формате ГГГГ-ММ-ДД, получено %q", ErrInvalidSearchQuery, key, raw)
+}
+
+// InBaseCurrency возвращает копию запроса с фильтром по стоимости в базовой валюте: задачи хранят цену в ней же.
+// Границы исходного запроса не меняются. Если курса валюты нет, возвращает money.ErrRateNotFound.
+func (q TaskSearchQuery) InBaseCurrency(ctx context.Context, currencies ports.CurrencyConverter) (TaskSearchQuery, error) {
+	if q.CostCurrency == money.Base {
+		return q, nil
+	}
+	for _, bound := range []**int{&q.CostMin, &q.CostMax} {
+		if *bound == nil {
+			continue
+		}
+		base, err := currencies.ToBase(ctx, money.New(**bound, q.CostCurrency))
+		if err != nil {
+			return TaskSearchQuery{}, fmt.Errorf("ошибка пересчёта фильтра по стоимости: %w", err)
+		}
+		*bound = &base
+	}
+	q.CostCurrency = money.Base
+	return q, nil
+}
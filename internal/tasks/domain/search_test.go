@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+func TestParseTaskSearchQuery(t *testing.T) {
+	values := url.Values{
+		"search":      {"ремонт"},
+		"category":    {"1,2", "3"},
+		"cost_min":    {"1000"},
+		"cost_max":    {"5000"},
+		"created_to":  {"2024-05-01"},
+		"status":      {"100,101"},
+		"sort":        {"price_asc"},
+		"subcategory": {""},
+	}
+
+	q, err := ParseTaskSearchQuery(values)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(q.CategoryIDs) != 3 || q.CategoryIDs[2] != 3 {
+		t.Errorf("категории разобраны неверно: %v", q.CategoryIDs)
+	}
+	if len(q.SubcategoryIDs) != 0 {
+		t.Errorf("пустой фильтр подкатегорий должен игнорироваться: %v", q.SubcategoryIDs)
+	}
+	if q.CostMin == nil || *q.CostMin != 1000 || q.CostMax == nil || *q.CostMax != 5000 {
+		t.Errorf("диапазон стоимости разобран неверно")
+	}
+	if q.CreatedTo == nil || q.CreatedTo.Hour() != 23 {
+		t.Errorf("верхняя граница даты должна включать весь день: %v", q.CreatedTo)
+	}
+	if len(q.Statuses) != 2 || q.Sort != SortPriceAsc {
+		t.Errorf("статусы или сортировка разобраны неверно: %v %v", q.Statuses, q.Sort)
+	}
+}
+
+func TestParseTaskSearchQueryDefaults(t *testing.T) {
+	q, err := ParseTaskSearchQuery(url.Values{})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if q.Sort != SortNewest {
+		t.Errorf("без текста сортировка по умолчанию должна быть newest, получено %q", q.Sort)
+	}
+	if len(q.Statuses) != 1 || q.Statuses[0] != StatusActive {
+		t.Errorf("по умолчанию ищутся только открытые задачи, получено %v", q.Statuses)
+	}
+}
+
+func TestParseTaskSearchQueryGeo(t *testing.T) {
+	q, err := ParseTaskSearchQuery(url.Values{"lat": {"55.75"}, "lon": {"37.62"}, "radius_km": {"15"}, "sort": {"distance"}})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if q.Near == nil || q.Near.Lat != 55.75 || q.Near.Lon != 37.62 {
+		t.Errorf("точка поиска разобрана неверно: %v", q.Near)
+	}
+	if q.RadiusKm == nil || *q.RadiusKm != 15 || q.Sort != SortDistance {
+		t.Errorf("радиус или сортировка разобраны неверно: %v %q", q.RadiusKm, q.Sort)
+	}
+}
+
+func TestParseTaskSearchQueryRejects(t *testing.T) {
+	cases := map[string]url.Values{
+		"неизвестный фильтр":       {"title": {"x"}},
+		"нечисловая категория":     {"category": {"abc"}},
+		"перевёрнутый диапазон":    {"cost_min": {"10"}, "cost_max": {"5"}},
+		"неизвестный статус":       {"status": {"108"}},
+		"неизвестная сортировка":   {"sort": {"rating"}},
+		"некорректная дата":        {"start_from": {"01.05.2024"}},
+		"отрицательная стоимость":  {"cost_min": {"-1"}},
+		"перевёрнутый период дат":  {"created_from": {"2024-05-02"}, "created_to": {"2024-05-01"}},
+		"курсор другой сортировки": {"sort": {"price_asc"}, "cursor": {pagination.TimeCursor("newest", time.Now(), 1).Encode()}},
+		"слишком большая страница": {"limit": {"1000"}},
+		"только широта":            {"lat": {"55.75"}},
+		"широта вне диапазона":     {"lat": {"95"}, "lon": {"37.6"}},
+		"радиус без точки":         {"radius_km": {"10"}},
+		"нулевой радиус":           {"lat": {"55.75"}, "lon": {"37.6"}, "radius_km": {"0"}},
+		"расстояние без точки":     {"sort": {"distance"}},
+	}
+
+	for name, values := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTaskSearchQuery(values); !errors.Is(err, ErrInvalidSearchQuery) {
+				t.Fatalf("ожидалась ErrInvalidSearchQuery, получено: %v", err)
+			}
+		})
+	}
+}
+
+// rateConverter пересчитывает суммы по фиксированному курсу.
+type rateConverter struct {
+	rates map[money.Currency]int
+}
+
+func (c rateConverter) ToBase(_ context.Context, m money.Money) (int, error) {
+	rate, ok := c.rates[m.Currency]
+	if !ok {
+		return 0, money.ErrRateNotFound
+	}
+	return m.Amount * rate, nil
+}
+
+func TestTaskSearchQueryInBaseCurrency(t *testing.T) {
+	converter := rateConverter{rates: map[money.Currency]int{"USD": 90}}
+	costMin, costMax := 1000, 5000
+	query := TaskSearchQuery{CostMin: &costMin, CostMax: &costMax, CostCurrency: "USD"}
+
+	base, err := query.InBaseCurrency(context.Background(), converter)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if base.CostCurrency != money.Base || *base.CostMin != 90000 || *base.CostMax != 450000 {
+		t.Errorf("неверный пересчёт фильтра: %s %d..%d", base.CostCurrency, *base.CostMin, *base.CostMax)
+	}
+	if costMin != 1000 || costMax != 5000 || query.CostCurrency != "USD" {
+		t.Errorf("исходный запрос не должен меняться: %s %d..%d", query.CostCurrency, costMin, costMax)
+	}
+
+	query.CostCurrency = "JPY"
+	if _, err := query.InBaseCurrency(context.Background(), converter); !errors.Is(err, money.ErrRateNotFound) {
+		t.Errorf("валюта без курса: ожидалась ErrRateNotFound, получено %v", err)
+	}
+}
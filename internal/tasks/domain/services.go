@@ -3,9 +3,19 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"fmt" // Для parsePage
+	"log/slog"
 	"time"
 	// Если нужны кастомные ошибки
+
+	"github.com/unclaim/chegonado.git/internal/bids"
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/shared/ports"
+	"github.com/unclaim/chegonado.git/internal/tasks"
 )
 
 // ServiceError - Кастомная ошибка для слоя сервиса
@@ -22,16 +32,81 @@ func (e *ServiceError) Error() string {
 	return e.Msg
 }
 
+// Unwrap позволяет errors.Is/errors.As добраться до исходной ошибки.
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
 // TasksServiceImp implements the TasksService interface.
 type TasksServiceImp struct {
-	tasksRepo TasksRepository
+	tasksRepo  TasksRepository
+	bus        EventBus
+	geocoder   ports.Geocoder
+	currencies ports.CurrencyConverter
 }
 
 // NewTasksService creates a new instance of TasksServiceImp.
-func NewTasksService(repo TasksRepository) *TasksServiceImp {
+func NewTasksService(repo TasksRepository, bus EventBus, geocoder ports.Geocoder, currencies ports.CurrencyConverter) *TasksServiceImp {
 	return &TasksServiceImp{
-		tasksRepo: repo,
+		tasksRepo:  repo,
+		bus:        bus,
+		geocoder:   geocoder,
+		currencies: currencies,
+	}
+}
+
+// priceCost проверяет цену задачи и пересчитывает её в базовую валюту для поиска и сортировки.
+// Без указанной валюты цена считается в базовой.
+func (s *TasksServiceImp) priceCost(ctx context.Context, cost *money.Money) (*int, error) {
+	if cost == nil {
+		return nil, nil
+	}
+	if cost.Currency == "" {
+		cost.Currency = money.Base
+	}
+	if err := cost.Validate(); err != nil {
+		return nil, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	}
+	base, err := s.currencies.ToBase(ctx, *cost)
+	if err != nil {
+		if errors.Is(err, money.ErrRateNotFound) {
+			return nil, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+		}
+		return nil, fmt.Errorf("ошибка пересчёта цены в базовую валюту: %w", err)
 	}
+	return &base, nil
+}
+
+// costQueryInBase пересчитывает фильтр по стоимости в базовую валюту; неизвестная валюта — ошибка клиента.
+func (s *TasksServiceImp) costQueryInBase(ctx context.Context, query TaskSearchQuery) (TaskSearchQuery, error) {
+	query, err := query.InBaseCurrency(ctx, s.currencies)
+	if err != nil {
+		if errors.Is(err, money.ErrRateNotFound) {
+			return TaskSearchQuery{}, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+		}
+		return TaskSearchQuery{}, err
+	}
+	return query, nil
+}
+
+// geocodeAddresses определяет координаты адресов задачи.
+// Ненайденный адрес сохраняется без координат: задача создаётся, но не попадёт в поиск по радиусу.
+func (s *TasksServiceImp) geocodeAddresses(ctx context.Context, addresses []string) []TaskLocation {
+	locations := make([]TaskLocation, 0, len(addresses))
+	for _, address := range addresses {
+		location := TaskLocation{Address: address}
+		point, err := s.geocoder.Geocode(ctx, address)
+		switch {
+		case err == nil:
+			location.Point = &point
+		case errors.Is(err, geo.ErrAddressNotFound):
+			slog.Warn("Адрес задачи не найден геокодером", "address", address)
+		default:
+			slog.Error("Ошибка геокодирования адреса задачи", "address", address, "error", err)
+		}
+		locations = append(locations, location)
+	}
+	return locations
 }
 
 // CreateTask создает новую задачу.
@@ -44,40 +119,115 @@ func (s *TasksServiceImp) CreateTask(ctx context.Context, task Task, userID int6
 		return 0, &ServiceError{Msg: "описание задачи не может быть пустым", Code: 400}
 	}
 
+	costBase, err := s.priceCost(ctx, task.Cost)
+	if err != nil {
+		return 0, err
+	}
+	task.CostBase = costBase
+
+	task.Locations = s.geocodeAddresses(ctx, task.Addresses)
+
 	id, err := s.tasksRepo.InsertTaskIntoDB(ctx, task, userID)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при вставке задачи в базу данных: %w", err)
 	}
+
+	s.bus.Publish(tasks.TaskCreatedEvent{
+		TaskID:     int64(id),
+		CustomerID: userID,
+		Title:      task.Title,
+	})
 	return id, nil
 }
 
-// GetTasks получает список задач с пагинацией.
-func (s *TasksServiceImp) GetTasks(page int) ([]Task, string, error) {
-	if page < 0 {
-		page = 0 // Убедимся, что страница не отрицательная
+// UpdateTask изменяет условия задачи, сохраняя предыдущую редакцию.
+// Менять условия можно только пока задача открыта; откликнувшиеся исполнители получают уведомление.
+func (s *TasksServiceImp) UpdateTask(ctx context.Context, taskID, userID int64, req UpdateTaskRequest) (*Task, error) {
+	if req.Title == "" {
+		return nil, &ServiceError{Msg: "название задачи не может быть пустым", Code: 400}
+	}
+	if req.Description == "" {
+		return nil, &ServiceError{Msg: "описание задачи не может быть пустым", Code: 400}
+	}
+	costBase, err := s.priceCost(ctx, req.Cost)
+	if err != nil {
+		return nil, err
+	}
+	req.CostBase = costBase
+	if req.StartDate != nil && req.EndDate != nil && req.EndDate.Before(*req.StartDate) {
+		return nil, &ServiceError{Msg: "дата окончания не может быть раньше даты начала", Code: 400}
 	}
 
-	tasks, err := s.tasksRepo.FetchTasksFromDB(page)
+	ownsTask, err := s.tasksRepo.CheckTaskOwnership(ctx, taskID, userID)
 	if err != nil {
-		return nil, "", fmt.Errorf("ошибка при получении задач из БД: %w", err)
+		return nil, fmt.Errorf("ошибка проверки владения задачей: %w", err)
+	}
+	if !ownsTask {
+		return nil, &ServiceError{Msg: "у вас нет прав для изменения этого задания", Code: 403}
 	}
 
-	message := ""
-	if len(tasks) < 5 { // Предполагаем лимит в 5, как в репозитории
-		message = "Конец списка"
+	status, err := s.tasksRepo.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении статуса задачи: %w", err)
 	}
-	return tasks, message, nil
+	if status != StatusActive {
+		return nil, &ServiceError{Msg: fmt.Sprintf("нельзя изменить задачу в статусе %s", status), Code: 409}
+	}
+
+	req.Locations = s.geocodeAddresses(ctx, req.Addresses)
+
+	revision, err := s.tasksRepo.UpdateTask(ctx, taskID, userID, req, currentTime())
+	if err != nil {
+		if errors.Is(err, ErrStatusConflict) {
+			return nil, &ServiceError{Msg: "задача уже не открыта, условия изменить нельзя", Code: 409, Err: err}
+		}
+		return nil, fmt.Errorf("ошибка при обновлении задачи: %w", err)
+	}
+
+	responderIDs, err := s.tasksRepo.GetResponderIDs(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении откликнувшихся исполнителей: %w", err)
+	}
+	s.bus.Publish(tasks.TaskUpdatedEvent{
+		TaskID:       taskID,
+		CustomerID:   userID,
+		Title:        req.Title,
+		Revision:     revision,
+		ResponderIDs: responderIDs,
+	})
+
+	return s.tasksRepo.GetTaskByID(ctx, taskID)
 }
 
-// SearchTasks ищет задачи.
-func (s *TasksServiceImp) SearchTasks(ctx context.Context, searchStr string, filterParams map[string]interface{}, pageSize, offset int) ([]Task, error) {
-	if searchStr == "" {
-		return nil, &ServiceError{Msg: "параметр 'search' обязателен", Code: 400}
+// GetTaskRevisions получает предыдущие редакции условий задачи.
+// Редакции видят заказчик и исполнители, откликнувшиеся на задачу.
+func (s *TasksServiceImp) GetTaskRevisions(ctx context.Context, taskID, userID int64) ([]TaskRevision, error) {
+	ownsTask, err := s.tasksRepo.CheckTaskOwnership(ctx, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки владения задачей: %w", err)
+	}
+	if !ownsTask {
+		responded, err := s.tasksRepo.ResponseExists(ctx, taskID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка проверки отклика на задачу: %w", err)
+		}
+		if !responded {
+			return nil, &ServiceError{Msg: "недостаточно прав для просмотра редакций задачи", Code: 403}
+		}
 	}
 
-	tasks, err := s.tasksRepo.SearchTasks(ctx, searchStr, filterParams, pageSize, offset)
+	revisions, err := s.tasksRepo.GetTaskRevisions(ctx, taskID)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при поиске задач: %w", err)
+		return nil, fmt.Errorf("не удалось получить редакции задачи: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetTasks получает ленту открытых задач с курсорной пагинацией.
+func (s *TasksServiceImp) GetTasks(ctx context.Context, page pagination.Request) (pagination.Page[Task], error) {
+	tasks, err := s.tasksRepo.FetchTasksFromDB(ctx, page)
+	if err != nil {
+		return pagination.Page[Task]{}, paginationError(err, "ошибка при получении задач из БД")
 	}
 	return tasks, nil
 }
@@ -103,15 +253,79 @@ func (s *TasksServiceImp) CreateContract(ctx context.Context, req CreateContract
 		return 0, &ServiceError{Msg: "контракт для этой задачи и исполнителя уже существует", Code: 409}
 	}
 
+	// Контракт можно заключить только по открытой задаче: иначе задача с активным контрактом
+	// продолжала бы висеть в поиске как свободная.
+	status, err := s.tasksRepo.GetTaskStatus(ctx, req.TaskID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении статуса задачи: %w", err)
+	}
+	if status != StatusActive {
+		return 0, &ServiceError{Msg: fmt.Sprintf("контракт нельзя заключить по задаче в статусе %s", status), Code: 409}
+	}
+
+	// Контракт заключается на условиях, согласованных в переговорах по отклику, а не на исходной цене задачи.
+	negotiation, err := s.tasksRepo.GetNegotiationByTaskAndUser(ctx, req.TaskID, req.ExecutorID)
+	if err != nil {
+		if errors.Is(err, ErrResponseNotFound) {
+			return 0, &ServiceError{Msg: "исполнитель не откликался на эту задачу", Code: 409, Err: err}
+		}
+		return 0, fmt.Errorf("ошибка при получении условий отклика: %w", err)
+	}
+	terms, implicitAccept, err := negotiation.ContractTerms()
+	if err != nil {
+		return 0, &ServiceError{Msg: err.Error(), Code: 409, Err: err}
+	}
+
+	plan := req.Milestones
+	if len(plan) == 0 {
+		plan = DefaultMilestones(terms)
+	}
+	if err := ValidateMilestones(plan, terms.Price); err != nil {
+		return 0, milestoneError(err)
+	}
+	if implicitAccept {
+		accept, status, err := negotiation.NextOffer(RoleCustomer, creatorID, OfferRequest{Action: OfferAccept})
+		if err != nil {
+			return 0, &ServiceError{Msg: err.Error(), Code: 409, Err: err}
+		}
+		accept.CreatedAt = currentTime()
+		if _, err := s.tasksRepo.AppendOffer(ctx, accept, status, negotiation.LastOffer.ID); err != nil {
+			return 0, offerError(err)
+		}
+	}
+
 	statusID, err := s.tasksRepo.GetActiveStatusID(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при получении статуса контракта: %w", err)
 	}
 
-	contractID, err := s.tasksRepo.CreateContractInDB(ctx, req.TaskID, req.ExecutorID, creatorID, currentTime(), statusID)
+	// Контракт и перевод задачи в работу сохраняются вместе: задача не может остаться открытой
+	// с действующим контрактом или уйти в работу без него.
+	contractID, err := s.tasksRepo.StartContract(ctx, NewContract{
+		TaskID:     req.TaskID,
+		ExecutorID: req.ExecutorID,
+		CustomerID: creatorID,
+		CreatedAt:  currentTime(),
+		StatusID:   statusID,
+		Terms:      terms,
+		Milestones: plan,
+	})
 	if err != nil {
+		if errors.Is(err, ErrStatusConflict) {
+			return 0, &ServiceError{Msg: "задача уже не открыта, контракт не заключён", Code: 409, Err: err}
+		}
 		return 0, fmt.Errorf("ошибка при создании контракта в базе данных: %w", err)
 	}
+
+	s.bus.Publish(tasks.TaskStatusChangedEvent{TaskID: req.TaskID, FromStatus: int(StatusActive), ToStatus: int(StatusInProgress)})
+	s.bus.Publish(tasks.ContractCreatedEvent{
+		ContractID: contractID,
+		TaskID:     req.TaskID,
+		CustomerID: creatorID,
+		ExecutorID: req.ExecutorID,
+		Price:      terms.Price,
+		Currency:   terms.Currency,
+	})
 	return contractID, nil
 }
 
@@ -124,14 +338,48 @@ func (s *TasksServiceImp) GetTasksResponses(ctx context.Context, userID int64) (
 	return tasks, nil
 }
 
-// CreateReport создает отчет.
-func (s *TasksServiceImp) CreateReport(ctx context.Context, report Report) error {
+// CreateReport создает отчет исполнителя по этапу контракта.
+// Отклонённый заказчиком отчёт можно сдать повторно, подтверждённый или ожидающий решения — нет.
+func (s *TasksServiceImp) CreateReport(ctx context.Context, userID int64, report Report) error {
 	if report.ExecutorComments == nil || *report.ExecutorComments == "" {
 		return &ServiceError{Msg: "комментарии исполнителя обязательны", Code: 400}
 	}
 
-	err := s.tasksRepo.CreateReport(ctx, report.ContractID, report.TaskID, *report.ExecutorComments, report.ExecutionStatus)
+	contract, err := s.tasksRepo.GetContractByID(ctx, report.ContractID)
 	if err != nil {
+		return fmt.Errorf("ошибка получения контракта: %w", err)
+	}
+	if contract == nil {
+		return &ServiceError{Msg: "контракт не найден", Code: 404}
+	}
+	if userID != contract.ExecutorID {
+		return &ServiceError{Msg: "отчёт сдаёт только исполнитель по контракту", Code: 403}
+	}
+	if !contract.IsActive {
+		return &ServiceError{Msg: "контракт закрыт", Code: 409}
+	}
+
+	milestones, err := s.tasksRepo.ListMilestones(ctx, contract.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении этапов контракта: %w", err)
+	}
+	milestone, err := FindMilestone(milestones, report.MilestoneID)
+	if err != nil {
+		return milestoneError(err)
+	}
+
+	switch milestone.Status {
+	case MilestonePending:
+		err = s.tasksRepo.CreateReport(ctx, contract.ID, contract.TaskID, milestone.ID, *report.ExecutorComments, report.ExecutionStatus)
+	case MilestoneRejected:
+		err = s.tasksRepo.ResubmitReport(ctx, milestone.Report.ID, *report.ExecutorComments, report.ExecutionStatus)
+	default:
+		return milestoneError(ErrReportAlreadySubmitted)
+	}
+	if err != nil {
+		if errors.Is(err, ErrReportAlreadySubmitted) {
+			return milestoneError(err)
+		}
 		return fmt.Errorf("ошибка при создании отчета: %w", err)
 	}
 	return nil
@@ -147,22 +395,6 @@ func (s *TasksServiceImp) CheckContract(ctx context.Context, taskID, customerID,
 	return contract, nil
 }
 
-// CreateReview создает отзыв.
-func (s *TasksServiceImp) CreateReview(ctx context.Context, review Review) (int, error) {
-	if review.Rating < 1 || review.Rating > 5 {
-		return 0, &ServiceError{Msg: "рейтинг должен быть от 1 до 5", Code: 400}
-	}
-	if review.Comment == "" {
-		return 0, &ServiceError{Msg: "комментарий не может быть пустым", Code: 400}
-	}
-
-	reviewID, err := s.tasksRepo.InsertReviewInDB(ctx, review)
-	if err != nil {
-		return 0, fmt.Errorf("ошибка при попытке добавить отзыв в базу данных: %w", err)
-	}
-	return reviewID, nil
-}
-
 // GetContractReportExists проверяет существование отчета по контракту.
 func (s *TasksServiceImp) GetContractReportExists(ctx context.Context, contractID int) (bool, *Report, error) {
 	// Репозиторий должен уметь получить отчет, если он существует.
@@ -178,43 +410,125 @@ func (s *TasksServiceImp) GetContractReportExists(ctx context.Context, contractI
 	return report != nil, report, nil
 }
 
-// GetReviewsByUser получает отзывы пользователя.
-func (s *TasksServiceImp) GetReviewsByUser(ctx context.Context, userID string) ([]Review, error) {
-	reviews, err := s.tasksRepo.FetchReviewsByUserFromDB(userID)
+// UpdateReport сохраняет отзыв заказчика на отчёт по этапу и его решение: подтвердить или отклонить.
+// Решение принимает только заказчик и только по отчёту, который ждёт решения: подтверждение
+// освобождает оплату этапа. Когда подтверждён последний этап, задача завершается, а контракт закрывается.
+func (s *TasksServiceImp) UpdateReport(ctx context.Context, contractID, userID int64, milestoneID *int64, feedback *string, confirmation *bool) error {
+	contract, err := s.tasksRepo.GetContractByID(ctx, contractID)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить отзывы: %w", err)
+		return fmt.Errorf("ошибка получения контракта: %w", err)
+	}
+	if contract == nil {
+		return &ServiceError{Msg: "контракт не найден", Code: 404}
+	}
+	if userID != contract.CustomerID {
+		return &ServiceError{Msg: "решение по отчёту принимает только заказчик", Code: 403}
 	}
-	return reviews, nil
-}
 
-// UpdateReport обновляет отчет.
-func (s *TasksServiceImp) UpdateReport(ctx context.Context, contractID int64, feedback *string, confirmation *bool) error {
-	// Получаем отчет для обновления
-	report, err := s.tasksRepo.GetReportByContractID(ctx, contractID)
+	milestones, err := s.tasksRepo.ListMilestones(ctx, contractID)
 	if err != nil {
-		return &ServiceError{Msg: fmt.Sprintf("не удалось получить отчет для contract ID: %v", err), Code: 404, Err: err}
+		return fmt.Errorf("ошибка при получении этапов контракта: %w", err)
+	}
+	milestone, err := FindMilestone(milestones, milestoneID)
+	if err != nil {
+		return milestoneError(err)
+	}
+	report := milestone.Report
+	if report == nil {
+		return &ServiceError{Msg: fmt.Sprintf("отчёт по этапу %d ещё не сдан", milestone.ID), Code: 404}
+	}
+	if confirmation != nil {
+		if !contract.IsActive {
+			return &ServiceError{Msg: "контракт закрыт", Code: 409}
+		}
+		if milestone.Status != MilestoneSubmitted {
+			return milestoneError(ErrReportNotSubmitted)
+		}
 	}
 
 	// Обновление полей отчета
 	if feedback != nil {
 		report.CustomerFeedback = feedback
 	}
-	if confirmation != nil {
-		report.CustomerConfirmation = confirmation
-	}
 
 	// Валидация обновленных данных
 	if report.CustomerFeedback != nil && *report.CustomerFeedback == "" {
 		return &ServiceError{Msg: "отзыв заказчика не может быть пустым", Code: 400}
 	}
+	customerFeedback := ""
+	if report.CustomerFeedback != nil {
+		customerFeedback = *report.CustomerFeedback
+	}
+
+	// Подтверждение последнего этапа записывается вместе с завершением задачи и закрытием контракта:
+	// если завершить не удалось, этап по-прежнему ждёт решения и подтверждение можно повторить.
+	if confirmation != nil && *confirmation && ConfirmationCompletesContract(milestones, milestone.ID) {
+		return s.confirmLastMilestone(ctx, contract, milestone, customerFeedback)
+	}
 
-	err = s.tasksRepo.UpdateReport(ctx, report.ID, *report.CustomerFeedback, report.CustomerConfirmation)
+	err = s.tasksRepo.UpdateReport(ctx, report.ID, customerFeedback, confirmation)
 	if err != nil {
+		if errors.Is(err, ErrReportNotSubmitted) {
+			return milestoneError(err)
+		}
 		return fmt.Errorf("ошибка при обновлении отчета: %w", err)
 	}
+
+	// События — только о решении, принятом этим запросом: правка отзыва не подтверждает этап повторно.
+	if confirmation == nil {
+		return nil
+	}
+	if !*confirmation {
+		s.bus.Publish(tasks.ReportRejectedEvent{
+			ContractID:     contractID,
+			TaskID:         report.TaskID,
+			MilestoneID:    milestone.ID,
+			MilestoneTitle: milestone.Title,
+			ExecutorID:     contract.ExecutorID,
+			Feedback:       customerFeedback,
+		})
+		return nil
+	}
+
+	s.publishMilestoneConfirmed(contract, milestone)
 	return nil
 }
 
+// confirmLastMilestone подтверждает последний этап, завершает задачу и закрывает контракт в одной
+// транзакции. После этого стороны могут оставить отзывы.
+func (s *TasksServiceImp) confirmLastMilestone(ctx context.Context, contract *Contract, milestone Milestone, customerFeedback string) error {
+	from, guards, err := s.transitionState(ctx, contract.TaskID)
+	if err != nil {
+		return err
+	}
+	// Подтверждение ещё не записано: переход проверяется с учётом решения, которое запишется вместе с ним.
+	guards.HasReport, guards.ReportConfirmed = true, true
+	change, err := newTransition(contract.TaskID, contract.CustomerID, from, StatusCompleted, fmt.Sprintf("заказчик подтвердил все этапы контракта %d", contract.ID), guards)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tasksRepo.ConfirmLastMilestone(ctx, milestone.Report.ID, customerFeedback, contract.ID, change); err != nil {
+		if errors.Is(err, ErrReportNotSubmitted) {
+			return milestoneError(err)
+		}
+		return statusChangeError(err)
+	}
+	s.publishMilestoneConfirmed(contract, milestone)
+	s.publishTransition(change)
+	return nil
+}
+
+// publishMilestoneConfirmed сообщает о подтверждённом этапе — по нему освобождается оплата.
+func (s *TasksServiceImp) publishMilestoneConfirmed(contract *Contract, milestone Milestone) {
+	s.bus.Publish(tasks.MilestoneConfirmedEvent{
+		ContractID:  contract.ID,
+		TaskID:      contract.TaskID,
+		MilestoneID: milestone.ID,
+		Amount:      milestone.Amount,
+	})
+}
+
 // CancelTask отменяет задачу.
 func (s *TasksServiceImp) CancelTask(ctx context.Context, taskID, userID int64) error {
 	ownsTask, err := s.tasksRepo.CheckTaskOwnership(ctx, taskID, userID)
@@ -225,10 +539,30 @@ func (s *TasksServiceImp) CancelTask(ctx context.Context, taskID, userID int64)
 		return &ServiceError{Msg: "у вас нет прав для отмены этого задания", Code: 403}
 	}
 
-	err = s.tasksRepo.CancelTask(ctx, taskID)
+	contract, err := s.tasksRepo.GetActiveContractByTask(ctx, taskID)
 	if err != nil {
-		return fmt.Errorf("ошибка отмены задания: %w", err)
+		return fmt.Errorf("ошибка при получении контракта по задаче: %w", err)
+	}
+	if contract == nil {
+		return s.transitionTask(ctx, taskID, userID, StatusCancelled, "отменена заказчиком")
+	}
+
+	// Действующий контракт расторгается в одной транзакции с отменой задачи,
+	// а нераспределённые средства возвращаются заказчику.
+	change, err := s.prepareTransition(ctx, taskID, userID, StatusCancelled, "отменена заказчиком", false)
+	if err != nil {
+		return err
+	}
+	if err := s.tasksRepo.FinishContract(ctx, contract.ID, change); err != nil {
+		return statusChangeError(err)
 	}
+	s.publishTransition(change)
+	s.bus.Publish(tasks.ContractCancelledEvent{
+		ContractID: contract.ID,
+		TaskID:     taskID,
+		CustomerID: contract.CustomerID,
+		ExecutorID: contract.ExecutorID,
+	})
 	return nil
 }
 
@@ -260,6 +594,25 @@ func (s *TasksServiceImp) CreateResponse(ctx context.Context, newResponse Propos
 	if exists {
 		return ProposedResponse{}, &ServiceError{Msg: "пользователь уже ответил на эту задачу", Code: 409}
 	}
+	// Предложенная цена открывает переговоры, поэтому она обязательна.
+	if err := (OfferTerms{Price: newResponse.ProposedPrice.Amount}).Validate(); err != nil {
+		return ProposedResponse{}, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	}
+	// Отклик предлагает цену в валюте задачи: иначе стороны торговались бы в разных валютах.
+	task, err := s.tasksRepo.GetTaskByID(ctx, newResponse.TaskID)
+	if err != nil {
+		return ProposedResponse{}, fmt.Errorf("ошибка при получении задачи: %w", err)
+	}
+	currency := money.Base
+	if task.Cost != nil {
+		currency = task.Cost.Currency
+	}
+	if newResponse.ProposedPrice.Currency == "" {
+		newResponse.ProposedPrice.Currency = currency
+	}
+	if newResponse.ProposedPrice.Currency != currency {
+		return ProposedResponse{}, &ServiceError{Msg: fmt.Sprintf("цена отклика должна быть указана в валюте задачи %s", currency), Code: 400}
+	}
 
 	createdResponse, err := s.tasksRepo.InsertResponseIntoDB(newResponse)
 	if err != nil {
@@ -281,14 +634,42 @@ func (s *TasksServiceImp) DeleteResponse(ctx context.Context, responseID, userID
 }
 
 // DeleteTaskByID удаляет задачу по ID.
+// Удалить можно только открытую или отменённую задачу без активного контракта,
+// чтобы не потерять историю выполненных и спорных работ.
 func (s *TasksServiceImp) DeleteTaskByID(ctx context.Context, userID, taskID int64) error {
-	err := s.tasksRepo.DeleteTask(ctx, userID, taskID)
+	status, err := s.tasksRepo.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		return &ServiceError{Msg: "задача не найдена", Code: 404, Err: err}
+	}
+	if status != StatusActive && status != StatusCancelled {
+		return &ServiceError{Msg: fmt.Sprintf("нельзя удалить задачу в статусе %s", status), Code: 409}
+	}
+	guards, err := s.tasksRepo.GetTransitionGuards(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке контрактов задачи: %w", err)
+	}
+	if guards.HasActiveContract {
+		return &ServiceError{Msg: "нельзя удалить задачу с активным контрактом", Code: 409}
+	}
+	hasDocuments, err := s.tasksRepo.HasContractDocuments(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке документов задачи: %w", err)
+	}
+	if hasDocuments {
+		return deleteTaskError(ErrTaskHasDocuments)
+	}
+
+	err = s.tasksRepo.DeleteTask(ctx, userID, taskID)
 	if err != nil {
+		if errors.Is(err, ErrTaskHasDocuments) {
+			return deleteTaskError(err)
+		}
 		if err.Error() == fmt.Sprintf("задача не найдена с ид: %d для пользователя с ид: %d", taskID, userID) {
 			return &ServiceError{Msg: fmt.Sprintf("задача не найдена: %v", err), Code: 404, Err: err}
 		}
 		return fmt.Errorf("не удалось удалить задачу: %w", err)
 	}
+	s.bus.Publish(tasks.TaskDeletedEvent{TaskID: taskID, CustomerID: userID})
 	return nil
 }
 
@@ -302,10 +683,10 @@ func (s *TasksServiceImp) TotalResponses(taskID int64) (int, error) {
 }
 
 // GetResponsesHandler получает отклики на задачу.
-func (s *TasksServiceImp) GetResponsesHandler(ctx context.Context, taskID, userID int64) ([]ResponseWithUser, error) {
-	responses, err := s.tasksRepo.GetResponsesByTaskID(taskID, userID)
+func (s *TasksServiceImp) GetResponsesHandler(ctx context.Context, taskID, userID int64, page pagination.Request) (pagination.Page[ResponseWithUser], error) {
+	responses, err := s.tasksRepo.GetResponsesByTaskID(taskID, userID, page)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить ответы: %w", err)
+		return pagination.Page[ResponseWithUser]{}, paginationError(err, "не удалось получить ответы")
 	}
 	return responses, nil
 }
@@ -413,15 +794,6 @@ func (s *TasksServiceImp) GetTasksCount(ctx context.Context, userID int64) (int,
 	return createdTasksCount, taskResponses, nil
 }
 
-// GetUserReviewsStats получает статистику отзывов пользователя.
-func (s *TasksServiceImp) GetUserReviewsStats(ctx context.Context, userID string) (ReviewStats, error) {
-	stats, err := s.tasksRepo.FetchUserReviewsStatsFromDB(ctx, userID)
-	if err != nil {
-		return ReviewStats{}, fmt.Errorf("не удалось получить статистику отзывов: %w", err)
-	}
-	return stats, nil
-}
-
 // RecordTaskView записывает просмотр задачи.
 func (s *TasksServiceImp) RecordTaskView(ctx context.Context, taskID, userID int64) error {
 	err := s.tasksRepo.RecordTaskView(ctx, taskID, userID)
@@ -449,6 +821,547 @@ func (s *TasksServiceImp) CheckResponseView(ctx context.Context, responseID, use
 	return viewed, nil
 }
 
+// GetTaskStatusHistory возвращает историю смены статусов задачи.
+// Историю видят только заказчик и исполнители, с которыми заключён контракт.
+func (s *TasksServiceImp) GetTaskStatusHistory(ctx context.Context, taskID, userID int64) ([]TaskStatusChange, error) {
+	allowed, err := s.tasksRepo.IsTaskParticipant(ctx, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки доступа к задаче: %w", err)
+	}
+	if !allowed {
+		return nil, &ServiceError{Msg: "недостаточно прав для просмотра истории задачи", Code: 403}
+	}
+
+	history, err := s.tasksRepo.GetTaskStatusHistory(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить историю статусов: %w", err)
+	}
+	return history, nil
+}
+
+// transitionAttempts — сколько раз обработчик событий повторяет переход статуса, который
+// параллельно изменили между проверкой и записью.
+const transitionAttempts = 3
+
+// transitionTask переводит задачу в новый статус, проверяя жизненный цикл и условия перехода,
+// и записывает изменение в историю.
+func (s *TasksServiceImp) transitionTask(ctx context.Context, taskID, actorID int64, to TaskStatusCode, reason string) error {
+	return s.changeTaskStatus(ctx, taskID, actorID, to, reason, false)
+}
+
+// changeTaskStatus выполняет переход статуса. byArbitration снимает условия по отчётам:
+// решение арбитра заменяет подтверждение заказчика.
+func (s *TasksServiceImp) changeTaskStatus(ctx context.Context, taskID, actorID int64, to TaskStatusCode, reason string, byArbitration bool) error {
+	change, err := s.prepareTransition(ctx, taskID, actorID, to, reason, byArbitration)
+	if err != nil {
+		return err
+	}
+	if err := s.tasksRepo.UpdateTaskStatus(ctx, change); err != nil {
+		return statusChangeError(err)
+	}
+	s.publishTransition(change)
+	return nil
+}
+
+// prepareTransition проверяет, что задачу можно перевести в статус to, и возвращает запись для истории.
+// Сам переход сохраняет вызывающий — отдельно или в одной транзакции с контрактом.
+func (s *TasksServiceImp) prepareTransition(ctx context.Context, taskID, actorID int64, to TaskStatusCode, reason string, byArbitration bool) (TaskStatusChange, error) {
+	from, guards, err := s.transitionState(ctx, taskID)
+	if err != nil {
+		return TaskStatusChange{}, err
+	}
+	guards.ArbitrationDecision = byArbitration
+	return newTransition(taskID, actorID, from, to, reason, guards)
+}
+
+// transitionState загружает текущий статус задачи и условия перехода.
+func (s *TasksServiceImp) transitionState(ctx context.Context, taskID int64) (TaskStatusCode, TransitionGuards, error) {
+	from, err := s.tasksRepo.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		return 0, TransitionGuards{}, &ServiceError{Msg: "задача не найдена", Code: 404, Err: err}
+	}
+
+	guards, err := s.tasksRepo.GetTransitionGuards(ctx, taskID)
+	if err != nil {
+		return 0, TransitionGuards{}, fmt.Errorf("ошибка при проверке условий перехода: %w", err)
+	}
+	return from, guards, nil
+}
+
+// newTransition проверяет переход и собирает запись о нём.
+func newTransition(taskID, actorID int64, from, to TaskStatusCode, reason string, guards TransitionGuards) (TaskStatusChange, error) {
+	if err := ValidateTransition(from, to, guards); err != nil {
+		return TaskStatusChange{}, &ServiceError{Msg: "смена статуса задачи невозможна", Code: 409, Err: err}
+	}
+
+	return TaskStatusChange{
+		TaskID:     taskID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  actorID,
+		Reason:     reason,
+		CreatedAt:  currentTime(),
+	}, nil
+}
+
+// publishTransition сообщает о смене статуса задачи; вызывается только после её сохранения.
+func (s *TasksServiceImp) publishTransition(change TaskStatusChange) {
+	if change.ToStatus == StatusCancelled {
+		s.bus.Publish(tasks.TaskCancelledEvent{TaskID: change.TaskID, CancelledBy: change.ChangedBy})
+	} else {
+		s.bus.Publish(tasks.TaskStatusChangedEvent{TaskID: change.TaskID, FromStatus: int(change.FromStatus), ToStatus: int(change.ToStatus)})
+	}
+}
+
+// deleteTaskError сообщает, что задачу нельзя удалить из-за выпущенных по ней документов.
+func deleteTaskError(err error) error {
+	return &ServiceError{Msg: "нельзя удалить задачу: " + err.Error(), Code: 409, Err: err}
+}
+
+// statusChangeError превращает отказ репозитория сменить статус в ошибку сервиса.
+func statusChangeError(err error) error {
+	if errors.Is(err, ErrStatusConflict) {
+		return &ServiceError{Msg: "смена статуса задачи невозможна", Code: 409, Err: err}
+	}
+	return fmt.Errorf("ошибка при смене статуса задачи: %w", err)
+}
+
+// paginationError превращает отказ репозитория принять курсор в ошибку клиента.
+func paginationError(err error, msg string) error {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &ServiceError{Msg: pagination.ErrInvalidCursor.Error(), Code: 400, Err: err}
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
 func currentTime() time.Time {
 	return time.Now()
 }
+
+// CreateSavedSearch сохраняет набор фильтров поиска задач для оповещений о новых задачах.
+func (s *TasksServiceImp) CreateSavedSearch(ctx context.Context, userID int64, req SavedSearchRequest) (*SavedSearch, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	}
+
+	count, err := s.tasksRepo.CountSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте сохранённых поисков: %w", err)
+	}
+	if count >= MaxSavedSearches {
+		return nil, &ServiceError{Msg: fmt.Sprintf("можно сохранить не больше %d поисков", MaxSavedSearches), Code: 409}
+	}
+
+	search := SavedSearch{
+		UserID:    userID,
+		Name:      req.Name,
+		Filters:   req.Filters,
+		Frequency: req.Frequency,
+		CreatedAt: currentTime(),
+	}
+	if search.ID, err = s.tasksRepo.InsertSavedSearch(ctx, search); err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении поиска: %w", err)
+	}
+	return &search, nil
+}
+
+// ListSavedSearches получает сохранённые поиски пользователя.
+func (s *TasksServiceImp) ListSavedSearches(ctx context.Context, userID int64) ([]SavedSearch, error) {
+	searches, err := s.tasksRepo.ListSavedSearchesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сохранённых поисков: %w", err)
+	}
+	return searches, nil
+}
+
+// UpdateSavedSearch изменяет название, фильтры или частоту оповещений сохранённого поиска.
+func (s *TasksServiceImp) UpdateSavedSearch(ctx context.Context, searchID, userID int64, req SavedSearchRequest) (*SavedSearch, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	}
+
+	search, err := s.tasksRepo.GetSavedSearch(ctx, searchID, userID)
+	if err != nil {
+		if errors.Is(err, ErrSavedSearchNotFound) {
+			return nil, &ServiceError{Msg: err.Error(), Code: 404, Err: err}
+		}
+		return nil, fmt.Errorf("ошибка при получении сохранённого поиска: %w", err)
+	}
+
+	search.Name = req.Name
+	search.Filters = req.Filters
+	search.Frequency = req.Frequency
+	ok, err := s.tasksRepo.UpdateSavedSearch(ctx, *search)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при изменении сохранённого поиска: %w", err)
+	}
+	if !ok {
+		return nil, &ServiceError{Msg: ErrSavedSearchNotFound.Error(), Code: 404, Err: ErrSavedSearchNotFound}
+	}
+	return search, nil
+}
+
+// DeleteSavedSearch удаляет сохранённый поиск пользователя.
+func (s *TasksServiceImp) DeleteSavedSearch(ctx context.Context, searchID, userID int64) error {
+	ok, err := s.tasksRepo.DeleteSavedSearch(ctx, searchID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении сохранённого поиска: %w", err)
+	}
+	if !ok {
+		return &ServiceError{Msg: ErrSavedSearchNotFound.Error(), Code: 404, Err: ErrSavedSearchNotFound}
+	}
+	return nil
+}
+
+// HandleTaskCreated — обработчик события создания задачи.
+// Сопоставляет задачу с сохранёнными поисками других пользователей: по мгновенным поискам
+// сразу публикует оповещение, для сводок копит совпадения до SendSavedSearchDigests.
+func (s *TasksServiceImp) HandleTaskCreated(event any) {
+	e, ok := event.(tasks.TaskCreatedEvent)
+	if !ok {
+		slog.Error("[SavedSearch] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+
+	task, err := s.tasksRepo.GetTaskByID(ctx, e.TaskID)
+	if err != nil {
+		slog.Error("[SavedSearch] Не удалось получить задачу", "task_id", e.TaskID, "error", err)
+		return
+	}
+
+	searches, err := s.tasksRepo.ListSavedSearchesExceptUser(ctx, e.CustomerID)
+	if err != nil {
+		slog.Error("[SavedSearch] Не удалось получить сохранённые поиски", "task_id", e.TaskID, "error", err)
+		return
+	}
+
+	now := currentTime()
+	for _, search := range searches {
+		matched, err := s.savedSearchMatches(ctx, search, *task)
+		if err != nil {
+			slog.Error("[SavedSearch] Ошибка сопоставления задачи с поиском", "task_id", e.TaskID, "search_id", search.ID, "error", err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		instant := search.Frequency == AlertInstant
+		if err := s.tasksRepo.InsertSavedSearchMatch(ctx, search.ID, task.ID, now, instant); err != nil {
+			slog.Error("[SavedSearch] Не удалось сохранить совпадение", "task_id", e.TaskID, "search_id", search.ID, "error", err)
+			continue
+		}
+		if instant {
+			s.bus.Publish(tasks.SavedSearchAlertEvent{
+				UserID:     search.UserID,
+				SearchID:   search.ID,
+				SearchName: search.Name,
+				Tasks:      []tasks.MatchedTask{{ID: task.ID, Title: task.Title}},
+			})
+		}
+	}
+}
+
+// savedSearchMatches проверяет задачу по фильтрам поиска; текстовый запрос проверяется в БД.
+func (s *TasksServiceImp) savedSearchMatches(ctx context.Context, search SavedSearch, task Task) (bool, error) {
+	query, err := search.Query()
+	if err != nil {
+		return false, err
+	}
+	query, err = s.costQueryInBase(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	if !query.MatchesTask(task) {
+		return false, nil
+	}
+	if query.Text == "" {
+		return true, nil
+	}
+	return s.tasksRepo.TaskMatchesText(ctx, task.ID, query.Text)
+}
+
+// SendSavedSearchDigests отправляет часовые и суточные сводки, срок которых наступил.
+func (s *TasksServiceImp) SendSavedSearchDigests(ctx context.Context, now time.Time) error {
+	digests, err := s.tasksRepo.ListPendingDigests(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении накопленных совпадений: %w", err)
+	}
+
+	for _, digest := range digests {
+		if !digest.Search.DigestDue(now) {
+			continue
+		}
+		taskIDs := make([]int64, len(digest.Tasks))
+		for i, t := range digest.Tasks {
+			taskIDs[i] = t.ID
+		}
+		// Сначала отмечаем отправку: лучше потерять сводку при сбое, чем слать её повторно каждый тик.
+		if err := s.tasksRepo.MarkDigestSent(ctx, digest.Search.ID, taskIDs, now); err != nil {
+			return fmt.Errorf("ошибка при отметке сводки поиска %d: %w", digest.Search.ID, err)
+		}
+		s.bus.Publish(tasks.SavedSearchAlertEvent{
+			UserID:     digest.Search.UserID,
+			SearchID:   digest.Search.ID,
+			SearchName: digest.Search.Name,
+			Digest:     true,
+			Tasks:      digest.Tasks,
+		})
+	}
+	return nil
+}
+
+// RunSavedSearchDigests периодически отправляет сводки по сохранённым поискам, пока не отменён ctx.
+func (s *TasksServiceImp) RunSavedSearchDigests(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.SendSavedSearchDigests(ctx, now); err != nil {
+				slog.Error("[SavedSearch] Ошибка отправки сводок", "error", err)
+			}
+		}
+	}
+}
+
+// GetResponseOffers возвращает историю переговоров по отклику заказчику задачи или автору отклика.
+func (s *TasksServiceImp) GetResponseOffers(ctx context.Context, responseID, userID int64) ([]ResponseOffer, error) {
+	negotiation, err := s.tasksRepo.GetNegotiation(ctx, responseID)
+	if err != nil {
+		return nil, offerError(err)
+	}
+	if _, ok := negotiation.RoleOf(userID); !ok {
+		return nil, &ServiceError{Msg: "историю переговоров видят только заказчик и исполнитель", Code: 403}
+	}
+
+	offers, err := s.tasksRepo.ListResponseOffers(ctx, responseID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить историю переговоров: %w", err)
+	}
+	return offers, nil
+}
+
+// MakeOffer записывает ход стороны в переговорах по отклику: встречное предложение, согласие или отзыв отклика.
+// После заключения контракта условия не меняются.
+func (s *TasksServiceImp) MakeOffer(ctx context.Context, responseID, userID int64, req OfferRequest) (*ResponseOffer, error) {
+	negotiation, err := s.tasksRepo.GetNegotiation(ctx, responseID)
+	if err != nil {
+		return nil, offerError(err)
+	}
+	role, ok := negotiation.RoleOf(userID)
+	if !ok {
+		return nil, &ServiceError{Msg: "участвовать в переговорах могут только заказчик и исполнитель", Code: 403}
+	}
+
+	hasContract, err := s.tasksRepo.GetContractExists(ctx, negotiation.Response.TaskID, negotiation.Response.UserID, negotiation.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при проверке существования контракта: %w", err)
+	}
+	if hasContract {
+		return nil, &ServiceError{Msg: "по отклику уже заключён контракт", Code: 409, Err: ErrNegotiationClosed}
+	}
+
+	offer, status, err := negotiation.NextOffer(role, userID, req)
+	if err != nil {
+		return nil, offerError(err)
+	}
+	offer.CreatedAt = currentTime()
+
+	offer, err = s.tasksRepo.AppendOffer(ctx, offer, status, negotiation.LastOffer.ID)
+	if err != nil {
+		return nil, offerError(err)
+	}
+
+	recipientID := negotiation.CustomerID
+	if role == RoleCustomer {
+		recipientID = negotiation.Response.UserID
+	}
+	s.bus.Publish(tasks.ResponseOfferEvent{
+		TaskID:      negotiation.Response.TaskID,
+		ResponseID:  responseID,
+		RecipientID: recipientID,
+		Action:      string(offer.Action),
+		Price:       offer.Price,
+		Currency:    offer.Currency,
+	})
+	return &offer, nil
+}
+
+// offerError превращает ошибки переговоров в ServiceError с подходящим кодом.
+func offerError(err error) error {
+	switch {
+	case errors.Is(err, ErrResponseNotFound):
+		return &ServiceError{Msg: err.Error(), Code: 404, Err: err}
+	case errors.Is(err, ErrInvalidOffer):
+		return &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	case errors.Is(err, ErrNegotiationClosed), errors.Is(err, ErrNotYourTurn), errors.Is(err, ErrOfferConflict):
+		return &ServiceError{Msg: err.Error(), Code: 409, Err: err}
+	}
+	return fmt.Errorf("ошибка переговоров по отклику: %w", err)
+}
+
+// GetContractMilestones возвращает этапы контракта с отчётами заказчику или исполнителю.
+func (s *TasksServiceImp) GetContractMilestones(ctx context.Context, contractID, userID int64) ([]Milestone, error) {
+	contract, err := s.tasksRepo.GetContractByID(ctx, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения контракта: %w", err)
+	}
+	if contract == nil {
+		return nil, &ServiceError{Msg: "контракт не найден", Code: 404}
+	}
+	if userID != contract.CustomerID && userID != contract.ExecutorID {
+		return nil, &ServiceError{Msg: "этапы контракта видят только заказчик и исполнитель", Code: 403}
+	}
+
+	milestones, err := s.tasksRepo.ListMilestones(ctx, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении этапов контракта: %w", err)
+	}
+	return milestones, nil
+}
+
+// ReplaceMilestones заменяет план этапов активного контракта. Доступно заказчику,
+// пока исполнитель не сдал ни одного отчёта; сумма этапов должна остаться равной цене контракта.
+func (s *TasksServiceImp) ReplaceMilestones(ctx context.Context, contractID, userID int64, plan []MilestoneRequest) ([]Milestone, error) {
+	contract, err := s.tasksRepo.GetContractByID(ctx, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения контракта: %w", err)
+	}
+	if contract == nil {
+		return nil, &ServiceError{Msg: "контракт не найден", Code: 404}
+	}
+	if userID != contract.CustomerID {
+		return nil, &ServiceError{Msg: "план этапов меняет только заказчик", Code: 403}
+	}
+	if !contract.IsActive {
+		return nil, &ServiceError{Msg: "контракт закрыт", Code: 409}
+	}
+
+	total := 0
+	if contract.Price != nil {
+		total = contract.Price.Amount
+	}
+	if err := ValidateMilestones(plan, total); err != nil {
+		return nil, milestoneError(err)
+	}
+
+	if err := s.tasksRepo.ReplaceMilestones(ctx, contractID, plan); err != nil {
+		return nil, milestoneError(err)
+	}
+	return s.tasksRepo.ListMilestones(ctx, contractID)
+}
+
+// milestoneError превращает ошибки этапов в ServiceError с подходящим кодом.
+func milestoneError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidMilestones), errors.Is(err, ErrMilestoneRequired):
+		return &ServiceError{Msg: err.Error(), Code: 400, Err: err}
+	case errors.Is(err, ErrMilestoneNotFound):
+		return &ServiceError{Msg: err.Error(), Code: 404, Err: err}
+	case errors.Is(err, ErrMilestonesLocked), errors.Is(err, ErrReportAlreadySubmitted), errors.Is(err, ErrReportNotSubmitted):
+		return &ServiceError{Msg: err.Error(), Code: 409, Err: err}
+	}
+	return fmt.Errorf("ошибка этапов контракта: %w", err)
+}
+
+// HandleDisputeResolved — обработчик решения арбитра по спору.
+// Контракт закрывается, задача завершается или отменяется в зависимости от решения.
+func (s *TasksServiceImp) HandleDisputeResolved(event any) {
+	e, ok := event.(disputes.DisputeResolvedEvent)
+	if !ok {
+		slog.Error("[Disputes] Получено некорректное событие", "event", event)
+		return
+	}
+	ctx := context.Background()
+
+	to := StatusCompleted
+	reason := fmt.Sprintf("решение арбитра по спору %d: работа принята", e.DisputeID)
+	switch e.Outcome {
+	case "partial":
+		reason = fmt.Sprintf("решение арбитра по спору %d: работа принята частично", e.DisputeID)
+	case "cancel":
+		to = StatusCancelled
+		reason = fmt.Sprintf("решение арбитра по спору %d: контракт расторгнут", e.DisputeID)
+	}
+
+	// Контракт закрывается в одной транзакции со сменой статуса задачи. Если статус успели изменить
+	// между проверкой и записью, переход проверяется и записывается заново.
+	for attempt := 1; ; attempt++ {
+		change, err := s.prepareTransition(ctx, e.TaskID, e.ArbitratorID, to, reason, true)
+		if err != nil {
+			slog.Error("[Disputes] Решение арбитра не применено: смена статуса задачи невозможна", "task_id", e.TaskID, "dispute_id", e.DisputeID, "error", err)
+			return
+		}
+		err = s.tasksRepo.FinishContract(ctx, e.ContractID, change)
+		if err == nil {
+			s.publishTransition(change)
+			return
+		}
+		if !errors.Is(err, ErrStatusConflict) || attempt == transitionAttempts {
+			slog.Error("[Disputes] Не удалось закрыть контракт и сменить статус задачи по решению арбитра", "task_id", e.TaskID, "contract_id", e.ContractID, "dispute_id", e.DisputeID, "error", err)
+			return
+		}
+	}
+}
+
+// HandleAuctionClosed — обработчик завершения торгов по задаче.
+// С победителем заключается контракт на сумму выигравшей ставки с одним этапом на весь объём работ;
+// заказчик может затем разбить его на этапы.
+func (s *TasksServiceImp) HandleAuctionClosed(event any) {
+	e, ok := event.(bids.AuctionClosedEvent)
+	if !ok {
+		slog.Error("[Bids] Получено некорректное событие", "event", event)
+		return
+	}
+	if e.WinnerID == nil || e.WinningAmount == nil {
+		return
+	}
+	ctx := context.Background()
+
+	status, err := s.tasksRepo.GetTaskStatus(ctx, e.TaskID)
+	if err != nil {
+		slog.Error("[Bids] Не удалось получить статус задачи", "task_id", e.TaskID, "error", err)
+		return
+	}
+	if status != StatusActive {
+		slog.Warn("[Bids] Контракт по итогам торгов не заключён: задача уже не открыта", "task_id", e.TaskID, "status", status.String())
+		return
+	}
+
+	statusID, err := s.tasksRepo.GetActiveStatusID(ctx)
+	if err != nil {
+		slog.Error("[Bids] Не удалось получить статус контракта", "error", err)
+		return
+	}
+	// Торги ведутся в базовой валюте.
+	terms := OfferTerms{Price: *e.WinningAmount, Currency: money.Base}
+	contractID, err := s.tasksRepo.StartContract(ctx, NewContract{
+		TaskID:     e.TaskID,
+		ExecutorID: *e.WinnerID,
+		CustomerID: e.CustomerID,
+		CreatedAt:  currentTime(),
+		StatusID:   statusID,
+		Terms:      terms,
+		Milestones: DefaultMilestones(terms),
+		AuctionID:  &e.AuctionID,
+	})
+	if err != nil {
+		slog.Error("[Bids] Не удалось заключить контракт с победителем торгов", "task_id", e.TaskID, "auction_id", e.AuctionID, "error", err)
+		return
+	}
+
+	s.bus.Publish(tasks.TaskStatusChangedEvent{TaskID: e.TaskID, FromStatus: int(StatusActive), ToStatus: int(StatusInProgress)})
+	s.bus.Publish(tasks.ContractCreatedEvent{
+		ContractID: contractID,
+		TaskID:     e.TaskID,
+		CustomerID: e.CustomerID,
+		ExecutorID: *e.WinnerID,
+		Price:      terms.Price,
+		Currency:   terms.Currency,
+	})
+}
@@ -0,0 +1,640 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/unclaim/chegonado.git/internal/bids"
+	"github.com/unclaim/chegonado.git/internal/disputes"
+	reviewsDomain "github.com/unclaim/chegonado.git/internal/reviews/domain"
+	"github.com/unclaim/chegonado.git/internal/tasks"
+	"github.com/unclaim/chegonado.git/pkg/infrastructure/eventbus"
+)
+
+const (
+	testCustomerID = 1
+	testExecutorID = 2
+	testStrangerID = 3
+)
+
+// memTasksRepo — контракт с этапами и статус задачи в памяти: достаточно для проверки отчётов.
+type memTasksRepo struct {
+	TasksRepository
+	contract   Contract
+	milestones []Milestone
+	status     TaskStatusCode
+	history    []TaskStatusChange
+	finished   int // Сколько раз контракт закрывался вместе со сменой статуса
+	responders []int64
+	revisions  []TaskRevision
+	documents  bool // По контракту выпущены счёт или чек
+	deleted    bool
+	// beforeWrite вызывается в начале транзакции: так тесты изображают параллельную смену статуса.
+	beforeWrite func(r *memTasksRepo)
+}
+
+func newMemTasksRepo(amounts ...int) *memTasksRepo {
+	r := &memTasksRepo{
+		contract: Contract{ID: 10, TaskID: 20, CustomerID: testCustomerID, ExecutorID: testExecutorID, IsActive: true},
+		status:   StatusInProgress,
+	}
+	for i, amount := range amounts {
+		r.milestones = append(r.milestones, Milestone{ID: int64(100 + i), ContractID: 10, Position: i + 1, Title: "Этап", Amount: amount})
+	}
+	return r
+}
+
+func (r *memTasksRepo) GetContractByID(_ context.Context, contractID int64) (*Contract, error) {
+	if contractID != r.contract.ID {
+		return nil, nil
+	}
+	c := r.contract
+	return &c, nil
+}
+
+func (r *memTasksRepo) ListMilestones(_ context.Context, _ int64) ([]Milestone, error) {
+	result := make([]Milestone, len(r.milestones))
+	for i, m := range r.milestones {
+		if m.Report != nil {
+			report := *m.Report
+			m.Report = &report
+		}
+		m.Status = StatusFromReport(m.Report)
+		result[i] = m
+	}
+	return result, nil
+}
+
+func (r *memTasksRepo) milestone(id int64) *Milestone {
+	for i := range r.milestones {
+		if r.milestones[i].ID == id {
+			return &r.milestones[i]
+		}
+	}
+	return nil
+}
+
+func (r *memTasksRepo) report(id int64) *Report {
+	for i := range r.milestones {
+		if rp := r.milestones[i].Report; rp != nil && rp.ID == id {
+			return rp
+		}
+	}
+	return nil
+}
+
+func (r *memTasksRepo) CreateReport(_ context.Context, contractID, taskID, milestoneID int64, comments string, executionStatus bool) error {
+	m := r.milestone(milestoneID)
+	m.Report = &Report{ID: milestoneID + 1000, ContractID: contractID, TaskID: taskID, MilestoneID: &m.ID, ExecutorComments: &comments, ExecutionStatus: executionStatus}
+	return nil
+}
+
+func (r *memTasksRepo) ResubmitReport(_ context.Context, reportID int64, comments string, executionStatus bool) error {
+	if r.beforeWrite != nil {
+		r.beforeWrite(r)
+	}
+	rp := r.report(reportID)
+	if rp.CustomerConfirmation != nil && *rp.CustomerConfirmation {
+		return ErrReportAlreadySubmitted
+	}
+	rp.ExecutorComments, rp.ExecutionStatus, rp.CustomerConfirmation = &comments, executionStatus, nil
+	return nil
+}
+
+func (r *memTasksRepo) UpdateReport(_ context.Context, reportID int64, feedback string, confirmation *bool) error {
+	rp := r.report(reportID)
+	if confirmation != nil && rp.CustomerConfirmation != nil {
+		return ErrReportNotSubmitted
+	}
+	rp.CustomerFeedback = &feedback
+	if confirmation != nil {
+		rp.CustomerConfirmation = confirmation
+	}
+	return nil
+}
+
+func (r *memTasksRepo) GetTaskStatus(_ context.Context, _ int64) (TaskStatusCode, error) {
+	return r.status, nil
+}
+
+func (r *memTasksRepo) GetTransitionGuards(_ context.Context, _ int64) (TransitionGuards, error) {
+	milestones, _ := r.ListMilestones(context.Background(), r.contract.ID)
+	guards := TransitionGuards{HasActiveContract: r.contract.IsActive}
+	for _, m := range milestones {
+		guards.HasReport = guards.HasReport || m.Report != nil
+	}
+	guards.ReportConfirmed = r.contract.IsActive && AllMilestonesConfirmed(milestones)
+	return guards, nil
+}
+
+func (r *memTasksRepo) UpdateTaskStatus(_ context.Context, change TaskStatusChange) error {
+	if r.status != change.FromStatus {
+		return ErrStatusConflict
+	}
+	r.status = change.ToStatus
+	r.history = append(r.history, change)
+	return nil
+}
+
+func (r *memTasksRepo) FinishContract(ctx context.Context, contractID int64, change TaskStatusChange) error {
+	if r.beforeWrite != nil {
+		r.beforeWrite(r)
+	}
+	if err := r.UpdateTaskStatus(ctx, change); err != nil {
+		return err
+	}
+	r.contract.IsActive = false
+	r.finished++
+	return nil
+}
+
+func (r *memTasksRepo) ConfirmLastMilestone(ctx context.Context, reportID int64, feedback string, contractID int64, change TaskStatusChange) error {
+	if r.beforeWrite != nil {
+		r.beforeWrite(r)
+	}
+	rp := r.report(reportID)
+	if rp.CustomerConfirmation != nil {
+		return ErrReportNotSubmitted
+	}
+	if err := r.UpdateTaskStatus(ctx, change); err != nil {
+		return err
+	}
+	rp.CustomerFeedback, rp.CustomerConfirmation = &feedback, boolPtr(true)
+	r.contract.IsActive = false
+	r.finished++
+	return nil
+}
+
+func (r *memTasksRepo) StartContract(ctx context.Context, c NewContract) (int64, error) {
+	if r.beforeWrite != nil {
+		r.beforeWrite(r)
+	}
+	contract := Contract{ID: r.contract.ID + 1, TaskID: c.TaskID, CustomerID: c.CustomerID, ExecutorID: c.ExecutorID, IsActive: true}
+	err := r.UpdateTaskStatus(ctx, TaskStatusChange{TaskID: c.TaskID, FromStatus: StatusActive, ToStatus: StatusInProgress, ChangedBy: c.CustomerID, Reason: c.StartReason(contract.ID), CreatedAt: c.CreatedAt})
+	if err != nil {
+		return 0, err
+	}
+	r.contract = contract
+	return contract.ID, nil
+}
+
+func (r *memTasksRepo) HasContractDocuments(_ context.Context, _ int64) (bool, error) {
+	return r.documents, nil
+}
+
+func (r *memTasksRepo) DeleteTask(_ context.Context, _, _ int64) error {
+	if r.documents {
+		return ErrTaskHasDocuments
+	}
+	r.deleted = true
+	return nil
+}
+
+func (r *memTasksRepo) GetActiveStatusID(_ context.Context) (int64, error) {
+	return 1, nil
+}
+
+func (r *memTasksRepo) CheckTaskOwnership(_ context.Context, _, userID int64) (bool, error) {
+	return userID == r.contract.CustomerID, nil
+}
+
+func (r *memTasksRepo) GetActiveContractByTask(_ context.Context, _ int64) (*Contract, error) {
+	if !r.contract.IsActive {
+		return nil, nil
+	}
+	c := r.contract
+	return &c, nil
+}
+
+func (r *memTasksRepo) ResponseExists(_ context.Context, _, userID int64) (bool, error) {
+	for _, id := range r.responders {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memTasksRepo) GetResponderIDs(_ context.Context, _ int64) ([]int64, error) {
+	return r.responders, nil
+}
+
+func (r *memTasksRepo) GetTaskRevisions(_ context.Context, _ int64) ([]TaskRevision, error) {
+	return r.revisions, nil
+}
+
+func (r *memTasksRepo) UpdateTask(_ context.Context, taskID, userID int64, req UpdateTaskRequest, changedAt time.Time) (int, error) {
+	if r.beforeWrite != nil {
+		r.beforeWrite(r)
+	}
+	if r.status != StatusActive {
+		return 0, ErrStatusConflict
+	}
+	revision := len(r.revisions) + 1
+	r.revisions = append(r.revisions, TaskRevision{TaskID: taskID, Revision: revision, Title: req.Title, ChangedBy: userID, ReplacedAt: changedAt})
+	return revision + 1, nil
+}
+
+func (r *memTasksRepo) GetTaskByID(_ context.Context, id int64) (*Task, error) {
+	return &Task{ID: id}, nil
+}
+
+type recordingBus struct {
+	events []eventbus.Event
+}
+
+func (b *recordingBus) Publish(event eventbus.Event) {
+	b.events = append(b.events, event)
+}
+
+func (b *recordingBus) count(match func(eventbus.Event) bool) int {
+	n := 0
+	for _, e := range b.events {
+		if match(e) {
+			n++
+		}
+	}
+	return n
+}
+
+func eventTypes(events []eventbus.Event) []string {
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = fmt.Sprintf("%T", e)
+	}
+	return types
+}
+
+func isMilestoneConfirmed(e eventbus.Event) bool {
+	_, ok := e.(tasks.MilestoneConfirmedEvent)
+	return ok
+}
+
+func serviceCode(err error) int {
+	var svcErr *ServiceError
+	if errors.As(err, &svcErr) {
+		return svcErr.Code
+	}
+	return 0
+}
+
+func testReport(milestoneID int64) Report {
+	comments := "Работа выполнена"
+	return Report{ContractID: 10, MilestoneID: &milestoneID, ExecutorComments: &comments, ExecutionStatus: true}
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestCreateReportOnlyByExecutor(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	svc := NewTasksService(repo, &recordingBus{}, nil, nil)
+	ctx := context.Background()
+
+	for _, userID := range []int64{testCustomerID, testStrangerID} {
+		if err := svc.CreateReport(ctx, userID, testReport(100)); serviceCode(err) != 403 {
+			t.Errorf("пользователь %d: отчёт сдаёт только исполнитель, получено %v", userID, err)
+		}
+	}
+	if repo.milestones[0].Report != nil {
+		t.Fatal("отчёт не должен сохраняться от чужого имени")
+	}
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("исполнитель должен сдать отчёт: %v", err)
+	}
+}
+
+func TestUpdateReportOnlyByCustomerAndOnlySubmitted(t *testing.T) {
+	repo := newMemTasksRepo(3000, 2000)
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	ctx := context.Background()
+
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); serviceCode(err) != 404 {
+		t.Errorf("этап без отчёта нельзя подтвердить, получено %v", err)
+	}
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	for _, userID := range []int64{testExecutorID, testStrangerID} {
+		if err := svc.UpdateReport(ctx, 10, userID, int64Ptr(100), nil, boolPtr(true)); serviceCode(err) != 403 {
+			t.Errorf("пользователь %d: решение по отчёту принимает только заказчик, получено %v", userID, err)
+		}
+	}
+	if n := bus.count(isMilestoneConfirmed); n != 0 {
+		t.Fatalf("подтверждение не заказчиком не должно освобождать оплату, событий %d", n)
+	}
+
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); err != nil {
+		t.Fatalf("заказчик должен подтвердить сданный отчёт: %v", err)
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); serviceCode(err) != 409 {
+		t.Errorf("подтверждённый отчёт нельзя подтвердить повторно, получено %v", err)
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(false)); serviceCode(err) != 409 {
+		t.Errorf("подтверждённый отчёт нельзя отклонить, получено %v", err)
+	}
+	feedback := "Спасибо"
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), &feedback, nil); err != nil {
+		t.Fatalf("отзыв на отчёт можно оставить и после решения: %v", err)
+	}
+	if n := bus.count(isMilestoneConfirmed); n != 1 {
+		t.Errorf("оплата этапа должна освобождаться один раз, событий %d", n)
+	}
+	if repo.status != StatusInProgress {
+		t.Errorf("пока подтверждены не все этапы, задача остаётся в работе: %s", repo.status)
+	}
+}
+
+func TestUpdateReportRejectThenResubmit(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	ctx := context.Background()
+
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	reason := "Не хватает фото"
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, nil, &reason, boolPtr(false)); err != nil {
+		t.Fatalf("заказчик должен отклонить отчёт: %v", err)
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, nil, nil, boolPtr(true)); serviceCode(err) != 409 {
+		t.Errorf("отклонённый отчёт нельзя подтвердить до повторной сдачи, получено %v", err)
+	}
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("отклонённый отчёт сдаётся повторно: %v", err)
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, nil, nil, boolPtr(true)); err != nil {
+		t.Fatalf("повторно сданный отчёт можно подтвердить: %v", err)
+	}
+}
+
+// memReviewsRepo отдаёт отзывам контракт из репозитория задач.
+type memReviewsRepo struct {
+	reviewsDomain.ReviewsRepository
+	tasks *memTasksRepo
+}
+
+func (r *memReviewsRepo) GetContract(_ context.Context, _ int64) (*reviewsDomain.Contract, error) {
+	c := r.tasks.contract
+	return &reviewsDomain.Contract{ID: c.ID, CustomerID: c.CustomerID, ExecutorID: c.ExecutorID, IsActive: c.IsActive}, nil
+}
+
+func (r *memReviewsRepo) InsertReview(_ context.Context, review reviewsDomain.Review) (*reviewsDomain.Review, error) {
+	review.ID = 1
+	return &review, nil
+}
+
+func (r *memReviewsRepo) PublishContract(_ context.Context, _ int64, _ time.Time) ([]reviewsDomain.Review, error) {
+	return nil, nil
+}
+
+func TestConfirmAllMilestonesThenReview(t *testing.T) {
+	repo := newMemTasksRepo(3000, 2000)
+	svc := NewTasksService(repo, &recordingBus{}, nil, nil)
+	reviewsSvc := reviewsDomain.NewReviewsService(&memReviewsRepo{tasks: repo}, &recordingBus{}, reviewsDomain.Settings{})
+	ctx := context.Background()
+	review := reviewsDomain.ReviewRequest{ContractID: 10, Rating: 5, Comment: "Всё сделано в срок"}
+
+	for _, m := range repo.milestones {
+		if err := svc.CreateReport(ctx, testExecutorID, testReport(m.ID)); err != nil {
+			t.Fatalf("этап %d: неожиданная ошибка при сдаче отчёта: %v", m.ID, err)
+		}
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := reviewsSvc.CreateReview(ctx, testCustomerID, review); !errors.Is(err, reviewsDomain.ErrContractActive) {
+		t.Fatalf("отзыв до подтверждения всех этапов: ожидалась ErrContractActive, получено %v", err)
+	}
+
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(101), nil, boolPtr(true)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if repo.status != StatusCompleted || repo.contract.IsActive {
+		t.Fatalf("после подтверждения всех этапов задача завершается, а контракт закрывается: статус %s, активен %v", repo.status, repo.contract.IsActive)
+	}
+	if repo.finished != 1 || len(repo.history) != 1 {
+		t.Errorf("контракт должен закрываться одной операцией со сменой статуса: закрытий %d, переходов %d", repo.finished, len(repo.history))
+	}
+
+	for _, authorID := range []int64{testCustomerID, testExecutorID} {
+		if _, err := reviewsSvc.CreateReview(ctx, authorID, review); err != nil {
+			t.Errorf("пользователь %d: после завершения контракта отзыв доступен, получено %v", authorID, err)
+		}
+	}
+}
+
+func TestCancelTaskClosesContractWithTask(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	ctx := context.Background()
+
+	// Пока сервис проверял переход, задачу завершили: ни отмены, ни расторжения быть не должно.
+	repo.beforeWrite = func(r *memTasksRepo) { r.status = StatusCompleted }
+	if err := svc.CancelTask(ctx, 20, testCustomerID); serviceCode(err) != 409 {
+		t.Fatalf("отмена уже завершённой задачи: ожидался конфликт, получено %v", err)
+	}
+	if !repo.contract.IsActive || len(bus.events) != 0 {
+		t.Fatalf("контракт не закрывается и события не публикуются, если отмена не сохранена: активен %v, события %v", repo.contract.IsActive, eventTypes(bus.events))
+	}
+
+	repo.status, repo.beforeWrite = StatusInProgress, nil
+	if err := svc.CancelTask(ctx, 20, testCustomerID); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if repo.status != StatusCancelled || repo.contract.IsActive || repo.finished != 1 {
+		t.Errorf("задача отменяется вместе с расторжением контракта: статус %s, активен %v, закрытий %d", repo.status, repo.contract.IsActive, repo.finished)
+	}
+	want := []string{"tasks.TaskCancelledEvent", "tasks.ContractCancelledEvent"}
+	if got := eventTypes(bus.events); !reflect.DeepEqual(got, want) {
+		t.Errorf("события: ожидалось %v, получено %v", want, got)
+	}
+}
+
+func TestAuctionClosedStartsContractWithTask(t *testing.T) {
+	repo := newMemTasksRepo()
+	repo.status, repo.contract.IsActive = StatusActive, false
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	winnerID, amount := int64(testExecutorID), 7000
+	event := bids.AuctionClosedEvent{AuctionID: 5, TaskID: 20, CustomerID: testCustomerID, WinnerID: &winnerID, WinningAmount: &amount}
+
+	// Заказчик отменил задачу одновременно с закрытием торгов.
+	repo.beforeWrite = func(r *memTasksRepo) { r.status = StatusCancelled }
+	svc.HandleAuctionClosed(event)
+	if repo.contract.IsActive || len(bus.events) != 0 {
+		t.Fatalf("контракт по отменённой задаче не заключается: активен %v, события %v", repo.contract.IsActive, eventTypes(bus.events))
+	}
+
+	repo.status, repo.beforeWrite = StatusActive, nil
+	svc.HandleAuctionClosed(event)
+	if repo.status != StatusInProgress || !repo.contract.IsActive {
+		t.Fatalf("задача уходит в работу вместе с заключением контракта: статус %s, активен %v", repo.status, repo.contract.IsActive)
+	}
+	if len(repo.history) != 1 || repo.history[0].Reason != "заключён контракт 11 по итогам торгов 5" {
+		t.Errorf("неверная история статусов: %+v", repo.history)
+	}
+	want := []string{"tasks.TaskStatusChangedEvent", "tasks.ContractCreatedEvent"}
+	if got := eventTypes(bus.events); !reflect.DeepEqual(got, want) {
+		t.Errorf("события: ожидалось %v, получено %v", want, got)
+	}
+}
+
+func TestGetTaskRevisionsOnlyForOwnerAndResponders(t *testing.T) {
+	repo := newMemTasksRepo()
+	repo.responders = []int64{testExecutorID}
+	repo.revisions = []TaskRevision{{TaskID: 20, Revision: 1, Title: "Старое название"}}
+	svc := NewTasksService(repo, &recordingBus{}, nil, nil)
+	ctx := context.Background()
+
+	for _, userID := range []int64{testCustomerID, testExecutorID} {
+		if revisions, err := svc.GetTaskRevisions(ctx, 20, userID); err != nil || len(revisions) != 1 {
+			t.Errorf("пользователь %d: редакции доступны заказчику и откликнувшимся, получено %v, %v", userID, revisions, err)
+		}
+	}
+	if _, err := svc.GetTaskRevisions(ctx, 20, testStrangerID); serviceCode(err) != 403 {
+		t.Errorf("посторонний не видит редакции задачи, получено %v", err)
+	}
+}
+
+func TestUpdateTaskRechecksStatusInTransaction(t *testing.T) {
+	repo := newMemTasksRepo()
+	repo.status = StatusActive
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	ctx := context.Background()
+	req := UpdateTaskRequest{Title: "Покрасить забор", Description: "Два слоя"}
+
+	// Пока сервис проверял статус, по задаче заключили контракт.
+	repo.beforeWrite = func(r *memTasksRepo) { r.status = StatusInProgress }
+	if _, err := svc.UpdateTask(ctx, 20, testCustomerID, req); serviceCode(err) != 409 {
+		t.Fatalf("правка задачи, ушедшей в работу: ожидался конфликт, получено %v", err)
+	}
+	if len(repo.revisions) != 0 || len(bus.events) != 0 {
+		t.Fatalf("условия задачи в работе не меняются: редакций %d, события %v", len(repo.revisions), eventTypes(bus.events))
+	}
+
+	repo.status, repo.beforeWrite = StatusActive, nil
+	if _, err := svc.UpdateTask(ctx, 20, testCustomerID, req); err != nil {
+		t.Fatalf("открытую задачу можно изменить: %v", err)
+	}
+	if len(repo.revisions) != 1 || len(bus.events) != 1 {
+		t.Errorf("ожидалась одна редакция и одно событие: редакций %d, события %v", len(repo.revisions), eventTypes(bus.events))
+	}
+}
+
+func TestDisputeResolvedFinishesContractWithTask(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	event := disputes.DisputeResolvedEvent{DisputeID: 3, ContractID: 10, TaskID: 20, ArbitratorID: 9, Outcome: "cancel"}
+
+	// Задача уже завершена: решение не применяется, контракт не закрывается отдельно от задачи.
+	repo.status = StatusCompleted
+	svc.HandleDisputeResolved(event)
+	if !repo.contract.IsActive || len(bus.events) != 0 {
+		t.Fatalf("без смены статуса контракт не закрывается: активен %v, события %v", repo.contract.IsActive, eventTypes(bus.events))
+	}
+
+	// Перед первой записью контракт параллельно расторгнут и задача вернулась в Active:
+	// переход проверяется заново и записывается уже из нового статуса.
+	repo.status = StatusInProgress
+	writes := 0
+	repo.beforeWrite = func(r *memTasksRepo) {
+		writes++
+		if writes == 1 {
+			r.status = StatusActive
+			r.contract.IsActive = false
+		}
+	}
+	svc.HandleDisputeResolved(event)
+	if writes != 2 || repo.status != StatusCancelled || repo.finished != 1 {
+		t.Fatalf("после конфликта переход повторяется: записей %d, статус %s, закрытий %d", writes, repo.status, repo.finished)
+	}
+	if got := eventTypes(bus.events); !reflect.DeepEqual(got, []string{"tasks.TaskCancelledEvent"}) {
+		t.Errorf("событие об отмене публикуется один раз после записи: %v", got)
+	}
+}
+
+func TestLastConfirmationIsSavedWithContractFinish(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	bus := &recordingBus{}
+	svc := NewTasksService(repo, bus, nil, nil)
+	ctx := context.Background()
+
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("неожиданная ошибка при сдаче отчёта: %v", err)
+	}
+
+	// Завершение не удалось: подтверждение не сохраняется, и его можно повторить.
+	repo.beforeWrite = func(r *memTasksRepo) { r.status = StatusCancelled }
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); serviceCode(err) != 409 {
+		t.Fatalf("конфликт статуса при завершении: ожидался код 409, получено %v", err)
+	}
+	repo.status = StatusInProgress
+	if got := repo.milestone(100).Report.CustomerConfirmation; got != nil || !repo.contract.IsActive || len(bus.events) != 0 {
+		t.Fatalf("без завершения контракта этап ждёт решения: подтверждение %v, активен %v, события %v", got, repo.contract.IsActive, eventTypes(bus.events))
+	}
+
+	repo.beforeWrite = nil
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(true)); err != nil {
+		t.Fatalf("повторное подтверждение: неожиданная ошибка %v", err)
+	}
+	if repo.status != StatusCompleted || repo.contract.IsActive || repo.finished != 1 {
+		t.Fatalf("подтверждение последнего этапа завершает задачу: статус %s, активен %v, закрытий %d", repo.status, repo.contract.IsActive, repo.finished)
+	}
+	want := []string{"tasks.MilestoneConfirmedEvent", "tasks.TaskStatusChangedEvent"}
+	if got := eventTypes(bus.events); !reflect.DeepEqual(got, want) {
+		t.Errorf("события публикуются после записи: ожидалось %v, получено %v", want, got)
+	}
+}
+
+func TestResubmitDoesNotReplaceConfirmedReport(t *testing.T) {
+	repo := newMemTasksRepo(3000, 2000)
+	svc := NewTasksService(repo, &recordingBus{}, nil, nil)
+	ctx := context.Background()
+
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if err := svc.UpdateReport(ctx, 10, testCustomerID, int64Ptr(100), nil, boolPtr(false)); err != nil {
+		t.Fatalf("заказчик должен отклонить отчёт: %v", err)
+	}
+
+	// Между проверкой и записью отчёт подтвердили: повторная сдача не снимает подтверждение.
+	repo.beforeWrite = func(r *memTasksRepo) { r.milestone(100).Report.CustomerConfirmation = boolPtr(true) }
+	if err := svc.CreateReport(ctx, testExecutorID, testReport(100)); serviceCode(err) != 409 {
+		t.Fatalf("подтверждённый отчёт нельзя сдать повторно: ожидался код 409, получено %v", err)
+	}
+	if got := repo.milestone(100).Report.CustomerConfirmation; got == nil || !*got {
+		t.Errorf("подтверждение отчёта должно сохраниться, получено %v", got)
+	}
+}
+
+func TestDeleteTaskWithDocuments(t *testing.T) {
+	repo := newMemTasksRepo(5000)
+	repo.status, repo.contract.IsActive, repo.documents = StatusCancelled, false, true
+	svc := NewTasksService(repo, &recordingBus{}, nil, nil)
+
+	err := svc.DeleteTaskByID(context.Background(), testCustomerID, 20)
+	if serviceCode(err) != 409 || !errors.Is(err, ErrTaskHasDocuments) {
+		t.Fatalf("задачу с выпущенными документами нельзя удалить: ожидался код 409, получено %v", err)
+	}
+	if repo.deleted {
+		t.Error("задача с документами не должна удаляться")
+	}
+
+	repo.documents = false
+	if err := svc.DeleteTaskByID(context.Background(), testCustomerID, 20); err != nil || !repo.deleted {
+		t.Errorf("отменённая задача без документов удаляется: удалена %v, ошибка %v", repo.deleted, err)
+	}
+}
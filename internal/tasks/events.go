@@ -0,0 +1,103 @@
+package tasks
+
+import "github.com/unclaim/chegonado.git/internal/shared/money"
+
+// TaskUpdatedEvent — событие, которое публикуется после изменения условий задачи заказчиком.
+type TaskUpdatedEvent struct {
+	TaskID       int64
+	CustomerID   int64
+	Title        string
+	Revision     int     // Номер новой редакции задачи
+	ResponderIDs []int64 // Исполнители, откликнувшиеся на предыдущие редакции; может быть пустым
+}
+
+// TaskStatusChangedEvent — событие смены статуса задачи, кроме отмены: для неё публикуется TaskCancelledEvent.
+type TaskStatusChangedEvent struct {
+	TaskID     int64
+	FromStatus int
+	ToStatus   int
+}
+
+// TaskCancelledEvent — событие отмены задачи заказчиком или по решению арбитра.
+type TaskCancelledEvent struct {
+	TaskID      int64
+	CancelledBy int64
+}
+
+// TaskDeletedEvent — событие удаления задачи заказчиком.
+type TaskDeletedEvent struct {
+	TaskID     int64
+	CustomerID int64
+}
+
+// TaskCreatedEvent — событие, которое публикуется после создания новой задачи.
+type TaskCreatedEvent struct {
+	TaskID     int64
+	CustomerID int64
+	Title      string
+}
+
+// MatchedTask — задача, подошедшая под сохранённый поиск.
+type MatchedTask struct {
+	ID    int64
+	Title string
+}
+
+// SavedSearchAlertEvent — событие оповещения пользователя о новых задачах по сохранённому поиску.
+// Для мгновенных оповещений Tasks содержит одну задачу, для сводок — все накопленные.
+type SavedSearchAlertEvent struct {
+	UserID     int64
+	SearchID   int64
+	SearchName string
+	Digest     bool // true для часовой или суточной сводки
+	Tasks      []MatchedTask
+}
+
+// ResponseOfferEvent — событие хода в переговорах по отклику.
+// RecipientID — сторона, которой теперь нужно ответить.
+type ResponseOfferEvent struct {
+	TaskID      int64
+	ResponseID  int64
+	RecipientID int64
+	Action      string // counter, accept или withdraw
+	Price       int    // В минимальных единицах Currency
+	Currency    money.Currency
+}
+
+// ReportRejectedEvent — событие отклонения заказчиком отчёта по этапу контракта.
+// Исполнитель может сдать отчёт заново или открыть спор.
+type ReportRejectedEvent struct {
+	ContractID     int64
+	TaskID         int64
+	MilestoneID    int64
+	MilestoneTitle string
+	ExecutorID     int64
+	Feedback       string
+}
+
+// ContractCreatedEvent — событие заключения контракта. Price — согласованная сумма контракта
+// в минимальных единицах Currency.
+type ContractCreatedEvent struct {
+	ContractID int64
+	TaskID     int64
+	CustomerID int64
+	ExecutorID int64
+	Price      int
+	Currency   money.Currency
+}
+
+// MilestoneConfirmedEvent — событие подтверждения заказчиком отчёта по этапу контракта.
+type MilestoneConfirmedEvent struct {
+	ContractID  int64
+	TaskID      int64
+	MilestoneID int64
+	Amount      int // Сумма этапа
+}
+
+// ContractCancelledEvent — событие расторжения действующего контракта при отмене задачи заказчиком.
+type ContractCancelledEvent struct {
+	ContractID int64
+	TaskID     int64
+	CustomerID int64
+	ExecutorID int64
+}
@@ -8,16 +8,27 @@ import (
 	"errors"
 	"fmt"
 	"log/slog" // Для GetTaskByID и SearchTasks, если там были strconv.ParseInt
-	"strings"
+	"net/url"
 	"time"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/lib/pq"
 
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/money"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/tasks"
 	"github.com/unclaim/chegonado.git/internal/tasks/domain" // Обновленный импорт моделей
 )
 
+// Порядки сортировки списков, для которых выдаются курсоры.
+const (
+	tasksFeedSort     = "newest"
+	responsesListSort = "oldest"
+)
+
 // TasksRepository представляет реализацию репозитория задач для PostgreSQL.
 type TasksRepository struct {
 	db *pgxpool.Pool
@@ -31,11 +42,14 @@ func NewTasksRepository(db *pgxpool.Pool) *TasksRepository {
 }
 
 // GetReportByContractID получает отчет по идентификатору контракта.
+// Если контракт разбит на этапы, возвращается последний сданный отчёт.
 func (r *TasksRepository) GetReportByContractID(ctx context.Context, contractID int64) (*domain.Report, error) {
 	query := `
-        SELECT id, contract_id, task_id, executor_comments, customer_feedback, execution_status, customer_confirmation, created_at, updated_at
+        SELECT id, contract_id, task_id, milestone_id, executor_comments, customer_feedback, execution_status, customer_confirmation, created_at, updated_at
         FROM reports
-        WHERE contract_id = $1;`
+        WHERE contract_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT 1;`
 	var report domain.Report
 	var executorComments, customerFeedback sql.NullString
 	var customerConfirmation sql.NullBool
@@ -44,6 +58,7 @@ func (r *TasksRepository) GetReportByContractID(ctx context.Context, contractID
 		&report.ID,
 		&report.ContractID,
 		&report.TaskID,
+		&report.MilestoneID,
 		&executorComments,
 		&customerFeedback,
 		&report.ExecutionStatus,
@@ -101,46 +116,180 @@ func (r *TasksRepository) RecordTaskView(ctx context.Context, taskID int64, user
 	return nil
 }
 
-// InsertTaskIntoDB вставляет новую задачу в базу данных.
+// InsertTaskIntoDB вставляет новую задачу в базу данных вместе с координатами её адресов.
 func (r *TasksRepository) InsertTaskIntoDB(ctx context.Context, task domain.Task, userID int64) (int, error) {
 	query := `
-        INSERT INTO tasks (title, description, user_id, category_id, subcategory_id, cost, addresses, service_location, period_type, start_date, end_date)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        INSERT INTO tasks (title, description, user_id, category_id, subcategory_id, cost, cost_currency, cost_base, addresses, service_location, period_type, start_date, end_date, status_code)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
         RETURNING id`
 
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var id int
-	err := r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		task.Title,
 		task.Description,
 		userID,
 		task.CategoryID,
 		task.SubcategoryID,
-		task.Cost,
+		costAmount(task.Cost),
+		costCurrency(task.Cost),
+		task.CostBase,
 		pq.Array(task.Addresses), // Использование pq.Array для []string
 		task.ServiceLocation,
 		task.PeriodType,
 		task.StartDate,
-		task.EndDate).Scan(&id)
+		task.EndDate,
+		int(domain.StatusActive)).Scan(&id)
 
 	if err != nil {
 		return 0, fmt.Errorf("не удалось создать задачу для пользователя с ID %d: %w", userID, err)
 	}
 
+	if err := replaceTaskLocations(ctx, tx, int64(id), task.Locations); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать создание задачи: %w", err)
+	}
+
 	return id, nil
 }
 
-// InsertResponseIntoDB вставляет новый отклик в базу данных.
+// replaceTaskLocations заменяет адреса задачи с координатами в рамках транзакции.
+func replaceTaskLocations(ctx context.Context, tx pgx.Tx, taskID int64, locations []domain.TaskLocation) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM task_addresses WHERE task_id = $1`, taskID); err != nil {
+		return fmt.Errorf("не удалось удалить адреса задачи с ID %d: %w", taskID, err)
+	}
+	if len(locations) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for i, loc := range locations {
+		var lat, lon *float64
+		if loc.Point != nil {
+			lat, lon = &loc.Point.Lat, &loc.Point.Lon
+		}
+		batch.Queue(`INSERT INTO task_addresses (task_id, position, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5)`,
+			taskID, i, loc.Address, lat, lon)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+	for range locations {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("не удалось сохранить адреса задачи с ID %d: %w", taskID, err)
+		}
+	}
+	return nil
+}
+
+// taskCost собирает цену задачи из колонок cost, cost_currency и cost_base.
+func taskCost(cost sql.NullInt64, currency money.Currency, base sql.NullInt64) (*money.Money, *int) {
+	if !cost.Valid {
+		return nil, nil
+	}
+	m := money.New(int(cost.Int64), currency)
+	if !base.Valid {
+		return &m, nil
+	}
+	b := int(base.Int64)
+	return &m, &b
+}
+
+// contractPrice собирает цену контракта; у старых контрактов цена может быть не указана.
+func contractPrice(price sql.NullInt64, currency money.Currency) *money.Money {
+	if !price.Valid {
+		return nil
+	}
+	m := money.New(int(price.Int64), currency)
+	return &m
+}
+
+// costAmount и costCurrency раскладывают цену задачи по колонкам; у задачи без цены валюта базовая.
+func costAmount(m *money.Money) *int {
+	if m == nil {
+		return nil
+	}
+	return &m.Amount
+}
+
+func costCurrency(m *money.Money) money.Currency {
+	if m == nil {
+		return money.Base
+	}
+	return m.Currency
+}
+
+// getTaskLocations получает адреса задачи с координатами в порядке их указания.
+func (r *TasksRepository) getTaskLocations(ctx context.Context, taskID int64) ([]domain.TaskLocation, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT address, latitude, longitude
+        FROM task_addresses
+        WHERE task_id = $1
+        ORDER BY position`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении адресов задачи: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []domain.TaskLocation
+	for rows.Next() {
+		var loc domain.TaskLocation
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&loc.Address, &lat, &lon); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании адреса задачи: %w", err)
+		}
+		if lat.Valid && lon.Valid {
+			loc.Point = &geo.Point{Lat: lat.Float64, Lon: lon.Float64}
+		}
+		locations = append(locations, loc)
+	}
+
+	return locations, rows.Err()
+}
+
+// InsertResponseIntoDB вставляет новый отклик в базу данных
+// вместе с первым предложением переговоров — условиями исполнителя.
 func (r *TasksRepository) InsertResponseIntoDB(newResponse domain.ProposedResponse) (domain.ProposedResponse, error) {
+	ctx := context.Background()
 	query := `
-        INSERT INTO responses (task_id, user_id, proposed_price, response_text)
-        VALUES ($1, $2, $3, $4) RETURNING id, created_at`
-	err := r.db.QueryRow(context.Background(), query, newResponse.TaskID, newResponse.UserID, newResponse.ProposedPrice, newResponse.ResponseText).
-		Scan(&newResponse.ID, &newResponse.CreatedAt)
+        INSERT INTO responses (task_id, user_id, proposed_price, proposed_currency, response_text, task_revision, negotiation_status)
+        VALUES ($1, $2, $3, $4, $5, (SELECT revision FROM tasks WHERE id = $1), $6) RETURNING id, created_at, task_revision`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.ProposedResponse{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	newResponse.NegotiationStatus = domain.NegotiationOpen
+	err = tx.QueryRow(ctx, query, newResponse.TaskID, newResponse.UserID, newResponse.ProposedPrice.Amount, newResponse.ProposedPrice.Currency,
+		newResponse.ResponseText, newResponse.NegotiationStatus).
+		Scan(&newResponse.ID, &newResponse.CreatedAt, &newResponse.TaskRevision)
 
 	if err != nil {
 		return domain.ProposedResponse{}, fmt.Errorf("не удалось вставить отклик для задачи с ID %d и пользователя с ID %d: %w", newResponse.TaskID, newResponse.UserID, err)
 	}
 
+	_, err = tx.Exec(ctx, `
+        INSERT INTO response_offers (response_id, author_id, author_role, action, price, comment, created_at)
+        VALUES ($1, $2, $3, $4, $5, '', $6)`,
+		newResponse.ID, newResponse.UserID, domain.RoleExecutor, domain.OfferPropose, newResponse.ProposedPrice.Amount, newResponse.CreatedAt)
+	if err != nil {
+		return domain.ProposedResponse{}, fmt.Errorf("не удалось сохранить первое предложение по отклику с ID %d: %w", newResponse.ID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.ProposedResponse{}, fmt.Errorf("не удалось зафиксировать отклик: %w", err)
+	}
+
 	return newResponse, nil
 }
 
@@ -161,7 +310,7 @@ func (r *TasksRepository) GetUserCreatedTasksCount(ctx context.Context, userID i
 
 // GetTasks получает задачи, созданные определенным пользователем.
 func (tr *TasksRepository) GetTasks(ctx context.Context, userID int64) ([]*domain.Task, error) {
-	query := ` SELECT id, title, description, created_at, user_id, category_id, subcategory_id, cost, addresses, service_location, period_type, start_date, end_date, status_code FROM tasks WHERE user_id = $1`
+	query := ` SELECT id, title, description, created_at, user_id, category_id, subcategory_id, cost, cost_currency, cost_base, addresses, service_location, period_type, start_date, end_date, status_code FROM tasks WHERE user_id = $1`
 
 	rows, err := tr.db.Query(ctx, query, userID)
 	if err != nil {
@@ -172,14 +321,15 @@ func (tr *TasksRepository) GetTasks(ctx context.Context, userID int64) ([]*domai
 	var tasks []*domain.Task
 	for rows.Next() {
 		var task domain.Task
-		var categoryID, subcategoryID, cost sql.NullInt64
+		var categoryID, subcategoryID, cost, costBase sql.NullInt64
+		var costCurrency money.Currency
 		var startDate, endDate sql.NullTime
 		var addresses pq.StringArray // Используем pq.StringArray
 		var statusCode sql.NullInt64
 
 		err := rows.Scan(
 			&task.ID, &task.Title, &task.Description, &task.CreatedAt,
-			&task.UserID, &categoryID, &subcategoryID, &cost,
+			&task.UserID, &categoryID, &subcategoryID, &cost, &costCurrency, &costBase,
 			&addresses, &task.ServiceLocation, // Сканируем в pq.StringArray
 			&task.PeriodType, &startDate, &endDate,
 			&statusCode,
@@ -197,10 +347,7 @@ func (tr *TasksRepository) GetTasks(ctx context.Context, userID int64) ([]*domai
 			task.SubcategoryID = new(int)
 			*task.SubcategoryID = int(subcategoryID.Int64)
 		}
-		if cost.Valid {
-			task.Cost = new(int)
-			*task.Cost = int(cost.Int64)
-		}
+		task.Cost, task.CostBase = taskCost(cost, costCurrency, costBase)
 		if startDate.Valid {
 			task.StartDate = new(time.Time)
 			*task.StartDate = startDate.Time
@@ -210,7 +357,7 @@ func (tr *TasksRepository) GetTasks(ctx context.Context, userID int64) ([]*domai
 			*task.EndDate = endDate.Time
 		}
 		if statusCode.Valid {
-			task.StatusCode = int(statusCode.Int64)
+			task.StatusCode = domain.TaskStatusCode(statusCode.Int64)
 		}
 
 		tasks = append(tasks, &task)
@@ -223,7 +370,7 @@ func (tr *TasksRepository) GetTasks(ctx context.Context, userID int64) ([]*domai
 func (r *TasksRepository) GetTasksByUserID(ctx context.Context, userID int64) ([]domain.Task, error) {
 	query := `
         SELECT t.id, t.title, t.description, t.created_at, t.user_id,
-               t.category_id, t.subcategory_id, t.cost, t.addresses,
+               t.category_id, t.subcategory_id, t.cost, t.cost_currency, t.cost_base, t.addresses,
                t.service_location, t.period_type, t.start_date, t.end_date, t.status_code
         FROM tasks t
         JOIN responses r ON t.id = r.task_id
@@ -238,13 +385,14 @@ func (r *TasksRepository) GetTasksByUserID(ctx context.Context, userID int64) ([
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		var categoryID, subcategoryID, cost sql.NullInt64
+		var categoryID, subcategoryID, cost, costBase sql.NullInt64
+		var costCurrency money.Currency
 		var startDate, endDate sql.NullTime
 		var statusCode sql.NullInt64
 		var addresses pq.StringArray
 
 		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.CreatedAt, &task.UserID,
-			&categoryID, &subcategoryID, &cost, &addresses,
+			&categoryID, &subcategoryID, &cost, &costCurrency, &costBase, &addresses,
 			&task.ServiceLocation, &task.PeriodType, &startDate, &endDate, &statusCode); err != nil {
 			return nil, fmt.Errorf("не удалось сканировать строку: %w", err)
 		}
@@ -261,10 +409,7 @@ func (r *TasksRepository) GetTasksByUserID(ctx context.Context, userID int64) ([
 			*task.SubcategoryID = int(subcategoryID.Int64)
 		}
 
-		if cost.Valid {
-			task.Cost = new(int)
-			*task.Cost = int(cost.Int64)
-		}
+		task.Cost, task.CostBase = taskCost(cost, costCurrency, costBase)
 
 		if startDate.Valid {
 			task.StartDate = new(time.Time)
@@ -277,7 +422,7 @@ func (r *TasksRepository) GetTasksByUserID(ctx context.Context, userID int64) ([
 		}
 
 		if statusCode.Valid {
-			task.StatusCode = int(statusCode.Int64)
+			task.StatusCode = domain.TaskStatusCode(statusCode.Int64)
 		}
 
 		tasks = append(tasks, task)
@@ -294,7 +439,7 @@ func (r *TasksRepository) GetTasksByUserID(ctx context.Context, userID int64) ([
 func (r *TasksRepository) GetTasksUserID(ctx context.Context, userID int64) ([]domain.Task, error) {
 	query := `
         SELECT id, title, description, created_at, user_id, category_id,
-               subcategory_id, cost, addresses, service_location,
+               subcategory_id, cost, cost_currency, cost_base, addresses, service_location,
                period_type, start_date, end_date, status_code
         FROM tasks
         WHERE user_id = $1`
@@ -307,14 +452,15 @@ func (r *TasksRepository) GetTasksUserID(ctx context.Context, userID int64) ([]d
 	var tasks []domain.Task
 	for rows.Next() {
 		var task domain.Task
-		var categoryID, subcategoryID, cost sql.NullInt64
+		var categoryID, subcategoryID, cost, costBase sql.NullInt64
+		var costCurrency money.Currency
 		var startDate, endDate sql.NullTime
 		var statusCode sql.NullInt64
 		var addresses pq.StringArray
 
 		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.CreatedAt,
 			&task.UserID, &categoryID, &subcategoryID,
-			&cost, &addresses, &task.ServiceLocation,
+			&cost, &costCurrency, &costBase, &addresses, &task.ServiceLocation,
 			&task.PeriodType, &startDate, &endDate, &statusCode); err != nil {
 			return nil, fmt.Errorf("не удалось сканировать строку: %w", err)
 		}
@@ -331,10 +477,7 @@ func (r *TasksRepository) GetTasksUserID(ctx context.Context, userID int64) ([]d
 			*task.SubcategoryID = int(subcategoryID.Int64)
 		}
 
-		if cost.Valid {
-			task.Cost = new(int)
-			*task.Cost = int(cost.Int64)
-		}
+		task.Cost, task.CostBase = taskCost(cost, costCurrency, costBase)
 
 		if startDate.Valid {
 			task.StartDate = new(time.Time)
@@ -347,7 +490,7 @@ func (r *TasksRepository) GetTasksUserID(ctx context.Context, userID int64) ([]d
 		}
 
 		if statusCode.Valid {
-			task.StatusCode = int(statusCode.Int64)
+			task.StatusCode = domain.TaskStatusCode(statusCode.Int64)
 		}
 
 		tasks = append(tasks, task)
@@ -462,11 +605,22 @@ func (r *TasksRepository) GetTaskOwner(ctx context.Context, taskID int64) (int64
 }
 
 // GetResponsesByTaskID получает отклики на задачу по ее ID, включая информацию о пользователях.
-func (r *TasksRepository) GetResponsesByTaskID(taskID, userID int64) ([]domain.ResponseWithUser, error) {
+// Отклики отдаются в порядке поступления, с курсорной пагинацией.
+func (r *TasksRepository) GetResponsesByTaskID(taskID, userID int64, page pagination.Request) (pagination.Page[domain.ResponseWithUser], error) {
+	args := []interface{}{taskID, page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil || page.After.Sort != responsesListSort {
+			return pagination.Page[domain.ResponseWithUser]{}, pagination.ErrInvalidCursor
+		}
+		keyset = "AND (r.created_at, r.id) > ($3, $4)"
+		args = append(args, *page.After.Time, page.After.ID)
+	}
+
 	var customerID int64
 	err := r.db.QueryRow(context.Background(), "SELECT user_id FROM tasks WHERE id = $1", taskID).Scan(&customerID)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить заказчика задачи: %w", err)
+		return pagination.Page[domain.ResponseWithUser]{}, fmt.Errorf("не удалось получить заказчика задачи: %w", err)
 	}
 
 	rows, err := r.db.Query(context.Background(),
@@ -475,18 +629,36 @@ func (r *TasksRepository) GetResponsesByTaskID(taskID, userID int64) ([]domain.R
         r.task_id,
         r.user_id,
         r.proposed_price,
+        r.proposed_currency,
         r.response_text,
         r.created_at,
+        r.task_revision,
+        t.revision > r.task_revision,
+        r.negotiation_status,
+        o.author_role,
+        o.price,
+        o.start_date,
+        o.end_date,
         u.first_name,
         u.last_name,
         u.username,
         u.avatar_url
         FROM responses r
         JOIN users u ON r.user_id = u.id
-        WHERE r.task_id = $1`, taskID)
+        JOIN tasks t ON r.task_id = t.id
+        JOIN LATERAL (
+            SELECT author_role, price, start_date, end_date
+            FROM response_offers
+            WHERE response_id = r.id
+            ORDER BY id DESC
+            LIMIT 1
+        ) o ON TRUE
+        WHERE r.task_id = $1 `+keyset+`
+        ORDER BY r.created_at, r.id
+        LIMIT $2`, args...)
 
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить отклики: %w", err)
+		return pagination.Page[domain.ResponseWithUser]{}, fmt.Errorf("не удалось получить отклики: %w", err)
 	}
 	defer rows.Close()
 
@@ -494,13 +666,20 @@ func (r *TasksRepository) GetResponsesByTaskID(taskID, userID int64) ([]domain.R
 	for rows.Next() {
 		var response domain.ResponseWithUser
 		var firstName, lastName, username, avatarURL sql.NullString
+		var offerStart, offerEnd sql.NullTime
 
-		err := rows.Scan(&response.ID, &response.TaskID, &response.UserID, &response.ProposedPrice,
-			&response.ResponseText, &response.CreatedAt, &firstName, &lastName, &username, &avatarURL)
+		err := rows.Scan(&response.ID, &response.TaskID, &response.UserID, &response.ProposedPrice.Amount,
+			&response.ProposedPrice.Currency, &response.ResponseText, &response.CreatedAt, &response.TaskRevision, &response.TermsChanged,
+			&response.NegotiationStatus, &response.LastOfferBy, &response.CurrentTerms.Price, &offerStart, &offerEnd,
+			&firstName, &lastName, &username, &avatarURL)
 		if err != nil {
-			return nil, fmt.Errorf("не удалось сканировать строку: %w", err)
+			return pagination.Page[domain.ResponseWithUser]{}, fmt.Errorf("не удалось сканировать строку: %w", err)
 		}
 
+		response.CurrentTerms.Currency = response.ProposedPrice.Currency
+		response.CurrentTerms.StartDate = nullTimePtr(offerStart)
+		response.CurrentTerms.EndDate = nullTimePtr(offerEnd)
+
 		if firstName.Valid {
 			response.UserInfo.FirstName = firstName.String
 		}
@@ -527,29 +706,32 @@ func (r *TasksRepository) GetResponsesByTaskID(taskID, userID int64) ([]domain.R
         status_id = (SELECT id FROM contract_statuses WHERE status = 'Active')
         )`, response.TaskID, response.UserID, customerID).Scan(&hasContract)
 		if err != nil {
-			return nil, fmt.Errorf("не удалось проверить существование контракта: %w", err)
+			return pagination.Page[domain.ResponseWithUser]{}, fmt.Errorf("не удалось проверить существование контракта: %w", err)
 		}
 		response.HasContract = hasContract
 		responses = append(responses, response)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка при итерации по строкам откликов: %w", err)
+		return pagination.Page[domain.ResponseWithUser]{}, fmt.Errorf("ошибка при итерации по строкам откликов: %w", err)
 	}
 
-	return responses, nil
+	return pagination.NewPage(responses, page, func(resp domain.ResponseWithUser) pagination.Cursor {
+		return pagination.TimeCursor(responsesListSort, resp.CreatedAt, resp.ID)
+	}), nil
 }
 
 // GetResponseByTaskAndUser получает отклик по ID задачи и ID пользователя.
 func (r *TasksRepository) GetResponseByTaskAndUser(ctx context.Context, taskID int64, userID int64) (domain.ProposedResponse, error) {
 	var response domain.ProposedResponse
 
-	query := `SELECT id, task_id, user_id, proposed_price, response_text, created_at
+	query := `SELECT id, task_id, user_id, proposed_price, proposed_currency, response_text, created_at, task_revision, negotiation_status
               FROM responses
               WHERE task_id = $1 AND user_id = $2`
 
 	err := r.db.QueryRow(ctx, query, taskID, userID).Scan(&response.ID, &response.TaskID, &response.UserID,
-		&response.ProposedPrice, &response.ResponseText, &response.CreatedAt)
+		&response.ProposedPrice.Amount, &response.ProposedPrice.Currency, &response.ResponseText, &response.CreatedAt,
+		&response.TaskRevision, &response.NegotiationStatus)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -565,9 +747,11 @@ func (r *TasksRepository) GetResponseByTaskAndUser(ctx context.Context, taskID i
 func (r *TasksRepository) GetContractByDetails(ctx context.Context, taskID int64, executorID int64, customerID int64) (*domain.Contract, error) {
 	var contract domain.Contract
 	var startDate, endDate sql.NullTime
+	var price sql.NullInt64
+	var priceCurrency money.Currency
 
 	err := r.db.QueryRow(ctx, `
-        SELECT id, task_id, executor_id, customer_id, created_at, updated_at, is_active, status_id, start_date, end_date
+        SELECT id, task_id, executor_id, customer_id, created_at, updated_at, is_active, status_id, start_date, end_date, price, price_currency
         FROM contracts
         WHERE task_id = $1 AND executor_id = $2 AND customer_id = $3`, taskID, executorID, customerID).Scan(
 		&contract.ID,
@@ -580,6 +764,8 @@ func (r *TasksRepository) GetContractByDetails(ctx context.Context, taskID int64
 		&contract.StatusID,
 		&startDate,
 		&endDate,
+		&price,
+		&priceCurrency,
 	)
 
 	if err != nil {
@@ -595,6 +781,7 @@ func (r *TasksRepository) GetContractByDetails(ctx context.Context, taskID int64
 	if endDate.Valid {
 		contract.EndDate = &endDate.Time
 	}
+	contract.Price = contractPrice(price, priceCurrency)
 
 	return &contract, nil
 }
@@ -657,31 +844,19 @@ func (r *TasksRepository) GetActiveStatusID(ctx context.Context) (int64, error)
 	return statusID, nil
 }
 
-// FetchUserReviewsStatsFromDB получает статистику отзывов пользователя.
-func (r *TasksRepository) FetchUserReviewsStatsFromDB(ctx context.Context, userID string) (domain.ReviewStats, error) {
-	query := `
-      SELECT
-        COUNT(*) AS total_reviews,
-        COALESCE(AVG(rating), 0) AS average_rating
-      FROM reviews
-      WHERE user_id = $1
-    `
-
-	var stats domain.ReviewStats
-	err := r.db.QueryRow(ctx, query, userID).Scan(&stats.TotalReviews, &stats.AverageRating)
-	if err != nil {
-		return domain.ReviewStats{}, err
+// FetchTasksFromDB получает ленту открытых задач, новые первыми, с курсорной пагинацией.
+func (r *TasksRepository) FetchTasksFromDB(ctx context.Context, page pagination.Request) (pagination.Page[domain.Task], error) {
+	args := []interface{}{int(domain.StatusActive), page.FetchLimit()}
+	keyset := ""
+	if page.After != nil {
+		if page.After.Time == nil || page.After.Sort != tasksFeedSort {
+			return pagination.Page[domain.Task]{}, pagination.ErrInvalidCursor
+		}
+		keyset = "AND (created_at, id) < ($3, $4)"
+		args = append(args, *page.After.Time, page.After.ID)
 	}
 
-	return stats, nil
-}
-
-// FetchTasksFromDB получает список задач с пагинацией.
-func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
-	limit := 5
-	offset := page * limit
-
-	rows, err := r.db.Query(context.Background(), `
+	rows, err := r.db.Query(ctx, `
     SELECT
         id,
         title,
@@ -691,6 +866,8 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
         category_id,
         subcategory_id,
         cost,
+        cost_currency,
+        cost_base,
         addresses,
         service_location,
         period_type,
@@ -699,10 +876,12 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
         status_code
     FROM
         tasks
-    LIMIT $1 OFFSET $2`, limit, offset)
+    WHERE status_code = $1 `+keyset+`
+    ORDER BY created_at DESC, id DESC
+    LIMIT $2`, args...)
 
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при выполнении запроса на получение задач: %w", err)
+		return pagination.Page[domain.Task]{}, fmt.Errorf("ошибка при выполнении запроса на получение задач: %w", err)
 	}
 	defer rows.Close()
 
@@ -710,7 +889,8 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
 	for rows.Next() {
 		var task domain.Task
 		var startDate, endDate sql.NullTime
-		var categoryID, subcategoryID, cost sql.NullInt64
+		var categoryID, subcategoryID, cost, costBase sql.NullInt64
+		var costCurrency money.Currency
 		var statusCode sql.NullInt64
 		var addresses pq.StringArray
 
@@ -722,7 +902,7 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
 			&task.UserID,
 			&categoryID,
 			&subcategoryID,
-			&cost,
+			&cost, &costCurrency, &costBase,
 			&addresses,
 			&task.ServiceLocation,
 			&task.PeriodType,
@@ -731,7 +911,7 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
 			&statusCode,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании задачи: %w", err)
+			return pagination.Page[domain.Task]{}, fmt.Errorf("ошибка при сканировании задачи: %w", err)
 		}
 
 		task.Addresses = []string(addresses) // Преобразуем pq.StringArray обратно в []string
@@ -744,10 +924,7 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
 			task.SubcategoryID = new(int)
 			*task.SubcategoryID = int(subcategoryID.Int64)
 		}
-		if cost.Valid {
-			task.Cost = new(int)
-			*task.Cost = int(cost.Int64)
-		}
+		task.Cost, task.CostBase = taskCost(cost, costCurrency, costBase)
 		if startDate.Valid {
 			task.StartDate = new(time.Time)
 			*task.StartDate = startDate.Time
@@ -757,13 +934,19 @@ func (r *TasksRepository) FetchTasksFromDB(page int) ([]domain.Task, error) {
 			*task.EndDate = endDate.Time
 		}
 		if statusCode.Valid {
-			task.StatusCode = int(statusCode.Int64)
+			task.StatusCode = domain.TaskStatusCode(statusCode.Int64)
 		}
 
 		tasks = append(tasks, task)
 	}
 
-	return tasks, nil
+	if err := rows.Err(); err != nil {
+		return pagination.Page[domain.Task]{}, fmt.Errorf("ошибка при чтении задач: %w", err)
+	}
+
+	return pagination.NewPage(tasks, page, func(t domain.Task) pagination.Cursor {
+		return pagination.TimeCursor(tasksFeedSort, t.CreatedAt, t.ID)
+	}), nil
 }
 
 // FetchSubcategoriesByCategoryIDFromDB получает подкатегории по ID категории.
@@ -791,33 +974,6 @@ func (r *TasksRepository) FetchSubcategoriesByCategoryIDFromDB(categoryID string
 	return subcategories, nil
 }
 
-// FetchReviewsByUserFromDB получает отзывы пользователя по его ID.
-func (r *TasksRepository) FetchReviewsByUserFromDB(userID string) ([]domain.Review, error) {
-	query := "SELECT id, contract_id, user_id, rating, comment, created_at FROM reviews WHERE user_id = $1;"
-
-	rows, err := r.db.Query(context.Background(), query, userID)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при выполнении запроса на получение отзывов пользователя: %w", err)
-	}
-	defer rows.Close()
-
-	var reviews []domain.Review
-
-	for rows.Next() {
-		var review domain.Review
-		if err := rows.Scan(&review.ID, &review.ContractID, &review.UserID, &review.Rating, &review.Comment, &review.CreatedAt); err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании отзыва: %w", err)
-		}
-		reviews = append(reviews, review)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка при обработке строк результата: %w", err)
-	}
-
-	return reviews, nil
-}
-
 // FetchCategoriesFromDB получает все категории.
 func (r *TasksRepository) FetchCategoriesFromDB() ([]domain.Category, error) {
 	rows, err := r.db.Query(context.Background(), "SELECT id, name FROM categories")
@@ -886,6 +1042,11 @@ func (r *TasksRepository) DeleteTask(ctx context.Context, userID int64, taskID i
 
 	result, err := r.db.Exec(ctx, query, taskID, userID)
 	if err != nil {
+		// Документы, выпущенные между проверкой и удалением, не дают удалить контракт задачи.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.TableName == "contract_documents" {
+			return domain.ErrTaskHasDocuments
+		}
 		return fmt.Errorf("ошибка при выполнении запроса на удаление задачи: %w", err)
 	}
 
@@ -898,6 +1059,21 @@ func (r *TasksRepository) DeleteTask(ctx context.Context, userID int64, taskID i
 	return nil
 }
 
+// HasContractDocuments проверяет, выпущены ли документы по контрактам задачи.
+func (r *TasksRepository) HasContractDocuments(ctx context.Context, taskID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM contract_documents d
+            JOIN contracts c ON c.id = d.contract_id
+            WHERE c.task_id = $1
+        )`, taskID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить документы по контрактам задачи с ID %d: %w", taskID, err)
+	}
+	return exists, nil
+}
+
 // DeleteResponse удаляет отклик из базы данных.
 func (r *TasksRepository) DeleteResponse(ctx context.Context, responseID int64, userID int64) error {
 	query := `DELETE FROM responses WHERE id = $1 AND user_id = $2`
@@ -916,27 +1092,76 @@ func (r *TasksRepository) DeleteResponse(ctx context.Context, responseID int64,
 	return nil
 }
 
-// CreateReport создает отчет в базе данных.
-func (r *TasksRepository) CreateReport(ctx context.Context, contractID, taskID int64, executorComments string, executionStatus bool) error {
-	query := `INSERT INTO reports (contract_id, task_id, executor_comments, execution_status) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(ctx, query, contractID, taskID, executorComments, executionStatus)
+// CreateReport создает отчет по этапу контракта в базе данных.
+func (r *TasksRepository) CreateReport(ctx context.Context, contractID, taskID, milestoneID int64, executorComments string, executionStatus bool) error {
+	query := `INSERT INTO reports (contract_id, task_id, milestone_id, executor_comments, execution_status) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(ctx, query, contractID, taskID, milestoneID, executorComments, executionStatus)
 	return err
 }
 
-// CreateContractInDB создает контракт в базе данных.
-func (r *TasksRepository) CreateContractInDB(ctx context.Context, taskID, executorID, customerID int64, createdAt time.Time, statusID int64) (int64, error) {
+// StartContract создает контракт на согласованных условиях вместе с планом этапов и переводит задачу
+// в работу в одной транзакции. Если дата начала не согласована, работы начинаются с момента заключения контракта.
+func (r *TasksRepository) StartContract(ctx context.Context, c domain.NewContract) (int64, error) {
+	startDate := c.CreatedAt
+	if c.Terms.StartDate != nil {
+		startDate = *c.Terms.StartDate
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var contractID int64
-	err := r.db.QueryRow(ctx,
-		`INSERT INTO contracts (task_id, executor_id, customer_id, created_at, updated_at, is_active, status_id, start_date) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
-		taskID, executorID, customerID, createdAt, createdAt, true, statusID, createdAt).Scan(&contractID)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO contracts (task_id, executor_id, customer_id, created_at, updated_at, is_active, status_id, start_date, end_date, price, price_currency) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		c.TaskID, c.ExecutorID, c.CustomerID, c.CreatedAt, c.CreatedAt, true, c.StatusID, startDate, c.Terms.EndDate, c.Terms.Price, c.Terms.Currency).Scan(&contractID)
 
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании контракта в базе данных: %w", err)
 	}
 
+	if err := insertMilestones(ctx, tx, contractID, c.Milestones); err != nil {
+		return 0, err
+	}
+
+	err = updateTaskStatus(ctx, tx, domain.TaskStatusChange{
+		TaskID:     c.TaskID,
+		FromStatus: domain.StatusActive,
+		ToStatus:   domain.StatusInProgress,
+		ChangedBy:  c.CustomerID,
+		Reason:     c.StartReason(contractID),
+		CreatedAt:  c.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать создание контракта: %w", err)
+	}
+
 	return contractID, nil
 }
 
+// insertMilestones сохраняет план этапов контракта в рамках транзакции.
+func insertMilestones(ctx context.Context, tx pgx.Tx, contractID int64, milestones []domain.MilestoneRequest) error {
+	batch := &pgx.Batch{}
+	for i, m := range milestones {
+		batch.Queue(`INSERT INTO contract_milestones (contract_id, position, title, amount, due_date) VALUES ($1, $2, $3, $4, $5)`,
+			contractID, i+1, m.Title, m.Amount, m.DueDate)
+	}
+	br := tx.SendBatch(ctx, batch)
+	for range milestones {
+		if _, err := br.Exec(); err != nil {
+			br.Close()
+			return fmt.Errorf("не удалось сохранить этапы контракта с ID %d: %w", contractID, err)
+		}
+	}
+	return br.Close()
+}
+
 // CountTaskViews считает количество просмотров задачи.
 func (r *TasksRepository) CountTaskViews(ctx context.Context, taskID int64) (int64, error) {
 	query := `SELECT COUNT(*) FROM task_views WHERE task_id = $1`
@@ -995,24 +1220,131 @@ func (r *TasksRepository) CheckTaskOwnership(ctx context.Context, taskID int64,
 	return count > 0, nil
 }
 
-// CancelTask отменяет задачу.
-func (r *TasksRepository) CancelTask(ctx context.Context, taskID int64) error {
+// GetTaskStatus получает текущий статус задачи.
+func (r *TasksRepository) GetTaskStatus(ctx context.Context, taskID int64) (domain.TaskStatusCode, error) {
+	var statusCode int
+	err := r.db.QueryRow(ctx, `SELECT status_code FROM tasks WHERE id = $1`, taskID).Scan(&statusCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("задача с ID %d не найдена", taskID)
+		}
+		return 0, fmt.Errorf("не удалось получить статус задачи с ID %d: %w", taskID, err)
+	}
+	return domain.TaskStatusCode(statusCode), nil
+}
+
+// GetTransitionGuards собирает сведения о контракте и отчёте, нужные для проверки перехода статуса.
+func (r *TasksRepository) GetTransitionGuards(ctx context.Context, taskID int64) (domain.TransitionGuards, error) {
 	query := `
-        UPDATE tasks
-        SET status_code = 108
-        WHERE id = $1
+        SELECT
+            EXISTS (SELECT 1 FROM contracts WHERE task_id = $1 AND is_active = TRUE),
+            EXISTS (
+                SELECT 1 FROM reports rp
+                JOIN contracts c ON c.id = rp.contract_id
+                WHERE c.task_id = $1 AND c.is_active = TRUE
+            ),
+            EXISTS (
+                SELECT 1 FROM contracts c
+                WHERE c.task_id = $1 AND c.is_active = TRUE
+                  AND EXISTS (SELECT 1 FROM contract_milestones m WHERE m.contract_id = c.id)
+                  AND NOT EXISTS (
+                      SELECT 1 FROM contract_milestones m
+                      WHERE m.contract_id = c.id
+                        AND NOT EXISTS (SELECT 1 FROM reports rp WHERE rp.milestone_id = m.id AND rp.customer_confirmation = TRUE)
+                  )
+            )
     `
 
-	_, err := r.db.Exec(ctx, query, taskID)
+	var guards domain.TransitionGuards
+	err := r.db.QueryRow(ctx, query, taskID).Scan(&guards.HasActiveContract, &guards.HasReport, &guards.ReportConfirmed)
+	if err != nil {
+		return domain.TransitionGuards{}, fmt.Errorf("не удалось проверить контракт и отчёт задачи с ID %d: %w", taskID, err)
+	}
+	return guards, nil
+}
+
+// UpdateTaskStatus меняет статус задачи и записывает изменение в историю в одной транзакции.
+// Если статус задачи уже не равен change.FromStatus, возвращает domain.ErrStatusConflict.
+func (r *TasksRepository) UpdateTaskStatus(ctx context.Context, change domain.TaskStatusChange) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("не удалось отменить задачу с ID %d: %w", taskID, err)
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateTaskStatus(ctx, tx, change); err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
 
+// updateTaskStatus меняет статус задачи и пишет историю в рамках транзакции.
+func updateTaskStatus(ctx context.Context, tx pgx.Tx, change domain.TaskStatusChange) error {
+	tag, err := tx.Exec(ctx, `
+        UPDATE tasks
+        SET status_code = $1
+        WHERE id = $2 AND status_code = $3`,
+		int(change.ToStatus), change.TaskID, int(change.FromStatus))
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус задачи с ID %d: %w", change.TaskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrStatusConflict
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO task_status_history (task_id, from_status, to_status, changed_by, reason, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`,
+		change.TaskID, int(change.FromStatus), int(change.ToStatus), change.ChangedBy, change.Reason, change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось записать историю статуса задачи с ID %d: %w", change.TaskID, err)
+	}
 	return nil
 }
 
+// GetTaskStatusHistory получает историю смены статусов задачи в хронологическом порядке.
+func (r *TasksRepository) GetTaskStatusHistory(ctx context.Context, taskID int64) ([]domain.TaskStatusChange, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, task_id, from_status, to_status, changed_by, reason, created_at
+        FROM task_status_history
+        WHERE task_id = $1
+        ORDER BY created_at, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории статусов задачи: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.TaskStatusChange{}
+	for rows.Next() {
+		var change domain.TaskStatusChange
+		var from, to int
+		if err := rows.Scan(&change.ID, &change.TaskID, &from, &to, &change.ChangedBy, &change.Reason, &change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании истории статусов: %w", err)
+		}
+		change.FromStatus = domain.TaskStatusCode(from)
+		change.ToStatus = domain.TaskStatusCode(to)
+		history = append(history, change)
+	}
+
+	return history, rows.Err()
+}
+
+// IsTaskParticipant проверяет, является ли пользователь заказчиком задачи или исполнителем по её контракту.
+func (r *TasksRepository) IsTaskParticipant(ctx context.Context, taskID int64, userID int64) (bool, error) {
+	query := `
+        SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND user_id = $2)
+            OR EXISTS (SELECT 1 FROM contracts WHERE task_id = $1 AND executor_id = $2)
+    `
+
+	var ok bool
+	if err := r.db.QueryRow(ctx, query, taskID, userID).Scan(&ok); err != nil {
+		return false, fmt.Errorf("не удалось проверить участие пользователя в задаче с ID %d: %w", taskID, err)
+	}
+	return ok, nil
+}
+
 // AddResponse добавляет отклик на задачу.
-func (r *TasksRepository) AddResponse(ctx context.Context, taskID int64, userID int64, proposedPrice int, responseText string) (domain.ProposedResponse, error) {
+func (r *TasksRepository) AddResponse(ctx context.Context, taskID int64, userID int64, proposedPrice money.Money, responseText string) (domain.ProposedResponse, error) {
 	// Эта проверка теперь должна быть в сервисе
 	// exists, err := r.ResponseExists(ctx, taskID, userID)
 	// if err != nil {
@@ -1025,11 +1357,12 @@ func (r *TasksRepository) AddResponse(ctx context.Context, taskID int64, userID
 
 	var response domain.ProposedResponse
 
-	query := `INSERT INTO responses (task_id, user_id, proposed_price, response_text)
-        VALUES ($1, $2, $3, $4) RETURNING id, task_id, user_id, proposed_price, response_text, created_at`
+	query := `INSERT INTO responses (task_id, user_id, proposed_price, proposed_currency, response_text)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id, task_id, user_id, proposed_price, proposed_currency, response_text, created_at`
 
-	err := r.db.QueryRow(ctx, query, taskID, userID, proposedPrice, responseText).
-		Scan(&response.ID, &response.TaskID, &response.UserID, &response.ProposedPrice, &response.ResponseText, &response.CreatedAt)
+	err := r.db.QueryRow(ctx, query, taskID, userID, proposedPrice.Amount, proposedPrice.Currency, responseText).
+		Scan(&response.ID, &response.TaskID, &response.UserID, &response.ProposedPrice.Amount, &response.ProposedPrice.Currency,
+			&response.ResponseText, &response.CreatedAt)
 	if err != nil {
 		return domain.ProposedResponse{}, fmt.Errorf("ошибка при добавлении отклика в базу данных: %w", err)
 	}
@@ -1070,20 +1403,6 @@ func (r *TasksRepository) GetContractExists(ctx context.Context, taskID int64, e
 	return exists, nil
 }
 
-// InsertReviewInDB вставляет новый отзыв в базу данных.
-func (r *TasksRepository) InsertReviewInDB(ctx context.Context, review domain.Review) (int, error) {
-	query := `
-        INSERT INTO reviews (contract_id, user_id, rating, comment) VALUES ($1, $2, $3, $4) RETURNING id`
-
-	var reviewID int
-	err := r.db.QueryRow(ctx, query, review.ContractID, review.UserID, review.Rating, review.Comment).Scan(&reviewID)
-	if err != nil {
-		return 0, fmt.Errorf("не удалось создать отзыв для контракта с ID %d и пользователя с ID %d: %w", review.ContractID, review.UserID, err)
-	}
-
-	return reviewID, nil
-}
-
 // ResponseExists проверяет, существует ли отклик пользователя на конкретную задачу.
 func (r *TasksRepository) ResponseExists(ctx context.Context, taskID int64, userID int64) (bool, error) {
 	var exists bool
@@ -1118,10 +1437,12 @@ func (r *TasksRepository) TotalResponses(taskID int64) (int, error) {
 
 // UpdateReport обновляет отчет в базе данных.
 func (r *TasksRepository) UpdateReport(ctx context.Context, reportID int64, customerFeedback string, customerConfirmation *bool) error {
+	// Решение записывается только поверх отчёта, который ещё ждёт решения: два параллельных
+	// подтверждения не освободят оплату этапа дважды.
 	query := `
         UPDATE reports
-        SET customer_feedback = $1, customer_confirmation = $2, updated_at = $3
-        WHERE id = $4;`
+        SET customer_feedback = $1, customer_confirmation = COALESCE($2, customer_confirmation), updated_at = $3
+        WHERE id = $4 AND ($2::boolean IS NULL OR customer_confirmation IS NULL);`
 
 	// pgx QueryRow/Exec не принимает nil для *bool напрямую. Используем sql.NullBool.
 	var nullConfirmation sql.NullBool
@@ -1131,24 +1452,142 @@ func (r *TasksRepository) UpdateReport(ctx context.Context, reportID int64, cust
 		nullConfirmation = sql.NullBool{Valid: false}
 	}
 
-	_, err := r.db.Exec(ctx, query, customerFeedback, nullConfirmation, time.Now(), reportID)
+	tag, err := r.db.Exec(ctx, query, customerFeedback, nullConfirmation, time.Now(), reportID)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении отчета: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReportNotSubmitted
+	}
 
 	return nil
 }
 
+// UpdateTask сохраняет текущую редакцию задачи в task_revisions и применяет новые условия.
+// Возвращает номер новой редакции.
+func (r *TasksRepository) UpdateTask(ctx context.Context, taskID, userID int64, req domain.UpdateTaskRequest, changedAt time.Time) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Статус проверяется под блокировкой строки: параллельное заключение контракта или отмена
+	// дождутся конца транзакции, а правка задачи, которая уже не открыта, не пройдёт.
+	var status int
+	err = tx.QueryRow(ctx, `SELECT status_code FROM tasks WHERE id = $1 FOR UPDATE`, taskID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("задача с ID %d не найдена", taskID)
+		}
+		return 0, fmt.Errorf("не удалось получить статус задачи с ID %d: %w", taskID, err)
+	}
+	if domain.TaskStatusCode(status) != domain.StatusActive {
+		return 0, domain.ErrStatusConflict
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO task_revisions (task_id, revision, title, description, cost, cost_currency, addresses, start_date, end_date, changed_by, replaced_at)
+        SELECT id, revision, title, description, cost, cost_currency, addresses, start_date, end_date, $2, $3
+        FROM tasks
+        WHERE id = $1`, taskID, userID, changedAt)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить редакцию задачи с ID %d: %w", taskID, err)
+	}
+
+	var revision int
+	err = tx.QueryRow(ctx, `
+        UPDATE tasks
+        SET title = $1, description = $2, cost = $3, cost_currency = $4, cost_base = $5, addresses = $6, start_date = $7, end_date = $8,
+            revision = revision + 1
+        WHERE id = $9
+        RETURNING revision`,
+		req.Title, req.Description, costAmount(req.Cost), costCurrency(req.Cost), req.CostBase, pq.Array(req.Addresses), req.StartDate, req.EndDate, taskID).Scan(&revision)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось обновить задачу с ID %d: %w", taskID, err)
+	}
+
+	if err := replaceTaskLocations(ctx, tx, taskID, req.Locations); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать изменение задачи с ID %d: %w", taskID, err)
+	}
+	return revision, nil
+}
+
+// GetTaskRevisions получает предыдущие редакции задачи, от старых к новым.
+func (r *TasksRepository) GetTaskRevisions(ctx context.Context, taskID int64) ([]domain.TaskRevision, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, task_id, revision, title, description, cost, cost_currency, addresses, start_date, end_date, changed_by, replaced_at
+        FROM task_revisions
+        WHERE task_id = $1
+        ORDER BY revision`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении редакций задачи: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.TaskRevision{}
+	for rows.Next() {
+		var rev domain.TaskRevision
+		var cost sql.NullInt64
+		var costCurrency money.Currency
+		var startDate, endDate sql.NullTime
+		var addresses pq.StringArray
+
+		err := rows.Scan(&rev.ID, &rev.TaskID, &rev.Revision, &rev.Title, &rev.Description, &cost, &costCurrency, &addresses,
+			&startDate, &endDate, &rev.ChangedBy, &rev.ReplacedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании редакции задачи: %w", err)
+		}
+
+		rev.Addresses = []string(addresses)
+		rev.Cost, _ = taskCost(cost, costCurrency, sql.NullInt64{})
+		if startDate.Valid {
+			rev.StartDate = &startDate.Time
+		}
+		if endDate.Valid {
+			rev.EndDate = &endDate.Time
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetResponderIDs получает ID пользователей, откликнувшихся на задачу.
+func (r *TasksRepository) GetResponderIDs(ctx context.Context, taskID int64) ([]int64, error) {
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT user_id FROM responses WHERE task_id = $1`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении откликнувшихся на задачу: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ID пользователя: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 // GetTaskByID получает задачу по ID.
 func (r *TasksRepository) GetTaskByID(ctx context.Context, id int64) (*domain.Task, error) {
 	var task domain.Task
 
 	query := `SELECT id, title, description, created_at, user_id, category_id, subcategory_id,
-                cost, addresses, service_location, period_type, start_date, end_date, status_code
+                cost, cost_currency, cost_base, addresses, service_location, period_type, start_date, end_date, status_code, revision
               FROM tasks WHERE id = $1`
 
 	var startDate, endDate sql.NullTime
-	var categoryID, subcategoryID, cost sql.NullInt64
+	var categoryID, subcategoryID, cost, costBase sql.NullInt64
+	var costCurrency money.Currency
 	var statusCode sql.NullInt64
 	var addresses pq.StringArray // Использование pq.StringArray
 
@@ -1160,13 +1599,14 @@ func (r *TasksRepository) GetTaskByID(ctx context.Context, id int64) (*domain.Ta
 		&task.UserID,
 		&categoryID,
 		&subcategoryID,
-		&cost,
+		&cost, &costCurrency, &costBase,
 		&addresses, // Сканируем в pq.StringArray
 		&task.ServiceLocation,
 		&task.PeriodType,
 		&startDate,
 		&endDate,
 		&statusCode,
+		&task.Revision,
 	)
 
 	if err != nil {
@@ -1186,10 +1626,7 @@ func (r *TasksRepository) GetTaskByID(ctx context.Context, id int64) (*domain.Ta
 		task.SubcategoryID = new(int)
 		*task.SubcategoryID = int(subcategoryID.Int64)
 	}
-	if cost.Valid {
-		task.Cost = new(int)
-		*task.Cost = int(cost.Int64)
-	}
+	task.Cost, task.CostBase = taskCost(cost, costCurrency, costBase)
 	if startDate.Valid {
 		task.StartDate = new(time.Time)
 		*task.StartDate = startDate.Time
@@ -1199,119 +1636,570 @@ func (r *TasksRepository) GetTaskByID(ctx context.Context, id int64) (*domain.Ta
 		*task.EndDate = endDate.Time
 	}
 	if statusCode.Valid {
-		task.StatusCode = int(statusCode.Int64)
+		task.StatusCode = domain.TaskStatusCode(statusCode.Int64)
+	}
+
+	if task.Locations, err = r.getTaskLocations(ctx, task.ID); err != nil {
+		return nil, err
 	}
 
 	return &task, nil
 }
 
-// SearchTasks ищет задачи по строке запроса и фильтрам.
-func (r *TasksRepository) SearchTasks(
-	ctx context.Context,
-	queryStr string,
-	filterParams map[string]interface{},
-	limit, offset int,
-) ([]domain.Task, error) {
-	// Базовый запрос
-	sqlQuery := ` SELECT id, title, description, created_at, user_id, status_code, start_date, end_date, cost, addresses, service_location, period_type, category_id, subcategory_id FROM tasks WHERE to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, '')) @@ plainto_tsquery($1)`
-
-	args := []interface{}{queryStr}
-	argIndex := 2
-
-	// Добавляем фильтры
-	for k, v := range filterParams {
-		fieldName := strings.ToLower(k)
-		switch v := v.(type) {
-		case string:
-			sqlQuery += fmt.Sprintf(" AND LOWER(%s) LIKE $%d", fieldName, argIndex)
-			args = append(args, "%"+strings.ToLower(v)+"%")
-			argIndex++
-		case time.Time:
-			sqlQuery += fmt.Sprintf(" AND %s >= $%d", fieldName, argIndex)
-			args = append(args, v) // time.Time будет автоматически преобразована pgx
-			argIndex++
-		case int: // Принимаем int
-			sqlQuery += fmt.Sprintf(" AND %s = $%d", fieldName, argIndex)
-			args = append(args, v)
-			argIndex++
-		case float64: // если есть float64
-			sqlQuery += fmt.Sprintf(" AND %s = $%d", fieldName, argIndex)
-			args = append(args, v)
-			argIndex++
-		default:
-			// Пропускаем неизвестные типы фильтров
-			continue
+// CountSavedSearches получает количество сохранённых поисков пользователя.
+func (r *TasksRepository) CountSavedSearches(ctx context.Context, userID int64) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчёте сохранённых поисков: %w", err)
+	}
+	return count, nil
+}
+
+// InsertSavedSearch сохраняет поиск. Фильтры хранятся строкой запроса, как в GET /tasks/search.
+func (r *TasksRepository) InsertSavedSearch(ctx context.Context, search domain.SavedSearch) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO saved_searches (user_id, name, filters, frequency, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`,
+		search.UserID, search.Name, search.Filters.Encode(), string(search.Frequency), search.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить поиск пользователя с ID %d: %w", search.UserID, err)
+	}
+	return id, nil
+}
+
+const savedSearchColumns = `id, user_id, name, filters, frequency, created_at, last_notified_at`
+
+// scanSavedSearch читает строку с колонками savedSearchColumns.
+func scanSavedSearch(row pgx.Row) (domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	var filters, frequency string
+	var lastNotifiedAt sql.NullTime
+
+	if err := row.Scan(&search.ID, &search.UserID, &search.Name, &filters, &frequency, &search.CreatedAt, &lastNotifiedAt); err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	values, err := url.ParseQuery(filters)
+	if err != nil {
+		return domain.SavedSearch{}, fmt.Errorf("повреждены фильтры сохранённого поиска с ID %d: %w", search.ID, err)
+	}
+	search.Filters = values
+	search.Frequency = domain.AlertFrequency(frequency)
+	if lastNotifiedAt.Valid {
+		search.LastNotifiedAt = &lastNotifiedAt.Time
+	}
+	return search, nil
+}
+
+// querySavedSearches выполняет запрос, возвращающий колонки savedSearchColumns.
+func (r *TasksRepository) querySavedSearches(ctx context.Context, query string, args ...interface{}) ([]domain.SavedSearch, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сохранённых поисков: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []domain.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании сохранённого поиска: %w", err)
 		}
+		searches = append(searches, search)
 	}
+	return searches, rows.Err()
+}
 
-	sqlQuery += fmt.Sprintf("\nORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+// ListSavedSearchesByUser получает сохранённые поиски пользователя в порядке создания.
+func (r *TasksRepository) ListSavedSearchesByUser(ctx context.Context, userID int64) ([]domain.SavedSearch, error) {
+	return r.querySavedSearches(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE user_id = $1 ORDER BY id`, userID)
+}
+
+// ListSavedSearchesExceptUser получает сохранённые поиски всех пользователей, кроме указанного.
+func (r *TasksRepository) ListSavedSearchesExceptUser(ctx context.Context, userID int64) ([]domain.SavedSearch, error) {
+	return r.querySavedSearches(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE user_id <> $1`, userID)
+}
 
-	rows, err := r.db.Query(ctx, sqlQuery, args...)
+// GetSavedSearch получает сохранённый поиск пользователя.
+func (r *TasksRepository) GetSavedSearch(ctx context.Context, searchID, userID int64) (*domain.SavedSearch, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = $1 AND user_id = $2`, searchID, userID)
+	search, err := scanSavedSearch(row)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSavedSearchNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении сохранённого поиска: %w", err)
+	}
+	return &search, nil
+}
+
+// UpdateSavedSearch изменяет сохранённый поиск. Возвращает false, если поиск не найден.
+func (r *TasksRepository) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE saved_searches
+        SET name = $1, filters = $2, frequency = $3
+        WHERE id = $4 AND user_id = $5`,
+		search.Name, search.Filters.Encode(), string(search.Frequency), search.ID, search.UserID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при изменении сохранённого поиска: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteSavedSearch удаляет сохранённый поиск. Возвращает false, если поиск не найден.
+func (r *TasksRepository) DeleteSavedSearch(ctx context.Context, searchID, userID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, searchID, userID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении сохранённого поиска: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// TaskMatchesText проверяет задачу полнотекстовым запросом с той же морфологией, что и SearchTasks.
+func (r *TasksRepository) TaskMatchesText(ctx context.Context, taskID int64, text string) (bool, error) {
+	var matches bool
+	err := r.db.QueryRow(ctx, `
+        SELECT to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, '')) @@ plainto_tsquery('russian', $2)
+        FROM tasks
+        WHERE id = $1`, taskID, text).Scan(&matches)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке текста задачи с ID %d: %w", taskID, err)
+	}
+	return matches, nil
+}
+
+// InsertSavedSearchMatch запоминает, что задача подошла под сохранённый поиск.
+// notified отмечает совпадение уже отправленным (мгновенные оповещения).
+func (r *TasksRepository) InsertSavedSearchMatch(ctx context.Context, searchID, taskID int64, matchedAt time.Time, notified bool) error {
+	var notifiedAt *time.Time
+	if notified {
+		notifiedAt = &matchedAt
+	}
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO saved_search_matches (search_id, task_id, matched_at, notified_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (search_id, task_id) DO NOTHING`, searchID, taskID, matchedAt, notifiedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении совпадения поиска %d с задачей %d: %w", searchID, taskID, err)
+	}
+	return nil
+}
+
+// ListPendingDigests получает неотправленные совпадения поисков со сводками, сгруппированные по поиску.
+// Задачи, закрытые или удалённые до отправки сводки, в неё не попадают.
+func (r *TasksRepository) ListPendingDigests(ctx context.Context) ([]domain.SavedSearchDigest, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT s.id, s.user_id, s.name, s.filters, s.frequency, s.created_at, s.last_notified_at, t.id, t.title
+        FROM saved_searches s
+        JOIN saved_search_matches m ON m.search_id = s.id AND m.notified_at IS NULL
+        JOIN tasks t ON t.id = m.task_id AND t.status_code = $1
+        WHERE s.frequency <> $2
+        ORDER BY s.id, m.matched_at`, int(domain.StatusActive), string(domain.AlertInstant))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении накопленных совпадений: %w", err)
 	}
 	defer rows.Close()
 
-	var tasks []domain.Task
+	var digests []domain.SavedSearchDigest
 	for rows.Next() {
-		var task domain.Task
-		var startDate, endDate sql.NullTime
-		var categoryID, subcategoryID, cost sql.NullInt64
-		var statusCode sql.NullInt64
-		var addresses pq.StringArray
+		var search domain.SavedSearch
+		var filters, frequency string
+		var lastNotifiedAt sql.NullTime
+		var task tasks.MatchedTask
 
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.CreatedAt,
-			&task.UserID,
-			&statusCode,
-			&startDate,
-			&endDate,
-			&cost,
-			&addresses,
-			&task.ServiceLocation,
-			&task.PeriodType,
-			&categoryID,
-			&subcategoryID,
-		)
+		err := rows.Scan(&search.ID, &search.UserID, &search.Name, &filters, &frequency, &search.CreatedAt, &lastNotifiedAt, &task.ID, &task.Title)
 		if err != nil {
-			return nil, fmt.Errorf("ошибка чтения строки: %w", err)
+			return nil, fmt.Errorf("ошибка при сканировании совпадения: %w", err)
 		}
 
-		task.Addresses = []string(addresses) // Преобразуем pq.StringArray обратно в []string
+		if n := len(digests); n > 0 && digests[n-1].Search.ID == search.ID {
+			digests[n-1].Tasks = append(digests[n-1].Tasks, task)
+			continue
+		}
+		search.Frequency = domain.AlertFrequency(frequency)
+		if lastNotifiedAt.Valid {
+			search.LastNotifiedAt = &lastNotifiedAt.Time
+		}
+		digests = append(digests, domain.SavedSearchDigest{Search: search, Tasks: []tasks.MatchedTask{task}})
+	}
 
-		if categoryID.Valid {
-			task.CategoryID = new(int)
-			*task.CategoryID = int(categoryID.Int64)
+	return digests, rows.Err()
+}
+
+// MarkDigestSent отмечает отправленными совпадения из сводки и запоминает время сводки.
+func (r *TasksRepository) MarkDigestSent(ctx context.Context, searchID int64, taskIDs []int64, sentAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        UPDATE saved_search_matches
+        SET notified_at = $3
+        WHERE search_id = $1 AND task_id = ANY($2) AND notified_at IS NULL`, searchID, pq.Array(taskIDs), sentAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при отметке совпадений поиска %d: %w", searchID, err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE saved_searches SET last_notified_at = $2 WHERE id = $1`, searchID, sentAt); err != nil {
+		return fmt.Errorf("ошибка при отметке сводки поиска %d: %w", searchID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать отправку сводки поиска %d: %w", searchID, err)
+	}
+	return nil
+}
+
+// nullTimePtr возвращает указатель на время или nil для NULL.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// negotiationQuery выбирает отклик, заказчика задачи и последнее предложение переговоров.
+const negotiationQuery = `
+        SELECT r.id, r.task_id, r.user_id, r.proposed_price, r.proposed_currency, r.response_text, r.created_at, r.task_revision, r.negotiation_status,
+               t.user_id,
+               o.id, o.author_id, o.author_role, o.action, o.price, o.start_date, o.end_date, o.comment, o.created_at
+        FROM responses r
+        JOIN tasks t ON t.id = r.task_id
+        JOIN LATERAL (
+            SELECT id, author_id, author_role, action, price, start_date, end_date, comment, created_at
+            FROM response_offers
+            WHERE response_id = r.id
+            ORDER BY id DESC
+            LIMIT 1
+        ) o ON TRUE`
+
+func scanNegotiation(row pgx.Row) (*domain.Negotiation, error) {
+	var n domain.Negotiation
+	var offerStart, offerEnd sql.NullTime
+	err := row.Scan(&n.Response.ID, &n.Response.TaskID, &n.Response.UserID, &n.Response.ProposedPrice.Amount,
+		&n.Response.ProposedPrice.Currency, &n.Response.ResponseText, &n.Response.CreatedAt, &n.Response.TaskRevision, &n.Response.NegotiationStatus,
+		&n.CustomerID,
+		&n.LastOffer.ID, &n.LastOffer.AuthorID, &n.LastOffer.AuthorRole, &n.LastOffer.Action, &n.LastOffer.Price,
+		&offerStart, &offerEnd, &n.LastOffer.Comment, &n.LastOffer.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrResponseNotFound
 		}
-		if subcategoryID.Valid {
-			task.SubcategoryID = new(int)
-			*task.SubcategoryID = int(subcategoryID.Int64)
+		return nil, fmt.Errorf("не удалось получить состояние переговоров: %w", err)
+	}
+	n.Status = n.Response.NegotiationStatus
+	n.LastOffer.ResponseID = n.Response.ID
+	n.LastOffer.Currency = n.Response.ProposedPrice.Currency
+	n.LastOffer.StartDate = nullTimePtr(offerStart)
+	n.LastOffer.EndDate = nullTimePtr(offerEnd)
+	return &n, nil
+}
+
+// GetNegotiation получает состояние переговоров по отклику.
+func (r *TasksRepository) GetNegotiation(ctx context.Context, responseID int64) (*domain.Negotiation, error) {
+	return scanNegotiation(r.db.QueryRow(ctx, negotiationQuery+` WHERE r.id = $1`, responseID))
+}
+
+// GetNegotiationByTaskAndUser получает состояние переговоров по отклику исполнителя на задачу.
+func (r *TasksRepository) GetNegotiationByTaskAndUser(ctx context.Context, taskID, executorID int64) (*domain.Negotiation, error) {
+	return scanNegotiation(r.db.QueryRow(ctx, negotiationQuery+` WHERE r.task_id = $1 AND r.user_id = $2`, taskID, executorID))
+}
+
+// AppendOffer добавляет ход в историю переговоров и меняет их статус.
+// Ход принимается, только если последнее предложение по отклику всё ещё prevOfferID:
+// так два одновременных ответа не проходят оба.
+func (r *TasksRepository) AppendOffer(ctx context.Context, offer domain.ResponseOffer, status domain.NegotiationStatus, prevOfferID int64) (domain.ResponseOffer, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.ResponseOffer{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var lastOfferID int64
+	err = tx.QueryRow(ctx, `
+        SELECT (SELECT MAX(id) FROM response_offers WHERE response_id = r.id)
+        FROM responses r
+        WHERE r.id = $1
+        FOR UPDATE`, offer.ResponseID).Scan(&lastOfferID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ResponseOffer{}, domain.ErrResponseNotFound
 		}
-		if cost.Valid {
-			task.Cost = new(int)
-			*task.Cost = int(cost.Int64)
+		return domain.ResponseOffer{}, fmt.Errorf("не удалось заблокировать отклик с ID %d: %w", offer.ResponseID, err)
+	}
+	if lastOfferID != prevOfferID {
+		return domain.ResponseOffer{}, domain.ErrOfferConflict
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO response_offers (response_id, author_id, author_role, action, price, start_date, end_date, comment, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id`,
+		offer.ResponseID, offer.AuthorID, offer.AuthorRole, offer.Action, offer.Price,
+		offer.StartDate, offer.EndDate, offer.Comment, offer.CreatedAt).Scan(&offer.ID)
+	if err != nil {
+		return domain.ResponseOffer{}, fmt.Errorf("не удалось сохранить предложение по отклику с ID %d: %w", offer.ResponseID, err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE responses SET negotiation_status = $1 WHERE id = $2`, status, offer.ResponseID); err != nil {
+		return domain.ResponseOffer{}, fmt.Errorf("не удалось обновить статус переговоров по отклику с ID %d: %w", offer.ResponseID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.ResponseOffer{}, fmt.Errorf("не удалось зафиксировать предложение: %w", err)
+	}
+	return offer, nil
+}
+
+// ListResponseOffers возвращает историю переговоров по отклику в порядке ходов.
+func (r *TasksRepository) ListResponseOffers(ctx context.Context, responseID int64) ([]domain.ResponseOffer, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT o.id, o.response_id, o.author_id, o.author_role, o.action, o.price, r.proposed_currency,
+               o.start_date, o.end_date, o.comment, o.created_at
+        FROM response_offers o
+        JOIN responses r ON r.id = o.response_id
+        WHERE o.response_id = $1
+        ORDER BY o.id`, responseID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить историю переговоров по отклику с ID %d: %w", responseID, err)
+	}
+	defer rows.Close()
+
+	var offers []domain.ResponseOffer
+	for rows.Next() {
+		var o domain.ResponseOffer
+		var startDate, endDate sql.NullTime
+		if err := rows.Scan(&o.ID, &o.ResponseID, &o.AuthorID, &o.AuthorRole, &o.Action, &o.Price, &o.Currency,
+			&startDate, &endDate, &o.Comment, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("не удалось сканировать предложение: %w", err)
 		}
-		if startDate.Valid {
-			task.StartDate = new(time.Time)
-			*task.StartDate = startDate.Time
+		o.StartDate = nullTimePtr(startDate)
+		o.EndDate = nullTimePtr(endDate)
+		offers = append(offers, o)
+	}
+	return offers, rows.Err()
+}
+
+// GetContractByID получает контракт по идентификатору.
+func (r *TasksRepository) GetContractByID(ctx context.Context, contractID int64) (*domain.Contract, error) {
+	var contract domain.Contract
+	var startDate, endDate sql.NullTime
+	var price sql.NullInt64
+	var priceCurrency money.Currency
+
+	err := r.db.QueryRow(ctx, `
+        SELECT id, task_id, executor_id, customer_id, created_at, updated_at, is_active, status_id, start_date, end_date, price, price_currency
+        FROM contracts
+        WHERE id = $1`, contractID).Scan(
+		&contract.ID,
+		&contract.TaskID,
+		&contract.ExecutorID,
+		&contract.CustomerID,
+		&contract.CreatedAt,
+		&contract.UpdatedAt,
+		&contract.IsActive,
+		&contract.StatusID,
+		&startDate,
+		&endDate,
+		&price,
+		&priceCurrency,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
 		}
-		if endDate.Valid {
-			task.EndDate = new(time.Time)
-			*task.EndDate = endDate.Time
+		return nil, fmt.Errorf("ошибка при получении контракта с ID %d: %w", contractID, err)
+	}
+
+	contract.StartDate = nullTimePtr(startDate)
+	contract.EndDate = nullTimePtr(endDate)
+	contract.Price = contractPrice(price, priceCurrency)
+	return &contract, nil
+}
+
+// ListMilestones возвращает этапы контракта вместе с отчётами по ним.
+func (r *TasksRepository) ListMilestones(ctx context.Context, contractID int64) ([]domain.Milestone, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT m.id, m.contract_id, m.position, m.title, m.amount, m.due_date, m.created_at,
+               rp.id, rp.task_id, rp.executor_comments, rp.customer_feedback, rp.execution_status,
+               rp.customer_confirmation, rp.created_at, rp.updated_at
+        FROM contract_milestones m
+        LEFT JOIN reports rp ON rp.milestone_id = m.id
+        WHERE m.contract_id = $1
+        ORDER BY m.position`, contractID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить этапы контракта с ID %d: %w", contractID, err)
+	}
+	defer rows.Close()
+
+	var milestones []domain.Milestone
+	for rows.Next() {
+		var m domain.Milestone
+		var dueDate sql.NullTime
+		var reportID sql.NullInt64
+		var taskID sql.NullInt64
+		var executorComments, customerFeedback sql.NullString
+		var executionStatus, customerConfirmation sql.NullBool
+		var reportCreated, reportUpdated sql.NullTime
+
+		err := rows.Scan(&m.ID, &m.ContractID, &m.Position, &m.Title, &m.Amount, &dueDate, &m.CreatedAt,
+			&reportID, &taskID, &executorComments, &customerFeedback, &executionStatus,
+			&customerConfirmation, &reportCreated, &reportUpdated)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось сканировать этап контракта: %w", err)
 		}
-		if statusCode.Valid {
-			task.StatusCode = int(statusCode.Int64)
+		m.DueDate = nullTimePtr(dueDate)
+
+		if reportID.Valid {
+			milestoneID := m.ID
+			report := &domain.Report{
+				ID:              reportID.Int64,
+				ContractID:      m.ContractID,
+				TaskID:          taskID.Int64,
+				MilestoneID:     &milestoneID,
+				ExecutionStatus: executionStatus.Bool,
+				CreatedAt:       reportCreated.Time,
+				UpdatedAt:       reportUpdated.Time,
+			}
+			if executorComments.Valid {
+				report.ExecutorComments = &executorComments.String
+			}
+			if customerFeedback.Valid {
+				report.CustomerFeedback = &customerFeedback.String
+			}
+			if customerConfirmation.Valid {
+				report.CustomerConfirmation = &customerConfirmation.Bool
+			}
+			m.Report = report
 		}
-		tasks = append(tasks, task)
+		m.Status = domain.StatusFromReport(m.Report)
+		milestones = append(milestones, m)
 	}
+	return milestones, rows.Err()
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка обработки результата: %w", err)
+// ReplaceMilestones заменяет план этапов контракта.
+// План меняется, только пока по контракту не сдано ни одного отчёта.
+func (r *TasksRepository) ReplaceMilestones(ctx context.Context, contractID int64, plan []domain.MilestoneRequest) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	return tasks, nil
+	var hasReports bool
+	err = tx.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM reports WHERE contract_id = c.id)
+        FROM contracts c
+        WHERE c.id = $1
+        FOR UPDATE`, contractID).Scan(&hasReports)
+	if err != nil {
+		return fmt.Errorf("не удалось заблокировать контракт с ID %d: %w", contractID, err)
+	}
+	if hasReports {
+		return domain.ErrMilestonesLocked
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM contract_milestones WHERE contract_id = $1`, contractID); err != nil {
+		return fmt.Errorf("не удалось удалить этапы контракта с ID %d: %w", contractID, err)
+	}
+	if err := insertMilestones(ctx, tx, contractID, plan); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать план этапов: %w", err)
+	}
+	return nil
+}
+
+// ResubmitReport заменяет отклонённый отчёт по этапу и снова отдаёт его на решение заказчику.
+// Подтверждённый отчёт не заменяется: оплата по нему уже освобождена.
+func (r *TasksRepository) ResubmitReport(ctx context.Context, reportID int64, executorComments string, executionStatus bool) error {
+	tag, err := r.db.Exec(ctx, `
+        UPDATE reports
+        SET executor_comments = $1, execution_status = $2, customer_confirmation = NULL, updated_at = $3
+        WHERE id = $4 AND customer_confirmation IS NOT TRUE`, executorComments, executionStatus, time.Now(), reportID)
+	if err != nil {
+		return fmt.Errorf("ошибка при повторной сдаче отчёта с ID %d: %w", reportID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReportAlreadySubmitted
+	}
+	return nil
+}
+
+// GetActiveContractByTask получает действующий контракт по задаче.
+func (r *TasksRepository) GetActiveContractByTask(ctx context.Context, taskID int64) (*domain.Contract, error) {
+	var contractID int64
+	err := r.db.QueryRow(ctx, `
+        SELECT id FROM contracts
+        WHERE task_id = $1 AND is_active = TRUE
+        ORDER BY created_at DESC
+        LIMIT 1`, taskID).Scan(&contractID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении контракта по задаче %d: %w", taskID, err)
+	}
+	return r.GetContractByID(ctx, contractID)
+}
+
+// FinishContract закрывает контракт и меняет статус задачи в одной транзакции.
+func (r *TasksRepository) FinishContract(ctx context.Context, contractID int64, change domain.TaskStatusChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateTaskStatus(ctx, tx, change); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE contracts SET is_active = FALSE, updated_at = $2 WHERE id = $1`, contractID, change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось закрыть контракт с ID %d: %w", contractID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать закрытие контракта с ID %d: %w", contractID, err)
+	}
+	return nil
+}
+
+// ConfirmLastMilestone подтверждает отчёт по последнему этапу, закрывает контракт и меняет статус
+// задачи в одной транзакции: подтверждение не сохранится без завершения контракта.
+func (r *TasksRepository) ConfirmLastMilestone(ctx context.Context, reportID int64, customerFeedback string, contractID int64, change domain.TaskStatusChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE reports
+        SET customer_feedback = $1, customer_confirmation = TRUE, updated_at = $2
+        WHERE id = $3 AND customer_confirmation IS NULL`, customerFeedback, change.CreatedAt, reportID)
+	if err != nil {
+		return fmt.Errorf("ошибка при подтверждении отчёта с ID %d: %w", reportID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReportNotSubmitted
+	}
+	if err := updateTaskStatus(ctx, tx, change); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE contracts SET is_active = FALSE, updated_at = $2 WHERE id = $1`, contractID, change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось закрыть контракт с ID %d: %w", contractID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать подтверждение отчёта с ID %d: %w", reportID, err)
+	}
+	return nil
 }
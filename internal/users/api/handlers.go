@@ -12,6 +12,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/unclaim/chegonado.git/internal/shared/common_errors"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
 	"github.com/unclaim/chegonado.git/internal/shared/utils"
 
 	"github.com/unclaim/chegonado.git/internal/users/domain"
@@ -139,26 +140,32 @@ func (uh *UserHandler) CheckUserHandler(w http.ResponseWriter, r *http.Request)
 	utils.NewResponse(w, http.StatusOK, response)
 }
 
-// GettingOrderExecutorsHandler - обработчик для получения списка исполнителей заказа.
+// GettingOrderExecutorsHandler - обработчик поиска исполнителей: текст, фильтры, фасеты и сортировка.
 func (uh *UserHandler) GettingOrderExecutorsHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := domain.ParseExecutorSearchQuery(r.URL.Query())
+	if err != nil {
+		common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		return
+	}
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-	proStr := r.URL.Query().Get("pro")
-	onlineStr := r.URL.Query().Get("online")
-	categories := r.URL.Query().Get("categories")
-	location := r.URL.Query().Get("location")
-
-	users, count, err := uh.Service.HandleGettingOrderExecutorsService(r.Context(), limitStr, offsetStr, proStr, onlineStr, categories, location)
+	result, err := uh.Service.SearchExecutorsService(r.Context(), query)
 	if err != nil {
-		common_errors.NewAppError(w, r, fmt.Errorf("ошибка при извлечении пользователей: %v", err), http.StatusInternalServerError)
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, domain.ErrInvalidExecutorQuery) {
+			common_errors.NewAppError(w, r, err, http.StatusBadRequest)
+		} else if errors.Is(err, domain.ErrTaskLocationUnknown) {
+			common_errors.NewAppError(w, r, err, http.StatusUnprocessableEntity)
+		} else {
+			common_errors.NewAppError(w, r, fmt.Errorf("ошибка при извлечении пользователей: %v", err), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	response := domain.OrderExecutorsResponse{
 		StatusCode: http.StatusOK,
-		Body:       users,
-		TotalCount: count,
+		Body:       result.Page.Items,
+		TotalCount: result.Total,
+		NextCursor: result.Page.NextCursor,
+		Facets:     result.Facets,
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+var (
+	// ErrInvalidExecutorQuery возвращается при неизвестном фильтре или некорректном значении фильтра
+	// в поиске исполнителей.
+	ErrInvalidExecutorQuery = errors.New("некорректный запрос поиска исполнителей")
+	// ErrTaskLocationUnknown возвращается, если у задачи нет адреса с известными координатами.
+	ErrTaskLocationUnknown = errors.New("координаты задачи неизвестны")
+)
+
+// ExecutorSortOrder — порядок сортировки исполнителей.
+type ExecutorSortOrder string
+
+const (
+	ExecutorsSortID        ExecutorSortOrder = "id"         // По id: порядок списка без сортировки
+	ExecutorsSortRelevance ExecutorSortOrder = "relevance"  // По релевантности текстового запроса
+	ExecutorsSortDistance  ExecutorSortOrder = "distance"   // Сначала ближайшие к точке поиска
+	ExecutorsSortRating    ExecutorSortOrder = "rating"     // По убыванию рейтинга; в категории, если фильтр задан ровно по одной
+	ExecutorsSortContracts ExecutorSortOrder = "contracts"  // По убыванию числа выполненных контрактов
+	ExecutorsSortPriceAsc  ExecutorSortOrder = "price_asc"  // Сначала с низким уровнем цен
+	ExecutorsSortPriceDesc ExecutorSortOrder = "price_desc" // Сначала с высоким уровнем цен
+)
+
+// IsValid сообщает, поддерживается ли порядок сортировки.
+func (o ExecutorSortOrder) IsValid() bool {
+	switch o {
+	case ExecutorsSortID, ExecutorsSortRelevance, ExecutorsSortDistance, ExecutorsSortRating,
+		ExecutorsSortContracts, ExecutorsSortPriceAsc, ExecutorsSortPriceDesc:
+		return true
+	}
+	return false
+}
+
+// ExecutorSearchQuery — типизированный запрос поиска исполнителей.
+// Пустые поля означают отсутствие соответствующего фильтра.
+type ExecutorSearchQuery struct {
+	Text           string   // Полнотекстовый запрос по имени, описанию и навыкам
+	CategoryIDs    []int    // Оказывает услуги хотя бы в одной из категорий
+	SubcategoryIDs []int    // Оказывает услуги хотя бы в одной из подкатегорий
+	SkillIDs       []int    // Есть хотя бы один из навыков
+	Pro            *bool    // Только с Pro-подпиской или только без неё
+	Verified       *bool    // Только с подтверждённым профилем или только без него
+	Online         bool     // Только с активной сессией
+	Location       string   // Местоположение, указанное в профиле
+	RatingMin      *float64 // Рейтинг не ниже RatingMin; исполнители без отзывов не подходят
+	Near           *geo.Point
+	TaskID         int64    // Точка поиска — первый адрес задачи с координатами; Near определяет сервис
+	RadiusKm       *float64 // Исполнитель не дальше RadiusKm от точки поиска
+	Sort           ExecutorSortOrder
+	Page           pagination.Request
+}
+
+// RatingCategory — категория, в которой берётся рейтинг для фильтра, фасета и сортировки:
+// если список отфильтрован ровно по одной категории, рейтинг в ней, иначе общий (0).
+func (q ExecutorSearchQuery) RatingCategory() int {
+	if len(q.CategoryIDs) == 1 {
+		return q.CategoryIDs[0]
+	}
+	return 0
+}
+
+// defaultExecutorsLimit — размер страницы списка исполнителей, если клиент не указал limit.
+const defaultExecutorsLimit = 3
+
+// Параметры запроса, которые понимает поиск исполнителей. Всё остальное отклоняется.
+const (
+	executorParamText          = "search"
+	executorParamCursor        = "cursor"
+	executorParamLimit         = "limit"
+	executorParamCategories    = "categories"
+	executorParamSubcategories = "subcategories"
+	executorParamSkills        = "skills"
+	executorParamPro           = "pro"
+	executorParamVerified      = "verified"
+	executorParamOnline        = "online"
+	executorParamLocation      = "location"
+	executorParamRatingMin     = "rating_min"
+	executorParamLat           = "lat"
+	executorParamLon           = "lon"
+	executorParamTaskID        = "task_id"
+	executorParamRadius        = "radius_km"
+	executorParamSort          = "sort"
+)
+
+var knownExecutorParams = map[string]bool{
+	executorParamText:          true,
+	executorParamCursor:        true,
+	executorParamLimit:         true,
+	executorParamCategories:    true,
+	executorParamSubcategories: true,
+	executorParamSkills:        true,
+	executorParamPro:           true,
+	executorParamVerified:      true,
+	executorParamOnline:        true,
+	executorParamLocation:      true,
+	executorParamRatingMin:     true,
+	executorParamLat:           true,
+	executorParamLon:           true,
+	executorParamTaskID:        true,
+	executorParamRadius:        true,
+	executorParamSort:          true,
+}
+
+// ParseExecutorSearchQuery разбирает параметры URL в ExecutorSearchQuery.
+// Списки можно передавать повтором параметра или через запятую (?categories=1,2).
+func ParseExecutorSearchQuery(values url.Values) (ExecutorSearchQuery, error) {
+	var unknown []string
+	for key := range values {
+		if !knownExecutorParams[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return ExecutorSearchQuery{}, fmt.Errorf("%w: неизвестные фильтры: %s", ErrInvalidExecutorQuery, strings.Join(unknown, ", "))
+	}
+
+	var q ExecutorSearchQuery
+	var err error
+
+	q.Text = strings.TrimSpace(values.Get(executorParamText))
+	q.Location = strings.TrimSpace(values.Get(executorParamLocation))
+
+	if q.CategoryIDs, err = parseExecutorIDs(values, executorParamCategories); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	if q.SubcategoryIDs, err = parseExecutorIDs(values, executorParamSubcategories); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	if q.SkillIDs, err = parseExecutorIDs(values, executorParamSkills); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	if q.Pro, err = parseExecutorBool(values, executorParamPro); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	if q.Verified, err = parseExecutorBool(values, executorParamVerified); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	// online=false не фильтрует: список и так включает исполнителей не в сети.
+	online, err := parseExecutorBool(values, executorParamOnline)
+	if err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	q.Online = online != nil && *online
+
+	if raw := strings.TrimSpace(values.Get(executorParamRatingMin)); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return ExecutorSearchQuery{}, fmt.Errorf("%w: %s должен быть числом, получено %q", ErrInvalidExecutorQuery, executorParamRatingMin, raw)
+		}
+		q.RatingMin = &v
+	}
+
+	if q.Near, err = geo.ParsePoint(values.Get(executorParamLat), values.Get(executorParamLon)); err != nil {
+		return ExecutorSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidExecutorQuery, err)
+	}
+	if raw := strings.TrimSpace(values.Get(executorParamTaskID)); raw != "" {
+		if q.TaskID, err = strconv.ParseInt(raw, 10, 64); err != nil || q.TaskID <= 0 {
+			return ExecutorSearchQuery{}, fmt.Errorf("%w: %s должен быть положительным числом, получено %q", ErrInvalidExecutorQuery, executorParamTaskID, raw)
+		}
+	}
+	if q.RadiusKm, err = geo.ParseRadiusKm(values.Get(executorParamRadius)); err != nil {
+		return ExecutorSearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidExecutorQuery, err)
+	}
+
+	q.Sort = ExecutorSortOrder(strings.TrimSpace(values.Get(executorParamSort)))
+
+	if q.Page, err = pagination.ParseRequest(values.Get(executorParamCursor), values.Get(executorParamLimit), defaultExecutorsLimit); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+
+	if err := q.Validate(); err != nil {
+		return ExecutorSearchQuery{}, err
+	}
+	return q, nil
+}
+
+// Validate проверяет согласованность фильтров и подставляет значения по умолчанию.
+func (q *ExecutorSearchQuery) Validate() error {
+	if q.RatingMin != nil && (*q.RatingMin < 0 || *q.RatingMin > 5) {
+		return fmt.Errorf("%w: %s должен быть от 0 до 5", ErrInvalidExecutorQuery, executorParamRatingMin)
+	}
+	if q.Near != nil && q.TaskID != 0 {
+		return fmt.Errorf("%w: укажите либо координаты, либо %s", ErrInvalidExecutorQuery, executorParamTaskID)
+	}
+
+	if q.Sort == "" {
+		q.Sort = ExecutorsSortRelevance
+	}
+	if !q.Sort.IsValid() {
+		return fmt.Errorf("%w: неизвестная сортировка %q", ErrInvalidExecutorQuery, q.Sort)
+	}
+	// Без текста релевантность не определена.
+	if q.Sort == ExecutorsSortRelevance && q.Text == "" {
+		q.Sort = ExecutorsSortID
+	}
+	if (q.RadiusKm != nil || q.Sort == ExecutorsSortDistance) && q.Near == nil && q.TaskID == 0 {
+		return fmt.Errorf("%w: для радиуса и сортировки по расстоянию нужны %s и %s или %s",
+			ErrInvalidExecutorQuery, executorParamLat, executorParamLon, executorParamTaskID)
+	}
+	if q.Page.Limit <= 0 {
+		q.Page.Limit = defaultExecutorsLimit
+	}
+	// Курсор привязан к сортировке: ключ страницы по рейтингу бессмыслен для сортировки по цене.
+	return q.Page.CheckSort(string(q.Sort))
+}
+
+func parseExecutorIDs(values url.Values, key string) ([]int, error) {
+	var result []int
+	for _, raw := range values[key] {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%w: %s должен содержать положительные целые числа, получено %q", ErrInvalidExecutorQuery, key, part)
+			}
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func parseExecutorBool(values url.Values, key string) (*bool, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s должен быть true или false, получено %q", ErrInvalidExecutorQuery, key, raw)
+	}
+	return &v, nil
+}
+
+// ExecutorFacetBucket — значение фасета и число исполнителей с ним.
+type ExecutorFacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// RatingRange — диапазон рейтинга: From включительно, To не включительно.
+type RatingRange struct {
+	From *float64 `json:"from,omitempty"`
+	To   *float64 `json:"to,omitempty"`
+}
+
+// RatingBucket — диапазон рейтинга и число исполнителей в нём.
+type RatingBucket struct {
+	RatingRange
+	Count int64 `json:"count"`
+}
+
+// RatingRanges — диапазоны фасета по рейтингу: от 4,5, 4–4,5, 3–4 и ниже 3.
+// Исполнители без отзывов не попадают ни в один диапазон.
+var RatingRanges = []RatingRange{
+	{From: ratingBound(4.5)},
+	{From: ratingBound(4), To: ratingBound(4.5)},
+	{From: ratingBound(3), To: ratingBound(4)},
+	{To: ratingBound(3)},
+}
+
+func ratingBound(v float64) *float64 {
+	return &v
+}
+
+// ExecutorFacets — распределение найденных исполнителей по значениям фильтров.
+// Фасет не учитывает собственный фильтр: клиент видит, сколько исполнителей добавит выбор ещё одного значения.
+type ExecutorFacets struct {
+	Categories    []ExecutorFacetBucket `json:"categories"`
+	Subcategories []ExecutorFacetBucket `json:"subcategories"`
+	Skills        []ExecutorFacetBucket `json:"skills"`
+	Ratings       []RatingBucket        `json:"ratings"`
+	Pro           []ExecutorFacetBucket `json:"pro"`
+	Verified      []ExecutorFacetBucket `json:"verified"`
+}
+
+// ExecutorSearchResult — страница найденных исполнителей, их общее число и фасеты.
+type ExecutorSearchResult struct {
+	Page   pagination.Page[User]
+	Total  int
+	Facets ExecutorFacets
+}
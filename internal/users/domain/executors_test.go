@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+)
+
+func TestParseExecutorSearchQuery(t *testing.T) {
+	values := url.Values{
+		"search":        {"сантехник"},
+		"categories":    {"3"},
+		"subcategories": {"10,11", "12"},
+		"skills":        {""},
+		"pro":           {"true"},
+		"verified":      {"false"},
+		"online":        {"false"},
+		"rating_min":    {"4.5"},
+		"sort":          {"contracts"},
+	}
+
+	q, err := ParseExecutorSearchQuery(values)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(q.SubcategoryIDs) != 3 || q.SubcategoryIDs[2] != 12 || len(q.SkillIDs) != 0 {
+		t.Errorf("списки разобраны неверно: %v %v", q.SubcategoryIDs, q.SkillIDs)
+	}
+	if q.Pro == nil || !*q.Pro || q.Verified == nil || *q.Verified || q.Online {
+		t.Errorf("флаги разобраны неверно: pro=%v verified=%v online=%v", q.Pro, q.Verified, q.Online)
+	}
+	if q.RatingMin == nil || *q.RatingMin != 4.5 || q.Sort != ExecutorsSortContracts {
+		t.Errorf("рейтинг или сортировка разобраны неверно: %v %q", q.RatingMin, q.Sort)
+	}
+	if q.RatingCategory() != 3 {
+		t.Errorf("при фильтре по одной категории рейтинг берётся в ней, получено %d", q.RatingCategory())
+	}
+}
+
+func TestParseExecutorSearchQueryDefaults(t *testing.T) {
+	q, err := ParseExecutorSearchQuery(url.Values{})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if q.Sort != ExecutorsSortID || q.Page.Limit != defaultExecutorsLimit {
+		t.Errorf("без текста список упорядочен по id, получено %q, limit %d", q.Sort, q.Page.Limit)
+	}
+
+	q, err = ParseExecutorSearchQuery(url.Values{"search": {"плиточник"}})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if q.Sort != ExecutorsSortRelevance {
+		t.Errorf("с текстом сортировка по умолчанию — релевантность, получено %q", q.Sort)
+	}
+	if q.RatingCategory() != 0 {
+		t.Errorf("без фильтра по одной категории рейтинг общий, получено %d", q.RatingCategory())
+	}
+}
+
+func TestParseExecutorSearchQueryRejects(t *testing.T) {
+	cases := map[string]url.Values{
+		"неизвестный фильтр":        {"city": {"Москва"}},
+		"неизвестная сортировка":    {"sort": {"popular"}},
+		"рейтинг вне шкалы":         {"rating_min": {"6"}},
+		"флаг не булев":             {"pro": {"yes"}},
+		"отрицательная категория":   {"categories": {"-1"}},
+		"расстояние без точки":      {"sort": {"distance"}},
+		"координаты вместе с task":  {"lat": {"55.75"}, "lon": {"37.62"}, "task_id": {"7"}},
+		"курсор другой сортировки":  {"sort": {"rating"}, "cursor": {pagination.IntCursor("contracts", 3, 1).Encode()}},
+		"некорректный номер задачи": {"task_id": {"0"}},
+	}
+	for name, values := range cases {
+		if _, err := ParseExecutorSearchQuery(values); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		} else if !errors.Is(err, ErrInvalidExecutorQuery) && !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("%s: неожиданная ошибка %v", name, err)
+		}
+	}
+}
+
+func TestParseExecutorSearchQueryTaskPoint(t *testing.T) {
+	q, err := ParseExecutorSearchQuery(url.Values{"task_id": {"7"}, "radius_km": {"10"}, "sort": {"distance"}})
+	if err != nil {
+		t.Fatalf("точку поиска можно задать задачей: %v", err)
+	}
+	if q.TaskID != 7 || q.Near != nil || q.RadiusKm == nil || *q.RadiusKm != 10 {
+		t.Errorf("параметры поиска по задаче разобраны неверно: %+v", q)
+	}
+}
@@ -101,9 +101,11 @@ type MessageResponse struct {
 	Body       interface{} `json:"body,omitzero"`       // Тело ответа (может содержать любые данные)
 }
 type OrderExecutorsResponse struct {
-	StatusCode int    `json:"statusCode"`
-	Body       []User `json:"body"`
-	TotalCount int    `json:"totalCount"`
+	StatusCode int            `json:"statusCode"`
+	Body       []User         `json:"body"`
+	TotalCount int            `json:"totalCount"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Курсор следующей страницы; пусто в конце списка
+	Facets     ExecutorFacets `json:"facets"`                // Распределение найденных исполнителей по значениям фильтров
 }
 type UserSkillsResponse struct {
 	UserID int64   `json:"user_id"`
@@ -265,34 +267,39 @@ type VerifyCode struct {
 
 // User представляет пользователя с информацией о его профиле.
 type User struct {
-	ID             int64      `json:"id,omitzero"`              // Уникальный идентификатор пользователя в системе.
-	Version        int64      `json:"ver,omitzero"`             // Версия профиля пользователя.
-	Blacklisted    bool       `json:"blacklisted,omitzero"`     // Статус черного списка: true, если пользователь в черном списке, иначе false.
-	Sex            *string    `json:"sex,omitzero"`             // Пол пользователя, представленный как строка (ENUM).
-	FollowersCount int64      `json:"followers_count,omitzero"` // Количество подписчиков пользователя.
-	Verified       bool       `json:"verified,omitzero"`        // Статус подтверждения профиля (true - подтвержден, false - не подтвержден).
-	NoAds          bool       `json:"no_ads,omitzero"`          // Флаг отключения рекламы (true - реклама отключена).
-	CanUploadShot  bool       `json:"can_upload_shot,omitzero"` // Флаг, указывающий, может ли пользователь загружать работы на платформу.
-	Pro            bool       `json:"pro,omitzero"`             // Флаг, указывающий, является ли пользователь профессионалом (true - да).
-	Type           string     `json:"type,omitzero"`            // Тип пользователя, например, "обычный" или "профессионал".
-	FirstName      *string    `json:"first_name,omitzero"`      // Имя пользователя.
-	LastName       *string    `json:"last_name,omitzero"`       // Фамилия пользователя.
-	MiddleName     *string    `json:"middle_name,omitzero"`     // Отчество пользователя (если есть).
-	Username       *string    `json:"username,omitzero"`        // Уникальное имя пользователя.
-	PasswordHash   string     `json:"password_hash,omitzero"`   // Хэш пароля пользователя.
-	Bdate          *time.Time `json:"bdate,omitzero"`           // Дата рождения пользователя.
-	Phone          *string    `json:"phone,omitzero"`           // Номер телефона пользователя.
-	Email          string     `json:"email,omitzero"`           // Электронная почта пользователя.
-	HTMLURL        *string    `json:"html_url,omitzero"`        // URL-адрес профиля пользователя в формате HTML.
-	AvatarURL      *string    `json:"avatar_url,omitzero"`      // URL-адрес аватара пользователя.
-	Bio            *string    `json:"bio,omitzero"`             // Краткая информация о пользователе.
-	Location       *string    `json:"location,omitzero"`        // Местоположение пользователя.
-	CreatedAt      time.Time  `json:"created_at,omitzero"`      // Дата создания профиля пользователя.
-	UpdatedAt      time.Time  `json:"updated_at,omitzero"`      // Дата последнего обновления профиля пользователя.
-	Links          UserLinks  `json:"links,omitzero"`           // Внешние ссылки пользователя (веб-сайт, Twitter и др.).
-	Teams          []Team     `json:"teams,omitzero"`           // Список команд, в которых состоит пользователь.
-	IsFollowing    bool       `json:"is_following,omitzero"`    // Указывает, подписан ли текущий пользователь на данного пользователя.
-	IsBlocked      bool       `json:"is_blocked,omitzero"`      // Указывает, заблокирован ли текущий пользователь данным пользователем.
+	ID                 int64      `json:"id,omitzero"`                  // Уникальный идентификатор пользователя в системе.
+	Version            int64      `json:"ver,omitzero"`                 // Версия профиля пользователя.
+	Blacklisted        bool       `json:"blacklisted,omitzero"`         // Статус черного списка: true, если пользователь в черном списке, иначе false.
+	Sex                *string    `json:"sex,omitzero"`                 // Пол пользователя, представленный как строка (ENUM).
+	FollowersCount     int64      `json:"followers_count,omitzero"`     // Количество подписчиков пользователя.
+	Verified           bool       `json:"verified,omitzero"`            // Статус подтверждения профиля (true - подтвержден, false - не подтвержден).
+	NoAds              bool       `json:"no_ads,omitzero"`              // Флаг отключения рекламы (true - реклама отключена).
+	CanUploadShot      bool       `json:"can_upload_shot,omitzero"`     // Флаг, указывающий, может ли пользователь загружать работы на платформу.
+	Pro                bool       `json:"pro,omitzero"`                 // Флаг, указывающий, является ли пользователь профессионалом (true - да).
+	Type               string     `json:"type,omitzero"`                // Тип пользователя, например, "обычный" или "профессионал".
+	FirstName          *string    `json:"first_name,omitzero"`          // Имя пользователя.
+	LastName           *string    `json:"last_name,omitzero"`           // Фамилия пользователя.
+	MiddleName         *string    `json:"middle_name,omitzero"`         // Отчество пользователя (если есть).
+	Username           *string    `json:"username,omitzero"`            // Уникальное имя пользователя.
+	PasswordHash       string     `json:"password_hash,omitzero"`       // Хэш пароля пользователя.
+	Bdate              *time.Time `json:"bdate,omitzero"`               // Дата рождения пользователя.
+	Phone              *string    `json:"phone,omitzero"`               // Номер телефона пользователя.
+	Email              string     `json:"email,omitzero"`               // Электронная почта пользователя.
+	HTMLURL            *string    `json:"html_url,omitzero"`            // URL-адрес профиля пользователя в формате HTML.
+	AvatarURL          *string    `json:"avatar_url,omitzero"`          // URL-адрес аватара пользователя.
+	Bio                *string    `json:"bio,omitzero"`                 // Краткая информация о пользователе.
+	Location           *string    `json:"location,omitzero"`            // Местоположение пользователя.
+	CreatedAt          time.Time  `json:"created_at,omitzero"`          // Дата создания профиля пользователя.
+	UpdatedAt          time.Time  `json:"updated_at,omitzero"`          // Дата последнего обновления профиля пользователя.
+	Links              UserLinks  `json:"links,omitzero"`               // Внешние ссылки пользователя (веб-сайт, Twitter и др.).
+	Teams              []Team     `json:"teams,omitzero"`               // Список команд, в которых состоит пользователь.
+	IsFollowing        bool       `json:"is_following,omitzero"`        // Указывает, подписан ли текущий пользователь на данного пользователя.
+	IsBlocked          bool       `json:"is_blocked,omitzero"`          // Указывает, заблокирован ли текущий пользователь данным пользователем.
+	DistanceKm         *float64   `json:"distance_km,omitzero"`         // Расстояние до точки поиска в списке исполнителей.
+	Rating             *float64   `json:"rating,omitzero"`              // Рейтинг исполнителя; в списке, отфильтрованном по одной категории, — рейтинг в ней.
+	ReviewCount        int64      `json:"review_count,omitzero"`        // Число отзывов, учтённых в Rating.
+	CompletedContracts int64      `json:"completed_contracts,omitzero"` // Число выполненных контрактов в списке исполнителей.
+	PriceLevel         *int64     `json:"price_level,omitzero"`         // Средняя цена выполненных задач в минимальных единицах базовой валюты.
 }
 
 // UserLinks представляет ссылки пользователя на внешние ресурсы.
@@ -5,6 +5,8 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
 )
 
 // UsersService defines the interface for user-related services.
@@ -26,7 +28,7 @@ type UsersService interface {
 	GetUserPersonalDataService(ctx context.Context, r *http.Request) (Response, error)
 	HandleGetService(ctx context.Context, r *http.Request) (User, error)
 	CheckUserService(ctx context.Context, req CheckUserRequest) (bool, error)
-	HandleGettingOrderExecutorsService(ctx context.Context, limitStr, offsetStr, proStr, onlineStr, categories, location string) ([]User, int, error)
+	SearchExecutorsService(ctx context.Context, query ExecutorSearchQuery) (ExecutorSearchResult, error)
 	HandleAccountUpdateEmailService(ctx context.Context, r *http.Request, userEmail string) error
 	HandleBlockUserService(ctx context.Context, r *http.Request, blockedID int64) (string, error)
 	HandlePostService(ctx context.Context, r *http.Request) error
@@ -69,7 +71,9 @@ type UserRepositoryPort interface {
 	CreateAccountVerificationsCode(ctx context.Context, email string, code int64) error
 	CreateHashPass(ctx context.Context, plainPassword, salt string) ([]byte, error)
 	DeleteUserByID(ctx context.Context, userID int64) error
-	FetchUsers(ctx context.Context, limit, offset int, proStr, onlineStr, categories, location string) ([]User, int, error)
+	SearchExecutors(ctx context.Context, query ExecutorSearchQuery) (ExecutorSearchResult, error) // query.Near уже определена сервисом
+	UpdateUserCoordinates(ctx context.Context, userID int64, point *geo.Point) error              // nil сбрасывает координаты
+	GetTaskPoint(ctx context.Context, taskID int64) (*geo.Point, error)                           // Первый адрес задачи с координатами; nil, если таких нет
 	GetByEmail(ctx context.Context, Email string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	GetCompanyInfo(ctx context.Context) (Company, error)
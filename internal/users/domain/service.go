@@ -10,13 +10,13 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/unclaim/chegonado.git/internal/shared/config"
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
 	"github.com/unclaim/chegonado.git/internal/shared/ports"
 	"github.com/unclaim/chegonado.git/pkg/security/session"
 	"github.com/unclaim/chegonado.git/pkg/security/token"
@@ -28,15 +28,17 @@ type UsersServiceImp struct {
 	EmailSender ports.EmailSender
 	Tokens      token.TokenManager
 	Config      *config.AppConfig
+	Geocoder    ports.Geocoder
 }
 
 // NewUsersService creates a new instance of UsersServiceImp.
-func NewUsersService(repo UserRepositoryPort, emailSender ports.EmailSender, tokens token.TokenManager, config config.AppConfig) *UsersServiceImp {
+func NewUsersService(repo UserRepositoryPort, emailSender ports.EmailSender, tokens token.TokenManager, config config.AppConfig, geocoder ports.Geocoder) *UsersServiceImp {
 	return &UsersServiceImp{
 		UsersRepo:   repo,
 		EmailSender: emailSender,
 		Tokens:      tokens,
 		Config:      &config,
+		Geocoder:    geocoder,
 	}
 }
 
@@ -211,30 +213,29 @@ func (s *UsersServiceImp) CheckUserService(ctx context.Context, req CheckUserReq
 	return user != nil, nil
 }
 
-// HandleGettingOrderExecutorsService - сервис для получения списка исполнителей заказа.
-func (s *UsersServiceImp) HandleGettingOrderExecutorsService(ctx context.Context, limitStr, offsetStr, proStr, onlineStr, categories, location string) ([]User, int, error) {
-	limit, offset := 3, 0
-	var err error
-
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			return nil, 0, fmt.Errorf("некорректный параметр limit: %w", err)
-		}
+// SearchExecutorsService - сервис поиска исполнителей с фасетами.
+// Если задан query.TaskID, точкой поиска становится адрес задачи.
+func (s *UsersServiceImp) SearchExecutorsService(ctx context.Context, query ExecutorSearchQuery) (ExecutorSearchResult, error) {
+	if err := query.Validate(); err != nil {
+		return ExecutorSearchResult{}, err
 	}
 
-	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
+	if query.TaskID != 0 {
+		point, err := s.UsersRepo.GetTaskPoint(ctx, query.TaskID)
 		if err != nil {
-			return nil, 0, fmt.Errorf("некорректный параметр offset: %w", err)
+			return ExecutorSearchResult{}, fmt.Errorf("ошибка при получении координат задачи: %w", err)
 		}
+		if point == nil {
+			return ExecutorSearchResult{}, fmt.Errorf("%w: задача %d", ErrTaskLocationUnknown, query.TaskID)
+		}
+		query.Near = point
 	}
 
-	users, count, err := s.UsersRepo.FetchUsers(ctx, limit, offset, proStr, onlineStr, categories, location)
+	result, err := s.UsersRepo.SearchExecutors(ctx, query)
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка при извлечении пользователей: %w", err)
+		return ExecutorSearchResult{}, fmt.Errorf("ошибка при поиске исполнителей: %w", err)
 	}
-	return users, count, nil
+	return result, nil
 }
 
 // HandleAccountUpdateEmailService - сервис для обновления email.
@@ -706,6 +707,7 @@ func (s *UsersServiceImp) UpdateUserPersonalDataService(ctx context.Context, r *
 	if err := s.UsersRepo.UpdateUserPersonalData(ctx, sess.UserID, request); err != nil {
 		return Response{}, err
 	}
+	s.updateCoordinates(ctx, sess.UserID, request.Location)
 
 	msg := SuccessResponse{Message: "Данные успешно обновлены"}
 	response := Response{StatusCode: http.StatusOK, Body: msg}
@@ -927,6 +929,32 @@ func (s *UsersServiceImp) ProfilePostService(ctx context.Context, r *http.Reques
 	if err := s.UsersRepo.UpdateUserPersonalData(ctx, sess.UserID, update); err != nil {
 		return fmt.Errorf("ошибка обновления профиля: %v", err)
 	}
+	s.updateCoordinates(ctx, sess.UserID, update.Location)
 
 	return nil
 }
+
+// updateCoordinates определяет координаты местоположения пользователя для поиска исполнителей по расстоянию.
+// Ошибка геокодирования не мешает сохранить профиль: координаты просто сбрасываются.
+func (s *UsersServiceImp) updateCoordinates(ctx context.Context, userID int64, location *string) {
+	if location == nil {
+		return
+	}
+
+	var point *geo.Point
+	if strings.TrimSpace(*location) != "" {
+		p, err := s.Geocoder.Geocode(ctx, *location)
+		switch {
+		case err == nil:
+			point = &p
+		case errors.Is(err, geo.ErrAddressNotFound):
+			log.Printf("местоположение пользователя %d не найдено геокодером: %q", userID, *location)
+		default:
+			log.Printf("ошибка геокодирования местоположения пользователя %d: %v", userID, err)
+		}
+	}
+
+	if err := s.UsersRepo.UpdateUserCoordinates(ctx, userID, point); err != nil {
+		log.Printf("не удалось сохранить координаты пользователя %d: %v", userID, err)
+	}
+}
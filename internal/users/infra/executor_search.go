@@ -0,0 +1,336 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+	"github.com/unclaim/chegonado.git/internal/shared/pagination"
+	"github.com/unclaim/chegonado.git/internal/users/domain"
+)
+
+// unknownDistanceKm — ключ сортировки по расстоянию для пользователей без координат.
+const unknownDistanceKm = 1e9
+
+// executorFacetSize — сколько самых частых значений возвращает фасет по категориям, подкатегориям и навыкам.
+const executorFacetSize = 20
+
+// completedTaskStatus — код статуса выполненной задачи (tasks/domain.StatusCompleted).
+// Контракт считается выполненным, если выполнена его задача.
+const completedTaskStatus = 102
+
+// executorsSource — исполнители с полями, по которым ищут, фильтруют и сортируют.
+// Навыки — категории из user_skills; категории и подкатегории — услуги из user_services.
+// Уровень цен — средняя цена выполненных задач в базовой валюте. Единственный параметр —
+// категория, в которой берётся рейтинг (0 — общий).
+const executorsSource = `(
+	SELECT u.id, u.pro, u.verified, u.type, u.username, u.avatar_url, u.first_name, u.last_name, u.bio, u.location,
+	       u.latitude, u.longitude, er.score, COALESCE(er.review_count, 0) AS review_count,
+	       EXISTS (SELECT 1 FROM sessions s WHERE s.user_id = u.id AND s.status = 'active') AS online,
+	       ARRAY(SELECT DISTINCT sv.category_id FROM user_services sv WHERE sv.user_id = u.id)::int[] AS category_ids,
+	       ARRAY(SELECT DISTINCT sub.id FROM user_services sv, unnest(sv.subcategory_ids) AS sub(id) WHERE sv.user_id = u.id)::int[] AS subcategory_ids,
+	       ARRAY(SELECT DISTINCT sk.category_id FROM user_skills sk WHERE sk.user_id = u.id)::int[] AS skill_ids,
+	       done.contracts, done.price_level,
+	       to_tsvector('russian', concat_ws(' ', u.first_name, u.last_name, u.username, u.bio,
+	           (SELECT string_agg(c.name, ' ') FROM user_skills sk JOIN categories c ON c.id = sk.category_id WHERE sk.user_id = u.id),
+	           (SELECT string_agg(sc.name, ' ') FROM user_services sv JOIN subcategories sc ON sc.id = ANY(sv.subcategory_ids) WHERE sv.user_id = u.id)
+	       )) AS document
+	FROM users u
+	LEFT JOIN executor_ratings er ON er.user_id = u.id AND er.category_id = %[1]s AND er.subcategory_id = 0
+	LEFT JOIN LATERAL (
+	    SELECT COUNT(*) AS contracts, AVG(t.cost_base)::bigint AS price_level
+	    FROM contracts c
+	    JOIN tasks t ON t.id = c.task_id
+	    WHERE c.executor_id = u.id AND t.status_code = %[2]d
+	) done ON TRUE
+	WHERE u.blacklisted = false AND u.type IN ('USER', 'BOT')
+) e`
+
+// Фильтры, которые не учитывает фасет с тем же именем.
+const (
+	executorFilterCategories    = "categories"
+	executorFilterSubcategories = "subcategories"
+	executorFilterSkills        = "skills"
+	executorFilterRating        = "rating"
+	executorFilterPro           = "pro"
+	executorFilterVerified      = "verified"
+)
+
+// executorQuery — условия поиска исполнителей в SQL. Имена колонок фиксированы в коде, из запроса
+// пользователя приходят только значения. Номера аргументов у каждого SQL-запроса свои, поэтому
+// условия собираются заново для каждого из них.
+type executorQuery struct {
+	args     []interface{}
+	source   string
+	where    []string          // Фильтры, которые учитываются всегда
+	filters  map[string]string // Фильтры, которые не учитывает фасет с тем же именем
+	textArg  string
+	distance string // Расстояние до точки поиска; пусто без неё
+}
+
+func newExecutorQuery(q domain.ExecutorSearchQuery) *executorQuery {
+	t := &executorQuery{filters: make(map[string]string)}
+	t.source = fmt.Sprintf(executorsSource, t.arg(q.RatingCategory()), completedTaskStatus)
+
+	if q.Text != "" {
+		t.textArg = t.arg(q.Text)
+		t.where = append(t.where, "e.document @@ plainto_tsquery('russian', "+t.textArg+")")
+	}
+	if len(q.CategoryIDs) > 0 {
+		t.filters[executorFilterCategories] = "e.category_ids && " + t.arg(pq.Array(q.CategoryIDs)) + "::int[]"
+	}
+	if len(q.SubcategoryIDs) > 0 {
+		t.filters[executorFilterSubcategories] = "e.subcategory_ids && " + t.arg(pq.Array(q.SubcategoryIDs)) + "::int[]"
+	}
+	if len(q.SkillIDs) > 0 {
+		t.filters[executorFilterSkills] = "e.skill_ids && " + t.arg(pq.Array(q.SkillIDs)) + "::int[]"
+	}
+	if q.RatingMin != nil {
+		t.filters[executorFilterRating] = "e.score >= " + t.arg(*q.RatingMin)
+	}
+	if q.Pro != nil {
+		t.filters[executorFilterPro] = "e.pro = " + t.arg(*q.Pro)
+	}
+	if q.Verified != nil {
+		t.filters[executorFilterVerified] = "e.verified = " + t.arg(*q.Verified)
+	}
+	if q.Online {
+		t.where = append(t.where, "e.online")
+	}
+	if q.Location != "" {
+		t.where = append(t.where, "e.location = "+t.arg(q.Location))
+	}
+	if q.Near != nil {
+		t.distance = geo.DistanceSQL("e.latitude", "e.longitude", t.arg(q.Near.Lat)+"::float8", t.arg(q.Near.Lon)+"::float8")
+		if q.RadiusKm != nil {
+			t.where = append(t.where, "e.latitude IS NOT NULL AND "+t.distance+" <= "+t.arg(*q.RadiusKm))
+		}
+	}
+	return t
+}
+
+func (t *executorQuery) arg(v interface{}) string {
+	t.args = append(t.args, v)
+	return fmt.Sprintf("$%d", len(t.args))
+}
+
+// whereSQL собирает WHERE из всех фильтров, кроме фильтра skip, и дополнительных условий;
+// пустые условия пропускаются.
+func (t *executorQuery) whereSQL(skip string, extra ...string) string {
+	conditions := append([]string{}, t.where...)
+	for _, name := range []string{
+		executorFilterCategories, executorFilterSubcategories, executorFilterSkills,
+		executorFilterRating, executorFilterPro, executorFilterVerified,
+	} {
+		if c := t.filters[name]; c != "" && name != skip {
+			conditions = append(conditions, c)
+		}
+	}
+	for _, c := range extra {
+		if c != "" {
+			conditions = append(conditions, c)
+		}
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// SearchExecutors ищет исполнителей по тексту и фильтрам и считает фасеты.
+// Пагинация курсорная: ключ курсора зависит от сортировки, при равенстве ключей порядок задаёт id.
+// Исполнители без координат, отзывов или выполненных контрактов идут в конце сортировки по соответствующему ключу.
+func (r *UserRepository) SearchExecutors(ctx context.Context, q domain.ExecutorSearchQuery) (domain.ExecutorSearchResult, error) {
+	page, err := r.searchExecutorsPage(ctx, q)
+	if err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	total, err := r.countExecutors(ctx, q)
+	if err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+
+	result := domain.ExecutorSearchResult{Page: page, Total: total}
+	if result.Facets.Categories, err = r.executorFacet(ctx, q, "v", ", unnest(e.category_ids) AS v", executorFilterCategories, executorFacetSize); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	if result.Facets.Subcategories, err = r.executorFacet(ctx, q, "v", ", unnest(e.subcategory_ids) AS v", executorFilterSubcategories, executorFacetSize); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	if result.Facets.Skills, err = r.executorFacet(ctx, q, "v", ", unnest(e.skill_ids) AS v", executorFilterSkills, executorFacetSize); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	if result.Facets.Pro, err = r.executorFacet(ctx, q, "e.pro", "", executorFilterPro, 2); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	if result.Facets.Verified, err = r.executorFacet(ctx, q, "e.verified", "", executorFilterVerified, 2); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	if result.Facets.Ratings, err = r.ratingFacet(ctx, q); err != nil {
+		return domain.ExecutorSearchResult{}, err
+	}
+	return result, nil
+}
+
+func (r *UserRepository) searchExecutorsPage(ctx context.Context, q domain.ExecutorSearchQuery) (pagination.Page[domain.User], error) {
+	t := newExecutorQuery(q)
+
+	// Ключ сортировки, направление и тип ключа курсора. Для сортировки по id отдельного ключа нет.
+	var sortKey, direction, cmp string
+	floatKey := false
+	switch q.Sort {
+	case domain.ExecutorsSortRelevance:
+		sortKey, direction, cmp, floatKey = "ts_rank(e.document, plainto_tsquery('russian', "+t.textArg+"))::float8", "DESC", "<", true
+	case domain.ExecutorsSortDistance:
+		sortKey, direction, cmp, floatKey = fmt.Sprintf("COALESCE(%s, %v)::float8", t.distance, unknownDistanceKm), "ASC", ">", true
+	case domain.ExecutorsSortRating:
+		sortKey, direction, cmp, floatKey = "COALESCE(e.score, 0)::float8", "DESC", "<", true
+	case domain.ExecutorsSortContracts:
+		sortKey, direction, cmp = "e.contracts::bigint", "DESC", "<"
+	case domain.ExecutorsSortPriceAsc:
+		sortKey, direction, cmp = "COALESCE(e.price_level, 9223372036854775807)::bigint", "ASC", ">"
+	case domain.ExecutorsSortPriceDesc:
+		sortKey, direction, cmp = "COALESCE(e.price_level, -1)::bigint", "DESC", "<"
+	default:
+		sortKey, direction, cmp = "e.id::bigint", "ASC", ">"
+	}
+	byID := q.Sort == domain.ExecutorsSortID
+
+	var after string
+	if cursor := q.Page.After; cursor != nil {
+		switch {
+		case byID:
+			after = "e.id > " + t.arg(cursor.ID)
+		case floatKey && cursor.Float != nil:
+			after = fmt.Sprintf("(%s, e.id) %s (%s, %s)", sortKey, cmp, t.arg(*cursor.Float), t.arg(cursor.ID))
+		case !floatKey && cursor.Int != nil:
+			after = fmt.Sprintf("(%s, e.id) %s (%s, %s)", sortKey, cmp, t.arg(*cursor.Int), t.arg(cursor.ID))
+		default:
+			return pagination.Page[domain.User]{}, pagination.ErrInvalidCursor
+		}
+	}
+
+	distanceColumn := "NULL::float8"
+	if t.distance != "" {
+		distanceColumn = t.distance
+	}
+
+	sqlQuery := `SELECT e.id, e.pro, e.verified, e.type, e.username, e.avatar_url, e.first_name, e.last_name, e.bio, e.location, ` +
+		distanceColumn + `, e.score, e.review_count, e.contracts, e.price_level, ` + sortKey + ` FROM ` + t.source +
+		t.whereSQL("", after) +
+		fmt.Sprintf(" ORDER BY %s %s, e.id %s LIMIT %s", sortKey, direction, direction, t.arg(q.Page.FetchLimit()))
+
+	rows, err := r.db.Query(ctx, sqlQuery, t.args...)
+	if err != nil {
+		return pagination.Page[domain.User]{}, fmt.Errorf("ошибка выполнения запроса: %v", err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	cursors := make(map[int64]pagination.Cursor)
+	sort := string(q.Sort)
+	for rows.Next() {
+		var user domain.User
+		var distanceKm sql.NullFloat64
+		var priceLevel sql.NullInt64
+		var floatValue float64
+		var intValue int64
+		var key interface{} = &intValue
+		if floatKey {
+			key = &floatValue
+		}
+		if err := rows.Scan(&user.ID, &user.Pro, &user.Verified, &user.Type, &user.Username, &user.AvatarURL, &user.FirstName, &user.LastName,
+			&user.Bio, &user.Location, &distanceKm, &user.Rating, &user.ReviewCount, &user.CompletedContracts, &priceLevel, key); err != nil {
+			return pagination.Page[domain.User]{}, fmt.Errorf("ошибка чтения строки пользователя: %v", err)
+		}
+		if distanceKm.Valid {
+			user.DistanceKm = &distanceKm.Float64
+		}
+		if priceLevel.Valid {
+			user.PriceLevel = &priceLevel.Int64
+		}
+		switch {
+		case byID:
+			cursors[user.ID] = pagination.Cursor{Sort: sort, ID: user.ID}
+		case floatKey:
+			cursors[user.ID] = pagination.FloatCursor(sort, floatValue, user.ID)
+		default:
+			cursors[user.ID] = pagination.IntCursor(sort, intValue, user.ID)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[domain.User]{}, fmt.Errorf("ошибка во время итерации результатов: %v", err)
+	}
+
+	return pagination.NewPage(users, q.Page, func(u domain.User) pagination.Cursor { return cursors[u.ID] }), nil
+}
+
+// countExecutors считает всех найденных исполнителей; курсор на счётчик не влияет.
+func (r *UserRepository) countExecutors(ctx context.Context, q domain.ExecutorSearchQuery) (int, error) {
+	t := newExecutorQuery(q)
+	var count int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM `+t.source+t.whereSQL(""), t.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета количества пользователей: %v", err)
+	}
+	return count, nil
+}
+
+// executorFacet считает самые частые значения выражения value среди найденных исполнителей.
+// from добавляется к источнику (например, unnest массива), skip — фильтр, который фасет не учитывает.
+func (r *UserRepository) executorFacet(ctx context.Context, q domain.ExecutorSearchQuery, value, from, skip string, size int) ([]domain.ExecutorFacetBucket, error) {
+	t := newExecutorQuery(q)
+	sqlQuery := `SELECT ` + value + `::text, COUNT(*) FROM ` + t.source + from + t.whereSQL(skip, value+" IS NOT NULL") +
+		` GROUP BY ` + value + ` ORDER BY COUNT(*) DESC, ` + value + ` LIMIT ` + t.arg(size)
+	rows, err := r.db.Query(ctx, sqlQuery, t.args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте фасета %s: %v", skip, err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.ExecutorFacetBucket{}
+	for rows.Next() {
+		var b domain.ExecutorFacetBucket
+		if err := rows.Scan(&b.Value, &b.Count); err != nil {
+			return nil, fmt.Errorf("ошибка чтения фасета %s: %v", skip, err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// ratingFacet считает найденных исполнителей по диапазонам рейтинга одним запросом.
+func (r *UserRepository) ratingFacet(ctx context.Context, q domain.ExecutorSearchQuery) ([]domain.RatingBucket, error) {
+	t := newExecutorQuery(q)
+	columns := make([]string, len(domain.RatingRanges))
+	for i, rr := range domain.RatingRanges {
+		bounds := []string{"e.score IS NOT NULL"}
+		if rr.From != nil {
+			bounds = append(bounds, "e.score >= "+t.arg(*rr.From))
+		}
+		if rr.To != nil {
+			bounds = append(bounds, "e.score < "+t.arg(*rr.To))
+		}
+		columns[i] = "COUNT(*) FILTER (WHERE " + strings.Join(bounds, " AND ") + ")"
+	}
+
+	counts := make([]int64, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	sqlQuery := `SELECT ` + strings.Join(columns, ", ") + ` FROM ` + t.source + t.whereSQL(executorFilterRating)
+	if err := r.db.QueryRow(ctx, sqlQuery, t.args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте фасета по рейтингу: %v", err)
+	}
+
+	buckets := make([]domain.RatingBucket, len(domain.RatingRanges))
+	for i, rr := range domain.RatingRanges {
+		buckets[i] = domain.RatingBucket{RatingRange: rr, Count: counts[i]}
+	}
+	return buckets, nil
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/lib/pq"
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
 	"github.com/unclaim/chegonado.git/internal/shared/utils"
 	"github.com/unclaim/chegonado.git/internal/users/domain"
 	"golang.org/x/crypto/argon2"
@@ -259,98 +259,36 @@ func (r *UserRepository) DeleteUserByID(ctx context.Context, userID int64) error
 	return nil
 }
 
-// FetchUsers получает список пользователей с фильтрацией и пагинацией.
-// **Внимание:** Запрос переписан с использованием параметризации для защиты от SQL-инъекций.
-func (r *UserRepository) FetchUsers(ctx context.Context, limit, offset int, proStr, onlineStr, categories, location string) ([]domain.User, int, error) {
-	var conditions []string
-	var args []interface{}
-	argCount := 1
-
-	baseQuery := `
-		SELECT DISTINCT u.id, u.pro, u.type, u.username, u.avatar_url, u.first_name, u.last_name, u.bio, u.location
-		FROM users u
-		LEFT JOIN sessions s ON u.id = s.user_id AND s.status = 'active'
-		LEFT JOIN user_skills us ON u.id = us.user_id
-		WHERE u.blacklisted = false AND u.type IN ('USER', 'BOT')
-	`
-
-	countQuery := `
-		SELECT COUNT(DISTINCT u.id)
-		FROM users u
-		LEFT JOIN sessions s ON u.id = s.user_id AND s.status = 'active'
-		LEFT JOIN user_skills us ON u.id = us.user_id
-		WHERE u.blacklisted = false AND u.type IN ('USER', 'BOT')
-	`
-
-	if proStr != "" {
-		switch proStr {
-		case "true":
-			conditions = append(conditions, fmt.Sprintf("u.pro = $%d", argCount))
-			args = append(args, true)
-			argCount++
-		case "false":
-			conditions = append(conditions, fmt.Sprintf("u.pro = $%d", argCount))
-			args = append(args, false)
-			argCount++
-		}
-	}
-
-	if onlineStr == "true" {
-		conditions = append(conditions, "s.id IS NOT NULL")
-	}
-
-	if categories != "" {
-		categoryIDs := strings.Split(categories, ",")
-		conditions = append(conditions, fmt.Sprintf("us.category_id = ANY($%d)", argCount))
-		var intIDs []int
-		for _, idStr := range categoryIDs {
-			var id int
-			if _, err := fmt.Sscanf(idStr, "%d", &id); err == nil {
-				intIDs = append(intIDs, id)
-			}
-		}
-		args = append(args, intIDs)
-		argCount++
-	}
-
-	if location != "" {
-		conditions = append(conditions, fmt.Sprintf("u.location = $%d", argCount))
-		args = append(args, location)
-		argCount++
-	}
-
-	if len(conditions) > 0 {
-		filterClause := " AND " + strings.Join(conditions, " AND ")
-		baseQuery += filterClause
-		countQuery += filterClause
+// UpdateUserCoordinates сохраняет координаты местоположения пользователя; nil сбрасывает их.
+func (r *UserRepository) UpdateUserCoordinates(ctx context.Context, userID int64, point *geo.Point) error {
+	var lat, lon *float64
+	if point != nil {
+		lat, lon = &point.Lat, &point.Lon
 	}
-
-	rows, err := r.db.Query(context.Background(), baseQuery+fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1), append(args, limit, offset)...)
+	_, err := r.db.Exec(ctx, `UPDATE users SET latitude = $1, longitude = $2 WHERE id = $3`, lat, lon, userID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка выполнения запроса: %v", err)
-	}
-	defer rows.Close()
-
-	var users []domain.User
-	for rows.Next() {
-		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Pro, &user.Type, &user.Username, &user.AvatarURL, &user.FirstName, &user.LastName, &user.Bio, &user.Location); err != nil {
-			return nil, 0, fmt.Errorf("ошибка чтения строки пользователя: %v", err)
-		}
-		users = append(users, user)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("ошибка во время итерации результатов: %v", err)
+		return fmt.Errorf("ошибка обновления координат пользователя: %v", err)
 	}
+	return nil
+}
 
-	var count int
-	err = r.db.QueryRow(context.Background(), countQuery, args...).Scan(&count)
+// GetTaskPoint получает координаты первого адреса задачи, для которого они известны.
+// Возвращает nil, если координат нет ни у одного адреса.
+func (r *UserRepository) GetTaskPoint(ctx context.Context, taskID int64) (*geo.Point, error) {
+	var point geo.Point
+	err := r.db.QueryRow(ctx, `
+		SELECT latitude, longitude
+		FROM task_addresses
+		WHERE task_id = $1 AND latitude IS NOT NULL
+		ORDER BY position
+		LIMIT 1`, taskID).Scan(&point.Lat, &point.Lon)
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка подсчета количества пользователей: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения координат задачи: %v", err)
 	}
-
-	return users, count, nil
+	return &point, nil
 }
 
 // GetByEmail ищет пользователя по email.
@@ -506,6 +444,13 @@ func (r *UserRepository) GetUserProfile(ctx context.Context, userId int64, curre
 	if err != nil {
 		return profile, 0, err
 	}
+	// Общий рейтинг исполнителя; у пользователя без отзывов о его работе рейтинга нет.
+	err = r.db.QueryRow(ctx,
+		"SELECT score, review_count FROM executor_ratings WHERE user_id = $1 AND category_id = 0 AND subcategory_id = 0",
+		userId).Scan(&profile.Rating, &profile.ReviewCount)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return profile, 0, err
+	}
 	return profile, subscriptionsCount, nil
 }
 
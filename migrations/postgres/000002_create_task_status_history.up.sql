@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS task_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    from_status INTEGER NOT NULL,
+    to_status INTEGER NOT NULL,
+    changed_by BIGINT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_status_history_task_id ON task_status_history (task_id, created_at);
+
+-- Исправляем задачи, отменённые старым кодом с несуществующим статусом 108.
+UPDATE tasks SET status_code = 103 WHERE status_code = 108;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS task_revisions;
+ALTER TABLE responses DROP COLUMN IF EXISTS task_revision;
+ALTER TABLE tasks DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,30 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE responses ADD COLUMN IF NOT EXISTS task_revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS task_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    cost INTEGER,
+    addresses TEXT[],
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    changed_by BIGINT NOT NULL,
+    replaced_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (task_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR(64) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, created_at DESC);
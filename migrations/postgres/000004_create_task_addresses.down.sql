@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_coordinates;
+ALTER TABLE users DROP COLUMN IF EXISTS longitude;
+ALTER TABLE users DROP COLUMN IF EXISTS latitude;
+DROP TABLE IF EXISTS task_addresses;
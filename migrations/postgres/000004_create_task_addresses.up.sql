@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS task_addresses (
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    PRIMARY KEY (task_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_addresses_coordinates ON task_addresses (latitude, longitude) WHERE latitude IS NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_users_coordinates ON users (latitude, longitude) WHERE latitude IS NOT NULL;
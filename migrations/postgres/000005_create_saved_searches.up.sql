@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters TEXT NOT NULL DEFAULT '',
+    frequency VARCHAR(16) NOT NULL DEFAULT 'instant' CHECK (frequency IN ('instant', 'hourly', 'daily')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_notified_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches (user_id);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    search_id BIGINT NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    matched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    notified_at TIMESTAMP,
+    PRIMARY KEY (search_id, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_pending ON saved_search_matches (search_id) WHERE notified_at IS NULL;
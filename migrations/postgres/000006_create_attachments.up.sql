@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    owner_type VARCHAR(16) NOT NULL CHECK (owner_type IN ('task', 'report')),
+    owner_id BIGINT NOT NULL,
+    uploader_id BIGINT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    url TEXT NOT NULL,
+    storage_path TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_owner ON attachments (owner_type, owner_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS response_offers;
+
+ALTER TABLE contracts DROP COLUMN IF EXISTS price;
+ALTER TABLE responses DROP COLUMN IF EXISTS negotiation_status;
@@ -0,0 +1,23 @@
+ALTER TABLE responses ADD COLUMN IF NOT EXISTS negotiation_status VARCHAR(16) NOT NULL DEFAULT 'open';
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS price INTEGER;
+
+CREATE TABLE IF NOT EXISTS response_offers (
+    id BIGSERIAL PRIMARY KEY,
+    response_id BIGINT NOT NULL REFERENCES responses(id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL,
+    author_role VARCHAR(16) NOT NULL CHECK (author_role IN ('customer', 'executor')),
+    action VARCHAR(16) NOT NULL CHECK (action IN ('propose', 'counter', 'accept', 'withdraw')),
+    price INTEGER NOT NULL,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_offers_response_id ON response_offers (response_id, id);
+
+-- Существующие отклики открывают переговоры своими исходными условиями.
+INSERT INTO response_offers (response_id, author_id, author_role, action, price, created_at)
+SELECT r.id, r.user_id, 'executor', 'propose', r.proposed_price, r.created_at
+FROM responses r
+WHERE NOT EXISTS (SELECT 1 FROM response_offers o WHERE o.response_id = r.id);
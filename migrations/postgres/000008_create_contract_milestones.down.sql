@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reports_milestone_id;
+ALTER TABLE reports DROP COLUMN IF EXISTS milestone_id;
+DROP TABLE IF EXISTS contract_milestones;
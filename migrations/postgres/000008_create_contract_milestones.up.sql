@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS contract_milestones (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    due_date TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (contract_id, position)
+);
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS milestone_id BIGINT REFERENCES contract_milestones(id) ON DELETE CASCADE;
+
+-- Существующие контракты получают один этап на весь объём работ, а их отчёты привязываются к нему.
+INSERT INTO contract_milestones (contract_id, position, title, amount, due_date, created_at)
+SELECT c.id, 1, 'Весь объём работ', COALESCE(c.price, t.cost, 0), c.end_date, c.created_at
+FROM contracts c
+JOIN tasks t ON t.id = c.task_id
+WHERE NOT EXISTS (SELECT 1 FROM contract_milestones m WHERE m.contract_id = c.id);
+
+UPDATE reports r
+SET milestone_id = m.id
+FROM contract_milestones m
+WHERE m.contract_id = r.contract_id
+  AND r.milestone_id IS NULL
+  AND r.id = (SELECT MAX(id) FROM reports WHERE contract_id = r.contract_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_milestone_id ON reports (milestone_id);
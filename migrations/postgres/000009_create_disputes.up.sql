@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS disputes (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    opened_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    executor_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_review', 'resolved')),
+    arbitrator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    outcome VARCHAR(16) CHECK (outcome IN ('complete', 'partial', 'cancel')),
+    settled_amount INTEGER CHECK (settled_amount > 0),
+    resolution_comment TEXT,
+    due_at TIMESTAMP NOT NULL,
+    sla_breached_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP
+);
+
+-- По контракту может быть только один нерассмотренный спор.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_contract_unresolved ON disputes (contract_id) WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS idx_disputes_status_due_at ON disputes (status, due_at);
+
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES disputes(id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_evidence_dispute_id ON dispute_evidence (dispute_id);
+
+-- Лента статусов спора, которую видят стороны и арбитр.
+CREATE TABLE IF NOT EXISTS dispute_events (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES disputes(id) ON DELETE CASCADE,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_events_dispute_id ON dispute_events (dispute_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS auctions DROP CONSTRAINT IF EXISTS fk_auctions_winning_bid;
+DROP TABLE IF EXISTS bids;
+DROP TABLE IF EXISTS auctions;
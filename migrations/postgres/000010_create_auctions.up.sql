@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS auctions (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL UNIQUE REFERENCES tasks(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mode VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (mode IN ('open', 'sealed')),
+    status VARCHAR(16) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'closed')),
+    reserve_price INTEGER CHECK (reserve_price > 0),
+    closes_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP,
+    winning_bid_id BIGINT,
+    winner_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    winning_amount INTEGER
+);
+
+-- Фоновая задача ищет идущие торги с истёкшим сроком.
+CREATE INDEX IF NOT EXISTS idx_auctions_running_closes_at ON auctions (closes_at) WHERE status = 'running';
+
+-- У исполнителя одна ставка на торги: повторная ставка понижает прежнюю.
+CREATE TABLE IF NOT EXISTS bids (
+    id BIGSERIAL PRIMARY KEY,
+    auction_id BIGINT NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    executor_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (auction_id, executor_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bids_auction_amount ON bids (auction_id, amount, updated_at);
+
+ALTER TABLE auctions ADD CONSTRAINT fk_auctions_winning_bid FOREIGN KEY (winning_bid_id) REFERENCES bids(id) ON DELETE SET NULL;
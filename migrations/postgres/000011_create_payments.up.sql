@@ -0,0 +1,32 @@
+-- Эскроу по контракту: один платёж на контракт.
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL UNIQUE REFERENCES contracts(id) ON DELETE CASCADE,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    executor_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    released_amount INTEGER NOT NULL DEFAULT 0 CHECK (released_amount >= 0),
+    refunded_amount INTEGER NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'held', 'released', 'refunded', 'settled', 'failed')),
+    gateway_ref VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    hold_attempts INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (released_amount + refunded_amount <= amount)
+);
+
+-- Ключ идемпотентности не даёт провести одну операцию дважды при повторной доставке события.
+CREATE TABLE IF NOT EXISTS payment_operations (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('hold', 'release', 'refund')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_operations_payment ON payment_operations (payment_id, created_at);
@@ -0,0 +1,59 @@
+-- Счета главной книги. У системных счетов (эскроу, комиссия, внешний) user_id = 0.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('wallet', 'escrow', 'commission', 'external')),
+    user_id BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, user_id, currency),
+    CHECK ((kind = 'wallet') = (user_id <> 0))
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    contract_id BIGINT REFERENCES contracts(id) ON DELETE RESTRICT,
+    description TEXT NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_contract ON ledger_entries (contract_id) WHERE contract_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ledger_lines (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES ledger_entries(id) ON DELETE RESTRICT,
+    account_id BIGINT NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    amount INTEGER NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_lines_account ON ledger_lines (account_id, entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_lines_entry ON ledger_lines (entry_id);
+
+-- Проводки неизменяемы: ошибки исправляются сторнирующими проводками.
+CREATE OR REPLACE FUNCTION ledger_forbid_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'записи главной книги нельзя изменять или удалять';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_immutable BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_change();
+CREATE TRIGGER ledger_lines_immutable BEFORE UPDATE OR DELETE ON ledger_lines
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_change();
+
+-- Сумма строк каждой проводки равна нулю. Проверка отложена до конца транзакции,
+-- потому что строки проводки вставляются по одной.
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_lines WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'проводка % не сбалансирована', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_lines_balanced AFTER INSERT ON ledger_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
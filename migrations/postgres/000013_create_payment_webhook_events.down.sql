@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_payments_gateway_ref;
+DROP TABLE IF EXISTS payment_webhook_events;
@@ -0,0 +1,19 @@
+-- Уведомления платёжного шлюза. Тело хранится как получено, чтобы уведомление можно было
+-- обработать повторно; event_id защищает от повторной доставки.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'received'
+        CHECK (status IN ('received', 'processed', 'ignored', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_webhook_events_status ON payment_webhook_events (status, received_at DESC);
+
+-- Уведомления находят платёж по идентификатору блокировки в шлюзе.
+CREATE INDEX IF NOT EXISTS idx_payments_gateway_ref ON payments (gateway_ref) WHERE gateway_ref <> '';
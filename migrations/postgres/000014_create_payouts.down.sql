@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS payout_batches;
+DROP TABLE IF EXISTS payout_methods;
+ALTER TABLE payment_operations DROP COLUMN IF EXISTS fee;
+ALTER TABLE payments DROP COLUMN IF EXISTS fee_amount;
+ALTER TABLE payments DROP COLUMN IF EXISTS fee_rate_bp;
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_kind_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_kind_check
+    CHECK (kind IN ('wallet', 'escrow', 'commission', 'external'));
@@ -0,0 +1,47 @@
+-- Счёт резерва под заявки на вывод.
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_kind_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_kind_check
+    CHECK (kind IN ('wallet', 'escrow', 'commission', 'external', 'payout'));
+
+-- Ставка комиссии фиксируется при заключении контракта.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fee_rate_bp INTEGER NOT NULL DEFAULT 0 CHECK (fee_rate_bp BETWEEN 0 AND 10000);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fee_amount INTEGER NOT NULL DEFAULT 0 CHECK (fee_amount >= 0);
+ALTER TABLE payment_operations ADD COLUMN IF NOT EXISTS fee INTEGER NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+-- Способы вывода хранятся как токены платёжного шлюза; реквизиты у нас не хранятся.
+CREATE TABLE IF NOT EXISTS payout_methods (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('card', 'bank_account')),
+    token VARCHAR(255) NOT NULL,
+    last4 VARCHAR(4) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_methods_user ON payout_methods (user_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS payout_batches (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS payouts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    method_id BIGINT NOT NULL REFERENCES payout_methods(id) ON DELETE RESTRICT,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    status VARCHAR(16) NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'approved', 'rejected', 'processing', 'paid', 'failed')),
+    batch_id BIGINT REFERENCES payout_batches(id) ON DELETE SET NULL,
+    gateway_ref VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_status ON payouts (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_payouts_user ON payouts (user_id, created_at DESC);
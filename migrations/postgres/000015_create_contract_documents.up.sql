@@ -0,0 +1,24 @@
+-- Счётчики номеров документов: сквозная нумерация по виду документа и году.
+-- Номер берётся в транзакции выпуска документа, поэтому откат возвращает его в счётчик.
+CREATE TABLE IF NOT EXISTS document_counters (
+    kind VARCHAR(16) NOT NULL,
+    year INTEGER NOT NULL,
+    last_number INTEGER NOT NULL CHECK (last_number > 0),
+    PRIMARY KEY (kind, year)
+);
+
+CREATE TABLE IF NOT EXISTS contract_documents (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL REFERENCES contracts(id) ON DELETE RESTRICT,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('invoice', 'receipt')),
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    number VARCHAR(32) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    fee INTEGER NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (contract_id, kind),
+    UNIQUE (kind, year, sequence)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subscription_charges;
+DROP TABLE IF EXISTS subscriptions;
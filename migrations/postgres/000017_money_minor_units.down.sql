@@ -0,0 +1,45 @@
+-- Откат возвращает суммы в целые рубли. Суммы в других валютах и дробные копейки при этом теряют смысл:
+-- откатывать миграцию можно, только пока площадка работала в рублях.
+
+ALTER TABLE subscription_charges ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+ALTER TABLE subscriptions ALTER COLUMN credit TYPE INTEGER USING credit / 100;
+
+ALTER TABLE contract_documents
+    ALTER COLUMN amount TYPE INTEGER USING amount / 100,
+    ALTER COLUMN fee TYPE INTEGER USING fee / 100;
+
+ALTER TABLE ledger_lines ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+
+ALTER TABLE payouts ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+ALTER TABLE payment_operations
+    ALTER COLUMN amount TYPE INTEGER USING amount / 100,
+    ALTER COLUMN fee TYPE INTEGER USING fee / 100;
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE INTEGER USING amount / 100,
+    ALTER COLUMN released_amount TYPE INTEGER USING released_amount / 100,
+    ALTER COLUMN refunded_amount TYPE INTEGER USING refunded_amount / 100,
+    ALTER COLUMN fee_amount TYPE INTEGER USING fee_amount / 100;
+
+ALTER TABLE bids ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+ALTER TABLE auctions ALTER COLUMN winning_amount TYPE INTEGER USING winning_amount / 100;
+ALTER TABLE auctions ALTER COLUMN reserve_price TYPE INTEGER USING reserve_price / 100;
+
+ALTER TABLE disputes ALTER COLUMN settled_amount TYPE INTEGER USING settled_amount / 100;
+ALTER TABLE contract_milestones ALTER COLUMN amount TYPE INTEGER USING amount / 100;
+ALTER TABLE contracts DROP COLUMN IF EXISTS price_currency;
+ALTER TABLE contracts ALTER COLUMN price TYPE INTEGER USING price / 100;
+
+ALTER TABLE response_offers ALTER COLUMN price TYPE INTEGER USING price / 100;
+ALTER TABLE responses DROP COLUMN IF EXISTS proposed_currency;
+ALTER TABLE responses ALTER COLUMN proposed_price TYPE INTEGER USING proposed_price / 100;
+
+ALTER TABLE task_revisions DROP COLUMN IF EXISTS cost_currency;
+ALTER TABLE task_revisions ALTER COLUMN cost TYPE INTEGER USING cost / 100;
+
+DROP INDEX IF EXISTS idx_tasks_cost_currency;
+DROP INDEX IF EXISTS idx_tasks_cost_base;
+ALTER TABLE tasks DROP COLUMN IF EXISTS cost_base;
+ALTER TABLE tasks DROP COLUMN IF EXISTS cost_currency;
+ALTER TABLE tasks ALTER COLUMN cost TYPE INTEGER USING cost / 100;
+
+DROP TABLE IF EXISTS currency_rates;
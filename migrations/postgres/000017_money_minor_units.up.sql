@@ -0,0 +1,56 @@
+-- Суммы хранятся в минимальных единицах валюты (копейках, центах) вместе с кодом валюты по ISO 4217.
+-- До этой миграции все суммы были в целых рублях, поэтому они умножаются на 100.
+-- ALTER COLUMN TYPE переписывает таблицу целиком и не вызывает строчных триггеров,
+-- поэтому запрет на изменение проводок главной книги пересчёту не мешает.
+
+CREATE TABLE IF NOT EXISTS currency_rates (
+    currency VARCHAR(3) PRIMARY KEY,
+    rate_micros BIGINT NOT NULL CHECK (rate_micros > 0), -- Курс к базовой валюте, умноженный на 1 000 000
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tasks ALTER COLUMN cost TYPE BIGINT USING cost::BIGINT * 100;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cost_currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+-- Цена в базовой валюте по текущему курсу: по ней фильтруют и сортируют поиск.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cost_base BIGINT;
+UPDATE tasks SET cost_base = cost;
+CREATE INDEX IF NOT EXISTS idx_tasks_cost_base ON tasks (cost_base);
+CREATE INDEX IF NOT EXISTS idx_tasks_cost_currency ON tasks (cost_currency) WHERE cost_currency <> 'RUB';
+
+ALTER TABLE task_revisions ALTER COLUMN cost TYPE BIGINT USING cost::BIGINT * 100;
+ALTER TABLE task_revisions ADD COLUMN IF NOT EXISTS cost_currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+
+-- Валюта отклика совпадает с валютой задачи; встречные предложения ведутся в ней же.
+ALTER TABLE responses ALTER COLUMN proposed_price TYPE BIGINT USING proposed_price::BIGINT * 100;
+ALTER TABLE responses ADD COLUMN IF NOT EXISTS proposed_currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE response_offers ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+
+ALTER TABLE contracts ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS price_currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE contract_milestones ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+ALTER TABLE disputes ALTER COLUMN settled_amount TYPE BIGINT USING settled_amount::BIGINT * 100;
+
+-- Торги ведутся в базовой валюте.
+ALTER TABLE auctions ALTER COLUMN reserve_price TYPE BIGINT USING reserve_price::BIGINT * 100;
+ALTER TABLE auctions ALTER COLUMN winning_amount TYPE BIGINT USING winning_amount::BIGINT * 100;
+ALTER TABLE bids ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100,
+    ALTER COLUMN released_amount TYPE BIGINT USING released_amount::BIGINT * 100,
+    ALTER COLUMN refunded_amount TYPE BIGINT USING refunded_amount::BIGINT * 100,
+    ALTER COLUMN fee_amount TYPE BIGINT USING fee_amount::BIGINT * 100;
+ALTER TABLE payment_operations
+    ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100,
+    ALTER COLUMN fee TYPE BIGINT USING fee::BIGINT * 100;
+ALTER TABLE payouts ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+
+ALTER TABLE ledger_lines ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+
+ALTER TABLE contract_documents
+    ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100,
+    ALTER COLUMN fee TYPE BIGINT USING fee::BIGINT * 100;
+
+ALTER TABLE subscriptions ALTER COLUMN credit TYPE BIGINT USING credit::BIGINT * 100;
+ALTER TABLE subscription_charges ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
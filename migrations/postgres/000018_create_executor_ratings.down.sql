@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rating_applied_reviews;
+DROP TABLE IF EXISTS executor_ratings;
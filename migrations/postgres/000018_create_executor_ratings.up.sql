@@ -0,0 +1,25 @@
+-- Рейтинг исполнителя по срезам: общий (категория и подкатегория равны нулю), по категории и по подкатегории.
+-- Взвешенные суммы приведены к моменту as_of, score — байесовская оценка на этот момент.
+CREATE TABLE IF NOT EXISTS executor_ratings (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL DEFAULT 0,
+    subcategory_id BIGINT NOT NULL DEFAULT 0,
+    review_count INTEGER NOT NULL DEFAULT 0,
+    rating_sum INTEGER NOT NULL DEFAULT 0,
+    weighted_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    weight_total DOUBLE PRECISION NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL,
+    as_of TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category_id, subcategory_id)
+);
+
+-- Сортировка исполнителей по рейтингу в срезе.
+CREATE INDEX IF NOT EXISTS idx_executor_ratings_scope_score ON executor_ratings (category_id, subcategory_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_executor_ratings_as_of ON executor_ratings (as_of);
+
+-- Отзывы, уже учтённые в executor_ratings: защищает от повторного учёта одного отзыва.
+CREATE TABLE IF NOT EXISTS rating_applied_reviews (
+    review_id BIGINT PRIMARY KEY REFERENCES reviews(id) ON DELETE CASCADE,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+ALTER TABLE rating_applied_reviews DROP COLUMN IF EXISTS rating;
+
+DROP TABLE IF EXISTS review_reports;
+
+DROP INDEX IF EXISTS idx_reviews_hidden;
+DROP INDEX IF EXISTS idx_reviews_user_published;
+DROP INDEX IF EXISTS idx_reviews_contract_author;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS removal_reason,
+    DROP COLUMN IF EXISTS removed_at,
+    DROP COLUMN IF EXISTS replied_at,
+    DROP COLUMN IF EXISTS reply,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS side,
+    DROP COLUMN IF EXISTS author_id;
@@ -0,0 +1,57 @@
+-- Автор и сторона отзыва, публикация после отзывов обеих сторон, ответ и снятие модератором.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS side VARCHAR(16) CHECK (side IN ('customer', 'executor')),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS reply TEXT,
+    ADD COLUMN IF NOT EXISTS replied_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS removal_reason TEXT;
+
+-- Автор старых отзывов — вторая сторона контракта; они уже были видны всем и остаются опубликованными.
+UPDATE reviews rv
+SET author_id = CASE WHEN rv.user_id = c.executor_id THEN c.customer_id ELSE c.executor_id END,
+    side = CASE WHEN rv.user_id = c.executor_id THEN 'customer' ELSE 'executor' END
+FROM contracts c
+WHERE c.id = rv.contract_id AND rv.author_id IS NULL;
+
+UPDATE reviews SET updated_at = created_at, published_at = created_at WHERE published_at IS NULL;
+
+ALTER TABLE reviews ALTER COLUMN updated_at SET DEFAULT NOW();
+ALTER TABLE reviews ALTER COLUMN updated_at SET NOT NULL;
+
+-- Повторные отзывы одной стороны по контракту, оставленные до ограничения, снимаются: остаётся первый.
+UPDATE reviews rv
+SET removed_at = NOW(), removal_reason = 'Повторный отзыв по контракту'
+WHERE rv.removed_at IS NULL AND EXISTS (
+    SELECT 1 FROM reviews earlier
+    WHERE earlier.contract_id = rv.contract_id AND earlier.author_id = rv.author_id
+      AND (earlier.created_at, earlier.id) < (rv.created_at, rv.id)
+);
+
+-- Один отзыв стороны по контракту. Снятые модератором остаются в таблице, поэтому повторный отзыв
+-- запрещает сервис, а индекс защищает от одновременной отправки.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_contract_author ON reviews (contract_id, author_id) WHERE removed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reviews_user_published ON reviews (user_id, created_at DESC) WHERE published_at IS NOT NULL AND removed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reviews_hidden ON reviews (created_at) WHERE published_at IS NULL AND removed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL CHECK (reason IN ('spam', 'abuse', 'personal_data', 'off_topic', 'other')),
+    comment TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'upheld', 'rejected')),
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (review_id, reporter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_reports_open ON review_reports (review_id, created_at) WHERE status = 'open';
+
+-- Рейтинг учитывает только опубликованные и не снятые отзывы. Для каждого отзыва хранится
+-- учтённая оценка (0 — не учтён), чтобы снятие отзыва можно было вычесть из рейтинга.
+ALTER TABLE rating_applied_reviews ADD COLUMN IF NOT EXISTS rating SMALLINT NOT NULL DEFAULT 0;
+UPDATE rating_applied_reviews a SET rating = rv.rating FROM reviews rv WHERE rv.id = a.review_id;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_updated_at;
+DROP TRIGGER IF EXISTS tasks_updated_at ON tasks;
+DROP FUNCTION IF EXISTS tasks_touch_updated_at();
+ALTER TABLE tasks DROP COLUMN IF EXISTS updated_at;
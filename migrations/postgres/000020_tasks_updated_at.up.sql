@@ -0,0 +1,15 @@
+-- Время последнего изменения задачи: по нему переиндексация догоняет изменения, сделанные во время её работы.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+UPDATE tasks SET updated_at = created_at;
+
+CREATE OR REPLACE FUNCTION tasks_touch_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_updated_at BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_touch_updated_at();
+
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks (updated_at);
@@ -0,0 +1,10 @@
+-- Журнал поисковых запросов для подсказок: нормализованный текст и число запусков, по которым что-то нашлось.
+CREATE TABLE IF NOT EXISTS search_queries (
+    query VARCHAR(100) PRIMARY KEY,
+    runs BIGINT NOT NULL DEFAULT 1,
+    first_run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_run_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Подсказки берут самые частые из недавних запросов.
+CREATE INDEX IF NOT EXISTS idx_search_queries_last_run_at ON search_queries (last_run_at);
@@ -0,0 +1,25 @@
+-- Короткие ссылки на задачи, профили и приглашения. clicks — переходы без учёта ботов для списка ссылок.
+CREATE TABLE IF NOT EXISTS short_links (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('task', 'profile', 'referral')),
+    target_id BIGINT NOT NULL,
+    custom BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    clicks BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_links_owner ON short_links (owner_id, created_at DESC, id DESC);
+
+-- Переходы по ссылкам. Хранится только домен источника и семейство браузера, время — в UTC.
+CREATE TABLE IF NOT EXISTS short_link_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    link_id BIGINT NOT NULL REFERENCES short_links(id) ON DELETE CASCADE,
+    clicked_at TIMESTAMP NOT NULL,
+    referrer_host VARCHAR(255) NOT NULL DEFAULT '',
+    ua_family VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_link_clicks_link ON short_link_clicks (link_id, clicked_at);
@@ -0,0 +1,13 @@
+-- Выплаты за подтверждённые этапы, которые ещё не проведены: подтверждение может прийти раньше,
+-- чем шлюз подтвердит блокировку средств. released_at заполняется, когда выплата проведена.
+CREATE TABLE IF NOT EXISTS payment_pending_releases (
+    id BIGSERIAL PRIMARY KEY,
+    contract_id BIGINT NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_pending_releases_contract ON payment_pending_releases (contract_id, created_at)
+    WHERE released_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_payouts_request_key;
+ALTER TABLE payouts DROP COLUMN IF EXISTS request_key;
@@ -0,0 +1,5 @@
+-- Ключ идемпотентности заявки на вывод из заголовка Idempotency-Key: повтор запроса с тем же
+-- ключом возвращает уже созданную заявку. Заявки без ключа хранят NULL и не конфликтуют.
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS request_key VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_request_key ON payouts (user_id, request_key);
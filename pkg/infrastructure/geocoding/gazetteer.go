@@ -0,0 +1,125 @@
+package geocoding
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+)
+
+//go:embed gazetteer_ru.txt
+var defaultGazetteer string
+
+// Gazetteer — офлайн-геокодер по справочнику населённых пунктов.
+// Работает без сети: ищет в адресе название населённого пункта и возвращает его координаты.
+// Адрес, заданный прямо координатами ("55.75, 37.61"), возвращается как есть.
+type Gazetteer struct {
+	places   map[string]geo.Point // Нормализованное название -> координаты
+	maxWords int                  // Наибольшее число слов в названии
+}
+
+// NewGazetteer создаёт геокодер со встроенным справочником городов России.
+func NewGazetteer() *Gazetteer {
+	g, err := NewGazetteerFromReader(strings.NewReader(defaultGazetteer))
+	if err != nil {
+		// Встроенный справочник проверяется тестами, ошибка здесь — ошибка сборки.
+		panic(fmt.Sprintf("встроенный справочник геокодера повреждён: %v", err))
+	}
+	return g
+}
+
+// NewGazetteerFromReader загружает справочник в формате "название|синоним;широта;долгота".
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func NewGazetteerFromReader(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{places: make(map[string]geo.Point)}
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("строка %d: ожидается 3 поля, получено %d", lineNo, len(fields))
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректная широта: %w", lineNo, err)
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректная долгота: %w", lineNo, err)
+		}
+		point := geo.Point{Lat: lat, Lon: lon}
+		if err := point.Validate(); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", lineNo, err)
+		}
+
+		for _, name := range strings.Split(fields[0], "|") {
+			words := normalize(name)
+			if len(words) == 0 {
+				continue
+			}
+			g.places[strings.Join(words, " ")] = point
+			if len(words) > g.maxWords {
+				g.maxWords = len(words)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения справочника: %w", err)
+	}
+	return g, nil
+}
+
+// Geocode возвращает координаты адреса.
+// Из нескольких совпадений выбирается самое длинное название, а при равной длине — первое в адресе.
+func (g *Gazetteer) Geocode(ctx context.Context, address string) (geo.Point, error) {
+	if p, ok := parseCoordinates(address); ok {
+		return p, nil
+	}
+
+	words := normalize(address)
+	for n := g.maxWords; n > 0; n-- {
+		for i := 0; i+n <= len(words); i++ {
+			if p, ok := g.places[strings.Join(words[i:i+n], " ")]; ok {
+				return p, nil
+			}
+		}
+	}
+	return geo.Point{}, fmt.Errorf("%w: %q", geo.ErrAddressNotFound, address)
+}
+
+// normalize приводит текст к словам в нижнем регистре: ё заменяется на е,
+// дефисы и знаки препинания считаются разделителями ("Ростов-на-Дону" -> ростов на дону).
+func normalize(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// parseCoordinates распознаёт адрес вида "55.7558, 37.6173".
+func parseCoordinates(s string) (geo.Point, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return geo.Point{}, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil {
+		return geo.Point{}, false
+	}
+	p := geo.Point{Lat: lat, Lon: lon}
+	return p, p.Validate() == nil
+}
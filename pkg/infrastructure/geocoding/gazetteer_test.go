@@ -0,0 +1,46 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/unclaim/chegonado.git/internal/shared/geo"
+)
+
+func TestGazetteerGeocode(t *testing.T) {
+	g := NewGazetteer()
+	ctx := context.Background()
+
+	cases := map[string]geo.Point{
+		"г. Москва, ул. Тверская, д. 1":     {Lat: 55.7558, Lon: 37.6173},
+		"Нижний Новгород, пр. Гагарина 10":  {Lat: 56.2965, Lon: 43.9361},
+		"ростов-на-дону, Большая Садовая":   {Lat: 47.2357, Lon: 39.7015},
+		"Орёл, Комсомольская":               {Lat: 52.9671, Lon: 36.0696},
+		"55.7, 37.6":                        {Lat: 55.7, Lon: 37.6},
+		"Россия, СПб, Невский проспект, 28": {Lat: 59.9343, Lon: 30.3351},
+	}
+	for address, want := range cases {
+		got, err := g.Geocode(ctx, address)
+		if err != nil {
+			t.Errorf("%q: неожиданная ошибка: %v", address, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: ожидалось %v, получено %v", address, want, got)
+		}
+	}
+
+	if _, err := g.Geocode(ctx, "Атлантида, ул. Подводная"); !errors.Is(err, geo.ErrAddressNotFound) {
+		t.Errorf("ожидалась geo.ErrAddressNotFound, получено: %v", err)
+	}
+}
+
+func TestNewGazetteerFromReaderRejectsBadLines(t *testing.T) {
+	for _, data := range []string{"москва;55.75", "москва;abc;37.6", "москва;95;37.6"} {
+		if _, err := NewGazetteerFromReader(strings.NewReader(data)); err == nil {
+			t.Errorf("%q: ожидалась ошибка разбора", data)
+		}
+	}
+}
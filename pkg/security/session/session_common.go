@@ -93,6 +93,7 @@ var noAuthUrls = map[string]struct{}{
 	"/auth/resend-code":             {},
 	"/api/auth/signup/send-code":    {},
 	"/api/user/check-user":          {},
+	"/api/payments/webhook":         {}, // Подлинность уведомлений шлюза проверяется по подписи
 }
 
 // AuthMiddleware является HTTP middleware, который проверяет наличие действительной сессии.
@@ -112,6 +113,11 @@ func AuthMiddleware(sm SessionManager, ctx context.Context, next http.Handler) h
 			next.ServeHTTP(w, r)
 			return
 		}
+		// Короткие ссылки открывают и незарегистрированные посетители
+		if strings.HasPrefix(currentPath, "/s/") {
+			next.ServeHTTP(w, r)
+			return
+		}
 		if strings.HasPrefix(currentPath, "/swagger/") {
 			next.ServeHTTP(w, r)
 			return